@@ -1,34 +1,66 @@
 package app
 
 import (
+	cmd2 "claude-squad/cmd"
 	"claude-squad/config"
 	"claude-squad/keys"
 	"claude-squad/log"
 	"claude-squad/session"
+	"claude-squad/session/git"
+	"claude-squad/session/tmux"
 	"claude-squad/ui"
 	"claude-squad/ui/autocomplete"
 	"claude-squad/ui/overlay"
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
 )
 
 const GlobalInstanceLimit = 10
 
-// Run is the main entrypoint into the application.
-func Run(ctx context.Context, program string, autoYes bool) error {
+// Run is the main entrypoint into the application and runs it until it quits. startupMode overrides the
+// startup_mode in the config if it isn't empty. It returns whether auto-yes was on when it quit, which can differ
+// from autoYes since auto-yes can be turned on and off while running.
+func Run(ctx context.Context, program string, autoYes bool, startupMode string, statusSocket string) (bool, error) {
+	// Fail early with an actionable message instead of a cryptic exec error when the first instance starts.
+	if _, err := tmux.CheckVersion(cmd2.MakeExecutor()); err != nil {
+		return autoYes, fmt.Errorf("error: %v\n%s", err, tmux.InstallHint())
+	}
+
+	h := newHome(ctx, program, autoYes, startupMode)
+	if statusSocket != "" {
+		server, err := startStatusServer(statusSocket)
+		if err != nil {
+			return autoYes, err
+		}
+		defer func() {
+			if err := server.Close(); err != nil {
+				log.WarningLog.Printf("failed to close status socket: %v", err)
+			}
+		}()
+		h.statusServer = server
+	}
+
 	p := tea.NewProgram(
-		newHome(ctx, program, autoYes),
+		h,
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(), // Mouse scroll
 	)
 	_, err := p.Run()
-	return err
+	return h.autoYes, err
 }
 
 type state int
@@ -43,6 +75,46 @@ const (
 	stateHelp
 	// stateConfirm is the state when a confirmation modal is displayed.
 	stateConfirm
+	// stateActivity is the state when an instance's activity log is displayed.
+	stateActivity
+	// stateTemplate is the state when the user is choosing a template for a new instance.
+	stateTemplate
+	// stateRemote is the state when the user is entering the remote branch for a new instance.
+	stateRemote
+	// stateNote is the state when the user is editing the selected instance's note.
+	stateNote
+	// stateColor is the state when the user is entering the selected instance's color and icon.
+	stateColor
+	// stateGroup is the state when the user is entering the selected instance's group.
+	stateGroup
+	// stateKeys is the state when the reference of every key is displayed.
+	stateKeys
+	// stateRepoPath is the state when the user is entering the repository of a new instance.
+	stateRepoPath
+	// stateSearch is the state when the user is entering a query to search the output of all instances.
+	stateSearch
+	// stateSearchResults is the state when the user is choosing one of the instances whose output matched.
+	stateSearchResults
+	// statePromptTemplate is the state when the user is choosing a prompt template to send to the selected instance.
+	statePromptTemplate
+	// statePromptForm is the state when the user is filling in the placeholders of a prompt template.
+	statePromptForm
+	// stateSaveDiff is the state when the user is entering the file to save the selected instance's diff to.
+	stateSaveDiff
+	// stateExitChoice is the state when the user is choosing what to do with an instance whose program exited.
+	stateExitChoice
+	// stateForwardPrompt is the state when the user is choosing the instance to send the selected instance's last
+	// prompt to.
+	stateForwardPrompt
+	// stateSwitcher is the state when the user is typing part of the title of an instance to jump to.
+	stateSwitcher
+	// stateTmuxSessions is the state when the tmux session names of the instances are displayed.
+	stateTmuxSessions
+	// stateCheckpoints is the state when the user is choosing an auto-commit or checkpoint of the selected instance
+	// to restore.
+	stateCheckpoints
+	// stateQuitting is the state when the running instances are paused before quitting. Keys are ignored.
+	stateQuitting
 )
 
 type home struct {
@@ -51,6 +123,8 @@ type home struct {
 	// -- Storage and Configuration --
 
 	program string
+	// autoYes is the global auto-yes switch. Turning it on or off sets AutoYes on every instance, and new instances
+	// follow it. An instance's own AutoYes can still differ from it until the switch is toggled again.
 	autoYes bool
 
 	// storage is the interface for saving/loading data to/from the app's state
@@ -70,11 +144,21 @@ type home struct {
 
 	// promptAfterName tracks if we should enter prompt mode after naming
 	promptAfterName bool
-	// pendingPrompt stores a prompt submitted before instance finished initializing
-	pendingPrompt string
+	// pendingPrompts stores the prompts submitted before their instance finished initializing, one per instance
+	pendingPrompts map[*session.Instance]string
+	// titleFromPrompt is true while the prompt is entered before the title, so the title can be derived from it
+	titleFromPrompt bool
+	// promptToExisting is true while a prompt is entered for an instance that is already running. It is queued if
+	// the instance is busy.
+	promptToExisting bool
+	// namePrompt stores the prompt entered before the title. It becomes the pending prompt once the instance starts.
+	namePrompt string
 
 	// keySent is used to manage underlining menu items
 	keySent bool
+	// windowWidth and windowHeight are the size of the terminal from the last WindowSizeMsg. Overlays opened
+	// between two resizes are sized from them.
+	windowWidth, windowHeight int
 
 	// -- UI Components --
 
@@ -94,9 +178,63 @@ type home struct {
 	textOverlay *overlay.TextOverlay
 	// confirmationOverlay displays confirmation modals
 	confirmationOverlay *overlay.ConfirmationOverlay
-
+	// activityOverlay displays the activity log of an instance, or the tmux session names of the instances
+	activityOverlay *overlay.ActivityOverlay
+	// tmuxSessionNames are the names of the open tmux sessions shown in activityOverlay in stateTmuxSessions, in order
+	tmuxSessionNames []string
+	// keysOverlay displays the reference of every key
+	keysOverlay *overlay.ActivityOverlay
+	// shownHelp is the help screen displayed in stateHelp
+	shownHelp helpText
+	// templatePicker lets the user choose the template of a new instance
+	templatePicker *overlay.PickerOverlay
+	// searchPicker lets the user choose one of searchMatches to jump to
+	searchPicker *overlay.PickerOverlay
+	// searchMatches are the instances whose output matched the last search, in the order of searchPicker
+	searchMatches []session.OutputMatch
+	// promptTemplatePicker lets the user choose the prompt template to send to promptTemplateTarget
+	promptTemplatePicker *overlay.PickerOverlay
+	// promptForm asks for the placeholders of promptTemplate
+	promptForm *overlay.FormOverlay
+	// promptTemplate is the prompt template whose placeholders are asked for in promptForm
+	promptTemplate config.PromptTemplate
+	// promptTemplateTarget is the instance the chosen prompt template is sent to
+	promptTemplateTarget *session.Instance
+	// exitChoicePicker asks whether to restart, archive or kill exitChoiceTarget
+	exitChoicePicker *overlay.PickerOverlay
+	// exitChoiceTarget is the instance whose program exited that exitChoicePicker asks about
+	exitChoiceTarget *session.Instance
+	// forwardPromptPicker asks which of forwardPromptTargets to send the last prompt of forwardPromptSource to
+	forwardPromptPicker *overlay.PickerOverlay
+	// forwardPromptSource is the instance whose last prompt is sent
+	forwardPromptSource *session.Instance
+	// forwardPromptTargets are the instances offered in forwardPromptPicker
+	forwardPromptTargets []*session.Instance
+	// switcher lets the user jump to one of switcherInstances by typing part of its title or tags
+	switcher *overlay.SwitcherOverlay
+	// switcherInstances are the instances offered in switcher, in the order of its items
+	switcherInstances []*session.Instance
+	// checkpointPicker asks which of checkpointCommits of checkpointInstance to restore
+	checkpointPicker *overlay.PickerOverlay
+	// checkpointInstance is the instance whose checkpoints are offered in checkpointPicker
+	checkpointInstance *session.Instance
+	// checkpointCommits are the commits offered in checkpointPicker, in the order of its items
+	checkpointCommits []git.CheckpointCommit
+
+	// repoConfigDir is the directory the per-repo files below are loaded from
+	repoConfigDir string
 	// hotkeys maps number keys (1-9) to commands for quick send
 	hotkeys config.Hotkeys
+	// templates are the per-repo templates offered when creating an instance from a template
+	templates config.Templates
+	// promptTemplates are the per-repo prompt templates that can be sent to an instance after filling placeholders
+	promptTemplates config.PromptTemplates
+	// promptHistory stores previously sent prompts for recall in the prompt overlay
+	promptHistory *config.PromptHistory
+	// commandUsage counts the slash commands sent in prompts, to offer the most used ones first
+	commandUsage *config.CommandUsage
+	// autoYesMatcher decides which prompts auto-yes mode confirms
+	autoYesMatcher *session.AutoYesMatcher
 
 	// autocompleter provides command autocomplete for prompt input
 	autocompleter autocomplete.Autocompleter
@@ -105,14 +243,97 @@ type home struct {
 
 	// initProgressMessage stores the current progress message for initializing instance
 	initProgressMessage string
+	// startCancels cancels the initialization of the instances that are still starting
+	startCancels map[*session.Instance]context.CancelFunc
 
 	// pendingKillInstance stores the instance pending deletion after confirmation
 	pendingKillInstance *session.Instance
+	// pendingPushInstance stores the instance pending push after confirmation
+	pendingPushInstance *session.Instance
+	// pendingPushAmend is true if the pending push amends claude-squad's last commit
+	pendingPushAmend bool
+	// pushing holds the instances whose changes are being pushed. The push and rebase keys do nothing for them.
+	pushing map[*session.Instance]bool
+	// pendingRebaseInstance stores the instance pending rebase after confirmation
+	pendingRebaseInstance *session.Instance
+	// pendingRestoreInstance stores the instance pending reset to pendingRestoreCommit after confirmation
+	pendingRestoreInstance *session.Instance
+	// pendingRestoreCommit is the checkpoint pendingRestoreInstance is reset to
+	pendingRestoreCommit git.CheckpointCommit
+	// rebasing maps the instances whose branches are being rebased to the branch they are rebased onto. The
+	// rebase and push keys do nothing for them.
+	rebasing map[*session.Instance]string
+	// autoCommitting is true while the auto-commits started on the last metadata tick are running
+	autoCommitting bool
+	// autoPausing is true while the idle instances are being paused in the background
+	autoPausing bool
+	// pendingBulk is true while pendingBulkAction, KeyPauseAll or KeyResumeAll, waits for confirmation
+	pendingBulk       bool
+	pendingBulkAction keys.KeyName
+	// bulkRunning is true while the instances are paused or resumed all at once in the background
+	bulkRunning bool
+	// inBackground holds the instances being paused or resumed in the background, by autoPauseIdle, runBulk or
+	// quitting. Their new status is applied once that is done, and the metadata tick leaves them alone until then.
+	inBackground map[*session.Instance]bool
+	// gitResult is the result of the last push or rebase shown in the status line, until it's cleared
+	gitResult string
+	// pinned is the instance the preview and diff panes show whatever is selected, or nil to follow the selection
+	pinned *session.Instance
+	// saveFailed is true while the last save of the instances failed. The status line shows that there are
+	// unsaved changes until a later save succeeds.
+	saveFailed bool
+
+	// resumeOpenInstance is the instance resumed with KeyResumeOpen that is attached once its program is ready
+	resumeOpenInstance *session.Instance
+	// resumeOpenDeadline is when to stop waiting for resumeOpenInstance to become ready
+	resumeOpenDeadline time.Time
+
+	// heldBack are the stored instances that weren't loaded into the list because of the startup mode. They are
+	// saved along with the list so they stay in storage.
+	heldBack []*session.Instance
+
+	// quitMode is what quitting does with the running instances, one of config.QuitModes
+	quitMode string
+	// pendingQuitPause stores the instances to pause if quitting is confirmed with pausing
+	pendingQuitPause []*session.Instance
+	// pendingQuitWithChanges is true while asking whether to quit although instances have changes
+	pendingQuitWithChanges bool
+	// quitProgress is shown in the status line while the instances are paused before quitting
+	quitProgress string
+
+	// startupCmd is run once on Init to surface anything found while loading instances
+	startupCmd tea.Cmd
+
+	// diffOptions control how the diffs of all instances are computed
+	diffOptions git.DiffOptions
+	// previewInterval is how long the preview waits between refreshes. It is read from the config in Init.
+	previewInterval time.Duration
+	// statusServer serves the status of the instances on a Unix socket, or is nil if it isn't enabled
+	statusServer *statusServer
+}
+
+// diffFileOrder returns the order of the files in the diff view set in appConfig. An invalid order keeps git's.
+func diffFileOrder(appConfig *config.Config) (git.FileOrder, error) {
+	order, err := config.ParseDiffFileOrder(appConfig.DiffFileOrder)
+	if err != nil {
+		return git.FileOrder{}, err
+	}
+	switch order {
+	case config.DiffOrderAlphabetical:
+		return git.FileOrder{Alphabetical: true}, nil
+	case config.DiffOrderPriority:
+		fileOrder := git.FileOrder{Priority: appConfig.DiffFilePriority}
+		if err := fileOrder.Validate(); err != nil {
+			return git.FileOrder{}, fmt.Errorf("diff_file_priority in config: %w", err)
+		}
+		return fileOrder, nil
+	}
+	return git.FileOrder{}, nil
 }
 
-func newHome(ctx context.Context, program string, autoYes bool) *home {
-	// Load application config
-	appConfig := config.LoadConfig()
+func newHome(ctx context.Context, program string, autoYes bool, startupMode string) *home {
+	// Load application config. A broken config file is shown once the UI is up, while running with the defaults.
+	appConfig, configErr := config.ReadConfig()
 
 	// Load application state
 	appState := config.LoadState()
@@ -136,14 +357,52 @@ func newHome(ctx context.Context, program string, autoYes bool) *home {
 		autoYes:      autoYes,
 		state:        stateDefault,
 		appState:     appState,
+		diffOptions: git.DiffOptions{
+			IgnoreWhitespace: appConfig.DiffIgnoreWhitespace,
+			ContextLines:     appConfig.DiffContextLines,
+		},
 	}
 	h.list = ui.NewList(&h.spinner, autoYes)
+	h.list.SetCompact(appConfig.CompactList)
+	h.list.SetSortByAttention(appConfig.SortByAttention)
+	h.list.SetCollapsedGroups(appConfig.CollapsedGroups)
+
+	startupErrs := []error{configErr}
+	if appConfig.AutoYesKey != "" {
+		if err := keys.Rebind(keys.KeyAutoYes, appConfig.AutoYesKey); err != nil {
+			startupErrs = append(startupErrs, fmt.Errorf("ignoring auto_yes_key in config: %w", err))
+		}
+	}
+	menuItems, unknownMenuItems := keys.ParseActionNames(appConfig.MenuItems)
+	h.menu.SetItems(menuItems)
+	if len(unknownMenuItems) > 0 {
+		startupErrs = append(startupErrs, fmt.Errorf("ignoring unknown menu items in config: %s",
+			strings.Join(unknownMenuItems, ", ")))
+	}
+	if unknown := appConfig.UnknownConfirmActions(); len(unknown) > 0 {
+		startupErrs = append(startupErrs, fmt.Errorf("ignoring unknown actions in confirm in config: %s",
+			strings.Join(unknown, ", ")))
+	}
+
+	fileOrder, err := diffFileOrder(appConfig)
+	if err != nil {
+		startupErrs = append(startupErrs, fmt.Errorf("%w, keeping git's order of the files in the diff", err))
+	}
+	h.tabbedWindow.SetDiffFileOrder(fileOrder)
+
+	h.quitMode, err = config.ParseQuitMode(appConfig.QuitMode)
+	if err != nil {
+		startupErrs = append(startupErrs, fmt.Errorf("%w, leaving sessions running on quit", err))
+	}
 
-	// Load per-repo hotkeys
-	h.hotkeys = config.LoadHotkeys(".")
+	// Load per-repo hotkeys, templates, prompt templates, prompt history and Claude commands
+	startupErrs = append(startupErrs, h.loadRepoFiles("."))
 
-	// Initialize autocompleter for Claude commands
-	h.autocompleter = autocomplete.NewClaudeCommandsAutocompleter(".")
+	autoYesMatcher, err := session.NewAutoYesMatcher(appConfig.AutoYesPatterns, appConfig.DangerKeywords())
+	if err != nil {
+		log.ErrorLog.Printf("%v", err)
+	}
+	h.autoYesMatcher = autoYesMatcher
 
 	// Load saved instances
 	instances, err := storage.LoadInstances()
@@ -152,60 +411,302 @@ func newHome(ctx context.Context, program string, autoYes bool) *home {
 		os.Exit(1)
 	}
 
+	if startupMode == "" {
+		startupMode = appConfig.StartupMode
+	}
+	startupMode, err = config.ParseStartupMode(startupMode)
+	if err != nil {
+		startupErrs = append(startupErrs, fmt.Errorf("%w, restoring all instances", err))
+	}
+	shown, heldBack := startupInstances(instances, startupMode)
+	h.heldBack = heldBack
+	if len(heldBack) > 0 {
+		startupErrs = append(startupErrs, fmt.Errorf("%d stored instance(s) not loaded because of startup mode %s: "+
+			"start with --startup %s to load them", len(heldBack), startupMode, config.StartupRestoreAll))
+	}
+	var configCmd tea.Cmd
+	if err := errors.Join(startupErrs...); err != nil {
+		configCmd = h.handleError(err)
+	}
+
 	// Add loaded instances to the list
-	for _, instance := range instances {
+	for _, instance := range shown {
 		// Call the finalizer immediately.
 		h.list.AddInstance(instance)()
 		if autoYes {
 			instance.AutoYes = true
 		}
+		instance.SetDiffOptions(h.diffOptions)
+		h.setStripCommandSlash(instance)
 	}
 
+	// The instances that weren't loaded still own their tmux sessions, so they aren't offered to be killed.
+	h.startupCmd = tea.Batch(configCmd, h.reconcileSessions(instances))
+
 	return h
 }
 
+// startupInstances splits the stored instances into the ones shown in the list and the ones held back in storage
+// in the given startup mode, one of config.StartupModes.
+func startupInstances(instances []*session.Instance, mode string) (shown, heldBack []*session.Instance) {
+	for _, instance := range instances {
+		switch {
+		case mode == config.StartupStartEmpty,
+			mode == config.StartupRestoreRunning && (instance.Paused() || instance.Archived()):
+			heldBack = append(heldBack, instance)
+		default:
+			shown = append(shown, instance)
+		}
+	}
+	return shown, heldBack
+}
+
+// saveInstances stores the instances in the list together with the ones that were held back on startup. A failed
+// save is remembered so the status line can show it, and every change saves everything again, which retries it.
+func (m *home) saveInstances() error {
+	err := m.storage.SaveInstances(slices.Concat(m.list.GetInstances(), m.heldBack))
+	m.saveFailed = err != nil
+	return err
+}
+
+// reconcileSessions cross-references the loaded instances with the running tmux sessions. Instances whose
+// session had to be recovered are reported, and the user is offered to kill sessions with no matching instance.
+func (m *home) reconcileSessions(instances []*session.Instance) tea.Cmd {
+	var cmd tea.Cmd
+	var recovered []string
+	for _, instance := range instances {
+		if instance.Recovered() {
+			recovered = append(recovered, instance.Title)
+		}
+	}
+	if len(recovered) > 0 {
+		cmd = m.handleError(fmt.Errorf("recovered %d instance(s) whose tmux session was gone: %s",
+			len(recovered), strings.Join(recovered, ", ")))
+	}
+
+	cmdExec := cmd2.MakeExecutor()
+	live, err := tmux.ListSessions(cmdExec)
+	if err != nil {
+		log.WarningLog.Printf("could not list tmux sessions: %v", err)
+		return cmd
+	}
+	orphaned := session.FindOrphanedSessions(live, instances)
+	if len(orphaned) == 0 {
+		return cmd
+	}
+
+	message := fmt.Sprintf("[!] Found %d tmux session(s) with no matching instance: %s. Kill them?",
+		len(orphaned), strings.Join(orphaned, ", "))
+	return tea.Batch(cmd, m.confirmAction(message, func() tea.Msg {
+		for _, name := range orphaned {
+			if err := tmux.KillSession(cmdExec, name); err != nil {
+				log.ErrorLog.Print(err)
+			}
+		}
+		return nil
+	}))
+}
+
+// setStripCommandSlash makes instance send the known slash commands without their slash, unless its program keeps
+// them.
+func (m *home) setStripCommandSlash(instance *session.Instance) {
+	if m.appConfig.KeepCommandSlash(instance.ProgramName()) {
+		instance.SetStripCommandSlash(nil)
+		return
+	}
+	instance.SetStripCommandSlash(m.isSlashCommand)
+}
+
+// isSlashCommand reports whether command, like "/commit", is one of the commands offered by the autocompleter.
+func (m *home) isSlashCommand(command string) bool {
+	if m.autocompleter == nil {
+		return false
+	}
+	for _, suggestion := range m.autocompleter.GetSuggestions(command) {
+		if suggestion.Value == command {
+			return true
+		}
+	}
+	return false
+}
+
+// loadRepoFiles loads the per-repo hotkeys, templates, prompt templates, prompt history and Claude commands of the repository
+// containing dir, so they are found when claude-squad is started in a subdirectory. Outside a repository they are
+// loaded from dir. It returns the errors of files that couldn't be parsed.
+func (m *home) loadRepoFiles(dir string) error {
+	root := git.RepoRootOrDir(dir)
+	m.repoConfigDir = config.RepoConfigDir(root)
+	var hotkeysErr, templatesErr, promptTemplatesErr error
+	m.hotkeys, hotkeysErr = config.ReadHotkeys(root)
+	m.templates, templatesErr = config.ReadTemplates(root)
+	m.promptTemplates, promptTemplatesErr = config.ReadPromptTemplates(root)
+	m.promptHistory = config.LoadPromptHistory(root)
+	m.commandUsage = config.LoadCommandUsage(root)
+	autocompleter := autocomplete.NewClaudeCommandsAutocompleter(root)
+	autocompleter.SetUsage(m.commandUsage)
+	m.autocompleter = autocompleter
+	return errors.Join(hotkeysErr, templatesErr, promptTemplatesErr)
+}
+
+// rememberPrompt adds a prompt that is sent to the prompt history and counts the slash command it starts with.
+func (m *home) rememberPrompt(prompt string) {
+	if m.promptHistory != nil {
+		if err := m.promptHistory.Add(prompt); err != nil {
+			log.WarningLog.Printf("failed to save prompt history: %v", err)
+		}
+	}
+	m.countCommand(prompt)
+}
+
+// countCommand counts the slash command that a prompt that is sent starts with, so autocomplete offers the most
+// used commands first.
+func (m *home) countCommand(prompt string) {
+	if m.commandUsage == nil {
+		return
+	}
+	if err := m.commandUsage.Record(prompt, time.Now()); err != nil {
+		log.WarningLog.Printf("failed to save command usage: %v", err)
+	}
+}
+
+// newPromptOverlay creates the overlay used to enter a prompt for an instance.
+func (m *home) newPromptOverlay() *overlay.AutocompleteInputOverlay {
+	promptOverlay := overlay.NewAutocompleteInputOverlay("Enter prompt", "", m.autocompleter)
+	promptOverlay.SetSubmitKey(m.appConfig.PromptSubmitKey)
+	if m.promptHistory != nil {
+		promptOverlay.SetHistory(m.promptHistory.Entries())
+	}
+	return promptOverlay
+}
+
 // updateHandleWindowSizeEvent sets the sizes of the components.
 // The components will try to render inside their bounds.
 func (m *home) updateHandleWindowSizeEvent(msg tea.WindowSizeMsg) {
+	// The layout is computed for at least the minimum size, so no pane gets a zero or negative size. A smaller
+	// terminal shows a message instead of the layout until it is resized.
+	width, height := max(msg.Width, minWindowWidth), max(msg.Height, minWindowHeight)
+
 	// List takes 30% of width, preview takes 70%
-	listWidth := int(float32(msg.Width) * 0.3)
-	tabsWidth := msg.Width - listWidth
+	listWidth := int(float32(width) * 0.3)
+	tabsWidth := width - listWidth
 
 	// Menu takes 10% of height, list and window take 90%
-	contentHeight := int(float32(msg.Height) * 0.9)
-	menuHeight := msg.Height - contentHeight - 1     // minus 1 for error box
-	m.errBox.SetSize(int(float32(msg.Width)*0.9), 1) // error box takes 1 row
+	contentHeight := int(float32(height) * 0.9)
+	menuHeight := height - contentHeight - 1     // minus 1 for error box
+	m.errBox.SetSize(int(float32(width)*0.9), 1) // error box takes 1 row
 
 	m.tabbedWindow.SetSize(tabsWidth, contentHeight)
 	m.list.SetSize(listWidth, contentHeight)
 
+	m.windowWidth, m.windowHeight = msg.Width, msg.Height
+	m.resizeOverlays()
+
+	previewWidth, previewHeight := m.tabbedWindow.GetPreviewSize()
+	if err := m.list.SetSessionPreviewSize(previewWidth, previewHeight); err != nil {
+		log.ErrorLog.Print(err)
+	}
+	m.menu.SetSize(width, menuHeight)
+}
+
+const (
+	// minWindowWidth and minWindowHeight are the smallest terminal the layout is rendered in. Below them, View
+	// only says that the terminal is too small.
+	minWindowWidth  = 40
+	minWindowHeight = 10
+)
+
+// tooSmall returns whether the terminal is smaller than the layout needs. It is false until the size is known.
+func (m *home) tooSmall() bool {
+	if m.windowWidth == 0 && m.windowHeight == 0 {
+		return false
+	}
+	return m.windowWidth < minWindowWidth || m.windowHeight < minWindowHeight
+}
+
+// tooSmallView is shown in place of the layout while the terminal is too small, cut to fit it.
+func (m *home) tooSmallView() string {
+	message := fmt.Sprintf("Terminal too small (%dx%d). Resize to at least %dx%d.",
+		m.windowWidth, m.windowHeight, minWindowWidth, minWindowHeight)
+	width, height := max(m.windowWidth, 1), max(m.windowHeight, 1)
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center,
+		lipgloss.NewStyle().Width(width).MaxHeight(height).Align(lipgloss.Center).Render(message))
+}
+
+// resizeOverlays sizes the open overlays to the terminal. View centers them with PlaceOverlay.
+func (m *home) resizeOverlays() {
+	if m.windowWidth == 0 || m.windowHeight == 0 {
+		return
+	}
+	// Overlays aren't shown in a terminal that is too small, but are still sized for the smallest layout.
+	width, height := max(m.windowWidth, minWindowWidth), max(m.windowHeight, minWindowHeight)
 	if m.textInputOverlay != nil {
-		m.textInputOverlay.SetSize(int(float32(msg.Width)*0.6), int(float32(msg.Height)*0.4))
+		m.textInputOverlay.SetSize(int(float32(width)*0.6), int(float32(height)*0.4))
 	}
 	if m.autocompleteInputOverlay != nil {
-		m.autocompleteInputOverlay.SetSize(int(float32(msg.Width)*0.6), int(float32(msg.Height)*0.4))
+		m.autocompleteInputOverlay.SetSize(int(float32(width)*0.6), int(float32(height)*0.4))
 	}
 	if m.textOverlay != nil {
-		m.textOverlay.SetWidth(int(float32(msg.Width) * 0.6))
+		m.textOverlay.SetWidth(int(float32(width) * 0.6))
 	}
-
-	previewWidth, previewHeight := m.tabbedWindow.GetPreviewSize()
-	if err := m.list.SetSessionPreviewSize(previewWidth, previewHeight); err != nil {
-		log.ErrorLog.Print(err)
+	if m.activityOverlay != nil {
+		m.activityOverlay.SetSize(int(float32(width)*0.6), int(float32(height)*0.6))
+	}
+	if m.keysOverlay != nil {
+		m.keysOverlay.SetSize(width, height)
+	}
+	if m.templatePicker != nil {
+		m.templatePicker.SetSize(int(float32(width)*0.4), int(float32(height)*0.6))
+	}
+	if m.searchPicker != nil {
+		m.searchPicker.SetSize(int(float32(width)*0.6), int(float32(height)*0.6))
+	}
+	if m.promptTemplatePicker != nil {
+		m.promptTemplatePicker.SetSize(int(float32(width)*0.4), int(float32(height)*0.6))
 	}
-	m.menu.SetSize(msg.Width, menuHeight)
+	if m.exitChoicePicker != nil {
+		m.exitChoicePicker.SetSize(int(float32(width)*0.4), int(float32(height)*0.6))
+	}
+	if m.forwardPromptPicker != nil {
+		m.forwardPromptPicker.SetSize(int(float32(width)*0.4), int(float32(height)*0.6))
+	}
+	if m.checkpointPicker != nil {
+		m.checkpointPicker.SetSize(int(float32(width)*0.6), int(float32(height)*0.6))
+	}
+	if m.switcher != nil {
+		m.switcher.SetSize(int(float32(width)*0.5), int(float32(height)*0.6))
+	}
+	if m.promptForm != nil {
+		m.promptForm.SetSize(int(float32(width)*0.6), height)
+	}
+	if m.confirmationOverlay != nil {
+		m.confirmationOverlay.SetWidth(confirmationWidth(width))
+	}
+}
+
+const (
+	// defaultConfirmationWidth is the width of the confirmation dialog before the terminal size is known.
+	defaultConfirmationWidth = 50
+	maxConfirmationWidth     = 70
+)
+
+// confirmationWidth returns the width of the confirmation dialog in a terminal that is termWidth wide. It takes
+// 40% of the terminal, at least the default width if it fits and at most maxConfirmationWidth.
+func confirmationWidth(termWidth int) int {
+	width := min(max(termWidth*2/5, defaultConfirmationWidth), maxConfirmationWidth)
+	// The border takes one column on each side.
+	return max(min(width, termWidth-2), 1)
 }
 
 func (m *home) Init() tea.Cmd {
+	m.previewInterval = m.appConfig.PreviewInterval()
 	// Upon starting, we want to start the spinner. Whenever we get a spinner.TickMsg, we
 	// update the spinner, which sends a new spinner.TickMsg. I think this lasts forever lol.
+	// The spinner ticks on its own, so it stays smooth however slowly the preview refreshes.
 	return tea.Batch(
 		m.spinner.Tick,
-		func() tea.Msg {
-			time.Sleep(100 * time.Millisecond)
-			return previewTickMsg{}
-		},
+		previewTickCmd(m.previewInterval),
 		tickUpdateMetadataCmd,
+		m.startupCmd,
 	)
 }
 
@@ -213,44 +714,139 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case hideErrMsg:
 		m.errBox.Clear()
+	case commandEditedMsg:
+		return m, m.commandEdited(msg)
+	case externalAttachMsg:
+		return m, m.externalAttached(msg)
+	case instancePushedMsg:
+		delete(m.pushing, msg.instance)
+		if msg.err != nil {
+			return m, m.handleError(fmt.Errorf("failed to push '%s': %w", msg.instance.Title, msg.err))
+		}
+		m.gitResult = fmt.Sprintf("Pushed '%s' to %s", msg.instance.Title, msg.branch)
+		return m, hideGitResultCmd(m.ctx, m.gitResult)
+	case pushSummaryMsg:
+		if msg.err != nil {
+			return m, m.handleError(fmt.Errorf("failed to push '%s': %w", msg.instance.Title, msg.err))
+		}
+		if msg.stats.IsEmpty() {
+			return m, m.handleError(fmt.Errorf("no changes to push from '%s'", msg.instance.Title))
+		}
+		if m.state != stateDefault || m.pushing[msg.instance] || m.rebasing[msg.instance] != "" {
+			// Something else was opened, or the key was pressed twice, while the diff was computed.
+			return m, nil
+		}
+		if !m.shouldConfirm(config.ConfirmPush) {
+			return m, m.pushInstance(msg.instance, msg.amend)
+		}
+
+		// Store the instance for async push after confirmation
+		m.pendingPushInstance = msg.instance
+		m.pendingPushAmend = msg.amend
+
+		// Show confirmation modal
+		return m, m.showConfirmation(pushConfirmMessage(msg.instance.Title, msg.stats, msg.amend))
+	case diffExportedMsg:
+		if msg.err != nil {
+			return m, m.handleError(msg.err)
+		}
+		m.gitResult = msg.result
+		return m, hideGitResultCmd(m.ctx, m.gitResult)
+	case autoCommittedMsg:
+		m.autoCommitting = false
+		return m, nil
+	case bulkDoneMsg:
+		m.bulkRunning = false
+		m.setInBackground(msg.instances, false)
+		msg.result.Finish()
+		// Persist right away so a restart reflects the bulk change.
+		if err := m.saveInstances(); err != nil {
+			return m, m.handleError(err)
+		}
+		return m, tea.Batch(tea.WindowSize(), m.instanceChanged(), m.handleError(fmt.Errorf("%s", msg.result.Summary())))
+	case autoPausedMsg:
+		m.autoPausing = false
+		m.setInBackground(msg.paused, false)
+		m.setInBackground(msg.failed, false)
+		for _, instance := range msg.paused {
+			instance.MarkPaused()
+		}
+		for _, instance := range msg.failed {
+			instance.MarkActive(msg.at)
+		}
+		if err := m.saveInstances(); err != nil {
+			log.ErrorLog.Printf("failed to save instances after auto-pausing: %v", err)
+		}
+		return m, nil
+	case instanceCheckpointedMsg:
+		if msg.err != nil {
+			return m, m.handleError(fmt.Errorf("failed to checkpoint '%s': %w", msg.instance.Title, msg.err))
+		}
+		m.gitResult = fmt.Sprintf("Checkpointed '%s' on %s", msg.instance.Title, msg.branch)
+		return m, hideGitResultCmd(m.ctx, m.gitResult)
+	case instanceRebasedMsg:
+		delete(m.rebasing, msg.instance)
+		msg.instance.RecheckConflicts()
+		var conflictErr *git.RebaseConflictError
+		if errors.As(msg.err, &conflictErr) {
+			return m, m.handleError(fmt.Errorf("'%s': %w", msg.instance.Title, msg.err))
+		}
+		if msg.err != nil {
+			return m, m.handleError(fmt.Errorf("failed to rebase '%s': %w", msg.instance.Title, msg.err))
+		}
+		// The instance now tracks the branch it was rebased onto.
+		if err := m.saveInstances(); err != nil {
+			return m, m.handleError(err)
+		}
+		m.gitResult = fmt.Sprintf("Rebased '%s' onto %s", msg.instance.Title, msg.onto)
+		return m, tea.Batch(hideGitResultCmd(m.ctx, m.gitResult), m.instanceChanged())
+	case quitPausedMsg:
+		if msg.err != nil {
+			// Quitting goes on, leaving the instance running like it would without pausing.
+			log.ErrorLog.Printf("failed to pause '%s' before quitting: %v", msg.instances[msg.index].Title, msg.err)
+		} else {
+			msg.instances[msg.index].MarkPaused()
+		}
+		if next := msg.index + 1; next < len(msg.instances) {
+			m.quitProgress = quitPauseProgress(msg.instances, next)
+			return m, pauseOnQuitCmd(msg.instances, next)
+		}
+		return m.quit()
+	case hideGitResultMsg:
+		if m.gitResult == msg.result {
+			m.gitResult = ""
+		}
 	case previewTickMsg:
 		cmd := m.instanceChanged()
-		return m, tea.Batch(
-			cmd,
-			func() tea.Msg {
-				time.Sleep(100 * time.Millisecond)
-				return previewTickMsg{}
-			},
-		)
+		return m, tea.Batch(cmd, previewTickCmd(m.previewInterval))
 	case keyupMsg:
 		m.menu.ClearKeydown()
 		return m, nil
 	case tickUpdateMetadataMessage:
+		now := time.Now()
+		selected := m.list.GetSelectedInstance()
 		for _, instance := range m.list.GetInstances() {
-			if !instance.Started() || instance.Paused() {
+			if m.inBackground[instance] {
 				continue
 			}
-			updated, prompt := instance.HasUpdated()
-			if updated {
-				instance.SetStatus(session.Running)
-			} else {
-				if prompt {
-					instance.TapEnter()
-				} else {
-					instance.SetStatus(session.Ready)
-				}
-			}
-			if err := instance.UpdateDiffStats(); err != nil {
-				log.WarningLog.Printf("could not update diff stats: %v", err)
-			}
+			// A pinned instance is in view as much as the selected one.
+			updateInstanceMetadata(instance, m.autoYesMatcher, instance == selected || instance == m.pinned, now)
+		}
+		autoPause := m.autoPauseIdle(now)
+		autoCommit := m.autoCommit(now)
+		if m.statusServer != nil {
+			m.statusServer.publish(session.Summarize(m.list.GetInstances()))
 		}
-		return m, tickUpdateMetadataCmd
+		return m, tea.Batch(tickUpdateMetadataCmd, m.openResumed(now), autoPause, autoCommit)
 	case tea.MouseMsg:
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft && m.state == stateDefault {
+			return m, m.handleMouseClick(msg.X, msg.Y)
+		}
 		// Handle mouse wheel events for scrolling the diff/preview pane
 		if msg.Action == tea.MouseActionPress {
 			if msg.Button == tea.MouseButtonWheelDown || msg.Button == tea.MouseButtonWheelUp {
-				selected := m.list.GetSelectedInstance()
-				if selected == nil || selected.Status == session.Paused {
+				shown := m.shownInstance()
+				if shown == nil || shown.Status == session.Paused {
 					return m, nil
 				}
 
@@ -286,25 +882,62 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Successfully deleted - remove from list
 		m.list.RemoveInstance(msg.instance)
 		return m, m.instanceChanged()
+	case confirmTickMsg:
+		// The tick belongs to an overlay that was already answered or replaced, so the timer stops here.
+		if m.state != stateConfirm || m.confirmationOverlay != msg.overlay {
+			return m, nil
+		}
+		if msg.overlay.TimedOut() {
+			return m, m.dismissConfirmation(false)
+		}
+		return m, m.confirmTickCmd(msg.overlay)
+	case instanceRestartedMsg:
+		if msg.err != nil {
+			// Restart failed - revert status and show error. The next metadata tick corrects it if the
+			// session turns out to be alive.
+			msg.instance.SetStatus(session.Dead)
+			return m, m.handleError(msg.err)
+		}
+		return m, tea.Batch(tea.WindowSize(), m.instanceChanged())
+	case instanceUnarchivedMsg:
+		if msg.err != nil {
+			return m, m.handleError(msg.err)
+		}
+		if err := m.saveInstances(); err != nil {
+			return m, m.handleError(err)
+		}
+		return m, tea.Batch(tea.WindowSize(), m.instanceChanged())
 	case instanceProgressMsg:
 		// Update progress message and continue listening
-		m.initProgressMessage = msg.progress.Message
-		return m, listenForProgressCmd(msg.instance, msg.channel, msg.finalizer, msg.promptAfterName)
+		m.initProgressMessage = formatInitProgress(msg.progress)
+		listen := listenForProgressCmd(msg.instance, msg.channel, msg.finalizer, msg.promptAfterName)
+		if msg.progress.Warning != nil {
+			return m, tea.Batch(listen, m.handleError(msg.progress.Warning))
+		}
+		return m, listen
 	case instanceStartCompleteMsg:
 		// Clear progress message
 		m.initProgressMessage = ""
 
+		if cancel, ok := m.startCancels[msg.instance]; ok {
+			cancel()
+			delete(m.startCancels, msg.instance)
+		} else if m.startCancels != nil {
+			// The start was cancelled and the instance already removed from the list.
+			if msg.err == nil {
+				// It finished before it noticed the cancellation, so tear it down now.
+				return m, killInstanceCmd(msg.instance)
+			}
+			return m, nil
+		}
+
 		if msg.err != nil {
 			// Find and remove the failed instance
-			for i, inst := range m.list.GetInstances() {
-				if inst == msg.instance {
-					m.list.SetSelectedInstance(i)
-					m.list.Kill()
-					break
-				}
+			if m.list.SelectInstance(msg.instance) {
+				m.list.Kill()
 			}
-			// Clear pending prompt on error
-			m.pendingPrompt = ""
+			// Drop the instance's pending prompt on error
+			delete(m.pendingPrompts, msg.instance)
 			// Close prompt overlay if open
 			if m.state == statePrompt {
 				m.autocompleteInputOverlay = nil
@@ -315,7 +948,7 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Save after adding new instance
-		if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
+		if err := m.saveInstances(); err != nil {
 			return m, m.handleError(err)
 		}
 
@@ -326,16 +959,20 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.autoYes {
 			msg.instance.AutoYes = true
 		}
+		msg.instance.SetDiffOptions(m.diffOptions)
 
-		// Send pending prompt if user submitted while instance was initializing
-		if m.pendingPrompt != "" {
-			prompt := m.pendingPrompt
-			m.pendingPrompt = ""
+		// Send the template's init prompts and the prompt the user submitted while the instance was initializing
+		prompts := msg.instance.InitPrompts
+		msg.instance.InitPrompts = nil
+		if prompt := m.takePendingPrompt(msg.instance); prompt != "" {
+			prompts = append(prompts, prompt)
+		}
+		if len(prompts) > 0 {
 			// Use async command to wait for input ready before sending
 			return m, tea.Batch(
 				tea.WindowSize(),
 				m.instanceChanged(),
-				sendPendingPromptCmd(msg.instance, prompt),
+				sendPendingPromptCmd(msg.instance, prompts, time.Duration(m.appConfig.PromptReadyTimeout)*time.Second),
 			)
 		} else if m.state == statePrompt {
 			// Prompt overlay is still open, user is still typing - do nothing
@@ -343,7 +980,7 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Legacy path (shouldn't happen with new flow)
 			m.state = statePrompt
 			m.menu.SetState(ui.StatePrompt)
-			m.autocompleteInputOverlay = overlay.NewAutocompleteInputOverlay("Enter prompt", "", m.autocompleter)
+			m.autocompleteInputOverlay = m.newPromptOverlay()
 		} else {
 			m.showHelpScreen(helpStart(msg.instance), nil)
 		}
@@ -365,12 +1002,123 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *home) handleQuit() (tea.Model, tea.Cmd) {
-	if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
+	if m.appConfig != nil && m.appConfig.ConfirmQuitWithChanges {
+		if changed := session.WithChanges(m.list.GetInstances()); len(changed) > 0 {
+			m.pendingQuitWithChanges = true
+			return m, m.showConfirmation(quitWithChangesMessage(changed, m.quitMode))
+		}
+	}
+	return m.planQuit()
+}
+
+// maxQuitSummaryInstances is the number of instances listed in the quit confirmation. The rest are counted.
+const maxQuitSummaryInstances = 5
+
+// quitWithChangesMessage asks whether to quit although the given instances have changes, listing the first ones
+// with their diff stats. It says what quitting in mode, one of config.QuitModes, does with them.
+func quitWithChangesMessage(changed []*session.Instance, mode string) string {
+	outcome := "they keep running"
+	switch mode {
+	case config.QuitPauseAll:
+		outcome = "running ones are paused"
+	case config.QuitAsk:
+		outcome = "you'll be asked whether to pause the running ones"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "[!] Quit? %d session(s) have changes, %s:\n", len(changed), outcome)
+	for i, instance := range changed {
+		if i == maxQuitSummaryInstances {
+			fmt.Fprintf(&b, "\n  and %d more", len(changed)-i)
+			break
+		}
+		stats := instance.GetDiffStats()
+		fmt.Fprintf(&b, "\n  '%s' +%d -%d", instance.Title, stats.Added, stats.Removed)
+	}
+	return b.String()
+}
+
+// planQuit quits as the quit mode says, pausing the running instances or asking whether to first.
+func (m *home) planQuit() (tea.Model, tea.Cmd) {
+	pausable := session.PausableOnQuit(m.list.GetInstances())
+	switch planQuit(m.quitMode, len(pausable)) {
+	case quitPause:
+		return m, m.pauseOnQuit(pausable)
+	case quitAskToPause:
+		// Store the instances for pausing after confirmation. Cancelling doesn't quit.
+		m.pendingQuitPause = pausable
+		return m, m.showConfirmation(fmt.Sprintf(
+			"[!] Pause %d running session(s) and quit? Cancel to keep claude-squad open.", len(pausable)))
+	}
+	return m.quit()
+}
+
+// quit saves the instances and quits. If they can't be saved, the error is shown instead.
+func (m *home) quit() (tea.Model, tea.Cmd) {
+	if err := m.saveInstances(); err != nil {
+		m.state = stateDefault
+		m.quitProgress = ""
 		return m, m.handleError(err)
 	}
 	return m, tea.Quit
 }
 
+// quitPlan is what quitting does with the running instances.
+type quitPlan int
+
+const (
+	// quitLeaveRunning quits right away, leaving the instances running.
+	quitLeaveRunning quitPlan = iota
+	// quitPause pauses the instances and then quits.
+	quitPause
+	// quitAskToPause asks whether to pause the instances before quitting.
+	quitAskToPause
+)
+
+// planQuit decides what quitting in mode, one of config.QuitModes, does with the given number of instances that can
+// be paused. Without any there is nothing to pause or ask about.
+func planQuit(mode string, pausable int) quitPlan {
+	if pausable == 0 {
+		return quitLeaveRunning
+	}
+	switch mode {
+	case config.QuitPauseAll:
+		return quitPause
+	case config.QuitAsk:
+		return quitAskToPause
+	default:
+		return quitLeaveRunning
+	}
+}
+
+// pauseOnQuit starts pausing instances one at a time, showing the progress in the status line. It quits once they
+// are all paused.
+func (m *home) pauseOnQuit(instances []*session.Instance) tea.Cmd {
+	m.state = stateQuitting
+	m.quitProgress = quitPauseProgress(instances, 0)
+	m.setInBackground(instances, true)
+	return pauseOnQuitCmd(instances, 0)
+}
+
+// quitPauseProgress describes pausing the instance at index of instances before quitting.
+func quitPauseProgress(instances []*session.Instance, index int) string {
+	return fmt.Sprintf("Pausing '%s' before quitting (%d/%d), ctrl+c to quit now...", instances[index].Title, index+1,
+		len(instances))
+}
+
+// pauseOnQuitCmd pauses the instance at index of instances in the background. The instance is marked paused when
+// quitPausedMsg is handled.
+func pauseOnQuitCmd(instances []*session.Instance, index int) tea.Cmd {
+	instance := instances[index]
+	if err := instance.CanPause(); err != nil {
+		return func() tea.Msg {
+			return quitPausedMsg{instances: instances, index: index, err: err}
+		}
+	}
+	return func() tea.Msg {
+		return quitPausedMsg{instances: instances, index: index, err: instance.PauseResources()}
+	}
+}
+
 func (m *home) handleMenuHighlighting(msg tea.KeyMsg) (cmd tea.Cmd, returnEarly bool) {
 	// Handle menu highlighting when you press a button. We intercept it here and immediately return to
 	// update the ui while re-sending the keypress. Then, on the next call to this, we actually handle the keypress.
@@ -378,7 +1126,13 @@ func (m *home) handleMenuHighlighting(msg tea.KeyMsg) (cmd tea.Cmd, returnEarly
 		m.keySent = false
 		return nil, false
 	}
-	if m.state == statePrompt || m.state == stateHelp || m.state == stateConfirm {
+	if m.state == statePrompt || m.state == stateHelp || m.state == stateConfirm || m.state == stateActivity ||
+		m.state == stateTemplate || m.state == stateRemote || m.state == stateNote || m.state == stateColor ||
+		m.state == stateGroup || m.state == stateKeys ||
+		m.state == stateSearch || m.state == stateSearchResults || m.state == stateRepoPath ||
+		m.state == statePromptTemplate || m.state == statePromptForm || m.state == stateSaveDiff ||
+		m.state == stateExitChoice || m.state == stateForwardPrompt || m.state == stateSwitcher ||
+		m.state == stateTmuxSessions || m.state == stateCheckpoints || m.state == stateQuitting {
 		return nil, false
 	}
 	// If it's in the global keymap, we should try to highlight it.
@@ -387,10 +1141,24 @@ func (m *home) handleMenuHighlighting(msg tea.KeyMsg) (cmd tea.Cmd, returnEarly
 		return nil, false
 	}
 
-	if m.list.GetSelectedInstance() != nil && m.list.GetSelectedInstance().Paused() && name == keys.KeyEnter {
+	if selected := m.list.GetSelectedInstance(); selected != nil && (selected.Paused() || selected.Archived()) &&
+		name == keys.KeyEnter {
+		return nil, false
+	}
+	if name == keys.KeyShiftDown || name == keys.KeyShiftUp || name == keys.KeyShiftLeft || name == keys.KeyShiftRight {
 		return nil, false
 	}
-	if name == keys.KeyShiftDown || name == keys.KeyShiftUp {
+	// The diff file, refresh and bulk keys are not shown in the menu.
+	switch name {
+	case keys.KeyDiffFiles, keys.KeyNextFile, keys.KeyPrevFile, keys.KeyNextHunk, keys.KeyPrevHunk,
+		keys.KeyDiffWhitespace, keys.KeyDiffMode,
+		keys.KeyRefresh, keys.KeyPauseAll, keys.KeyResumeAll, keys.KeyActivity,
+		keys.KeyCopyBranch, keys.KeyCopyPath, keys.KeyCompact, keys.KeyPrevTab, keys.KeyTemplate,
+		keys.KeyRemote, keys.KeyShowArchived, keys.KeySendPrompt, keys.KeyInterrupt,
+		keys.KeyNote, keys.KeyResumeOpen, keys.KeySort, keys.KeyResend, keys.KeyRebase, keys.KeySearch,
+		keys.KeyNewInRepo, keys.KeyColor, keys.KeyGroup, keys.KeyFold, keys.KeyCheckpoint, keys.KeyObserve,
+		keys.KeyPin, keys.KeyPromptTemplate, keys.KeyCopyDiff, keys.KeySaveDiff, keys.KeyAutoYes,
+		keys.KeyNewInPlace, keys.KeyForwardPrompt, keys.KeySwitch, keys.KeyTmuxSessions, keys.KeyCheckpoints:
 		return nil, false
 	}
 
@@ -411,68 +1179,293 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		return m, cmd
 	}
 
+	if m.state == stateQuitting {
+		// ctrl+c quits right away, leaving the instances that aren't paused yet running.
+		if msg.String() == "ctrl+c" {
+			return m.quit()
+		}
+		return m, nil
+	}
+
 	if m.state == stateHelp {
 		return m.handleHelpState(msg)
 	}
 
-	if m.state == stateNew {
-		// Handle quit commands first. Don't handle q because the user might want to type that.
-		if msg.String() == "ctrl+c" {
+	if m.state == stateActivity {
+		if m.activityOverlay.HandleKeyPress(msg) {
+			m.activityOverlay = nil
 			m.state = stateDefault
-			m.promptAfterName = false
-			m.list.Kill()
-			return m, tea.Sequence(
-				tea.WindowSize(),
-				func() tea.Msg {
-					m.menu.SetState(ui.StateDefault)
-					return nil
-				},
-			)
+			return m, tea.WindowSize()
 		}
+		return m, nil
+	}
 
-		instance := m.list.GetInstances()[m.list.NumInstances()-1]
-		switch msg.Type {
-		// Start the instance asynchronously and go back to the main menu state.
-		case tea.KeyEnter:
-			if len(instance.Title) == 0 {
-				return m, m.handleError(fmt.Errorf("title cannot be empty"))
-			}
-
-			// Set loading state
-			instance.SetStatus(session.Loading)
-
-			// Capture state before clearing
-			finalizer := m.newInstanceFinalizer
-			promptAfterName := m.promptAfterName
-			m.promptAfterName = false
-			m.pendingPrompt = ""
-			m.initProgressMessage = "Starting..."
-
-			// If prompt after name, show overlay immediately while instance initializes
-			if promptAfterName {
-				m.state = statePrompt
-				m.menu.SetState(ui.StatePrompt)
-				m.autocompleteInputOverlay = overlay.NewAutocompleteInputOverlay("Enter prompt", "", m.autocompleter)
-				// Start async initialization and trigger window resize to size the overlay
-				return m, tea.Batch(startInstanceCmd(instance, finalizer, false), tea.WindowSize())
-			}
+	if m.state == stateTmuxSessions {
+		return m, m.handleTmuxSessions(msg)
+	}
 
+	if m.state == stateKeys {
+		if m.keysOverlay.HandleKeyPress(msg) {
+			m.keysOverlay = nil
 			m.state = stateDefault
 			m.menu.SetState(ui.StateDefault)
-			// Start async initialization (pass false for promptAfterName since we handle it above)
-			return m, startInstanceCmd(instance, finalizer, false)
-		case tea.KeyRunes:
-			if len(instance.Title) >= 32 {
-				return m, m.handleError(fmt.Errorf("title cannot be longer than 32 characters"))
-			}
-			if err := instance.SetTitle(instance.Title + string(msg.Runes)); err != nil {
+			return m, tea.WindowSize()
+		}
+		return m, nil
+	}
+
+	if m.state == stateTemplate {
+		if !m.templatePicker.HandleKeyPress(msg) {
+			return m, nil
+		}
+		picker := m.templatePicker
+		m.templatePicker = nil
+		m.state = stateDefault
+		if !picker.Submitted {
+			return m, tea.WindowSize()
+		}
+		return m, tea.Batch(tea.WindowSize(), m.newInstanceFromTemplate(picker.Selected()))
+	}
+
+	if m.state == statePromptTemplate {
+		return m, m.handlePromptTemplatePicker(msg)
+	}
+
+	if m.state == statePromptForm {
+		return m, m.handlePromptForm(msg)
+	}
+
+	if m.state == stateExitChoice {
+		return m, m.handleExitChoice(msg)
+	}
+
+	if m.state == stateForwardPrompt {
+		return m, m.handleForwardPrompt(msg)
+	}
+
+	if m.state == stateSwitcher {
+		return m, m.handleSwitcher(msg)
+	}
+
+	if m.state == stateCheckpoints {
+		return m, m.handleCheckpoints(msg)
+	}
+
+	if m.state == stateRepoPath {
+		if !m.textInputOverlay.HandleKeyPress(msg) {
+			return m, nil
+		}
+		input := m.textInputOverlay
+		m.textInputOverlay = nil
+		m.state = stateDefault
+		if !input.IsSubmitted() {
+			return m, tea.WindowSize()
+		}
+		path, err := session.ResolveRepoPath(input.GetValue())
+		if err != nil {
+			// Ask again with what was entered, so a typo can be fixed.
+			m.openRepoPathInput(input.GetValue())
+			return m, m.handleError(err)
+		}
+		if err := m.addNewInstance(session.InstanceOptions{Path: path, Program: m.program}); err != nil {
+			return m, tea.Batch(tea.WindowSize(), m.handleError(err))
+		}
+		return m, tea.WindowSize()
+	}
+
+	if m.state == stateSearch {
+		if !m.textInputOverlay.HandleKeyPress(msg) {
+			return m, nil
+		}
+		input := m.textInputOverlay
+		m.textInputOverlay = nil
+		m.state = stateDefault
+		if !input.IsSubmitted() || strings.TrimSpace(input.GetValue()) == "" {
+			return m, tea.WindowSize()
+		}
+		return m, tea.Batch(tea.WindowSize(), m.searchOutput(input.GetValue()))
+	}
+
+	if m.state == stateSearchResults {
+		if !m.searchPicker.HandleKeyPress(msg) {
+			return m, nil
+		}
+		picker := m.searchPicker
+		matches := m.searchMatches
+		m.searchPicker = nil
+		m.searchMatches = nil
+		m.state = stateDefault
+		if !picker.Submitted {
+			return m, tea.WindowSize()
+		}
+		m.list.SelectInstance(matches[picker.SelectedIndex()].Instance)
+		return m, tea.Batch(tea.WindowSize(), m.instanceChanged())
+	}
+
+	if m.state == stateNote {
+		if !m.textInputOverlay.HandleKeyPress(msg) {
+			return m, nil
+		}
+		input := m.textInputOverlay
+		m.textInputOverlay = nil
+		m.state = stateDefault
+		selected := m.list.GetSelectedInstance()
+		if !input.IsSubmitted() || selected == nil {
+			return m, tea.WindowSize()
+		}
+		selected.Note = strings.TrimSpace(input.GetValue())
+		if err := m.saveInstances(); err != nil {
+			return m, tea.Batch(tea.WindowSize(), m.handleError(err))
+		}
+		return m, tea.Batch(tea.WindowSize(), m.instanceChanged())
+	}
+
+	if m.state == stateColor {
+		if !m.textInputOverlay.HandleKeyPress(msg) {
+			return m, nil
+		}
+		input := m.textInputOverlay
+		m.textInputOverlay = nil
+		m.state = stateDefault
+		selected := m.list.GetSelectedInstance()
+		if !input.IsSubmitted() || selected == nil {
+			return m, tea.WindowSize()
+		}
+		color, icon, err := session.ParseColorIcon(input.GetValue())
+		if err != nil {
+			// Ask again with what was entered, so a typo can be fixed.
+			m.openColorInput(selected, input.GetValue())
+			return m, m.handleError(err)
+		}
+		selected.Color, selected.Icon = color, icon
+		if err := m.saveInstances(); err != nil {
+			return m, tea.Batch(tea.WindowSize(), m.handleError(err))
+		}
+		return m, tea.Batch(tea.WindowSize(), m.instanceChanged())
+	}
+
+	if m.state == stateGroup {
+		if !m.textInputOverlay.HandleKeyPress(msg) {
+			return m, nil
+		}
+		input := m.textInputOverlay
+		m.textInputOverlay = nil
+		m.state = stateDefault
+		selected := m.list.GetSelectedInstance()
+		if !input.IsSubmitted() || selected == nil {
+			return m, tea.WindowSize()
+		}
+		selected.Group = strings.Join(strings.Fields(input.GetValue()), " ")
+		// The group may be collapsed, so keep the instance selected by expanding it.
+		m.list.SelectInstance(selected)
+		if err := m.saveInstances(); err != nil {
+			return m, tea.Batch(tea.WindowSize(), m.handleError(err))
+		}
+		return m, tea.Batch(tea.WindowSize(), m.instanceChanged())
+	}
+
+	if m.state == stateSaveDiff {
+		if !m.textInputOverlay.HandleKeyPress(msg) {
+			return m, nil
+		}
+		input := m.textInputOverlay
+		m.textInputOverlay = nil
+		m.state = stateDefault
+		selected := m.list.GetSelectedInstance()
+		if !input.IsSubmitted() || selected == nil {
+			return m, tea.WindowSize()
+		}
+		return m, tea.Batch(tea.WindowSize(), m.saveDiff(selected, input.GetValue()))
+	}
+
+	if m.state == stateRemote {
+		if !m.textInputOverlay.HandleKeyPress(msg) {
+			return m, nil
+		}
+		input := m.textInputOverlay
+		m.textInputOverlay = nil
+		m.state = stateDefault
+		if !input.IsSubmitted() {
+			return m, tea.WindowSize()
+		}
+		opts := session.InstanceOptions{Path: ".", Program: m.program, RemoteRef: strings.TrimSpace(input.GetValue())}
+		if err := m.addNewInstance(opts); err != nil {
+			return m, tea.Batch(tea.WindowSize(), m.handleError(err))
+		}
+		return m, tea.WindowSize()
+	}
+
+	if m.state == stateNew {
+		// Handle quit commands first. Don't handle q because the user might want to type that.
+		if msg.String() == "ctrl+c" {
+			m.state = stateDefault
+			m.promptAfterName = false
+			m.namePrompt = ""
+			m.list.Kill()
+			return m, tea.Sequence(
+				tea.WindowSize(),
+				func() tea.Msg {
+					m.menu.SetState(ui.StateDefault)
+					return nil
+				},
+			)
+		}
+
+		instances := m.list.GetInstances()
+		instance := instances[len(instances)-1]
+		switch msg.Type {
+		// Start the instance asynchronously and go back to the main menu state.
+		case tea.KeyEnter:
+			if len(instance.Title) == 0 {
+				// Ask for the prompt first and suggest a title from it.
+				if m.promptAfterName && !m.appConfig.DisableTitleFromPrompt {
+					m.titleFromPrompt = true
+					m.state = statePrompt
+					m.menu.SetState(ui.StatePrompt)
+					m.autocompleteInputOverlay = m.newPromptOverlay()
+					return m, tea.WindowSize()
+				}
+				return m, m.handleError(fmt.Errorf("title cannot be empty"))
+			}
+
+			// Set loading state
+			instance.SetStatus(session.Loading)
+
+			// Capture state before clearing
+			finalizer := m.newInstanceFinalizer
+			promptAfterName := m.promptAfterName
+			m.promptAfterName = false
+			m.setPendingPrompt(instance, m.namePrompt)
+			m.namePrompt = ""
+			m.initProgressMessage = "Starting..."
+
+			// If prompt after name, show overlay immediately while instance initializes
+			if promptAfterName {
+				m.state = statePrompt
+				m.menu.SetState(ui.StatePrompt)
+				m.autocompleteInputOverlay = m.newPromptOverlay()
+				// Start async initialization and trigger window resize to size the overlay
+				return m, tea.Batch(m.beginStart(instance, finalizer), tea.WindowSize())
+			}
+
+			m.state = stateDefault
+			m.menu.SetState(ui.StateDefault)
+			// Start async initialization (pass false for promptAfterName since we handle it above)
+			return m, m.beginStart(instance, finalizer)
+		case tea.KeyRunes:
+			// Count display width so wide characters (CJK, emoji) can't push the title past the list layout.
+			if maxWidth := m.appConfig.TitleLength(); runewidth.StringWidth(instance.Title+string(msg.Runes)) > maxWidth {
+				return m, m.handleError(fmt.Errorf("title cannot be longer than %d characters", maxWidth))
+			}
+			if err := instance.SetTitle(instance.Title + string(msg.Runes)); err != nil {
 				return m, m.handleError(err)
 			}
 		case tea.KeyBackspace:
-			if len(instance.Title) == 0 {
+			runes := []rune(instance.Title)
+			if len(runes) == 0 {
 				return m, nil
 			}
-			if err := instance.SetTitle(instance.Title[:len(instance.Title)-1]); err != nil {
+			if err := instance.SetTitle(string(runes[:len(runes)-1])); err != nil {
 				return m, m.handleError(err)
 			}
 		case tea.KeySpace:
@@ -482,6 +1475,7 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		case tea.KeyEsc:
 			m.list.Kill()
 			m.state = stateDefault
+			m.namePrompt = ""
 			m.instanceChanged()
 
 			return m, tea.Sequence(
@@ -505,24 +1499,32 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			if selected == nil {
 				return m, nil
 			}
+			if m.titleFromPrompt {
+				return m, m.handleTitleFromPrompt(selected)
+			}
+			if m.promptToExisting {
+				return m, m.handlePromptToExisting(selected)
+			}
 			if m.autocompleteInputOverlay.IsSubmitted() {
 				prompt := m.autocompleteInputOverlay.GetValue()
+				m.rememberPrompt(prompt)
 				// Try to send prompt - if instance not ready yet, store as pending
 				if err := selected.SendPrompt(prompt); err != nil {
 					// Instance not ready yet, store prompt for later
-					m.pendingPrompt = prompt
+					m.setPendingPrompt(selected, prompt)
 				}
 			}
 
 			// Close the overlay and reset state
 			m.autocompleteInputOverlay = nil
 			m.state = stateDefault
+			_, pending := m.pendingPrompts[selected]
 			return m, tea.Sequence(
 				tea.WindowSize(),
 				func() tea.Msg {
 					m.menu.SetState(ui.StateDefault)
 					// Only show help screen if instance is ready (no pending prompt)
-					if m.pendingPrompt == "" {
+					if !pending {
 						m.showHelpScreen(helpStart(selected), nil)
 					}
 					return nil
@@ -530,6 +1532,9 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			)
 		}
 
+		if path := m.autocompleteInputOverlay.TakeEditPath(); path != "" {
+			return m, m.editCommandFile(path)
+		}
 		return m, nil
 	}
 
@@ -540,53 +1545,35 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		cancelled := keyStr == "n" || keyStr == "esc"
 
 		if confirmed || cancelled {
-			m.state = stateDefault
-			overlay := m.confirmationOverlay
-			m.confirmationOverlay = nil
-
-			// Handle kill confirmation (async)
-			if confirmed && m.pendingKillInstance != nil {
-				instance := m.pendingKillInstance
-				m.pendingKillInstance = nil
-
-				// Mark as deleting immediately so user sees feedback
-				instance.SetStatus(session.Deleting)
-
-				// Start async deletion
-				return m, deleteInstanceCmd(instance, m.storage)
-			}
-
-			// Clear pending instance on cancel
-			m.pendingKillInstance = nil
-
-			// Handle other confirmations via callbacks (e.g., push)
-			if overlay != nil {
-				if confirmed && overlay.OnConfirm != nil {
-					overlay.OnConfirm()
-				} else if cancelled && overlay.OnCancel != nil {
-					overlay.OnCancel()
-				}
-			}
-
-			return m, nil
+			return m, m.dismissConfirmation(confirmed)
 		}
 		return m, nil
 	}
 
+	if msg.Type == tea.KeyEsc && m.showOnboarding() {
+		m.dismissOnboarding()
+		return m, nil
+	}
+
 	// Exit scrolling mode when ESC is pressed and preview pane is in scrolling mode
 	// Check if Escape key was pressed and we're not in the diff tab (meaning we're in preview tab)
 	// Always check for escape key first to ensure it doesn't get intercepted elsewhere
 	if msg.Type == tea.KeyEsc {
 		// If in preview tab and in scroll mode, exit scroll mode
 		if !m.tabbedWindow.IsInDiffTab() && m.tabbedWindow.IsPreviewInScrollMode() {
-			// Use the selected instance from the list
-			selected := m.list.GetSelectedInstance()
-			err := m.tabbedWindow.ResetPreviewToNormalMode(selected)
+			// Use the instance the preview shows
+			err := m.tabbedWindow.ResetPreviewToNormalMode(m.shownInstance())
 			if err != nil {
 				return m, m.handleError(err)
 			}
 			return m, m.instanceChanged()
 		}
+		// Cancel the initialization of the selected instance if it is still starting
+		if selected := m.list.GetSelectedInstance(); selected != nil {
+			if cancel, ok := m.startCancels[selected]; ok {
+				return m, m.cancelStart(selected, cancel)
+			}
+		}
 	}
 
 	// Handle quit commands first
@@ -594,18 +1581,32 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		return m.handleQuit()
 	}
 
-	// Handle hotkey numbers 1-9 in stateDefault
+	// Handle hotkey numbers 1-9 in stateDefault. A number without a command is an error, not a prompt.
 	keyStr := msg.String()
 	if len(keyStr) == 1 && keyStr[0] >= '1' && keyStr[0] <= '9' {
-		if command, ok := m.hotkeys[keyStr]; ok {
-			selected := m.list.GetSelectedInstance()
-			if selected != nil && !selected.Paused() && selected.Started() {
-				if err := selected.SendPrompt(command); err != nil {
-					return m, m.handleError(err)
-				}
-				return m, nil
-			}
+		command := m.hotkeys[keyStr]
+		if strings.TrimSpace(command) == "" {
+			return m, m.handleError(fmt.Errorf("no command is bound to %s in %s", keyStr,
+				filepath.Join(m.repoConfigDir, config.HotkeysFileName)))
+		}
+		selected := m.list.GetSelectedInstance()
+		if selected == nil || selected.Paused() || !selected.Started() {
+			return m, nil
+		}
+		if _, err := selected.SubmitPrompt(command); err != nil {
+			return m, m.handleError(err)
+		}
+		m.countCommand(command)
+		return m, nil
+	}
+
+	// Handle alt+1-9 to select the Nth instance. Plain numbers are left to the hotkeys above.
+	if index, ok := jumpKeyIndex(keyStr); ok {
+		if index >= m.list.NumInstances() {
+			return m, nil
 		}
+		m.list.SetSelectedInstance(index)
+		return m, m.instanceChanged()
 	}
 
 	name, ok := keys.GlobalKeyStringsMap[msg.String()]
@@ -617,187 +1618,986 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 	case keys.KeyHelp:
 		return m.showHelpScreen(helpTypeGeneral{}, nil)
 	case keys.KeyPrompt:
-		if m.list.NumInstances() >= GlobalInstanceLimit {
-			return m, m.handleError(
-				fmt.Errorf("you can't create more than %d instances", GlobalInstanceLimit))
-		}
-		instance, err := session.NewInstance(session.InstanceOptions{
-			Title:   "",
-			Path:    ".",
-			Program: m.program,
-		})
-		if err != nil {
+		if err := m.addNewInstance(session.InstanceOptions{Path: ".", Program: m.program}); err != nil {
 			return m, m.handleError(err)
 		}
-
-		m.newInstanceFinalizer = m.list.AddInstance(instance)
-		m.list.SetSelectedInstance(m.list.NumInstances() - 1)
-		m.state = stateNew
-		m.menu.SetState(ui.StateNewInstance)
 		m.promptAfterName = true
-
 		return m, nil
 	case keys.KeyNew:
-		if m.list.NumInstances() >= GlobalInstanceLimit {
+		if err := m.addNewInstance(session.InstanceOptions{Path: ".", Program: m.program}); err != nil {
+			return m, m.handleError(err)
+		}
+		return m, nil
+	case keys.KeyNewInPlace:
+		if err := m.addNewInstance(session.InstanceOptions{Path: ".", Program: m.program, InPlace: true}); err != nil {
+			return m, m.handleError(err)
+		}
+		return m, nil
+	case keys.KeyTemplate:
+		if m.list.NumActiveInstances() >= GlobalInstanceLimit {
 			return m, m.handleError(
 				fmt.Errorf("you can't create more than %d instances", GlobalInstanceLimit))
 		}
-		instance, err := session.NewInstance(session.InstanceOptions{
-			Title:   "",
-			Path:    ".",
-			Program: m.program,
-		})
-		if err != nil {
-			return m, m.handleError(err)
+		if len(m.templates) == 0 {
+			return m, m.handleError(fmt.Errorf("no templates found in %s",
+				filepath.Join(m.repoConfigDir, config.TemplatesFileName)))
 		}
-
-		m.newInstanceFinalizer = m.list.AddInstance(instance)
-		m.list.SetSelectedInstance(m.list.NumInstances() - 1)
-		m.state = stateNew
-		m.menu.SetState(ui.StateNewInstance)
-
+		m.templatePicker = overlay.NewPickerOverlay("New instance from template", m.templates.Names())
+		m.state = stateTemplate
+		m.resizeOverlays()
 		return m, nil
-	case keys.KeyUp:
-		m.list.Up()
-		return m, m.instanceChanged()
-	case keys.KeyDown:
-		m.list.Down()
-		return m, m.instanceChanged()
-	case keys.KeyShiftUp:
-		m.tabbedWindow.ScrollUp()
-		return m, m.instanceChanged()
-	case keys.KeyShiftDown:
-		m.tabbedWindow.ScrollDown()
-		return m, m.instanceChanged()
-	case keys.KeyTab:
-		m.tabbedWindow.Toggle()
-		m.menu.SetInDiffTab(m.tabbedWindow.IsInDiffTab())
-		return m, m.instanceChanged()
-	case keys.KeyKill:
+	case keys.KeySendPrompt:
 		selected := m.list.GetSelectedInstance()
-		if selected == nil {
+		if selected == nil || !selected.Started() || selected.Paused() || selected.Archived() {
 			return m, nil
 		}
-
-		// Store the instance for async deletion after confirmation
-		m.pendingKillInstance = selected
-
-		// Show confirmation modal
-		message := fmt.Sprintf("[!] Kill session '%s'?", selected.Title)
-		m.state = stateConfirm
-		m.confirmationOverlay = overlay.NewConfirmationOverlay(message)
-		m.confirmationOverlay.SetWidth(50)
-
-		return m, nil
-	case keys.KeySubmit:
+		m.promptToExisting = true
+		m.state = statePrompt
+		m.menu.SetState(ui.StatePrompt)
+		m.autocompleteInputOverlay = m.newPromptOverlay()
+		return m, tea.WindowSize()
+	case keys.KeyPromptTemplate:
 		selected := m.list.GetSelectedInstance()
-		if selected == nil {
+		if selected == nil || !selected.Started() || selected.Paused() || selected.Archived() {
 			return m, nil
 		}
-
-		// Create the push action as a tea.Cmd
-		pushAction := func() tea.Msg {
-			// Default commit message with timestamp
-			commitMsg := fmt.Sprintf("[claudesquad] update from '%s' on %s", selected.Title, time.Now().Format(time.RFC822))
-			worktree, err := selected.GetGitWorktree()
-			if err != nil {
-				return err
-			}
-			if err = worktree.PushChanges(commitMsg, true); err != nil {
-				return err
-			}
-			return nil
+		return m, m.openPromptTemplatePicker(selected)
+	case keys.KeyResend:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil || selected.Paused() || selected.Archived() {
+			return m, nil
 		}
-
-		// Show confirmation modal
-		message := fmt.Sprintf("[!] Push changes from session '%s'?", selected.Title)
-		return m, m.confirmAction(message, pushAction)
-	case keys.KeyCheckout:
+		if _, err := selected.ResendLastPrompt(); err != nil {
+			return m, m.handleError(err)
+		}
+		m.countCommand(selected.LastPrompt())
+		return m, tea.WindowSize()
+	case keys.KeyForwardPrompt:
 		selected := m.list.GetSelectedInstance()
 		if selected == nil {
 			return m, nil
 		}
-
-		// Show help screen before pausing
-		m.showHelpScreen(helpTypeInstanceCheckout{}, func() {
-			if err := selected.Pause(); err != nil {
-				m.handleError(err)
-			}
-			m.instanceChanged()
-		})
+		return m, m.openForwardPrompt(selected)
+	case keys.KeyNote:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil {
+			return m, nil
+		}
+		m.textInputOverlay = overlay.NewTextInputOverlay(fmt.Sprintf("Note for %s", selected.Title), selected.Note)
+		m.state = stateNote
+		m.resizeOverlays()
 		return m, nil
-	case keys.KeyResume:
+	case keys.KeyColor:
 		selected := m.list.GetSelectedInstance()
 		if selected == nil {
 			return m, nil
 		}
-		if err := selected.Resume(); err != nil {
-			return m, m.handleError(err)
+		m.openColorInput(selected, selected.ColorIconInput())
+		return m, nil
+	case keys.KeyGroup:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil {
+			return m, nil
 		}
-		return m, tea.WindowSize()
-	case keys.KeyEnter:
-		if m.list.NumInstances() == 0 {
+		m.textInputOverlay = overlay.NewTextInputOverlay(
+			fmt.Sprintf("Group of %s (empty ungroups it)", selected.Title), selected.Group)
+		m.state = stateGroup
+		m.resizeOverlays()
+		return m, nil
+	case keys.KeyFold:
+		if !m.list.ToggleGroup() {
 			return m, nil
 		}
+		m.appConfig.CollapsedGroups = m.list.CollapsedGroups()
+		if err := config.SaveConfig(m.appConfig); err != nil {
+			return m, m.handleError(fmt.Errorf("could not save the collapsed groups: %w", err))
+		}
+		return m, m.instanceChanged()
+	case keys.KeyInterrupt:
 		selected := m.list.GetSelectedInstance()
-		if selected == nil || selected.Paused() || selected.Status == session.Loading || !selected.TmuxAlive() {
+		if selected == nil || !selected.Started() {
 			return m, nil
 		}
-		// Show help screen before attaching
-		m.showHelpScreen(helpTypeInstanceAttach{}, func() {
-			ch, err := m.list.Attach()
-			if err != nil {
-				m.handleError(err)
-				return
-			}
-			<-ch
-			m.state = stateDefault
-		})
+		if err := selected.Interrupt(m.appConfig.StopSequence); err != nil {
+			return m, m.handleError(err)
+		}
 		return m, nil
-	default:
+	case keys.KeyNewInRepo:
+		if m.list.NumActiveInstances() >= GlobalInstanceLimit {
+			return m, m.handleError(
+				fmt.Errorf("you can't create more than %d instances", GlobalInstanceLimit))
+		}
+		m.openRepoPathInput(defaultRepoPathInput())
+		return m, nil
+	case keys.KeySwitch:
+		return m, m.openSwitcher()
+	case keys.KeyTmuxSessions:
+		return m, m.showTmuxSessions()
+	case keys.KeySearch:
+		m.textInputOverlay = overlay.NewTextInputOverlay("Search the output of all sessions", "")
+		m.state = stateSearch
+		m.resizeOverlays()
+		return m, nil
+	case keys.KeyRemote:
+		if m.list.NumActiveInstances() >= GlobalInstanceLimit {
+			return m, m.handleError(
+				fmt.Errorf("you can't create more than %d instances", GlobalInstanceLimit))
+		}
+		m.textInputOverlay = overlay.NewTextInputOverlay("Remote branch to check out (e.g. origin/feature-x)", "")
+		m.state = stateRemote
+		m.resizeOverlays()
+		return m, nil
+	case keys.KeyUp:
+		m.list.Up()
+		return m, m.instanceChanged()
+	case keys.KeyDown:
+		m.list.Down()
+		return m, m.instanceChanged()
+	case keys.KeyShiftUp:
+		m.tabbedWindow.ScrollUp()
+		return m, m.instanceChanged()
+	case keys.KeyShiftDown:
+		m.tabbedWindow.ScrollDown()
+		return m, m.instanceChanged()
+	case keys.KeyShiftLeft:
+		m.tabbedWindow.ScrollLeft()
+		return m, m.instanceChanged()
+	case keys.KeyShiftRight:
+		m.tabbedWindow.ScrollRight()
+		return m, m.instanceChanged()
+	case keys.KeyRefresh:
+		// Update the selected instance right away instead of waiting for the next metadata tick. This runs
+		// inside Update like the tick does, so the two never overlap and the tick schedule is unchanged.
+		selected := m.list.GetSelectedInstance()
+		if selected == nil || !selected.Started() || selected.Paused() {
+			return m, nil
+		}
+		selected.RecheckConflicts()
+		updateInstanceMetadata(selected, m.autoYesMatcher, true, time.Now())
+		return m, m.instanceChanged()
+	case keys.KeyDiffWhitespace:
+		if !m.tabbedWindow.IsInDiffTab() {
+			return m, nil
+		}
+		m.diffOptions.IgnoreWhitespace = !m.diffOptions.IgnoreWhitespace
+		m.applyDiffOptions()
+		return m, m.instanceChanged()
+	case keys.KeyDiffMode:
+		if !m.tabbedWindow.IsInDiffTab() {
+			return m, nil
+		}
+		if m.diffOptions.Mode == git.DiffUncommitted {
+			m.diffOptions.Mode = git.DiffBranch
+		} else {
+			m.diffOptions.Mode = git.DiffUncommitted
+		}
+		m.applyDiffOptions()
+		return m, m.instanceChanged()
+	case keys.KeyDiffFiles, keys.KeyNextFile, keys.KeyPrevFile, keys.KeyNextHunk, keys.KeyPrevHunk:
+		if !m.tabbedWindow.IsInDiffTab() {
+			return m, nil
+		}
+		switch name {
+		case keys.KeyDiffFiles:
+			m.tabbedWindow.ToggleDiffFiles()
+		case keys.KeyNextFile:
+			m.tabbedWindow.NextDiffFile()
+		case keys.KeyPrevFile:
+			m.tabbedWindow.PrevDiffFile()
+		case keys.KeyNextHunk:
+			m.tabbedWindow.NextDiffHunk()
+		case keys.KeyPrevHunk:
+			m.tabbedWindow.PrevDiffHunk()
+		}
+		return m, nil
+	case keys.KeyTab, keys.KeyPrevTab:
+		if name == keys.KeyPrevTab {
+			m.tabbedWindow.PrevTab()
+		} else {
+			m.tabbedWindow.NextTab()
+		}
+		m.menu.SetActiveTab(m.tabbedWindow.ActiveTab())
+		return m, m.instanceChanged()
+	case keys.KeyKill:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil {
+			return m, nil
+		}
+
+		return m, m.confirmKill(selected)
+	case keys.KeySubmit:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil {
+			return m, nil
+		}
+
+		if m.pushing[selected] || m.rebasing[selected] != "" {
+			return m, nil
+		}
+		// The push is confirmed once pushSummaryMsg says what it contains
+		return m, pushSummaryCmd(selected, m.appConfig.AmendPush)
+	case keys.KeyRebase:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil || !selected.Started() || selected.Paused() || selected.Archived() ||
+			m.pushing[selected] || m.rebasing[selected] != "" {
+			return m, nil
+		}
+		worktree, err := selected.GetGitWorktree()
+		if err != nil {
+			return m, m.handleError(err)
+		}
+		_, _, onto, err := worktree.RebaseTarget()
+		if err != nil {
+			return m, m.handleError(fmt.Errorf("can't rebase '%s': %w", selected.Title, err))
+		}
+		if !m.shouldConfirm(config.ConfirmRebase) {
+			return m, m.rebaseInstance(selected, onto)
+		}
+
+		// Store the instance for async rebase after confirmation
+		m.pendingRebaseInstance = selected
+		return m, m.showConfirmation(fmt.Sprintf("[!] Rebase '%s' onto the latest %s?", selected.Title, onto))
+	case keys.KeyCheckpoint:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil || !selected.Started() || selected.Paused() || selected.Archived() ||
+			m.pushing[selected] || m.rebasing[selected] != "" {
+			return m, nil
+		}
+		return m, checkpointCmd(selected)
+	case keys.KeyCheckpoints:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil || !selected.Started() || selected.Paused() || selected.Archived() ||
+			m.pushing[selected] || m.rebasing[selected] != "" {
+			return m, nil
+		}
+		return m, m.openCheckpoints(selected)
+	case keys.KeyCheckout:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil {
+			return m, nil
+		}
+
+		// Show help screen before pausing
+		var errCmd tea.Cmd
+		m.showHelpScreen(helpTypeInstanceCheckout{}, func() {
+			if err := selected.Pause(); err != nil {
+				errCmd = m.handleError(err)
+			}
+			m.instanceChanged()
+		})
+		return m, errCmd
+	case keys.KeyResume:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil {
+			return m, nil
+		}
+		if err := selected.Resume(); err != nil {
+			return m, m.handleError(err)
+		}
+		return m, tea.WindowSize()
+	case keys.KeyResumeOpen:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil || !selected.Paused() {
+			return m, m.attachSelected()
+		}
+		// A failed resume leaves the instance paused, so there is nothing to attach to.
+		if err := selected.Resume(); err != nil {
+			return m, m.handleError(err)
+		}
+		m.resumeOpenInstance = selected
+		m.resumeOpenDeadline = time.Now().Add(time.Duration(m.appConfig.PromptReadyTimeout) * time.Second)
+		return m, tea.Batch(tea.WindowSize(), m.instanceChanged())
+	case keys.KeyCompact:
+		m.list.SetCompact(!m.list.Compact())
+		m.appConfig.CompactList = m.list.Compact()
+		if err := config.SaveConfig(m.appConfig); err != nil {
+			return m, m.handleError(fmt.Errorf("could not save the list mode: %w", err))
+		}
 		return m, nil
+	case keys.KeySort:
+		m.list.SetSortByAttention(!m.list.SortByAttention())
+		m.appConfig.SortByAttention = m.list.SortByAttention()
+		if err := config.SaveConfig(m.appConfig); err != nil {
+			return m, m.handleError(fmt.Errorf("could not save the list order: %w", err))
+		}
+		return m, m.instanceChanged()
+	case keys.KeyCopyBranch, keys.KeyCopyPath:
+		// Paused instances keep their worktree metadata, so this works for them too.
+		selected := m.list.GetSelectedInstance()
+		if selected == nil {
+			return m, nil
+		}
+		worktree, err := selected.GetGitWorktree()
+		if err != nil {
+			return m, m.handleError(err)
+		}
+		if name == keys.KeyCopyBranch {
+			return m, m.copyToClipboard("branch name", worktree.GetBranchName())
+		}
+		return m, m.copyToClipboard("worktree path", worktree.GetWorktreePath())
+	case keys.KeyCopyDiff, keys.KeySaveDiff:
+		// The diff of a paused instance is taken from the commits of its branch, so this works for them too.
+		selected := m.list.GetSelectedInstance()
+		if selected == nil {
+			return m, nil
+		}
+		if name == keys.KeyCopyDiff {
+			return m, m.copyDiff(selected)
+		}
+		return m, m.openSaveDiffInput(selected)
+	case keys.KeyAutoYes:
+		return m, m.toggleAutoYes()
+	case keys.KeyPin:
+		return m, m.togglePin()
+	case keys.KeyObserve:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil || !selected.Started() || selected.Paused() || selected.Archived() {
+			return m, nil
+		}
+		return m, m.showObserveCommand(selected)
+	case keys.KeyActivity:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil {
+			return m, nil
+		}
+		activity, err := selected.ActivityLog()
+		if err != nil {
+			return m, m.handleError(err)
+		}
+		events, err := activity.Events()
+		if err != nil {
+			return m, m.handleError(err)
+		}
+		m.activityOverlay = overlay.NewActivityOverlay(fmt.Sprintf("Activity of '%s'", selected.Title), ui.FormatEvents(events))
+		m.state = stateActivity
+		return m, tea.WindowSize()
+	case keys.KeyRestart:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil || (selected.Status != session.Dead && selected.Status != session.Exited) {
+			return m, nil
+		}
+		return m, m.restartInstance(selected)
+	case keys.KeyArchive:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil || !selected.Started() {
+			return m, nil
+		}
+		return m, m.toggleArchived(selected)
+	case keys.KeyShowArchived:
+		m.list.SetShowArchived(!m.list.ShowArchived())
+		return m, m.instanceChanged()
+	case keys.KeyPauseAll, keys.KeyResumeAll:
+		return m, m.confirmBulk(name)
+	case keys.KeyEnter:
+		return m, m.attachSelected()
+	default:
+		return m, nil
+	}
+}
+
+// attachSelected attaches to the selected instance if it has a live session, showing the attach help screen first
+// if it hasn't been seen. With an attach_command, the instance is opened in a new terminal window instead. For an
+// instance whose program exited, the user is asked whether to restart, archive or kill it.
+func (m *home) attachSelected() tea.Cmd {
+	if m.list.NumInstances() == 0 {
+		return nil
+	}
+	selected := m.list.GetSelectedInstance()
+	if selected != nil && (selected.Status == session.Exited || selected.Status == session.Dead) {
+		return m.openExitChoice(selected)
+	}
+	if selected == nil || selected.Paused() || selected.Status == session.Loading || !selected.TmuxAlive() {
+		return nil
+	}
+	if m.appConfig.AttachCommand != "" {
+		return m.attachExternally(selected)
+	}
+	var errCmd tea.Cmd
+	m.showHelpScreen(helpTypeInstanceAttach{}, func() {
+		ch, err := m.list.Attach()
+		if err != nil {
+			errCmd = m.handleError(err)
+			return
+		}
+		<-ch
+		m.state = stateDefault
+	})
+	return errCmd
+}
+
+// openResumed attaches to the instance resumed with KeyResumeOpen once the metadata tick found its program ready
+// for input. It gives up if the user selected another instance, or with an error if the program exited or didn't
+// become ready in time; the instance then stays resumed.
+func (m *home) openResumed(now time.Time) tea.Cmd {
+	instance := m.resumeOpenInstance
+	if instance == nil {
+		return nil
+	}
+	switch {
+	case m.list.GetSelectedInstance() != instance || instance.Paused() || instance.Archived():
+		m.resumeOpenInstance = nil
+	case instance.Status == session.Dead || instance.Status == session.Exited:
+		m.resumeOpenInstance = nil
+		return m.handleError(fmt.Errorf("the program of '%s' exited after resuming", instance.Title))
+	case instance.Status == session.Ready && m.state == stateDefault:
+		m.resumeOpenInstance = nil
+		return m.attachSelected()
+	case now.After(m.resumeOpenDeadline):
+		m.resumeOpenInstance = nil
+		return m.handleError(fmt.Errorf("resumed '%s', but it wasn't ready for input after %d seconds",
+			instance.Title, m.appConfig.PromptReadyTimeout))
+	}
+	return nil
+}
+
+// instanceChanged updates the preview pane, menu, and diff pane based on the selected instance. It returns an error
+// Cmd if there was any error.
+func (m *home) instanceChanged() tea.Cmd {
+	// selected may be nil
+	selected := m.list.GetSelectedInstance()
+	// The panes show the pinned instance if there is one, and the selected one otherwise.
+	shown := m.shownInstance()
+
+	if shown != nil {
+		shown.MarkSeen()
+	}
+	m.tabbedWindow.SetPinned(m.pinned)
+	m.tabbedWindow.UpdateDiff(shown)
+	m.tabbedWindow.SetInstance(shown)
+	if err := m.tabbedWindow.UpdateActivity(shown); err != nil {
+		log.WarningLog.Printf("could not update the activity tab: %v", err)
+	}
+	m.tabbedWindow.UpdateLogs(shown)
+	// Update menu with current instance
+	m.menu.SetInstance(selected)
+
+	// If there's no selected instance, we don't need to update the preview.
+	if err := m.tabbedWindow.UpdatePreview(shown); err != nil {
+		return m.handleError(err)
+	}
+	return nil
+}
+
+// shownInstance returns the instance the panes show: the pinned one, or the selected one if nothing is pinned. A
+// pinned instance that was removed from the list is unpinned.
+func (m *home) shownInstance() *session.Instance {
+	if m.pinned != nil && !slices.Contains(m.list.GetInstances(), m.pinned) {
+		m.pinned = nil
+	}
+	if m.pinned != nil {
+		return m.pinned
+	}
+	return m.list.GetSelectedInstance()
+}
+
+// confirmKill kills instance, asking first if kills are confirmed.
+func (m *home) confirmKill(instance *session.Instance) tea.Cmd {
+	if !m.shouldConfirm(config.ConfirmKill) {
+		return m.killInstance(instance)
+	}
+
+	// Store the instance for async deletion after confirmation
+	m.pendingKillInstance = instance
+
+	// Show confirmation modal
+	message := fmt.Sprintf("[!] Kill session '%s'?", instance.Title)
+	return m.showConfirmation(message)
+}
+
+// restartInstance relaunches the program of instance, which exited or crashed, in the background.
+func (m *home) restartInstance(instance *session.Instance) tea.Cmd {
+	// Show the instance as loading while the program starts in the background.
+	instance.SetStatus(session.Loading)
+	return restartInstanceCmd(instance)
+}
+
+// toggleArchived archives instance, or restores it if it is archived.
+func (m *home) toggleArchived(instance *session.Instance) tea.Cmd {
+	if instance.Archived() {
+		// Archived instances don't count against the limit, so restoring one must respect it.
+		if m.list.NumActiveInstances() >= GlobalInstanceLimit {
+			return m.handleError(
+				fmt.Errorf("you can't have more than %d instances, archive or kill one first", GlobalInstanceLimit))
+		}
+		return unarchiveInstanceCmd(instance)
+	}
+	if err := instance.Archive(); err != nil {
+		return m.handleError(err)
+	}
+	if err := m.saveInstances(); err != nil {
+		return m.handleError(err)
+	}
+	return tea.Batch(tea.WindowSize(), m.instanceChanged())
+}
+
+// togglePin pins the panes to the selected instance, or unpins them if they are pinned.
+func (m *home) togglePin() tea.Cmd {
+	if m.pinned != nil {
+		m.pinned = nil
+	} else if m.pinned = m.list.GetSelectedInstance(); m.pinned == nil {
+		return nil
+	}
+	return m.instanceChanged()
+}
+
+// toggleAutoYes turns the global auto-yes switch on or off and applies it to every instance, including the ones held
+// back on startup. Instances check AutoYes before every confirmation, so turning it off also stops prompts that are
+// already waiting from being confirmed.
+func (m *home) toggleAutoYes() tea.Cmd {
+	m.autoYes = !m.autoYes
+	instances := slices.Concat(m.list.GetInstances(), m.heldBack)
+	for _, instance := range instances {
+		instance.AutoYes = m.autoYes
+	}
+	m.list.SetAutoYes(m.autoYes)
+	if err := m.saveInstances(); err != nil {
+		return m.handleError(err)
+	}
+	if m.autoYes {
+		m.gitResult = fmt.Sprintf("Auto-yes on for all %d sessions", len(instances))
+	} else {
+		m.gitResult = "Auto-yes off for all sessions"
+	}
+	return hideGitResultCmd(m.ctx, m.gitResult)
+}
+
+type keyupMsg struct{}
+
+// keydownCallback clears the menu option highlighting after 500ms.
+func (m *home) keydownCallback(name keys.KeyName) tea.Cmd {
+	m.menu.Keydown(name)
+	return func() tea.Msg {
+		select {
+		case <-m.ctx.Done():
+		case <-time.After(500 * time.Millisecond):
+		}
+
+		return keyupMsg{}
+	}
+}
+
+// hideErrMsg implements tea.Msg and clears the error text from the screen.
+type hideErrMsg struct{}
+
+// handleMouseClick selects the instance or tab under a left-click at screen position (x, y). Clicks outside the
+// list rows and tab headers are ignored.
+func (m *home) handleMouseClick(x, y int) tea.Cmd {
+	// View renders the list and tabbed window side by side below one line of padding, centered above the menu.
+	listView := m.list.String()
+	listAndPreviewWidth := lipgloss.Width(listView) + lipgloss.Width(m.tabbedWindow.String())
+	left := int(math.Round(float64(lipgloss.Width(m.View())-listAndPreviewWidth) * 0.5))
+	x -= left
+	y--
+
+	listWidth := lipgloss.Width(listView)
+	if x < 0 || y < 0 {
+		return nil
+	}
+	if x < listWidth {
+		index, ok := m.list.InstanceAt(y)
+		if !ok {
+			return nil
+		}
+		m.list.SetSelectedInstance(index)
+		return m.instanceChanged()
+	}
+
+	tab, ok := m.tabbedWindow.TabAt(x-listWidth, y)
+	if !ok || tab == m.tabbedWindow.ActiveTab() {
+		return nil
+	}
+	m.tabbedWindow.SetActiveTab(tab)
+	m.menu.SetActiveTab(m.tabbedWindow.ActiveTab())
+	return m.instanceChanged()
+}
+
+// jumpKeyIndex returns the zero-based list index for an alt+1 to alt+9 key.
+func jumpKeyIndex(keyStr string) (int, bool) {
+	digit, found := strings.CutPrefix(keyStr, "alt+")
+	if !found || len(digit) != 1 || digit[0] < '1' || digit[0] > '9' {
+		return 0, false
+	}
+	return int(digit[0] - '1'), true
+}
+
+// applyDiffOptions switches every instance to m.diffOptions. The selected instance's diff is computed right away
+// unless it is cached, the others are updated on the next metadata tick.
+func (m *home) applyDiffOptions() {
+	selected := m.list.GetSelectedInstance()
+	for _, instance := range m.list.GetInstances() {
+		cached := instance.SetDiffOptions(m.diffOptions)
+		if cached || instance != selected || !instance.Started() || instance.Paused() {
+			continue
+		}
+		if err := instance.UpdateDiffStats(); err != nil {
+			log.WarningLog.Printf("could not update diff stats: %v", err)
+		}
+	}
+}
+
+// updateInstanceMetadata updates the status and diff stats of a running instance. Prompts are only confirmed
+// in auto-yes mode if autoYesMatcher matches them. Instances that are not started, paused, archived, loading or
+// being deleted are left untouched. Instances whose program exited with code 0 are marked Exited, and those whose
+// program crashed or whose tmux session died are marked Dead, so they can be restarted.
+// The next queued prompt is sent once the instance has been Ready for two ticks in a row, so a program that is
+// slow to react to the previous prompt isn't sent the next one too early. now is recorded as the instance's last
+// activity if its output changed, or if it has none yet. Changed output of an instance that isn't selected is marked
+// unseen until it is selected.
+func updateInstanceMetadata(instance *session.Instance, autoYesMatcher *session.AutoYesMatcher, selected bool,
+	now time.Time) {
+	if !instance.Started() || instance.Paused() || instance.Archived() || instance.Status == session.Loading ||
+		instance.Status == session.Deleting {
+		return
+	}
+	if instance.CheckExited() {
+		return
+	}
+	updated, prompt := instance.HasUpdated()
+	// Keep the output before tmux drops it from its scrollback. Unchanged panes have nothing new to keep.
+	if updated || instance.OutputHistory().Version() == 0 {
+		if err := instance.RecordOutputHistory(); err != nil {
+			log.WarningLog.Printf("could not record output history: %v", err)
+		}
+	}
+	instance.TrackUnseen(updated, selected)
+	if updated || instance.LastActive().IsZero() {
+		instance.MarkActive(now)
+	}
+	if updated {
+		instance.SetStatus(session.Running)
+	} else {
+		if prompt {
+			if autoYesMatcher.Matches(instance.PromptText()) {
+				instance.TapEnter()
+			}
+		} else {
+			wasReady := instance.Status == session.Ready
+			instance.SetStatus(session.Ready)
+			if wasReady {
+				if err := instance.SendQueuedPrompt(); err != nil {
+					log.WarningLog.Printf("could not send queued prompt to %s: %v", instance.Title, err)
+				}
+			}
+		}
+	}
+	if err := instance.UpdateDiffStats(); err != nil {
+		log.WarningLog.Printf("could not update diff stats: %v", err)
+	}
+	if err := instance.UpdateConflicts(now); err != nil {
+		log.WarningLog.Printf("could not check %s for conflicts: %v", instance.Title, err)
+	}
+}
+
+// autoPauseIdle returns the Cmd that pauses in the background the instances that have been idle at now for longer
+// than the configured idle_pause_minutes. The selected instance is never paused, since the user may be attached to
+// it, and neither is the pinned one, which the tabs keep showing, nor instances being pushed or rebased. An
+// instance that fails to pause is tried again after another idle period. The paused instances are marked paused
+// when autoPausedMsg is handled.
+func (m *home) autoPauseIdle(now time.Time) tea.Cmd {
+	// While quitting, the instances are already being paused. An auto-commit running in the background would race
+	// with the commit of the pause, so the pause waits for the next tick.
+	if m.appConfig.IdlePauseMinutes <= 0 || m.state == stateQuitting || m.autoCommitting || m.autoPausing ||
+		m.bulkRunning {
+		return nil
+	}
+	timeout := time.Duration(m.appConfig.IdlePauseMinutes) * time.Minute
+	var idle []*session.Instance
+	for _, instance := range session.IdleInstances(m.list.GetInstances(), m.list.GetSelectedInstance(), timeout, now) {
+		if instance != m.pinned && !m.pushing[instance] && m.rebasing[instance] == "" && instance.CanPause() == nil {
+			idle = append(idle, instance)
+		}
+	}
+	if len(idle) == 0 {
+		return nil
+	}
+	m.autoPausing = true
+	m.setInBackground(idle, true)
+	minutes := m.appConfig.IdlePauseMinutes
+	return func() tea.Msg {
+		msg := autoPausedMsg{at: now}
+		for _, instance := range idle {
+			// Pausing commits the changes, which would commit the conflicts of a stopped rebase or merge.
+			if operation := inProgressOperation(instance); operation != "" {
+				log.InfoLog.Printf("not auto-pausing instance %s in the middle of a %s", instance.Title, operation)
+				msg.failed = append(msg.failed, instance)
+				continue
+			}
+			if err := instance.PauseResources(); err != nil {
+				log.ErrorLog.Printf("failed to auto-pause idle instance %s: %v", instance.Title, err)
+				msg.failed = append(msg.failed, instance)
+				continue
+			}
+			log.InfoLog.Printf("auto-paused instance %s after %d minutes without output", instance.Title, minutes)
+			msg.paused = append(msg.paused, instance)
+		}
+		return msg
+	}
+}
+
+// inProgressOperation returns the git operation that stopped in the worktree of instance, like
+// Instance.InProgressOperation, but without looking at the instance's status, so it can run in the background.
+func inProgressOperation(instance *session.Instance) string {
+	worktree, err := instance.GetGitWorktree()
+	if err != nil {
+		return ""
+	}
+	operation, err := worktree.InProgressOperation()
+	if err != nil {
+		return ""
+	}
+	return operation
+}
+
+// setInBackground records whether instances are being paused or resumed in the background.
+func (m *home) setInBackground(instances []*session.Instance, busy bool) {
+	if m.inBackground == nil {
+		m.inBackground = make(map[*session.Instance]bool)
+	}
+	for _, instance := range instances {
+		if busy {
+			m.inBackground[instance] = true
+		} else {
+			delete(m.inBackground, instance)
+		}
+	}
+}
+
+// confirmBulk asks to confirm pausing all running instances or resuming all paused ones, for KeyPauseAll or
+// KeyResumeAll.
+func (m *home) confirmBulk(name keys.KeyName) tea.Cmd {
+	instances := m.bulkInstances(name)
+	if len(instances) == 0 {
+		if name == keys.KeyPauseAll {
+			return m.handleError(fmt.Errorf("no running sessions to pause"))
+		}
+		return m.handleError(fmt.Errorf("no paused sessions to resume"))
+	}
+	m.pendingBulk = true
+	m.pendingBulkAction = name
+	if name == keys.KeyPauseAll {
+		return m.showConfirmation(fmt.Sprintf(
+			"[!] Pause %d running session(s)? Their changes are committed and their worktrees removed.", len(instances)))
+	}
+	return m.showConfirmation(fmt.Sprintf("[!] Resume %d paused session(s)?", len(instances)))
+}
+
+// bulkInstances returns the instances KeyPauseAll pauses or KeyResumeAll resumes. Instances being pushed or rebased
+// are left alone.
+func (m *home) bulkInstances(name keys.KeyName) []*session.Instance {
+	var instances []*session.Instance
+	for _, instance := range m.list.GetInstances() {
+		if !m.pushing[instance] && m.rebasing[instance] == "" {
+			instances = append(instances, instance)
+		}
+	}
+	if name == keys.KeyPauseAll {
+		return session.Pausable(instances)
+	}
+	return session.Resumable(instances)
+}
+
+// runBulk returns the Cmd that pauses or resumes the instances of bulkInstances in the background, for KeyPauseAll
+// or KeyResumeAll. It waits for the auto-commits and auto-pauses running in the background, which would race with
+// the commits of the pauses.
+func (m *home) runBulk(name keys.KeyName) tea.Cmd {
+	if m.bulkRunning || m.autoCommitting || m.autoPausing {
+		return m.handleError(fmt.Errorf("sessions are being paused or committed in the background, try again in a moment"))
+	}
+	instances := m.bulkInstances(name)
+	m.bulkRunning = true
+	m.setInBackground(instances, true)
+	return func() tea.Msg {
+		if name == keys.KeyPauseAll {
+			return bulkDoneMsg{instances: instances, result: session.PauseAll(instances)}
+		}
+		return bulkDoneMsg{instances: instances, result: session.ResumeAll(instances)}
+	}
+}
+
+// autoCommit returns the Cmd that commits in the background the changes of the instances whose configured
+// auto_commit_minutes have passed at now, or nil if none are due. Instances being pushed or rebased are left alone,
+// and nothing is started while the last auto-commits are still running.
+func (m *home) autoCommit(now time.Time) tea.Cmd {
+	if m.appConfig.AutoCommitMinutes <= 0 || m.state == stateQuitting || m.autoCommitting || m.autoPausing ||
+		m.bulkRunning {
+		return nil
+	}
+	interval := time.Duration(m.appConfig.AutoCommitMinutes) * time.Minute
+	var due []*session.Instance
+	for _, instance := range m.list.GetInstances() {
+		if !m.pushing[instance] && m.rebasing[instance] == "" && instance.AutoCommitDue(interval, now) {
+			due = append(due, instance)
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+	m.autoCommitting = true
+	return func() tea.Msg {
+		for _, instance := range due {
+			committed, err := instance.AutoCommit(now)
+			if err != nil {
+				log.WarningLog.Print(err)
+				continue
+			}
+			if committed {
+				log.InfoLog.Printf("auto-committed the changes of instance %s", instance.Title)
+			}
+		}
+		return autoCommittedMsg{}
+	}
+}
+
+// addNewInstance adds an unstarted instance created with opts to the list and asks for its title.
+func (m *home) addNewInstance(opts session.InstanceOptions) error {
+	if m.list.NumActiveInstances() >= GlobalInstanceLimit {
+		return fmt.Errorf("you can't create more than %d instances", GlobalInstanceLimit)
+	}
+	opts.PostCreateHook = m.appConfig.PostCreateHook
+	instance, err := session.NewInstance(opts)
+	if err != nil {
+		return err
+	}
+	if instance.InPlace() {
+		// Two programs editing the same files would get in each other's way.
+		for _, other := range m.list.GetInstances() {
+			if other.InPlace() && other.Path == instance.Path && !other.Archived() {
+				return fmt.Errorf("'%s' already runs in place in %s", other.Title, instance.Path)
+			}
+		}
+	}
+	m.setStripCommandSlash(instance)
+	// The new instance joins the group that is selected, so it is created where the user is working.
+	instance.Group = m.list.SelectedGroup()
+
+	m.newInstanceFinalizer = m.list.AddInstance(instance)
+	m.list.SelectInstance(instance)
+	m.state = stateNew
+	m.menu.SetState(ui.StateNewInstance)
+	return nil
+}
+
+// openRepoPathInput asks for the repository of a new instance, starting with value. Tab completes directories.
+func (m *home) openRepoPathInput(value string) {
+	m.textInputOverlay = overlay.NewTextInputOverlay("Repository of the new session (tab completes)", value)
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = ""
+	}
+	m.textInputOverlay.SetCompleter(func(value string) string {
+		return autocomplete.CompletePath(value, home)
+	})
+	m.state = stateRepoPath
+	m.resizeOverlays()
+}
+
+// openColorInput asks for the color and icon of instance, starting with value.
+func (m *home) openColorInput(instance *session.Instance, value string) {
+	m.textInputOverlay = overlay.NewTextInputOverlay(
+		fmt.Sprintf("Color and icon for %s, e.g. blue 🚀 or #ff8800 (empty clears them)", instance.Title), value)
+	m.state = stateColor
+	m.resizeOverlays()
+}
+
+// defaultRepoPathInput returns the directory containing the current repository, where other repositories are
+// likely to be, with a trailing slash to complete from.
+func defaultRepoPathInput() string {
+	cwd, err := filepath.Abs(".")
+	if err != nil {
+		return ""
 	}
+	return filepath.Dir(git.RepoRootOrDir(cwd)) + string(filepath.Separator)
 }
 
-// instanceChanged updates the preview pane, menu, and diff pane based on the selected instance. It returns an error
-// Cmd if there was any error.
-func (m *home) instanceChanged() tea.Cmd {
-	// selected may be nil
-	selected := m.list.GetSelectedInstance()
-
-	m.tabbedWindow.UpdateDiff(selected)
-	m.tabbedWindow.SetInstance(selected)
-	// Update menu with current instance
-	m.menu.SetInstance(selected)
-
-	// If there's no selected instance, we don't need to update the preview.
-	if err := m.tabbedWindow.UpdatePreview(selected); err != nil {
+// newInstanceFromTemplate adds an instance with the settings of the named template and asks for its title.
+func (m *home) newInstanceFromTemplate(name string) tea.Cmd {
+	tmpl, ok := m.templates.Find(name)
+	if !ok {
+		return m.handleError(fmt.Errorf("template %q not found", name))
+	}
+	opts, err := session.OptionsFromTemplate(tmpl, ".", m.program)
+	if err != nil {
+		return m.handleError(err)
+	}
+	if err := m.addNewInstance(opts); err != nil {
 		return m.handleError(err)
 	}
 	return nil
 }
 
-type keyupMsg struct{}
+// handlePromptToExisting closes the prompt overlay opened for a running instance and sends the submitted prompt, or
+// queues it if the instance is still busy with an earlier one.
+func (m *home) handlePromptToExisting(instance *session.Instance) tea.Cmd {
+	m.promptToExisting = false
+	promptOverlay := m.autocompleteInputOverlay
+	m.autocompleteInputOverlay = nil
+	m.state = stateDefault
+	m.menu.SetState(ui.StateDefault)
+	if !promptOverlay.IsSubmitted() {
+		return tea.WindowSize()
+	}
 
-// keydownCallback clears the menu option highlighting after 500ms.
-func (m *home) keydownCallback(name keys.KeyName) tea.Cmd {
-	m.menu.Keydown(name)
-	return func() tea.Msg {
-		select {
-		case <-m.ctx.Done():
-		case <-time.After(500 * time.Millisecond):
-		}
+	prompt := promptOverlay.GetValue()
+	m.rememberPrompt(prompt)
+	if _, err := instance.SubmitPrompt(prompt); err != nil {
+		return tea.Batch(tea.WindowSize(), m.handleError(err))
+	}
+	return tea.WindowSize()
+}
 
-		return keyupMsg{}
+// handleTitleFromPrompt closes the prompt overlay that was opened because the new instance's title was left
+// blank. A submitted prompt is sent once the instance starts and suggests the title, which can still be edited before
+// the instance is started. Either way, the user goes back to naming the instance.
+func (m *home) handleTitleFromPrompt(instance *session.Instance) tea.Cmd {
+	m.titleFromPrompt = false
+	overlay := m.autocompleteInputOverlay
+	m.autocompleteInputOverlay = nil
+	m.state = stateNew
+	m.menu.SetState(ui.StateNewInstance)
+
+	if !overlay.IsSubmitted() {
+		return tea.WindowSize()
+	}
+
+	prompt := overlay.GetValue()
+	m.rememberPrompt(prompt)
+	m.namePrompt = prompt
+	m.promptAfterName = false
+
+	title := session.TitleFromPrompt(prompt, m.appConfig.TitleLength())
+	if title == "" {
+		return tea.Batch(tea.WindowSize(), m.handleError(fmt.Errorf("could not derive a title from the prompt, please type one")))
 	}
+	if err := instance.SetTitle(title); err != nil {
+		return m.handleError(err)
+	}
+	return tea.WindowSize()
 }
 
-// hideErrMsg implements tea.Msg and clears the error text from the screen.
-type hideErrMsg struct{}
+// writeClipboard writes text to the system clipboard. It is a variable so tests can replace it.
+var writeClipboard = clipboard.WriteAll
+
+// copyToClipboard copies value to the clipboard and briefly confirms it in the status line. If there is no
+// clipboard tool, the value is shown in the error box instead so it can still be copied by hand.
+func (m *home) copyToClipboard(label, value string) tea.Cmd {
+	if err := writeClipboard(value); err != nil {
+		return m.handleError(fmt.Errorf("could not copy %s (%v): %s", label, err, value))
+	}
+	m.gitResult = fmt.Sprintf("Copied %s: %s", label, value)
+	return hideGitResultCmd(m.ctx, m.gitResult)
+}
 
 // previewTickMsg implements tea.Msg and triggers a preview update
 type previewTickMsg struct{}
 
+// previewTickCmd sends the next previewTickMsg after interval.
+func previewTickCmd(interval time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		time.Sleep(interval)
+		return previewTickMsg{}
+	}
+}
+
 type tickUpdateMetadataMessage struct{}
 
 type instanceChangedMsg struct{}
@@ -808,6 +2608,84 @@ type instanceDeletedMsg struct {
 	err      error
 }
 
+// confirmTickMsg counts down the timeout of a confirmation overlay
+type confirmTickMsg struct {
+	overlay *overlay.ConfirmationOverlay
+}
+
+// instanceRestartedMsg signals that async instance restart has completed
+type instanceRestartedMsg struct {
+	instance *session.Instance
+	err      error
+}
+
+// instanceUnarchivedMsg signals that an archived instance was restored
+type instanceUnarchivedMsg struct {
+	instance *session.Instance
+	err      error
+}
+
+// instancePushedMsg signals that an instance's changes were pushed, or why they weren't
+type instancePushedMsg struct {
+	instance *session.Instance
+	branch   string
+	err      error
+}
+
+// quitPausedMsg signals that the instance at index of the instances paused before quitting was paused, or why it
+// wasn't
+type quitPausedMsg struct {
+	instances []*session.Instance
+	index     int
+	err       error
+}
+
+// instanceRebasedMsg signals that an instance's branch was rebased onto onto, or why it wasn't
+type instanceRebasedMsg struct {
+	instance *session.Instance
+	onto     string
+	err      error
+}
+
+// pushSummaryMsg carries the diff that pushing instance would send, or why it couldn't be computed
+type pushSummaryMsg struct {
+	instance *session.Instance
+	stats    *git.DiffStats
+	// amend is true if the push amends claude-squad's last commit
+	amend bool
+	err   error
+}
+
+// autoCommittedMsg signals that the auto-commits started by autoCommit are done
+type autoCommittedMsg struct{}
+
+// bulkDoneMsg signals that the instances were paused or resumed all at once, with the result. The instances are
+// marked paused or resumed when it is handled.
+type bulkDoneMsg struct {
+	instances []*session.Instance
+	result    session.BulkResult
+}
+
+// autoPausedMsg signals that the pauses started by autoPauseIdle at are done. The paused instances are marked paused
+// when it is handled, and the failed instances were not paused.
+type autoPausedMsg struct {
+	at     time.Time
+	paused []*session.Instance
+	failed []*session.Instance
+}
+
+// instanceCheckpointedMsg signals that an instance's work was committed on the checkpoint branch, or why it wasn't
+type instanceCheckpointedMsg struct {
+	instance *session.Instance
+	branch   string
+	err      error
+}
+
+// hideGitResultMsg clears the result of a push or rebase from the status line, unless another result replaced it
+type hideGitResultMsg struct {
+	result string
+}
+
 // instanceProgressMsg is sent during async instance initialization to report progress
 type instanceProgressMsg struct {
 	instance *session.Instance
@@ -832,19 +2710,51 @@ type pendingPromptSentMsg struct {
 	err      error
 }
 
-// sendPendingPromptCmd waits for the instance to be ready and sends the pending prompt
-func sendPendingPromptCmd(instance *session.Instance, prompt string) tea.Cmd {
+// pendingPromptAttempts is how many times a pending prompt is tried before giving up.
+const pendingPromptAttempts = 3
+
+// pendingPromptBackoff is the wait before retrying a pending prompt. It doubles after each attempt. It is a
+// variable so tests can shorten it.
+var pendingPromptBackoff = time.Second
+
+// promptTarget is the part of an instance that a pending prompt is sent to.
+type promptTarget interface {
+	WaitForInputReady(timeout time.Duration) error
+	SendPrompt(prompt string) error
+}
+
+// sendPendingPromptCmd sends the pending prompts in order, waiting for the instance to be ready before each one
+func sendPendingPromptCmd(instance *session.Instance, prompts []string, readyTimeout time.Duration) tea.Cmd {
 	return func() tea.Msg {
-		// Wait for the program to be ready to accept input (up to 5 seconds)
-		_ = instance.WaitForInputReady(5 * time.Second)
+		for _, prompt := range prompts {
+			if err := sendWhenReady(instance, prompt, readyTimeout); err != nil {
+				return pendingPromptSentMsg{instance: instance, err: err}
+			}
+		}
+		return pendingPromptSentMsg{instance: instance}
+	}
+}
 
-		// Send the prompt
-		err := instance.SendPrompt(prompt)
-		return pendingPromptSentMsg{
-			instance: instance,
-			err:      err,
+// sendWhenReady waits up to readyTimeout for target to accept input and then sends prompt. Both steps are retried
+// with backoff, up to pendingPromptAttempts times in total.
+func sendWhenReady(target promptTarget, prompt string, readyTimeout time.Duration) error {
+	backoff := pendingPromptBackoff
+	var err error
+	for attempt := 1; attempt <= pendingPromptAttempts; attempt++ {
+		if attempt > 1 {
+			log.WarningLog.Printf("pending prompt attempt %d failed, retrying in %s: %v", attempt-1, backoff, err)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = target.WaitForInputReady(readyTimeout); err != nil {
+			continue
+		}
+		if err = target.SendPrompt(prompt); err == nil {
+			return nil
 		}
 	}
+	return fmt.Errorf("could not send prompt after %d attempts, it is still in the prompt history: %w",
+		pendingPromptAttempts, err)
 }
 
 // deleteInstanceCmd performs async instance deletion
@@ -876,6 +2786,9 @@ func deleteInstanceCmd(instance *session.Instance, storage *session.Storage) tea
 			log.ErrorLog.Printf("failed to delete instance from storage: %v", err)
 		}
 
+		// A new instance with the same title starts with an empty activity log.
+		instance.DeleteActivityLog()
+
 		// Kill the instance (tmux session + git worktree cleanup)
 		// Log errors but don't fail - resources may already be cleaned up
 		if err := instance.Kill(); err != nil {
@@ -887,6 +2800,20 @@ func deleteInstanceCmd(instance *session.Instance, storage *session.Storage) tea
 	}
 }
 
+// restartInstanceCmd performs async instance restart
+func restartInstanceCmd(instance *session.Instance) tea.Cmd {
+	return func() tea.Msg {
+		return instanceRestartedMsg{instance: instance, err: instance.Restart()}
+	}
+}
+
+// unarchiveInstanceCmd relaunches the program of an archived instance in the background
+func unarchiveInstanceCmd(instance *session.Instance) tea.Cmd {
+	return func() tea.Msg {
+		return instanceUnarchivedMsg{instance: instance, err: instance.Unarchive()}
+	}
+}
+
 // tickUpdateMetadataCmd is the callback to update the metadata of the instances every 500ms. Note that we iterate
 // overall the instances and capture their output. It's a pretty expensive operation. Let's do it 2x a second only.
 var tickUpdateMetadataCmd = func() tea.Msg {
@@ -909,11 +2836,66 @@ func (m *home) handleError(err error) tea.Cmd {
 	}
 }
 
+// beginStart starts initializing a new instance and remembers how to cancel it.
+func (m *home) beginStart(instance *session.Instance, finalizer func()) tea.Cmd {
+	ctx, cancel := context.WithCancel(m.ctx)
+	if m.startCancels == nil {
+		m.startCancels = make(map[*session.Instance]context.CancelFunc)
+	}
+	m.startCancels[instance] = cancel
+	// Pass false for promptAfterName since the prompt overlay is shown while the instance starts.
+	return startInstanceCmd(ctx, instance, finalizer, false)
+}
+
+// cancelStart cancels the initialization of an instance and removes it from the list right away. The start
+// goroutine tears down whatever it created once it notices, and its progress is still drained until it's done.
+func (m *home) cancelStart(instance *session.Instance, cancel context.CancelFunc) tea.Cmd {
+	cancel()
+	delete(m.startCancels, instance)
+	m.list.RemoveInstance(instance)
+	m.initProgressMessage = ""
+	delete(m.pendingPrompts, instance)
+	return tea.Batch(
+		m.handleError(fmt.Errorf("cancelled starting %s", instance.Title)),
+		tea.WindowSize(),
+		m.instanceChanged(),
+	)
+}
+
+// setPendingPrompt stores the prompt to send to instance once it finishes initializing. An empty prompt clears it.
+func (m *home) setPendingPrompt(instance *session.Instance, prompt string) {
+	if prompt == "" {
+		delete(m.pendingPrompts, instance)
+		return
+	}
+	if m.pendingPrompts == nil {
+		m.pendingPrompts = make(map[*session.Instance]string)
+	}
+	m.pendingPrompts[instance] = prompt
+}
+
+// takePendingPrompt returns and clears the prompt waiting for instance, or "" if there is none.
+func (m *home) takePendingPrompt(instance *session.Instance) string {
+	prompt := m.pendingPrompts[instance]
+	delete(m.pendingPrompts, instance)
+	return prompt
+}
+
+// killInstanceCmd kills an instance that is no longer in the list.
+func killInstanceCmd(instance *session.Instance) tea.Cmd {
+	return func() tea.Msg {
+		if err := instance.Kill(); err != nil {
+			log.ErrorLog.Printf("could not kill cancelled instance: %v", err)
+		}
+		return nil
+	}
+}
+
 // startInstanceCmd starts instance initialization asynchronously and returns the first progress message
-func startInstanceCmd(instance *session.Instance, finalizer func(), promptAfterName bool) tea.Cmd {
+func startInstanceCmd(ctx context.Context, instance *session.Instance, finalizer func(), promptAfterName bool) tea.Cmd {
 	return func() tea.Msg {
 		progress := make(chan session.InitProgress, 1)
-		go instance.StartWithProgress(true, progress)
+		go instance.StartWithProgress(ctx, true, progress)
 
 		// Wait for first progress message
 		p := <-progress
@@ -927,6 +2909,14 @@ func startInstanceCmd(instance *session.Instance, finalizer func(), promptAfterN
 	}
 }
 
+// formatInitProgress returns the status line text for an initialization stage, like "[2/4] Launching tmux session...".
+func formatInitProgress(p session.InitProgress) string {
+	if counter := p.Counter(); counter != "" {
+		return counter + " " + p.Message
+	}
+	return p.Message
+}
+
 // listenForProgressCmd continues listening for progress updates from the channel
 func listenForProgressCmd(instance *session.Instance, ch <-chan session.InitProgress, finalizer func(), promptAfterName bool) tea.Cmd {
 	return func() tea.Msg {
@@ -967,12 +2957,7 @@ func listenForProgressCmd(instance *session.Instance, ch <-chan session.InitProg
 
 // confirmAction shows a confirmation modal and stores the action to execute on confirm
 func (m *home) confirmAction(message string, action tea.Cmd) tea.Cmd {
-	m.state = stateConfirm
-
-	// Create and show the confirmation overlay using ConfirmationOverlay
-	m.confirmationOverlay = overlay.NewConfirmationOverlay(message)
-	// Set a fixed width for consistent appearance
-	m.confirmationOverlay.SetWidth(50)
+	cmd := m.showConfirmation(message)
 
 	// Set callbacks for confirmation and cancellation
 	m.confirmationOverlay.OnConfirm = func() {
@@ -987,22 +2972,367 @@ func (m *home) confirmAction(message string, action tea.Cmd) tea.Cmd {
 		m.state = stateDefault
 	}
 
+	return cmd
+}
+
+// showConfirmation shows a confirmation overlay with the given message. If a confirmation timeout is configured,
+// it returns the Cmd that counts it down.
+func (m *home) showConfirmation(message string) tea.Cmd {
+	m.state = stateConfirm
+
+	// Create and show the confirmation overlay using ConfirmationOverlay
+	m.confirmationOverlay = overlay.NewConfirmationOverlay(message)
+	m.confirmationOverlay.SetWidth(defaultConfirmationWidth)
+	m.resizeOverlays()
+
+	if m.appConfig == nil || m.appConfig.ConfirmTimeout <= 0 {
+		return nil
+	}
+	m.confirmationOverlay.SetTimeout(time.Duration(m.appConfig.ConfirmTimeout) * time.Second)
+	return m.confirmTickCmd(m.confirmationOverlay)
+}
+
+// dismissConfirmation closes the confirmation overlay, running the pending kill or the overlay's callbacks.
+func (m *home) dismissConfirmation(confirmed bool) tea.Cmd {
+	m.state = stateDefault
+	overlay := m.confirmationOverlay
+	m.confirmationOverlay = nil
+
+	// Handle kill confirmation (async)
+	if confirmed && m.pendingKillInstance != nil {
+		instance := m.pendingKillInstance
+		m.pendingKillInstance = nil
+		return m.killInstance(instance)
+	}
+
+	// Handle push confirmation (async)
+	if confirmed && m.pendingPushInstance != nil {
+		instance := m.pendingPushInstance
+		m.pendingPushInstance = nil
+		return m.pushInstance(instance, m.pendingPushAmend)
+	}
+
+	// Handle rebase confirmation (async)
+	if confirmed && m.pendingRebaseInstance != nil {
+		instance := m.pendingRebaseInstance
+		m.pendingRebaseInstance = nil
+		worktree, err := instance.GetGitWorktree()
+		if err != nil {
+			return m.handleError(err)
+		}
+		_, _, onto, err := worktree.RebaseTarget()
+		if err != nil {
+			return m.handleError(fmt.Errorf("can't rebase '%s': %w", instance.Title, err))
+		}
+		return m.rebaseInstance(instance, onto)
+	}
+
+	// Handle pause-all and resume-all confirmation (async)
+	if confirmed && m.pendingBulk {
+		m.pendingBulk = false
+		return m.runBulk(m.pendingBulkAction)
+	}
+
+	// Handle checkpoint restore confirmation
+	if confirmed && m.pendingRestoreInstance != nil {
+		instance := m.pendingRestoreInstance
+		m.pendingRestoreInstance = nil
+		return m.restoreCheckpoint(instance, m.pendingRestoreCommit)
+	}
+
+	// Go on quitting if it was confirmed although instances have changes
+	if m.pendingQuitWithChanges {
+		m.pendingQuitWithChanges = false
+		if !confirmed {
+			return nil
+		}
+		_, cmd := m.planQuit()
+		return cmd
+	}
+
+	// Pause the running instances and quit if it was confirmed
+	if m.pendingQuitPause != nil {
+		instances := m.pendingQuitPause
+		m.pendingQuitPause = nil
+		if !confirmed {
+			return nil
+		}
+		return m.pauseOnQuit(instances)
+	}
+
+	// Clear pending instances on cancel
+	m.pendingKillInstance = nil
+	m.pendingPushInstance = nil
+	m.pendingRebaseInstance = nil
+	m.pendingRestoreInstance = nil
+	m.pendingBulk = false
+
+	// Handle other confirmations via callbacks (e.g., orphaned tmux sessions)
+	if overlay != nil {
+		if confirmed && overlay.OnConfirm != nil {
+			overlay.OnConfirm()
+		} else if !confirmed && overlay.OnCancel != nil {
+			overlay.OnCancel()
+		}
+	}
+
 	return nil
 }
 
+// pushInstance commits and pushes the changes of instance in the background, amending claude-squad's last commit
+// if amend is true. The status line shows the push until instancePushedMsg reports how it went.
+func (m *home) pushInstance(instance *session.Instance, amend bool) tea.Cmd {
+	if m.pushing == nil {
+		m.pushing = make(map[*session.Instance]bool)
+	}
+	m.pushing[instance] = true
+	m.gitResult = ""
+
+	// Default commit message with timestamp
+	commitMsg := fmt.Sprintf("%s update from '%s' on %s", git.CommitPrefix, instance.Title, time.Now().Format(time.RFC822))
+	return func() tea.Msg {
+		worktree, err := instance.GetGitWorktree()
+		if err != nil {
+			return instancePushedMsg{instance: instance, err: err}
+		}
+		if err := worktree.PushChanges(commitMsg, true, amend); err != nil {
+			return instancePushedMsg{instance: instance, err: err}
+		}
+		branch := worktree.GetBranchName()
+		instance.RecordEvent(session.EventPushed, branch)
+		return instancePushedMsg{instance: instance, branch: branch}
+	}
+}
+
+// rebaseInstance fetches the base branch of instance and rebases the instance branch onto it in the background.
+// onto is only shown in the status line, which shows the rebase until instanceRebasedMsg reports how it went.
+func (m *home) rebaseInstance(instance *session.Instance, onto string) tea.Cmd {
+	if m.rebasing == nil {
+		m.rebasing = make(map[*session.Instance]string)
+	}
+	m.rebasing[instance] = onto
+	m.gitResult = ""
+
+	return func() tea.Msg {
+		worktree, err := instance.GetGitWorktree()
+		if err != nil {
+			return instanceRebasedMsg{instance: instance, err: err}
+		}
+		onto, err := worktree.Rebase()
+		if err != nil {
+			return instanceRebasedMsg{instance: instance, err: err}
+		}
+		instance.RecordEvent(session.EventRebased, onto)
+		return instanceRebasedMsg{instance: instance, onto: onto}
+	}
+}
+
+// maxPushSummaryFiles is the number of files listed in the push confirmation. The rest are counted.
+const maxPushSummaryFiles = 5
+
+// pushSummaryCmd computes the diff of the instance's branch, which is what a push sends, and returns it in a
+// pushSummaryMsg. If amend is true, it also checks whether the push can amend claude-squad's last commit.
+func pushSummaryCmd(instance *session.Instance, amend bool) tea.Cmd {
+	return func() tea.Msg {
+		worktree, err := instance.GetGitWorktree()
+		if err != nil {
+			return pushSummaryMsg{instance: instance, err: err}
+		}
+		stats := worktree.Diff(git.DiffOptions{})
+		if stats.Error != nil {
+			return pushSummaryMsg{instance: instance, err: stats.Error}
+		}
+		if amend {
+			if amend, err = worktree.CanAmend(); err != nil {
+				log.WarningLog.Printf("could not check if the last commit of '%s' can be amended: %v", instance.Title, err)
+			}
+		}
+		return pushSummaryMsg{instance: instance, stats: stats, amend: amend}
+	}
+}
+
+// pushConfirmMessage returns the push confirmation for the instance titled title, summarizing stats like
+// "[!] Push 3 files (+120 -14) from 'title'?" followed by the first files. It asks to amend and push if amend is
+// true.
+func pushConfirmMessage(title string, stats *git.DiffStats, amend bool) string {
+	verb := "Push"
+	if amend {
+		verb = "Amend and push"
+	}
+	counts := fmt.Sprintf("+%d -%d", stats.Added, stats.Removed)
+	var b strings.Builder
+	switch len(stats.Files) {
+	case 0:
+		fmt.Fprintf(&b, "[!] %s changes (%s) from '%s'?", verb, counts, title)
+	case 1:
+		fmt.Fprintf(&b, "[!] %s 1 file (%s) from '%s'?", verb, counts, title)
+	default:
+		fmt.Fprintf(&b, "[!] %s %d files (%s) from '%s'?", verb, len(stats.Files), counts, title)
+	}
+	if len(stats.Files) > 0 {
+		b.WriteString("\n")
+	}
+	for i, file := range stats.Files {
+		if i == maxPushSummaryFiles {
+			fmt.Fprintf(&b, "\n  and %d more", len(stats.Files)-i)
+			break
+		}
+		if file.Binary {
+			fmt.Fprintf(&b, "\n  %s (binary)", file.Path)
+		} else {
+			fmt.Fprintf(&b, "\n  %s +%d -%d", file.Path, file.Added, file.Removed)
+		}
+	}
+	return b.String()
+}
+
+// checkpointCmd commits the work of instance on a new checkpoint branch in the background.
+func checkpointCmd(instance *session.Instance) tea.Cmd {
+	return func() tea.Msg {
+		branch, err := instance.Checkpoint(time.Now())
+		return instanceCheckpointedMsg{instance: instance, branch: branch, err: err}
+	}
+}
+
+// hideGitResultCmd clears result from the status line after a few seconds.
+func hideGitResultCmd(ctx context.Context, result string) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case <-ctx.Done():
+		case <-time.After(3 * time.Second):
+		}
+		return hideGitResultMsg{result: result}
+	}
+}
+
+// gitStatus returns the status line text for the pushes and rebases in flight, like "Pushing 'fix-tests'..." or
+// "Rebasing 'docs' onto origin/main...", or the result of the last one.
+func (m *home) gitStatus() (status string, busy bool) {
+	var titles []string
+	for instance := range m.pushing {
+		titles = append(titles, fmt.Sprintf("'%s'", instance.Title))
+	}
+	var rebases []string
+	for instance, onto := range m.rebasing {
+		rebases = append(rebases, fmt.Sprintf("'%s' onto %s", instance.Title, onto))
+	}
+	if len(titles) == 0 && len(rebases) == 0 {
+		return m.gitResult, false
+	}
+	var statuses []string
+	if len(titles) > 0 {
+		slices.Sort(titles)
+		statuses = append(statuses, fmt.Sprintf("Pushing %s...", strings.Join(titles, ", ")))
+	}
+	if len(rebases) > 0 {
+		slices.Sort(rebases)
+		statuses = append(statuses, fmt.Sprintf("Rebasing %s...", strings.Join(rebases, ", ")))
+	}
+	return strings.Join(statuses, " "), true
+}
+
+// queuedPromptsStatus returns the status line text for the prompts waiting for their instance to be ready, like
+// "⏳ Prompts queued for 'fix' (2), 'docs' (1). Next for 'fix': run the tests", or "" if there are none. The next
+// prompt of the selected instance is shown so it isn't typed again. If that is wider than width, only the number of
+// queued prompts is shown, like "⏳ 3 prompts queued".
+func (m *home) queuedPromptsStatus(width int) string {
+	var counts []string
+	total := 0
+	for _, instance := range m.list.GetInstances() {
+		if n := instance.QueuedPrompts(); n > 0 {
+			counts = append(counts, fmt.Sprintf("'%s' (%d)", instance.Title, n))
+			total += n
+		}
+	}
+	if len(counts) == 0 {
+		return ""
+	}
+	status := fmt.Sprintf("⏳ Prompts queued for %s", strings.Join(counts, ", "))
+	if selected := m.list.GetSelectedInstance(); selected != nil && selected.QueuedPrompts() > 0 {
+		next := strings.Join(strings.Fields(selected.NextQueuedPrompt()), " ")
+		status += fmt.Sprintf(". Next for '%s': %s", selected.Title, runewidth.Truncate(next, 40, "..."))
+	}
+	if runewidth.StringWidth(status) <= width {
+		return status
+	}
+	if total == 1 {
+		return "⏳ 1 prompt queued"
+	}
+	return fmt.Sprintf("⏳ %d prompts queued", total)
+}
+
+// killInstance marks instance as deleting and deletes it in the background.
+func (m *home) killInstance(instance *session.Instance) tea.Cmd {
+	// Mark as deleting immediately so user sees feedback
+	instance.SetStatus(session.Deleting)
+
+	// Start async deletion
+	return deleteInstanceCmd(instance, m.storage)
+}
+
+// shouldConfirm returns whether the named action, one of config.ConfirmActions, asks for confirmation first.
+func (m *home) shouldConfirm(action string) bool {
+	return m.appConfig == nil || m.appConfig.ShouldConfirm(action)
+}
+
+// confirmTickCmd waits up to a second and then reports on the countdown of the given confirmation overlay.
+func (m *home) confirmTickCmd(confirmation *overlay.ConfirmationOverlay) tea.Cmd {
+	return func() tea.Msg {
+		wait := time.Second
+		if remaining, ok := confirmation.Remaining(); ok && remaining < wait {
+			wait = remaining
+		}
+		select {
+		case <-m.ctx.Done():
+		case <-time.After(wait):
+		}
+		return confirmTickMsg{overlay: confirmation}
+	}
+}
+
 func (m *home) View() string {
+	if m.tooSmall() {
+		return m.tooSmallView()
+	}
 	listWithPadding := lipgloss.NewStyle().PaddingTop(1).Render(m.list.String())
 	previewWithPadding := lipgloss.NewStyle().PaddingTop(1).Render(m.tabbedWindow.String())
 	listAndPreview := lipgloss.JoinHorizontal(lipgloss.Top, listWithPadding, previewWithPadding)
+	if m.showOnboarding() {
+		// Explain what to do in place of the empty list and preview
+		listAndPreview = lipgloss.Place(lipgloss.Width(listAndPreview), lipgloss.Height(listAndPreview),
+			lipgloss.Center, lipgloss.Center, onboardingContent())
+	}
 
 	// Show init progress message if present
 	var statusLine string
-	if m.initProgressMessage != "" {
-		statusStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#888888")).
-			Italic(true)
+	statusStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		Italic(true)
+	if m.quitProgress != "" {
+		statusLine = statusStyle.Render(fmt.Sprintf("  %s %s", m.spinner.View(), m.quitProgress))
+	} else if m.initProgressMessage != "" {
 		statusLine = statusStyle.Render(fmt.Sprintf("  %s %s", m.spinner.View(), m.initProgressMessage))
+	} else if status, busy := m.gitStatus(); busy {
+		statusLine = statusStyle.Render(fmt.Sprintf("  %s %s", m.spinner.View(), status))
+	} else if status != "" {
+		statusLine = statusStyle.Render("  " + status)
+	}
+	// Before the first WindowSizeMsg the width isn't known, so nothing is shortened.
+	statusWidth := m.windowWidth
+	if statusWidth == 0 {
+		statusWidth = math.MaxInt
+	}
+	if queued := m.queuedPromptsStatus(statusWidth - lipgloss.Width(statusLine) - 2); queued != "" {
+		statusLine = lipgloss.JoinHorizontal(lipgloss.Top, statusLine,
+			lipgloss.NewStyle().Foreground(lipgloss.Color("#61afef")).Bold(true).Render("  "+queued))
 	}
+	if m.saveFailed {
+		unsaved := lipgloss.NewStyle().Foreground(lipgloss.Color("#ef4444")).Bold(true).
+			Render("  ⚠ Unsaved changes: the last save failed, it is retried on the next change")
+		statusLine = lipgloss.JoinHorizontal(lipgloss.Top, statusLine, unsaved)
+	}
+	// A status line wider than the window would wrap and push the menu off the screen.
+	statusLine = ansi.Truncate(statusLine, statusWidth, "…")
 
 	mainView := lipgloss.JoinVertical(
 		lipgloss.Center,
@@ -1027,6 +3357,62 @@ func (m *home) View() string {
 			log.ErrorLog.Printf("confirmation overlay is nil")
 		}
 		return overlay.PlaceOverlay(0, 0, m.confirmationOverlay.Render(), mainView, true, true)
+	} else if m.state == stateActivity || m.state == stateTmuxSessions {
+		if m.activityOverlay == nil {
+			log.ErrorLog.Printf("activity overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.activityOverlay.Render(), mainView, true, true)
+	} else if m.state == stateKeys {
+		if m.keysOverlay == nil {
+			log.ErrorLog.Printf("keys overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.keysOverlay.Render(), mainView, true, true)
+	} else if m.state == stateTemplate {
+		if m.templatePicker == nil {
+			log.ErrorLog.Printf("template picker is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.templatePicker.Render(), mainView, true, true)
+	} else if m.state == stateSearchResults {
+		if m.searchPicker == nil {
+			log.ErrorLog.Printf("search picker is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.searchPicker.Render(), mainView, true, true)
+	} else if m.state == statePromptTemplate {
+		if m.promptTemplatePicker == nil {
+			log.ErrorLog.Printf("prompt template picker is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.promptTemplatePicker.Render(), mainView, true, true)
+	} else if m.state == statePromptForm {
+		if m.promptForm == nil {
+			log.ErrorLog.Printf("prompt form is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.promptForm.Render(), mainView, true, true)
+	} else if m.state == stateExitChoice {
+		if m.exitChoicePicker == nil {
+			log.ErrorLog.Printf("exit choice picker is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.exitChoicePicker.Render(), mainView, true, true)
+	} else if m.state == stateForwardPrompt {
+		if m.forwardPromptPicker == nil {
+			log.ErrorLog.Printf("forward prompt picker is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.forwardPromptPicker.Render(), mainView, true, true)
+	} else if m.state == stateCheckpoints {
+		if m.checkpointPicker == nil {
+			log.ErrorLog.Printf("checkpoint picker is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.checkpointPicker.Render(), mainView, true, true)
+	} else if m.state == stateSwitcher {
+		if m.switcher == nil {
+			log.ErrorLog.Printf("switcher is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.switcher.Render(), mainView, true, true)
+	} else if m.state == stateRemote || m.state == stateNote || m.state == stateColor || m.state == stateGroup ||
+		m.state == stateSearch || m.state == stateRepoPath || m.state == stateSaveDiff {
+		if m.textInputOverlay == nil {
+			log.ErrorLog.Printf("text input overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.textInputOverlay.Render(), mainView, true, true)
 	}
 
 	return mainView
@@ -2,17 +2,31 @@ package app
 
 import (
 	"claude-squad/config"
+	"claude-squad/keys"
 	"claude-squad/log"
 	"claude-squad/session"
+	"claude-squad/session/git"
+	"claude-squad/session/tmux"
 	"claude-squad/ui"
+	"claude-squad/ui/autocomplete"
 	"claude-squad/ui/overlay"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -32,12 +46,8 @@ func TestMain(m *testing.M) {
 
 // TestConfirmationModalStateTransitions tests state transitions without full instance setup
 func TestConfirmationModalStateTransitions(t *testing.T) {
-	// Create a minimal home struct for testing state transitions
-	h := &home{
-		ctx:       context.Background(),
-		state:     stateDefault,
-		appConfig: config.DefaultConfig(),
-	}
+	// Create a home for testing state transitions
+	h := newTestHome(t)
 
 	t.Run("shows confirmation on D press", func(t *testing.T) {
 		// Simulate pressing 'D'
@@ -112,14 +122,10 @@ func TestConfirmationModalKeyHandling(t *testing.T) {
 	list := ui.NewList(&spinner, false)
 
 	// Create enough of home struct to test handleKeyPress in confirmation state
-	h := &home{
-		ctx:                 context.Background(),
-		state:               stateConfirm,
-		appConfig:           config.DefaultConfig(),
-		list:                list,
-		menu:                ui.NewMenu(),
-		confirmationOverlay: overlay.NewConfirmationOverlay("Kill session?"),
-	}
+	h := newTestHome(t)
+	h.state = stateConfirm
+	h.list = list
+	h.confirmationOverlay = overlay.NewConfirmationOverlay("Kill session?")
 
 	testCases := []struct {
 		name              string
@@ -243,13 +249,8 @@ func TestConfirmationFlowSimulation(t *testing.T) {
 	_ = list.AddInstance(instance)
 	list.SetSelectedInstance(0)
 
-	h := &home{
-		ctx:       context.Background(),
-		state:     stateDefault,
-		appConfig: config.DefaultConfig(),
-		list:      list,
-		menu:      ui.NewMenu(),
-	}
+	h := newTestHome(t)
+	h.list = list
 
 	// Simulate what happens when D is pressed
 	selected := h.list.GetSelectedInstance()
@@ -271,11 +272,7 @@ func TestConfirmationFlowSimulation(t *testing.T) {
 
 // TestConfirmActionWithDifferentTypes tests that confirmAction works with different action types
 func TestConfirmActionWithDifferentTypes(t *testing.T) {
-	h := &home{
-		ctx:       context.Background(),
-		state:     stateDefault,
-		appConfig: config.DefaultConfig(),
-	}
+	h := newTestHome(t)
 
 	t.Run("works with simple action returning nil", func(t *testing.T) {
 		actionCalled := false
@@ -361,11 +358,7 @@ func TestConfirmActionWithDifferentTypes(t *testing.T) {
 
 // TestMultipleConfirmationsDontInterfere tests that multiple confirmations don't interfere with each other
 func TestMultipleConfirmationsDontInterfere(t *testing.T) {
-	h := &home{
-		ctx:       context.Background(),
-		state:     stateDefault,
-		appConfig: config.DefaultConfig(),
-	}
+	h := newTestHome(t)
 
 	// First confirmation
 	action1Called := false
@@ -435,11 +428,7 @@ func TestMultipleConfirmationsDontInterfere(t *testing.T) {
 
 // TestConfirmationModalVisualAppearance tests that confirmation modal has distinct visual appearance
 func TestConfirmationModalVisualAppearance(t *testing.T) {
-	h := &home{
-		ctx:       context.Background(),
-		state:     stateDefault,
-		appConfig: config.DefaultConfig(),
-	}
+	h := newTestHome(t)
 
 	// Create a test confirmation overlay
 	message := "[!] Delete everything?"
@@ -464,3 +453,2944 @@ func TestConfirmationModalVisualAppearance(t *testing.T) {
 	// Test that the danger indicator is preserved
 	assert.Contains(t, rendered, "[!")
 }
+
+func TestSmallTerminal(t *testing.T) {
+	h := newTestHome(t)
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "tiny", Path: t.TempDir(), Program: "claude"})
+	require.NoError(t, err)
+	h.list.AddInstance(instance)()
+	h.showConfirmation("[!] Kill session 'tiny'?")
+
+	for _, size := range [][2]int{{1, 1}, {2, 2}, {5, 3}, {1, 50}, {200, 1}, {39, 30}, {80, 9}} {
+		width, height := size[0], size[1]
+		require.NotPanics(t, func() { h.Update(tea.WindowSizeMsg{Width: width, Height: height}) }, "%dx%d", width, height)
+
+		previewWidth, previewHeight := h.tabbedWindow.GetPreviewSize()
+		assert.Positive(t, previewWidth, "%dx%d", width, height)
+		assert.Positive(t, previewHeight, "%dx%d", width, height)
+
+		var view string
+		require.NotPanics(t, func() { view = h.View() }, "%dx%d", width, height)
+		assert.LessOrEqual(t, lipgloss.Width(view), width, "%dx%d", width, height)
+		assert.LessOrEqual(t, lipgloss.Height(view), height, "%dx%d", width, height)
+		if width >= 30 && height >= 3 {
+			assert.Contains(t, ansi.Strip(view), "Terminal too small", "%dx%d", width, height)
+		}
+	}
+
+	// Making the terminal large enough brings the layout back.
+	h.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	view := ansi.Strip(h.View())
+	assert.NotContains(t, view, "Terminal too small")
+	assert.Contains(t, view, "Kill session 'tiny'?")
+}
+
+func TestPinPanes(t *testing.T) {
+	h := newTestHome(t)
+	h.updateHandleWindowSizeEvent(tea.WindowSizeMsg{Width: 120, Height: 40})
+	var instances []*session.Instance
+	for _, title := range []string{"important", "other", "third"} {
+		instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)()
+		instances = append(instances, instance)
+	}
+	h.list.SetSelectedInstance(0)
+	press := func(key string) {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		h.handleKeyPress(msg)
+		if h.keySent {
+			h.handleKeyPress(msg)
+		}
+	}
+
+	press("m")
+	require.Equal(t, instances[0], h.pinned)
+	press("j")
+	press("j")
+
+	assert.Equal(t, instances[2], h.list.GetSelectedInstance(), "navigation still moves the selection")
+	assert.Equal(t, instances[0], h.shownInstance(), "the panes stay on the pinned instance")
+	assert.Contains(t, ansi.Strip(h.View()), "Pinned: important")
+
+	t.Run("a removed instance is unpinned", func(t *testing.T) {
+		h.list.RemoveInstance(instances[0])
+		assert.Equal(t, h.list.GetSelectedInstance(), h.shownInstance())
+		assert.Nil(t, h.pinned)
+		h.list.AddInstance(instances[0])()
+		h.list.SelectInstance(instances[2])
+	})
+
+	t.Run("unpinning follows the selection again", func(t *testing.T) {
+		press("m")
+		require.Equal(t, instances[2], h.pinned, "pinning picks the selected instance")
+		press("m")
+		assert.Nil(t, h.pinned)
+		assert.Equal(t, h.list.GetSelectedInstance(), h.shownInstance())
+		assert.NotContains(t, ansi.Strip(h.View()), "Pinned:")
+	})
+}
+
+func TestOverlaysResize(t *testing.T) {
+	newResizeHome := func() *home {
+		return newTestHome(t)
+	}
+	resize := func(h *home, width, height int) {
+		h.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	}
+
+	t.Run("confirmation adapts to the terminal width", func(t *testing.T) {
+		h := newResizeHome()
+		h.showConfirmation("Kill session?")
+		assert.Equal(t, defaultConfirmationWidth+2, lipgloss.Width(h.confirmationOverlay.Render()),
+			"the default width is used before the terminal size is known")
+
+		resize(h, 200, 50)
+		assert.Equal(t, maxConfirmationWidth+2, lipgloss.Width(h.confirmationOverlay.Render()))
+
+		resize(h, 40, 20)
+		assert.Equal(t, 40, lipgloss.Width(h.confirmationOverlay.Render()), "the dialog fits a narrow terminal")
+		assert.Contains(t, ansi.Strip(h.View()), "Press y to confirm", "the dialog is not clipped")
+	})
+
+	t.Run("an overlay opened after a resize is sized right away", func(t *testing.T) {
+		h := newResizeHome()
+		resize(h, 120, 40)
+		h.showConfirmation("Kill session?")
+		assert.Equal(t, confirmationWidth(120)+2, lipgloss.Width(h.confirmationOverlay.Render()))
+	})
+
+	t.Run("help, text input and prompt overlays follow the terminal", func(t *testing.T) {
+		h := newResizeHome()
+		h.textOverlay = overlay.NewTextOverlay("help")
+		h.textInputOverlay = overlay.NewTextInputOverlay("Enter prompt", "")
+		h.autocompleteInputOverlay = overlay.NewAutocompleteInputOverlay("Enter prompt", "", nil)
+
+		widths := func() []int {
+			return []int{
+				lipgloss.Width(h.textOverlay.Render()),
+				lipgloss.Width(h.textInputOverlay.Render()),
+				lipgloss.Width(h.autocompleteInputOverlay.Render()),
+			}
+		}
+		resize(h, 200, 50)
+		wide := widths()
+		resize(h, 100, 50)
+		narrow := widths()
+		for i := range wide {
+			assert.Less(t, narrow[i], wide[i], "overlay %d", i)
+			assert.LessOrEqual(t, narrow[i], 100, "overlay %d", i)
+		}
+	})
+}
+
+// TestRefreshKeySkipsInactiveInstances tests that ctrl+r is a no-op for instances that aren't running
+func TestRefreshKeySkipsInactiveInstances(t *testing.T) {
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	list := ui.NewList(&spinner, false)
+
+	h := newTestHome(t)
+	h.list = list
+
+	t.Run("no selected instance", func(t *testing.T) {
+		_, cmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlR})
+		assert.Nil(t, cmd)
+	})
+
+	t.Run("instance that hasn't started", func(t *testing.T) {
+		instance, err := session.NewInstance(session.InstanceOptions{
+			Title:   "not-started",
+			Path:    t.TempDir(),
+			Program: "claude",
+		})
+		require.NoError(t, err)
+		list.AddInstance(instance)()
+
+		_, cmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlR})
+		assert.Nil(t, cmd)
+		assert.Nil(t, instance.GetDiffStats())
+	})
+}
+
+func TestCompactListKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	list := ui.NewList(&spinner, false)
+
+	h := newTestHome(t)
+	h.list = list
+
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")}
+	h.handleKeyPress(msg)
+	assert.True(t, list.Compact())
+	assert.True(t, config.LoadConfig().CompactList, "the list mode is saved")
+
+	h.handleKeyPress(msg)
+	assert.False(t, list.Compact())
+	assert.False(t, config.LoadConfig().CompactList)
+}
+
+func TestTemplateKey(t *testing.T) {
+	newHome := func(templates config.Templates) *home {
+		h := newTestHome(t)
+		h.program = "claude"
+		h.templates = templates
+		return h
+	}
+	templateKey := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")}
+
+	t.Run("creating from a template only asks for the title", func(t *testing.T) {
+		h := newHome(config.Templates{
+			{Name: "review", Program: "aider", Args: []string{"--yes"}, Env: map[string]string{"TASK": "review"},
+				InitPrompts: []string{"/review"}, Tags: []string{"review"}},
+			{Name: "bugfix"},
+		})
+
+		h.handleKeyPress(templateKey)
+		require.Equal(t, stateTemplate, h.state)
+		require.NotNil(t, h.templatePicker)
+
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Nil(t, h.templatePicker)
+		assert.Equal(t, stateNew, h.state)
+		require.Equal(t, 1, h.list.NumInstances())
+
+		instance := h.list.GetSelectedInstance()
+		assert.Empty(t, instance.Title)
+		assert.Equal(t, "aider --yes", instance.Program)
+		assert.Equal(t, map[string]string{"TASK": "review"}, instance.Env)
+		assert.Equal(t, []string{"/review"}, instance.InitPrompts)
+		assert.Equal(t, []string{"review"}, instance.Tags)
+	})
+
+	t.Run("templates without a program use the default program", func(t *testing.T) {
+		h := newHome(config.Templates{{Name: "review"}, {Name: "bugfix", Tags: []string{"bug"}}})
+
+		h.handleKeyPress(templateKey)
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+
+		instance := h.list.GetSelectedInstance()
+		require.NotNil(t, instance)
+		assert.Equal(t, "claude", instance.Program)
+		assert.Equal(t, []string{"bug"}, instance.Tags)
+	})
+
+	t.Run("cancelling the picker creates nothing", func(t *testing.T) {
+		h := newHome(config.Templates{{Name: "review"}})
+
+		h.handleKeyPress(templateKey)
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Equal(t, stateDefault, h.state)
+		assert.Equal(t, 0, h.list.NumInstances())
+	})
+
+	t.Run("without templates an error is shown", func(t *testing.T) {
+		h := newHome(nil)
+
+		h.handleKeyPress(templateKey)
+		assert.Equal(t, stateDefault, h.state)
+		assert.Contains(t, h.errBox.String(), "no templates found")
+	})
+}
+
+func TestPromptTemplates(t *testing.T) {
+	newHome := func(t *testing.T) (*home, *session.Instance) {
+		h := newTestHome(t)
+		h.promptHistory = config.LoadPromptHistory(t.TempDir())
+		h.promptTemplates = config.PromptTemplates{
+			{Name: "review", Prompt: "review the changes"},
+			{Name: "fix test", Prompt: "fix {test} in {file}, then run {test} again"},
+		}
+		instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)
+		return h, instance
+	}
+	press := func(h *home, keys ...tea.KeyMsg) {
+		for _, key := range keys {
+			h.handleKeyPress(key)
+		}
+	}
+	typeText := func(h *home, s string) {
+		for _, r := range s {
+			press(h, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	t.Run("the key ignores instances that aren't started", func(t *testing.T) {
+		h, _ := newHome(t)
+		press(h, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.promptTemplatePicker)
+	})
+
+	t.Run("asks for each placeholder once and sends the expanded prompt", func(t *testing.T) {
+		h, instance := newHome(t)
+		h.openPromptTemplatePicker(instance)
+		require.Equal(t, statePromptTemplate, h.state)
+
+		press(h, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}, enter)
+		require.Equal(t, statePromptForm, h.state)
+		require.NotNil(t, h.promptForm)
+		typeText(h, "TestLogin")
+		press(h, enter)
+		typeText(h, "auth_test.go")
+		press(h, enter)
+
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.promptForm)
+		assert.Equal(t, []string{"fix TestLogin in auth_test.go, then run TestLogin again"}, h.promptHistory.Entries())
+		// The instance was never started, so sending it fails.
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "instance not started")
+	})
+
+	t.Run("a template without placeholders is sent right away", func(t *testing.T) {
+		h, instance := newHome(t)
+		h.openPromptTemplatePicker(instance)
+		press(h, enter)
+
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.promptForm)
+		assert.Equal(t, []string{"review the changes"}, h.promptHistory.Entries())
+	})
+
+	t.Run("missing values keep the form open", func(t *testing.T) {
+		h, instance := newHome(t)
+		h.openPromptTemplatePicker(instance)
+		press(h, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}, enter)
+		typeText(h, "TestLogin")
+		press(h, enter, enter)
+
+		assert.Equal(t, statePromptForm, h.state)
+		require.NotNil(t, h.promptForm)
+		assert.False(t, h.promptForm.Submitted)
+		assert.Empty(t, h.promptHistory.Entries())
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "missing values for {file}")
+
+		press(h, tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.promptTemplateTarget)
+	})
+
+	t.Run("cancelling the form sends nothing", func(t *testing.T) {
+		h, instance := newHome(t)
+		h.openPromptTemplatePicker(instance)
+		press(h, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}, enter, tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.promptTemplateTarget)
+		assert.Empty(t, h.promptHistory.Entries())
+	})
+
+	t.Run("without prompt templates an error is shown", func(t *testing.T) {
+		h, instance := newHome(t)
+		h.promptTemplates = nil
+		h.openPromptTemplatePicker(instance)
+		assert.Equal(t, stateDefault, h.state)
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "no prompt templates found")
+	})
+}
+
+func TestRemoteKey(t *testing.T) {
+	newHome := func() *home {
+		h := newTestHome(t)
+		h.program = "claude"
+		return h
+	}
+	// enterRemote types ref into the remote branch input and submits it.
+	enterRemote := func(h *home, ref string) {
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("B")})
+		require.Equal(t, stateRemote, h.state)
+		require.NotNil(t, h.textInputOverlay)
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(ref)})
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyTab})
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	t.Run("a remote branch asks for the title", func(t *testing.T) {
+		h := newHome()
+
+		enterRemote(h, "origin/feature-x")
+		assert.Nil(t, h.textInputOverlay)
+		assert.Equal(t, stateNew, h.state)
+		assert.Equal(t, 1, h.list.NumInstances())
+	})
+
+	t.Run("an invalid remote branch is an error", func(t *testing.T) {
+		h := newHome()
+
+		enterRemote(h, "feature-x")
+		assert.Equal(t, stateDefault, h.state)
+		assert.Equal(t, 0, h.list.NumInstances())
+		assert.Contains(t, h.errBox.String(), "invalid remote branch")
+	})
+
+	t.Run("cancelling creates nothing", func(t *testing.T) {
+		h := newHome()
+
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("B")})
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Nil(t, h.textInputOverlay)
+		assert.Equal(t, stateDefault, h.state)
+		assert.Equal(t, 0, h.list.NumInstances())
+	})
+}
+
+func TestArchivedInstancesAndLimit(t *testing.T) {
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	list := ui.NewList(&spinner, false)
+	for i := 0; i < GlobalInstanceLimit; i++ {
+		instance, err := session.NewInstance(session.InstanceOptions{
+			Title: fmt.Sprintf("task-%d", i), Path: ".", Program: "claude"})
+		require.NoError(t, err)
+		list.AddInstance(instance)
+	}
+	h := newTestHome(t)
+	h.program = "claude"
+	h.list = list
+	// press handles a key like the program does, including the re-sent key press used for menu highlighting.
+	press := func(key string) {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		h.handleKeyPress(msg)
+		if h.keySent {
+			h.handleKeyPress(msg)
+		}
+	}
+
+	press("n")
+	assert.Equal(t, stateDefault, h.state)
+	assert.Contains(t, h.errBox.String(), "can't create more than")
+
+	list.GetInstances()[0].Status = session.Archived
+	press("A")
+	assert.True(t, list.ShowArchived())
+	press("A")
+	assert.False(t, list.ShowArchived())
+
+	press("n")
+	assert.Equal(t, stateNew, h.state, "archived instances don't count against the limit")
+	assert.Len(t, list.GetInstances(), GlobalInstanceLimit+1)
+	assert.Empty(t, list.GetSelectedInstance().Title, "the new instance is selected")
+}
+
+func TestJumpKeyIndex(t *testing.T) {
+	testCases := []struct {
+		key      string
+		expected int
+		ok       bool
+	}{
+		{key: "alt+1", expected: 0, ok: true},
+		{key: "alt+9", expected: 8, ok: true},
+		{key: "alt+0", ok: false},
+		{key: "1", ok: false},
+		{key: "alt+a", ok: false},
+		{key: "alt+10", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			index, ok := jumpKeyIndex(tc.key)
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.Equal(t, tc.expected, index)
+			}
+		})
+	}
+}
+
+// TestJumpToInstanceByNumber tests that alt+N selects the Nth instance and ignores out of range numbers
+func TestJumpToInstanceByNumber(t *testing.T) {
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	list := ui.NewList(&spinner, false)
+	for _, title := range []string{"first", "second", "third"} {
+		instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		list.AddInstance(instance)()
+	}
+
+	h := newTestHome(t)
+	h.list = list
+	altKey := func(r rune) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}, Alt: true}
+	}
+
+	h.handleKeyPress(altKey('3'))
+	assert.Equal(t, "third", list.GetSelectedInstance().Title)
+
+	h.handleKeyPress(altKey('1'))
+	assert.Equal(t, "first", list.GetSelectedInstance().Title)
+
+	h.handleKeyPress(altKey('5'))
+	assert.Equal(t, "first", list.GetSelectedInstance().Title)
+}
+
+// TestMouseClickSelection tests that clicking a list row selects it and clicking a tab header switches tabs
+func TestMouseClickSelection(t *testing.T) {
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	list := ui.NewList(&spinner, false)
+	for _, title := range []string{"first", "second"} {
+		instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		list.AddInstance(instance)()
+	}
+
+	h := newTestHome(t)
+	h.list = list
+	h.updateHandleWindowSizeEvent(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	// find returns the screen position of the first occurrence of text in the view.
+	find := func(text string) (int, int) {
+		for y, line := range strings.Split(h.View(), "\n") {
+			if i := strings.Index(ansi.Strip(line), text); i != -1 {
+				return ansi.StringWidth(ansi.Strip(line)[:i]), y
+			}
+		}
+		t.Fatalf("%q not found in view", text)
+		return 0, 0
+	}
+	click := func(x, y int) {
+		h.Update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	}
+
+	x, y := find("second")
+	click(x, y)
+	assert.Equal(t, "second", list.GetSelectedInstance().Title)
+
+	x, y = find("Diff")
+	click(x, y)
+	assert.True(t, h.tabbedWindow.IsInDiffTab())
+
+	x, y = find("Logs")
+	click(x, y)
+	assert.Equal(t, ui.LogsTab, h.tabbedWindow.ActiveTab())
+
+	x, y = find("Preview")
+	click(x, y)
+	assert.Equal(t, ui.PreviewTab, h.tabbedWindow.ActiveTab())
+
+	// Clicking the list title doesn't change the selection.
+	x, y = find("Instances")
+	click(x, y)
+	assert.Equal(t, "second", list.GetSelectedInstance().Title)
+}
+
+func TestConfirmationTimeout(t *testing.T) {
+	newConfirmHome := func(timeout int) *home {
+		h := newTestHome(t)
+		h.appConfig = &config.Config{ConfirmTimeout: timeout}
+		return h
+	}
+
+	t.Run("disabled without a timeout", func(t *testing.T) {
+		h := newConfirmHome(0)
+
+		cmd := h.showConfirmation("Kill session?")
+
+		assert.Nil(t, cmd)
+		_, ok := h.confirmationOverlay.Remaining()
+		assert.False(t, ok)
+	})
+
+	t.Run("cancels after the timeout", func(t *testing.T) {
+		h := newConfirmHome(1)
+		cancelled := false
+		cmd := h.confirmAction("Push changes?", nil)
+		require.NotNil(t, cmd)
+		h.confirmationOverlay.OnCancel = func() {
+			cancelled = true
+			h.state = stateDefault
+		}
+		h.confirmationOverlay.SetTimeout(time.Millisecond)
+
+		var msg tea.Msg = cmd()
+		for i := 0; i < 5 && h.state == stateConfirm; i++ {
+			_, next := h.Update(msg)
+			if next != nil {
+				msg = next()
+			}
+		}
+
+		assert.True(t, cancelled)
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.confirmationOverlay)
+	})
+
+	t.Run("shows a countdown", func(t *testing.T) {
+		h := newConfirmHome(30)
+		h.showConfirmation("Kill session?")
+
+		assert.Contains(t, ansi.Strip(h.confirmationOverlay.Render()), "Cancelling in 30s")
+	})
+
+	t.Run("responding stops the timer", func(t *testing.T) {
+		h := newConfirmHome(1)
+		cmd := h.showConfirmation("Kill session?")
+		require.NotNil(t, cmd)
+		answered := h.confirmationOverlay
+
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+		require.Equal(t, stateDefault, h.state)
+		// A new confirmation must not be cancelled by the old timer.
+		h.showConfirmation("Kill session?")
+
+		_, next := h.Update(confirmTickMsg{overlay: answered})
+
+		assert.Nil(t, next)
+		assert.Equal(t, stateConfirm, h.state)
+		assert.NotNil(t, h.confirmationOverlay)
+	})
+}
+
+func TestCopyToClipboard(t *testing.T) {
+	newCopyHome := func() *home {
+		return newTestHome(t)
+	}
+	original := writeClipboard
+	t.Cleanup(func() { writeClipboard = original })
+
+	t.Run("copies and confirms", func(t *testing.T) {
+		var copied string
+		writeClipboard = func(text string) error {
+			copied = text
+			return nil
+		}
+		h := newCopyHome()
+
+		cmd := h.copyToClipboard("branch name", "user/feature")
+
+		assert.Equal(t, "user/feature", copied)
+		assert.Equal(t, "Copied branch name: user/feature", h.gitResult)
+		assert.Empty(t, strings.TrimSpace(ansi.Strip(h.errBox.String())), "it isn't shown as an error")
+		assert.NotNil(t, cmd)
+	})
+
+	t.Run("shows the value without a clipboard tool", func(t *testing.T) {
+		writeClipboard = func(string) error {
+			return fmt.Errorf("no clipboard utilities available")
+		}
+		h := newCopyHome()
+
+		h.copyToClipboard("worktree path", "/tmp/worktree")
+
+		view := ansi.Strip(h.errBox.String())
+		assert.Contains(t, view, "could not copy worktree path")
+		assert.Contains(t, view, "/tmp/worktree")
+	})
+}
+
+func TestTitleFromPrompt(t *testing.T) {
+	newTitleHome := func(cfg *config.Config) *home {
+		h := newTestHome(t)
+		h.appConfig = cfg
+		h.program = "my-agent"
+		return h
+	}
+	// press handles a key like the program does, including the re-sent key press used for menu highlighting.
+	press := func(h *home, msg tea.KeyMsg) {
+		h.handleKeyPress(msg)
+		if h.keySent {
+			h.handleKeyPress(msg)
+		}
+	}
+	runes := func(s string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	t.Run("blank title asks for the prompt and suggests a title", func(t *testing.T) {
+		h := newTitleHome(newTestConfig())
+
+		press(h, runes("N"))
+		require.Equal(t, stateNew, h.state)
+		press(h, tea.KeyMsg{Type: tea.KeyEnter})
+		require.Equal(t, statePrompt, h.state)
+		require.NotNil(t, h.autocompleteInputOverlay)
+
+		press(h, runes("Fix the login bug, please!"))
+		press(h, tea.KeyMsg{Type: tea.KeyCtrlD})
+
+		assert.Equal(t, stateNew, h.state)
+		assert.Equal(t, "fix-the-login-bug-please", h.list.GetSelectedInstance().Title)
+		assert.Equal(t, "Fix the login bug, please!", h.namePrompt)
+
+		// The suggested title can still be edited before the instance starts.
+		press(h, tea.KeyMsg{Type: tea.KeyBackspace})
+		press(h, runes("E"))
+		assert.Equal(t, "fix-the-login-bug-pleasE", h.list.GetSelectedInstance().Title)
+	})
+
+	t.Run("cancelling the prompt goes back to naming", func(t *testing.T) {
+		h := newTitleHome(newTestConfig())
+
+		press(h, runes("N"))
+		press(h, tea.KeyMsg{Type: tea.KeyEnter})
+		require.Equal(t, statePrompt, h.state)
+		press(h, tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Equal(t, stateNew, h.state)
+		assert.Empty(t, h.list.GetSelectedInstance().Title)
+		assert.Empty(t, h.namePrompt)
+		assert.True(t, h.promptAfterName)
+	})
+
+	t.Run("disabled in config", func(t *testing.T) {
+		cfg := newTestConfig()
+		cfg.DisableTitleFromPrompt = true
+		h := newTitleHome(cfg)
+
+		press(h, runes("N"))
+		press(h, tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Equal(t, stateNew, h.state)
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "title cannot be empty")
+	})
+}
+
+func TestMaxTitleLength(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.MaxTitleLength = 40
+	h := newTestHome(t)
+	h.appConfig = cfg
+	h.program = "my-agent"
+	require.NoError(t, h.addNewInstance(session.InstanceOptions{Title: "", Path: ".", Program: "my-agent"}))
+	require.Equal(t, stateNew, h.state)
+
+	// Longer than the default of 32 but within the configured length.
+	title := strings.Repeat("a", 40)
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(title)})
+	assert.Equal(t, title, h.list.GetSelectedInstance().Title)
+	assert.Empty(t, strings.TrimSpace(ansi.Strip(h.errBox.String())))
+
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	assert.Equal(t, title, h.list.GetSelectedInstance().Title, "the title can't get longer than configured")
+	assert.Contains(t, ansi.Strip(h.errBox.String()), "title cannot be longer than 40 characters")
+}
+
+// fakePromptTarget becomes ready for input after readyAfter readiness checks.
+type fakePromptTarget struct {
+	readyAfter int
+	sendErr    error
+	checks     int
+	timeouts   []time.Duration
+	sent       []string
+}
+
+func (f *fakePromptTarget) WaitForInputReady(timeout time.Duration) error {
+	f.checks++
+	f.timeouts = append(f.timeouts, timeout)
+	if f.checks < f.readyAfter {
+		return fmt.Errorf("program was not ready for input after %s", timeout)
+	}
+	return nil
+}
+
+func (f *fakePromptTarget) SendPrompt(prompt string) error {
+	if f.sendErr != nil {
+		return f.sendErr
+	}
+	f.sent = append(f.sent, prompt)
+	return nil
+}
+
+func TestSendWhenReady(t *testing.T) {
+	original := pendingPromptBackoff
+	pendingPromptBackoff = time.Millisecond
+	t.Cleanup(func() { pendingPromptBackoff = original })
+
+	t.Run("sends once ready", func(t *testing.T) {
+		target := &fakePromptTarget{readyAfter: 1}
+
+		require.NoError(t, sendWhenReady(target, "hello", 7*time.Second))
+		assert.Equal(t, []string{"hello"}, target.sent)
+		assert.Equal(t, []time.Duration{7 * time.Second}, target.timeouts)
+	})
+
+	t.Run("retries until ready", func(t *testing.T) {
+		target := &fakePromptTarget{readyAfter: 3}
+
+		require.NoError(t, sendWhenReady(target, "hello", time.Second))
+		assert.Equal(t, 3, target.checks)
+		assert.Equal(t, []string{"hello"}, target.sent)
+	})
+
+	t.Run("gives up when never ready", func(t *testing.T) {
+		target := &fakePromptTarget{readyAfter: pendingPromptAttempts + 1}
+
+		err := sendWhenReady(target, "hello", time.Second)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not send prompt after 3 attempts")
+		assert.Contains(t, err.Error(), "not ready for input")
+		assert.Equal(t, pendingPromptAttempts, target.checks)
+		assert.Empty(t, target.sent)
+	})
+
+	t.Run("retries failed sends", func(t *testing.T) {
+		target := &fakePromptTarget{sendErr: fmt.Errorf("tmux went away")}
+
+		err := sendWhenReady(target, "hello", time.Second)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tmux went away")
+		assert.Equal(t, pendingPromptAttempts, target.checks)
+	})
+}
+
+func TestFormatInitProgress(t *testing.T) {
+	assert.Equal(t, "[2/4] Launching tmux session...",
+		formatInitProgress(session.InitProgress{Stage: session.StageStartingTmux, Message: "Launching tmux session...", Index: 2, Total: 4}))
+	assert.Equal(t, "Starting...", formatInitProgress(session.InitProgress{Message: "Starting..."}))
+}
+
+func TestInitProgressWarning(t *testing.T) {
+	errBox := ui.NewErrBox()
+	errBox.SetSize(200, 1)
+	h := &home{ctx: context.Background(), errBox: errBox}
+	ch := make(chan session.InitProgress)
+
+	_, cmd := h.Update(instanceProgressMsg{
+		progress: session.InitProgress{Stage: session.StageRunningHook, Message: "Post-create hook failed",
+			Warning: fmt.Errorf("post-create hook \"npm install\" failed: exit status 1"), Index: 2, Total: 5},
+		channel: ch,
+	})
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "[2/5] Post-create hook failed", h.initProgressMessage)
+	assert.Contains(t, ansi.Strip(h.errBox.String()), `post-create hook "npm install" failed`)
+}
+
+func TestCancelStart(t *testing.T) {
+	newLoadingHome := func(t *testing.T) (*home, *session.Instance) {
+		h := newTestHome(t)
+		instance, err := session.NewInstance(session.InstanceOptions{Title: "stuck", Path: t.TempDir(), Program: "my-agent"})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)
+		instance.SetStatus(session.Loading)
+		h.beginStart(instance, nil)
+		h.initProgressMessage = "[2/4] Launching tmux session..."
+		return h, instance
+	}
+	pressEsc := func(h *home) {
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+		if h.keySent {
+			h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+		}
+	}
+
+	t.Run("esc removes the starting instance", func(t *testing.T) {
+		h, _ := newLoadingHome(t)
+
+		pressEsc(h)
+
+		assert.Equal(t, 0, h.list.NumInstances())
+		assert.Empty(t, h.startCancels)
+		assert.Empty(t, h.initProgressMessage)
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "cancelled starting stuck")
+	})
+
+	t.Run("cancelled start is drained quietly", func(t *testing.T) {
+		h, instance := newLoadingHome(t)
+		pressEsc(h)
+		h.errBox.Clear()
+
+		_, cmd := h.Update(instanceStartCompleteMsg{
+			instance: instance,
+			err:      fmt.Errorf("initialization cancelled: %w", context.Canceled),
+		})
+
+		assert.Nil(t, cmd)
+		assert.Empty(t, strings.TrimSpace(ansi.Strip(h.errBox.String())))
+	})
+
+	t.Run("esc only cancels starting instances", func(t *testing.T) {
+		h, instance := newLoadingHome(t)
+		h.Update(instanceStartCompleteMsg{instance: instance, err: fmt.Errorf("boom")})
+		require.Empty(t, h.startCancels)
+
+		pressEsc(h)
+		assert.NotContains(t, ansi.Strip(h.errBox.String()), "cancelled")
+	})
+}
+
+func TestPendingPromptsPerInstance(t *testing.T) {
+	h, _ := newStoredTestHome(t)
+	h.program = "my-agent"
+	press := func(msg tea.KeyMsg) {
+		h.handleKeyPress(msg)
+		if h.keySent {
+			h.handleKeyPress(msg)
+		}
+	}
+	runes := func(s string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+	// startWithPrompt names a new instance and submits a prompt while it is still initializing. The start command
+	// isn't run, so the instance stays loading.
+	startWithPrompt := func(title, prompt string) *session.Instance {
+		press(runes("N"))
+		require.Equal(t, stateNew, h.state)
+		press(runes(title))
+		press(tea.KeyMsg{Type: tea.KeyEnter})
+		require.Equal(t, statePrompt, h.state)
+		press(runes(prompt))
+		press(tea.KeyMsg{Type: tea.KeyCtrlD})
+		require.Equal(t, stateDefault, h.state)
+		return h.list.GetSelectedInstance()
+	}
+
+	first := startWithPrompt("first", "fix the login bug")
+	second := startWithPrompt("second", "write the docs")
+	assert.Equal(t, map[*session.Instance]string{
+		first:  "fix the login bug",
+		second: "write the docs",
+	}, h.pendingPrompts)
+
+	// The second instance finishing takes its own prompt and leaves the first one's waiting.
+	_, cmd := h.Update(instanceStartCompleteMsg{instance: second})
+	require.NotNil(t, cmd)
+	assert.Equal(t, map[*session.Instance]string{first: "fix the login bug"}, h.pendingPrompts)
+
+	// The first instance failing drops only its prompt.
+	h.Update(instanceStartCompleteMsg{instance: first, err: fmt.Errorf("boom")})
+	assert.Empty(t, h.pendingPrompts)
+	assert.Contains(t, ansi.Strip(h.errBox.String()), "boom")
+}
+
+func TestResumeOpen(t *testing.T) {
+	newPausedHome := func(t *testing.T) (*home, *session.Instance) {
+		h := newTestHome(t)
+		instance, err := session.NewInstance(session.InstanceOptions{Title: "paused", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)
+		instance.SetStatus(session.Paused)
+		return h, instance
+	}
+
+	t.Run("failed resume doesn't attach later", func(t *testing.T) {
+		h, instance := newPausedHome(t)
+
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("O")})
+
+		assert.Nil(t, h.resumeOpenInstance)
+		assert.True(t, instance.Paused())
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "cannot resume")
+	})
+
+	t.Run("waits for the program to be ready", func(t *testing.T) {
+		h, instance := newPausedHome(t)
+		instance.SetStatus(session.Running)
+		now := time.Now()
+		h.resumeOpenInstance = instance
+		h.resumeOpenDeadline = now.Add(5 * time.Second)
+
+		assert.Nil(t, h.openResumed(now))
+		assert.Equal(t, instance, h.resumeOpenInstance)
+
+		h.openResumed(now.Add(6 * time.Second))
+		assert.Nil(t, h.resumeOpenInstance)
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "wasn't ready for input after 5 seconds")
+	})
+
+	t.Run("program exited", func(t *testing.T) {
+		h, instance := newPausedHome(t)
+		instance.SetStatus(session.Dead)
+		h.resumeOpenInstance = instance
+		h.resumeOpenDeadline = time.Now().Add(5 * time.Second)
+
+		h.openResumed(time.Now())
+		assert.Nil(t, h.resumeOpenInstance)
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "exited after resuming")
+	})
+
+	t.Run("selecting another instance gives up", func(t *testing.T) {
+		h, instance := newPausedHome(t)
+		instance.SetStatus(session.Ready)
+		other, err := session.NewInstance(session.InstanceOptions{Title: "other", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(other)
+		h.list.SetSelectedInstance(1)
+		h.resumeOpenInstance = instance
+		h.resumeOpenDeadline = time.Now().Add(5 * time.Second)
+
+		assert.Nil(t, h.openResumed(time.Now()))
+		assert.Nil(t, h.resumeOpenInstance)
+		assert.Empty(t, strings.TrimSpace(ansi.Strip(h.errBox.String())))
+	})
+}
+
+func TestConfigurableConfirmations(t *testing.T) {
+	newHome := func(t *testing.T, confirm map[string]bool) (*home, *session.Instance) {
+		appConfig := newTestConfig()
+		appConfig.Confirm = confirm
+		h := newTestHome(t)
+		h.appConfig = appConfig
+		instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)
+		instance.SetStatus(session.Ready)
+		return h, instance
+	}
+	// press handles a key like the program does, including the re-sent key press used for menu highlighting.
+	press := func(h *home, key string) tea.Cmd {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		_, cmd := h.handleKeyPress(msg)
+		if h.keySent {
+			_, cmd = h.handleKeyPress(msg)
+		}
+		return cmd
+	}
+
+	t.Run("kill is confirmed by default", func(t *testing.T) {
+		h, instance := newHome(t, map[string]bool{config.ConfirmPush: false})
+
+		press(h, "D")
+
+		assert.Equal(t, stateConfirm, h.state)
+		assert.Equal(t, instance, h.pendingKillInstance)
+		assert.Equal(t, session.Ready, instance.Status)
+	})
+
+	t.Run("kill without confirmation", func(t *testing.T) {
+		h, instance := newHome(t, map[string]bool{config.ConfirmKill: false})
+
+		cmd := press(h, "D")
+
+		assert.NotNil(t, cmd)
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.confirmationOverlay)
+		assert.Nil(t, h.pendingKillInstance)
+		assert.Equal(t, session.Deleting, instance.Status)
+	})
+
+	changes := &git.DiffStats{Content: "+x", Added: 1, Files: []git.FileStat{{Path: "main.go", Added: 1}}}
+
+	t.Run("push is confirmed by default", func(t *testing.T) {
+		h, instance := newHome(t, map[string]bool{config.ConfirmKill: false})
+
+		require.NotNil(t, press(h, "p"))
+		h.Update(pushSummaryMsg{instance: instance, stats: changes})
+
+		assert.Equal(t, stateConfirm, h.state)
+		require.NotNil(t, h.confirmationOverlay)
+	})
+
+	t.Run("push without confirmation", func(t *testing.T) {
+		h, instance := newHome(t, map[string]bool{config.ConfirmPush: false})
+
+		require.NotNil(t, press(h, "p"))
+		_, cmd := h.Update(pushSummaryMsg{instance: instance, stats: changes})
+
+		assert.NotNil(t, cmd)
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.confirmationOverlay)
+		assert.True(t, h.pushing[instance])
+	})
+}
+
+func TestAsyncPush(t *testing.T) {
+	newHome := func(t *testing.T, confirm map[string]bool) (*home, *session.Instance) {
+		appConfig := newTestConfig()
+		appConfig.Confirm = confirm
+		h := newTestHome(t)
+		h.appConfig = appConfig
+		instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)
+		instance.SetStatus(session.Ready)
+		return h, instance
+	}
+	// push presses the push key and hands the app the summary of changes it asks for.
+	push := func(h *home, instance *session.Instance) tea.Cmd {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")}
+		_, cmd := h.handleKeyPress(msg)
+		if h.keySent {
+			_, cmd = h.handleKeyPress(msg)
+		}
+		if cmd == nil {
+			return nil
+		}
+		changes := &git.DiffStats{Content: "+x", Added: 1, Files: []git.FileStat{{Path: "main.go", Added: 1}}}
+		_, cmd = h.Update(pushSummaryMsg{instance: instance, stats: changes})
+		return cmd
+	}
+
+	t.Run("shows the push until it finishes", func(t *testing.T) {
+		h, instance := newHome(t, map[string]bool{config.ConfirmPush: false})
+
+		cmd := push(h, instance)
+		require.NotNil(t, cmd)
+		assert.True(t, h.pushing[instance])
+		status, busy := h.gitStatus()
+		assert.True(t, busy)
+		assert.Equal(t, "Pushing 'task'...", status)
+
+		// The push key does nothing while the push is in flight.
+		assert.Nil(t, push(h, instance))
+
+		// The instance was never started, so it has no worktree to push.
+		msg := cmd()
+		pushed, ok := msg.(instancePushedMsg)
+		require.True(t, ok, "got %T", msg)
+		require.Error(t, pushed.err)
+
+		h.Update(msg)
+		assert.Empty(t, h.pushing)
+		status, busy = h.gitStatus()
+		assert.False(t, busy)
+		assert.Empty(t, status)
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "failed to push 'task'")
+		assert.NotNil(t, push(h, instance), "the push key works again")
+	})
+
+	t.Run("shows the result for a while", func(t *testing.T) {
+		h, instance := newHome(t, map[string]bool{config.ConfirmPush: false})
+		push(h, instance)
+
+		_, cmd := h.Update(instancePushedMsg{instance: instance, branch: "agent/task"})
+		require.NotNil(t, cmd)
+		status, busy := h.gitStatus()
+		assert.False(t, busy)
+		assert.Equal(t, "Pushed 'task' to agent/task", status)
+
+		h.Update(hideGitResultMsg{result: "Pushed 'other' to agent/other"})
+		status, _ = h.gitStatus()
+		assert.Equal(t, "Pushed 'task' to agent/task", status, "only its own result is cleared")
+		h.Update(hideGitResultMsg{result: status})
+		status, _ = h.gitStatus()
+		assert.Empty(t, status)
+	})
+
+	t.Run("pushes after confirmation", func(t *testing.T) {
+		h, instance := newHome(t, nil)
+
+		push(h, instance)
+		assert.Equal(t, stateConfirm, h.state)
+		assert.Equal(t, instance, h.pendingPushInstance)
+		assert.False(t, h.pushing[instance], "nothing is pushed before it's confirmed")
+
+		cmd := h.dismissConfirmation(true)
+		require.NotNil(t, cmd)
+		assert.Nil(t, h.pendingPushInstance)
+		assert.True(t, h.pushing[instance])
+	})
+
+	t.Run("cancelled push", func(t *testing.T) {
+		h, instance := newHome(t, nil)
+
+		push(h, instance)
+		assert.Nil(t, h.dismissConfirmation(false))
+		assert.Nil(t, h.pendingPushInstance)
+		assert.False(t, h.pushing[instance])
+	})
+}
+
+func TestAsyncAutoCommit(t *testing.T) {
+	appConfig := newTestConfig()
+	appConfig.AutoCommitMinutes = 5
+	h := newTestHome(t)
+	h.appConfig = appConfig
+	dir := t.TempDir()
+	instance, err := session.FromInstanceData(session.InstanceData{
+		Title: "task", Path: dir, Program: "claude", Status: session.Paused,
+		Worktree: session.GitWorktreeData{RepoPath: dir, WorktreePath: dir, SessionName: "task", BranchName: "me/task"},
+	})
+	require.NoError(t, err)
+	instance.SetStatus(session.Ready)
+	h.list.AddInstance(instance)
+	start := time.Now()
+
+	assert.Nil(t, h.autoCommit(start), "the first tick only starts the interval")
+	assert.Nil(t, h.autoCommit(start.Add(time.Minute)), "nothing is due yet")
+
+	cmd := h.autoCommit(start.Add(5 * time.Minute))
+	require.NotNil(t, cmd)
+	assert.True(t, h.autoCommitting)
+	assert.Nil(t, h.autoCommit(start.Add(10*time.Minute)), "nothing is started while the last auto-commits run")
+
+	msg := cmd()
+	require.IsType(t, autoCommittedMsg{}, msg)
+	h.Update(msg)
+	assert.False(t, h.autoCommitting)
+	assert.NotNil(t, h.autoCommit(start.Add(15*time.Minute)))
+}
+
+func TestAutoPauseIdleAppliesStatusInUpdate(t *testing.T) {
+	h, _ := newStoredTestHome(t)
+	h.appConfig.IdlePauseMinutes = 1
+	newInstance := func(title string) *session.Instance {
+		dir := t.TempDir()
+		instance, err := session.FromInstanceData(session.InstanceData{
+			Title: title, Path: dir, Program: "claude", Status: session.Paused,
+			Worktree: session.GitWorktreeData{RepoPath: dir, WorktreePath: dir, SessionName: title, BranchName: "me/" + title},
+		})
+		require.NoError(t, err)
+		instance.SetStatus(session.Ready)
+		h.list.AddInstance(instance)
+		return instance
+	}
+	selected := newInstance("selected")
+	idle := newInstance("idle")
+	h.list.SelectInstance(selected)
+	start := time.Now()
+	idle.MarkActive(start)
+
+	cmd := h.autoPauseIdle(start.Add(2 * time.Minute))
+	require.NotNil(t, cmd)
+	assert.True(t, h.inBackground[idle])
+	assert.False(t, h.inBackground[selected])
+
+	// The pause runs in the background without changing the instance, which is marked paused in Update.
+	msg, ok := cmd().(autoPausedMsg)
+	require.True(t, ok)
+	assert.Equal(t, session.Ready, idle.Status)
+	msg.failed, msg.paused = nil, []*session.Instance{idle}
+	h.Update(msg)
+	assert.Equal(t, session.Paused, idle.Status)
+	assert.Empty(t, h.inBackground)
+	assert.False(t, h.autoPausing)
+}
+
+func TestResendKeyWithoutPrompt(t *testing.T) {
+	h := newTestHome(t)
+	press := func() tea.Cmd {
+		_, cmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(".")})
+		return cmd
+	}
+
+	assert.Nil(t, press(), "no selected instance")
+
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: t.TempDir(), Program: "claude"})
+	require.NoError(t, err)
+	h.list.AddInstance(instance)
+	instance.SetStatus(session.Ready)
+
+	assert.NotNil(t, press())
+	assert.Equal(t, stateDefault, h.state)
+	assert.Contains(t, ansi.Strip(h.errBox.String()), "no prompt has been sent to 'task' yet")
+	assert.Zero(t, instance.QueuedPrompts())
+
+	h.errBox.Clear()
+	instance.SetStatus(session.Paused)
+	assert.Nil(t, press(), "paused instances are skipped")
+	assert.Empty(t, strings.TrimSpace(ansi.Strip(h.errBox.String())))
+}
+
+func TestLoadRepoFilesFromSubdirectory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	repo := t.TempDir()
+	output, err := exec.Command("git", "-C", repo, "init", "-q").CombinedOutput()
+	require.NoError(t, err, string(output))
+	writeFile := func(dir, name, content string) {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	writeFile(repo, ".claude-squad/hotkeys.json", `{"1": "/review"}`)
+	writeFile(repo, ".claude-squad/templates.json", `[{"name": "bugfix"}]`)
+	writeFile(repo, ".claude/commands/review.md", "Review the changes")
+	subdir := filepath.Join(repo, "pkg", "nested")
+	require.NoError(t, os.MkdirAll(subdir, 0755))
+
+	t.Run("a subdirectory loads the files at the repo root", func(t *testing.T) {
+		h := &home{}
+		require.NoError(t, h.loadRepoFiles(subdir))
+
+		assert.Equal(t, "/review", h.hotkeys["1"])
+		assert.Equal(t, []string{"bugfix"}, h.templates.Names())
+		require.Len(t, h.autocompleter.GetSuggestions("/rev"), 1)
+		assert.Equal(t, "/review", h.autocompleter.GetSuggestions("/rev")[0].Value)
+	})
+
+	t.Run("outside a repository the directory itself is used", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(dir, ".claude-squad/hotkeys.json", `{"2": "/commit"}`)
+
+		h := &home{}
+		require.NoError(t, h.loadRepoFiles(dir))
+
+		assert.Equal(t, config.Hotkeys{"2": "/commit"}, h.hotkeys)
+		assert.Empty(t, h.templates)
+		assert.Empty(t, h.autocompleter.GetSuggestions(""))
+	})
+
+	t.Run("parse errors are returned", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(dir, ".claude-squad/hotkeys.json", `{"2": }`)
+
+		h := &home{}
+		err := h.loadRepoFiles(dir)
+
+		var parseErr *config.ParseError
+		require.ErrorAs(t, err, &parseErr)
+		assert.Empty(t, h.hotkeys)
+	})
+}
+
+func TestPushConfirmMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats *git.DiffStats
+		want  string
+	}{
+		{
+			name: "several files",
+			stats: &git.DiffStats{Added: 120, Removed: 14, Files: []git.FileStat{
+				{Path: "app/app.go", Added: 100, Removed: 10},
+				{Path: "README.md", Added: 20, Removed: 4},
+				{Path: "logo.png", Binary: true},
+			}},
+			want: "[!] Push 3 files (+120 -14) from 'task'?\n\n" +
+				"  app/app.go +100 -10\n  README.md +20 -4\n  logo.png (binary)",
+		},
+		{
+			name:  "one file",
+			stats: &git.DiffStats{Added: 2, Files: []git.FileStat{{Path: "main.go", Added: 2}}},
+			want:  "[!] Push 1 file (+2 -0) from 'task'?\n\n  main.go +2 -0",
+		},
+		{
+			name:  "no file stats",
+			stats: &git.DiffStats{Added: 3, Removed: 1, Content: "+a"},
+			want:  "[!] Push changes (+3 -1) from 'task'?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, pushConfirmMessage("task", tt.stats, false))
+		})
+	}
+
+	t.Run("amend", func(t *testing.T) {
+		stats := &git.DiffStats{Added: 2, Files: []git.FileStat{{Path: "main.go", Added: 2}}}
+		assert.Equal(t, "[!] Amend and push 1 file (+2 -0) from 'task'?\n\n  main.go +2 -0",
+			pushConfirmMessage("task", stats, true))
+	})
+
+	t.Run("lists the first files", func(t *testing.T) {
+		stats := &git.DiffStats{}
+		for i := 0; i < maxPushSummaryFiles+3; i++ {
+			stats.Files = append(stats.Files, git.FileStat{Path: fmt.Sprintf("file%d.go", i), Added: 1})
+			stats.Added++
+		}
+
+		message := pushConfirmMessage("task", stats, false)
+
+		assert.Contains(t, message, "[!] Push 8 files (+8 -0) from 'task'?")
+		assert.Contains(t, message, "file4.go")
+		assert.NotContains(t, message, "file5.go")
+		assert.True(t, strings.HasSuffix(message, "\n  and 3 more"), message)
+	})
+}
+
+func TestPushSummary(t *testing.T) {
+	h := newTestHome(t)
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: t.TempDir(), Program: "claude"})
+	require.NoError(t, err)
+	h.list.AddInstance(instance)
+
+	// An instance that was never started has no worktree to diff.
+	msg := pushSummaryCmd(instance, false)()
+	summary, ok := msg.(pushSummaryMsg)
+	require.True(t, ok, "got %T", msg)
+	require.Error(t, summary.err)
+	h.Update(msg)
+	assert.Contains(t, ansi.Strip(h.errBox.String()), "failed to push 'task'")
+	assert.Equal(t, stateDefault, h.state)
+
+	h.Update(pushSummaryMsg{instance: instance, stats: &git.DiffStats{}})
+	assert.Contains(t, ansi.Strip(h.errBox.String()), "no changes to push from 'task'")
+	assert.Equal(t, stateDefault, h.state)
+	assert.Nil(t, h.pendingPushInstance)
+	assert.Empty(t, h.pushing)
+}
+
+// memoryAppState is an AppState that isn't saved.
+type memoryAppState struct {
+	helpScreensSeen uint32
+}
+
+func (s *memoryAppState) GetHelpScreensSeen() uint32 {
+	return s.helpScreensSeen
+}
+
+func (s *memoryAppState) SetHelpScreensSeen(seen uint32) error {
+	s.helpScreensSeen = seen
+	return nil
+}
+
+func TestOnboarding(t *testing.T) {
+	newHome := func(appState config.AppState) *home {
+		h := newTestHome(t)
+		h.appState = appState
+		h.updateHandleWindowSizeEvent(tea.WindowSizeMsg{Width: 200, Height: 50})
+		return h
+	}
+	const welcome = "Welcome to Claude Squad"
+
+	t.Run("shown while there are no sessions", func(t *testing.T) {
+		h := newHome(&memoryAppState{})
+		assert.Contains(t, ansi.Strip(h.View()), welcome)
+
+		instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)
+		assert.NotContains(t, ansi.Strip(h.View()), welcome)
+
+		h.list.RemoveInstance(instance)
+		assert.Contains(t, ansi.Strip(h.View()), welcome)
+	})
+
+	t.Run("esc hides it for good", func(t *testing.T) {
+		appState := &memoryAppState{helpScreensSeen: 1}
+		h := newHome(appState)
+
+		_, cmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Nil(t, cmd)
+		assert.NotContains(t, ansi.Strip(h.View()), welcome)
+		assert.Equal(t, uint32(1)|onboardingMask, appState.helpScreensSeen, "the other help screens stay seen")
+		assert.NotContains(t, ansi.Strip(newHome(appState).View()), welcome, "it stays hidden after a restart")
+	})
+}
+
+func TestKeysReference(t *testing.T) {
+	h := newTestHome(t)
+	h.appState = &memoryAppState{}
+	h.updateHandleWindowSizeEvent(tea.WindowSizeMsg{Width: 120, Height: 40})
+	press := func(msg tea.KeyMsg) {
+		h.handleKeyPress(msg)
+		if h.keySent {
+			h.handleKeyPress(msg)
+		}
+	}
+	question := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")}
+
+	press(question)
+	require.Equal(t, stateHelp, h.state)
+	press(question)
+	require.Equal(t, stateKeys, h.state)
+	require.NotNil(t, h.keysOverlay)
+
+	view := ansi.Strip(h.View())
+	assert.Contains(t, view, "Sessions:")
+	assert.Contains(t, view, "k/up")
+	assert.Contains(t, view, keys.Descriptions[keys.KeyUp].Text)
+
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, stateDefault, h.state)
+	assert.Nil(t, h.keysOverlay)
+}
+
+func TestKeysReferenceLines(t *testing.T) {
+	lines := keysReferenceLines(keys.Cheatsheet(map[string]keys.KeyName{
+		"ctrl+c": keys.KeyQuit,
+		"q":      keys.KeyQuit,
+		"n":      keys.KeyNew,
+	}))
+
+	var stripped []string
+	for _, line := range lines {
+		stripped = append(stripped, ansi.Strip(line))
+	}
+	assert.Equal(t, []string{
+		"Sessions:",
+		"n        - Create a new session",
+		"",
+		"Other:",
+		"q/ctrl-c - Quit",
+	}, stripped)
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Run("uses $VISUAL first", func(t *testing.T) {
+		t.Setenv("VISUAL", "code --wait")
+		t.Setenv("EDITOR", "vim")
+
+		cmd, err := editorCommand("/repo/.claude/commands/fix.md")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"code", "--wait", "/repo/.claude/commands/fix.md"}, cmd.Args)
+	})
+
+	t.Run("then $EDITOR", func(t *testing.T) {
+		t.Setenv("VISUAL", "")
+		t.Setenv("EDITOR", "vim")
+
+		cmd, err := editorCommand("fix.md")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"vim", "fix.md"}, cmd.Args)
+	})
+
+	t.Run("errors without an editor", func(t *testing.T) {
+		t.Setenv("VISUAL", "")
+		t.Setenv("EDITOR", "")
+
+		_, err := editorCommand("fix.md")
+		assert.ErrorIs(t, err, errNoEditor)
+	})
+}
+
+// countingAutocompleter counts its reloads.
+type countingAutocompleter struct {
+	reloads int
+}
+
+func (c *countingAutocompleter) GetSuggestions(string) []autocomplete.Suggestion {
+	return nil
+}
+
+func (c *countingAutocompleter) Reload() error {
+	c.reloads++
+	return nil
+}
+
+func TestEditCommandFile(t *testing.T) {
+	errBox := ui.NewErrBox()
+	errBox.SetSize(200, 1)
+	ac := &countingAutocompleter{}
+	h := &home{ctx: context.Background(), errBox: errBox, autocompleter: ac}
+
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	require.NotNil(t, h.editCommandFile("fix.md"))
+	assert.Contains(t, ansi.Strip(h.errBox.String()), "set $EDITOR to edit commands")
+
+	// The commands are reloaded once the editor exits, even if it failed.
+	assert.Nil(t, h.commandEdited(commandEditedMsg{}))
+	assert.Equal(t, 1, ac.reloads)
+	assert.NotNil(t, h.commandEdited(commandEditedMsg{err: fmt.Errorf("exit status 1")}))
+	assert.Equal(t, 2, ac.reloads)
+	assert.Contains(t, ansi.Strip(h.errBox.String()), "editor failed: exit status 1")
+}
+
+// staticAutocompleter offers a fixed list of suggestions.
+type staticAutocompleter []autocomplete.Suggestion
+
+func (s staticAutocompleter) GetSuggestions(prefix string) []autocomplete.Suggestion {
+	var matches []autocomplete.Suggestion
+	for _, suggestion := range s {
+		if strings.HasPrefix(suggestion.Value, prefix) {
+			matches = append(matches, suggestion)
+		}
+	}
+	return matches
+}
+
+func (s staticAutocompleter) Reload() error {
+	return nil
+}
+
+func TestIsSlashCommand(t *testing.T) {
+	h := &home{autocompleter: staticAutocompleter{{Value: "/commit"}, {Value: "/commit-all"}}}
+
+	assert.True(t, h.isSlashCommand("/commit"))
+	assert.True(t, h.isSlashCommand("/commit-all"))
+	assert.False(t, h.isSlashCommand("/com"), "a prefix of a command isn't a command")
+	assert.False(t, h.isSlashCommand("/path/to/file"))
+	assert.False(t, (&home{}).isSlashCommand("/commit"), "without an autocompleter no command is known")
+}
+
+func TestExternalAttachCommand(t *testing.T) {
+	attachArgs := []string{"tmux", "-L", "claudesquad", "attach-session", "-t", "=claudesquad_fix it"}
+
+	for _, tt := range []struct {
+		name     string
+		template string
+		expected []string
+	}{
+		{
+			name:     "appends the tmux command without placeholders",
+			template: "kitty --single-instance",
+			expected: append([]string{"kitty", "--single-instance"}, attachArgs...),
+		},
+		{
+			name:     "expands {attach} as an argument of its own",
+			template: "gnome-terminal --tab -- {attach}",
+			expected: append([]string{"gnome-terminal", "--tab", "--"}, attachArgs...),
+		},
+		{
+			name:     "joins {attach} into a shell command inside an argument",
+			template: `osascript -e 'tell app "Terminal" to do script "{attach}"'`,
+			expected: []string{"osascript", "-e",
+				`tell app "Terminal" to do script "tmux -L claudesquad attach-session -t '=claudesquad_fix it'"`},
+		},
+		{
+			name:     "replaces {session}",
+			template: `wezterm start -- tmux attach -t "{session}"`,
+			expected: []string{"wezterm", "start", "--", "tmux", "attach", "-t", "claudesquad_fix it"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := externalAttachCommand(tt.template, "claudesquad_fix it", attachArgs)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cmd.Args)
+		})
+	}
+
+	t.Run("errors on an invalid template", func(t *testing.T) {
+		_, err := externalAttachCommand(`xterm -e "tmux`, "s", attachArgs)
+		assert.ErrorContains(t, err, "failed to parse the attach command")
+		_, err = externalAttachCommand("  ", "s", attachArgs)
+		assert.Error(t, err)
+	})
+}
+
+func TestExternalAttached(t *testing.T) {
+	errBox := ui.NewErrBox()
+	errBox.SetSize(200, 1)
+	h := &home{ctx: context.Background(), errBox: errBox}
+
+	assert.Nil(t, h.externalAttached(externalAttachMsg{title: "fix"}))
+	assert.NotNil(t, h.externalAttached(externalAttachMsg{title: "fix", err: fmt.Errorf("exit status 1")}))
+	assert.Contains(t, ansi.Strip(h.errBox.String()), "failed to open 'fix' in a new window: exit status 1")
+}
+
+func TestPreviewRefreshInterval(t *testing.T) {
+	appConfig := newTestConfig()
+	appConfig.PreviewRefreshInterval = 250
+	h := newTestHome(t)
+	h.appConfig = appConfig
+	h.Init()
+	assert.Equal(t, 250*time.Millisecond, h.previewInterval)
+
+	start := time.Now()
+	msg := previewTickCmd(20 * time.Millisecond)()
+	assert.IsType(t, previewTickMsg{}, msg)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestAsyncRebase(t *testing.T) {
+	newHome := func(t *testing.T) (*home, *session.Instance, *memoryState) {
+		h, state := newStoredTestHome(t)
+		instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)
+		instance.SetStatus(session.Ready)
+		return h, instance, state
+	}
+
+	t.Run("shows the rebase until it finishes", func(t *testing.T) {
+		h, instance, _ := newHome(t)
+
+		cmd := h.rebaseInstance(instance, "origin/main")
+		require.NotNil(t, cmd)
+		status, busy := h.gitStatus()
+		assert.True(t, busy)
+		assert.Equal(t, "Rebasing 'task' onto origin/main...", status)
+
+		// Pushing waits for the rebase.
+		_, pushCmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+		if h.keySent {
+			_, pushCmd = h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+		}
+		assert.Nil(t, pushCmd)
+
+		// The instance was never started, so it has no worktree to rebase.
+		msg := cmd()
+		rebased, ok := msg.(instanceRebasedMsg)
+		require.True(t, ok, "got %T", msg)
+		require.Error(t, rebased.err)
+
+		h.Update(msg)
+		assert.Empty(t, h.rebasing)
+		_, busy = h.gitStatus()
+		assert.False(t, busy)
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "failed to rebase 'task'")
+	})
+
+	t.Run("tells the user to resolve conflicts", func(t *testing.T) {
+		h, instance, _ := newHome(t)
+		h.rebaseInstance(instance, "origin/main")
+
+		h.Update(instanceRebasedMsg{
+			instance: instance,
+			err:      &git.RebaseConflictError{Onto: "origin/main", Files: []string{"main.go"}},
+		})
+		assert.Empty(t, h.rebasing)
+		errText := ansi.Strip(h.errBox.String())
+		assert.Contains(t, errText, "'task': rebasing onto origin/main stopped at conflicts in main.go")
+		assert.Contains(t, errText, "attach to resolve them")
+	})
+
+	t.Run("shows the result", func(t *testing.T) {
+		h, instance, state := newHome(t)
+		h.rebaseInstance(instance, "origin/main")
+
+		_, cmd := h.Update(instanceRebasedMsg{instance: instance, onto: "origin/main"})
+		require.NotNil(t, cmd)
+		status, busy := h.gitStatus()
+		assert.False(t, busy)
+		assert.Equal(t, "Rebased 'task' onto origin/main", status)
+		assert.Equal(t, 1, state.saves, "the rebase is saved")
+	})
+
+	t.Run("pushes and rebases at once", func(t *testing.T) {
+		h, instance, _ := newHome(t)
+		other, err := session.NewInstance(session.InstanceOptions{Title: "other", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.rebaseInstance(instance, "main")
+		h.pushInstance(other, false)
+
+		status, busy := h.gitStatus()
+		assert.True(t, busy)
+		assert.Equal(t, "Pushing 'other'... Rebasing 'task' onto main...", status)
+	})
+
+	t.Run("the rebase key ignores instances that aren't started", func(t *testing.T) {
+		h, _, _ := newHome(t)
+		_, cmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("U")})
+		if h.keySent {
+			_, cmd = h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("U")})
+		}
+		assert.Nil(t, cmd)
+		assert.Equal(t, stateDefault, h.state)
+		assert.Empty(t, h.rebasing)
+	})
+}
+
+func TestCheckpoint(t *testing.T) {
+	h := newTestHome(t)
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: t.TempDir(), Program: "claude"})
+	require.NoError(t, err)
+	h.list.AddInstance(instance)
+
+	t.Run("the key ignores instances that aren't started", func(t *testing.T) {
+		_, cmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")})
+		if h.keySent {
+			_, cmd = h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")})
+		}
+		assert.Nil(t, cmd)
+	})
+
+	t.Run("reports a failure", func(t *testing.T) {
+		msg := checkpointCmd(instance)()
+		checkpointed, ok := msg.(instanceCheckpointedMsg)
+		require.True(t, ok, "got %T", msg)
+		require.Error(t, checkpointed.err)
+
+		h.Update(msg)
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "failed to checkpoint 'task'")
+	})
+
+	t.Run("shows the branch", func(t *testing.T) {
+		_, cmd := h.Update(instanceCheckpointedMsg{instance: instance, branch: "me/task-checkpoint-20250102-150405"})
+		require.NotNil(t, cmd)
+		status, busy := h.gitStatus()
+		assert.False(t, busy)
+		assert.Equal(t, "Checkpointed 'task' on me/task-checkpoint-20250102-150405", status)
+	})
+}
+
+func TestObserveContent(t *testing.T) {
+	command := session.JoinArgs([]string{"tmux", "attach-session", "-t", "=claudesquad_fix", "-r"})
+
+	content := ansi.Strip(observeContent("fix", "claudesquad_fix", command, nil))
+	assert.Contains(t, content, "Observe 'fix'")
+	assert.Contains(t, content, "tmux attach-session -t =claudesquad_fix -r")
+	assert.Contains(t, content, "tmux session: claudesquad_fix")
+	assert.Contains(t, content, "copied to your clipboard")
+
+	content = ansi.Strip(observeContent("fix", "claudesquad_fix", command, fmt.Errorf("no clipboard utilities")))
+	assert.Contains(t, content, "could not be copied to your clipboard: no clipboard utilities")
+}
+
+func TestDiffExport(t *testing.T) {
+	h := newTestHome(t)
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "fix login", Path: t.TempDir(), Program: "claude"})
+	require.NoError(t, err)
+	h.list.AddInstance(instance)
+
+	for _, key := range []string{"y", "Y"} {
+		h.errBox.Clear()
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		assert.Equal(t, stateDefault, h.state, key)
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "'fix login' has no changes", key)
+	}
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(cwd, "fix-login.patch"), defaultPatchPath("fix login"))
+	assert.Equal(t, filepath.Join(cwd, "me-fix.patch"), defaultPatchPath("me/fix"))
+}
+
+func TestStatusServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status.sock")
+	get := func(t *testing.T) session.StatusSummary {
+		client := http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}}
+		resp, err := client.Get("http://localhost/status")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var summary session.StatusSummary
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&summary))
+		return summary
+	}
+
+	server, err := startStatusServer(path)
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.Zero(t, get(t).Total)
+
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: t.TempDir(), Program: "claude"})
+	require.NoError(t, err)
+	instance.SetStatus(session.Ready)
+	server.publish(session.Summarize([]*session.Instance{instance}))
+	summary := get(t)
+	assert.Equal(t, map[string]int{"ready": 1}, summary.ByStatus)
+	assert.Equal(t, "task", summary.Instances[0].Title)
+
+	_, err = startStatusServer(path)
+	assert.ErrorContains(t, err, "already in use")
+
+	require.NoError(t, server.Close())
+	assert.NoFileExists(t, path)
+
+	t.Run("replaces a stale socket", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, nil, 0600))
+		server, err := startStatusServer(path)
+		require.NoError(t, err)
+		assert.Zero(t, get(t).Total)
+		require.NoError(t, server.Close())
+	})
+}
+
+func TestStartupInstances(t *testing.T) {
+	newInstance := func(title string, status session.Status) *session.Instance {
+		instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		instance.SetStatus(status)
+		return instance
+	}
+	running := newInstance("running", session.Running)
+	ready := newInstance("ready", session.Ready)
+	paused := newInstance("paused", session.Paused)
+	archived := newInstance("archived", session.Archived)
+	instances := []*session.Instance{running, paused, ready, archived}
+
+	tests := []struct {
+		mode     string
+		shown    []*session.Instance
+		heldBack []*session.Instance
+	}{
+		{mode: config.StartupRestoreAll, shown: instances},
+		{
+			mode:     config.StartupRestoreRunning,
+			shown:    []*session.Instance{running, ready},
+			heldBack: []*session.Instance{paused, archived},
+		},
+		{mode: config.StartupStartEmpty, heldBack: instances},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			shown, heldBack := startupInstances(instances, tt.mode)
+			assert.Equal(t, tt.shown, shown)
+			assert.Equal(t, tt.heldBack, heldBack)
+		})
+	}
+}
+
+func TestSearchOutput(t *testing.T) {
+	h := newTestHome(t)
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: t.TempDir(), Program: "claude"})
+	require.NoError(t, err)
+	h.list.AddInstance(instance)
+
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	require.Equal(t, stateSearch, h.state)
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("panic")})
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyTab})
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// The instance was never started, so it has no output to search.
+	assert.Equal(t, stateDefault, h.state)
+	assert.Nil(t, h.searchPicker)
+	assert.Contains(t, ansi.Strip(h.errBox.String()), `no session's output contains "panic"`)
+}
+
+func TestSearchResultItems(t *testing.T) {
+	first, err := session.NewInstance(session.InstanceOptions{Title: "fix-tests", Path: t.TempDir(), Program: "claude"})
+	require.NoError(t, err)
+	second, err := session.NewInstance(session.InstanceOptions{Title: "docs", Path: t.TempDir(), Program: "claude"})
+	require.NoError(t, err)
+
+	items := searchResultItems([]session.OutputMatch{
+		{Instance: first, Count: 3, Line: "Error: connection refused"},
+		{Instance: second, Count: 1, Line: strings.Repeat("x", 100)},
+	})
+	assert.Equal(t, []string{
+		"fix-tests (3): Error: connection refused",
+		"docs (1): " + strings.Repeat("x", maxSearchSnippetWidth-3) + "...",
+	}, items)
+}
+
+func TestNewInRepo(t *testing.T) {
+	newHome := func() *home {
+		h := newTestHome(t)
+		h.program = "claude"
+		return h
+	}
+	// enterPath opens the repository input with path and submits it.
+	enterPath := func(h *home, path string) {
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")})
+		require.Equal(t, stateRepoPath, h.state)
+		h.openRepoPathInput(path)
+		h.textInputOverlay.FocusIndex = 1
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	t.Run("a non-repository path is rejected", func(t *testing.T) {
+		h := newHome()
+		notRepo := t.TempDir()
+
+		enterPath(h, notRepo)
+		assert.Equal(t, stateRepoPath, h.state, "the path is asked for again")
+		require.NotNil(t, h.textInputOverlay)
+		assert.Equal(t, notRepo, h.textInputOverlay.GetValue())
+		assert.Contains(t, ansi.Strip(h.errBox.String()), notRepo+" is not a git repository")
+		assert.Equal(t, 0, h.list.NumInstances())
+	})
+
+	t.Run("a repository asks for the title", func(t *testing.T) {
+		h := newHome()
+		repo := t.TempDir()
+		out, err := exec.Command("git", "init", "-q", repo).CombinedOutput()
+		require.NoError(t, err, string(out))
+		repo, err = filepath.EvalSymlinks(repo)
+		require.NoError(t, err)
+
+		enterPath(h, repo)
+		assert.Equal(t, stateNew, h.state)
+		require.Equal(t, 1, h.list.NumInstances())
+		assert.Equal(t, repo, h.list.GetSelectedInstance().Path)
+	})
+}
+
+// memoryState is an in-memory config.InstanceStorage that counts how often the instances are saved.
+type memoryState struct {
+	data  json.RawMessage
+	saves int
+	// err, if set, fails the saves.
+	err error
+}
+
+func (m *memoryState) SaveInstances(instancesJSON json.RawMessage) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.data = instancesJSON
+	m.saves++
+	return nil
+}
+
+func (m *memoryState) GetInstances() json.RawMessage {
+	return m.data
+}
+
+func (m *memoryState) DeleteAllInstances() error {
+	m.data = json.RawMessage("[]")
+	return nil
+}
+
+// newTestConfig returns the default config without looking for the claude command, which runs the user's shell
+// and is slow.
+func newTestConfig() *config.Config {
+	return &config.Config{
+		DefaultProgram:     "claude",
+		DaemonPollInterval: 1000,
+		BranchPrefix:       "session/",
+		TmuxSessionPrefix:  "claudesquad_",
+		PromptSubmitKey:    "ctrl+d",
+		PromptReadyTimeout: 5,
+		DetachKey:          "ctrl+q",
+	}
+}
+
+// newTestHome returns a home in the default state with the test config, an empty list and an error box wide
+// enough to show the errors the tests look for. It has no storage, see newStoredTestHome.
+func newTestHome(t *testing.T) *home {
+	t.Helper()
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	errBox := ui.NewErrBox()
+	errBox.SetSize(300, 1)
+	return &home{
+		ctx:          context.Background(),
+		state:        stateDefault,
+		appConfig:    newTestConfig(),
+		list:         ui.NewList(&spinner, false),
+		menu:         ui.NewMenu(),
+		errBox:       errBox,
+		tabbedWindow: ui.NewTabbedWindow(ui.NewPreviewPane(), ui.NewDiffPane()),
+	}
+}
+
+// newStoredTestHome returns a home like newTestHome whose instances are saved to the returned memoryState.
+func newStoredTestHome(t *testing.T) (*home, *memoryState) {
+	t.Helper()
+	state := &memoryState{data: json.RawMessage("[]")}
+	storage, err := session.NewStorage(state)
+	require.NoError(t, err)
+	h := newTestHome(t)
+	h.storage = storage
+	return h, state
+}
+
+func TestUnsavedChanges(t *testing.T) {
+	h, state := newStoredTestHome(t)
+	h.tabbedWindow.SetSize(100, 20)
+	h.list.SetSize(40, 20)
+
+	state.err = fmt.Errorf("disk full")
+	assert.Error(t, h.saveInstances())
+	assert.True(t, h.saveFailed)
+	assert.Contains(t, ansi.Strip(h.View()), "Unsaved changes")
+
+	// The indicator stays after the error message fades.
+	h.errBox.Clear()
+	assert.Contains(t, ansi.Strip(h.View()), "Unsaved changes")
+
+	// The next change saves again and clears it once that works.
+	state.err = nil
+	require.NoError(t, h.saveInstances())
+	assert.False(t, h.saveFailed)
+	assert.Equal(t, 1, state.saves)
+	assert.NotContains(t, ansi.Strip(h.View()), "Unsaved changes")
+}
+
+func TestPlanQuit(t *testing.T) {
+	tests := []struct {
+		mode     string
+		pausable int
+		want     quitPlan
+	}{
+		{mode: config.QuitLeaveRunning, pausable: 2, want: quitLeaveRunning},
+		{mode: config.QuitPauseAll, pausable: 2, want: quitPause},
+		{mode: config.QuitAsk, pausable: 2, want: quitAskToPause},
+		{mode: "", pausable: 2, want: quitLeaveRunning},
+		{mode: config.QuitPauseAll, pausable: 0, want: quitLeaveRunning},
+		{mode: config.QuitAsk, pausable: 0, want: quitLeaveRunning},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s with %d pausable", tt.mode, tt.pausable), func(t *testing.T) {
+			assert.Equal(t, tt.want, planQuit(tt.mode, tt.pausable))
+		})
+	}
+}
+
+func TestPauseOnQuit(t *testing.T) {
+	newHome := func(t *testing.T, mode string) (*home, *memoryState) {
+		h, state := newStoredTestHome(t)
+		h.quitMode = mode
+		return h, state
+	}
+	// The instances were never started, so pausing them fails, which quitting goes on from.
+	newInstances := func(t *testing.T, titles ...string) []*session.Instance {
+		var instances []*session.Instance
+		for _, title := range titles {
+			instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: t.TempDir(), Program: "claude"})
+			require.NoError(t, err)
+			instances = append(instances, instance)
+		}
+		return instances
+	}
+	isQuit := func(cmd tea.Cmd) bool {
+		if cmd == nil {
+			return false
+		}
+		_, ok := cmd().(tea.QuitMsg)
+		return ok
+	}
+
+	t.Run("quits right away without running instances", func(t *testing.T) {
+		for _, mode := range config.QuitModes {
+			h, state := newHome(t, mode)
+			h.list.AddInstance(newInstances(t, "not-started")[0])()
+
+			_, cmd := h.handleQuit()
+			assert.True(t, isQuit(cmd), mode)
+			assert.Equal(t, 1, state.saves, mode)
+		}
+	})
+
+	t.Run("quits without asking when no instance has changes", func(t *testing.T) {
+		h, _ := newHome(t, config.QuitLeaveRunning)
+		h.appConfig.ConfirmQuitWithChanges = true
+		h.list.AddInstance(newInstances(t, "not-started")[0])()
+
+		_, cmd := h.handleQuit()
+		assert.True(t, isQuit(cmd))
+		assert.False(t, h.pendingQuitWithChanges)
+	})
+
+	t.Run("the changes confirmation says what quitting does", func(t *testing.T) {
+		changed, err := session.FromInstanceData(session.InstanceData{
+			Title: "fix", Path: t.TempDir(), Program: "claude", Status: session.Paused,
+			DiffStats: session.DiffStatsData{Added: 3, Removed: 1},
+		})
+		require.NoError(t, err)
+		for mode, outcome := range map[string]string{
+			config.QuitLeaveRunning: "1 session(s) have changes, they keep running:",
+			config.QuitPauseAll:     "1 session(s) have changes, running ones are paused:",
+			config.QuitAsk:          "1 session(s) have changes, you'll be asked whether to pause the running ones:",
+		} {
+			message := quitWithChangesMessage([]*session.Instance{changed}, mode)
+			assert.Contains(t, message, outcome, mode)
+			assert.Contains(t, message, "'fix' +3 -1", mode)
+		}
+	})
+
+	t.Run("cancelling the changes confirmation doesn't quit", func(t *testing.T) {
+		h, state := newHome(t, config.QuitLeaveRunning)
+		h.pendingQuitWithChanges = true
+		h.showConfirmation("[!] Quit?")
+
+		assert.Nil(t, h.dismissConfirmation(false))
+		assert.False(t, h.pendingQuitWithChanges)
+		assert.Equal(t, stateDefault, h.state)
+		assert.Zero(t, state.saves)
+
+		h.pendingQuitWithChanges = true
+		h.showConfirmation("[!] Quit?")
+		assert.True(t, isQuit(h.dismissConfirmation(true)))
+	})
+
+	t.Run("pauses one instance at a time and quits after the last", func(t *testing.T) {
+		h, state := newHome(t, config.QuitPauseAll)
+		instances := newInstances(t, "first", "second")
+
+		h.pauseOnQuit(instances)
+		assert.Equal(t, stateQuitting, h.state)
+		assert.Contains(t, ansi.Strip(h.View()), "Pausing 'first' before quitting (1/2)")
+
+		_, cmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+		assert.Nil(t, cmd, "keys are ignored while pausing")
+
+		_, cmd = h.Update(pauseOnQuitCmd(instances, 0)())
+		require.NotNil(t, cmd)
+		assert.Equal(t, "Pausing 'second' before quitting (2/2), ctrl+c to quit now...", h.quitProgress)
+		assert.Zero(t, state.saves)
+
+		_, cmd = h.Update(cmd())
+		assert.True(t, isQuit(cmd))
+		assert.Equal(t, 1, state.saves, "the instances are saved after pausing")
+	})
+
+	t.Run("asking pauses on confirm", func(t *testing.T) {
+		h, _ := newHome(t, config.QuitAsk)
+		instances := newInstances(t, "task")
+		h.pendingQuitPause = instances
+		h.showConfirmation("pause?")
+
+		_, cmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+		require.NotNil(t, cmd)
+		assert.Equal(t, stateQuitting, h.state)
+		assert.Nil(t, h.pendingQuitPause)
+		assert.Equal(t, quitPausedMsg{instances: instances, index: 0, err: instances[0].PauseQuietly()}, cmd())
+	})
+
+	t.Run("cancelling the question doesn't quit", func(t *testing.T) {
+		for _, key := range []string{"n", "esc"} {
+			h, state := newHome(t, config.QuitAsk)
+			h.pendingQuitPause = newInstances(t, "task")
+			h.showConfirmation("pause?")
+
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+			if key == "esc" {
+				msg = tea.KeyMsg{Type: tea.KeyEsc}
+			}
+			_, cmd := h.handleKeyPress(msg)
+			assert.False(t, isQuit(cmd), key)
+			assert.Zero(t, state.saves, key)
+			assert.Nil(t, h.pendingQuitPause, key)
+			assert.Equal(t, stateDefault, h.state, key)
+		}
+	})
+
+	t.Run("ctrl+c quits while pausing", func(t *testing.T) {
+		h, state := newHome(t, config.QuitPauseAll)
+		h.pauseOnQuit(newInstances(t, "first", "second"))
+
+		_, cmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlC})
+		assert.True(t, isQuit(cmd))
+		assert.Equal(t, 1, state.saves)
+	})
+}
+
+func TestColorKey(t *testing.T) {
+	h, state := newStoredTestHome(t)
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: t.TempDir(), Program: "claude"})
+	require.NoError(t, err)
+	h.list.AddInstance(instance)()
+
+	// enter opens the color input with the key and submits value.
+	enter := func(value string) {
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("#")})
+		require.Equal(t, stateColor, h.state)
+		h.openColorInput(instance, value)
+		h.textInputOverlay.FocusIndex = 1
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	enter("purpel 🚀")
+	assert.Equal(t, stateColor, h.state, "the color is asked for again")
+	assert.Equal(t, "purpel 🚀", h.textInputOverlay.GetValue())
+	assert.Contains(t, ansi.Strip(h.errBox.String()), `unknown color "purpel"`)
+	assert.Empty(t, instance.Color)
+	h.textInputOverlay = nil
+	h.state = stateDefault
+
+	enter("purple 🚀")
+	assert.Equal(t, stateDefault, h.state)
+	assert.Equal(t, "purple", instance.Color)
+	assert.Equal(t, "🚀", instance.Icon)
+	assert.Equal(t, 1, state.saves)
+	assert.Equal(t, "purple 🚀", instance.ColorIconInput())
+}
+
+func TestGroupKeys(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	h, state := newStoredTestHome(t)
+	var instances []*session.Instance
+	for _, title := range []string{"first", "second"} {
+		instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)()
+		instances = append(instances, instance)
+	}
+	press := func(key string) {
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+
+	t.Run("fold does nothing without groups", func(t *testing.T) {
+		press("z")
+		assert.Empty(t, h.appConfig.CollapsedGroups)
+		assert.Equal(t, instances[0], h.list.GetSelectedInstance())
+	})
+
+	t.Run("g sets the group", func(t *testing.T) {
+		h.list.SelectInstance(instances[1])
+		press("g")
+		require.Equal(t, stateGroup, h.state)
+		h.textInputOverlay.HandleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("  api   work ")})
+		h.textInputOverlay.FocusIndex = 1
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Equal(t, stateDefault, h.state)
+		assert.Equal(t, "api work", instances[1].Group)
+		assert.Equal(t, 1, state.saves)
+		assert.Equal(t, instances[1], h.list.GetSelectedInstance())
+	})
+
+	t.Run("z collapses the selected group and saves it", func(t *testing.T) {
+		press("z")
+		assert.Nil(t, h.list.GetSelectedInstance(), "the header is selected")
+		assert.Equal(t, []string{"api work"}, h.appConfig.CollapsedGroups)
+		assert.Equal(t, []string{"api work"}, config.LoadConfig().CollapsedGroups)
+
+		// Keys for an instance do nothing on a header.
+		press("g")
+		assert.Equal(t, stateDefault, h.state)
+	})
+
+	t.Run("new instances join the selected group", func(t *testing.T) {
+		require.NoError(t, h.addNewInstance(session.InstanceOptions{Title: "third", Path: t.TempDir(),
+			Program: "claude"}))
+		added := h.list.GetSelectedInstance()
+		require.NotNil(t, added, "the collapsed group is expanded to select the new instance")
+		assert.Equal(t, "api work", added.Group)
+	})
+}
+
+func TestUnseenOutputClearedOnSelect(t *testing.T) {
+	h := newTestHome(t)
+	var instances []*session.Instance
+	for _, title := range []string{"first", "second"} {
+		instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)()
+		instance.MarkActive(time.Now())
+		instances = append(instances, instance)
+	}
+	h.list.SetSelectedInstance(0)
+	first, second := instances[0], instances[1]
+
+	// The second instance prints output while the first is selected.
+	first.TrackUnseen(true, true)
+	second.TrackUnseen(true, false)
+	assert.False(t, first.HasUnseenOutput())
+	assert.True(t, second.HasUnseenOutput())
+
+	h.list.Down()
+	h.instanceChanged()
+	assert.False(t, second.HasUnseenOutput(), "selecting the instance sees its output")
+}
+
+func TestToggleAutoYes(t *testing.T) {
+	h, state := newStoredTestHome(t)
+	h.appState = &memoryAppState{}
+	h.list.SetSize(60, 20)
+	newInstance := func(title string) *session.Instance {
+		instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		return instance
+	}
+	shown, held := newInstance("shown"), newInstance("held")
+	h.list.AddInstance(shown)
+	h.heldBack = []*session.Instance{held}
+	press := func() {
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlY})
+	}
+
+	press()
+	assert.True(t, h.autoYes)
+	assert.True(t, shown.AutoYes)
+	assert.True(t, held.AutoYes)
+	assert.Contains(t, h.list.String(), "auto-yes")
+	assert.Equal(t, "Auto-yes on for all 2 sessions", h.gitResult)
+
+	// Sessions created while it is on follow it.
+	created := newInstance("created")
+	h.list.AddInstance(created)
+	h.Update(instanceStartCompleteMsg{instance: created})
+	assert.True(t, created.AutoYes)
+	h.state = stateDefault // dismiss the help screen shown for the new session
+
+	press()
+	assert.False(t, h.autoYes)
+	for _, instance := range []*session.Instance{shown, held, created} {
+		assert.False(t, instance.AutoYes, instance.Title)
+	}
+	assert.NotContains(t, h.list.String(), "auto-yes")
+	assert.Equal(t, "Auto-yes off for all sessions", h.gitResult)
+	assert.NotContains(t, string(state.data), `"auto_yes":true`)
+
+	later := newInstance("later")
+	h.list.AddInstance(later)
+	h.Update(instanceStartCompleteMsg{instance: later})
+	assert.False(t, later.AutoYes)
+}
+
+func TestExitChoice(t *testing.T) {
+	newHome := func(t *testing.T, status session.Status) (*home, *session.Instance) {
+		h := newTestHome(t)
+		instance, err := session.NewInstance(session.InstanceOptions{Title: "fix", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)
+		instance.SetStatus(status)
+		return h, instance
+	}
+	press := func(h *home, msg tea.KeyMsg) {
+		h.handleKeyPress(msg)
+		if h.keySent {
+			h.handleKeyPress(msg)
+		}
+	}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	t.Run("enter asks what to do with an exited instance", func(t *testing.T) {
+		h, _ := newHome(t, session.Exited)
+
+		press(h, enter)
+
+		require.Equal(t, stateExitChoice, h.state)
+		assert.Contains(t, h.exitChoicePicker.Render(), "The program of 'fix' exited")
+		assert.Equal(t, exitChoiceRestart, h.exitChoicePicker.Selected())
+	})
+
+	t.Run("kill is confirmed", func(t *testing.T) {
+		h, instance := newHome(t, session.Exited)
+		press(h, enter)
+
+		press(h, tea.KeyMsg{Type: tea.KeyDown})
+		press(h, tea.KeyMsg{Type: tea.KeyDown})
+		press(h, enter)
+
+		assert.Equal(t, stateConfirm, h.state)
+		assert.Equal(t, instance, h.pendingKillInstance)
+		assert.Nil(t, h.exitChoicePicker)
+	})
+
+	t.Run("esc leaves the instance alone", func(t *testing.T) {
+		h, instance := newHome(t, session.Dead)
+		press(h, enter)
+		require.Equal(t, stateExitChoice, h.state)
+
+		press(h, tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.exitChoiceTarget)
+		assert.Equal(t, session.Dead, instance.Status)
+	})
+
+	t.Run("describes how the program ended", func(t *testing.T) {
+		_, instance := newHome(t, session.Dead)
+		assert.Equal(t, "The program of 'fix' is no longer running", exitTitle(instance))
+	})
+}
+
+func TestNewInPlaceInstance(t *testing.T) {
+	h := newTestHome(t)
+	h.program = "claude"
+	press := func() {
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("I")})
+	}
+
+	press()
+	require.Equal(t, stateNew, h.state)
+	instance := h.list.GetSelectedInstance()
+	require.NotNil(t, instance)
+	assert.True(t, instance.InPlace())
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, cwd, instance.Path)
+
+	// A second in-place session in the same directory is refused.
+	h.state = stateDefault
+	press()
+	assert.Equal(t, stateDefault, h.state)
+	assert.Contains(t, ansi.Strip(h.errBox.String()), "already runs in place in "+cwd)
+	assert.Equal(t, 1, h.list.NumInstances())
+}
+
+func TestForwardPrompt(t *testing.T) {
+	newHome := func(t *testing.T) (*home, *session.Instance, *memoryState) {
+		h, state := newStoredTestHome(t)
+		// A paused instance restored from storage has a last prompt without running anything.
+		source, err := session.FromInstanceData(session.InstanceData{
+			Title: "source", Path: t.TempDir(), Program: "claude", Status: session.Paused, LastPrompt: "fix the tests",
+		})
+		require.NoError(t, err)
+		h.list.AddInstance(source)
+		require.True(t, h.list.SelectInstance(source))
+		return h, source, state
+	}
+	press := func(h *home, msg tea.KeyMsg) {
+		h.handleKeyPress(msg)
+		if h.keySent {
+			h.handleKeyPress(msg)
+		}
+	}
+	forward := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	t.Run("needs another instance", func(t *testing.T) {
+		h, _, _ := newHome(t)
+
+		press(h, forward)
+
+		assert.Equal(t, stateDefault, h.state)
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "there is no other session to send the last prompt of 'source' to")
+	})
+
+	t.Run("needs a last prompt", func(t *testing.T) {
+		h, _, _ := newHome(t)
+		other, err := session.NewInstance(session.InstanceOptions{Title: "other", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(other)
+		require.True(t, h.list.SelectInstance(other))
+
+		press(h, forward)
+
+		assert.Equal(t, stateDefault, h.state)
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "no prompt has been sent to 'other' yet")
+	})
+
+	t.Run("a running target is submitted the prompt", func(t *testing.T) {
+		h, _, _ := newHome(t)
+		starting, err := session.NewInstance(session.InstanceOptions{Title: "starting", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(starting)
+		busy, err := session.FromInstanceData(session.InstanceData{
+			Title: "busy", Path: t.TempDir(), Program: "claude", Status: session.Paused,
+		})
+		require.NoError(t, err)
+		busy.SetStatus(session.Running)
+		h.list.AddInstance(busy)
+
+		press(h, forward)
+		require.Equal(t, stateForwardPrompt, h.state)
+		assert.Contains(t, h.forwardPromptPicker.Render(), "Send the last prompt of 'source' to")
+		assert.Equal(t, []*session.Instance{starting, busy}, h.forwardPromptTargets)
+		press(h, tea.KeyMsg{Type: tea.KeyDown})
+		press(h, enter)
+
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.forwardPromptPicker)
+		// The busy target queues it like any submitted prompt.
+		assert.Equal(t, 1, busy.QueuedPrompts())
+		assert.Zero(t, starting.QueuedPrompts())
+		assert.Equal(t, "Queued the last prompt of 'source' for 'busy'", h.gitResult)
+	})
+
+	t.Run("a target that can't take prompts yet is sent it once it runs", func(t *testing.T) {
+		h, source, _ := newHome(t)
+		starting, err := session.NewInstance(session.InstanceOptions{Title: "starting", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(starting)
+
+		press(h, forward)
+		press(h, enter)
+
+		assert.Equal(t, 1, starting.QueuedPrompts())
+		assert.Zero(t, source.QueuedPrompts())
+		assert.Equal(t, "The last prompt of 'source' will be sent to 'starting' once it runs", h.gitResult)
+	})
+
+	t.Run("a prompt held for a paused target is saved", func(t *testing.T) {
+		h, _, state := newHome(t)
+		paused, err := session.FromInstanceData(session.InstanceData{
+			Title: "paused", Path: t.TempDir(), Program: "claude", Status: session.Paused,
+		})
+		require.NoError(t, err)
+		h.list.AddInstance(paused)
+
+		press(h, forward)
+		press(h, enter)
+
+		assert.Equal(t, 1, paused.QueuedPrompts())
+		assert.Equal(t, 1, state.saves)
+		var stored []session.InstanceData
+		require.NoError(t, json.Unmarshal(state.data, &stored))
+		require.Len(t, stored, 2)
+		assert.Equal(t, []string{"fix the tests"}, stored[1].QueuedPrompts)
+	})
+
+	t.Run("esc sends nothing", func(t *testing.T) {
+		h, _, _ := newHome(t)
+		other, err := session.NewInstance(session.InstanceOptions{Title: "other", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(other)
+
+		press(h, forward)
+		require.Equal(t, stateForwardPrompt, h.state)
+		press(h, tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.forwardPromptSource)
+		assert.Zero(t, other.QueuedPrompts())
+	})
+}
+
+func TestSwitcher(t *testing.T) {
+	h := newTestHome(t)
+	press := func(msg tea.KeyMsg) {
+		h.handleKeyPress(msg)
+		if h.keySent {
+			h.handleKeyPress(msg)
+		}
+	}
+	typeText := func(s string) {
+		for _, r := range s {
+			press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+	ctrlP := tea.KeyMsg{Type: tea.KeyCtrlP}
+
+	press(ctrlP)
+	assert.Equal(t, stateDefault, h.state, "nothing to switch to")
+
+	var instances []*session.Instance
+	for _, title := range []string{"docs", "fix-tests", "refactor-auth", "old-spike"} {
+		instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)
+		instances = append(instances, instance)
+	}
+	instances[1].Tags = []string{"ci"}
+	instances[3].SetStatus(session.Archived)
+	require.Equal(t, instances[0], h.list.GetSelectedInstance())
+
+	t.Run("jumps to the best match", func(t *testing.T) {
+		press(ctrlP)
+		require.Equal(t, stateSwitcher, h.state)
+		assert.Equal(t, instances[:3], h.switcherInstances, "hidden archived instances aren't offered")
+
+		typeText("fxt")
+		press(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.switcher)
+		assert.Equal(t, instances[1], h.list.GetSelectedInstance())
+	})
+
+	t.Run("matches tags", func(t *testing.T) {
+		h.list.SelectInstance(instances[0])
+		press(ctrlP)
+		typeText("ci")
+		press(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Equal(t, instances[1], h.list.GetSelectedInstance())
+	})
+
+	t.Run("esc keeps the selection", func(t *testing.T) {
+		h.list.SelectInstance(instances[0])
+		press(ctrlP)
+		typeText("auth")
+		press(tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Equal(t, stateDefault, h.state)
+		assert.Equal(t, instances[0], h.list.GetSelectedInstance())
+	})
+}
+
+func TestTmuxSessionsList(t *testing.T) {
+	h := newTestHome(t)
+	original := writeClipboard
+	t.Cleanup(func() { writeClipboard = original })
+	var copied string
+	writeClipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	press := func(msg tea.KeyMsg) {
+		h.handleKeyPress(msg)
+		if h.keySent {
+			h.handleKeyPress(msg)
+		}
+	}
+	ctrlT := tea.KeyMsg{Type: tea.KeyCtrlT}
+
+	press(ctrlT)
+	assert.Equal(t, stateDefault, h.state)
+	assert.Contains(t, ansi.Strip(h.errBox.String()), "there are no sessions to list")
+
+	// Sessions that aren't started or are paused have no tmux session to copy.
+	unstarted, err := session.NewInstance(session.InstanceOptions{Title: "new", Path: t.TempDir(), Program: "claude"})
+	require.NoError(t, err)
+	h.list.AddInstance(unstarted)
+	paused, err := session.FromInstanceData(session.InstanceData{
+		Title: "old", Path: t.TempDir(), Program: "claude", Status: session.Paused,
+	})
+	require.NoError(t, err)
+	h.list.AddInstance(paused)
+
+	press(ctrlT)
+	require.Equal(t, stateTmuxSessions, h.state)
+	assert.Empty(t, h.tmuxSessionNames)
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	assert.Equal(t, stateDefault, h.state)
+	assert.Empty(t, copied)
+	assert.Contains(t, ansi.Strip(h.errBox.String()), "none of the tmux sessions is open")
+
+	for _, title := range []string{"fix", "refactor auth"} {
+		// A paused instance restored from storage counts as started; running it again opens its session.
+		instance, err := session.FromInstanceData(session.InstanceData{
+			Title: title, Path: t.TempDir(), Program: "claude", Status: session.Paused,
+		})
+		require.NoError(t, err)
+		instance.SetStatus(session.Running)
+		h.list.AddInstance(instance)
+	}
+
+	press(ctrlT)
+	require.Equal(t, stateTmuxSessions, h.state)
+	assert.Equal(t, []string{tmux.TmuxPrefix + "fix", tmux.TmuxPrefix + "refactorauth"}, h.tmuxSessionNames)
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	assert.Equal(t, stateDefault, h.state)
+	assert.Nil(t, h.activityOverlay)
+	assert.Equal(t, tmux.TmuxPrefix+"fix\n"+tmux.TmuxPrefix+"refactorauth", copied)
+	assert.Equal(t, "Copied 2 tmux session names", h.gitResult)
+
+	copied = ""
+	press(ctrlT)
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, stateDefault, h.state)
+	assert.Empty(t, copied, "esc copies nothing")
+}
+
+func TestTmuxSessionLines(t *testing.T) {
+	entries := []session.TmuxSessionEntry{
+		{Title: "fix", Name: "claudesquad_fix", Status: "running", Open: true},
+		{Title: "refactor auth", Name: "claudesquad_refactorauth", Status: "paused"},
+	}
+
+	assert.Equal(t, []string{
+		"claudesquad_fix           running",
+		"claudesquad_refactorauth  paused (closed)",
+	}, tmuxSessionLines(entries, "tmux"))
+
+	lines := tmuxSessionLines(entries, "tmux -L claudesquad")
+	assert.Equal(t, "The sessions run on their own tmux server, use tmux -L claudesquad to reach them.",
+		lines[len(lines)-1])
+}
+
+func TestCheckpointLines(t *testing.T) {
+	at := time.Date(2025, 1, 2, 15, 4, 5, 0, time.Local)
+	commits := []git.CheckpointCommit{
+		{Hash: "1a2b3c4d5e", Time: at, Subject: git.AutoCommitPrefix + " of 'fix'", Files: 2, Added: 3, Removed: 1},
+		{Hash: "5e6f7a8b9c", Time: at.Add(-time.Hour), Subject: git.CheckpointPrefix + " of 'fix'", Files: 1, Added: 4},
+	}
+
+	assert.Equal(t, []string{
+		"1a2b3c4  Jan 02 15:04  auto-commit  2 files +3 -1",
+		"5e6f7a8  Jan 02 14:04  checkpoint   1 file +4 -0",
+	}, checkpointLines(commits))
+}
+
+func TestRestoreCheckpoint(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	repo := t.TempDir()
+	gitRun := func(args ...string) string {
+		output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(output))
+		return strings.TrimSpace(string(output))
+	}
+	gitRun("init", "-q", "-b", "me/fix")
+	gitRun("commit", "-q", "--allow-empty", "-m", "initial")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "notes.txt"), []byte("first\n"), 0644))
+	gitRun("add", ".")
+	gitRun("commit", "-q", "-m", git.AutoCommitPrefix+" of 'fix'")
+	restored := gitRun("rev-parse", "HEAD")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "notes.txt"), []byte("second\n"), 0644))
+	gitRun("commit", "-q", "-am", "later work")
+
+	h := newTestHome(t)
+	// A paused instance restored from storage has a worktree without running anything.
+	instance, err := session.FromInstanceData(session.InstanceData{
+		Title: "fix", Path: repo, Program: "claude", Status: session.Paused,
+		Worktree: session.GitWorktreeData{RepoPath: repo, WorktreePath: repo, SessionName: "fix", BranchName: "me/fix"},
+	})
+	require.NoError(t, err)
+	h.list.AddInstance(instance)
+	require.True(t, h.list.SelectInstance(instance))
+
+	require.Nil(t, h.openCheckpoints(instance))
+	require.Equal(t, stateCheckpoints, h.state)
+	require.Len(t, h.checkpointCommits, 1)
+	assert.Equal(t, restored, h.checkpointCommits[0].Hash)
+
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, stateConfirm, h.state)
+	confirmation := ansi.Strip(h.confirmationOverlay.Render())
+	assert.Contains(t, confirmation, "Reset 'fix' to the auto-commit "+restored[:7])
+	assert.Contains(t, confirmation, "The 1 commit after it and")
+	assert.Equal(t, "later work", gitRun("log", "-1", "--format=%s"), "nothing is reset before confirming")
+
+	// A push started while the restore was being confirmed keeps the branch as it is.
+	h.pushing = map[*session.Instance]bool{instance: true}
+	h.dismissConfirmation(true)
+	assert.Equal(t, "later work", gitRun("log", "-1", "--format=%s"))
+	assert.Contains(t, ansi.Strip(h.errBox.String()), "'fix' is being pushed or rebased")
+
+	delete(h.pushing, instance)
+	h.openCheckpoints(instance)
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, stateConfirm, h.state)
+	h.dismissConfirmation(true)
+	assert.Equal(t, stateDefault, h.state)
+	assert.Equal(t, restored, gitRun("rev-parse", "HEAD"))
+	content, err := os.ReadFile(filepath.Join(repo, "notes.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", string(content))
+	assert.Equal(t, "Restored 'fix' to "+restored[:7], h.gitResult)
+}
+
+func TestHelpScreensSeen(t *testing.T) {
+	newHome := func(appState config.AppState) *home {
+		h := newTestHome(t)
+		h.appState = appState
+		h.updateHandleWindowSizeEvent(tea.WindowSizeMsg{Width: 200, Height: 50})
+		return h
+	}
+	attachMask := helpTypeInstanceAttach{}.mask()
+
+	t.Run("shown until dismissed", func(t *testing.T) {
+		appState := &memoryAppState{}
+		h := newHome(appState)
+		attached := 0
+		attach := func() { attached++ }
+
+		h.showHelpScreen(helpTypeInstanceAttach{}, attach)
+		require.Equal(t, stateHelp, h.state)
+		assert.Zero(t, attached)
+		assert.Zero(t, appState.helpScreensSeen&attachMask, "it isn't seen before it is dismissed")
+
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, stateDefault, h.state)
+		assert.Equal(t, 1, attached)
+		assert.Equal(t, attachMask, appState.helpScreensSeen)
+
+		h.showHelpScreen(helpTypeInstanceAttach{}, attach)
+		assert.Equal(t, stateDefault, h.state, "the action runs right away once the help was dismissed")
+		assert.Equal(t, 2, attached)
+
+		h.showHelpScreen(helpTypeInstanceCheckout{}, nil)
+		assert.Equal(t, stateHelp, h.state, "other help screens are still shown")
+	})
+
+	t.Run("the general help shows them again", func(t *testing.T) {
+		appState := &memoryAppState{helpScreensSeen: tipsMask | onboardingMask}
+		h := newHome(appState)
+
+		h.showHelpScreen(helpTypeGeneral{}, nil)
+		require.Equal(t, stateHelp, h.state)
+		_, cmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+		require.NotNil(t, cmd)
+		assert.Equal(t, stateDefault, h.state)
+		assert.Equal(t, onboardingMask, appState.helpScreensSeen,
+			"the onboarding stays dismissed")
+		assert.Equal(t, "The new session, attach and checkout help screens will be shown again", h.gitResult)
+
+		h.showHelpScreen(helpTypeInstanceAttach{}, nil)
+		assert.Equal(t, stateHelp, h.state)
+	})
+}
+
+func TestHotkeys(t *testing.T) {
+	h := newTestHome(t)
+	h.repoConfigDir = "/repo/.claude-squad"
+	h.hotkeys = config.Hotkeys{"1": "/review", "2": " "}
+	// A paused instance restored from storage counts as started; running it again makes it busy, so the hotkey's
+	// command is queued instead of sent.
+	instance, err := session.FromInstanceData(session.InstanceData{
+		Title: "busy", Path: t.TempDir(), Program: "claude", Status: session.Paused,
+	})
+	require.NoError(t, err)
+	instance.SetStatus(session.Running)
+	h.list.AddInstance(instance)
+	h.list.SetSelectedInstance(0)
+	press := func(key string) {
+		h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+
+	press("1")
+	assert.Equal(t, 1, instance.QueuedPrompts())
+	assert.Equal(t, "/review", instance.NextQueuedPrompt())
+
+	for _, key := range []string{"2", "3"} {
+		press(key)
+		assert.Equal(t, 1, instance.QueuedPrompts(), "%s isn't queued", key)
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "no command is bound to "+key, key)
+	}
+}
+
+func TestHelpShowsAutoYesKey(t *testing.T) {
+	binding := keys.GlobalkeyBindings[keys.KeyAutoYes]
+	t.Cleanup(func() { keys.GlobalkeyBindings[keys.KeyAutoYes] = binding })
+
+	assert.Contains(t, helpTypeGeneral{}.toContent(), "ctrl-y")
+	keys.GlobalkeyBindings[keys.KeyAutoYes] = key.NewBinding(key.WithKeys("ctrl+a"), key.WithHelp("ctrl+a", "auto-yes"))
+	content := helpTypeGeneral{}.toContent()
+	assert.Contains(t, content, "ctrl-a")
+	assert.NotContains(t, content, "ctrl-y")
+}
+
+func TestQueuedPromptsStatus(t *testing.T) {
+	h := newTestHome(t)
+	h.appState = &memoryAppState{}
+	h.updateHandleWindowSizeEvent(tea.WindowSizeMsg{Width: 200, Height: 50})
+	newInstance := func(title string) *session.Instance {
+		instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)
+		return instance
+	}
+	fix := newInstance("fix")
+	docs := newInstance("docs")
+	require.True(t, h.list.SelectInstance(fix))
+
+	assert.Empty(t, h.queuedPromptsStatus(200))
+	assert.NotContains(t, ansi.Strip(h.View()), "Prompts queued")
+
+	docs.QueuePrompt("update the readme")
+	assert.Equal(t, "⏳ Prompts queued for 'docs' (1)", h.queuedPromptsStatus(200))
+
+	fix.QueuePrompt("run   the\ntests")
+	fix.QueuePrompt("fix them")
+	assert.Equal(t, "⏳ Prompts queued for 'fix' (2), 'docs' (1). Next for 'fix': run the tests",
+		h.queuedPromptsStatus(200))
+	assert.Contains(t, ansi.Strip(h.View()), "Prompts queued for 'fix' (2), 'docs' (1)")
+
+	require.True(t, h.list.SelectInstance(docs))
+	assert.Equal(t, "⏳ Prompts queued for 'fix' (2), 'docs' (1). Next for 'docs': update the readme",
+		h.queuedPromptsStatus(200))
+
+	t.Run("summarises the prompts if they don't fit", func(t *testing.T) {
+		assert.Equal(t, "⏳ 3 prompts queued", h.queuedPromptsStatus(40))
+	})
+
+	t.Run("keeps the status line within the window", func(t *testing.T) {
+		h.updateHandleWindowSizeEvent(tea.WindowSizeMsg{Width: 80, Height: 50})
+		h.saveFailed = true
+		defer func() { h.saveFailed = false }()
+
+		view := h.View()
+		assert.Contains(t, ansi.Strip(view), "3 prompts queued")
+		for _, line := range strings.Split(view, "\n") {
+			assert.LessOrEqual(t, ansi.StringWidth(line), 80, "line %q", ansi.Strip(line))
+		}
+	})
+}
+
+func TestBulkPauseResume(t *testing.T) {
+	press := func(h *home, key string) tea.Cmd {
+		_, cmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		if h.keySent {
+			_, cmd = h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		}
+		return cmd
+	}
+	newHome := func(t *testing.T) (*home, *memoryState) {
+		h, state := newStoredTestHome(t)
+		dir := t.TempDir()
+		instance, err := session.FromInstanceData(session.InstanceData{
+			Title: "task", Path: dir, Program: "claude", Status: session.Paused,
+			Worktree: session.GitWorktreeData{RepoPath: dir, WorktreePath: dir, SessionName: "task", BranchName: "me/task"},
+		})
+		require.NoError(t, err)
+		h.list.AddInstance(instance)
+		return h, state
+	}
+
+	t.Run("says when there is nothing to pause", func(t *testing.T) {
+		h, _ := newHome(t)
+
+		press(h, "C")
+		assert.Equal(t, stateDefault, h.state)
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "no running sessions to pause")
+	})
+
+	t.Run("resumes in the background after confirming", func(t *testing.T) {
+		h, state := newHome(t)
+		h.appConfig.AutoCommitMinutes = 1
+
+		press(h, "R")
+		require.Equal(t, stateConfirm, h.state)
+		assert.Contains(t, ansi.Strip(h.confirmationOverlay.Render()), "Resume 1 paused session(s)?")
+		assert.Zero(t, state.saves, "nothing is resumed before confirming")
+
+		cmd := h.dismissConfirmation(true)
+		require.NotNil(t, cmd)
+		assert.True(t, h.bulkRunning)
+		assert.Nil(t, h.autoCommit(time.Now()), "auto-commits wait for the resumes")
+
+		msg := cmd()
+		done, ok := msg.(bulkDoneMsg)
+		require.True(t, ok, "got %T", msg)
+		h.Update(done)
+		assert.False(t, h.bulkRunning)
+		assert.Equal(t, 1, state.saves)
+		// The paused instance has no branch to resume from.
+		assert.Contains(t, ansi.Strip(h.errBox.String()), "resumed 0 instance(s), 1 failed")
+	})
+
+	t.Run("cancelling keeps the sessions as they are", func(t *testing.T) {
+		h, state := newHome(t)
+
+		press(h, "R")
+		require.Equal(t, stateConfirm, h.state)
+		assert.Nil(t, h.dismissConfirmation(false))
+		assert.False(t, h.pendingBulk)
+		assert.False(t, h.bulkRunning)
+		assert.Zero(t, state.saves)
+	})
+}
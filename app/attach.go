@@ -0,0 +1,82 @@
+package app
+
+import (
+	cmd2 "claude-squad/cmd"
+	"claude-squad/session"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// externalAttachCommand returns the command that attaches to the tmux session name in a new terminal window, from
+// the attach_command template. In the template, {session} is replaced by name. {attach} is replaced by the
+// arguments of attachArgs when it is an argument of its own, and by attachArgs joined into a shell command inside
+// another argument, like a script. If the template has neither, attachArgs are appended.
+func externalAttachCommand(template, name string, attachArgs []string) (*exec.Cmd, error) {
+	args, err := session.SplitArgs(template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the attach command %q: %w", template, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("the attach command is empty")
+	}
+
+	substituted := false
+	expanded := make([]string, 0, len(args)+len(attachArgs))
+	for _, arg := range args {
+		if arg == "{attach}" {
+			expanded = append(expanded, attachArgs...)
+			substituted = true
+			continue
+		}
+		if strings.Contains(arg, "{session}") || strings.Contains(arg, "{attach}") {
+			substituted = true
+		}
+		arg = strings.ReplaceAll(arg, "{session}", name)
+		arg = strings.ReplaceAll(arg, "{attach}", session.JoinArgs(attachArgs))
+		expanded = append(expanded, arg)
+	}
+	if !substituted {
+		expanded = append(expanded, attachArgs...)
+	}
+	return exec.Command(expanded[0], expanded[1:]...), nil
+}
+
+// externalAttachMsg signals that the command opening an instance in a new terminal window exited
+type externalAttachMsg struct {
+	title string
+	err   error
+}
+
+// attachExternally opens the instance in a new terminal window with the attach_command, leaving the app running.
+// Terminals that keep the command running until the window closes are waited for in the background.
+func (m *home) attachExternally(instance *session.Instance) tea.Cmd {
+	attachArgs, err := instance.AttachArgs()
+	if err != nil {
+		return m.handleError(err)
+	}
+	cmd, err := externalAttachCommand(m.appConfig.AttachCommand, instance.TmuxSessionName(), attachArgs)
+	if err != nil {
+		return m.handleError(err)
+	}
+	executor := cmd2.MakeExecutor()
+	return func() tea.Msg {
+		out, err := executor.CombinedOutput(cmd)
+		if err != nil {
+			if output := strings.TrimSpace(string(out)); output != "" {
+				err = fmt.Errorf("%w: %s", err, output)
+			}
+		}
+		return externalAttachMsg{title: instance.Title, err: err}
+	}
+}
+
+// externalAttached shows an error if the attach command failed.
+func (m *home) externalAttached(msg externalAttachMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.handleError(fmt.Errorf("failed to open '%s' in a new window: %w", msg.title, msg.err))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openCheckpoints lists the recent auto-commits and checkpoints of instance to choose one to restore.
+func (m *home) openCheckpoints(instance *session.Instance) tea.Cmd {
+	worktree, err := instance.GetGitWorktree()
+	if err != nil {
+		return m.handleError(err)
+	}
+	commits, err := worktree.CheckpointCommits()
+	if err != nil {
+		return m.handleError(fmt.Errorf("can't list the checkpoints of '%s': %w", instance.Title, err))
+	}
+	if len(commits) == 0 {
+		return m.handleError(fmt.Errorf("'%s' has no auto-commits or checkpoints yet", instance.Title))
+	}
+	m.checkpointInstance = instance
+	m.checkpointCommits = commits
+	m.checkpointPicker = overlay.NewPickerOverlay(
+		fmt.Sprintf("Restore '%s' to", instance.Title), checkpointLines(commits))
+	m.state = stateCheckpoints
+	m.resizeOverlays()
+	return nil
+}
+
+// checkpointLines describes each of commits on a line, like "1a2b3c4  Jan 02 15:04  auto-commit  2 files +3 -1".
+func checkpointLines(commits []git.CheckpointCommit) []string {
+	lines := make([]string, len(commits))
+	for i, commit := range commits {
+		files := fmt.Sprintf("%d files", commit.Files)
+		if commit.Files == 1 {
+			files = "1 file"
+		}
+		lines[i] = fmt.Sprintf("%s  %s  %-11s  %s +%d -%d", commit.ShortHash(),
+			commit.Time.Local().Format("Jan 02 15:04"), checkpointKind(commit), files, commit.Added, commit.Removed)
+	}
+	return lines
+}
+
+// checkpointKind returns whether commit is an auto-commit or a checkpoint.
+func checkpointKind(commit git.CheckpointCommit) string {
+	if strings.HasPrefix(commit.Subject, git.AutoCommitPrefix) {
+		return "auto-commit"
+	}
+	return "checkpoint"
+}
+
+// handleCheckpoints handles a key in the checkpoint picker and asks to confirm restoring the chosen checkpoint.
+func (m *home) handleCheckpoints(msg tea.KeyMsg) tea.Cmd {
+	if !m.checkpointPicker.HandleKeyPress(msg) {
+		return nil
+	}
+	picker := m.checkpointPicker
+	instance := m.checkpointInstance
+	commits := m.checkpointCommits
+	m.checkpointPicker = nil
+	m.checkpointInstance = nil
+	m.checkpointCommits = nil
+	m.state = stateDefault
+	// The instance may have been removed while the picker was open.
+	if !picker.Submitted || !slices.Contains(m.list.GetInstances(), instance) {
+		return tea.WindowSize()
+	}
+	commit := commits[picker.SelectedIndex()]
+	worktree, err := instance.GetGitWorktree()
+	if err != nil {
+		return m.handleError(err)
+	}
+	dropped, err := worktree.CommitsAfter(commit.Hash)
+	if err != nil {
+		return m.handleError(fmt.Errorf("can't restore '%s': %w", instance.Title, err))
+	}
+	m.pendingRestoreInstance = instance
+	m.pendingRestoreCommit = commit
+	return m.showConfirmation(fmt.Sprintf("[!] Reset '%s' to the %s %s from %s? %s",
+		instance.Title, checkpointKind(commit), commit.ShortHash(), commit.Time.Local().Format("Jan 02 15:04"),
+		restoreLoss(dropped)))
+}
+
+// restoreLoss describes what restoring a checkpoint loses when dropped commits come after it.
+func restoreLoss(dropped int) string {
+	switch dropped {
+	case 0:
+		return "Uncommitted changes are lost."
+	case 1:
+		return "The 1 commit after it and uncommitted changes are lost."
+	default:
+		return fmt.Sprintf("The %d commits after it and uncommitted changes are lost.", dropped)
+	}
+}
+
+// restoreCheckpoint resets the branch and worktree of instance to commit. A push or rebase started while the
+// restore was being confirmed keeps the branch as it is.
+func (m *home) restoreCheckpoint(instance *session.Instance, commit git.CheckpointCommit) tea.Cmd {
+	if !slices.Contains(m.list.GetInstances(), instance) {
+		return nil
+	}
+	if m.pushing[instance] || m.rebasing[instance] != "" {
+		return m.handleError(fmt.Errorf("'%s' is being pushed or rebased, restore it once that is done", instance.Title))
+	}
+	worktree, err := instance.GetGitWorktree()
+	if err != nil {
+		return m.handleError(err)
+	}
+	if err := worktree.RestoreCheckpoint(commit.Hash); err != nil {
+		return m.handleError(fmt.Errorf("failed to restore '%s': %w", instance.Title, err))
+	}
+	m.gitResult = fmt.Sprintf("Restored '%s' to %s", instance.Title, commit.ShortHash())
+	return tea.Batch(hideGitResultCmd(m.ctx, m.gitResult), m.instanceChanged())
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"claude-squad/ui/autocomplete"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// instancePatch returns the full diff of instance as a patch. It is computed again rather than taken from the diff
+// tab, which may hide whitespace changes or show fewer context lines than git apply needs.
+func instancePatch(instance *session.Instance) (string, error) {
+	patch, err := instance.Patch()
+	if err != nil {
+		return "", fmt.Errorf("the diff of '%s' could not be computed: %w", instance.Title, err)
+	}
+	if strings.TrimSpace(patch) == "" {
+		return "", fmt.Errorf("'%s' has no changes", instance.Title)
+	}
+	return patch, nil
+}
+
+// diffExportedMsg signals that the diff of an instance was copied or saved, describing it in result, or why not.
+type diffExportedMsg struct {
+	result string
+	err    error
+}
+
+// copyDiff copies the full diff of instance to the clipboard in the background.
+func (m *home) copyDiff(instance *session.Instance) tea.Cmd {
+	if !instance.Started() {
+		return m.handleError(fmt.Errorf("'%s' has no changes", instance.Title))
+	}
+	return func() tea.Msg {
+		patch, err := instancePatch(instance)
+		if err != nil {
+			return diffExportedMsg{err: err}
+		}
+		if err := writeClipboard(patch); err != nil {
+			return diffExportedMsg{err: fmt.Errorf("could not copy the diff of '%s': %w", instance.Title, err)}
+		}
+		return diffExportedMsg{
+			result: fmt.Sprintf("Copied the diff of '%s' (%d lines)", instance.Title, strings.Count(patch, "\n")),
+		}
+	}
+}
+
+// openSaveDiffInput asks where to save the diff of instance, offering <title>.patch in the current directory.
+func (m *home) openSaveDiffInput(instance *session.Instance) tea.Cmd {
+	if !instance.Started() {
+		return m.handleError(fmt.Errorf("'%s' has no changes", instance.Title))
+	}
+	m.textInputOverlay = overlay.NewTextInputOverlay(
+		fmt.Sprintf("Save the diff of %s to (tab completes)", instance.Title), defaultPatchPath(instance.Title))
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = ""
+	}
+	m.textInputOverlay.SetCompleter(func(value string) string {
+		return autocomplete.CompletePath(value, home)
+	})
+	m.state = stateSaveDiff
+	m.resizeOverlays()
+	return nil
+}
+
+// saveDiff writes the full diff of instance as a patch to path in the background. A relative path is relative to
+// the directory claude-squad was started in, and ~ is the home directory.
+func (m *home) saveDiff(instance *session.Instance, path string) tea.Cmd {
+	path, err := session.ExpandHome(strings.TrimSpace(path))
+	if err != nil {
+		return m.handleError(err)
+	}
+	if path == "" {
+		return m.handleError(fmt.Errorf("no file to save the diff of '%s' to", instance.Title))
+	}
+	return func() tea.Msg {
+		patch, err := instancePatch(instance)
+		if err != nil {
+			return diffExportedMsg{err: err}
+		}
+		if err := git.WritePatch(path, patch); err != nil {
+			return diffExportedMsg{err: fmt.Errorf("could not save the diff of '%s': %w", instance.Title, err)}
+		}
+		return diffExportedMsg{result: fmt.Sprintf("Saved the diff of '%s' to %s", instance.Title, path)}
+	}
+}
+
+// defaultPatchPath is <title>.patch in the current directory, with the characters that can't be in a file name
+// replaced.
+func defaultPatchPath(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' {
+			return '-'
+		}
+		return r
+	}, title)
+	if abs, err := filepath.Abs(name + ".patch"); err == nil {
+		return abs
+	}
+	return name + ".patch"
+}
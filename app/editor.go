@@ -0,0 +1,86 @@
+package app
+
+import (
+	cmd2 "claude-squad/cmd"
+	"claude-squad/session"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// errNoEditor is returned when neither $VISUAL nor $EDITOR says which editor to open files in.
+var errNoEditor = errors.New("set $EDITOR to edit commands, e.g. export EDITOR=vim")
+
+// editorCommand returns the command that opens path in the user's editor, $VISUAL or else $EDITOR. The variable
+// may include arguments, like "code --wait".
+func editorCommand(path string) (*exec.Cmd, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args, err := session.SplitArgs(editor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the editor %q: %w", editor, err)
+	}
+	if len(args) == 0 {
+		return nil, errNoEditor
+	}
+	return exec.Command(args[0], append(args[1:], path)...), nil
+}
+
+// executorCommand runs a command with an Executor in the foreground, for tea.Exec. The program gives up the
+// terminal while it runs.
+type executorCommand struct {
+	executor cmd2.Executor
+	cmd      *exec.Cmd
+}
+
+func (c executorCommand) Run() error {
+	return c.executor.Run(c.cmd)
+}
+
+func (c executorCommand) SetStdin(r io.Reader) {
+	c.cmd.Stdin = r
+}
+
+func (c executorCommand) SetStdout(w io.Writer) {
+	c.cmd.Stdout = w
+}
+
+func (c executorCommand) SetStderr(w io.Writer) {
+	c.cmd.Stderr = w
+}
+
+// commandEditedMsg signals that the editor opened on a command file exited
+type commandEditedMsg struct {
+	err error
+}
+
+// editCommandFile opens path in the user's editor in place of the app. The autocompleter is reloaded once the
+// editor exits, so the edits are offered right away.
+func (m *home) editCommandFile(path string) tea.Cmd {
+	cmd, err := editorCommand(path)
+	if err != nil {
+		return m.handleError(err)
+	}
+	return tea.Exec(executorCommand{executor: cmd2.MakeExecutor(), cmd: cmd}, func(err error) tea.Msg {
+		return commandEditedMsg{err: err}
+	})
+}
+
+// commandEdited reloads the autocompleter after a command file was edited.
+func (m *home) commandEdited(msg commandEditedMsg) tea.Cmd {
+	if m.autocompleter != nil {
+		if err := m.autocompleter.Reload(); err != nil {
+			return m.handleError(fmt.Errorf("failed to reload commands: %w", err))
+		}
+	}
+	if msg.err != nil {
+		return m.handleError(fmt.Errorf("editor failed: %w", msg.err))
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The choices offered for an instance whose program exited.
+const (
+	exitChoiceRestart = "Restart"
+	exitChoiceArchive = "Archive"
+	exitChoiceKill    = "Kill"
+)
+
+// exitTitle describes how the program of instance, which is Exited or Dead, ended.
+func exitTitle(instance *session.Instance) string {
+	switch code := instance.ExitCode(); {
+	case instance.Status == session.Exited:
+		return fmt.Sprintf("The program of '%s' exited", instance.Title)
+	case code > 0:
+		return fmt.Sprintf("The program of '%s' crashed with exit code %d", instance.Title, code)
+	default:
+		return fmt.Sprintf("The program of '%s' is no longer running", instance.Title)
+	}
+}
+
+// openExitChoice asks whether to restart, archive or kill instance, whose program exited or crashed.
+func (m *home) openExitChoice(instance *session.Instance) tea.Cmd {
+	m.exitChoiceTarget = instance
+	m.exitChoicePicker = overlay.NewPickerOverlay(exitTitle(instance),
+		[]string{exitChoiceRestart, exitChoiceArchive, exitChoiceKill})
+	m.state = stateExitChoice
+	m.resizeOverlays()
+	return nil
+}
+
+// handleExitChoice handles a key in the exit choice picker and carries out the chosen action.
+func (m *home) handleExitChoice(msg tea.KeyMsg) tea.Cmd {
+	if !m.exitChoicePicker.HandleKeyPress(msg) {
+		return nil
+	}
+	picker := m.exitChoicePicker
+	instance := m.exitChoiceTarget
+	m.exitChoicePicker = nil
+	m.exitChoiceTarget = nil
+	m.state = stateDefault
+	// The instance may have been restarted or removed while the picker was open.
+	if !picker.Submitted || instance == nil ||
+		(instance.Status != session.Exited && instance.Status != session.Dead) {
+		return tea.WindowSize()
+	}
+	switch picker.Selected() {
+	case exitChoiceRestart:
+		return tea.Batch(tea.WindowSize(), m.restartInstance(instance))
+	case exitChoiceArchive:
+		return tea.Batch(tea.WindowSize(), m.toggleArchived(instance))
+	case exitChoiceKill:
+		return tea.Batch(tea.WindowSize(), m.confirmKill(instance))
+	}
+	return tea.WindowSize()
+}
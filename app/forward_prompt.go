@@ -0,0 +1,93 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openForwardPrompt asks which other instance to send the last prompt of source to.
+func (m *home) openForwardPrompt(source *session.Instance) tea.Cmd {
+	if source.LastPrompt() == "" {
+		return m.handleError(fmt.Errorf("no prompt has been sent to '%s' yet", source.Title))
+	}
+	var targets []*session.Instance
+	var titles []string
+	for _, instance := range m.list.GetInstances() {
+		if instance == source {
+			continue
+		}
+		targets = append(targets, instance)
+		titles = append(titles, instance.Title)
+	}
+	if len(targets) == 0 {
+		return m.handleError(fmt.Errorf("there is no other session to send the last prompt of '%s' to", source.Title))
+	}
+	m.forwardPromptSource = source
+	m.forwardPromptTargets = targets
+	m.forwardPromptPicker = overlay.NewPickerOverlay(
+		fmt.Sprintf("Send the last prompt of '%s' to", source.Title), titles)
+	m.state = stateForwardPrompt
+	m.resizeOverlays()
+	return nil
+}
+
+// handleForwardPrompt handles a key in the forward prompt picker and sends the prompt to the chosen instance.
+func (m *home) handleForwardPrompt(msg tea.KeyMsg) tea.Cmd {
+	if !m.forwardPromptPicker.HandleKeyPress(msg) {
+		return nil
+	}
+	picker := m.forwardPromptPicker
+	source := m.forwardPromptSource
+	targets := m.forwardPromptTargets
+	m.forwardPromptPicker = nil
+	m.forwardPromptSource = nil
+	m.forwardPromptTargets = nil
+	m.state = stateDefault
+	if !picker.Submitted {
+		return tea.WindowSize()
+	}
+	// Titles are unique, and the chosen instance may have been removed while the picker was open.
+	selected := picker.Selected()
+	for _, target := range targets {
+		if target.Title == selected && slices.Contains(m.list.GetInstances(), target) {
+			return tea.Batch(tea.WindowSize(), m.forwardPrompt(source, target))
+		}
+	}
+	return tea.WindowSize()
+}
+
+// forwardPrompt sends the last prompt of source to target. It is queued while target is busy, and held until
+// target runs again if it can't take prompts now, e.g. because it is paused or still starting. A held prompt is saved
+// right away, so it isn't lost if claude-squad quits before target runs again.
+func (m *home) forwardPrompt(source, target *session.Instance) tea.Cmd {
+	prompt := source.LastPrompt()
+	if prompt == "" {
+		return m.handleError(fmt.Errorf("no prompt has been sent to '%s' yet", source.Title))
+	}
+	switch {
+	case !target.Started() || target.Status == session.Loading || target.Paused() || target.Archived() ||
+		target.Status == session.Dead || target.Status == session.Exited:
+		target.QueuePrompt(prompt)
+		if err := m.saveInstances(); err != nil {
+			return m.handleError(err)
+		}
+		m.gitResult = fmt.Sprintf("The last prompt of '%s' will be sent to '%s' once it runs", source.Title, target.Title)
+	default:
+		queued, err := target.SubmitPrompt(prompt)
+		if err != nil {
+			return m.handleError(fmt.Errorf("could not send the last prompt of '%s' to '%s': %w",
+				source.Title, target.Title, err))
+		}
+		if queued {
+			m.gitResult = fmt.Sprintf("Queued the last prompt of '%s' for '%s'", source.Title, target.Title)
+		} else {
+			m.gitResult = fmt.Sprintf("Sent the last prompt of '%s' to '%s'", source.Title, target.Title)
+		}
+	}
+	m.countCommand(prompt)
+	return hideGitResultCmd(m.ctx, m.gitResult)
+}
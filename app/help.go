@@ -1,11 +1,14 @@
 package app
 
 import (
+	"claude-squad/keys"
 	"claude-squad/log"
 	"claude-squad/session"
+	"claude-squad/session/tmux"
 	"claude-squad/ui"
 	"claude-squad/ui/overlay"
 	"fmt"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -41,21 +44,70 @@ func (h helpTypeGeneral) toContent() string {
 		"",
 		headerStyle.Render("Managing:"),
 		keyStyle.Render("n")+descStyle.Render("         - Create a new session"),
-		keyStyle.Render("N")+descStyle.Render("         - Create a new session with a prompt"),
+		keyStyle.Render("N")+descStyle.Render("         - Create a new session with a prompt (a blank name is suggested from the prompt)"),
+		keyStyle.Render("t")+descStyle.Render("         - Create a new session from a template"),
+		keyStyle.Render("B")+descStyle.Render("         - Create a new session from a remote branch"),
+		keyStyle.Render("P")+descStyle.Render("         - Create a new session in another repository"),
+		keyStyle.Render("I")+descStyle.Render("         - Create a new session in the current directory, without a worktree"),
 		keyStyle.Render("D")+descStyle.Render("         - Kill (delete) the selected session"),
+		keyStyle.Render("esc")+descStyle.Render("       - Cancel starting the selected session"),
 		keyStyle.Render("↑/j, ↓/k")+descStyle.Render("  - Navigate between sessions"),
+		keyStyle.Render("alt-1..9")+descStyle.Render("  - Jump to the Nth session"),
+		keyStyle.Render("ctrl-p")+descStyle.Render("    - Jump to a session by typing part of its title or tags"),
 		keyStyle.Render("↵/o")+descStyle.Render("       - Attach to the selected session"),
-		keyStyle.Render("ctrl-q")+descStyle.Render("    - Detach from session"),
+		keyStyle.Render("s")+descStyle.Render("         - Send a prompt, queued while the session is busy"),
+		keyStyle.Render("T")+descStyle.Render("         - Send a prompt template after filling in its placeholders"),
+		keyStyle.Render(".")+descStyle.Render("         - Send the session's last prompt again"),
+		keyStyle.Render("F")+descStyle.Render("         - Send the session's last prompt to another session"),
+		keyStyle.Render("i")+descStyle.Render("         - Interrupt the session's program (sends Ctrl-C)"),
+		keyStyle.Render("e")+descStyle.Render("         - Edit the session's note"),
+		keyStyle.Render("#")+descStyle.Render("         - Set the session's color and icon, e.g. blue 🚀"),
+		keyStyle.Render("g")+descStyle.Render("         - Set the session's group, listed under a header (empty ungroups it)"),
+		keyStyle.Render("/")+descStyle.Render("         - Search the output of all sessions and jump to one"),
+		keyStyle.Render(fmt.Sprintf("%-10s", displayKey(tmux.DetachKey())))+descStyle.Render("- Detach from session"),
 		"",
 		headerStyle.Render("Handoff:"),
 		keyStyle.Render("p")+descStyle.Render("         - Commit and push branch to github"),
+		keyStyle.Render("U")+descStyle.Render("         - Rebase the branch onto the latest base branch"),
+		keyStyle.Render("K")+descStyle.Render("         - Checkpoint: commit the work on a new branch, keep working"),
+		keyStyle.Render("H")+descStyle.Render("         - List the auto-commits and checkpoints, reset to one"),
+		keyStyle.Render("V")+descStyle.Render("         - Observe: show the command to watch the session read-only"),
+		keyStyle.Render("ctrl-t")+descStyle.Render("    - List the tmux session names of all sessions, to copy them"),
 		keyStyle.Render("c")+descStyle.Render("         - Checkout: commit changes and pause session"),
 		keyStyle.Render("r")+descStyle.Render("         - Resume a paused session"),
+		keyStyle.Render("O")+descStyle.Render("         - Resume a paused session and attach once it is ready"),
+		keyStyle.Render("C")+descStyle.Render("         - Pause all running sessions"),
+		keyStyle.Render("R")+descStyle.Render("         - Resume all paused sessions"),
+		keyStyle.Render("x")+descStyle.Render("         - Restart a session whose program exited"),
+		keyStyle.Render("a")+descStyle.Render("         - Archive the selected session, or restore it if it is archived"),
+		keyStyle.Render("A")+descStyle.Render("         - Show or hide archived sessions"),
+		keyStyle.Render("L")+descStyle.Render("         - Show the activity log of the selected session"),
+		keyStyle.Render("b")+descStyle.Render("         - Copy the selected session's branch name"),
+		keyStyle.Render("w")+descStyle.Render("         - Copy the selected session's worktree path"),
+		keyStyle.Render("y/Y")+descStyle.Render("       - Copy the selected session's diff, or save it to a patch file"),
 		"",
 		headerStyle.Render("Other:"),
-		keyStyle.Render("tab")+descStyle.Render("       - Switch between preview and diff tabs"),
+		keyStyle.Render("tab")+descStyle.Render("       - Switch to the next tab: preview, diff, activity or output logs"),
+		keyStyle.Render("shift-tab")+descStyle.Render(" - Switch to the previous tab"),
+		keyStyle.Render("m")+descStyle.Render("         - Pin the panes to the selected session while navigating, or unpin them"),
 		keyStyle.Render("shift-↓/↑")+descStyle.Render(" - Scroll in diff view"),
+		keyStyle.Render("shift-←/→")+descStyle.Render(" - Scroll wide lines in diff view"),
+		keyStyle.Render("f")+descStyle.Render("         - Expand the changed files in diff view"),
+		keyStyle.Render("[/]")+descStyle.Render("       - Jump to the previous/next file in diff view"),
+		keyStyle.Render("{/}")+descStyle.Render("       - Jump to the previous/next hunk in diff view"),
+		keyStyle.Render("W")+descStyle.Render("         - Hide or show whitespace-only changes in diff view"),
+		keyStyle.Render("u")+descStyle.Render("         - Switch the diff view between the whole branch and uncommitted changes"),
+		keyStyle.Render("ctrl-r")+descStyle.Render("    - Refresh the selected session's status and diff"),
+		keyStyle.Render(fmt.Sprintf("%-10s", displayKey(keys.GlobalkeyBindings[keys.KeyAutoYes].Help().Key)))+
+			descStyle.Render("- Turn auto-yes on or off for all sessions"),
+		keyStyle.Render("v")+descStyle.Render("         - Show one line per session in the list, or two"),
+		keyStyle.Render("S")+descStyle.Render("         - List the sessions that need you first, or in the order they were created"),
+		keyStyle.Render("z")+descStyle.Render("         - Collapse or expand the selected group in the list"),
 		keyStyle.Render("q")+descStyle.Render("         - Quit the application"),
+		"",
+		descStyle.Render("Press ")+keyStyle.Render("?")+descStyle.Render(" again to list every key."),
+		descStyle.Render("Press ")+keyStyle.Render("h")+
+			descStyle.Render(" to show the new session, attach and checkout help screens again."),
 	)
 	return content
 }
@@ -82,11 +134,18 @@ func (h helpTypeInstanceStart) toContent() string {
 	return content
 }
 
+// displayKey formats a key name like "ctrl+q" the way the help screens show keys, like "ctrl-q".
+func displayKey(key string) string {
+	return strings.ReplaceAll(key, "+", "-")
+}
+
 func (h helpTypeInstanceAttach) toContent() string {
 	content := lipgloss.JoinVertical(lipgloss.Left,
 		titleStyle.Render("Attaching to Instance"),
 		"",
-		descStyle.Render("To detach from a session, press ")+keyStyle.Render("ctrl-q"),
+		descStyle.Render("To detach from a session, press ")+keyStyle.Render(displayKey(tmux.DetachKey())),
+		descStyle.Render("This returns to claude-squad and leaves the session running in the background."),
+		descStyle.Render("The key can be changed with detach_key in the config."),
 	)
 	return content
 }
@@ -119,6 +178,10 @@ func (h helpTypeInstanceCheckout) mask() uint32 {
 	return 1 << 3
 }
 
+// tipsMask are the help screens shown before an action until they are dismissed once. The general help menu can
+// show them again.
+var tipsMask = helpTypeInstanceStart{}.mask() | helpTypeInstanceAttach{}.mask() | helpTypeInstanceCheckout{}.mask()
+
 var (
 	titleStyle  = lipgloss.NewStyle().Bold(true).Underline(true).Foreground(lipgloss.Color("#7D56F4"))
 	headerStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#36CFC9"))
@@ -126,7 +189,8 @@ var (
 	descStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
 )
 
-// showHelpScreen displays the help screen overlay if it hasn't been shown before
+// showHelpScreen displays the help screen overlay if it hasn't been dismissed before, and otherwise runs onDismiss
+// right away. The general help screen is always shown.
 func (m *home) showHelpScreen(helpType helpText, onDismiss func()) (tea.Model, tea.Cmd) {
 	// Get the flag for this help type
 	var alwaysShow bool
@@ -135,21 +199,15 @@ func (m *home) showHelpScreen(helpType helpText, onDismiss func()) (tea.Model, t
 		alwaysShow = true
 	}
 
-	flag := helpType.mask()
-
-	// Check if this help screen has been seen before
 	// Only show if we're showing the general help screen or the corresponding flag is not set
-	// in the seen bitmask.
-	if alwaysShow || (m.appState.GetHelpScreensSeen()&flag) == 0 {
-		// Mark this help screen as seen and save state
-		if err := m.appState.SetHelpScreensSeen(m.appState.GetHelpScreensSeen() | flag); err != nil {
-			log.WarningLog.Printf("Failed to save help screen state: %v", err)
-		}
-
+	// in the seen bitmask. The flag is set once the screen is dismissed.
+	if alwaysShow || (m.appState.GetHelpScreensSeen()&helpType.mask()) == 0 {
 		content := helpType.toContent()
+		m.shownHelp = helpType
 
 		m.textOverlay = overlay.NewTextOverlay(content)
 		m.textOverlay.OnDismiss = onDismiss
+		m.resizeOverlays()
 		m.state = stateHelp
 		return m, nil
 	}
@@ -161,9 +219,31 @@ func (m *home) showHelpScreen(helpType helpText, onDismiss func()) (tea.Model, t
 	return m, nil
 }
 
+// setHelpScreensSeen saves the bitmask of the help screens that were dismissed.
+func (m *home) setHelpScreensSeen(seen uint32) {
+	if err := m.appState.SetHelpScreensSeen(seen); err != nil {
+		log.WarningLog.Printf("Failed to save help screen state: %v", err)
+	}
+}
+
 // handleHelpState handles key events when in help state
 func (m *home) handleHelpState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Any key press will close the help overlay
+	if _, general := m.shownHelp.(helpTypeGeneral); general && msg.String() == "?" {
+		m.textOverlay = nil
+		m.showKeysReference()
+		return m, nil
+	}
+	if _, general := m.shownHelp.(helpTypeGeneral); general && msg.String() == "h" {
+		m.textOverlay = nil
+		m.state = stateDefault
+		m.setHelpScreensSeen(m.appState.GetHelpScreensSeen() &^ tipsMask)
+		m.gitResult = "The new session, attach and checkout help screens will be shown again"
+		return m, tea.Batch(tea.WindowSize(), hideGitResultCmd(m.ctx, m.gitResult))
+	}
+
+	// Any key press will close the help overlay. The help screen is acknowledged before its callback runs, which
+	// may attach and block until the user detaches.
+	m.setHelpScreensSeen(m.appState.GetHelpScreensSeen() | m.shownHelp.mask())
 	shouldClose := m.textOverlay.HandleKeyPress(msg)
 	if shouldClose {
 		m.state = stateDefault
@@ -178,3 +258,36 @@ func (m *home) handleHelpState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	return m, nil
 }
+
+// showKeysReference displays every key and what it does in a scrollable overlay the size of the terminal.
+func (m *home) showKeysReference() {
+	m.keysOverlay = overlay.NewActivityOverlay("Keys", keysReferenceLines(keys.Cheatsheet(keys.GlobalKeyStringsMap)))
+	m.keysOverlay.StartAtTop()
+	m.resizeOverlays()
+	m.state = stateKeys
+}
+
+// keysReferenceLines renders the sections of the keybindings reference, one line per action like
+// "k/up       - Select the previous session".
+func keysReferenceLines(sections []keys.Section) []string {
+	width := 0
+	for _, section := range sections {
+		for _, binding := range section.Bindings {
+			width = max(width, lipgloss.Width(displayKey(strings.Join(binding.Keys, "/"))))
+		}
+	}
+
+	var lines []string
+	for i, section := range sections {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, headerStyle.Render(string(section.Category)+":"))
+		for _, binding := range section.Bindings {
+			keyText := displayKey(strings.Join(binding.Keys, "/"))
+			padding := strings.Repeat(" ", width-lipgloss.Width(keyText))
+			lines = append(lines, keyStyle.Render(keyText)+descStyle.Render(padding+" - "+binding.Text))
+		}
+	}
+	return lines
+}
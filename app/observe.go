@@ -0,0 +1,42 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// showObserveCommand shows the tmux session of instance and the command that attaches to it read-only, so a
+// teammate can watch it from their own terminal. The command is copied to the clipboard.
+func (m *home) showObserveCommand(instance *session.Instance) tea.Cmd {
+	command, err := instance.ObserveCommand()
+	if err != nil {
+		return m.handleError(err)
+	}
+	m.textOverlay = overlay.NewTextOverlay(observeContent(instance.Title, instance.TmuxSessionName(), command,
+		writeClipboard(command)))
+	m.shownHelp = nil
+	m.resizeOverlays()
+	m.state = stateHelp
+	return nil
+}
+
+// observeContent is the text of the observe overlay. copyErr is the error of copying the command, if it failed.
+func observeContent(title, sessionName, command string, copyErr error) string {
+	copied := descStyle.Render("The command has been copied to your clipboard.")
+	if copyErr != nil {
+		copied = descStyle.Render("The command could not be copied to your clipboard: " + copyErr.Error())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Observe '"+title+"'"),
+		"",
+		descStyle.Render("Run this in another terminal to watch the session read-only:"),
+		"",
+		keyStyle.Render(command),
+		"",
+		headerStyle.Render("tmux session: ")+descStyle.Render(sessionName),
+		copied,
+	)
+}
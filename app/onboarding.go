@@ -0,0 +1,51 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session/tmux"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// onboardingMask is the bit of the seen help screens in AppState that is set once the onboarding shown while there
+// are no sessions is dismissed.
+const onboardingMask uint32 = 1 << 4
+
+// showOnboarding returns whether the onboarding replaces the empty list and preview: there are no sessions to show
+// and it was never dismissed.
+func (m *home) showOnboarding() bool {
+	if m.appState == nil || !m.list.Empty() {
+		return false
+	}
+	return m.appState.GetHelpScreensSeen()&onboardingMask == 0
+}
+
+// dismissOnboarding hides the onboarding for good.
+func (m *home) dismissOnboarding() {
+	if err := m.appState.SetHelpScreensSeen(m.appState.GetHelpScreensSeen() | onboardingMask); err != nil {
+		log.WarningLog.Printf("Failed to save help screen state: %v", err)
+	}
+}
+
+// onboardingContent explains the key actions and what a session is to someone who hasn't created one yet.
+func onboardingContent() string {
+	return lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Welcome to Claude Squad"),
+		"",
+		descStyle.Render("Each session runs Claude Code (or another agent) in its own git worktree and branch,"),
+		descStyle.Render("so several tasks can be worked on at once without touching your checkout."),
+		"",
+		headerStyle.Render("Get started:"),
+		keyStyle.Render("n")+descStyle.Render("         - Create a new session"),
+		keyStyle.Render("N")+descStyle.Render("         - Create a new session with a prompt"),
+		keyStyle.Render("s")+descStyle.Render("         - Send a prompt to the selected session"),
+		keyStyle.Render("↵/o")+descStyle.Render("       - Attach to the selected session, ")+
+			keyStyle.Render(displayKey(tmux.DetachKey()))+descStyle.Render(" to detach"),
+		keyStyle.Render("p")+descStyle.Render("         - Commit and push the session's branch"),
+		keyStyle.Render("?")+descStyle.Render("         - Show all the keys"),
+		"",
+		descStyle.Render(fmt.Sprintf("This shows while there are no sessions. Press %s to hide it for good.",
+			keyStyle.Render("esc"))),
+	)
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"claude-squad/config"
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openPromptTemplatePicker lets the user choose one of the repo's prompt templates to send to instance.
+func (m *home) openPromptTemplatePicker(instance *session.Instance) tea.Cmd {
+	if len(m.promptTemplates) == 0 {
+		return m.handleError(fmt.Errorf("no prompt templates found in %s",
+			filepath.Join(m.repoConfigDir, config.PromptTemplatesFileName)))
+	}
+	m.promptTemplateTarget = instance
+	m.promptTemplatePicker = overlay.NewPickerOverlay(fmt.Sprintf("Send a prompt template to %s", instance.Title),
+		m.promptTemplates.Names())
+	m.state = statePromptTemplate
+	m.resizeOverlays()
+	return nil
+}
+
+// handlePromptTemplatePicker handles a key in the prompt template picker. A chosen template with placeholders asks
+// for them in a form; one without is sent right away.
+func (m *home) handlePromptTemplatePicker(msg tea.KeyMsg) tea.Cmd {
+	if !m.promptTemplatePicker.HandleKeyPress(msg) {
+		return nil
+	}
+	picker := m.promptTemplatePicker
+	m.promptTemplatePicker = nil
+	m.state = stateDefault
+	if !picker.Submitted {
+		m.promptTemplateTarget = nil
+		return tea.WindowSize()
+	}
+	tmpl, ok := m.promptTemplates.Find(picker.Selected())
+	if !ok {
+		m.promptTemplateTarget = nil
+		return tea.Batch(tea.WindowSize(), m.handleError(fmt.Errorf("prompt template %q not found", picker.Selected())))
+	}
+
+	placeholders := config.Placeholders(tmpl.Prompt)
+	if len(placeholders) == 0 {
+		return tea.Batch(tea.WindowSize(), m.sendPromptTemplate(tmpl.Prompt))
+	}
+	m.promptTemplate = tmpl
+	m.promptForm = overlay.NewFormOverlay(tmpl.Name, placeholders)
+	m.state = statePromptForm
+	m.resizeOverlays()
+	return nil
+}
+
+// handlePromptForm handles a key in the placeholder form. Once it is submitted, the expanded prompt is sent. If a
+// value is missing, the form stays open so it can be filled in.
+func (m *home) handlePromptForm(msg tea.KeyMsg) tea.Cmd {
+	if !m.promptForm.HandleKeyPress(msg) {
+		return nil
+	}
+	form := m.promptForm
+	if !form.Submitted {
+		m.promptForm = nil
+		m.state = stateDefault
+		m.promptTemplateTarget = nil
+		return tea.WindowSize()
+	}
+	expanded, err := config.ExpandPrompt(m.promptTemplate.Prompt, form.Values())
+	if err != nil {
+		form.Submitted = false
+		return m.handleError(err)
+	}
+	m.promptForm = nil
+	m.state = stateDefault
+	return tea.Batch(tea.WindowSize(), m.sendPromptTemplate(expanded))
+}
+
+// sendPromptTemplate sends the expanded prompt to promptTemplateTarget, queued while it is busy.
+func (m *home) sendPromptTemplate(expanded string) tea.Cmd {
+	instance := m.promptTemplateTarget
+	m.promptTemplateTarget = nil
+	if instance == nil {
+		return nil
+	}
+	m.rememberPrompt(expanded)
+	if _, err := instance.SubmitPrompt(expanded); err != nil {
+		return m.handleError(err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// maxSearchSnippetWidth is the display width at which the matching line shown for a search result is cut.
+const maxSearchSnippetWidth = 60
+
+// searchOutput searches the output of all instances for query and opens the matches in a picker to jump to one.
+func (m *home) searchOutput(query string) tea.Cmd {
+	matches := session.SearchOutput(m.list.GetInstances(), query)
+	if len(matches) == 0 {
+		return m.handleError(fmt.Errorf("no session's output contains %q", query))
+	}
+	m.searchMatches = matches
+	m.searchPicker = overlay.NewPickerOverlay(fmt.Sprintf("Sessions whose output contains %q", query),
+		searchResultItems(matches))
+	m.state = stateSearchResults
+	m.resizeOverlays()
+	return nil
+}
+
+// searchResultItems describes each match like "fix-tests (3): Error: connection refused", with the most recent
+// matching line.
+func searchResultItems(matches []session.OutputMatch) []string {
+	items := make([]string, len(matches))
+	for i, match := range matches {
+		items[i] = fmt.Sprintf("%s (%d): %s", match.Instance.Title, match.Count,
+			runewidth.Truncate(match.Line, maxSearchSnippetWidth, "..."))
+	}
+	return items
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// statusServer serves the session.StatusSummary of the instances as JSON over HTTP on a Unix domain socket, e.g.
+// for `curl --unix-socket <path> http://localhost/status`. The summary is a snapshot published from the bubbletea
+// loop, so requests never touch the live instances.
+type statusServer struct {
+	path     string
+	listener net.Listener
+	server   *http.Server
+
+	mu sync.Mutex
+	// payload is the JSON of the last published summary.
+	payload []byte
+}
+
+// startStatusServer listens on the Unix socket at path and serves the status in the background. A stale socket
+// left by a crashed claude-squad is replaced, but one that another process is still serving is not.
+func startStatusServer(path string) (*statusServer, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("status socket %s is already in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale status socket: %w", err)
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on status socket: %w", err)
+	}
+	// The socket lists session titles, so only the user may connect.
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to restrict status socket: %w", err)
+	}
+
+	s := &statusServer{path: path, listener: listener}
+	s.publish(session.Summarize(nil))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", s.serveStatus)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.ErrorLog.Printf("status socket stopped: %v", err)
+		}
+	}()
+	return s, nil
+}
+
+// publish makes summary the status served from now on.
+func (s *statusServer) publish(summary session.StatusSummary) {
+	payload, err := json.Marshal(summary)
+	if err != nil {
+		log.ErrorLog.Printf("failed to encode status: %v", err)
+		return
+	}
+	s.mu.Lock()
+	s.payload = payload
+	s.mu.Unlock()
+}
+
+func (s *statusServer) serveStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.Lock()
+	payload := s.payload
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(payload)
+}
+
+// Close stops serving and removes the socket.
+func (s *statusServer) Close() error {
+	err := s.server.Close()
+	if removeErr := os.Remove(s.path); removeErr != nil && !os.IsNotExist(removeErr) {
+		err = errors.Join(err, removeErr)
+	}
+	return err
+}
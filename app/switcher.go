@@ -0,0 +1,50 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openSwitcher lists the instances shown in the list to jump to one by typing part of its title or tags.
+func (m *home) openSwitcher() tea.Cmd {
+	var instances []*session.Instance
+	var items []overlay.SwitcherItem
+	for _, instance := range m.list.GetInstances() {
+		if instance.Archived() && !m.list.ShowArchived() {
+			continue
+		}
+		instances = append(instances, instance)
+		items = append(items, overlay.SwitcherItem{
+			Glyph: m.list.StatusGlyph(instance),
+			Title: instance.Title,
+			Tags:  instance.Tags,
+		})
+	}
+	if len(instances) == 0 {
+		return nil
+	}
+	m.switcherInstances = instances
+	m.switcher = overlay.NewSwitcherOverlay("Jump to session", items)
+	m.state = stateSwitcher
+	m.resizeOverlays()
+	return nil
+}
+
+// handleSwitcher handles a key in the switcher and selects the chosen instance.
+func (m *home) handleSwitcher(msg tea.KeyMsg) tea.Cmd {
+	if !m.switcher.HandleKeyPress(msg) {
+		return nil
+	}
+	switcher := m.switcher
+	instances := m.switcherInstances
+	m.switcher = nil
+	m.switcherInstances = nil
+	m.state = stateDefault
+	if !switcher.Submitted {
+		return tea.WindowSize()
+	}
+	m.list.SelectInstance(instances[switcher.SelectedIndex()])
+	return tea.Batch(tea.WindowSize(), m.instanceChanged())
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/session/tmux"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// showTmuxSessions lists the tmux session names of all instances with their status, for users who switch between
+// sessions with their own tmux key bindings.
+func (m *home) showTmuxSessions() tea.Cmd {
+	entries := session.TmuxSessions(m.list.GetInstances())
+	if len(entries) == 0 {
+		return m.handleError(fmt.Errorf("there are no sessions to list"))
+	}
+	m.tmuxSessionNames = nil
+	for _, entry := range entries {
+		if entry.Open {
+			m.tmuxSessionNames = append(m.tmuxSessionNames, entry.Name)
+		}
+	}
+	m.activityOverlay = overlay.NewActivityOverlay("tmux sessions", tmuxSessionLines(entries, tmux.ServerCommand()))
+	m.activityOverlay.StartAtTop()
+	m.activityOverlay.SetHint("y to copy the names of the open sessions, ↑/↓ to scroll, esc to close")
+	m.state = stateTmuxSessions
+	return tea.WindowSize()
+}
+
+// tmuxSessionLines lists each session name with the status of its instance, like "claudesquad_fix  running".
+// Sessions that don't exist are marked as closed. If the sessions don't run on the default tmux server, server is
+// the tmux command that reaches them.
+func tmuxSessionLines(entries []session.TmuxSessionEntry, server string) []string {
+	width := 0
+	for _, entry := range entries {
+		width = max(width, runewidth.StringWidth(entry.Name))
+	}
+	lines := make([]string, 0, len(entries)+2)
+	for _, entry := range entries {
+		line := runewidth.FillRight(entry.Name, width) + "  " + entry.Status
+		if !entry.Open {
+			line += " (closed)"
+		}
+		lines = append(lines, line)
+	}
+	if server != "tmux" {
+		lines = append(lines, "", fmt.Sprintf("The sessions run on their own tmux server, use %s to reach them.", server))
+	}
+	return lines
+}
+
+// handleTmuxSessions handles a key in the list of tmux sessions. y copies the names of the open sessions, one per
+// line.
+func (m *home) handleTmuxSessions(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() != "y" && !m.activityOverlay.HandleKeyPress(msg) {
+		return nil
+	}
+	names := m.tmuxSessionNames
+	m.activityOverlay = nil
+	m.tmuxSessionNames = nil
+	m.state = stateDefault
+	if msg.String() != "y" {
+		return tea.WindowSize()
+	}
+	if len(names) == 0 {
+		return tea.Batch(tea.WindowSize(), m.handleError(fmt.Errorf("none of the tmux sessions is open")))
+	}
+	if err := writeClipboard(strings.Join(names, "\n")); err != nil {
+		return tea.Batch(tea.WindowSize(), m.handleError(fmt.Errorf("could not copy the tmux session names: %w", err)))
+	}
+	m.gitResult = fmt.Sprintf("Copied %d tmux session names", len(names))
+	return tea.Batch(tea.WindowSize(), hideGitResultCmd(m.ctx, m.gitResult))
+}
@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"os/exec"
 	"strings"
+	"sync"
 )
 
 type Executor interface {
@@ -29,6 +31,61 @@ func MakeExecutor() Executor {
 	return Exec{}
 }
 
+// ContextExecutor runs commands with another Executor, killing them when its context is cancelled. Once detached,
+// it runs them as the other Executor does.
+type ContextExecutor struct {
+	exec Executor
+
+	mu  sync.Mutex
+	ctx context.Context
+}
+
+// WithContext returns a ContextExecutor that runs the commands with e until ctx is cancelled.
+func WithContext(ctx context.Context, e Executor) *ContextExecutor {
+	return &ContextExecutor{exec: e, ctx: ctx}
+}
+
+// Detach stops tying the commands to the context, so they run even after it is cancelled.
+func (e *ContextExecutor) Detach() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ctx = nil
+}
+
+// bind returns a copy of cmd that is killed when the context is cancelled, or cmd itself once detached. The copy
+// fails to start if the context is already cancelled.
+func (e *ContextExecutor) bind(cmd *exec.Cmd) *exec.Cmd {
+	e.mu.Lock()
+	ctx := e.ctx
+	e.mu.Unlock()
+	if ctx == nil {
+		return cmd
+	}
+	bound := exec.CommandContext(ctx, cmd.Path)
+	bound.Args = cmd.Args
+	bound.Err = cmd.Err
+	bound.Dir = cmd.Dir
+	bound.Env = cmd.Env
+	bound.Stdin = cmd.Stdin
+	bound.Stdout = cmd.Stdout
+	bound.Stderr = cmd.Stderr
+	bound.ExtraFiles = cmd.ExtraFiles
+	bound.SysProcAttr = cmd.SysProcAttr
+	return bound
+}
+
+func (e *ContextExecutor) Run(cmd *exec.Cmd) error {
+	return e.exec.Run(e.bind(cmd))
+}
+
+func (e *ContextExecutor) Output(cmd *exec.Cmd) ([]byte, error) {
+	return e.exec.Output(e.bind(cmd))
+}
+
+func (e *ContextExecutor) CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	return e.exec.CombinedOutput(e.bind(cmd))
+}
+
 func ToString(cmd *exec.Cmd) string {
 	if cmd == nil {
 		return "<nil>"
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextExecutor(t *testing.T) {
+	t.Run("kills the command when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		e := WithContext(ctx, MakeExecutor())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		start := time.Now()
+		err := e.Run(exec.Command("sleep", "10"))
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("does not run commands after the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		e := WithContext(ctx, MakeExecutor())
+
+		_, err := e.Output(exec.Command("echo", "hi"))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("runs commands as usual once detached", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		e := WithContext(ctx, MakeExecutor())
+		e.Detach()
+		cancel()
+
+		output, err := e.CombinedOutput(exec.Command("echo", "hi"))
+		require.NoError(t, err)
+		assert.Equal(t, "hi\n", string(output))
+	})
+}
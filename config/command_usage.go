@@ -0,0 +1,134 @@
+package config
+
+import (
+	"claude-squad/log"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	CommandUsageFileName = "command_usage.json"
+	// MaxCommandUsage is the number of commands whose usage is kept. The least recently used are dropped.
+	MaxCommandUsage = 200
+)
+
+// CommandUsageEntry is how often a slash command was sent and when it was last sent.
+type CommandUsageEntry struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// CommandUsage is the per-repo record of the slash commands sent in prompts, keyed by command like "/commit".
+type CommandUsage struct {
+	path    string
+	entries map[string]CommandUsageEntry
+}
+
+// LoadCommandUsage loads the command usage from command_usage.json in the RepoConfigDir of the given repo path,
+// .claude-squad by default. Returns empty usage if the file doesn't exist or cannot be parsed (not an error), and
+// leaves out invalid entries, so a damaged file only loses the usage counts.
+func LoadCommandUsage(repoPath string) *CommandUsage {
+	usage := &CommandUsage{
+		path:    filepath.Join(RepoConfigDir(repoPath), CommandUsageFileName),
+		entries: make(map[string]CommandUsageEntry),
+	}
+
+	data, err := os.ReadFile(usage.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.WarningLog.Printf("failed to read command usage file: %v", err)
+		}
+		return usage
+	}
+
+	var entries map[string]CommandUsageEntry
+	if err := unmarshalFile(usage.path, data, &entries); err != nil {
+		log.WarningLog.Printf("failed to parse command usage file: %v", err)
+		return usage
+	}
+	for command, entry := range entries {
+		if commandOf(command) != command || entry.Count <= 0 {
+			continue
+		}
+		usage.entries[command] = entry
+	}
+	return usage
+}
+
+// commandOf returns the slash command that prompt starts with, like "/commit" for "/commit the fix", or an empty
+// string if it doesn't start with one.
+func commandOf(prompt string) string {
+	fields := strings.Fields(prompt)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") || fields[0] == "/" {
+		return ""
+	}
+	return fields[0]
+}
+
+// Usage returns how often command was sent and when it was last sent. It is zero for commands that weren't sent.
+func (u *CommandUsage) Usage(command string) (count int, lastUsed time.Time) {
+	entry := u.entries[command]
+	return entry.Count, entry.LastUsed
+}
+
+// Record counts the slash command that prompt starts with as sent at now and saves the usage. Prompts that don't
+// start with a command are not recorded.
+func (u *CommandUsage) Record(prompt string, now time.Time) error {
+	command := commandOf(prompt)
+	if command == "" {
+		return nil
+	}
+
+	entry := u.entries[command]
+	entry.Count++
+	entry.LastUsed = now
+	u.entries[command] = entry
+	for len(u.entries) > MaxCommandUsage {
+		u.dropLeastRecent()
+	}
+	return u.save()
+}
+
+// dropLeastRecent forgets the command that was sent longest ago, the first by name if several were sent then.
+func (u *CommandUsage) dropLeastRecent() {
+	var oldest string
+	for command, entry := range u.entries {
+		if oldest == "" {
+			oldest = command
+			continue
+		}
+		switch entry.LastUsed.Compare(u.entries[oldest].LastUsed) {
+		case -1:
+			oldest = command
+		case 0:
+			oldest = min(oldest, command)
+		}
+	}
+	delete(u.entries, oldest)
+}
+
+// save writes the usage to disk. It is written to a temporary file first, so an interrupted write can't leave a
+// damaged file behind.
+func (u *CommandUsage) save() error {
+	if err := os.MkdirAll(filepath.Dir(u.path), 0755); err != nil {
+		return fmt.Errorf("failed to create command usage directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(u.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal command usage: %w", err)
+	}
+
+	tmpPath := u.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write command usage: %w", err)
+	}
+	if err := os.Rename(tmpPath, u.path); err != nil {
+		return fmt.Errorf("failed to write command usage: %w", err)
+	}
+	return nil
+}
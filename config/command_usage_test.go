@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandUsage(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("counts commands and persists them", func(t *testing.T) {
+		repo := t.TempDir()
+		usage := LoadCommandUsage(repo)
+
+		require.NoError(t, usage.Record("/commit fix the flaky test", now))
+		require.NoError(t, usage.Record("/commit", now.Add(time.Minute)))
+		require.NoError(t, usage.Record("/review-pr 42", now))
+		// Prompts that don't start with a command aren't counted.
+		require.NoError(t, usage.Record("please /commit", now))
+		require.NoError(t, usage.Record("/", now))
+		require.NoError(t, usage.Record("", now))
+
+		reloaded := LoadCommandUsage(repo)
+		count, lastUsed := reloaded.Usage("/commit")
+		assert.Equal(t, 2, count)
+		assert.True(t, now.Add(time.Minute).Equal(lastUsed))
+		count, _ = reloaded.Usage("/review-pr")
+		assert.Equal(t, 1, count)
+		count, lastUsed = reloaded.Usage("please")
+		assert.Zero(t, count)
+		assert.True(t, lastUsed.IsZero())
+		assert.Len(t, reloaded.entries, 2)
+	})
+
+	t.Run("a damaged file starts over", func(t *testing.T) {
+		repo := t.TempDir()
+		path := filepath.Join(repo, ".claude-squad", CommandUsageFileName)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(`{"/commit": {"count": 3`), 0644))
+
+		usage := LoadCommandUsage(repo)
+		assert.Empty(t, usage.entries)
+
+		require.NoError(t, usage.Record("/commit", now))
+		count, _ := LoadCommandUsage(repo).Usage("/commit")
+		assert.Equal(t, 1, count, "recording replaces the damaged file")
+		_, err := os.Stat(path + ".tmp")
+		assert.True(t, os.IsNotExist(err), "the temporary file is renamed into place")
+	})
+
+	t.Run("invalid entries are left out", func(t *testing.T) {
+		repo := t.TempDir()
+		path := filepath.Join(repo, ".claude-squad", CommandUsageFileName)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(`{
+			"/commit": {"count": 3},
+			"commit": {"count": 1},
+			"/two words": {"count": 1},
+			"/negative": {"count": -2}
+		}`), 0644))
+
+		usage := LoadCommandUsage(repo)
+		assert.Len(t, usage.entries, 1)
+		count, _ := usage.Usage("/commit")
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("keeps the most recently used commands", func(t *testing.T) {
+		usage := LoadCommandUsage(t.TempDir())
+		for i := 0; i < MaxCommandUsage+5; i++ {
+			require.NoError(t, usage.Record(fmt.Sprintf("/cmd-%03d", i), now.Add(time.Duration(i)*time.Second)))
+		}
+
+		assert.Len(t, usage.entries, MaxCommandUsage)
+		count, _ := usage.Usage("/cmd-004")
+		assert.Zero(t, count)
+		count, _ = usage.Usage("/cmd-005")
+		assert.Equal(t, 1, count)
+	})
+}
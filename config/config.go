@@ -10,11 +10,37 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 )
 
 const (
 	ConfigFileName = "config.json"
 	defaultProgram = "claude"
+	// defaultTmuxSessionPrefix should match tmux.TmuxPrefix.
+	defaultTmuxSessionPrefix = "claudesquad_"
+	// defaultPromptSubmitKey should match overlay.DefaultSubmitKey.
+	defaultPromptSubmitKey = "ctrl+d"
+	// defaultDetachKey should match tmux.DefaultDetachKey.
+	defaultDetachKey = "ctrl+q"
+	// defaultPromptReadyTimeout is how many seconds to wait for a new instance's program to accept input.
+	defaultPromptReadyTimeout = 5
+	// defaultPreviewRefreshInterval is how many milliseconds the preview waits between refreshes.
+	defaultPreviewRefreshInterval = 100
+	// minPreviewRefreshInterval is the shortest preview refresh interval in milliseconds. Shorter intervals
+	// capture the pane so often that it costs more CPU than it looks smoother.
+	minPreviewRefreshInterval = 50
+	// maxPreviewRefreshInterval is the longest preview refresh interval in milliseconds. Longer intervals make the
+	// preview look frozen.
+	maxPreviewRefreshInterval = 10000
+	// defaultMaxTitleLength is how many columns an instance title may take.
+	defaultMaxTitleLength = 32
+	// maxMaxTitleLength is the most MaxTitleLength can raise the title length to. Longer titles make long tmux
+	// session names and leave no room for the status in the list.
+	maxMaxTitleLength = 100
+	// defaultGitRetryAttempts should match git.DefaultRetryAttempts.
+	defaultGitRetryAttempts = 3
+	// defaultGitRetryDelay should match git.DefaultRetryBaseDelay, in milliseconds.
+	defaultGitRetryDelay = 500
 )
 
 // GetConfigDir returns the path to the application's configuration directory
@@ -32,10 +58,122 @@ type Config struct {
 	DefaultProgram string `json:"default_program"`
 	// AutoYes is a flag to automatically accept all prompts.
 	AutoYes bool `json:"auto_yes"`
+	// AutoYesPatterns are regexes matched against the text around a prompt. When set, auto-yes only confirms
+	// prompts that match at least one pattern. When empty, auto-yes confirms every prompt.
+	AutoYesPatterns []string `json:"auto_yes_patterns"`
+	// AutoYesDangerCheck keeps auto-yes from confirming prompts that contain a danger keyword, like "rm -rf", even
+	// if they match AutoYesPatterns. Those prompts wait to be answered by hand.
+	AutoYesDangerCheck bool `json:"auto_yes_danger_check"`
+	// AutoYesDangerKeywords replace DefaultDangerKeywords as the keywords AutoYesDangerCheck looks for. They are
+	// matched anywhere in the text around the prompt. Lower case keywords ignore case; others, like "branch -D",
+	// match only that case.
+	AutoYesDangerKeywords []string `json:"auto_yes_danger_keywords,omitempty"`
+	// AutoYesKey is the key that turns auto-yes on or off for all instances, like "ctrl+y". Empty uses ctrl+y.
+	AutoYesKey string `json:"auto_yes_key,omitempty"`
 	// DaemonPollInterval is the interval (ms) at which the daemon polls sessions for autoyes mode.
 	DaemonPollInterval int `json:"daemon_poll_interval"`
 	// BranchPrefix is the prefix used for git branches created by the application.
 	BranchPrefix string `json:"branch_prefix"`
+	// TmuxSessionPrefix is the prefix used for tmux sessions created by the application. Only sessions with
+	// this prefix are ever listed or cleaned up.
+	TmuxSessionPrefix string `json:"tmux_session_prefix"`
+	// PromptSubmitKey is the key that submits the prompt overlay (e.g. "ctrl+d"). Enter inserts a newline.
+	PromptSubmitKey string `json:"prompt_submit_key"`
+	// ConfirmTimeout is the number of seconds after which an unanswered confirmation dialog is cancelled. Zero
+	// disables the timeout.
+	ConfirmTimeout int `json:"confirm_timeout"`
+	// DisableTitleFromPrompt turns off deriving the title of a new instance from its prompt when the title is
+	// left blank.
+	DisableTitleFromPrompt bool `json:"disable_title_from_prompt"`
+	// PromptReadyTimeout is the number of seconds to wait for a new instance's program to be ready for input
+	// before sending the prompt it was created with. Each retry waits this long again.
+	PromptReadyTimeout int `json:"prompt_ready_timeout"`
+	// DiffIgnoreWhitespace hides whitespace-only changes in the diff view when the app starts. It can be toggled
+	// with W.
+	DiffIgnoreWhitespace bool `json:"diff_ignore_whitespace"`
+	// DiffContextLines is the number of unchanged lines shown around each change in the diff view. Zero uses
+	// git's default of three.
+	DiffContextLines int `json:"diff_context_lines"`
+	// DiffFileOrder is the order of the files in the diff view: git (the default) keeps git's order, alphabetical
+	// sorts them by path and priority orders them by DiffFilePriority.
+	DiffFileOrder string `json:"diff_file_order,omitempty"`
+	// DiffFilePriority are the path patterns that order the files in the diff view with the priority order, e.g.
+	// ["*.go", "*_test.go"] for sources before tests. Files matching an earlier pattern come first, the last pattern
+	// a file matches counts, and files matching none come last.
+	DiffFilePriority []string `json:"diff_file_priority,omitempty"`
+	// MenuItems are the actions shown in the bottom menu, in order, e.g. ["new", "kill", "open", "help", "quit"].
+	// Actions are only shown when they apply. Empty shows the default menu.
+	MenuItems []string `json:"menu_items"`
+	// CompactList shows each instance on a single line in the instance list. It is toggled with v.
+	CompactList bool `json:"compact_list"`
+	// SortByAttention lists the instances waiting at a prompt first, then ready, exited, running and paused ones,
+	// instead of in the order they were created. It is toggled with S.
+	SortByAttention bool `json:"sort_by_attention"`
+	// CollapsedGroups are the groups of instances whose instances are hidden under their header in the list. The
+	// instances without a group are the group "". They are toggled with z.
+	CollapsedGroups []string `json:"collapsed_groups,omitempty"`
+	// DetachKey is the key that detaches from an attached session and returns to claude-squad, like "ctrl+q". It
+	// must be ctrl with a letter or one of \ ] ^ _.
+	DetachKey string `json:"detach_key"`
+	// IsolatedTmux runs sessions on a tmux server of their own that ignores the user's tmux config, so the keys
+	// inside sessions behave the same regardless of the global tmux setup. Sessions started before changing this
+	// are restarted.
+	IsolatedTmux bool `json:"isolated_tmux"`
+	// StopSequence are the tmux keys that the interrupt key sends to the selected instance's program, like
+	// ["Escape"] to stop Claude Code without leaving it. Empty sends Ctrl-C.
+	StopSequence []string `json:"stop_sequence,omitempty"`
+	// IdlePauseMinutes pauses instances whose program hasn't printed anything for this many minutes, to save
+	// resources. The selected and pinned instances, in-place instances and instances with queued prompts are never
+	// paused. Zero turns it off.
+	IdlePauseMinutes int `json:"idle_pause_minutes"`
+	// AutoCommitMinutes commits the changes of running instances every this many minutes, if there are any, with a
+	// message starting with "[claudesquad] auto-commit" so the commits can be squashed later. Zero turns it off.
+	AutoCommitMinutes int `json:"auto_commit_minutes,omitempty"`
+	// StartupMode chooses which stored instances are loaded on start: restore-all (the default),
+	// restore-running-only to leave out paused and archived ones, or start-empty. Instances that aren't loaded stay
+	// in storage. The --startup flag overrides it.
+	StartupMode string `json:"startup_mode,omitempty"`
+	// QuitMode chooses what quitting does with the running instances: leave-running (the default) keeps them running
+	// in tmux, pause-all pauses them first, and ask asks whether to pause them. Checked out instances aren't paused.
+	QuitMode string `json:"quit_mode,omitempty"`
+	// ConfirmQuitWithChanges asks for confirmation before quitting while any instance has changes, listing them.
+	// Quitting then does what QuitMode says. It is off by default.
+	ConfirmQuitWithChanges bool `json:"confirm_quit_with_changes,omitempty"`
+	// Confirm turns the confirmation dialog of actions on or off, e.g. {"push": false}. The keys are the names in
+	// ConfirmActions. Actions that aren't listed are confirmed.
+	Confirm map[string]bool `json:"confirm,omitempty"`
+	// CommandSlash turns the leading slash of slash commands sent to a program on or off, e.g. {"aider": false}
+	// sends "/commit" to aider as "commit". The keys are program names without arguments. Programs that aren't
+	// listed get the slash.
+	CommandSlash map[string]bool `json:"command_slash,omitempty"`
+	// PreviewRefreshInterval is the interval (ms) at which the preview of the selected instance is refreshed, e.g.
+	// 250 to save CPU on battery. Zero uses the default of 100, and it is between 50 and 10000. The spinner isn't
+	// affected.
+	PreviewRefreshInterval int `json:"preview_refresh_interval,omitempty"`
+	// MaxTitleLength is how many columns an instance title may take when it is typed or derived from a prompt.
+	// Zero uses the default of 32, and it is at most 100.
+	MaxTitleLength int `json:"max_title_length,omitempty"`
+	// AmendPush amends the last commit on push instead of adding one, if claude-squad made that commit. A commit
+	// that was already pushed is force-pushed with a lease.
+	AmendPush bool `json:"amend_push"`
+	// TmuxControlMode captures sessions over a tmux control mode connection kept open per session, instead of
+	// starting capture-pane for every capture. Sessions without output aren't captured at all. Sessions fall back
+	// to capture-pane if the connection fails.
+	TmuxControlMode bool `json:"tmux_control_mode"`
+	// AttachCommand opens attached sessions in a new terminal window or tab instead of in place, e.g.
+	// "gnome-terminal -- {attach}" or "kitty". {session} is replaced by the tmux session name and {attach} by the
+	// tmux command that attaches to it. Without either, the tmux command is appended. Empty attaches in place.
+	AttachCommand string `json:"attach_command,omitempty"`
+	// GitRetryAttempts is how many times git worktree creation, fetch and push are run in all when they fail
+	// transiently, e.g. on a stale index.lock or a network timeout. Zero uses the default of 3, and 1 doesn't retry.
+	GitRetryAttempts int `json:"git_retry_attempts,omitempty"`
+	// GitRetryDelay is how long (ms) to wait before the first retry of a git command. The wait doubles every retry.
+	// Zero uses the default of 500.
+	GitRetryDelay int `json:"git_retry_delay,omitempty"`
+	// PostCreateHook is a shell command run with sh in the worktree of every new instance once it's created,
+	// before the program starts, e.g. "npm install" or "direnv allow". It is killed after 10 minutes. A failure is
+	// reported but doesn't stop the instance. Empty runs nothing.
+	PostCreateHook string `json:"post_create_hook,omitempty"`
 }
 
 // DefaultConfig returns the default configuration
@@ -58,6 +196,10 @@ func DefaultConfig() *Config {
 			}
 			return fmt.Sprintf("%s/", strings.ToLower(user.Username))
 		}(),
+		TmuxSessionPrefix:  defaultTmuxSessionPrefix,
+		PromptSubmitKey:    defaultPromptSubmitKey,
+		PromptReadyTimeout: defaultPromptReadyTimeout,
+		DetachKey:          defaultDetachKey,
 	}
 }
 
@@ -109,11 +251,22 @@ func GetClaudeCommand() (string, error) {
 	return "", fmt.Errorf("claude command not found in aliases or PATH")
 }
 
+// LoadConfig loads the config from the config directory, or returns the default config if it can't be read. Errors
+// are logged.
 func LoadConfig() *Config {
+	config, err := ReadConfig()
+	if err != nil {
+		log.ErrorLog.Printf("%v", err)
+	}
+	return config
+}
+
+// ReadConfig is LoadConfig that also returns why the config couldn't be read, e.g. a *ParseError pointing at a
+// mistake in the file. The returned config is always usable: the defaults if there was an error.
+func ReadConfig() (*Config, error) {
 	configDir, err := GetConfigDir()
 	if err != nil {
-		log.ErrorLog.Printf("failed to get config directory: %v", err)
-		return DefaultConfig()
+		return DefaultConfig(), fmt.Errorf("failed to get config directory: %w", err)
 	}
 
 	configPath := filepath.Join(configDir, ConfigFileName)
@@ -125,20 +278,77 @@ func LoadConfig() *Config {
 			if saveErr := saveConfig(defaultCfg); saveErr != nil {
 				log.WarningLog.Printf("failed to save default config: %v", saveErr)
 			}
-			return defaultCfg
+			return defaultCfg, nil
 		}
 
-		log.WarningLog.Printf("failed to get config file: %v", err)
-		return DefaultConfig()
+		return DefaultConfig(), fmt.Errorf("failed to get config file: %w", err)
 	}
 
 	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		log.ErrorLog.Printf("failed to parse config file: %v", err)
-		return DefaultConfig()
+	if err := unmarshalFile(configPath, data, &config); err != nil {
+		return DefaultConfig(), fmt.Errorf("failed to parse config file, using the defaults: %w", err)
+	}
+	// Config files written before the prefix was configurable don't have it set.
+	if config.TmuxSessionPrefix == "" {
+		config.TmuxSessionPrefix = defaultTmuxSessionPrefix
+	}
+	if config.PromptSubmitKey == "" {
+		config.PromptSubmitKey = defaultPromptSubmitKey
+	}
+	if config.PromptReadyTimeout <= 0 {
+		config.PromptReadyTimeout = defaultPromptReadyTimeout
+	}
+	if config.DetachKey == "" {
+		config.DetachKey = defaultDetachKey
+	}
+
+	return &config, nil
+}
+
+// PreviewInterval returns how long the preview waits between refreshes, the default if PreviewRefreshInterval isn't
+// set, and no less than the minimum nor more than the maximum.
+func (c *Config) PreviewInterval() time.Duration {
+	interval := c.PreviewRefreshInterval
+	if interval <= 0 {
+		interval = defaultPreviewRefreshInterval
+	}
+	interval = min(max(interval, minPreviewRefreshInterval), maxPreviewRefreshInterval)
+	return time.Duration(interval) * time.Millisecond
+}
+
+// TitleLength returns how many columns an instance title may take: MaxTitleLength, the default if it isn't set, and
+// no more than the maximum.
+func (c *Config) TitleLength() int {
+	length := c.MaxTitleLength
+	if length <= 0 {
+		length = defaultMaxTitleLength
 	}
+	return min(length, maxMaxTitleLength)
+}
+
+// RetryAttempts returns how many times a git command that fails transiently is run in all: GitRetryAttempts, or the
+// default if it isn't set.
+func (c *Config) RetryAttempts() int {
+	if c.GitRetryAttempts <= 0 {
+		return defaultGitRetryAttempts
+	}
+	return c.GitRetryAttempts
+}
+
+// RetryDelay returns how long to wait before the first retry of a git command: GitRetryDelay, or the default if it
+// isn't set.
+func (c *Config) RetryDelay() time.Duration {
+	delay := c.GitRetryDelay
+	if delay <= 0 {
+		delay = defaultGitRetryDelay
+	}
+	return time.Duration(delay) * time.Millisecond
+}
 
-	return &config
+// KeepCommandSlash returns whether slash commands are sent to the named program with their leading slash.
+func (c *Config) KeepCommandSlash(program string) bool {
+	keep, ok := c.CommandSlash[program]
+	return !ok || keep
 }
 
 // saveConfig saves the configuration to disk
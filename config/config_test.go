@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -108,6 +109,17 @@ func TestDefaultConfig(t *testing.T) {
 		assert.Equal(t, 1000, config.DaemonPollInterval)
 		assert.NotEmpty(t, config.BranchPrefix)
 		assert.True(t, strings.HasSuffix(config.BranchPrefix, "/"))
+		assert.Equal(t, "claudesquad_", config.TmuxSessionPrefix)
+		assert.Equal(t, "ctrl+d", config.PromptSubmitKey)
+		assert.Equal(t, 0, config.ConfirmTimeout)
+		assert.False(t, config.DisableTitleFromPrompt)
+		assert.Equal(t, 5, config.PromptReadyTimeout)
+		assert.False(t, config.DiffIgnoreWhitespace)
+		assert.Equal(t, 0, config.DiffContextLines)
+		assert.Empty(t, config.MenuItems)
+		assert.False(t, config.CompactList)
+		assert.Equal(t, "ctrl+q", config.DetachKey)
+		assert.False(t, config.IsolatedTmux)
 	})
 
 }
@@ -172,6 +184,11 @@ func TestLoadConfig(t *testing.T) {
 		assert.True(t, config.AutoYes)
 		assert.Equal(t, 2000, config.DaemonPollInterval)
 		assert.Equal(t, "test/", config.BranchPrefix)
+		// Older config files without a tmux prefix, submit key, ready timeout or detach key get the defaults.
+		assert.Equal(t, "claudesquad_", config.TmuxSessionPrefix)
+		assert.Equal(t, "ctrl+d", config.PromptSubmitKey)
+		assert.Equal(t, 5, config.PromptReadyTimeout)
+		assert.Equal(t, "ctrl+q", config.DetachKey)
 	})
 
 	t.Run("returns default config on invalid JSON", func(t *testing.T) {
@@ -237,3 +254,55 @@ func TestSaveConfig(t *testing.T) {
 		assert.Equal(t, testConfig.BranchPrefix, loadedConfig.BranchPrefix)
 	})
 }
+
+func TestKeepCommandSlash(t *testing.T) {
+	config := DefaultConfig()
+	assert.True(t, config.KeepCommandSlash("claude"), "the slash is kept by default")
+
+	config.CommandSlash = map[string]bool{"aider": false, "claude": true}
+	assert.False(t, config.KeepCommandSlash("aider"))
+	assert.True(t, config.KeepCommandSlash("claude"))
+	assert.True(t, config.KeepCommandSlash("gemini"))
+}
+
+func TestPreviewInterval(t *testing.T) {
+	config := DefaultConfig()
+	assert.Equal(t, 100*time.Millisecond, config.PreviewInterval(), "unset uses the default")
+
+	config.PreviewRefreshInterval = 250
+	assert.Equal(t, 250*time.Millisecond, config.PreviewInterval())
+
+	config.PreviewRefreshInterval = 10
+	assert.Equal(t, 50*time.Millisecond, config.PreviewInterval(), "too short is raised to the minimum")
+
+	config.PreviewRefreshInterval = 60000
+	assert.Equal(t, 10*time.Second, config.PreviewInterval(), "too long is lowered to the maximum")
+
+	config.PreviewRefreshInterval = -1
+	assert.Equal(t, 100*time.Millisecond, config.PreviewInterval())
+}
+
+func TestTitleLength(t *testing.T) {
+	config := DefaultConfig()
+	assert.Equal(t, 32, config.TitleLength(), "unset uses the default")
+
+	config.MaxTitleLength = 60
+	assert.Equal(t, 60, config.TitleLength())
+
+	config.MaxTitleLength = 500
+	assert.Equal(t, 100, config.TitleLength(), "too long is lowered to the maximum")
+
+	config.MaxTitleLength = -1
+	assert.Equal(t, 32, config.TitleLength())
+}
+
+func TestGitRetry(t *testing.T) {
+	config := DefaultConfig()
+	assert.Equal(t, 3, config.RetryAttempts(), "unset uses the default")
+	assert.Equal(t, 500*time.Millisecond, config.RetryDelay(), "unset uses the default")
+
+	config.GitRetryAttempts = 1
+	config.GitRetryDelay = 2000
+	assert.Equal(t, 1, config.RetryAttempts())
+	assert.Equal(t, 2*time.Second, config.RetryDelay())
+}
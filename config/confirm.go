@@ -0,0 +1,32 @@
+package config
+
+import "slices"
+
+// The actions whose confirmation can be turned off in Config.Confirm.
+const (
+	ConfirmKill   = "kill"
+	ConfirmPush   = "push"
+	ConfirmRebase = "rebase"
+)
+
+// ConfirmActions are the names that can be used in Config.Confirm.
+var ConfirmActions = []string{ConfirmKill, ConfirmPush, ConfirmRebase}
+
+// ShouldConfirm returns whether the named action asks for confirmation before it runs. Actions are confirmed unless
+// they are turned off in Confirm.
+func (c *Config) ShouldConfirm(action string) bool {
+	confirm, ok := c.Confirm[action]
+	return !ok || confirm
+}
+
+// UnknownConfirmActions returns the names in Confirm that aren't in ConfirmActions, sorted.
+func (c *Config) UnknownConfirmActions() []string {
+	var unknown []string
+	for action := range c.Confirm {
+		if !slices.Contains(ConfirmActions, action) {
+			unknown = append(unknown, action)
+		}
+	}
+	slices.Sort(unknown)
+	return unknown
+}
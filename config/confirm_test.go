@@ -0,0 +1,42 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldConfirm(t *testing.T) {
+	tests := []struct {
+		name    string
+		confirm map[string]bool
+		kill    bool
+		push    bool
+	}{
+		{name: "not set", kill: true, push: true},
+		{name: "push turned off", confirm: map[string]bool{ConfirmPush: false}, kill: true, push: false},
+		{name: "kill turned off", confirm: map[string]bool{ConfirmKill: false}, kill: false, push: true},
+		{name: "turned on explicitly", confirm: map[string]bool{ConfirmKill: true, ConfirmPush: true}, kill: true, push: true},
+		{name: "both turned off", confirm: map[string]bool{ConfirmKill: false, ConfirmPush: false}, kill: false, push: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Confirm: tt.confirm}
+			assert.Equal(t, tt.kill, config.ShouldConfirm(ConfirmKill))
+			assert.Equal(t, tt.push, config.ShouldConfirm(ConfirmPush))
+		})
+	}
+}
+
+func TestConfirmFromJSON(t *testing.T) {
+	var config Config
+	require.NoError(t, json.Unmarshal([]byte(`{"confirm": {"push": false, "discard": false, "delete": true}}`), &config))
+
+	assert.True(t, config.ShouldConfirm(ConfirmKill))
+	assert.False(t, config.ShouldConfirm(ConfirmPush))
+	assert.Equal(t, []string{"delete", "discard"}, config.UnknownConfirmActions())
+
+	assert.Empty(t, DefaultConfig().UnknownConfirmActions())
+}
@@ -0,0 +1,20 @@
+package config
+
+// DefaultDangerKeywords are the danger keywords used when AutoYesDangerCheck is on and AutoYesDangerKeywords isn't
+// set.
+var DefaultDangerKeywords = []string{
+	"rm -rf", "rm -fr", "delete", "drop table", "drop database", "truncate", "force push", "push --force",
+	"push -f", "reset --hard", "clean -fd", "branch -D", "mkfs", "dd if=",
+}
+
+// DangerKeywords returns the keywords that keep auto-yes from confirming a prompt: AutoYesDangerKeywords if set,
+// otherwise DefaultDangerKeywords. It returns nil if AutoYesDangerCheck is off.
+func (c *Config) DangerKeywords() []string {
+	if !c.AutoYesDangerCheck {
+		return nil
+	}
+	if len(c.AutoYesDangerKeywords) > 0 {
+		return c.AutoYesDangerKeywords
+	}
+	return DefaultDangerKeywords
+}
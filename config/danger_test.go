@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDangerKeywords(t *testing.T) {
+	t.Run("off by default", func(t *testing.T) {
+		config := &Config{AutoYesDangerKeywords: []string{"deploy"}}
+		assert.Nil(t, config.DangerKeywords())
+	})
+
+	t.Run("default keywords", func(t *testing.T) {
+		config := &Config{AutoYesDangerCheck: true}
+		assert.Equal(t, DefaultDangerKeywords, config.DangerKeywords())
+	})
+
+	t.Run("configured keywords replace the defaults", func(t *testing.T) {
+		config := &Config{AutoYesDangerCheck: true, AutoYesDangerKeywords: []string{"deploy", "rm -rf"}}
+		assert.Equal(t, []string{"deploy", "rm -rf"}, config.DangerKeywords())
+	})
+}
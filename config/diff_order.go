@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// The orders of the files in the diff view that can be used in Config.DiffFileOrder.
+const (
+	// DiffOrderGit shows the files in the order git lists them.
+	DiffOrderGit = "git"
+	// DiffOrderAlphabetical shows the files by path, ignoring case.
+	DiffOrderAlphabetical = "alphabetical"
+	// DiffOrderPriority shows the files in the order of the patterns in Config.DiffFilePriority.
+	DiffOrderPriority = "priority"
+)
+
+// DiffFileOrders are the names that can be used in Config.DiffFileOrder.
+var DiffFileOrders = []string{DiffOrderGit, DiffOrderAlphabetical, DiffOrderPriority}
+
+// ParseDiffFileOrder checks that order is one of DiffFileOrders. An empty order is DiffOrderGit.
+func ParseDiffFileOrder(order string) (string, error) {
+	if order == "" {
+		return DiffOrderGit, nil
+	}
+	if !slices.Contains(DiffFileOrders, order) {
+		return DiffOrderGit, fmt.Errorf("unknown diff file order %q (expected %s)", order,
+			strings.Join(DiffFileOrders, ", "))
+	}
+	return order, nil
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDiffFileOrder(t *testing.T) {
+	for _, order := range DiffFileOrders {
+		parsed, err := ParseDiffFileOrder(order)
+		assert.NoError(t, err)
+		assert.Equal(t, order, parsed)
+	}
+
+	parsed, err := ParseDiffFileOrder("")
+	assert.NoError(t, err)
+	assert.Equal(t, DiffOrderGit, parsed, "not set keeps git's order")
+
+	parsed, err = ParseDiffFileOrder("size")
+	assert.ErrorContains(t, err, `unknown diff file order "size"`)
+	assert.Equal(t, DiffOrderGit, parsed)
+}
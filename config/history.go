@@ -0,0 +1,86 @@
+package config
+
+import (
+	"claude-squad/log"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	PromptHistoryFileName = "prompt_history.json"
+	// MaxPromptHistory is the number of prompts kept in the history file. Older prompts are dropped.
+	MaxPromptHistory = 100
+)
+
+// PromptHistory is the per-repo list of previously sent prompts, oldest first.
+type PromptHistory struct {
+	path    string
+	entries []string
+}
+
+// LoadPromptHistory loads the prompt history from prompt_history.json in the RepoConfigDir of the given repo path,
+// .claude-squad by default. Returns an empty history if the file doesn't exist or cannot be parsed (not an error).
+func LoadPromptHistory(repoPath string) *PromptHistory {
+	history := &PromptHistory{
+		path:    filepath.Join(RepoConfigDir(repoPath), PromptHistoryFileName),
+		entries: make([]string, 0),
+	}
+
+	data, err := os.ReadFile(history.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.WarningLog.Printf("failed to read prompt history file: %v", err)
+		}
+		return history
+	}
+
+	var entries []string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		log.WarningLog.Printf("failed to parse prompt history file: %v", err)
+		return history
+	}
+	history.entries = entries
+	return history
+}
+
+// Entries returns the prompts in the history, oldest first.
+func (h *PromptHistory) Entries() []string {
+	return h.entries
+}
+
+// Add appends a prompt to the history and saves it to disk. Empty prompts and prompts equal to the
+// most recent entry are not recorded.
+func (h *PromptHistory) Add(prompt string) error {
+	if strings.TrimSpace(prompt) == "" {
+		return nil
+	}
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == prompt {
+		return nil
+	}
+
+	h.entries = append(h.entries, prompt)
+	if len(h.entries) > MaxPromptHistory {
+		h.entries = h.entries[len(h.entries)-MaxPromptHistory:]
+	}
+	return h.save()
+}
+
+// save writes the history to disk.
+func (h *PromptHistory) save() error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create prompt history directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(h.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal prompt history: %w", err)
+	}
+
+	if err := os.WriteFile(h.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write prompt history: %w", err)
+	}
+	return nil
+}
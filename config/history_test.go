@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptHistory(t *testing.T) {
+	t.Run("returns empty history when file doesn't exist", func(t *testing.T) {
+		history := LoadPromptHistory(t.TempDir())
+
+		assert.NotNil(t, history.Entries())
+		assert.Len(t, history.Entries(), 0)
+	})
+
+	t.Run("persists prompts oldest first", func(t *testing.T) {
+		tempDir := t.TempDir()
+
+		history := LoadPromptHistory(tempDir)
+		require.NoError(t, history.Add("fix the bug"))
+		require.NoError(t, history.Add("write tests"))
+
+		reloaded := LoadPromptHistory(tempDir)
+		assert.Equal(t, []string{"fix the bug", "write tests"}, reloaded.Entries())
+	})
+
+	t.Run("skips empty prompts and consecutive duplicates", func(t *testing.T) {
+		history := LoadPromptHistory(t.TempDir())
+
+		require.NoError(t, history.Add("fix the bug"))
+		require.NoError(t, history.Add("fix the bug"))
+		require.NoError(t, history.Add(""))
+		require.NoError(t, history.Add("  \n"))
+		require.NoError(t, history.Add("write tests"))
+		require.NoError(t, history.Add("fix the bug"))
+
+		assert.Equal(t, []string{"fix the bug", "write tests", "fix the bug"}, history.Entries())
+	})
+
+	t.Run("caps history size", func(t *testing.T) {
+		tempDir := t.TempDir()
+		history := LoadPromptHistory(tempDir)
+
+		for i := 0; i < MaxPromptHistory+5; i++ {
+			require.NoError(t, history.Add(fmt.Sprintf("prompt %d", i)))
+		}
+
+		entries := LoadPromptHistory(tempDir).Entries()
+		require.Len(t, entries, MaxPromptHistory)
+		assert.Equal(t, "prompt 5", entries[0])
+		assert.Equal(t, fmt.Sprintf("prompt %d", MaxPromptHistory+4), entries[len(entries)-1])
+	})
+
+	t.Run("returns empty history on invalid JSON", func(t *testing.T) {
+		tempDir := t.TempDir()
+		configDir := filepath.Join(tempDir, ".claude-squad")
+		require.NoError(t, os.MkdirAll(configDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, PromptHistoryFileName), []byte("not json"), 0644))
+
+		history := LoadPromptHistory(tempDir)
+
+		assert.Len(t, history.Entries(), 0)
+	})
+}
@@ -2,7 +2,7 @@ package config
 
 import (
 	"claude-squad/log"
-	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 )
@@ -12,24 +12,36 @@ const HotkeysFileName = "hotkeys.json"
 // Hotkeys maps number keys (1-9) to commands
 type Hotkeys map[string]string
 
-// LoadHotkeys loads hotkey configuration from .claude-squad/hotkeys.json in the given repo path.
-// Returns an empty map if the file doesn't exist or cannot be parsed (not an error).
+// LoadHotkeys loads hotkey configuration from hotkeys.json in the RepoConfigDir of the given repo path,
+// .claude-squad by default. Returns an empty map if the file doesn't exist or cannot be parsed (not an error).
 func LoadHotkeys(repoPath string) Hotkeys {
-	configPath := filepath.Join(repoPath, ".claude-squad", HotkeysFileName)
+	hotkeys, err := ReadHotkeys(repoPath)
+	if err != nil {
+		log.WarningLog.Printf("%v", err)
+	}
+	return hotkeys
+}
+
+// ReadHotkeys is LoadHotkeys that also returns why the file couldn't be read, e.g. a *ParseError pointing at a
+// mistake in it. A missing file is not an error.
+func ReadHotkeys(repoPath string) (Hotkeys, error) {
+	configPath := filepath.Join(RepoConfigDir(repoPath), HotkeysFileName)
 
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		if !os.IsNotExist(err) {
-			log.WarningLog.Printf("failed to read hotkeys file: %v", err)
+			return make(Hotkeys), fmt.Errorf("failed to read hotkeys file: %w", err)
 		}
-		return make(Hotkeys)
+		return make(Hotkeys), nil
 	}
 
 	var hotkeys Hotkeys
-	if err := json.Unmarshal(data, &hotkeys); err != nil {
-		log.WarningLog.Printf("failed to parse hotkeys file: %v", err)
-		return make(Hotkeys)
+	if err := unmarshalFile(configPath, data, &hotkeys); err != nil {
+		return make(Hotkeys), fmt.Errorf("failed to parse hotkeys file: %w", err)
+	}
+	if hotkeys == nil {
+		hotkeys = make(Hotkeys)
 	}
 
-	return hotkeys
+	return hotkeys, nil
 }
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ParseError is a JSON file that couldn't be decoded. Line and Column point at the problem, counted from 1, or are
+// 0 if the decoder didn't report where it is.
+type ParseError struct {
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("%s:%d:%d: %v", e.Path, e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// unmarshalFile decodes data, the contents of the file at path, into v. Errors are *ParseError.
+func unmarshalFile(path string, data []byte, v any) error {
+	err := json.Unmarshal(data, v)
+	if err == nil {
+		return nil
+	}
+
+	parseErr := &ParseError{Path: path, Err: err}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		parseErr.Line, parseErr.Column = position(data, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		parseErr.Line, parseErr.Column = position(data, typeErr.Offset)
+	}
+	return parseErr
+}
+
+// position returns the line and column of the last byte before offset in data. The decoder reports offsets just
+// past the byte it failed on, so this is the byte at fault.
+func position(data []byte, offset int64) (line, column int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	// A problem at the end of a file ending with a newline is on the last line, not after it.
+	before = bytes.TrimRight(before, "\r\n")
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = len(before) - (bytes.LastIndexByte(before, '\n') + 1)
+	if column == 0 {
+		column = 1
+	}
+	return line, column
+}
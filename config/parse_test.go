@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		column  int
+		message string
+	}{
+		{
+			name:    "syntax error",
+			content: "{\n  \"auto_yes\": true,\n  \"branch_prefix\": nope\n}",
+			line:    3,
+			column:  21,
+			message: "invalid character 'o' in literal null (expecting 'u')",
+		},
+		{
+			name:    "trailing comma",
+			content: "{\n  \"auto_yes\": true,\n}\n",
+			line:    3,
+			column:  1,
+			message: "invalid character '}' looking for beginning of object key string",
+		},
+		{
+			name:    "truncated file",
+			content: "{\n  \"auto_yes\": true\n",
+			line:    2,
+			column:  18,
+			message: "unexpected end of JSON input",
+		},
+		{
+			name:    "wrong type",
+			content: "{\n  \"daemon_poll_interval\": \"fast\"\n}",
+			line:    2,
+			column:  32,
+			message: "json: cannot unmarshal string into Go struct field Config.daemon_poll_interval of type int",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config Config
+			err := unmarshalFile("config.json", []byte(tt.content), &config)
+
+			var parseErr *ParseError
+			require.True(t, errors.As(err, &parseErr), "got %v", err)
+			assert.Equal(t, "config.json", parseErr.Path)
+			assert.Equal(t, tt.line, parseErr.Line)
+			assert.Equal(t, tt.column, parseErr.Column)
+			assert.Equal(t, tt.message, parseErr.Err.Error())
+		})
+	}
+
+	t.Run("valid file", func(t *testing.T) {
+		var config Config
+		require.NoError(t, unmarshalFile("config.json", []byte(`{"auto_yes": true}`), &config))
+		assert.True(t, config.AutoYes)
+	})
+}
+
+func TestParseErrorString(t *testing.T) {
+	err := &ParseError{Path: "/tmp/config.json", Line: 3, Column: 7, Err: errors.New("bad")}
+	assert.Equal(t, "/tmp/config.json:3:7: bad", err.Error())
+
+	err = &ParseError{Path: "/tmp/config.json", Err: errors.New("bad")}
+	assert.Equal(t, "/tmp/config.json: bad", err.Error())
+}
+
+func TestReadConfigParseError(t *testing.T) {
+	tempHome := t.TempDir()
+	configDir := filepath.Join(tempHome, ".claude-squad")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	configPath := filepath.Join(configDir, ConfigFileName)
+	require.NoError(t, os.WriteFile(configPath, []byte("{\n  \"auto_yes\": yes\n}"), 0644))
+	t.Setenv("HOME", tempHome)
+
+	config, err := ReadConfig()
+
+	var parseErr *ParseError
+	require.True(t, errors.As(err, &parseErr), "got %v", err)
+	assert.Equal(t, configPath, parseErr.Path)
+	assert.Equal(t, 2, parseErr.Line)
+	assert.Contains(t, err.Error(), configPath+":2:")
+	// The defaults are still usable.
+	require.NotNil(t, config)
+	assert.Equal(t, DefaultConfig().DaemonPollInterval, config.DaemonPollInterval)
+}
+
+func TestReadHotkeysAndTemplatesParseError(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, ".claude-squad")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, HotkeysFileName), []byte(`{"1": "/commit",}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, TemplatesFileName), []byte(`[{"name": 1}]`), 0644))
+
+	hotkeys, err := ReadHotkeys(tempDir)
+	var parseErr *ParseError
+	require.True(t, errors.As(err, &parseErr), "got %v", err)
+	assert.Equal(t, filepath.Join(configDir, HotkeysFileName), parseErr.Path)
+	assert.Equal(t, 1, parseErr.Line)
+	assert.Equal(t, 17, parseErr.Column)
+	assert.NotNil(t, hotkeys)
+	assert.Empty(t, hotkeys)
+
+	templates, err := ReadTemplates(tempDir)
+	require.True(t, errors.As(err, &parseErr), "got %v", err)
+	assert.Equal(t, filepath.Join(configDir, TemplatesFileName), parseErr.Path)
+	assert.Empty(t, templates)
+
+	// Missing files are not errors.
+	emptyDir := t.TempDir()
+	_, err = ReadHotkeys(emptyDir)
+	assert.NoError(t, err)
+	_, err = ReadTemplates(emptyDir)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"claude-squad/log"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const PromptTemplatesFileName = "prompt_templates.json"
+
+// PromptTemplate is a prompt with {name} placeholders that are filled in before it is sent to an instance.
+type PromptTemplate struct {
+	// Name is shown in the prompt template picker. It must be unique within a repo.
+	Name string `json:"name"`
+	// Prompt is the text sent to the instance, e.g. "fix the failing test in {file}".
+	Prompt string `json:"prompt"`
+}
+
+// PromptTemplates is the per-repo list of prompt templates, in the order they are offered.
+type PromptTemplates []PromptTemplate
+
+// ReadPromptTemplates reads the prompt templates from prompt_templates.json in the RepoConfigDir of the given repo
+// path. A missing file is not an error and returns no prompt templates. Prompt templates without a name or prompt
+// and later ones with the name of an earlier one are skipped.
+func ReadPromptTemplates(repoPath string) (PromptTemplates, error) {
+	path := filepath.Join(RepoConfigDir(repoPath), PromptTemplatesFileName)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read prompt templates file: %w", err)
+		}
+		return nil, nil
+	}
+
+	var loaded PromptTemplates
+	if err := unmarshalFile(path, data, &loaded); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt templates file: %w", err)
+	}
+
+	templates := make(PromptTemplates, 0, len(loaded))
+	for _, t := range loaded {
+		t.Name = strings.TrimSpace(t.Name)
+		if t.Name == "" || strings.TrimSpace(t.Prompt) == "" {
+			log.WarningLog.Printf("skipping prompt template without a name or prompt in %s", path)
+			continue
+		}
+		if _, ok := templates.Find(t.Name); ok {
+			log.WarningLog.Printf("skipping duplicate prompt template %q in %s", t.Name, path)
+			continue
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// Find returns the prompt template with the given name.
+func (t PromptTemplates) Find(name string) (PromptTemplate, bool) {
+	for _, tmpl := range t {
+		if tmpl.Name == name {
+			return tmpl, true
+		}
+	}
+	return PromptTemplate{}, false
+}
+
+// Names returns the names of the prompt templates in order.
+func (t PromptTemplates) Names() []string {
+	names := make([]string, len(t))
+	for i, tmpl := range t {
+		names[i] = tmpl.Name
+	}
+	return names
+}
+
+// placeholderPattern matches a {name} placeholder. Braces around anything else, like JSON or code, are left alone.
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z0-9_-]+)\}`)
+
+// Placeholders returns the names of the placeholders in prompt, each once, in the order they first appear.
+func Placeholders(prompt string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range placeholderPattern.FindAllStringSubmatch(prompt, -1) {
+		if name := match[1]; !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ExpandPrompt replaces every placeholder in prompt with its value in values. It fails, naming them, if any
+// placeholder has no value or a blank one.
+func ExpandPrompt(prompt string, values map[string]string) (string, error) {
+	var missing []string
+	for _, name := range Placeholders(prompt) {
+		if strings.TrimSpace(values[name]) == "" {
+			missing = append(missing, "{"+name+"}")
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing values for %s", strings.Join(missing, ", "))
+	}
+	return placeholderPattern.ReplaceAllStringFunc(prompt, func(placeholder string) string {
+		return values[placeholder[1:len(placeholder)-1]]
+	}), nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPromptTemplates(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		repo := t.TempDir()
+		dir := filepath.Join(repo, ".claude-squad")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, PromptTemplatesFileName), []byte(content), 0644))
+		return repo
+	}
+
+	t.Run("returns no prompt templates when file doesn't exist", func(t *testing.T) {
+		templates, err := ReadPromptTemplates(t.TempDir())
+		require.NoError(t, err)
+		assert.Empty(t, templates)
+	})
+
+	t.Run("loads prompt templates in order and skips invalid ones", func(t *testing.T) {
+		repo := write(t, `[
+			{"name": " fix test ", "prompt": "fix the failing test in {file}"},
+			{"name": "empty", "prompt": " "},
+			{"prompt": "no name"},
+			{"name": "fix test", "prompt": "duplicate"},
+			{"name": "explain", "prompt": "explain {symbol}"}
+		]`)
+
+		templates, err := ReadPromptTemplates(repo)
+		require.NoError(t, err)
+
+		assert.Equal(t, PromptTemplates{
+			{Name: "fix test", Prompt: "fix the failing test in {file}"},
+			{Name: "explain", Prompt: "explain {symbol}"},
+		}, templates)
+		assert.Equal(t, []string{"fix test", "explain"}, templates.Names())
+		tmpl, ok := templates.Find("explain")
+		assert.True(t, ok)
+		assert.Equal(t, "explain {symbol}", tmpl.Prompt)
+	})
+
+	t.Run("returns an error on invalid JSON", func(t *testing.T) {
+		_, err := ReadPromptTemplates(write(t, `{"name": "fix"}`))
+		assert.ErrorContains(t, err, "failed to parse prompt templates file")
+	})
+}
+
+func TestPlaceholders(t *testing.T) {
+	assert.Equal(t, []string{"file", "test_name"}, Placeholders("fix {file}: {test_name} fails, rerun {file}"))
+	assert.Empty(t, Placeholders(`send {"json": true} and { spaced } braces`))
+}
+
+func TestExpandPrompt(t *testing.T) {
+	t.Run("replaces multiple placeholders", func(t *testing.T) {
+		expanded, err := ExpandPrompt("fix {test} in {file}, then run {test} again", map[string]string{
+			"file": "auth_test.go",
+			"test": "TestLogin",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "fix TestLogin in auth_test.go, then run TestLogin again", expanded)
+	})
+
+	t.Run("leaves a prompt without placeholders as it is", func(t *testing.T) {
+		expanded, err := ExpandPrompt("run the tests {}", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "run the tests {}", expanded)
+	})
+
+	t.Run("fails on missing values", func(t *testing.T) {
+		_, err := ExpandPrompt("fix {test} in {file} for {user}", map[string]string{"test": "TestLogin", "file": " "})
+		assert.EqualError(t, err, "missing values for {file}, {user}")
+	})
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// The quit modes that can be used in Config.QuitMode.
+const (
+	// QuitLeaveRunning quits and leaves the instances running in their tmux sessions.
+	QuitLeaveRunning = "leave-running"
+	// QuitPauseAll pauses the running instances before quitting.
+	QuitPauseAll = "pause-all"
+	// QuitAsk asks to confirm pausing the running instances and quitting. Cancelling doesn't quit.
+	QuitAsk = "ask"
+)
+
+// QuitModes are the names that can be used in Config.QuitMode.
+var QuitModes = []string{QuitLeaveRunning, QuitPauseAll, QuitAsk}
+
+// ParseQuitMode checks that mode is one of QuitModes. An empty mode is QuitLeaveRunning.
+func ParseQuitMode(mode string) (string, error) {
+	if mode == "" {
+		return QuitLeaveRunning, nil
+	}
+	if !slices.Contains(QuitModes, mode) {
+		return QuitLeaveRunning, fmt.Errorf("unknown quit mode %q (expected %s)", mode, strings.Join(QuitModes, ", "))
+	}
+	return mode, nil
+}
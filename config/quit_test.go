@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuitMode(t *testing.T) {
+	for _, mode := range QuitModes {
+		parsed, err := ParseQuitMode(mode)
+		assert.NoError(t, err)
+		assert.Equal(t, mode, parsed)
+	}
+
+	parsed, err := ParseQuitMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, QuitLeaveRunning, parsed, "not set leaves the instances running")
+
+	parsed, err = ParseQuitMode("pause")
+	assert.ErrorContains(t, err, `unknown quit mode "pause"`)
+	assert.Equal(t, QuitLeaveRunning, parsed)
+}
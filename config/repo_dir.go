@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	// RepoConfigDirEnv names the environment variable that overrides the per-repo config directory.
+	RepoConfigDirEnv = "CLAUDE_SQUAD_CONFIG_DIR"
+	// repoConfigDirName is the per-repo config directory at the root of a repo.
+	repoConfigDirName = ".claude-squad"
+)
+
+// repoConfigDir overrides the per-repo config directory of every repo if it isn't empty.
+var repoConfigDir string
+
+// SetRepoConfigDir makes dir the per-repo config directory of every repo, e.g. to share hotkeys and templates
+// between the sub-projects of a monorepo. It takes precedence over CLAUDE_SQUAD_CONFIG_DIR. Empty restores the
+// default. A relative dir is relative to the working directory.
+func SetRepoConfigDir(dir string) error {
+	if dir == "" {
+		repoConfigDir = ""
+		return nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	repoConfigDir = abs
+	return nil
+}
+
+// RepoConfigDir returns the directory of the per-repo files of the repo at repoPath, like hotkeys.json and the
+// prompt history. That is .claude-squad in the repo, unless SetRepoConfigDir or CLAUDE_SQUAD_CONFIG_DIR override
+// it.
+func RepoConfigDir(repoPath string) string {
+	if repoConfigDir != "" {
+		return repoConfigDir
+	}
+	if dir := os.Getenv(RepoConfigDirEnv); dir != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			return abs
+		}
+		return dir
+	}
+	return filepath.Join(repoPath, repoConfigDirName)
+}
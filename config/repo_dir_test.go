@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoConfigDir(t *testing.T) {
+	t.Cleanup(func() { _ = SetRepoConfigDir("") })
+	repo := t.TempDir()
+
+	t.Setenv(RepoConfigDirEnv, "")
+	assert.Equal(t, filepath.Join(repo, ".claude-squad"), RepoConfigDir(repo))
+
+	shared := t.TempDir()
+	t.Setenv(RepoConfigDirEnv, shared)
+	assert.Equal(t, shared, RepoConfigDir(repo))
+
+	flag := t.TempDir()
+	require.NoError(t, SetRepoConfigDir(flag))
+	assert.Equal(t, flag, RepoConfigDir(repo), "the flag should take precedence over the environment")
+
+	require.NoError(t, SetRepoConfigDir("shared"))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(wd, "shared"), RepoConfigDir(repo), "a relative dir should be made absolute")
+
+	require.NoError(t, SetRepoConfigDir(""))
+	assert.Equal(t, shared, RepoConfigDir(repo))
+}
+
+func TestRepoConfigDirRedirectsFiles(t *testing.T) {
+	repo := t.TempDir()
+	shared := t.TempDir()
+	t.Setenv(RepoConfigDirEnv, shared)
+
+	require.NoError(t, os.WriteFile(filepath.Join(shared, HotkeysFileName), []byte(`{"1": "/commit"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(shared, TemplatesFileName), []byte(`[{"name": "review"}]`), 0644))
+	assert.Equal(t, Hotkeys{"1": "/commit"}, LoadHotkeys(repo))
+	assert.Equal(t, []string{"review"}, LoadTemplates(repo).Names())
+
+	require.NoError(t, LoadPromptHistory(repo).Add("fix the bug"))
+	require.NoError(t, LoadCommandUsage(repo).Record("/commit", time.Now()))
+	assert.FileExists(t, filepath.Join(shared, PromptHistoryFileName))
+	assert.FileExists(t, filepath.Join(shared, CommandUsageFileName))
+	assert.Equal(t, []string{"fix the bug"}, LoadPromptHistory(repo).Entries())
+
+	assert.NoDirExists(t, filepath.Join(repo, ".claude-squad"), "nothing should be written to the repo")
+}
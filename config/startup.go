@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// The startup modes that can be used in Config.StartupMode and with the --startup flag.
+const (
+	// StartupRestoreAll loads every stored instance.
+	StartupRestoreAll = "restore-all"
+	// StartupRestoreRunning loads the stored instances that aren't paused or archived.
+	StartupRestoreRunning = "restore-running-only"
+	// StartupStartEmpty loads no stored instances. They stay in storage for a later start.
+	StartupStartEmpty = "start-empty"
+)
+
+// StartupModes are the names that can be used in Config.StartupMode.
+var StartupModes = []string{StartupRestoreAll, StartupRestoreRunning, StartupStartEmpty}
+
+// ParseStartupMode checks that mode is one of StartupModes. An empty mode is StartupRestoreAll.
+func ParseStartupMode(mode string) (string, error) {
+	if mode == "" {
+		return StartupRestoreAll, nil
+	}
+	if !slices.Contains(StartupModes, mode) {
+		return StartupRestoreAll, fmt.Errorf("unknown startup mode %q (expected %s)", mode,
+			strings.Join(StartupModes, ", "))
+	}
+	return mode, nil
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStartupMode(t *testing.T) {
+	for _, mode := range StartupModes {
+		parsed, err := ParseStartupMode(mode)
+		assert.NoError(t, err)
+		assert.Equal(t, mode, parsed)
+	}
+
+	parsed, err := ParseStartupMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, StartupRestoreAll, parsed, "not set restores all instances")
+
+	parsed, err = ParseStartupMode("restore-none")
+	assert.ErrorContains(t, err, `unknown startup mode "restore-none"`)
+	assert.Equal(t, StartupRestoreAll, parsed)
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"claude-squad/log"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const TemplatesFileName = "templates.json"
+
+// Template bundles the settings of the instances created for one kind of task.
+type Template struct {
+	// Name is shown in the template picker. It must be unique within a repo.
+	Name string `json:"name"`
+	// Program is the program to run, split into arguments with shell quoting rules. Empty means the default
+	// program.
+	Program string `json:"program,omitempty"`
+	// Args are extra arguments appended to the program's own.
+	Args []string `json:"args,omitempty"`
+	// Env is set in the instance's environment on top of claude-squad's own.
+	Env map[string]string `json:"env,omitempty"`
+	// InitPrompts are sent to the program one after another once it has started.
+	InitPrompts []string `json:"init_prompts,omitempty"`
+	// Tags label the instances created from the template.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Templates is the per-repo list of templates, in the order they are offered.
+type Templates []Template
+
+// LoadTemplates loads the templates from templates.json in the RepoConfigDir of the given repo path, .claude-squad
+// by default. Returns no templates if the file doesn't exist or cannot be parsed (not an error). Templates without
+// a name and later templates with the name of an earlier one are skipped.
+func LoadTemplates(repoPath string) Templates {
+	templates, err := ReadTemplates(repoPath)
+	if err != nil {
+		log.WarningLog.Printf("%v", err)
+	}
+	return templates
+}
+
+// ReadTemplates is LoadTemplates that also returns why the file couldn't be read, e.g. a *ParseError pointing at a
+// mistake in it. A missing file is not an error.
+func ReadTemplates(repoPath string) (Templates, error) {
+	path := filepath.Join(RepoConfigDir(repoPath), TemplatesFileName)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read templates file: %w", err)
+		}
+		return nil, nil
+	}
+
+	var loaded Templates
+	if err := unmarshalFile(path, data, &loaded); err != nil {
+		return nil, fmt.Errorf("failed to parse templates file: %w", err)
+	}
+
+	templates := make(Templates, 0, len(loaded))
+	for _, t := range loaded {
+		t.Name = strings.TrimSpace(t.Name)
+		if t.Name == "" {
+			log.WarningLog.Printf("skipping template without a name in %s", path)
+			continue
+		}
+		if _, ok := templates.Find(t.Name); ok {
+			log.WarningLog.Printf("skipping duplicate template %q in %s", t.Name, path)
+			continue
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// Find returns the template with the given name.
+func (t Templates) Find(name string) (Template, bool) {
+	for _, tmpl := range t {
+		if tmpl.Name == name {
+			return tmpl, true
+		}
+	}
+	return Template{}, false
+}
+
+// Names returns the names of the templates in order.
+func (t Templates) Names() []string {
+	names := make([]string, len(t))
+	for i, tmpl := range t {
+		names[i] = tmpl.Name
+	}
+	return names
+}
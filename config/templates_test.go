@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTemplates(t *testing.T, content string) string {
+	t.Helper()
+	repo := t.TempDir()
+	dir := filepath.Join(repo, ".claude-squad")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, TemplatesFileName), []byte(content), 0644))
+	return repo
+}
+
+func TestLoadTemplates(t *testing.T) {
+	t.Run("returns no templates when file doesn't exist", func(t *testing.T) {
+		assert.Empty(t, LoadTemplates(t.TempDir()))
+	})
+
+	t.Run("loads templates in order", func(t *testing.T) {
+		repo := writeTemplates(t, `[
+			{
+				"name": "review",
+				"program": "claude --model opus",
+				"args": ["--verbose"],
+				"env": {"TASK": "review"},
+				"init_prompts": ["/review", "summarize"],
+				"tags": ["review"]
+			},
+			{"name": "bugfix"}
+		]`)
+
+		templates := LoadTemplates(repo)
+
+		require.Len(t, templates, 2)
+		assert.Equal(t, Template{
+			Name:        "review",
+			Program:     "claude --model opus",
+			Args:        []string{"--verbose"},
+			Env:         map[string]string{"TASK": "review"},
+			InitPrompts: []string{"/review", "summarize"},
+			Tags:        []string{"review"},
+		}, templates[0])
+		assert.Equal(t, Template{Name: "bugfix"}, templates[1])
+		assert.Equal(t, []string{"review", "bugfix"}, templates.Names())
+	})
+
+	t.Run("skips templates without a name and duplicates", func(t *testing.T) {
+		repo := writeTemplates(t, `[
+			{"name": " docs ", "program": "aider"},
+			{"program": "codex"},
+			{"name": "docs", "program": "gemini"}
+		]`)
+
+		templates := LoadTemplates(repo)
+
+		require.Len(t, templates, 1)
+		assert.Equal(t, Template{Name: "docs", Program: "aider"}, templates[0])
+	})
+
+	t.Run("returns no templates on invalid JSON", func(t *testing.T) {
+		assert.Empty(t, LoadTemplates(writeTemplates(t, `{"name": "review"}`)))
+	})
+}
+
+func TestTemplatesFind(t *testing.T) {
+	templates := Templates{{Name: "review", Program: "claude"}, {Name: "bugfix", Program: "aider"}}
+
+	tmpl, ok := templates.Find("bugfix")
+	assert.True(t, ok)
+	assert.Equal(t, "aider", tmpl.Program)
+
+	_, ok = templates.Find("docs")
+	assert.False(t, ok)
+}
@@ -33,6 +33,11 @@ func RunDaemon(cfg *config.Config) error {
 		instance.AutoYes = true
 	}
 
+	autoYesMatcher, err := session.NewAutoYesMatcher(cfg.AutoYesPatterns, cfg.DangerKeywords())
+	if err != nil {
+		log.ErrorLog.Printf("%v", err)
+	}
+
 	pollInterval := time.Duration(cfg.DaemonPollInterval) * time.Millisecond
 
 	// If we get an error for a session, it's likely that we'll keep getting the error. Log every 30 seconds.
@@ -47,8 +52,8 @@ func RunDaemon(cfg *config.Config) error {
 		for {
 			for _, instance := range instances {
 				// We only store started instances, but check anyway.
-				if instance.Started() && !instance.Paused() {
-					if _, hasPrompt := instance.HasUpdated(); hasPrompt {
+				if instance.Started() && !instance.Paused() && !instance.Archived() {
+					if _, hasPrompt := instance.HasUpdated(); hasPrompt && autoYesMatcher.Matches(instance.PromptText()) {
 						instance.TapEnter()
 						if err := instance.UpdateDiffStats(); err != nil {
 							if everyN.ShouldLog() {
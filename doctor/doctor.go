@@ -0,0 +1,160 @@
+package doctor
+
+import (
+	"bytes"
+	"claude-squad/cmd"
+	"claude-squad/config"
+	"claude-squad/session"
+	"claude-squad/session/tmux"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Check is a single health check run by the doctor command.
+type Check struct {
+	// Name is shown in the checklist.
+	Name string
+	// Hard checks make the doctor command fail. Failed soft checks are only reported as warnings.
+	Hard bool
+	// Run performs the check and returns a short detail to display on success.
+	Run func() (string, error)
+}
+
+// Result is the outcome of running a Check.
+type Result struct {
+	Name   string
+	Hard   bool
+	Detail string
+	Err    error
+}
+
+// DefaultChecks returns the checks for the current environment. configDir is the claude-squad config directory
+// and program is the program new instances will run.
+func DefaultChecks(cmdExec cmd.Executor, configDir string, program string) []Check {
+	return []Check{
+		{Name: "tmux", Hard: true, Run: func() (string, error) { return CheckTmux(cmdExec) }},
+		{Name: "git", Hard: true, Run: func() (string, error) { return CheckGit(cmdExec) }},
+		{Name: "program", Hard: false, Run: func() (string, error) { return CheckProgram(program, exec.LookPath) }},
+		{Name: "config", Hard: true, Run: func() (string, error) {
+			return CheckConfig(filepath.Join(configDir, config.ConfigFileName))
+		}},
+		{Name: "storage", Hard: true, Run: func() (string, error) {
+			return CheckStorage(filepath.Join(configDir, config.StateFileName))
+		}},
+	}
+}
+
+// RunChecks runs every check in order and collects the results.
+func RunChecks(checks []Check) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		detail, err := check.Run()
+		results = append(results, Result{Name: check.Name, Hard: check.Hard, Detail: detail, Err: err})
+	}
+	return results
+}
+
+// PrintResults writes a checklist of results to w. It returns false if any hard check failed.
+func PrintResults(w io.Writer, results []Result) bool {
+	ok := true
+	for _, r := range results {
+		switch {
+		case r.Err == nil:
+			fmt.Fprintf(w, "[✓] %s: %s\n", r.Name, r.Detail)
+		case r.Hard:
+			ok = false
+			fmt.Fprintf(w, "[✗] %s: %v\n", r.Name, r.Err)
+		default:
+			fmt.Fprintf(w, "[!] %s: %v\n", r.Name, r.Err)
+		}
+	}
+	return ok
+}
+
+// CheckTmux verifies that tmux is installed and new enough.
+func CheckTmux(cmdExec cmd.Executor) (string, error) {
+	version, err := tmux.CheckVersion(cmdExec)
+	if err != nil {
+		return "", fmt.Errorf("%w. %s", err, tmux.InstallHint())
+	}
+	return version, nil
+}
+
+// CheckGit verifies that git is installed.
+func CheckGit(cmdExec cmd.Executor) (string, error) {
+	output, err := cmdExec.Output(exec.Command("git", "--version"))
+	if err != nil {
+		return "", fmt.Errorf("git is not available: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CheckProgram verifies that the executable of program (ex. "claude --model x") resolves using lookPath. The
+// program is split with shell quoting rules, so an executable whose path has quoted spaces is found.
+// This is a soft check because the program may be a shell alias that only resolves in an interactive shell.
+func CheckProgram(program string, lookPath func(string) (string, error)) (string, error) {
+	args, err := session.SplitArgs(program)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse program: %w", err)
+	}
+	if len(args) == 0 {
+		return "", fmt.Errorf("no program configured")
+	}
+	path, err := lookPath(args[0])
+	if err != nil {
+		return "", fmt.Errorf("%s not found on PATH: %w", args[0], err)
+	}
+	return path, nil
+}
+
+// CheckConfig verifies that the config file at path parses. A missing file is fine since defaults are used.
+func CheckConfig(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "not found, defaults will be used", nil
+		}
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+	// Unknown keys are reported, since a misspelled setting is otherwise silently ignored.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var cfg config.Config
+	if err := decoder.Decode(&cfg); err != nil {
+		if key, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return "", fmt.Errorf("unknown key %s in %s", key, path)
+		}
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if decoder.More() {
+		return "", fmt.Errorf("failed to parse %s: unexpected data after the config", path)
+	}
+	return path, nil
+}
+
+// CheckStorage verifies that the state file at path is readable and its stored instances parse.
+func CheckStorage(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "not found, it will be created on first run", nil
+		}
+		return "", fmt.Errorf("failed to read state file: %w", err)
+	}
+	var state config.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	instances := make([]session.InstanceData, 0)
+	if len(state.InstancesData) > 0 {
+		if err := json.Unmarshal(state.InstancesData, &instances); err != nil {
+			return "", fmt.Errorf("failed to parse stored instances: %w", err)
+		}
+	}
+	return fmt.Sprintf("%d stored instance(s)", len(instances)), nil
+}
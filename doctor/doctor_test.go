@@ -0,0 +1,137 @@
+package doctor
+
+import (
+	"bytes"
+	"claude-squad/cmd/cmd_test"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func outputExec(output string, err error) cmd_test.MockCmdExec {
+	return cmd_test.MockCmdExec{
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			return []byte(output), err
+		},
+	}
+}
+
+func TestCheckTmux(t *testing.T) {
+	t.Run("passes for a supported version", func(t *testing.T) {
+		detail, err := CheckTmux(outputExec("tmux 3.3a\n", nil))
+		require.NoError(t, err)
+		assert.Equal(t, "tmux 3.3a", detail)
+	})
+
+	t.Run("fails for an old version", func(t *testing.T) {
+		_, err := CheckTmux(outputExec("tmux 1.8\n", nil))
+		assert.Error(t, err)
+	})
+
+	t.Run("fails when tmux is missing", func(t *testing.T) {
+		_, err := CheckTmux(outputExec("", exec.ErrNotFound))
+		assert.Error(t, err)
+	})
+}
+
+func TestCheckGit(t *testing.T) {
+	detail, err := CheckGit(outputExec("git version 2.43.0\n", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "git version 2.43.0", detail)
+
+	_, err = CheckGit(outputExec("", exec.ErrNotFound))
+	assert.Error(t, err)
+}
+
+func TestCheckProgram(t *testing.T) {
+	lookPath := func(name string) (string, error) {
+		switch name {
+		case "claude":
+			return "/usr/local/bin/claude", nil
+		case "/opt/my tools/claude":
+			return name, nil
+		}
+		return "", exec.ErrNotFound
+	}
+
+	detail, err := CheckProgram("claude --dangerously-skip-permissions", lookPath)
+	require.NoError(t, err)
+	assert.Equal(t, "/usr/local/bin/claude", detail)
+
+	_, err = CheckProgram("aider", lookPath)
+	assert.Error(t, err)
+
+	detail, err = CheckProgram("'/opt/my tools/claude' --model x", lookPath)
+	require.NoError(t, err)
+	assert.Equal(t, "/opt/my tools/claude", detail)
+
+	_, err = CheckProgram("", lookPath)
+	assert.Error(t, err)
+
+	_, err = CheckProgram("'claude", lookPath)
+	assert.Error(t, err)
+}
+
+func TestCheckConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := CheckConfig(filepath.Join(dir, "missing.json"))
+	assert.NoError(t, err)
+
+	valid := filepath.Join(dir, "valid.json")
+	require.NoError(t, os.WriteFile(valid, []byte(`{"default_program": "claude"}`), 0644))
+	_, err = CheckConfig(valid)
+	assert.NoError(t, err)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"default_program": `), 0644))
+	_, err = CheckConfig(invalid)
+	assert.Error(t, err)
+
+	unknown := filepath.Join(dir, "unknown.json")
+	require.NoError(t, os.WriteFile(unknown, []byte(`{"default_program": "claude", "auto_yse": true}`), 0644))
+	_, err = CheckConfig(unknown)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown key "auto_yse"`)
+}
+
+func TestCheckStorage(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := CheckStorage(filepath.Join(dir, "missing.json"))
+	assert.NoError(t, err)
+
+	valid := filepath.Join(dir, "valid.json")
+	require.NoError(t, os.WriteFile(valid, []byte(`{"instances": [{"title": "a"}]}`), 0644))
+	detail, err := CheckStorage(valid)
+	require.NoError(t, err)
+	assert.Equal(t, "1 stored instance(s)", detail)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"instances": {}}`), 0644))
+	_, err = CheckStorage(invalid)
+	assert.Error(t, err)
+}
+
+func TestPrintResults(t *testing.T) {
+	var buf bytes.Buffer
+	ok := PrintResults(&buf, RunChecks([]Check{
+		{Name: "hard-pass", Hard: true, Run: func() (string, error) { return "fine", nil }},
+		{Name: "soft-fail", Hard: false, Run: func() (string, error) { return "", fmt.Errorf("missing") }},
+	}))
+	assert.True(t, ok)
+	assert.Contains(t, buf.String(), "[✓] hard-pass: fine")
+	assert.Contains(t, buf.String(), "[!] soft-fail: missing")
+
+	buf.Reset()
+	ok = PrintResults(&buf, RunChecks([]Check{
+		{Name: "hard-fail", Hard: true, Run: func() (string, error) { return "", fmt.Errorf("broken") }},
+	}))
+	assert.False(t, ok)
+	assert.Contains(t, buf.String(), "[✗] hard-fail: broken")
+}
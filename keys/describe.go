@@ -0,0 +1,140 @@
+package keys
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Category groups the keys in the keybindings reference.
+type Category string
+
+const (
+	CategorySessions Category = "Sessions"
+	CategoryHandoff  Category = "Handoff"
+	CategoryView     Category = "View"
+	CategoryOther    Category = "Other"
+)
+
+// Categories are the categories of the keybindings reference, in the order they are listed.
+var Categories = []Category{CategorySessions, CategoryHandoff, CategoryView, CategoryOther}
+
+// Description says what the action of a key does and where the keybindings reference lists it.
+type Description struct {
+	Category Category
+	Text     string
+}
+
+// Descriptions describes every key action. Actions that aren't bound to a key in GlobalKeyStringsMap, like
+// KeySubmitName, are described too but aren't listed in the reference.
+var Descriptions = map[KeyName]Description{
+	KeyUp:             {CategorySessions, "Select the previous session"},
+	KeyDown:           {CategorySessions, "Select the next session"},
+	KeyEnter:          {CategorySessions, "Attach to the selected session"},
+	KeyNew:            {CategorySessions, "Create a new session"},
+	KeyPrompt:         {CategorySessions, "Create a new session with a prompt"},
+	KeyTemplate:       {CategorySessions, "Create a new session from a template"},
+	KeyRemote:         {CategorySessions, "Create a new session from a remote branch"},
+	KeyNewInRepo:      {CategorySessions, "Create a new session in another repository"},
+	KeySubmitName:     {CategorySessions, "Submit the name of a new session"},
+	KeyKill:           {CategorySessions, "Kill (delete) the selected session"},
+	KeySendPrompt:     {CategorySessions, "Send a prompt, queued while the session is busy"},
+	KeyResend:         {CategorySessions, "Send the session's last prompt again"},
+	KeyForwardPrompt:  {CategorySessions, "Send the session's last prompt to another session"},
+	KeyPromptTemplate: {CategorySessions, "Send a prompt template after filling in its placeholders"},
+	KeyInterrupt:      {CategorySessions, "Interrupt the session's program"},
+	KeyAutoYes:        {CategorySessions, "Turn auto-yes on or off for all sessions"},
+	KeyNewInPlace:     {CategorySessions, "Create a new session in the current directory, without a worktree"},
+	KeyNote:           {CategorySessions, "Edit the session's note"},
+	KeyColor:          {CategorySessions, "Set the session's color and icon"},
+	KeyGroup:          {CategorySessions, "Set the session's group in the list"},
+	KeyRestart:        {CategorySessions, "Restart a session whose program exited"},
+	KeySearch:         {CategorySessions, "Search the output of all sessions"},
+	KeySwitch:         {CategorySessions, "Jump to a session by typing part of its title or tags"},
+
+	KeyReview:       {CategoryHandoff, "Review the session's changes"},
+	KeyPush:         {CategoryHandoff, "Push the session's branch"},
+	KeySubmit:       {CategoryHandoff, "Commit and push the session's branch"},
+	KeyRebase:       {CategoryHandoff, "Rebase the session's branch onto its latest base branch"},
+	KeyCheckpoint:   {CategoryHandoff, "Commit the session's work on a new checkpoint branch"},
+	KeyCheckpoints:  {CategoryHandoff, "List the session's auto-commits and checkpoints to restore one"},
+	KeyCheckout:     {CategoryHandoff, "Commit changes and pause the session"},
+	KeyResume:       {CategoryHandoff, "Resume a paused session"},
+	KeyResumeOpen:   {CategoryHandoff, "Resume a paused session and attach once it is ready"},
+	KeyPauseAll:     {CategoryHandoff, "Pause all running sessions"},
+	KeyResumeAll:    {CategoryHandoff, "Resume all paused sessions"},
+	KeyArchive:      {CategoryHandoff, "Archive the selected session, or restore it"},
+	KeyShowArchived: {CategoryHandoff, "Show or hide archived sessions"},
+	KeyCopyBranch:   {CategoryHandoff, "Copy the session's branch name"},
+	KeyCopyPath:     {CategoryHandoff, "Copy the session's worktree path"},
+	KeyCopyDiff:     {CategoryHandoff, "Copy the session's full diff"},
+	KeySaveDiff:     {CategoryHandoff, "Save the session's full diff to a patch file"},
+	KeyObserve:      {CategoryHandoff, "Show and copy the command to watch the session read-only"},
+	KeyTmuxSessions: {CategoryHandoff, "List the tmux session names of all sessions, to copy them"},
+
+	KeyTab:            {CategoryView, "Switch to the next tab"},
+	KeyPrevTab:        {CategoryView, "Switch to the previous tab"},
+	KeyShiftUp:        {CategoryView, "Scroll up in the diff or preview"},
+	KeyShiftDown:      {CategoryView, "Scroll down in the diff or preview"},
+	KeyShiftLeft:      {CategoryView, "Scroll wide lines left in the diff"},
+	KeyShiftRight:     {CategoryView, "Scroll wide lines right in the diff"},
+	KeyDiffFiles:      {CategoryView, "Expand the changed files in the diff"},
+	KeyNextFile:       {CategoryView, "Jump to the next file in the diff"},
+	KeyPrevFile:       {CategoryView, "Jump to the previous file in the diff"},
+	KeyNextHunk:       {CategoryView, "Jump to the next hunk in the diff"},
+	KeyPrevHunk:       {CategoryView, "Jump to the previous hunk in the diff"},
+	KeyDiffWhitespace: {CategoryView, "Hide or show whitespace-only changes in the diff"},
+	KeyDiffMode:       {CategoryView, "Diff the whole branch or only uncommitted changes"},
+	KeyRefresh:        {CategoryView, "Refresh the session's status and diff"},
+	KeyCompact:        {CategoryView, "Show one line per session in the list, or two"},
+	KeySort:           {CategoryView, "List the sessions that need you first"},
+	KeyFold:           {CategoryView, "Collapse or expand the selected group in the list"},
+	KeyPin:            {CategoryView, "Pin the panes to the selected session, or unpin them"},
+	KeyActivity:       {CategoryView, "Show the session's activity log"},
+
+	KeyHelp: {CategoryOther, "Show help"},
+	KeyQuit: {CategoryOther, "Quit"},
+}
+
+// Binding is a key action with the keys bound to it.
+type Binding struct {
+	Name KeyName
+	// Keys are the keys bound to the action, shortest first, like ["k", "up"].
+	Keys []string
+	Description
+}
+
+// Section is a category of the keybindings reference and its bindings.
+type Section struct {
+	Category Category
+	Bindings []Binding
+}
+
+// Cheatsheet returns the key actions bound in keyStrings, like GlobalKeyStringsMap, grouped by category in the
+// order of Categories. Each section lists its actions in KeyName order. Categories without bound actions are left
+// out, and so are actions without a description.
+func Cheatsheet(keyStrings map[string]KeyName) []Section {
+	bound := make(map[KeyName][]string)
+	for keyString, name := range keyStrings {
+		bound[name] = append(bound[name], keyString)
+	}
+
+	var sections []Section
+	for _, category := range Categories {
+		section := Section{Category: category}
+		for name := KeyName(0); name < numKeyNames; name++ {
+			description, ok := Descriptions[name]
+			if !ok || description.Category != category || len(bound[name]) == 0 {
+				continue
+			}
+			keys := bound[name]
+			slices.SortFunc(keys, func(a, b string) int {
+				return cmp.Or(cmp.Compare(len(a), len(b)), cmp.Compare(a, b))
+			})
+			section.Bindings = append(section.Bindings, Binding{Name: name, Keys: keys, Description: description})
+		}
+		if len(section.Bindings) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
@@ -0,0 +1,85 @@
+package keys
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEveryKeyHasADescription(t *testing.T) {
+	for name := KeyName(0); name < numKeyNames; name++ {
+		description, ok := Descriptions[name]
+		if assert.True(t, ok, "key %d has no description", name) {
+			assert.NotEmpty(t, description.Text, "key %d", name)
+			assert.Contains(t, Categories, description.Category, "key %d", name)
+		}
+	}
+}
+
+func TestCheatsheet(t *testing.T) {
+	t.Run("lists every bound key once", func(t *testing.T) {
+		listed := make(map[string]int)
+		for _, section := range Cheatsheet(GlobalKeyStringsMap) {
+			for _, binding := range section.Bindings {
+				assert.Equal(t, section.Category, binding.Category)
+				for _, key := range binding.Keys {
+					listed[key]++
+					assert.Equal(t, binding.Name, GlobalKeyStringsMap[key], "key %q", key)
+				}
+			}
+		}
+		for key := range GlobalKeyStringsMap {
+			assert.Equal(t, 1, listed[key], "key %q", key)
+		}
+	})
+
+	t.Run("reflects the given bindings", func(t *testing.T) {
+		sections := Cheatsheet(map[string]KeyName{
+			"ctrl+c": KeyQuit,
+			"x":      KeyQuit,
+			"?":      KeyHelp,
+			"h":      KeyUp,
+		})
+
+		require.Len(t, sections, 2)
+		assert.Equal(t, CategorySessions, sections[0].Category)
+		assert.Equal(t, []Binding{{Name: KeyUp, Keys: []string{"h"}, Description: Descriptions[KeyUp]}},
+			sections[0].Bindings)
+		assert.Equal(t, CategoryOther, sections[1].Category)
+		assert.Equal(t, []Binding{
+			{Name: KeyQuit, Keys: []string{"x", "ctrl+c"}, Description: Descriptions[KeyQuit]},
+			{Name: KeyHelp, Keys: []string{"?"}, Description: Descriptions[KeyHelp]},
+		}, sections[1].Bindings)
+	})
+
+	t.Run("sections follow the order of the categories", func(t *testing.T) {
+		var categories []Category
+		for _, section := range Cheatsheet(GlobalKeyStringsMap) {
+			categories = append(categories, section.Category)
+		}
+		assert.Equal(t, Categories, categories)
+	})
+}
+
+func TestRebind(t *testing.T) {
+	keyStrings := make(map[string]KeyName)
+	for k, v := range GlobalKeyStringsMap {
+		keyStrings[k] = v
+	}
+	binding := GlobalkeyBindings[KeyAutoYes]
+	t.Cleanup(func() {
+		GlobalKeyStringsMap = keyStrings
+		GlobalkeyBindings[KeyAutoYes] = binding
+	})
+
+	require.NoError(t, Rebind(KeyAutoYes, "ctrl+a"))
+	assert.Equal(t, KeyAutoYes, GlobalKeyStringsMap["ctrl+a"])
+	assert.NotContains(t, GlobalKeyStringsMap, "ctrl+y")
+	assert.Equal(t, "ctrl+a", GlobalkeyBindings[KeyAutoYes].Help().Key)
+	assert.Equal(t, "auto-yes", GlobalkeyBindings[KeyAutoYes].Help().Desc)
+
+	require.NoError(t, Rebind(KeyAutoYes, "ctrl+a"), "rebinding to the same key does nothing")
+	assert.EqualError(t, Rebind(KeyAutoYes, "n"), `key "n" is already bound to new`)
+	assert.Equal(t, KeyAutoYes, GlobalKeyStringsMap["ctrl+a"])
+}
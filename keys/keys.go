@@ -1,6 +1,8 @@
 package keys
 
 import (
+	"fmt"
+
 	"github.com/charmbracelet/bubbles/key"
 )
 
@@ -18,6 +20,7 @@ const (
 	KeySubmit
 
 	KeyTab        // Tab is a special keybinding for switching between panes.
+	KeyPrevTab    // PrevTab switches to the pane to the left.
 	KeySubmitName // SubmitName is a special keybinding for submitting the name of a new instance.
 
 	KeyCheckout
@@ -28,30 +31,145 @@ const (
 	// Diff keybindings
 	KeyShiftUp
 	KeyShiftDown
+	KeyShiftLeft
+	KeyShiftRight
+	KeyDiffFiles
+	KeyNextFile
+	KeyPrevFile
+	KeyNextHunk       // Key for jumping to the next hunk in the diff
+	KeyPrevHunk       // Key for jumping to the previous hunk in the diff
+	KeyDiffWhitespace // Key for toggling whether whitespace-only changes are shown in the diff
+	KeyDiffMode       // Key for switching the diff between the whole branch and uncommitted changes
+
+	KeyRefresh // Key for updating the selected instance immediately
+
+	KeyPauseAll  // Key for pausing every running instance
+	KeyResumeAll // Key for resuming every paused instance
+
+	KeyRestart // Key for restarting an instance whose program died
+
+	KeyActivity // Key for showing the activity log of the selected instance
+
+	KeyCopyBranch // Key for copying the selected instance's branch name
+	KeyCopyPath   // Key for copying the selected instance's worktree path
+
+	KeyCompact // Key for switching the instance list between one line and two lines per instance
+
+	KeyTemplate // Key for creating a new instance from a template
+	KeyRemote   // Key for creating a new instance from a remote branch
+
+	KeySendPrompt // Key for sending a prompt to the selected instance, queued while it is busy
+	KeyInterrupt  // Key for interrupting the selected instance's program without attaching
+	KeyNote       // Key for editing the selected instance's note
+	KeyResumeOpen // Key for resuming the selected instance if it is paused and attaching to it
+	KeySort       // Key for switching the instance list between attention order and the order they were added
+	KeyResend     // Key for sending the selected instance's last prompt again
+
+	KeyArchive      // Key for archiving the selected instance or restoring it if it is archived
+	KeyShowArchived // Key for showing or hiding archived instances
+
+	KeyRebase    // Key for rebasing the selected instance's branch onto its base branch
+	KeySearch    // Key for searching the output of all instances
+	KeyNewInRepo // Key for creating a new instance in another repository
+	KeyColor     // Key for setting the selected instance's color and icon
+	KeyGroup     // Key for setting the selected instance's group
+	KeyFold      // Key for collapsing or expanding the selected group in the list
+
+	KeyCheckpoint // Key for committing the selected instance's work on a checkpoint branch
+
+	KeyObserve // Key for showing the command that attaches to the selected instance read-only
+
+	KeyPin // Key for pinning the preview and diff panes to the selected instance, or unpinning them
+
+	KeyPromptTemplate // Key for sending a prompt template to the selected instance after filling its placeholders
+
+	KeyCopyDiff // Key for copying the selected instance's full diff
+	KeySaveDiff // Key for saving the selected instance's full diff to a patch file
+
+	KeyAutoYes // Key for turning auto-yes on or off for all instances
+
+	KeyNewInPlace // Key for creating a new instance that runs in the current directory without a worktree
+
+	KeyForwardPrompt // Key for sending the selected instance's last prompt to another instance
+
+	KeySwitch // Key for jumping to an instance by typing part of its title or tags
+
+	KeyTmuxSessions // Key for listing the tmux session names of all instances
+
+	KeyCheckpoints // Key for listing the selected instance's auto-commits and checkpoints to restore one
+
+	// numKeyNames is the number of key names. It must stay last.
+	numKeyNames
 )
 
-// GlobalKeyStringsMap is a global, immutable map string to keybinding.
+// GlobalKeyStringsMap is a global map string to keybinding. Rebind changes it at startup; it is read-only after.
 var GlobalKeyStringsMap = map[string]KeyName{
-	"up":         KeyUp,
-	"k":          KeyUp,
-	"down":       KeyDown,
-	"j":          KeyDown,
-	"shift+up":   KeyShiftUp,
-	"shift+down": KeyShiftDown,
-	"N":          KeyPrompt,
-	"enter":      KeyEnter,
-	"o":          KeyEnter,
-	"n":          KeyNew,
-	"D":          KeyKill,
-	"q":          KeyQuit,
-	"tab":        KeyTab,
-	"c":          KeyCheckout,
-	"r":          KeyResume,
-	"p":          KeySubmit,
-	"?":          KeyHelp,
+	"up":          KeyUp,
+	"k":           KeyUp,
+	"down":        KeyDown,
+	"j":           KeyDown,
+	"shift+up":    KeyShiftUp,
+	"shift+down":  KeyShiftDown,
+	"shift+left":  KeyShiftLeft,
+	"shift+right": KeyShiftRight,
+	"N":           KeyPrompt,
+	"enter":       KeyEnter,
+	"o":           KeyEnter,
+	"n":           KeyNew,
+	"D":           KeyKill,
+	"q":           KeyQuit,
+	"tab":         KeyTab,
+	"shift+tab":   KeyPrevTab,
+	"c":           KeyCheckout,
+	"r":           KeyResume,
+	"p":           KeySubmit,
+	"?":           KeyHelp,
+	"f":           KeyDiffFiles,
+	"]":           KeyNextFile,
+	"[":           KeyPrevFile,
+	"}":           KeyNextHunk,
+	"{":           KeyPrevHunk,
+	"W":           KeyDiffWhitespace,
+	"u":           KeyDiffMode,
+	"ctrl+r":      KeyRefresh,
+	"C":           KeyPauseAll,
+	"R":           KeyResumeAll,
+	"x":           KeyRestart,
+	"L":           KeyActivity,
+	"b":           KeyCopyBranch,
+	"w":           KeyCopyPath,
+	"v":           KeyCompact,
+	"t":           KeyTemplate,
+	"B":           KeyRemote,
+	"s":           KeySendPrompt,
+	"i":           KeyInterrupt,
+	"e":           KeyNote,
+	"O":           KeyResumeOpen,
+	"S":           KeySort,
+	".":           KeyResend,
+	"a":           KeyArchive,
+	"A":           KeyShowArchived,
+	"U":           KeyRebase,
+	"/":           KeySearch,
+	"P":           KeyNewInRepo,
+	"#":           KeyColor,
+	"g":           KeyGroup,
+	"z":           KeyFold,
+	"K":           KeyCheckpoint,
+	"V":           KeyObserve,
+	"m":           KeyPin,
+	"T":           KeyPromptTemplate,
+	"y":           KeyCopyDiff,
+	"Y":           KeySaveDiff,
+	"ctrl+y":      KeyAutoYes,
+	"I":           KeyNewInPlace,
+	"F":           KeyForwardPrompt,
+	"ctrl+p":      KeySwitch,
+	"ctrl+t":      KeyTmuxSessions,
+	"H":           KeyCheckpoints,
 }
 
-// GlobalkeyBindings is a global, immutable map of KeyName tot keybinding.
+// GlobalkeyBindings is a global map of KeyName to keybinding. Rebind changes it at startup; it is read-only after.
 var GlobalkeyBindings = map[KeyName]key.Binding{
 	KeyUp: key.NewBinding(
 		key.WithKeys("up", "k"),
@@ -69,6 +187,186 @@ var GlobalkeyBindings = map[KeyName]key.Binding{
 		key.WithKeys("shift+down"),
 		key.WithHelp("shift+↓", "scroll"),
 	),
+	KeyDiffFiles: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "files"),
+	),
+	KeyNextFile: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next file"),
+	),
+	KeyPrevFile: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "prev file"),
+	),
+	KeyNextHunk: key.NewBinding(
+		key.WithKeys("}"),
+		key.WithHelp("}", "next hunk"),
+	),
+	KeyPrevHunk: key.NewBinding(
+		key.WithKeys("{"),
+		key.WithHelp("{", "prev hunk"),
+	),
+	KeyDiffWhitespace: key.NewBinding(
+		key.WithKeys("W"),
+		key.WithHelp("W", "whitespace"),
+	),
+	KeyDiffMode: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "uncommitted"),
+	),
+	KeyRefresh: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "refresh"),
+	),
+	KeyRestart: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "restart"),
+	),
+	KeyActivity: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "activity"),
+	),
+	KeyCopyBranch: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "copy branch"),
+	),
+	KeyCopyPath: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "copy path"),
+	),
+	KeyCompact: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "compact"),
+	),
+	KeyTemplate: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "new from template"),
+	),
+	KeyRemote: key.NewBinding(
+		key.WithKeys("B"),
+		key.WithHelp("B", "new from remote"),
+	),
+	KeySendPrompt: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "send prompt"),
+	),
+	KeyInterrupt: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "interrupt"),
+	),
+	KeyNote: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "note"),
+	),
+	KeyResumeOpen: key.NewBinding(
+		key.WithKeys("O"),
+		key.WithHelp("O", "resume & open"),
+	),
+	KeySort: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "sort"),
+	),
+	KeyResend: key.NewBinding(
+		key.WithKeys("."),
+		key.WithHelp(".", "resend"),
+	),
+	KeyArchive: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "archive/restore"),
+	),
+	KeyShowArchived: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "show archived"),
+	),
+	KeyRebase: key.NewBinding(
+		key.WithKeys("U"),
+		key.WithHelp("U", "rebase"),
+	),
+	KeyNewInRepo: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "new in repo"),
+	),
+	KeyColor: key.NewBinding(
+		key.WithKeys("#"),
+		key.WithHelp("#", "color"),
+	),
+	KeyGroup: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "group"),
+	),
+	KeyFold: key.NewBinding(
+		key.WithKeys("z"),
+		key.WithHelp("z", "fold group"),
+	),
+	KeyCheckpoint: key.NewBinding(
+		key.WithKeys("K"),
+		key.WithHelp("K", "checkpoint"),
+	),
+	KeyObserve: key.NewBinding(
+		key.WithKeys("V"),
+		key.WithHelp("V", "observe"),
+	),
+	KeyPin: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "pin"),
+	),
+	KeyPromptTemplate: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "prompt template"),
+	),
+	KeyCopyDiff: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "copy diff"),
+	),
+	KeySaveDiff: key.NewBinding(
+		key.WithKeys("Y"),
+		key.WithHelp("Y", "save diff"),
+	),
+	KeyAutoYes: key.NewBinding(
+		key.WithKeys("ctrl+y"),
+		key.WithHelp("ctrl+y", "auto-yes"),
+	),
+	KeyNewInPlace: key.NewBinding(
+		key.WithKeys("I"),
+		key.WithHelp("I", "new in place"),
+	),
+	KeyForwardPrompt: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("F", "forward prompt"),
+	),
+	KeySwitch: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("ctrl+p", "switch"),
+	),
+	KeyTmuxSessions: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("ctrl+t", "tmux sessions"),
+	),
+	KeyCheckpoints: key.NewBinding(
+		key.WithKeys("H"),
+		key.WithHelp("H", "checkpoints"),
+	),
+	KeySearch: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search output"),
+	),
+	KeyPauseAll: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "pause all"),
+	),
+	KeyResumeAll: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "resume all"),
+	),
+	KeyShiftLeft: key.NewBinding(
+		key.WithKeys("shift+left"),
+		key.WithHelp("shift+←", "scroll left"),
+	),
+	KeyShiftRight: key.NewBinding(
+		key.WithKeys("shift+right"),
+		key.WithHelp("shift+→", "scroll right"),
+	),
 	KeyEnter: key.NewBinding(
 		key.WithKeys("enter", "o"),
 		key.WithHelp("↵/o", "open"),
@@ -105,6 +403,10 @@ var GlobalkeyBindings = map[KeyName]key.Binding{
 		key.WithKeys("tab"),
 		key.WithHelp("tab", "switch tab"),
 	),
+	KeyPrevTab: key.NewBinding(
+		key.WithKeys("shift+tab"),
+		key.WithHelp("shift+tab", "prev tab"),
+	),
 	KeyResume: key.NewBinding(
 		key.WithKeys("r"),
 		key.WithHelp("r", "resume"),
@@ -117,3 +419,98 @@ var GlobalkeyBindings = map[KeyName]key.Binding{
 		key.WithHelp("enter", "submit name"),
 	),
 }
+
+// ActionNames maps the names used for actions in the config, like in menu_items, to their keys.
+var ActionNames = map[string]KeyName{
+	"new":             KeyNew,
+	"prompt":          KeyPrompt,
+	"kill":            KeyKill,
+	"open":            KeyEnter,
+	"push":            KeySubmit,
+	"checkout":        KeyCheckout,
+	"resume":          KeyResume,
+	"restart":         KeyRestart,
+	"scroll":          KeyShiftUp,
+	"tab":             KeyTab,
+	"prev-tab":        KeyPrevTab,
+	"help":            KeyHelp,
+	"quit":            KeyQuit,
+	"files":           KeyDiffFiles,
+	"next-file":       KeyNextFile,
+	"prev-file":       KeyPrevFile,
+	"next-hunk":       KeyNextHunk,
+	"prev-hunk":       KeyPrevHunk,
+	"whitespace":      KeyDiffWhitespace,
+	"diff-mode":       KeyDiffMode,
+	"refresh":         KeyRefresh,
+	"activity":        KeyActivity,
+	"copy-branch":     KeyCopyBranch,
+	"copy-path":       KeyCopyPath,
+	"pause-all":       KeyPauseAll,
+	"resume-all":      KeyResumeAll,
+	"compact":         KeyCompact,
+	"template":        KeyTemplate,
+	"remote":          KeyRemote,
+	"send":            KeySendPrompt,
+	"interrupt":       KeyInterrupt,
+	"note":            KeyNote,
+	"resume-open":     KeyResumeOpen,
+	"sort":            KeySort,
+	"resend":          KeyResend,
+	"archive":         KeyArchive,
+	"archived":        KeyShowArchived,
+	"rebase":          KeyRebase,
+	"search":          KeySearch,
+	"new-in-repo":     KeyNewInRepo,
+	"color":           KeyColor,
+	"group":           KeyGroup,
+	"fold":            KeyFold,
+	"checkpoint":      KeyCheckpoint,
+	"observe":         KeyObserve,
+	"pin":             KeyPin,
+	"prompt-template": KeyPromptTemplate,
+	"copy-diff":       KeyCopyDiff,
+	"save-diff":       KeySaveDiff,
+	"auto-yes":        KeyAutoYes,
+	"new-in-place":    KeyNewInPlace,
+	"forward-prompt":  KeyForwardPrompt,
+	"switch":          KeySwitch,
+	"tmux-sessions":   KeyTmuxSessions,
+	"checkpoints":     KeyCheckpoints,
+}
+
+// ParseActionNames returns the keys of the named actions in the same order. Names that aren't in ActionNames are
+// skipped and returned in unknown.
+func ParseActionNames(names []string) (actions []KeyName, unknown []string) {
+	for _, name := range names {
+		action, ok := ActionNames[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions, unknown
+}
+
+// Rebind moves the action name from its key to keyString, e.g. "ctrl+y". It is meant to be called once at startup,
+// before the key maps are read. It fails if keyString is already bound to another action.
+func Rebind(name KeyName, keyString string) error {
+	if bound, ok := GlobalKeyStringsMap[keyString]; ok {
+		if bound == name {
+			return nil
+		}
+		return fmt.Errorf("key %q is already bound to %s", keyString, GlobalkeyBindings[bound].Help().Desc)
+	}
+	for k, n := range GlobalKeyStringsMap {
+		if n == name {
+			delete(GlobalKeyStringsMap, k)
+		}
+	}
+	GlobalKeyStringsMap[keyString] = name
+	GlobalkeyBindings[name] = key.NewBinding(
+		key.WithKeys(keyString),
+		key.WithHelp(keyString, GlobalkeyBindings[name].Help().Desc),
+	)
+	return nil
+}
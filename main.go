@@ -5,6 +5,7 @@ import (
 	cmd2 "claude-squad/cmd"
 	"claude-squad/config"
 	"claude-squad/daemon"
+	"claude-squad/doctor"
 	"claude-squad/log"
 	"claude-squad/session"
 	"claude-squad/session/git"
@@ -12,7 +13,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -22,7 +25,13 @@ var (
 	programFlag                    string
 	autoYesFlag                    bool
 	daemonFlag                     bool
+	onConflictFlag                 string
+	repairFixFlag                  bool
+	repairRemoveFlag               bool
 	dangerouslySkipPermissionsFlag bool
+	startupFlag                    string
+	repoConfigDirFlag              string
+	statusSocketFlag               string
 	rootCmd                        = &cobra.Command{
 		Use:   "claude-squad",
 		Short: "Claude Squad - Manage multiple AI agents like Claude Code, Aider, Codex, and Amp.",
@@ -33,6 +42,9 @@ var (
 
 			if daemonFlag {
 				cfg := config.LoadConfig()
+				setupTmuxServer(cfg)
+				tmux.SetControlMode(cfg.TmuxControlMode)
+				git.SetRetryPolicy(git.RetryPolicy{Attempts: cfg.RetryAttempts(), BaseDelay: cfg.RetryDelay()})
 				err := daemon.RunDaemon(cfg)
 				log.ErrorLog.Printf("failed to start daemon %v", err)
 				return err
@@ -47,8 +59,22 @@ var (
 			if !git.IsGitRepo(currentDir) {
 				return fmt.Errorf("error: claude-squad must be run from within a git repository")
 			}
+			if startupFlag != "" {
+				if _, err := config.ParseStartupMode(startupFlag); err != nil {
+					return err
+				}
+			}
+			if err := config.SetRepoConfigDir(repoConfigDirFlag); err != nil {
+				return fmt.Errorf("invalid --repo-config-dir: %w", err)
+			}
 
 			cfg := config.LoadConfig()
+			setupTmuxServer(cfg)
+			tmux.SetControlMode(cfg.TmuxControlMode)
+			git.SetRetryPolicy(git.RetryPolicy{Attempts: cfg.RetryAttempts(), BaseDelay: cfg.RetryDelay()})
+			if err := tmux.SetDetachKey(cfg.DetachKey); err != nil {
+				log.WarningLog.Printf("%v, using %s", err, tmux.DefaultDetachKey)
+			}
 
 			// Program flag overrides config
 			program := cfg.DefaultProgram
@@ -64,19 +90,19 @@ var (
 			if autoYesFlag {
 				autoYes = true
 			}
-			if autoYes {
-				defer func() {
-					if err := daemon.LaunchDaemon(); err != nil {
-						log.ErrorLog.Printf("failed to launch daemon: %v", err)
-					}
-				}()
-			}
 			// Kill any daemon that's running.
 			if err := daemon.StopDaemon(); err != nil {
 				log.ErrorLog.Printf("failed to stop daemon: %v", err)
 			}
 
-			return app.Run(ctx, program, autoYes)
+			// Auto-yes can be toggled while running, so the daemon follows the state it was left in.
+			autoYes, err = app.Run(ctx, program, autoYes, startupFlag, statusSocketFlag)
+			if autoYes {
+				if err := daemon.LaunchDaemon(); err != nil {
+					log.ErrorLog.Printf("failed to launch daemon: %v", err)
+				}
+			}
+			return err
 		},
 	}
 
@@ -87,6 +113,9 @@ var (
 			log.Initialize(false)
 			defer log.Close()
 
+			cfg := config.LoadConfig()
+			setupTmuxServer(cfg)
+
 			state := config.LoadState()
 			storage, err := session.NewStorage(state)
 			if err != nil {
@@ -102,7 +131,7 @@ var (
 			}
 			fmt.Println("Tmux sessions have been cleaned up")
 
-			if err := git.CleanupWorktrees(); err != nil {
+			if err := git.CleanupWorktrees(cmd2.MakeExecutor()); err != nil {
 				return fmt.Errorf("failed to cleanup worktrees: %w", err)
 			}
 			fmt.Println("Worktrees have been cleaned up")
@@ -138,6 +167,126 @@ var (
 		},
 	}
 
+	exportCmd = &cobra.Command{
+		Use:   "export [file]",
+		Short: "Export stored instances as JSON (to stdout if no file is given)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+			setupTmuxServer(config.LoadConfig())
+
+			storage, err := session.NewStorage(config.LoadState())
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+
+			out := os.Stdout
+			if len(args) == 1 {
+				f, err := os.Create(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to create export file: %w", err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			if err := storage.ExportJSON(out); err != nil {
+				return fmt.Errorf("failed to export instances: %w", err)
+			}
+			return nil
+		},
+	}
+
+	importCmd = &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import instances exported from another machine",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+			setupTmuxServer(config.LoadConfig())
+
+			mode, err := session.ParseConflictMode(onConflictFlag)
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open import file: %w", err)
+			}
+			defer f.Close()
+
+			storage, err := session.NewStorage(config.LoadState())
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			needsCheckout, err := storage.ImportJSON(cmd2.MakeExecutor(), f, mode)
+			if err != nil {
+				return fmt.Errorf("failed to import instances: %w", err)
+			}
+			fmt.Println("Instances have been imported successfully")
+			if len(needsCheckout) > 0 {
+				fmt.Printf("These instances have no worktree on this machine and need a checkout, resume them to "+
+					"check them out: %s\n", strings.Join(needsCheckout, ", "))
+			}
+			return nil
+		},
+	}
+
+	doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that claude-squad's dependencies and files are healthy",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+
+			cfg := config.LoadConfig()
+			setupTmuxServer(cfg)
+			configDir, err := config.GetConfigDir()
+			if err != nil {
+				log.Close()
+				return fmt.Errorf("failed to get config directory: %w", err)
+			}
+
+			results := doctor.RunChecks(doctor.DefaultChecks(cmd2.MakeExecutor(), configDir, cfg.DefaultProgram))
+			ok := doctor.PrintResults(os.Stdout, results)
+			log.Close()
+			if !ok {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+
+	repairCmd = &cobra.Command{
+		Use:   "repair",
+		Short: "Prune stale git worktrees and find instances whose worktree is missing",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+			setupTmuxServer(config.LoadConfig())
+
+			storage, err := session.NewStorage(config.LoadState())
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+
+			report, err := storage.CheckWorktrees(cmd2.MakeExecutor(), session.RepairOptions{
+				Repair: repairFixFlag,
+				Remove: repairRemoveFlag,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to check worktrees: %w", err)
+			}
+			report.Print(os.Stdout)
+			if report.Unfixed() > 0 && !repairFixFlag && !repairRemoveFlag {
+				fmt.Println("Run with --fix to check out missing worktrees again and --remove to remove instances that cannot be repaired")
+			}
+			return nil
+		},
+	}
+
 	versionCmd = &cobra.Command{
 		Use:   "version",
 		Short: "Print the version number of claude-squad",
@@ -148,6 +297,13 @@ var (
 	}
 )
 
+// setupTmuxServer makes the tmux commands use the session prefix and the tmux server configured in cfg, so that
+// the subcommands find the same sessions the app created.
+func setupTmuxServer(cfg *config.Config) {
+	tmux.SetSessionPrefix(cfg.TmuxSessionPrefix)
+	tmux.SetIsolatedServer(cfg.IsolatedTmux)
+}
+
 func init() {
 	rootCmd.Flags().StringVarP(&programFlag, "program", "p", "",
 		"Program to run in new instances (e.g. 'aider --model ollama_chat/gemma3:1b')")
@@ -155,6 +311,13 @@ func init() {
 		"[experimental] If enabled, all instances will automatically accept prompts")
 	rootCmd.Flags().BoolVar(&dangerouslySkipPermissionsFlag, "dangerously-skip-permissions", false,
 		"Skip Claude's permission prompts (adds --dangerously-skip-permissions to claude)")
+	rootCmd.Flags().StringVar(&startupFlag, "startup", "",
+		"Which stored sessions to load: restore-all, restore-running-only or start-empty (overrides startup_mode)")
+	rootCmd.Flags().StringVar(&repoConfigDirFlag, "repo-config-dir", "",
+		"Directory of the per-repo hotkeys, templates and prompt history instead of .claude-squad in the repo"+
+			" (overrides "+config.RepoConfigDirEnv+")")
+	rootCmd.Flags().StringVar(&statusSocketFlag, "status-socket", "",
+		"Serve the status of the sessions as JSON on a Unix socket at this path, for status bars and other tools")
 	rootCmd.Flags().BoolVar(&daemonFlag, "daemon", false, "Run a program that loads all sessions"+
 		" and runs autoyes mode on them.")
 
@@ -164,9 +327,21 @@ func init() {
 		panic(err)
 	}
 
+	importCmd.Flags().StringVar(&onConflictFlag, "on-conflict", "skip",
+		"How to handle instances whose title already exists: skip, overwrite or rename")
+
+	repairCmd.Flags().BoolVar(&repairFixFlag, "fix", false,
+		"Check out missing worktrees again from their branch and reconnect worktrees git lost track of")
+	repairCmd.Flags().BoolVar(&repairRemoveFlag, "remove", false,
+		"Remove instances whose worktree and branch are both gone")
+
 	rootCmd.AddCommand(debugCmd)
+	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(resetCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(repairCmd)
 }
 
 func main() {
@@ -0,0 +1,178 @@
+package session
+
+import (
+	"bufio"
+	"claude-squad/config"
+	"claude-squad/log"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// EventKind is the kind of lifecycle event recorded in an instance's activity log.
+type EventKind string
+
+const (
+	EventCreated   EventKind = "created"
+	EventPrompt    EventKind = "prompt"
+	EventPushed    EventKind = "pushed"
+	EventRebased   EventKind = "rebased"
+	EventPaused    EventKind = "paused"
+	EventResumed   EventKind = "resumed"
+	EventRestarted EventKind = "restarted"
+	EventArchived  EventKind = "archived"
+	EventRestored  EventKind = "restored"
+	// EventInterrupted is recorded when the program was sent the interrupt keys.
+	EventInterrupted EventKind = "interrupted"
+	// EventExited is recorded with the exit code when the program ended on its own.
+	EventExited EventKind = "exited"
+	// EventCheckpoint is recorded with the branch the instance's work was committed on to bookmark it.
+	EventCheckpoint EventKind = "checkpoint"
+)
+
+const (
+	activityDirName = "activity"
+	// maxActivityLogSize is the size in bytes at which an activity log is rotated. One rotated file is kept, so
+	// an instance's activity takes at most about twice this much space.
+	maxActivityLogSize = 256 * 1024
+)
+
+// Event is a single entry in an instance's activity log.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Kind   EventKind `json:"kind"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// ActivityLog is the append-only log of lifecycle events of one instance. Each event is stored as a line of
+// JSON in .claude-squad/activity/<title>.jsonl.
+type ActivityLog struct {
+	path string
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// NewActivityLog returns the activity log of the instance with the given title.
+func NewActivityLog(title string) (*ActivityLog, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	name := unsafeFileNameChars.ReplaceAllString(title, "_") + ".jsonl"
+	return &ActivityLog{path: filepath.Join(configDir, activityDirName, name)}, nil
+}
+
+// Append adds an event to the log, rotating the log first if it has grown too large.
+func (l *ActivityLog) Append(kind EventKind, detail string) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create activity log directory: %w", err)
+	}
+	if info, err := os.Stat(l.path); err == nil && info.Size() >= maxActivityLogSize {
+		if err := os.Rename(l.path, l.rotatedPath()); err != nil {
+			return fmt.Errorf("failed to rotate activity log: %w", err)
+		}
+	}
+
+	line, err := json.Marshal(Event{Time: time.Now(), Kind: kind, Detail: detail})
+	if err != nil {
+		return fmt.Errorf("failed to marshal activity event: %w", err)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open activity log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write activity log: %w", err)
+	}
+	return nil
+}
+
+// Events returns the events in the log, including the rotated file, oldest first. Lines that can't be parsed
+// are skipped.
+func (l *ActivityLog) Events() ([]Event, error) {
+	var events []Event
+	for _, path := range []string{l.rotatedPath(), l.path} {
+		fileEvents, err := readEvents(path)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, fileEvents...)
+	}
+	return events, nil
+}
+
+// Delete removes the log and its rotated file. A log that was never written is not an error.
+func (l *ActivityLog) Delete() error {
+	for _, path := range []string{l.path, l.rotatedPath()} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete activity log: %w", err)
+		}
+	}
+	return nil
+}
+
+func (l *ActivityLog) rotatedPath() string {
+	return l.path + ".1"
+}
+
+// readEvents reads the events in the file at path. A missing file has no events.
+func readEvents(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open activity log: %w", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	// Prompts can be long, so allow lines up to the rotation size.
+	scanner.Buffer(make([]byte, 0, 64*1024), maxActivityLogSize)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read activity log: %w", err)
+	}
+	return events, nil
+}
+
+// RecordEvent appends an event to the instance's activity log. Failures are logged, since the log is only a
+// record and should never get in the way of the action itself.
+func (i *Instance) RecordEvent(kind EventKind, detail string) {
+	activity, err := i.ActivityLog()
+	if err == nil {
+		err = activity.Append(kind, detail)
+	}
+	if err != nil && log.WarningLog != nil {
+		log.WarningLog.Printf("failed to record %s event for %s: %v", kind, i.Title, err)
+	}
+}
+
+// DeleteActivityLog removes the instance's activity log once it is killed, so an instance created later with the
+// same title doesn't show its events. Failures are logged like those of RecordEvent.
+func (i *Instance) DeleteActivityLog() {
+	activity, err := i.ActivityLog()
+	if err == nil {
+		err = activity.Delete()
+	}
+	if err != nil && log.WarningLog != nil {
+		log.WarningLog.Printf("failed to delete the activity log of %s: %v", i.Title, err)
+	}
+}
+
+// ActivityLog returns the instance's activity log.
+func (i *Instance) ActivityLog() (*ActivityLog, error) {
+	return NewActivityLog(i.Title)
+}
@@ -0,0 +1,105 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestActivityLog(t *testing.T, title string) *ActivityLog {
+	t.Setenv("HOME", t.TempDir())
+	activity, err := NewActivityLog(title)
+	require.NoError(t, err)
+	return activity
+}
+
+func TestActivityLog(t *testing.T) {
+	t.Run("no events before anything is recorded", func(t *testing.T) {
+		activity := newTestActivityLog(t, "feature")
+
+		events, err := activity.Events()
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("appends events in order", func(t *testing.T) {
+		activity := newTestActivityLog(t, "feature")
+
+		require.NoError(t, activity.Append(EventCreated, "user/feature"))
+		require.NoError(t, activity.Append(EventPrompt, "fix the tests\nthen push"))
+		require.NoError(t, activity.Append(EventPaused, ""))
+
+		events, err := activity.Events()
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+		assert.Equal(t, EventCreated, events[0].Kind)
+		assert.Equal(t, "user/feature", events[0].Detail)
+		assert.Equal(t, "fix the tests\nthen push", events[1].Detail)
+		assert.Equal(t, EventPaused, events[2].Kind)
+		assert.False(t, events[0].Time.After(events[2].Time))
+	})
+
+	t.Run("stored under the config directory with a safe file name", func(t *testing.T) {
+		activity := newTestActivityLog(t, "fix/../bug one")
+
+		require.NoError(t, activity.Append(EventCreated, ""))
+
+		home := os.Getenv("HOME")
+		assert.FileExists(t, filepath.Join(home, ".claude-squad", "activity", "fix_.._bug_one.jsonl"))
+	})
+
+	t.Run("rotates when the log grows too large", func(t *testing.T) {
+		activity := newTestActivityLog(t, "feature")
+		big := strings.Repeat("x", maxActivityLogSize/4)
+
+		for i := 0; i < 10; i++ {
+			require.NoError(t, activity.Append(EventPrompt, big))
+		}
+
+		info, err := os.Stat(activity.path)
+		require.NoError(t, err)
+		assert.Less(t, info.Size(), int64(maxActivityLogSize+len(big)+100))
+		assert.FileExists(t, activity.rotatedPath())
+
+		// The rotated file is still shown, but older rotations are dropped.
+		events, err := activity.Events()
+		require.NoError(t, err)
+		assert.Less(t, len(events), 10)
+		assert.NotEmpty(t, events)
+	})
+
+	t.Run("deleting removes the rotated file too", func(t *testing.T) {
+		activity := newTestActivityLog(t, "feature")
+		require.NoError(t, activity.Append(EventCreated, ""))
+		require.NoError(t, os.WriteFile(activity.rotatedPath(), []byte("{}\n"), 0644))
+
+		require.NoError(t, activity.Delete())
+		assert.NoFileExists(t, activity.path)
+		assert.NoFileExists(t, activity.rotatedPath())
+		events, err := activity.Events()
+		require.NoError(t, err)
+		assert.Empty(t, events)
+
+		require.NoError(t, activity.Delete(), "a missing log is already deleted")
+	})
+
+	t.Run("skips corrupt lines", func(t *testing.T) {
+		activity := newTestActivityLog(t, "feature")
+		require.NoError(t, activity.Append(EventCreated, ""))
+		f, err := os.OpenFile(activity.path, os.O_APPEND|os.O_WRONLY, 0644)
+		require.NoError(t, err)
+		_, err = f.WriteString("{not json\n")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		require.NoError(t, activity.Append(EventPaused, ""))
+
+		events, err := activity.Events()
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, EventPaused, events[1].Kind)
+	})
+}
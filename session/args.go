@@ -0,0 +1,116 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitArgs splits a program string into its arguments the way a POSIX shell would, so that quoted
+// arguments and paths with spaces survive. Single quotes preserve everything literally, double quotes
+// allow backslash escapes of ", \, $ and `, and a backslash outside quotes escapes the next character.
+func SplitArgs(s string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		// inArg is true once the current argument has started, so that "" yields an empty argument.
+		inArg bool
+	)
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("trailing backslash in %q", s)
+			}
+			i++
+			// A backslash-newline is a line continuation.
+			if runes[i] != '\n' {
+				inArg = true
+				current.WriteRune(runes[i])
+			}
+		case r == '\'':
+			inArg = true
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					closed = true
+					break
+				}
+				current.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated single quote in %q", s)
+			}
+		case r == '"':
+			inArg = true
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '"' {
+					closed = true
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated double quote in %q", s)
+			}
+		default:
+			inArg = true
+			current.WriteRune(r)
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// JoinArgs joins arguments into a string that a POSIX shell splits back into the same arguments.
+// Arguments that need it are wrapped in single quotes.
+func JoinArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteArg(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// quoteArg single-quotes arg unless it consists only of characters the shell treats literally.
+func quoteArg(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range arg {
+		if !isSafeArgRune(r) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return arg
+	}
+	// A single quote can't appear inside single quotes, so close the quotes, add an escaped quote and reopen.
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+func isSafeArgRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+	return strings.ContainsRune("-_./:=@%+,~", r)
+}
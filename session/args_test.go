@@ -0,0 +1,105 @@
+package session
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"single program", "claude", []string{"claude"}},
+		{"flags", "claude --model opus --dangerously-skip-permissions", []string{"claude", "--model", "opus", "--dangerously-skip-permissions"}},
+		{"extra whitespace", "  aider \t --yes  ", []string{"aider", "--yes"}},
+		{"empty", "", nil},
+		{"path with spaces in single quotes", "'/opt/my tools/claude' --verbose", []string{"/opt/my tools/claude", "--verbose"}},
+		{"path with spaces in double quotes", `"/opt/my tools/claude"`, []string{"/opt/my tools/claude"}},
+		{"escaped space", `/opt/my\ tools/claude`, []string{"/opt/my tools/claude"}},
+		{"quoted argument", `claude --append-system-prompt "be brief, don't ramble"`, []string{"claude", "--append-system-prompt", "be brief, don't ramble"}},
+		{"escapes inside double quotes", `echo "say \"hi\" \$HOME \n"`, []string{"echo", `say "hi" $HOME \n`}},
+		{"backslash is literal in single quotes", `echo 'a\b'`, []string{"echo", `a\b`}},
+		{"adjacent quoted parts join", `--flag='a b'"c d"e`, []string{"--flag=a bc de"}},
+		{"empty quoted argument", `claude "" ''`, []string{"claude", "", ""}},
+		{"line continuation", "claude \\\n--yes", []string{"claude", "--yes"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitArgs(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("errors on unbalanced quoting", func(t *testing.T) {
+		for _, input := range []string{`claude "unterminated`, `claude 'unterminated`, `claude \`} {
+			_, err := SplitArgs(input)
+			assert.Error(t, err, input)
+		}
+	})
+}
+
+func TestJoinArgs(t *testing.T) {
+	t.Run("leaves plain arguments unquoted", func(t *testing.T) {
+		assert.Equal(t, "claude --model opus ~/bin/x KEY=value", JoinArgs([]string{"claude", "--model", "opus", "~/bin/x", "KEY=value"}))
+	})
+
+	t.Run("round trips through SplitArgs", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"/opt/my tools/claude", "--verbose"},
+			{"claude", "--append-system-prompt", "don't \"ramble\""},
+			{"claude", "", "$HOME", "a;b", "`x`", "tab\there", "new\nline"},
+		} {
+			got, err := SplitArgs(JoinArgs(args))
+			require.NoError(t, err)
+			assert.Equal(t, args, got)
+		}
+	})
+}
+
+func TestInstanceArgs(t *testing.T) {
+	t.Run("launches the program string as it is", func(t *testing.T) {
+		program := `MODEL=opus claude --model "$MODEL" && echo done | tee $(mktemp)`
+		instance, err := NewInstance(InstanceOptions{Title: "args", Path: t.TempDir(), Program: program})
+		require.NoError(t, err)
+
+		assert.Nil(t, instance.Args)
+		assert.Equal(t, program, instance.command())
+		assert.Equal(t, "MODEL=opus", instance.ProgramName())
+	})
+
+	t.Run("explicit args take precedence", func(t *testing.T) {
+		instance, err := NewInstance(InstanceOptions{Title: "args", Path: t.TempDir(), Args: []string{"claude", "--append-system-prompt", "be brief"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, "claude --append-system-prompt 'be brief'", instance.Program)
+		assert.Equal(t, "claude --append-system-prompt 'be brief'", instance.command())
+	})
+
+	t.Run("stored programs are not rewritten", func(t *testing.T) {
+		instance, err := FromInstanceData(InstanceData{Title: "legacy", Path: t.TempDir(),
+			Program: `claude --model "$MODEL"`, Status: Paused})
+		require.NoError(t, err)
+
+		assert.Nil(t, instance.Args)
+		assert.Nil(t, instance.ToInstanceData().Args)
+		assert.Equal(t, `claude --model "$MODEL"`, instance.command())
+	})
+
+	t.Run("args are persisted", func(t *testing.T) {
+		instance, err := NewInstance(InstanceOptions{Title: "args", Path: t.TempDir(), Args: []string{"claude", "a b"}})
+		require.NoError(t, err)
+
+		encoded, err := json.Marshal(instance.ToInstanceData())
+		require.NoError(t, err)
+		var data InstanceData
+		require.NoError(t, json.Unmarshal(encoded, &data))
+
+		assert.Equal(t, []string{"claude", "a b"}, data.Args)
+	})
+}
@@ -0,0 +1,74 @@
+package session
+
+import (
+	"slices"
+	"time"
+)
+
+// attentionRank is how urgently an instance needs the user. Lower ranks need the user sooner.
+type attentionRank int
+
+const (
+	// attentionPrompt is a program waiting at a prompt, such as a permission question.
+	attentionPrompt attentionRank = iota
+	// attentionReady is a program that finished and waits for the next prompt.
+	attentionReady
+	// attentionDead is a program that exited and needs a restart.
+	attentionDead
+	attentionRunning
+	attentionPaused
+	// attentionAside is an instance that is archived or being deleted.
+	attentionAside
+)
+
+// attention is what an instance is ordered by in SortByAttention.
+type attention struct {
+	rank attentionRank
+	// readySince is when the program last printed something. More recently ready instances come first.
+	readySince time.Time
+}
+
+// attentionOf returns the attention of instance from its status and the last HasUpdated call.
+func attentionOf(instance *Instance) attention {
+	switch {
+	case instance.Status == Dead || instance.Status == Exited:
+		return attention{rank: attentionDead}
+	case instance.Status == Paused:
+		return attention{rank: attentionPaused}
+	case instance.Status == Archived || instance.Status == Deleting:
+		return attention{rank: attentionAside}
+	case instance.PromptText() != "":
+		return attention{rank: attentionPrompt}
+	case instance.Status == Ready:
+		return attention{rank: attentionReady, readySince: instance.LastActive()}
+	}
+	return attention{rank: attentionRunning}
+}
+
+// compareAttention returns a negative number if a needs the user sooner than b, a positive number if b does, and
+// zero if they are equally urgent.
+func compareAttention(a, b attention) int {
+	if a.rank != b.rank {
+		return int(a.rank - b.rank)
+	}
+	if a.rank == attentionReady {
+		// The most recently ready instance first.
+		return b.readySince.Compare(a.readySince)
+	}
+	return 0
+}
+
+// SortByAttention returns instances ordered by how urgently they need the user: programs waiting at a prompt
+// first, then ready programs, most recently ready first, then exited, running and paused ones. Instances that are
+// equally urgent keep their order. instances itself is not changed.
+func SortByAttention(instances []*Instance) []*Instance {
+	sorted := slices.Clone(instances)
+	attentions := make(map[*Instance]attention, len(instances))
+	for _, instance := range instances {
+		attentions[instance] = attentionOf(instance)
+	}
+	slices.SortStableFunc(sorted, func(a, b *Instance) int {
+		return compareAttention(attentions[a], attentions[b])
+	})
+	return sorted
+}
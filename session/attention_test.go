@@ -0,0 +1,81 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareAttention(t *testing.T) {
+	now := time.Now()
+	prompt := attention{rank: attentionPrompt}
+	readyNow := attention{rank: attentionReady, readySince: now}
+	readyEarlier := attention{rank: attentionReady, readySince: now.Add(-time.Minute)}
+	dead := attention{rank: attentionDead}
+	running := attention{rank: attentionRunning}
+	paused := attention{rank: attentionPaused}
+	aside := attention{rank: attentionAside}
+
+	// Each attention needs the user sooner than every later one.
+	ordered := []attention{prompt, readyNow, readyEarlier, dead, running, paused, aside}
+	for i, a := range ordered {
+		for j, b := range ordered {
+			switch {
+			case i < j:
+				assert.Negative(t, compareAttention(a, b), "%d before %d", i, j)
+			case i > j:
+				assert.Positive(t, compareAttention(a, b), "%d after %d", i, j)
+			default:
+				assert.Zero(t, compareAttention(a, b), "%d equal to itself", i)
+			}
+		}
+	}
+
+	// Equally urgent instances compare equal, so a stable sort keeps their order.
+	assert.Zero(t, compareAttention(attention{rank: attentionRunning}, attention{rank: attentionRunning}))
+	assert.Zero(t, compareAttention(attention{rank: attentionReady, readySince: now},
+		attention{rank: attentionReady, readySince: now}))
+	// The activity time only orders ready instances.
+	assert.Zero(t, compareAttention(attention{rank: attentionRunning, readySince: now},
+		attention{rank: attentionRunning, readySince: now.Add(-time.Hour)}))
+}
+
+func TestSortByAttention(t *testing.T) {
+	now := time.Now()
+	newInstance := func(title string, status Status, lastActive time.Time) *Instance {
+		instance, err := NewInstance(InstanceOptions{Title: title, Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+		instance.Status = status
+		instance.MarkActive(lastActive)
+		return instance
+	}
+	titles := func(instances []*Instance) []string {
+		var titles []string
+		for _, instance := range instances {
+			titles = append(titles, instance.Title)
+		}
+		return titles
+	}
+
+	instances := []*Instance{
+		newInstance("paused", Paused, time.Time{}),
+		newInstance("running-1", Running, now),
+		newInstance("ready-old", Ready, now.Add(-time.Hour)),
+		newInstance("archived", Archived, time.Time{}),
+		newInstance("running-2", Running, now.Add(-time.Hour)),
+		newInstance("dead", Dead, time.Time{}),
+		newInstance("ready-new", Ready, now.Add(-time.Minute)),
+		newInstance("loading", Loading, time.Time{}),
+		newInstance("paused-2", Paused, time.Time{}),
+	}
+
+	sorted := SortByAttention(instances)
+
+	assert.Equal(t, []string{
+		"ready-new", "ready-old", "dead", "running-1", "running-2", "loading", "paused", "paused-2", "archived",
+	}, titles(sorted))
+	assert.Equal(t, "paused", instances[0].Title, "the given order is not changed")
+	assert.Empty(t, SortByAttention(nil))
+}
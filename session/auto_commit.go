@@ -0,0 +1,58 @@
+package session
+
+import (
+	"claude-squad/session/git"
+	"fmt"
+	"time"
+)
+
+// AutoCommitDue reports whether the changes in the instance's worktree are due to be committed with AutoCommit at
+// now, because interval has passed since the last time they were due, so work is saved as the program goes. The
+// first call only starts the interval. Nothing is due if the interval is not positive or the instance isn't running
+// in a worktree of its own.
+func (i *Instance) AutoCommitDue(interval time.Duration, now time.Time) bool {
+	if interval <= 0 || !i.started || i.inPlace || (i.Status != Running && i.Status != Ready) {
+		return false
+	}
+	if i.lastAutoCommit.IsZero() {
+		i.lastAutoCommit = now
+		return false
+	}
+	if now.Sub(i.lastAutoCommit) < interval {
+		return false
+	}
+	i.lastAutoCommit = now
+	return true
+}
+
+// AutoCommit commits the changes in the instance's worktree once AutoCommitDue said they are due at now. Nothing is
+// committed if there are no changes or a rebase or merge stopped at conflicts in the worktree, so conflict markers
+// are never committed. It only runs git in the worktree, so it can run in the background while the instance is
+// shown. The commits start with git.AutoCommitPrefix so they can be told apart and squashed later. Returns whether
+// a commit was made.
+func (i *Instance) AutoCommit(now time.Time) (bool, error) {
+	if operation, err := i.gitWorktree.InProgressOperation(); err != nil {
+		return false, fmt.Errorf("failed to auto-commit '%s': %w", i.Title, err)
+	} else if operation != "" {
+		return false, nil
+	}
+	message := fmt.Sprintf("%s of '%s' on %s", git.AutoCommitPrefix, i.Title, now.Format(time.RFC822))
+	committed, err := i.gitWorktree.CommitIfDirty(message)
+	if err != nil {
+		return false, fmt.Errorf("failed to auto-commit '%s': %w", i.Title, err)
+	}
+	return committed, nil
+}
+
+// InProgressOperation returns the git operation, like "rebase" or "merge", that stopped in the instance's worktree
+// and waits to be continued or aborted, or "" if there is none or the instance has no worktree.
+func (i *Instance) InProgressOperation() string {
+	if !i.started || i.inPlace || i.Paused() || i.Archived() {
+		return ""
+	}
+	operation, err := i.gitWorktree.InProgressOperation()
+	if err != nil {
+		return ""
+	}
+	return operation
+}
@@ -0,0 +1,142 @@
+package session
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"claude-squad/session/git"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoCommit(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	interval := 10 * time.Minute
+
+	newStarted := func(t *testing.T) *Instance {
+		instance, _ := newRepoTestInstance(t)
+		require.NoError(t, instance.Start(true))
+		instance.Status = Ready
+		return instance
+	}
+	log := func(t *testing.T, instance *Instance) []string {
+		out, err := exec.Command("git", "-C", instance.gitWorktree.GetWorktreePath(), "log", "--format=%s").CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.Split(strings.TrimSpace(string(out)), "\n")
+	}
+	change := func(t *testing.T, instance *Instance) {
+		path := filepath.Join(instance.gitWorktree.GetWorktreePath(), "notes.txt")
+		require.NoError(t, os.WriteFile(path, []byte("work in progress\n"), 0644))
+	}
+	// autoCommit commits like the app does: AutoCommit runs if AutoCommitDue says so.
+	autoCommit := func(instance *Instance, interval time.Duration, now time.Time) (bool, error) {
+		if !instance.AutoCommitDue(interval, now) {
+			return false, nil
+		}
+		return instance.AutoCommit(now)
+	}
+
+	t.Run("commits the changes once the interval passed", func(t *testing.T) {
+		instance := newStarted(t)
+		change(t, instance)
+
+		committed, err := autoCommit(instance, interval, start)
+		require.NoError(t, err)
+		assert.False(t, committed, "the first call only starts the interval")
+
+		committed, err = autoCommit(instance, interval, start.Add(interval-time.Second))
+		require.NoError(t, err)
+		assert.False(t, committed)
+
+		committed, err = autoCommit(instance, interval, start.Add(interval))
+		require.NoError(t, err)
+		assert.True(t, committed)
+		subjects := log(t, instance)
+		require.Len(t, subjects, 2)
+		assert.True(t, strings.HasPrefix(subjects[0], git.AutoCommitPrefix+" of 'cancelled'"), subjects[0])
+	})
+
+	t.Run("skips when there is nothing to commit", func(t *testing.T) {
+		instance := newStarted(t)
+
+		_, err := autoCommit(instance, interval, start)
+		require.NoError(t, err)
+		committed, err := autoCommit(instance, interval, start.Add(interval))
+		require.NoError(t, err)
+		assert.False(t, committed)
+		assert.Len(t, log(t, instance), 1)
+
+		// The interval starts over even though nothing was committed.
+		change(t, instance)
+		committed, err = autoCommit(instance, interval, start.Add(interval+time.Minute))
+		require.NoError(t, err)
+		assert.False(t, committed)
+		committed, err = autoCommit(instance, interval, start.Add(2*interval))
+		require.NoError(t, err)
+		assert.True(t, committed)
+	})
+
+	t.Run("skips a rebase stopped at conflicts", func(t *testing.T) {
+		instance := newStarted(t)
+		path := instance.gitWorktree.GetWorktreePath()
+		runGit := func(args ...string) {
+			out, err := exec.Command("git", append([]string{"-C", path}, args...)...).CombinedOutput()
+			require.NoError(t, err, string(out))
+		}
+		// Two branches change the same line, and rebasing one onto the other stops at the conflict.
+		base := strings.TrimSpace(func() string {
+			out, err := exec.Command("git", "-C", path, "rev-parse", "HEAD").CombinedOutput()
+			require.NoError(t, err, string(out))
+			return string(out)
+		}())
+		change(t, instance)
+		runGit("add", ".")
+		runGit("commit", "-q", "-m", "ours")
+		runGit("branch", "theirs", base)
+		runGit("checkout", "-q", "theirs")
+		require.NoError(t, os.WriteFile(filepath.Join(path, "notes.txt"), []byte("other work\n"), 0644))
+		runGit("add", ".")
+		runGit("commit", "-q", "-m", "theirs")
+		runGit("checkout", "-q", "-")
+		out, err := exec.Command("git", "-C", path, "rebase", "theirs").CombinedOutput()
+		require.Error(t, err, string(out))
+		assert.Equal(t, "rebase", instance.InProgressOperation())
+
+		_, err = autoCommit(instance, interval, start)
+		require.NoError(t, err)
+		committed, err := autoCommit(instance, interval, start.Add(interval))
+		require.NoError(t, err)
+		assert.False(t, committed)
+		assert.NotContains(t, log(t, instance)[0], git.AutoCommitPrefix)
+	})
+
+	t.Run("skips paused instances", func(t *testing.T) {
+		instance := newStarted(t)
+		change(t, instance)
+		_, err := autoCommit(instance, interval, start)
+		require.NoError(t, err)
+		instance.Status = Paused
+
+		committed, err := autoCommit(instance, interval, start.Add(interval))
+		require.NoError(t, err)
+		assert.False(t, committed)
+		assert.Len(t, log(t, instance), 1)
+	})
+
+	t.Run("is off without an interval", func(t *testing.T) {
+		instance := newStarted(t)
+		change(t, instance)
+
+		for _, now := range []time.Time{start, start.Add(time.Hour)} {
+			committed, err := autoCommit(instance, 0, now)
+			require.NoError(t, err)
+			assert.False(t, committed)
+		}
+		assert.Len(t, log(t, instance), 1)
+	})
+}
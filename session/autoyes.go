@@ -0,0 +1,80 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AutoYesMatcher decides which prompts auto-yes mode may confirm, based on user configured regexes matched
+// against the prompt text and danger keywords that must not appear in it.
+type AutoYesMatcher struct {
+	patterns []*regexp.Regexp
+	// configured is true if any patterns were given, even if none of them compiled. In that case nothing
+	// matches, so a typo never widens auto-yes to every prompt.
+	configured bool
+	// dangerKeywords are trimmed. A prompt containing one is never matched.
+	dangerKeywords []string
+}
+
+// NewAutoYesMatcher compiles the given patterns. With no patterns, every prompt matches. Prompts that contain
+// one of dangerKeywords never match. Keywords in lower case ignore case, and ones with an upper case letter, like
+// "branch -D", match only that case. Invalid patterns are skipped and reported in the returned
+// error; the returned matcher is always usable.
+func NewAutoYesMatcher(patterns []string, dangerKeywords []string) (*AutoYesMatcher, error) {
+	m := &AutoYesMatcher{configured: len(patterns) > 0}
+	for _, keyword := range dangerKeywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			m.dangerKeywords = append(m.dangerKeywords, keyword)
+		}
+	}
+
+	var invalid []string
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%q: %v", pattern, err))
+			continue
+		}
+		m.patterns = append(m.patterns, re)
+	}
+
+	if len(invalid) > 0 {
+		return m, fmt.Errorf("invalid auto-yes patterns: %s", strings.Join(invalid, "; "))
+	}
+	return m, nil
+}
+
+// Matches returns true if auto-yes may confirm the prompt. A nil matcher matches everything.
+func (m *AutoYesMatcher) Matches(prompt string) bool {
+	if m == nil {
+		return true
+	}
+	if m.DangerKeyword(prompt) != "" {
+		return false
+	}
+	if !m.configured {
+		return true
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(prompt) {
+			return true
+		}
+	}
+	return false
+}
+
+// DangerKeyword returns the first danger keyword the prompt contains, or an empty string if it contains none.
+func (m *AutoYesMatcher) DangerKeyword(prompt string) string {
+	if m == nil || len(m.dangerKeywords) == 0 {
+		return ""
+	}
+	lower := strings.ToLower(prompt)
+	for _, keyword := range m.dangerKeywords {
+		if (keyword == strings.ToLower(keyword) && strings.Contains(lower, keyword)) ||
+			strings.Contains(prompt, keyword) {
+			return keyword
+		}
+	}
+	return ""
+}
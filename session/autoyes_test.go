@@ -0,0 +1,89 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoYesMatcher(t *testing.T) {
+	readPrompt := "Do you want to proceed?\n❯ 1. Yes\n  2. No, and tell Claude what to do differently\nRead(src/main.go)"
+	testPrompt := "Bash command\n  npm test\nDo you want to proceed?\n  2. No, and tell Claude what to do differently"
+	deletePrompt := "Bash command\n  rm -rf build/\nDo you want to proceed?\n  2. No, and tell Claude what to do differently"
+	pushPrompt := "Bash command\n  git push --force origin main\n  2. No, and tell Claude what to do differently"
+
+	t.Run("matches everything without patterns", func(t *testing.T) {
+		m, err := NewAutoYesMatcher(nil, nil)
+		require.NoError(t, err)
+
+		for _, prompt := range []string{readPrompt, testPrompt, deletePrompt, pushPrompt, ""} {
+			assert.True(t, m.Matches(prompt))
+		}
+	})
+
+	t.Run("nil matcher matches everything", func(t *testing.T) {
+		var m *AutoYesMatcher
+		assert.True(t, m.Matches(deletePrompt))
+	})
+
+	t.Run("only matches configured patterns", func(t *testing.T) {
+		m, err := NewAutoYesMatcher([]string{`Read\(`, `(?m)^\s+npm (test|run lint)$`}, nil)
+		require.NoError(t, err)
+
+		assert.True(t, m.Matches(readPrompt))
+		assert.True(t, m.Matches(testPrompt))
+		assert.False(t, m.Matches(deletePrompt))
+		assert.False(t, m.Matches(pushPrompt))
+	})
+
+	t.Run("invalid patterns are reported and skipped", func(t *testing.T) {
+		m, err := NewAutoYesMatcher([]string{`Read\(`, `npm (test`}, nil)
+		assert.Error(t, err)
+
+		assert.True(t, m.Matches(readPrompt))
+		assert.False(t, m.Matches(testPrompt))
+	})
+
+	t.Run("only invalid patterns match nothing", func(t *testing.T) {
+		m, err := NewAutoYesMatcher([]string{`npm (test`}, nil)
+		assert.Error(t, err)
+
+		assert.False(t, m.Matches(readPrompt))
+	})
+
+	t.Run("danger keywords suppress matching prompts", func(t *testing.T) {
+		m, err := NewAutoYesMatcher(nil, []string{" rm -rf", "push --force", " "})
+		require.NoError(t, err)
+
+		assert.True(t, m.Matches(readPrompt))
+		assert.True(t, m.Matches(testPrompt))
+		assert.False(t, m.Matches(deletePrompt))
+		assert.False(t, m.Matches(pushPrompt))
+		assert.Equal(t, "rm -rf", m.DangerKeyword(deletePrompt))
+		assert.Equal(t, "", m.DangerKeyword(readPrompt))
+	})
+
+	t.Run("lower case danger keywords ignore case, others match the case", func(t *testing.T) {
+		m, err := NewAutoYesMatcher(nil, []string{"drop table", "branch -D"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "drop table", m.DangerKeyword("Bash command\n  psql -c 'DROP TABLE users'"))
+		assert.Equal(t, "branch -D", m.DangerKeyword("Bash command\n  git branch -D feature"))
+		assert.Equal(t, "", m.DangerKeyword("Bash command\n  git branch -d feature"),
+			"deleting a merged branch is safe")
+	})
+
+	t.Run("danger keywords override patterns", func(t *testing.T) {
+		m, err := NewAutoYesMatcher([]string{`Bash command`}, []string{"rm -rf"})
+		require.NoError(t, err)
+
+		assert.True(t, m.Matches(testPrompt))
+		assert.False(t, m.Matches(deletePrompt))
+	})
+
+	t.Run("nil matcher has no danger keywords", func(t *testing.T) {
+		var m *AutoYesMatcher
+		assert.Equal(t, "", m.DangerKeyword(deletePrompt))
+	})
+}
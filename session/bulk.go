@@ -0,0 +1,134 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BulkFailure records an instance that a bulk action failed on.
+type BulkFailure struct {
+	Title string
+	Err   error
+}
+
+// BulkResult summarizes a bulk action over several instances.
+type BulkResult struct {
+	// Action is the past tense of the action, e.g. "paused".
+	Action    string
+	Succeeded []string
+	Failed    []BulkFailure
+
+	// done are the instances the action succeeded on, which finish gives their new status.
+	done   []*Instance
+	finish func(*Instance)
+}
+
+// Finish marks the instances the action succeeded on paused or resumed. PauseAll and ResumeAll leave that to it so
+// they can run in the background: it must be called on the goroutine that reads the instances.
+func (r BulkResult) Finish() {
+	for _, instance := range r.done {
+		r.finish(instance)
+	}
+}
+
+// Summary returns a human readable summary of the result, listing every failure.
+func (r BulkResult) Summary() string {
+	if len(r.Succeeded) == 0 && len(r.Failed) == 0 {
+		return fmt.Sprintf("no instances %s", r.Action)
+	}
+
+	summary := fmt.Sprintf("%s %d instance(s)", r.Action, len(r.Succeeded))
+	if len(r.Failed) == 0 {
+		return summary
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s, %d failed:", summary, len(r.Failed)))
+	for _, failure := range r.Failed {
+		b.WriteString(fmt.Sprintf("\n  - %s: %v", failure.Title, failure.Err))
+	}
+	return b.String()
+}
+
+// Pausable returns the instances PauseAll can pause: the started instances that aren't paused or archived. In-place
+// instances can't be paused and are left out.
+func Pausable(instances []*Instance) []*Instance {
+	var pausable []*Instance
+	for _, instance := range instances {
+		if instance.CanPause() == nil {
+			pausable = append(pausable, instance)
+		}
+	}
+	return pausable
+}
+
+// PauseAll pauses the instances picked with Pausable, one at a time since pausing commits to and removes git
+// worktrees. Only their worktrees and tmux sessions are touched, and the instances keep their status until Finish is
+// called on the result.
+func PauseAll(instances []*Instance) BulkResult {
+	result := BulkResult{Action: "paused", finish: (*Instance).MarkPaused}
+	for _, instance := range instances {
+		if err := instance.PauseResources(); err != nil {
+			result.Failed = append(result.Failed, BulkFailure{Title: instance.Title, Err: err})
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, instance.Title)
+		result.done = append(result.done, instance)
+	}
+	return result
+}
+
+// PausableOnQuit returns the instances that quitting with pause-all pauses: the started instances that aren't
+// paused or archived, leaving out the in-place ones and the ones whose branch is checked out in their repository.
+func PausableOnQuit(instances []*Instance) []*Instance {
+	var pausable []*Instance
+	for _, instance := range instances {
+		if !instance.Started() || instance.Paused() || instance.Archived() || instance.InPlace() ||
+			instance.BranchCheckedOut() {
+			continue
+		}
+		pausable = append(pausable, instance)
+	}
+	return pausable
+}
+
+// WithChanges returns the running instances whose last computed diff has changes. It only looks at the cached
+// diff stats, so it doesn't run git.
+func WithChanges(instances []*Instance) []*Instance {
+	var changed []*Instance
+	for _, instance := range instances {
+		if !instance.Started() || instance.Paused() || instance.Archived() {
+			continue
+		}
+		if stats := instance.GetDiffStats(); stats != nil && stats.Error == nil && !stats.IsEmpty() {
+			changed = append(changed, instance)
+		}
+	}
+	return changed
+}
+
+// Resumable returns the paused instances, which ResumeAll can resume.
+func Resumable(instances []*Instance) []*Instance {
+	var resumable []*Instance
+	for _, instance := range instances {
+		if instance.CanResume() == nil {
+			resumable = append(resumable, instance)
+		}
+	}
+	return resumable
+}
+
+// ResumeAll resumes the instances picked with Resumable, one at a time. Only their worktrees and tmux sessions are
+// touched, and the instances keep their status until Finish is called on the result.
+func ResumeAll(instances []*Instance) BulkResult {
+	result := BulkResult{Action: "resumed", finish: (*Instance).MarkResumed}
+	for _, instance := range instances {
+		if err := instance.ResumeResources(); err != nil {
+			result.Failed = append(result.Failed, BulkFailure{Title: instance.Title, Err: err})
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, instance.Title)
+		result.done = append(result.done, instance)
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+package session
+
+import (
+	"claude-squad/session/git"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkResultSummary(t *testing.T) {
+	t.Run("nothing to do", func(t *testing.T) {
+		assert.Equal(t, "no instances paused", BulkResult{Action: "paused"}.Summary())
+	})
+
+	t.Run("all succeeded", func(t *testing.T) {
+		result := BulkResult{Action: "resumed", Succeeded: []string{"feature", "bugfix"}}
+		assert.Equal(t, "resumed 2 instance(s)", result.Summary())
+	})
+
+	t.Run("lists failures", func(t *testing.T) {
+		result := BulkResult{
+			Action:    "paused",
+			Succeeded: []string{"feature"},
+			Failed:    []BulkFailure{{Title: "bugfix", Err: fmt.Errorf("worktree is locked")}},
+		}
+		assert.Equal(t, "paused 1 instance(s), 1 failed:\n  - bugfix: worktree is locked", result.Summary())
+	})
+}
+
+func TestBulkActionsSkipInactiveInstances(t *testing.T) {
+	instance, err := NewInstance(InstanceOptions{Title: "not-started", Path: t.TempDir(), Program: "claude"})
+	require.NoError(t, err)
+	paused, err := NewInstance(InstanceOptions{Title: "paused", Path: t.TempDir(), Program: "claude"})
+	require.NoError(t, err)
+	paused.Status = Paused
+
+	instances := []*Instance{instance, paused}
+
+	t.Run("pause all skips instances that aren't running", func(t *testing.T) {
+		assert.Empty(t, Pausable(instances))
+	})
+
+	t.Run("resume all skips instances that haven't started", func(t *testing.T) {
+		assert.Empty(t, Resumable(instances))
+	})
+}
+
+func TestBulkResultFinish(t *testing.T) {
+	running := &Instance{Title: "running", Status: Running, started: true}
+	paused := &Instance{Title: "paused", Status: Paused, started: true}
+
+	BulkResult{Action: "paused", finish: (*Instance).MarkPaused, done: []*Instance{running}}.Finish()
+	assert.Equal(t, Paused, running.Status)
+	BulkResult{Action: "resumed", finish: (*Instance).MarkResumed, done: []*Instance{paused}}.Finish()
+	assert.Equal(t, Running, paused.Status)
+}
+
+func TestPausableOnQuit(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"checkout", "-q", "-b", "user/checked-out"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	newInstance := func(title string, status Status, branch string) *Instance {
+		return &Instance{
+			Title:       title,
+			Status:      status,
+			started:     true,
+			gitWorktree: git.NewGitWorktreeFromStorage(repo, "", title, branch, "", "main"),
+		}
+	}
+	running := newInstance("running", Running, "user/running")
+	ready := newInstance("ready", Ready, "user/ready")
+	checkedOut := newInstance("checked-out", Ready, "user/checked-out")
+	paused := newInstance("paused", Paused, "user/paused")
+	archived := newInstance("archived", Archived, "user/archived")
+	unstarted := &Instance{Title: "unstarted", Status: Ready}
+
+	instances := []*Instance{running, ready, checkedOut, paused, archived, unstarted}
+	assert.Equal(t, []*Instance{running, ready}, PausableOnQuit(instances))
+}
+
+func TestWithChanges(t *testing.T) {
+	newInstance := func(title string, status Status, stats *git.DiffStats) *Instance {
+		return &Instance{Title: title, Status: status, started: true, diffStats: stats}
+	}
+	changed := newInstance("changed", Running, &git.DiffStats{Added: 3, Removed: 1})
+	ready := newInstance("ready", Ready, &git.DiffStats{Content: "diff --git a/new.txt b/new.txt"})
+	clean := newInstance("clean", Running, &git.DiffStats{})
+	notComputed := newInstance("not-computed", Running, nil)
+	failed := newInstance("failed", Running, &git.DiffStats{Added: 1, Error: fmt.Errorf("no base commit")})
+	paused := newInstance("paused", Paused, &git.DiffStats{Added: 1})
+	archived := newInstance("archived", Archived, &git.DiffStats{Added: 1})
+	unstarted := &Instance{Title: "unstarted", Status: Ready, diffStats: &git.DiffStats{Added: 1}}
+
+	instances := []*Instance{changed, ready, clean, notComputed, failed, paused, archived, unstarted}
+	assert.Equal(t, []*Instance{changed, ready}, WithChanges(instances))
+	assert.Empty(t, WithChanges([]*Instance{clean, paused}))
+}
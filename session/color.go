@@ -0,0 +1,95 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// ColorPalette maps the color names that can be given to an instance to the colors they are shown in.
+var ColorPalette = map[string]string{
+	"red":    "#e06c75",
+	"orange": "#d19a66",
+	"yellow": "#e5c07b",
+	"green":  "#98c379",
+	"teal":   "#56b6c2",
+	"blue":   "#61afef",
+	"purple": "#c678dd",
+	"pink":   "#f5a3c7",
+	"gray":   "#8b929e",
+}
+
+// ColorNames are the names in ColorPalette, in the order they are listed to the user.
+var ColorNames = []string{"red", "orange", "yellow", "green", "teal", "blue", "purple", "pink", "gray"}
+
+// hexColorPattern matches colors like #f80 and #ff8800.
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// maxIconWidth is the number of cells an instance's icon can take, enough for an emoji.
+const maxIconWidth = 2
+
+// ColorHex returns the hex color of color, a name in ColorPalette or a hex color like #ff8800, or "" if it is
+// neither. A color that isn't valid, e.g. one edited in the state file, shows no color.
+func ColorHex(color string) string {
+	if hex, ok := ColorPalette[strings.ToLower(color)]; ok {
+		return hex
+	}
+	if hexColorPattern.MatchString(color) {
+		return color
+	}
+	return ""
+}
+
+// ParseColorIcon parses the color and icon of an instance from input like "blue 🚀", "#ff8800" or "🐛". A word
+// starting with # is a hex color and a word in ColorPalette a named color. Any other word is the icon, which must
+// be a single character or emoji. Empty input clears both.
+func ParseColorIcon(input string) (color, icon string, err error) {
+	for _, word := range strings.Fields(input) {
+		switch {
+		case strings.HasPrefix(word, "#"):
+			if !hexColorPattern.MatchString(word) {
+				return "", "", fmt.Errorf("%q is not a hex color like #ff8800", word)
+			}
+		case ColorPalette[strings.ToLower(word)] != "":
+			word = strings.ToLower(word)
+		case isWord(word):
+			return "", "", fmt.Errorf("unknown color %q (expected %s, or a hex color like #ff8800)", word,
+				strings.Join(ColorNames, ", "))
+		default:
+			if icon != "" {
+				return "", "", fmt.Errorf("only one icon can be set, got %q and %q", icon, word)
+			}
+			if runewidth.StringWidth(word) > maxIconWidth {
+				return "", "", fmt.Errorf("%q is too wide for an icon, use a single character or emoji", word)
+			}
+			icon = word
+			continue
+		}
+		if color != "" {
+			return "", "", fmt.Errorf("only one color can be set, got %q and %q", color, word)
+		}
+		color = word
+	}
+	return color, icon, nil
+}
+
+// isWord returns true if s is at least two ASCII letters, which is taken as a misspelled color name rather than an
+// icon.
+func isWord(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// ColorIconInput returns the color and icon of the instance as ParseColorIcon reads them, to edit them.
+func (i *Instance) ColorIconInput() string {
+	return strings.TrimSpace(i.Color + " " + i.Icon)
+}
@@ -0,0 +1,50 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColorIcon(t *testing.T) {
+	tests := []struct {
+		input string
+		color string
+		icon  string
+		err   string
+	}{
+		{input: "", color: "", icon: ""},
+		{input: "blue", color: "blue"},
+		{input: " Blue  🚀 ", color: "blue", icon: "🚀"},
+		{input: "🐛 #FF8800", color: "#FF8800", icon: "🐛"},
+		{input: "#f80", color: "#f80"},
+		{input: "★", icon: "★"},
+		{input: "A", icon: "A"},
+		{input: "#ff88", err: `"#ff88" is not a hex color`},
+		{input: "blu", err: `unknown color "blu" (expected red, orange`},
+		{input: "red blue", err: `only one color can be set, got "red" and "blue"`},
+		{input: "🚀 🐛", err: `only one icon can be set`},
+		{input: "🚀🐛", err: `"🚀🐛" is too wide for an icon`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			color, icon, err := ParseColorIcon(tt.input)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.color, color)
+			assert.Equal(t, tt.icon, icon)
+		})
+	}
+}
+
+func TestColorHex(t *testing.T) {
+	assert.Equal(t, ColorPalette["green"], ColorHex("green"))
+	assert.Equal(t, ColorPalette["green"], ColorHex("Green"))
+	assert.Equal(t, "#ff8800", ColorHex("#ff8800"))
+	assert.Empty(t, ColorHex(""))
+	assert.Empty(t, ColorHex("chartreuse"), "an unknown color shows no color")
+	assert.Len(t, ColorNames, len(ColorPalette))
+}
@@ -0,0 +1,135 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkpointLogLimit is how many of the most recent checkpoint commits CheckpointCommits returns.
+const checkpointLogLimit = 50
+
+// CheckpointCommit is a commit that saved the work of an instance along the way: an auto-commit on its branch or
+// the commit of one of its checkpoint branches.
+type CheckpointCommit struct {
+	Hash    string
+	Time    time.Time
+	Subject string
+	// Files, Added and Removed are the number of files the commit changed and of lines it added and removed.
+	Files   int
+	Added   int
+	Removed int
+}
+
+// ShortHash returns the abbreviated hash of the commit.
+func (c CheckpointCommit) ShortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+// checkpointLogArgs returns the git log arguments that list the recent auto-commits on the branch and the commits of
+// its checkpoint branches, newest first, in the format parseCheckpointLog reads.
+func (g *GitWorktree) checkpointLogArgs() []string {
+	return []string{"log", "HEAD", "--branches=" + g.branchName + "-checkpoint-*",
+		"--fixed-strings", "--grep=" + AutoCommitPrefix, "--grep=" + CheckpointPrefix,
+		"-n", strconv.Itoa(checkpointLogLimit), "--format=%x1e%H%x1f%ct%x1f%s", "--shortstat"}
+}
+
+// CheckpointCommits returns the recent auto-commits on the instance branch and the commits of its checkpoint
+// branches, newest first.
+func (g *GitWorktree) CheckpointCommits() ([]CheckpointCommit, error) {
+	if g.inPlace {
+		return nil, errInPlace("list the checkpoints of")
+	}
+	output, err := g.runGitCommand(g.worktreePath, g.checkpointLogArgs()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the checkpoints: %w", err)
+	}
+	return parseCheckpointLog(output)
+}
+
+// parseCheckpointLog parses the output of the git log command of checkpointLogArgs. Each commit starts with a
+// record separator and its hash, commit time and subject separated by unit separators, followed by its shortstat
+// line, like " 2 files changed, 3 insertions(+), 1 deletion(-)", unless it changed nothing. Commits whose subject
+// only mentions a checkpoint prefix further in are left out.
+func parseCheckpointLog(output string) ([]CheckpointCommit, error) {
+	var commits []CheckpointCommit
+	for _, record := range strings.Split(output, "\x1e") {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+		header, stat, _ := strings.Cut(record, "\n")
+		fields := strings.SplitN(header, "\x1f", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected git log line %q", header)
+		}
+		seconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected commit time %q: %w", fields[1], err)
+		}
+		commit := CheckpointCommit{Hash: fields[0], Time: time.Unix(seconds, 0), Subject: fields[2]}
+		if !strings.HasPrefix(commit.Subject, AutoCommitPrefix) && !strings.HasPrefix(commit.Subject, CheckpointPrefix) {
+			continue
+		}
+		commit.Files, commit.Added, commit.Removed = parseShortstat(stat)
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// parseShortstat parses a git shortstat line like " 2 files changed, 3 insertions(+), 1 deletion(-)". Counts that
+// are missing are zero.
+func parseShortstat(line string) (files, added, removed int) {
+	for _, part := range strings.Split(strings.TrimSpace(line), ",") {
+		count, kind, ok := strings.Cut(strings.TrimSpace(part), " ")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(kind, "file"):
+			files = n
+		case strings.HasPrefix(kind, "insertion"):
+			added = n
+		case strings.HasPrefix(kind, "deletion"):
+			removed = n
+		}
+	}
+	return files, added, removed
+}
+
+// CommitsAfter returns how many commits on the instance branch come after commit. RestoreCheckpoint drops them from
+// the branch when it restores commit.
+func (g *GitWorktree) CommitsAfter(commit string) (int, error) {
+	output, err := g.runGitCommand(g.worktreePath, "rev-list", "--count", commit+"..HEAD")
+	if err != nil {
+		return 0, fmt.Errorf("failed to count the commits after %s: %w", commit, err)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected commit count %q: %w", strings.TrimSpace(output), err)
+	}
+	return count, nil
+}
+
+// RestoreCheckpoint resets the instance branch and the files in the worktree to commit, e.g. one returned by
+// CheckpointCommits. Uncommitted changes to tracked files are lost, and untracked files are left as they are.
+func (g *GitWorktree) RestoreCheckpoint(commit string) error {
+	if g.inPlace {
+		return errInPlace("restore a checkpoint of")
+	}
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+
+	if _, err := g.runGitCommand(g.worktreePath, "reset", "--hard", commit); err != nil {
+		return fmt.Errorf("failed to restore the checkpoint %s: %w", commit, err)
+	}
+	return nil
+}
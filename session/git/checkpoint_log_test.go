@@ -0,0 +1,116 @@
+package git
+
+import (
+	"claude-squad/cmd/cmd_test"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCheckpointLog(t *testing.T) {
+	output := "\x1eaaaaaaaaaa\x1f1735830245\x1f[claudesquad] auto-commit of 'fix' on 02 Jan 25 15:04 UTC\n\n" +
+		" 2 files changed, 3 insertions(+), 1 deletion(-)\n" +
+		"\x1ebbbbbbbbbb\x1f1735826645\x1f[claudesquad] checkpoint of 'fix' on 02 Jan 25 14:04 UTC\n\n" +
+		" 1 file changed, 4 deletions(-)\n" +
+		"\x1ecccccccccc\x1f1735823045\x1fRevert \"[claudesquad] auto-commit of 'fix'\"\n\n" +
+		" 1 file changed, 1 insertion(+)\n" +
+		"\x1edddddddddd\x1f1735819445\x1f[claudesquad] auto-commit of 'fix' on 02 Jan 25 12:04 UTC\n"
+
+	commits, err := parseCheckpointLog(output)
+	require.NoError(t, err)
+	assert.Equal(t, []CheckpointCommit{
+		{
+			Hash:    "aaaaaaaaaa",
+			Time:    time.Unix(1735830245, 0),
+			Subject: "[claudesquad] auto-commit of 'fix' on 02 Jan 25 15:04 UTC",
+			Files:   2,
+			Added:   3,
+			Removed: 1,
+		},
+		{
+			Hash:    "bbbbbbbbbb",
+			Time:    time.Unix(1735826645, 0),
+			Subject: "[claudesquad] checkpoint of 'fix' on 02 Jan 25 14:04 UTC",
+			Files:   1,
+			Removed: 4,
+		},
+		{
+			Hash:    "dddddddddd",
+			Time:    time.Unix(1735819445, 0),
+			Subject: "[claudesquad] auto-commit of 'fix' on 02 Jan 25 12:04 UTC",
+		},
+	}, commits)
+	assert.Equal(t, "aaaaaaa", commits[0].ShortHash())
+
+	t.Run("no commits", func(t *testing.T) {
+		commits, err := parseCheckpointLog("")
+		require.NoError(t, err)
+		assert.Empty(t, commits)
+	})
+
+	t.Run("unexpected output", func(t *testing.T) {
+		_, err := parseCheckpointLog("\x1eaaaaaaaaaa\n")
+		assert.ErrorContains(t, err, "unexpected git log line")
+	})
+}
+
+func TestCheckpointLogCommands(t *testing.T) {
+	record := func() (*GitWorktree, *[]string) {
+		var ran []string
+		worktree := NewGitWorktreeFromStorage("/repo", "/worktree", "fix", "me/fix", "", "main")
+		worktree.SetExecutor(cmd_test.MockCmdExec{
+			CombinedOutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+				ran = append(ran, strings.Join(cmd.Args[1:], " "))
+				return nil, nil
+			},
+		})
+		return worktree, &ran
+	}
+
+	t.Run("lists the auto-commits and checkpoint branches", func(t *testing.T) {
+		worktree, ran := record()
+
+		_, err := worktree.CheckpointCommits()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"-C /worktree log HEAD --branches=me/fix-checkpoint-* --fixed-strings " +
+			"--grep=[claudesquad] auto-commit --grep=[claudesquad] checkpoint -n 50 " +
+			"--format=%x1e%H%x1f%ct%x1f%s --shortstat"}, *ran)
+	})
+
+	t.Run("resets the worktree to the checkpoint", func(t *testing.T) {
+		worktree, ran := record()
+
+		require.NoError(t, worktree.RestoreCheckpoint("aaaaaaaaaa"))
+		assert.Equal(t, []string{"-C /worktree reset --hard aaaaaaaaaa"}, *ran)
+	})
+
+	t.Run("counts the commits dropped by a restore", func(t *testing.T) {
+		worktree := NewGitWorktreeFromStorage("/repo", "/worktree", "fix", "me/fix", "", "main")
+		var ran []string
+		worktree.SetExecutor(cmd_test.MockCmdExec{
+			CombinedOutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+				ran = append(ran, strings.Join(cmd.Args[1:], " "))
+				return []byte("3\n"), nil
+			},
+		})
+
+		count, err := worktree.CommitsAfter("aaaaaaaaaa")
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, []string{"-C /worktree rev-list --count aaaaaaaaaa..HEAD"}, ran)
+	})
+
+	t.Run("refuses in-place sessions", func(t *testing.T) {
+		worktree, ran := record()
+		worktree.inPlace = true
+
+		_, err := worktree.CheckpointCommits()
+		assert.ErrorContains(t, err, "in-place")
+		assert.ErrorContains(t, worktree.RestoreCheckpoint("aaaaaaaaaa"), "in-place")
+		assert.Empty(t, *ran)
+	})
+}
@@ -0,0 +1,55 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Conflicts returns the files that would conflict if the instance branch were merged into its base branch, or
+// nil if it merges cleanly. Only committed work is checked, and nothing is checked if the base branch isn't
+// known. The merge is done with `git merge-tree`, which doesn't touch the worktree. It needs git 2.38, so with an
+// older git nothing is checked. It doesn't wait for another operation on the worktree, like a push, to finish, and
+// returns ErrBusy while one is running.
+func (g *GitWorktree) Conflicts() ([]string, error) {
+	if !g.opMu.TryLock() {
+		return nil, ErrBusy
+	}
+	defer g.opMu.Unlock()
+
+	if g.baseBranch == "" || !supportsMergeTree(g.cmdExec) {
+		return nil, nil
+	}
+	cmd := exec.Command("git", "-C", g.worktreePath, "merge-tree", "--write-tree", "--name-only", "--no-messages",
+		"HEAD", g.baseBranch)
+	output, err := g.cmdExec.Output(cmd)
+	if err != nil {
+		// merge-tree exits with 1 if the merge has conflicts and with other codes if it couldn't merge at all.
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("failed to check for conflicts with %s: %w", g.baseBranch, err)
+		}
+	}
+	return parseMergeTree(string(output)), nil
+}
+
+// parseMergeTree parses the output of `git merge-tree --write-tree --name-only --no-messages`: the id of the
+// merged tree, followed by one line per conflicted file if there are conflicts. A file is listed once for each of
+// its conflicting stages in some versions of git, so duplicates are dropped.
+func parseMergeTree(output string) []string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	var files []string
+	seen := make(map[string]bool)
+	for _, line := range lines[1:] {
+		if line == "" {
+			// The informational messages start after a blank line.
+			break
+		}
+		if !seen[line] {
+			seen[line] = true
+			files = append(files, line)
+		}
+	}
+	return files
+}
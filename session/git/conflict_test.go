@@ -0,0 +1,73 @@
+package git
+
+import (
+	"claude-squad/cmd"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMergeTree(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{"clean merge", "8073f2026d6082bf5632db1e3b300f3a42a47780\n", nil},
+		{"no output", "", nil},
+		{
+			"conflicts",
+			"45217e4167d12dcc9dec9c1add18184b2dae0498\nmain.go\nui/list.go\n",
+			[]string{"main.go", "ui/list.go"},
+		},
+		{
+			"duplicate stages",
+			"45217e4167d12dcc9dec9c1add18184b2dae0498\nmain.go\nmain.go\nREADME.md\n",
+			[]string{"main.go", "README.md"},
+		},
+		{
+			"messages after the files",
+			"45217e4167d12dcc9dec9c1add18184b2dae0498\nmain.go\n\nAuto-merging main.go\nCONFLICT (content): Merge conflict in main.go\n",
+			[]string{"main.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseMergeTree(tt.output))
+		})
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	setupGitEnv(t)
+	repo := newTestRepo(t, "main.txt")
+	worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), repo, "conflicts")
+	require.NoError(t, err)
+	require.NoError(t, worktree.Setup())
+	t.Cleanup(func() { _ = worktree.Cleanup() })
+	path := worktree.GetWorktreePath()
+
+	commit := func(dir, file, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+		runGit(t, dir, "add", ".")
+		runGit(t, dir, "commit", "-q", "-m", "change "+file)
+	}
+
+	commit(path, "main.txt", "from the branch\n")
+	conflicts, err := worktree.Conflicts()
+	require.NoError(t, err)
+	assert.Empty(t, conflicts, "the base branch hasn't moved")
+
+	commit(repo, "other.txt", "unrelated\n")
+	conflicts, err = worktree.Conflicts()
+	require.NoError(t, err)
+	assert.Empty(t, conflicts, "changes to other files merge cleanly")
+
+	commit(repo, "main.txt", "from main\n")
+	conflicts, err = worktree.Conflicts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.txt"}, conflicts)
+}
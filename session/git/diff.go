@@ -1,9 +1,37 @@
 package git
 
 import (
+	"claude-squad/log"
+	"fmt"
+	"os"
+	"strconv"
 	"strings"
 )
 
+// File statuses reported in FileStat.Status.
+const (
+	FileModified = "modified"
+	FileAdded    = "added"
+	FileDeleted  = "deleted"
+	FileRenamed  = "renamed"
+)
+
+// FileStat holds the change statistics for a single file in a diff
+type FileStat struct {
+	// Path is the path of the file after the change
+	Path string
+	// OldPath is the path of the file before a rename. Empty if the file was not renamed.
+	OldPath string
+	// Added is the number of added lines
+	Added int
+	// Removed is the number of removed lines
+	Removed int
+	// Binary is true if git reported the file as binary, in which case Added and Removed are 0
+	Binary bool
+	// Status is one of FileModified, FileAdded, FileDeleted or FileRenamed
+	Status string
+}
+
 // DiffStats holds statistics about the changes in a diff
 type DiffStats struct {
 	// Content is the full diff content
@@ -12,6 +40,8 @@ type DiffStats struct {
 	Added int
 	// Removed is the number of removed lines
 	Removed int
+	// Files holds the per-file statistics, in the order git lists them
+	Files []FileStat
 	// Error holds any error that occurred during diff computation
 	// This allows propagating setup errors (like missing base commit) without breaking the flow
 	Error error
@@ -21,18 +51,71 @@ func (d *DiffStats) IsEmpty() bool {
 	return d.Added == 0 && d.Removed == 0 && d.Content == ""
 }
 
+// DiffMode selects what a diff compares the worktree against.
+type DiffMode int
+
+const (
+	// DiffBranch compares the worktree, including committed work, against where the instance branch forked
+	// from its base branch.
+	DiffBranch DiffMode = iota
+	// DiffUncommitted only shows the changes that haven't been committed yet.
+	DiffUncommitted
+)
+
+// DiffOptions control how a diff is computed. The zero value is git's default diff of the whole branch.
+type DiffOptions struct {
+	// Mode selects what the worktree is compared against.
+	Mode DiffMode
+	// IgnoreWhitespace hides changes that only touch whitespace (git diff -w).
+	IgnoreWhitespace bool
+	// ContextLines is the number of unchanged lines shown around each change. Zero uses git's default of three.
+	ContextLines int
+}
+
+// args returns the git diff flags for the options. withContext is false for output without hunks, like numstat.
+func (o DiffOptions) args(withContext bool) []string {
+	var args []string
+	if o.IgnoreWhitespace {
+		args = append(args, "--ignore-all-space")
+	}
+	if withContext && o.ContextLines > 0 {
+		args = append(args, fmt.Sprintf("--unified=%d", o.ContextLines))
+	}
+	return args
+}
+
 // Diff returns the git diff between the worktree and the base branch along with statistics
-func (g *GitWorktree) Diff() *DiffStats {
+func (g *GitWorktree) Diff(opts DiffOptions) *DiffStats {
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+	return g.diff(opts)
+}
+
+// TryDiff is Diff that doesn't wait for another operation on the worktree, like a push, to finish. If one is
+// running, the returned stats have ErrBusy as their Error.
+func (g *GitWorktree) TryDiff(opts DiffOptions) *DiffStats {
+	if !g.opMu.TryLock() {
+		return &DiffStats{Error: ErrBusy}
+	}
+	defer g.opMu.Unlock()
+	return g.diff(opts)
+}
+
+// diff is Diff without taking the lock.
+func (g *GitWorktree) diff(opts DiffOptions) *DiffStats {
 	stats := &DiffStats{}
 
-	// -N stages untracked files (intent to add), including them in the diff
-	_, err := g.runGitCommand(g.worktreePath, "add", "-N", ".")
-	if err != nil {
-		stats.Error = err
-		return stats
+	// -N stages untracked files (intent to add), including them in the diff. The index of an in-place instance is
+	// the user's own, so it is left alone and new files only show once they are added.
+	if !g.inPlace {
+		if _, err := g.runGitCommand(g.worktreePath, "add", "-N", "."); err != nil {
+			stats.Error = err
+			return stats
+		}
 	}
 
-	content, err := g.runGitCommand(g.worktreePath, "--no-pager", "diff", g.GetBaseCommitSHA())
+	args := append([]string{"--no-pager", "diff"}, opts.args(true)...)
+	content, err := g.runGitCommand(g.worktreePath, append(args, g.diffBase(opts.Mode))...)
 	if err != nil {
 		stats.Error = err
 		return stats
@@ -46,6 +129,179 @@ func (g *GitWorktree) Diff() *DiffStats {
 		}
 	}
 	stats.Content = content
+	stats.Files = g.cachedFileStats(content, opts)
 
 	return stats
 }
+
+// Patch returns the diff of the worktree in the given mode as a patch that git apply accepts: with git's default
+// formatting, whatever options the diff is shown with, and with binary files included. Without a worktree, e.g.
+// while the instance is paused, the commits of the branch are diffed in the repository instead.
+func (g *GitWorktree) Patch(mode DiffMode) (string, error) {
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+
+	if _, err := os.Stat(g.worktreePath); err != nil {
+		if mode == DiffUncommitted {
+			return "", nil
+		}
+		return g.runGitCommand(g.repoPath, "--no-pager", "diff", "--binary", g.branchBase(), g.branchName)
+	}
+	if !g.inPlace {
+		if _, err := g.runGitCommand(g.worktreePath, "add", "-N", "."); err != nil {
+			return "", err
+		}
+	}
+	return g.runGitCommand(g.worktreePath, "--no-pager", "diff", "--binary", g.diffBase(mode))
+}
+
+// cachedFileStats returns the per-file stats for the given diff content, only asking git for them again
+// when the content changed since the last call.
+func (g *GitWorktree) cachedFileStats(content string, opts DiffOptions) []FileStat {
+	if g.lastFileStats != nil && content == g.lastDiffContent {
+		return g.lastFileStats
+	}
+
+	files, err := g.diffFileStats(opts)
+	if err != nil {
+		log.WarningLog.Printf("failed to get per-file diff stats: %v", err)
+		return nil
+	}
+	g.lastDiffContent = content
+	g.lastFileStats = files
+	return files
+}
+
+// DiffFileStats returns the per-file statistics of the diff between the worktree and the base branch.
+func (g *GitWorktree) DiffFileStats(opts DiffOptions) ([]FileStat, error) {
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+	return g.diffFileStats(opts)
+}
+
+// diffFileStats is DiffFileStats without taking the lock.
+func (g *GitWorktree) diffFileStats(opts DiffOptions) ([]FileStat, error) {
+	args := append([]string{"--no-pager", "diff", "--numstat", "--summary"}, opts.args(false)...)
+	output, err := g.runGitCommand(g.worktreePath, append(args, g.diffBase(opts.Mode))...)
+	if err != nil {
+		return nil, err
+	}
+	return parseNumstat(output), nil
+}
+
+// diffBase returns the commit the worktree is diffed against in the given mode. For DiffBranch that is the
+// merge-base of the instance branch and its base branch, so work merged into the base branch since doesn't
+// show up. If the base branch isn't known or is gone, the commit the worktree was created from is used. The
+// merge-base is cached until HEAD or the base branch moves, since finding it can be slow in large repositories.
+func (g *GitWorktree) diffBase(mode DiffMode) string {
+	if mode == DiffUncommitted || g.inPlace {
+		return "HEAD"
+	}
+	if g.baseBranch == "" {
+		return g.GetBaseCommitSHA()
+	}
+	output, err := g.runGitCommand(g.worktreePath, "rev-parse", "HEAD", g.baseBranch)
+	if err != nil {
+		log.WarningLog.Printf("could not resolve %s, using the base commit: %v", g.baseBranch, err)
+		return g.GetBaseCommitSHA()
+	}
+	key := strings.Join(strings.Fields(output), " ")
+	if key != "" && key == g.mergeBaseKey {
+		return g.mergeBase
+	}
+	output, err = g.runGitCommand(g.worktreePath, "merge-base", "HEAD", g.baseBranch)
+	if err != nil {
+		log.WarningLog.Printf("could not find merge-base with %s, using the base commit: %v", g.baseBranch, err)
+		return g.GetBaseCommitSHA()
+	}
+	g.mergeBaseKey, g.mergeBase = key, strings.TrimSpace(output)
+	return g.mergeBase
+}
+
+// branchBase is diffBase in DiffBranch mode for the branch itself, found in the repository rather than the worktree.
+func (g *GitWorktree) branchBase() string {
+	if g.baseBranch == "" {
+		return g.GetBaseCommitSHA()
+	}
+	output, err := g.runGitCommand(g.repoPath, "merge-base", g.branchName, g.baseBranch)
+	if err != nil {
+		log.WarningLog.Printf("could not find merge-base with %s, using the base commit: %v", g.baseBranch, err)
+		return g.GetBaseCommitSHA()
+	}
+	return strings.TrimSpace(output)
+}
+
+// parseNumstat parses the output of `git diff --numstat --summary`. Numstat lines look like
+// "<added>\t<removed>\t<path>", where binary files use "-" for both counts and renames use
+// "old => new" or "dir/{old => new}/file" as the path. The summary lines that follow mark
+// created and deleted files.
+func parseNumstat(output string) []FileStat {
+	files := make([]FileStat, 0)
+	byPath := make(map[string]int)
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) == 3 {
+			file := FileStat{Status: FileModified}
+			if fields[0] == "-" && fields[1] == "-" {
+				file.Binary = true
+			} else {
+				file.Added, _ = strconv.Atoi(fields[0])
+				file.Removed, _ = strconv.Atoi(fields[1])
+			}
+			file.OldPath, file.Path = splitRenamePath(fields[2])
+			if file.OldPath != "" {
+				file.Status = FileRenamed
+			}
+			byPath[file.Path] = len(files)
+			files = append(files, file)
+			continue
+		}
+
+		summary := strings.TrimSpace(line)
+		var status string
+		switch {
+		case strings.HasPrefix(summary, "create mode "):
+			status = FileAdded
+		case strings.HasPrefix(summary, "delete mode "):
+			status = FileDeleted
+		default:
+			continue
+		}
+		// The summary looks like "create mode 100644 path/to/file".
+		parts := strings.SplitN(summary, " ", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		if i, ok := byPath[parts[3]]; ok {
+			files[i].Status = status
+		}
+	}
+
+	return files
+}
+
+// splitRenamePath splits a numstat path into the old and new path. The old path is empty if the
+// path is not a rename.
+func splitRenamePath(path string) (oldPath, newPath string) {
+	if !strings.Contains(path, " => ") {
+		return "", path
+	}
+
+	// Renames within a directory look like "dir/{old => new}/file".
+	if start := strings.Index(path, "{"); start != -1 {
+		if end := strings.Index(path[start:], "}"); end != -1 {
+			end += start
+			prefix, suffix := path[:start], path[end+1:]
+			names := strings.SplitN(path[start+1:end], " => ", 2)
+			if len(names) == 2 {
+				oldPath = strings.ReplaceAll(prefix+names[0]+suffix, "//", "/")
+				newPath = strings.ReplaceAll(prefix+names[1]+suffix, "//", "/")
+				return oldPath, newPath
+			}
+		}
+	}
+
+	names := strings.SplitN(path, " => ", 2)
+	return names[0], names[1]
+}
@@ -0,0 +1,319 @@
+package git
+
+import (
+	"claude-squad/cmd"
+	"claude-squad/cmd/cmd_test"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNumstat(t *testing.T) {
+	t.Run("modified, added and deleted files", func(t *testing.T) {
+		output := "3\t1\tmain.go\n" +
+			"10\t0\tnew.go\n" +
+			"0\t7\told.go\n" +
+			" create mode 100644 new.go\n" +
+			" delete mode 100644 old.go\n"
+
+		files := parseNumstat(output)
+
+		assert.Equal(t, []FileStat{
+			{Path: "main.go", Added: 3, Removed: 1, Status: FileModified},
+			{Path: "new.go", Added: 10, Status: FileAdded},
+			{Path: "old.go", Removed: 7, Status: FileDeleted},
+		}, files)
+	})
+
+	t.Run("renames", func(t *testing.T) {
+		output := "0\t0\told.go => new.go\n" +
+			"2\t1\tsession/{git => vcs}/diff.go\n" +
+			"1\t0\tui/{ => panes}/diff.go\n" +
+			" rename old.go => new.go (100%)\n" +
+			" rename session/{git => vcs}/diff.go (90%)\n"
+
+		files := parseNumstat(output)
+
+		assert.Equal(t, []FileStat{
+			{Path: "new.go", OldPath: "old.go", Status: FileRenamed},
+			{Path: "session/vcs/diff.go", OldPath: "session/git/diff.go", Added: 2, Removed: 1, Status: FileRenamed},
+			{Path: "ui/panes/diff.go", OldPath: "ui/diff.go", Added: 1, Status: FileRenamed},
+		}, files)
+	})
+
+	t.Run("binary files", func(t *testing.T) {
+		output := "-\t-\tassets/screenshot.png\n" +
+			"-\t-\tassets/logo.png\n" +
+			" create mode 100644 assets/logo.png\n"
+
+		files := parseNumstat(output)
+
+		assert.Equal(t, []FileStat{
+			{Path: "assets/screenshot.png", Binary: true, Status: FileModified},
+			{Path: "assets/logo.png", Binary: true, Status: FileAdded},
+		}, files)
+	})
+
+	t.Run("empty output", func(t *testing.T) {
+		assert.Empty(t, parseNumstat(""))
+	})
+}
+
+func TestDiffFileStats(t *testing.T) {
+	var commands []string
+	numstatCalls := 0
+	cmdExec := cmd_test.MockCmdExec{
+		CombinedOutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			command := strings.Join(cmd.Args, " ")
+			commands = append(commands, command)
+			switch {
+			case strings.Contains(command, "--numstat"):
+				numstatCalls++
+				return []byte("1\t0\tmain.go\n"), nil
+			case strings.Contains(command, " diff "):
+				return []byte("+added line\n"), nil
+			}
+			return nil, nil
+		},
+	}
+	g := &GitWorktree{worktreePath: "/tmp/worktree", baseCommitSHA: "abc123", cmdExec: cmdExec}
+
+	t.Run("runs numstat through the executor", func(t *testing.T) {
+		files, err := g.DiffFileStats(DiffOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []FileStat{{Path: "main.go", Added: 1, Status: FileModified}}, files)
+		assert.Contains(t, commands, "git -C /tmp/worktree --no-pager diff --numstat --summary abc123")
+	})
+
+	t.Run("caches file stats until the diff changes", func(t *testing.T) {
+		numstatCalls = 0
+
+		first := g.Diff(DiffOptions{})
+		second := g.Diff(DiffOptions{})
+
+		require.NoError(t, first.Error)
+		assert.Equal(t, first.Files, second.Files)
+		assert.Equal(t, 1, numstatCalls)
+	})
+
+	t.Run("reports git errors", func(t *testing.T) {
+		failing := &GitWorktree{worktreePath: "/tmp/worktree", cmdExec: cmd_test.MockCmdExec{
+			CombinedOutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+				return []byte("fatal: bad revision"), fmt.Errorf("exit status 128")
+			},
+		}}
+
+		_, err := failing.DiffFileStats(DiffOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestDiffOptions(t *testing.T) {
+	var commands []string
+	g := &GitWorktree{worktreePath: "/tmp/worktree", baseCommitSHA: "abc123", cmdExec: cmd_test.MockCmdExec{
+		CombinedOutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			commands = append(commands, strings.Join(cmd.Args, " "))
+			return []byte("+added line\n"), nil
+		},
+	}}
+
+	testCases := []struct {
+		name    string
+		opts    DiffOptions
+		diff    string
+		numstat string
+	}{
+		{
+			name:    "defaults",
+			diff:    "git -C /tmp/worktree --no-pager diff abc123",
+			numstat: "git -C /tmp/worktree --no-pager diff --numstat --summary abc123",
+		},
+		{
+			name:    "ignore whitespace",
+			opts:    DiffOptions{IgnoreWhitespace: true},
+			diff:    "git -C /tmp/worktree --no-pager diff --ignore-all-space abc123",
+			numstat: "git -C /tmp/worktree --no-pager diff --numstat --summary --ignore-all-space abc123",
+		},
+		{
+			name:    "context lines only apply to the patch",
+			opts:    DiffOptions{IgnoreWhitespace: true, ContextLines: 10},
+			diff:    "git -C /tmp/worktree --no-pager diff --ignore-all-space --unified=10 abc123",
+			numstat: "git -C /tmp/worktree --no-pager diff --numstat --summary --ignore-all-space abc123",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			commands = nil
+			// Start from an empty file stats cache so numstat runs.
+			g.lastFileStats = nil
+
+			stats := g.Diff(tc.opts)
+
+			require.NoError(t, stats.Error)
+			assert.Contains(t, commands, tc.diff)
+			assert.Contains(t, commands, tc.numstat)
+		})
+	}
+}
+
+func TestDiffBase(t *testing.T) {
+	newWorktree := func(baseBranch string, mergeBase func() ([]byte, error)) (*GitWorktree, *[]string) {
+		commands := &[]string{}
+		return &GitWorktree{
+			worktreePath:  "/tmp/worktree",
+			baseCommitSHA: "abc123",
+			baseBranch:    baseBranch,
+			cmdExec: cmd_test.MockCmdExec{
+				CombinedOutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+					command := strings.Join(cmd.Args, " ")
+					*commands = append(*commands, command)
+					if strings.Contains(command, "merge-base") {
+						return mergeBase()
+					}
+					return nil, nil
+				},
+			},
+		}, commands
+	}
+	found := func() ([]byte, error) { return []byte("def456\n"), nil }
+
+	t.Run("branch mode diffs against the merge-base with the base branch", func(t *testing.T) {
+		g, commands := newWorktree("main", found)
+
+		require.NoError(t, g.Diff(DiffOptions{}).Error)
+
+		assert.Contains(t, *commands, "git -C /tmp/worktree merge-base HEAD main")
+		assert.Contains(t, *commands, "git -C /tmp/worktree --no-pager diff def456")
+	})
+
+	t.Run("uncommitted mode diffs against HEAD", func(t *testing.T) {
+		g, commands := newWorktree("main", found)
+
+		require.NoError(t, g.Diff(DiffOptions{Mode: DiffUncommitted}).Error)
+
+		assert.Contains(t, *commands, "git -C /tmp/worktree --no-pager diff HEAD")
+		for _, command := range *commands {
+			assert.NotContains(t, command, "merge-base")
+		}
+	})
+
+	t.Run("falls back to the base commit without a base branch", func(t *testing.T) {
+		g, commands := newWorktree("", found)
+
+		require.NoError(t, g.Diff(DiffOptions{}).Error)
+
+		assert.Contains(t, *commands, "git -C /tmp/worktree --no-pager diff abc123")
+		for _, command := range *commands {
+			assert.NotContains(t, command, "merge-base")
+		}
+	})
+
+	t.Run("caches the merge-base until HEAD or the base branch moves", func(t *testing.T) {
+		g, commands := newWorktree("main", found)
+		heads := "111\n222\n"
+		mock := g.cmdExec.(cmd_test.MockCmdExec)
+		g.cmdExec = cmd_test.MockCmdExec{
+			CombinedOutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+				if strings.Contains(strings.Join(cmd.Args, " "), "rev-parse HEAD main") {
+					*commands = append(*commands, strings.Join(cmd.Args, " "))
+					return []byte(heads), nil
+				}
+				return mock.CombinedOutputFunc(cmd)
+			},
+		}
+		countMergeBases := func() int {
+			count := 0
+			for _, command := range *commands {
+				if strings.Contains(command, "merge-base") {
+					count++
+				}
+			}
+			return count
+		}
+
+		assert.Equal(t, "def456", g.diffBase(DiffBranch))
+		assert.Equal(t, "def456", g.diffBase(DiffBranch))
+		assert.Equal(t, 1, countMergeBases())
+
+		heads = "111\n333\n"
+		assert.Equal(t, "def456", g.diffBase(DiffBranch))
+		assert.Equal(t, 2, countMergeBases(), "the base branch moved")
+	})
+
+	t.Run("falls back to the base commit if the base branch is gone", func(t *testing.T) {
+		g, commands := newWorktree("deleted", func() ([]byte, error) {
+			return []byte("fatal: Not a valid object name deleted"), fmt.Errorf("exit status 128")
+		})
+
+		require.NoError(t, g.Diff(DiffOptions{}).Error)
+
+		assert.Contains(t, *commands, "git -C /tmp/worktree --no-pager diff abc123")
+	})
+}
+
+func TestDiffModes(t *testing.T) {
+	setupGitEnv(t)
+	repo := newTestRepo(t, "main.txt")
+	worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), repo, "diff-modes")
+	require.NoError(t, err)
+	require.NoError(t, worktree.Setup())
+	t.Cleanup(func() { _ = worktree.Cleanup() })
+	path := worktree.GetWorktreePath()
+
+	// Committed work on the instance branch, an uncommitted change, and unrelated work on main since.
+	require.NoError(t, os.WriteFile(filepath.Join(path, "committed.txt"), []byte("committed\n"), 0644))
+	runGit(t, path, "add", ".")
+	runGit(t, path, "commit", "-q", "-m", "committed work")
+	require.NoError(t, os.WriteFile(filepath.Join(path, "uncommitted.txt"), []byte("uncommitted\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "upstream.txt"), []byte("upstream\n"), 0644))
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-q", "-m", "upstream work")
+
+	branch := worktree.Diff(DiffOptions{})
+	require.NoError(t, branch.Error)
+	assert.Contains(t, branch.Content, "+committed")
+	assert.Contains(t, branch.Content, "+uncommitted")
+	assert.NotContains(t, branch.Content, "upstream")
+
+	uncommitted := worktree.Diff(DiffOptions{Mode: DiffUncommitted})
+	require.NoError(t, uncommitted.Error)
+	assert.NotContains(t, uncommitted.Content, "+committed")
+	assert.Contains(t, uncommitted.Content, "+uncommitted")
+}
+
+func TestPatchWithoutWorktree(t *testing.T) {
+	setupGitEnv(t)
+	repo := newTestRepo(t, "main.txt")
+	worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), repo, "paused-patch")
+	require.NoError(t, err)
+	require.NoError(t, worktree.Setup())
+	t.Cleanup(func() { _ = worktree.Cleanup() })
+	path := worktree.GetWorktreePath()
+
+	require.NoError(t, os.WriteFile(filepath.Join(path, "committed.txt"), []byte("committed\n"), 0644))
+	runGit(t, path, "add", ".")
+	runGit(t, path, "commit", "-q", "-m", "committed work")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "upstream.txt"), []byte("upstream\n"), 0644))
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-q", "-m", "upstream work")
+
+	// Like a pause, which commits the changes first.
+	require.NoError(t, worktree.Remove())
+
+	patch, err := worktree.Patch(DiffBranch)
+	require.NoError(t, err)
+	assert.Contains(t, patch, "+committed")
+	assert.NotContains(t, patch, "upstream")
+
+	patch, err = worktree.Patch(DiffUncommitted)
+	require.NoError(t, err)
+	assert.Empty(t, patch)
+}
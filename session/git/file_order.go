@@ -0,0 +1,138 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// FileOrder decides the order the files of a diff are shown in. The zero value keeps git's order.
+type FileOrder struct {
+	// Alphabetical orders the files by path, ignoring case, instead of in git's order.
+	Alphabetical bool
+	// Priority are path patterns, like "*.go" or "docs", that order the files: files matching an earlier pattern
+	// come first and files matching none come last. Like in .gitignore, the last pattern a file matches counts, so
+	// ["*.go", "*_test.go"] shows Go sources before their tests. A pattern without a slash matches the name of the
+	// file or of any directory it is in, and one with a slash matches the path from the top of the repository.
+	// Files with the same priority keep their order.
+	Priority []string
+}
+
+// Validate checks that the patterns of the order are valid.
+func (o FileOrder) Validate() error {
+	for _, pattern := range o.Priority {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// rank returns the index of the last pattern in Priority that path matches, or len(Priority) if it matches none.
+func (o FileOrder) rank(filePath string) int {
+	for i := len(o.Priority) - 1; i >= 0; i-- {
+		if matchFilePattern(o.Priority[i], filePath) {
+			return i
+		}
+	}
+	return len(o.Priority)
+}
+
+// matchFilePattern reports whether pattern matches filePath or one of the directories it is in.
+func matchFilePattern(pattern, filePath string) bool {
+	pattern = strings.TrimSuffix(pattern, "/")
+	for p := filePath; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		name := p
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(p)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Less reports whether the file at path a is shown before the one at path b.
+func (o FileOrder) Less(a, b string) bool {
+	if rankA, rankB := o.rank(a), o.rank(b); rankA != rankB {
+		return rankA < rankB
+	}
+	if o.Alphabetical {
+		lowerA, lowerB := strings.ToLower(a), strings.ToLower(b)
+		if lowerA != lowerB {
+			return lowerA < lowerB
+		}
+	}
+	return false
+}
+
+// IsDefault reports whether the order keeps git's order.
+func (o FileOrder) IsDefault() bool {
+	return !o.Alphabetical && len(o.Priority) == 0
+}
+
+// SortFiles returns a copy of files in the order.
+func (o FileOrder) SortFiles(files []FileStat) []FileStat {
+	sorted := append([]FileStat(nil), files...)
+	if o.IsDefault() {
+		return sorted
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return o.Less(sorted[i].Path, sorted[j].Path)
+	})
+	return sorted
+}
+
+// SortDiff reorders the per-file sections of diff, the output of git diff, in the order. Anything before the first
+// file is kept at the top.
+func (o FileOrder) SortDiff(diff string) string {
+	if o.IsDefault() {
+		return diff
+	}
+	// The final newline ends the diff rather than the last file, which may not stay last.
+	body := strings.TrimSuffix(diff, "\n")
+	preamble, sections := splitFileDiffs(body)
+	sort.SliceStable(sections, func(i, j int) bool {
+		return o.Less(sections[i].path, sections[j].path)
+	})
+	parts := preamble
+	for _, section := range sections {
+		parts = append(parts, section.lines...)
+	}
+	return strings.Join(parts, "\n") + diff[len(body):]
+}
+
+// fileDiff is the part of a diff about a single file.
+type fileDiff struct {
+	// path is the path of the file after the change.
+	path  string
+	lines []string
+}
+
+// splitFileDiffs splits diff into the lines before the first file and the sections of each file, each starting
+// with its "diff --git" line.
+func splitFileDiffs(diff string) (preamble []string, sections []fileDiff) {
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			sections = append(sections, fileDiff{path: diffHeaderPath(line), lines: []string{line}})
+			continue
+		}
+		if len(sections) == 0 {
+			preamble = append(preamble, line)
+			continue
+		}
+		last := &sections[len(sections)-1]
+		last.lines = append(last.lines, line)
+	}
+	return preamble, sections
+}
+
+// diffHeaderPath returns the path after the change from a "diff --git a/<old> b/<new>" line.
+func diffHeaderPath(header string) string {
+	if i := strings.LastIndex(header, " b/"); i != -1 {
+		return header[i+len(" b/"):]
+	}
+	return strings.TrimPrefix(header, "diff --git ")
+}
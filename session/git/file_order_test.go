@@ -0,0 +1,114 @@
+package git
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileOrderLess(t *testing.T) {
+	// The paths in git's order.
+	paths := []string{
+		"Makefile",
+		"README.md",
+		"app/app.go",
+		"app/app_test.go",
+		"docs/usage.md",
+		"session/git/diff.go",
+		"session/git/diff_test.go",
+		"testdata/golden.txt",
+	}
+	ordered := func(order FileOrder) []string {
+		sorted := append([]string(nil), paths...)
+		sort.SliceStable(sorted, func(i, j int) bool { return order.Less(sorted[i], sorted[j]) })
+		return sorted
+	}
+
+	assert.Equal(t, paths, ordered(FileOrder{}), "the zero order keeps git's order")
+
+	assert.Equal(t, []string{
+		"app/app.go",
+		"app/app_test.go",
+		"docs/usage.md",
+		"Makefile",
+		"README.md",
+		"session/git/diff.go",
+		"session/git/diff_test.go",
+		"testdata/golden.txt",
+	}, ordered(FileOrder{Alphabetical: true}), "alphabetical ignores case")
+
+	assert.Equal(t, []string{
+		"app/app.go",
+		"session/git/diff.go",
+		"app/app_test.go",
+		"session/git/diff_test.go",
+		"testdata/golden.txt",
+		"Makefile",
+		"README.md",
+		"docs/usage.md",
+	}, ordered(FileOrder{Priority: []string{"*.go", "*_test.go", "testdata"}}),
+		"sources before tests, and the last matching pattern counts")
+
+	assert.Equal(t, []string{
+		"README.md",
+		"docs/usage.md",
+		"app/app.go",
+		"app/app_test.go",
+		"Makefile",
+		"session/git/diff.go",
+		"session/git/diff_test.go",
+		"testdata/golden.txt",
+	}, ordered(FileOrder{Priority: []string{"*.md", "docs/", "app/*"}}),
+		"directories and paths match, and the files of a priority keep git's order")
+
+	assert.Equal(t, []string{
+		"docs/usage.md",
+		"README.md",
+		"app/app.go",
+		"app/app_test.go",
+		"Makefile",
+		"session/git/diff.go",
+		"session/git/diff_test.go",
+		"testdata/golden.txt",
+	}, ordered(FileOrder{Alphabetical: true, Priority: []string{"*.md"}}),
+		"the files of a priority are alphabetical")
+}
+
+func TestFileOrderValidate(t *testing.T) {
+	assert.NoError(t, FileOrder{Priority: []string{"*.go", "docs/"}}.Validate())
+	assert.ErrorContains(t, FileOrder{Priority: []string{"*.go", "[a-"}}.Validate(), `invalid file pattern "[a-"`)
+}
+
+func TestFileOrderSortDiff(t *testing.T) {
+	diff := "diff --git a/main_test.go b/main_test.go\n" +
+		"@@ -1 +1 @@\n" +
+		"-old test\n" +
+		"+new test\n" +
+		"diff --git a/old.go b/main.go\n" +
+		"similarity index 90%\n" +
+		"rename from old.go\n" +
+		"rename to main.go\n" +
+		"@@ -1 +1 @@\n" +
+		"-old\n" +
+		"+new\n"
+	order := FileOrder{Priority: []string{"*.go", "*_test.go"}}
+
+	assert.Equal(t, "diff --git a/old.go b/main.go\n"+
+		"similarity index 90%\n"+
+		"rename from old.go\n"+
+		"rename to main.go\n"+
+		"@@ -1 +1 @@\n"+
+		"-old\n"+
+		"+new\n"+
+		"diff --git a/main_test.go b/main_test.go\n"+
+		"@@ -1 +1 @@\n"+
+		"-old test\n"+
+		"+new test\n", order.SortDiff(diff))
+	assert.Equal(t, diff, FileOrder{}.SortDiff(diff))
+	assert.Equal(t, "", order.SortDiff(""))
+
+	files := []FileStat{{Path: "main_test.go"}, {Path: "main.go", OldPath: "old.go"}}
+	assert.Equal(t, []FileStat{{Path: "main.go", OldPath: "old.go"}, {Path: "main_test.go"}}, order.SortFiles(files))
+	assert.Equal(t, "main_test.go", files[0].Path, "the files passed in are left alone")
+}
@@ -0,0 +1,23 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// WritePatch writes diff, the output of git diff, to the file at path so that it can be applied with git apply. An
+// existing file is overwritten. It fails if diff has no changes.
+func WritePatch(path string, diff string) error {
+	if strings.TrimSpace(diff) == "" {
+		return fmt.Errorf("no changes to save")
+	}
+	// git apply rejects a patch whose last line isn't terminated.
+	if !strings.HasSuffix(diff, "\n") {
+		diff += "\n"
+	}
+	if err := os.WriteFile(path, []byte(diff), 0644); err != nil {
+		return fmt.Errorf("failed to write patch: %w", err)
+	}
+	return nil
+}
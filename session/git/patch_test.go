@@ -0,0 +1,53 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePatch(t *testing.T) {
+	setupGitEnv(t)
+
+	t.Run("writes a patch that git apply accepts", func(t *testing.T) {
+		repo := newTestRepo(t, "main.txt")
+		require.NoError(t, os.WriteFile(filepath.Join(repo, "main.txt"), []byte("changed\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(repo, "new.txt"), []byte("new\n"), 0644))
+		runGit(t, repo, "add", "-N", ".")
+		diff := gitOutput(t, repo, "--no-pager", "diff") // trimmed, so the final newline is missing
+		runGit(t, repo, "reset", "-q", "--hard")
+		require.NoFileExists(t, filepath.Join(repo, "new.txt"))
+
+		path := filepath.Join(t.TempDir(), "fix.patch")
+		require.NoError(t, WritePatch(path, diff))
+		written, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, diff+"\n", string(written))
+
+		runGit(t, repo, "apply", path)
+		assert.Equal(t, "changed\n", readFile(t, repo, "main.txt"))
+		assert.Equal(t, "new\n", readFile(t, repo, "new.txt"))
+	})
+
+	t.Run("fails without changes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "empty.patch")
+		assert.EqualError(t, WritePatch(path, " \n"), "no changes to save")
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("fails if the file can't be written", func(t *testing.T) {
+		err := WritePatch(filepath.Join(t.TempDir(), "missing", "fix.patch"), "diff --git a/a b/a\n")
+		assert.ErrorContains(t, err, "failed to write patch")
+	})
+}
+
+// readFile returns the content of file in dir.
+func readFile(t *testing.T, dir, file string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	require.NoError(t, err)
+	return string(data)
+}
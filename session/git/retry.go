@@ -0,0 +1,79 @@
+package git
+
+import (
+	"claude-squad/log"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRetryAttempts is how many times a git command that fails transiently is run in all by default.
+	DefaultRetryAttempts = 3
+	// DefaultRetryBaseDelay is how long to wait before the first retry by default. The wait doubles every retry.
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+)
+
+// RetryPolicy says how often git commands that fail transiently, like worktree add, fetch and push, are retried.
+type RetryPolicy struct {
+	// Attempts is how many times the command is run in all. One or less runs it once.
+	Attempts int
+	// BaseDelay is how long to wait before the first retry. The wait doubles every retry.
+	BaseDelay time.Duration
+}
+
+// retryPolicy is the policy of every worktree.
+var retryPolicy = RetryPolicy{Attempts: DefaultRetryAttempts, BaseDelay: DefaultRetryBaseDelay}
+
+// retrySleep waits between attempts. Tests replace it to not wait.
+var retrySleep = time.Sleep
+
+// SetRetryPolicy sets how often git commands that fail transiently are retried.
+func SetRetryPolicy(policy RetryPolicy) {
+	retryPolicy = policy
+}
+
+// transientErrors are parts of git's output for failures that may go away when the command is run again: a lock
+// left by a concurrent git command, and network failures. Other failures, like a branch that already exists, fail
+// right away.
+var transientErrors = []string{
+	"index.lock",
+	// Also covers "cannot lock ref" while another command holds the ref's lock file, but not when the ref can't be
+	// created because of a conflicting ref name.
+	".lock': File exists",
+	"could not resolve host",
+	"connection timed out",
+	"operation timed out",
+	"connection reset",
+	"connection refused",
+	"the remote end hung up unexpectedly",
+	"early eof",
+	"tls handshake timeout",
+	"failed to connect",
+	"rpc failed",
+}
+
+// isTransient reports whether err from a git command looks like a failure that may go away on a retry.
+func isTransient(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, transient := range transientErrors {
+		if strings.Contains(msg, strings.ToLower(transient)) {
+			return true
+		}
+	}
+	return false
+}
+
+// runGitCommandWithRetry is runGitCommand that runs the command again after a transient failure, waiting longer
+// each time, as long as the retry policy allows.
+func (g *GitWorktree) runGitCommandWithRetry(path string, args ...string) (string, error) {
+	delay := retryPolicy.BaseDelay
+	for attempt := 1; ; attempt++ {
+		output, err := g.runGitCommand(path, args...)
+		if err == nil || attempt >= retryPolicy.Attempts || !isTransient(err) {
+			return output, err
+		}
+		log.WarningLog.Printf("git %s failed, retrying in %v: %v", args[0], delay, err)
+		retrySleep(delay)
+		delay *= 2
+	}
+}
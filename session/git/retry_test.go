@@ -0,0 +1,123 @@
+package git
+
+import (
+	"claude-squad/cmd/cmd_test"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const indexLockOutput = "fatal: Unable to create '/repo/.git/index.lock': File exists."
+
+// failingGit returns a worktree whose git commands print the given outputs and fail, one per run, and succeed once
+// they run out. It counts the runs in runs, and the retry waits are recorded in sleeps instead of waited.
+func failingGit(t *testing.T, policy RetryPolicy, outputs ...string) (worktree *GitWorktree, runs *int, sleeps *[]time.Duration) {
+	prevPolicy, prevSleep := retryPolicy, retrySleep
+	t.Cleanup(func() { retryPolicy, retrySleep = prevPolicy, prevSleep })
+	SetRetryPolicy(policy)
+	sleeps = &[]time.Duration{}
+	retrySleep = func(d time.Duration) { *sleeps = append(*sleeps, d) }
+
+	runs = new(int)
+	worktree = NewGitWorktreeFromStorage("/repo", "/worktree", "retry", "me/retry", "", "main")
+	worktree.SetExecutor(cmd_test.MockCmdExec{
+		CombinedOutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			*runs++
+			if *runs <= len(outputs) {
+				return []byte(outputs[*runs-1]), errors.New("exit status 128")
+			}
+			return []byte("ok"), nil
+		},
+	})
+	return worktree, runs, sleeps
+}
+
+func TestRunGitCommandWithRetry(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3, BaseDelay: 100 * time.Millisecond}
+
+	t.Run("retries a transient failure", func(t *testing.T) {
+		worktree, runs, sleeps := failingGit(t, policy, indexLockOutput)
+
+		output, err := worktree.runGitCommandWithRetry("/repo", "worktree", "add", "/worktree", "me/retry")
+		require.NoError(t, err)
+		assert.Equal(t, "ok", output)
+		assert.Equal(t, 2, *runs)
+		assert.Equal(t, []time.Duration{100 * time.Millisecond}, *sleeps)
+	})
+
+	t.Run("doubles the wait and gives up after the attempts", func(t *testing.T) {
+		timeout := "fatal: unable to access 'https://github.com/o/r/': Connection timed out"
+		worktree, runs, sleeps := failingGit(t, policy, timeout, timeout, timeout, timeout)
+
+		_, err := worktree.runGitCommandWithRetry("/repo", "fetch", "origin", "main")
+		assert.ErrorContains(t, err, "Connection timed out")
+		assert.Equal(t, 3, *runs)
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, *sleeps)
+	})
+
+	t.Run("fails fast on other errors", func(t *testing.T) {
+		worktree, runs, sleeps := failingGit(t, policy, "fatal: a branch named 'me/retry' already exists")
+
+		_, err := worktree.runGitCommandWithRetry("/repo", "worktree", "add", "-b", "me/retry", "/worktree")
+		assert.ErrorContains(t, err, "already exists")
+		assert.Equal(t, 1, *runs)
+		assert.Empty(t, *sleeps)
+	})
+
+	t.Run("runs once with a single attempt", func(t *testing.T) {
+		worktree, runs, _ := failingGit(t, RetryPolicy{Attempts: 1}, indexLockOutput)
+
+		_, err := worktree.runGitCommandWithRetry("/repo", "push", "-u", "origin", "me/retry")
+		assert.Error(t, err)
+		assert.Equal(t, 1, *runs)
+	})
+}
+
+func TestRetriedGitOperations(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3, BaseDelay: time.Millisecond}
+
+	t.Run("worktree add from an existing branch", func(t *testing.T) {
+		// The first run is the worktree remove that clears the way, whose failure is ignored.
+		worktree, runs, _ := failingGit(t, policy, "fatal: not a working tree", indexLockOutput)
+
+		require.NoError(t, worktree.setupFromExistingBranch())
+		assert.Equal(t, 3, *runs)
+	})
+
+	t.Run("fetch of a remote branch", func(t *testing.T) {
+		worktree, runs, sleeps := failingGit(t, policy,
+			"fatal: unable to access 'https://github.com/o/r/': Could not resolve host: github.com")
+		worktree.remote = "origin"
+
+		require.NoError(t, worktree.setupFromRemote())
+		assert.Len(t, *sleeps, 1, "the fetch should be retried once")
+		// The fetch twice, then rev-parse, worktree remove and worktree add.
+		assert.Equal(t, 5, *runs)
+		assert.Equal(t, "origin/me/retry", worktree.GetBaseBranch())
+	})
+}
+
+func TestIsTransient(t *testing.T) {
+	for _, output := range []string{
+		indexLockOutput,
+		"error: cannot lock ref 'refs/heads/me/retry': Unable to create '/repo/.git/refs/heads/me/retry.lock': File exists.",
+		"ssh: Could not resolve hostname github.com",
+		"fatal: the remote end hung up unexpectedly",
+		"error: RPC failed; curl 56 Recv failure: Connection reset by peer",
+	} {
+		assert.True(t, isTransient(fmt.Errorf("git command failed: %s (exit status 128)", output)), output)
+	}
+	for _, output := range []string{
+		"fatal: 'me/retry' is already checked out",
+		"error: failed to push some refs: Updates were rejected because the tip of your current branch is behind",
+		"remote: Permission to o/r.git denied",
+		"error: cannot lock ref 'refs/heads/me/retry/x': 'refs/heads/me/retry' exists; cannot create 'refs/heads/me/retry/x'",
+	} {
+		assert.False(t, isTransient(fmt.Errorf("git command failed: %s (exit status 1)", output)), output)
+	}
+}
@@ -1,13 +1,12 @@
 package git
 
 import (
+	"claude-squad/cmd"
 	"fmt"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
-
-	"github.com/go-git/go-git/v5"
 )
 
 // sanitizeBranchName transforms an arbitrary string into a Git branch name friendly string.
@@ -53,34 +52,40 @@ func checkGHCLI() error {
 
 // IsGitRepo checks if the given path is within a git repository
 func IsGitRepo(path string) bool {
-	for {
-		_, err := git.PlainOpen(path)
-		if err == nil {
-			return true
-		}
+	_, err := findGitRepoRoot(cmd.MakeExecutor(), path)
+	return err == nil
+}
 
-		parent := filepath.Dir(path)
-		if parent == path {
-			return false
-		}
-		path = parent
+// RepoRootOrDir returns the root of the repository containing path, so per-repo files like .claude-squad are found
+// from any subdirectory. It returns path itself if it isn't in a repository.
+func RepoRootOrDir(path string) string {
+	root, err := findGitRepoRoot(cmd.MakeExecutor(), path)
+	if err != nil {
+		return path
 	}
+	return root
 }
 
-func findGitRepoRoot(path string) (string, error) {
-	currentPath := path
-	for {
-		_, err := git.PlainOpen(currentPath)
-		if err == nil {
-			// Found the repository root
-			return currentPath, nil
-		}
+// findGitRepoRoot returns the root of the repository containing path. Git resolves it, so subdirectories,
+// submodules and linked worktrees (whose .git is a file) all work. Paths inside a working tree resolve to the
+// top of the working tree, and bare repositories resolve to the repository directory itself. Git is run by cmdExec.
+func findGitRepoRoot(cmdExec cmd.Executor, path string) (string, error) {
+	output, err := cmdExec.Output(exec.Command("git", "-C", path, "rev-parse", "--is-bare-repository"))
+	if err != nil {
+		return "", fmt.Errorf("not a git repository (or any of the parent directories): %s", path)
+	}
 
-		parent := filepath.Dir(currentPath)
-		if parent == currentPath {
-			// Reached the filesystem root without finding a repository
-			return "", fmt.Errorf("failed to find Git repository root from path: %s", path)
-		}
-		currentPath = parent
+	flag := "--show-toplevel"
+	if strings.TrimSpace(string(output)) == "true" {
+		flag = "--absolute-git-dir"
+	}
+	output, err = cmdExec.Output(exec.Command("git", "-C", path, "rev-parse", flag))
+	if err != nil {
+		return "", fmt.Errorf("failed to find git repository root from path %s: %w", path, err)
+	}
+	root := strings.TrimSpace(string(output))
+	if root == "" {
+		return "", fmt.Errorf("failed to find git repository root from path: %s", path)
 	}
+	return filepath.Clean(root), nil
 }
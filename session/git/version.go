@@ -0,0 +1,59 @@
+package git
+
+import (
+	"claude-squad/cmd"
+	"claude-squad/log"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// mergeTreeMinVersion is the oldest git release whose merge-tree can merge without a worktree (--write-tree).
+const mergeTreeMinVersion = "2.38"
+
+var versionRegex = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+var (
+	// mergeTreeOnce guards mergeTreeSupported, which is found out the first time it is needed.
+	mergeTreeOnce      sync.Once
+	mergeTreeSupported bool
+)
+
+// supportsMergeTree reports whether the installed git has `merge-tree --write-tree`. Git is asked for its version
+// with cmdExec the first time only. If it can't be asked, the check is disabled.
+func supportsMergeTree(cmdExec cmd.Executor) bool {
+	mergeTreeOnce.Do(func() {
+		output, err := cmdExec.Output(exec.Command("git", "version"))
+		if err != nil {
+			log.WarningLog.Printf("failed to get the git version, not checking branches for conflicts: %v", err)
+			return
+		}
+		version := strings.TrimSpace(string(output))
+		mergeTreeSupported = versionAtLeast(version, mergeTreeMinVersion)
+		if !mergeTreeSupported {
+			log.WarningLog.Printf("%s is too old to check branches for conflicts, which needs git %s or newer",
+				version, mergeTreeMinVersion)
+		}
+	})
+	return mergeTreeSupported
+}
+
+// versionAtLeast reports whether the major.minor version in version is at least min. Versions that can't be
+// parsed are assumed to be recent enough.
+func versionAtLeast(version, min string) bool {
+	got := versionRegex.FindStringSubmatch(version)
+	want := versionRegex.FindStringSubmatch(min)
+	if got == nil || want == nil {
+		return true
+	}
+	gotMajor, _ := strconv.Atoi(got[1])
+	gotMinor, _ := strconv.Atoi(got[2])
+	wantMajor, _ := strconv.Atoi(want[1])
+	wantMinor, _ := strconv.Atoi(want[2])
+	if gotMajor != wantMajor {
+		return gotMajor > wantMajor
+	}
+	return gotMinor >= wantMinor
+}
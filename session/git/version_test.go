@@ -0,0 +1,54 @@
+package git
+
+import (
+	"claude-squad/cmd/cmd_test"
+	"fmt"
+	"os/exec"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportsMergeTree(t *testing.T) {
+	// fakeVersion makes the next supportsMergeTree ask a fake git that prints output, and counts how often it is
+	// asked in asked.
+	fakeVersion := func(t *testing.T, output string, err error) (*GitWorktree, *int) {
+		mergeTreeOnce = sync.Once{}
+		t.Cleanup(func() { mergeTreeOnce = sync.Once{} })
+		asked := 0
+		worktree := NewGitWorktreeFromStorage("/repo", "/worktree", "old-git", "me/old-git", "", "main")
+		worktree.SetExecutor(cmd_test.MockCmdExec{OutputFunc: func(c *exec.Cmd) ([]byte, error) {
+			require.Equal(t, []string{"git", "version"}, c.Args, "merge-tree must not run")
+			asked++
+			return []byte(output), err
+		}})
+		return worktree, &asked
+	}
+
+	for output, want := range map[string]bool{
+		"git version 2.37.1\n":                 false,
+		"git version 1.8.3.1\n":                false,
+		"git version 2.38.0\n":                 true,
+		"git version 2.39.2 (Apple Git-143)\n": true,
+		"git version 3.0.0\n":                  true,
+	} {
+		worktree, asked := fakeVersion(t, output, nil)
+		assert.Equal(t, want, supportsMergeTree(worktree.cmdExec), output)
+		assert.Equal(t, want, supportsMergeTree(worktree.cmdExec), output)
+		assert.Equal(t, 1, *asked, "git is asked once")
+	}
+
+	t.Run("an old git checks nothing", func(t *testing.T) {
+		worktree, _ := fakeVersion(t, "git version 2.30.0\n", nil)
+		conflicts, err := worktree.Conflicts()
+		require.NoError(t, err)
+		assert.Nil(t, conflicts)
+	})
+
+	t.Run("a git that can't be asked checks nothing", func(t *testing.T) {
+		worktree, _ := fakeVersion(t, "", fmt.Errorf("exec: \"git\": executable file not found in $PATH"))
+		assert.False(t, supportsMergeTree(worktree.cmdExec))
+	})
+}
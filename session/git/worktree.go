@@ -1,10 +1,13 @@
 package git
 
 import (
+	"claude-squad/cmd"
 	"claude-squad/config"
 	"claude-squad/log"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
@@ -29,20 +32,49 @@ type GitWorktree struct {
 	branchName string
 	// Base commit hash for the worktree
 	baseCommitSHA string
+	// baseBranch is the branch the worktree was created from. It is empty if the repository was on a detached
+	// HEAD, or for worktrees saved before it was recorded.
+	baseBranch string
+	// remote is the remote to fetch the branch from when the worktree is created from a remote branch. It is
+	// cleared once the local branch exists.
+	remote string
+	// inPlace is true if worktreePath is a directory of the repository itself rather than a worktree created for
+	// the instance. Nothing is created or removed then, and branchName is whatever was checked out there.
+	inPlace bool
+	// cmdExec runs git commands
+	cmdExec cmd.Executor
+	// opMu serializes the operations that run git in the worktree, so that e.g. a diff can't race with a push and
+	// trip over its index.lock. Operations on different worktrees run in parallel. The periodic refreshes, TryDiff
+	// and Conflicts, don't wait for it.
+	opMu sync.Mutex
+
+	// lastDiffContent and lastFileStats cache the per-file stats of the last diff so they are only
+	// recomputed when the diff changes.
+	lastDiffContent string
+	lastFileStats   []FileStat
+	// mergeBaseKey and mergeBase cache the merge-base found by diffBase. The key is the commits HEAD and the base
+	// branch were at, so the merge-base is only looked for again when either moves.
+	mergeBaseKey string
+	mergeBase    string
 }
 
-func NewGitWorktreeFromStorage(repoPath string, worktreePath string, sessionName string, branchName string, baseCommitSHA string) *GitWorktree {
+// ErrBusy is returned by the refreshes that don't wait while another operation runs git in the worktree.
+var ErrBusy = errors.New("another git operation is running in the worktree")
+
+func NewGitWorktreeFromStorage(repoPath string, worktreePath string, sessionName string, branchName string, baseCommitSHA string, baseBranch string) *GitWorktree {
 	return &GitWorktree{
 		repoPath:      repoPath,
 		worktreePath:  worktreePath,
 		sessionName:   sessionName,
 		branchName:    branchName,
 		baseCommitSHA: baseCommitSHA,
+		baseBranch:    baseBranch,
+		cmdExec:       cmd.MakeExecutor(),
 	}
 }
 
-// NewGitWorktree creates a new GitWorktree instance
-func NewGitWorktree(repoPath string, sessionName string) (tree *GitWorktree, branchname string, err error) {
+// NewGitWorktree creates a new GitWorktree instance whose git commands are run by cmdExec
+func NewGitWorktree(cmdExec cmd.Executor, repoPath string, sessionName string) (tree *GitWorktree, branchname string, err error) {
 	cfg := config.LoadConfig()
 	branchName := fmt.Sprintf("%s%s", cfg.BranchPrefix, sessionName)
 	// Sanitize the final branch name to handle invalid characters from any source
@@ -57,7 +89,7 @@ func NewGitWorktree(repoPath string, sessionName string) (tree *GitWorktree, bra
 		absPath = repoPath
 	}
 
-	repoPath, err = findGitRepoRoot(absPath)
+	repoPath, err = findGitRepoRoot(cmdExec, absPath)
 	if err != nil {
 		return nil, "", err
 	}
@@ -76,9 +108,15 @@ func NewGitWorktree(repoPath string, sessionName string) (tree *GitWorktree, bra
 		sessionName:  sessionName,
 		branchName:   branchName,
 		worktreePath: worktreePath,
+		cmdExec:      cmdExec,
 	}, branchName, nil
 }
 
+// SetExecutor makes the worktree run its git commands with cmdExec, e.g. to fake them in tests.
+func (g *GitWorktree) SetExecutor(cmdExec cmd.Executor) {
+	g.cmdExec = cmdExec
+}
+
 // GetWorktreePath returns the path to the worktree
 func (g *GitWorktree) GetWorktreePath() string {
 	return g.worktreePath
@@ -103,3 +141,8 @@ func (g *GitWorktree) GetRepoName() string {
 func (g *GitWorktree) GetBaseCommitSHA() string {
 	return g.baseCommitSHA
 }
+
+// GetBaseBranch returns the branch the worktree was created from, or "" if it isn't known.
+func (g *GitWorktree) GetBaseBranch() string {
+	return g.baseBranch
+}
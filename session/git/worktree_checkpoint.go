@@ -0,0 +1,80 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// checkpointTimeFormat is the format of the time in the names of checkpoint branches.
+const checkpointTimeFormat = "20060102-150405"
+
+// CheckpointBranchName returns the name of the checkpoint branch of the instance branch made at now, like
+// "me/fix-checkpoint-20250102-150405".
+func (g *GitWorktree) CheckpointBranchName(now time.Time) string {
+	return fmt.Sprintf("%s-checkpoint-%s", g.branchName, now.Format(checkpointTimeFormat))
+}
+
+// Checkpoint commits the current state of the worktree, including uncommitted and untracked files, on a new branch
+// named by CheckpointBranchName, to bookmark the progress. The commit is made from an index of its own, so the
+// instance branch, its index and the files in the worktree are left as they are. If nothing changed since the last
+// commit, the new branch points at that commit. It returns the name of the new branch.
+func (g *GitWorktree) Checkpoint(commitMessage string, now time.Time) (string, error) {
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+
+	branch := g.CheckpointBranchName(now)
+	head, err := g.runGitCommand(g.worktreePath, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to read the last commit: %w", err)
+	}
+	head = strings.TrimSpace(head)
+
+	tree, err := g.worktreeTree()
+	if err != nil {
+		return "", err
+	}
+	headTree, err := g.runGitCommand(g.worktreePath, "rev-parse", "HEAD^{tree}")
+	if err != nil {
+		return "", fmt.Errorf("failed to read the last commit: %w", err)
+	}
+
+	commit := head
+	if tree != strings.TrimSpace(headTree) {
+		output, err := g.runGitCommand(g.worktreePath, "commit-tree", tree, "-p", head, "-m", commitMessage)
+		if err != nil {
+			return "", fmt.Errorf("failed to commit the checkpoint: %w", err)
+		}
+		commit = strings.TrimSpace(output)
+	}
+
+	if _, err := g.runGitCommand(g.worktreePath, "branch", branch, commit); err != nil {
+		return "", fmt.Errorf("failed to create the checkpoint branch %s: %w", branch, err)
+	}
+	return branch, nil
+}
+
+// worktreeTree writes the files in the worktree, as git add -A would stage them, to a tree and returns its id. It
+// uses a temporary index starting from HEAD, so the worktree's own index is untouched.
+func (g *GitWorktree) worktreeTree() (string, error) {
+	dir, err := os.MkdirTemp("", "claudesquad-checkpoint-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create a temporary index: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(dir, "index")}
+
+	if _, err := g.runGitCommandWithEnv(env, g.worktreePath, "read-tree", "HEAD"); err != nil {
+		return "", fmt.Errorf("failed to read the last commit: %w", err)
+	}
+	if _, err := g.runGitCommandWithEnv(env, g.worktreePath, "add", "-A"); err != nil {
+		return "", fmt.Errorf("failed to stage the changes: %w", err)
+	}
+	tree, err := g.runGitCommandWithEnv(env, g.worktreePath, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to write the changes: %w", err)
+	}
+	return strings.TrimSpace(tree), nil
+}
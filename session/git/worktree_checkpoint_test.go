@@ -0,0 +1,112 @@
+package git
+
+import (
+	"claude-squad/cmd"
+	"claude-squad/cmd/cmd_test"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var checkpointTime = time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+func TestCheckpointCommands(t *testing.T) {
+	// ran records the git commands without the -C <path> in front, and whether they used a temporary index.
+	type run struct {
+		args      string
+		tempIndex bool
+	}
+	record := func(headTree string) (*GitWorktree, *[]run) {
+		var ran []run
+		worktree := NewGitWorktreeFromStorage("/repo", "/worktree", "fix", "me/fix", "", "main")
+		worktree.SetExecutor(cmd_test.MockCmdExec{
+			CombinedOutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+				args := strings.Join(cmd.Args[3:], " ")
+				tempIndex := false
+				for _, entry := range cmd.Env {
+					tempIndex = tempIndex || strings.HasPrefix(entry, "GIT_INDEX_FILE=")
+				}
+				ran = append(ran, run{args: args, tempIndex: tempIndex})
+				switch args {
+				case "rev-parse HEAD":
+					return []byte("head\n"), nil
+				case "write-tree":
+					return []byte("tree\n"), nil
+				case "rev-parse HEAD^{tree}":
+					return []byte(headTree + "\n"), nil
+				case "commit-tree tree -p head -m checkpoint":
+					return []byte("commit\n"), nil
+				}
+				return nil, nil
+			},
+		})
+		return worktree, &ran
+	}
+
+	t.Run("commits the changes on a new branch", func(t *testing.T) {
+		worktree, ran := record("old-tree")
+
+		branch, err := worktree.Checkpoint("checkpoint", checkpointTime)
+		require.NoError(t, err)
+		assert.Equal(t, "me/fix-checkpoint-20250102-150405", branch)
+		assert.Equal(t, []run{
+			{args: "rev-parse HEAD"},
+			{args: "read-tree HEAD", tempIndex: true},
+			{args: "add -A", tempIndex: true},
+			{args: "write-tree", tempIndex: true},
+			{args: "rev-parse HEAD^{tree}"},
+			{args: "commit-tree tree -p head -m checkpoint"},
+			{args: "branch me/fix-checkpoint-20250102-150405 commit"},
+		}, *ran)
+	})
+
+	t.Run("points the branch at the last commit without changes", func(t *testing.T) {
+		worktree, ran := record("tree")
+
+		_, err := worktree.Checkpoint("checkpoint", checkpointTime)
+		require.NoError(t, err)
+		assert.Equal(t, run{args: "branch me/fix-checkpoint-20250102-150405 head"}, (*ran)[len(*ran)-1])
+		for _, r := range *ran {
+			assert.NotContains(t, r.args, "commit-tree")
+		}
+	})
+}
+
+func TestCheckpoint(t *testing.T) {
+	setupGitEnv(t)
+	worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), newTestRepo(t, "main.txt"), "checkpoint")
+	require.NoError(t, err)
+	require.NoError(t, worktree.Setup())
+	t.Cleanup(func() { _ = worktree.Cleanup() })
+	path := worktree.GetWorktreePath()
+	head := gitOutput(t, path, "rev-parse", "HEAD")
+
+	require.NoError(t, os.WriteFile(filepath.Join(path, "main.txt"), []byte("changed\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "staged.txt"), []byte("staged\n"), 0644))
+	runGit(t, path, "add", "staged.txt")
+	require.NoError(t, os.WriteFile(filepath.Join(path, "new.txt"), []byte("new\n"), 0644))
+	status := gitOutput(t, path, "status", "--porcelain")
+
+	branch, err := worktree.Checkpoint(CommitPrefix+" checkpoint", checkpointTime)
+	require.NoError(t, err)
+
+	assert.Equal(t, head, gitOutput(t, path, "rev-parse", "HEAD"), "the instance branch should stay where it was")
+	assert.Equal(t, worktree.GetBranchName(), gitOutput(t, path, "branch", "--show-current"))
+	assert.Equal(t, status, gitOutput(t, path, "status", "--porcelain"), "the index and files should be untouched")
+
+	assert.Equal(t, head, gitOutput(t, path, "rev-parse", branch+"^"))
+	assert.Equal(t, "changed", gitOutput(t, path, "show", branch+":main.txt"))
+	assert.Equal(t, "staged", gitOutput(t, path, "show", branch+":staged.txt"))
+	assert.Equal(t, "new", gitOutput(t, path, "show", branch+":new.txt"))
+
+	t.Run("fails if the branch exists", func(t *testing.T) {
+		_, err := worktree.Checkpoint(CommitPrefix+" checkpoint", checkpointTime)
+		assert.ErrorContains(t, err, "failed to create the checkpoint branch")
+	})
+}
@@ -3,16 +3,35 @@ package git
 import (
 	"claude-squad/log"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 )
 
+// CommitPrefix starts the message of the commits claude-squad makes, so they can be told apart from the user's.
+const CommitPrefix = "[claudesquad]"
+
+// AutoCommitPrefix starts the message of the commits made automatically every interval, so they are easy to find and
+// squash later, e.g. with git rebase -i.
+const AutoCommitPrefix = CommitPrefix + " auto-commit"
+
+// CheckpointPrefix starts the message of the commits made on checkpoint branches.
+const CheckpointPrefix = CommitPrefix + " checkpoint"
+
 // runGitCommand executes a git command and returns any error
 func (g *GitWorktree) runGitCommand(path string, args ...string) (string, error) {
+	return g.runGitCommandWithEnv(nil, path, args...)
+}
+
+// runGitCommandWithEnv is runGitCommand with env, a list of KEY=VALUE entries, added to git's environment.
+func (g *GitWorktree) runGitCommandWithEnv(env []string, path string, args ...string) (string, error) {
 	baseArgs := []string{"-C", path}
 	cmd := exec.Command("git", append(baseArgs, args...)...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
-	output, err := cmd.CombinedOutput()
+	output, err := g.cmdExec.CombinedOutput(cmd)
 	if err != nil {
 		return "", fmt.Errorf("git command failed: %s (%w)", output, err)
 	}
@@ -20,14 +39,21 @@ func (g *GitWorktree) runGitCommand(path string, args ...string) (string, error)
 	return string(output), nil
 }
 
-// PushChanges commits and pushes changes in the worktree to the remote branch
-func (g *GitWorktree) PushChanges(commitMessage string, open bool) error {
+// PushChanges commits and pushes changes in the worktree to the remote branch. If amend is true and CanAmend, the
+// changes amend the last commit instead, and the branch is force-pushed if that commit was already pushed.
+func (g *GitWorktree) PushChanges(commitMessage string, open bool, amend bool) error {
+	if g.inPlace {
+		return errInPlace("push")
+	}
 	if err := checkGHCLI(); err != nil {
 		return err
 	}
 
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+
 	// Check if there are any changes to commit
-	isDirty, err := g.IsDirty()
+	isDirty, err := g.isDirty()
 	if err != nil {
 		return fmt.Errorf("failed to check for changes: %w", err)
 	}
@@ -39,6 +65,16 @@ func (g *GitWorktree) PushChanges(commitMessage string, open bool) error {
 			return fmt.Errorf("failed to stage changes: %w", err)
 		}
 
+		if amend {
+			if amend, err = g.canAmend(); err != nil {
+				log.ErrorLog.Print(err)
+				return err
+			}
+		}
+		if amend {
+			return g.amendAndPush(commitMessage, open)
+		}
+
 		// Create commit
 		if _, err := g.runGitCommand(g.worktreePath, "commit", "-m", commitMessage, "--no-verify"); err != nil {
 			log.ErrorLog.Print(err)
@@ -46,16 +82,19 @@ func (g *GitWorktree) PushChanges(commitMessage string, open bool) error {
 		}
 	}
 
+	return g.pushBranch(open)
+}
+
+// pushBranch pushes the instance branch to the remote and opens it in the browser if open is true.
+func (g *GitWorktree) pushBranch(open bool) error {
 	// First push the branch to remote to ensure it exists
 	pushCmd := exec.Command("gh", "repo", "sync", "--source", "-b", g.branchName)
 	pushCmd.Dir = g.worktreePath
 	if err := pushCmd.Run(); err != nil {
 		// If sync fails, try creating the branch on remote first
-		gitPushCmd := exec.Command("git", "push", "-u", "origin", g.branchName)
-		gitPushCmd.Dir = g.worktreePath
-		if pushOutput, pushErr := gitPushCmd.CombinedOutput(); pushErr != nil {
+		if _, pushErr := g.runGitCommandWithRetry(g.worktreePath, "push", "-u", "origin", g.branchName); pushErr != nil {
 			log.ErrorLog.Print(pushErr)
-			return fmt.Errorf("failed to push branch: %s (%w)", pushOutput, pushErr)
+			return fmt.Errorf("failed to push branch: %w", pushErr)
 		}
 	}
 
@@ -67,44 +106,148 @@ func (g *GitWorktree) PushChanges(commitMessage string, open bool) error {
 		return fmt.Errorf("failed to sync changes: %s (%w)", output, err)
 	}
 
-	// Open the branch in the browser
-	if open {
-		if err := g.OpenBranchURL(); err != nil {
-			// Just log the error but don't fail the push operation
-			log.ErrorLog.Printf("failed to open branch URL: %v", err)
-		}
+	g.openPushedBranch(open)
+	return nil
+}
+
+// amendAndPush amends the last commit with the staged changes and pushes it. If the commit was already pushed,
+// the branch is force-pushed with a lease on the pushed commit, so work pushed from elsewhere since isn't lost.
+func (g *GitWorktree) amendAndPush(commitMessage string, open bool) error {
+	head, err := g.runGitCommand(g.worktreePath, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to read the last commit: %w", err)
 	}
+	remote := g.remoteBranchSHA()
+	pushed := remote != "" && g.isAncestor(strings.TrimSpace(head), remote)
 
+	if _, err := g.runGitCommand(g.worktreePath, "commit", "--amend", "-m", commitMessage, "--no-verify"); err != nil {
+		log.ErrorLog.Print(err)
+		return fmt.Errorf("failed to amend the last commit: %w", err)
+	}
+	if !pushed {
+		return g.pushBranch(open)
+	}
+
+	lease := fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", g.branchName, remote)
+	if _, err := g.runGitCommandWithRetry(g.worktreePath, "push", lease, "origin", g.branchName); err != nil {
+		log.ErrorLog.Print(err)
+		return fmt.Errorf("failed to force-push the amended commit: %w", err)
+	}
+	g.openPushedBranch(open)
 	return nil
 }
 
+// openPushedBranch opens the branch in the browser if open is true. Failing to open it doesn't fail the push.
+func (g *GitWorktree) openPushedBranch(open bool) {
+	if !open {
+		return
+	}
+	if err := g.OpenBranchURL(); err != nil {
+		// Just log the error but don't fail the push operation
+		log.ErrorLog.Printf("failed to open branch URL: %v", err)
+	}
+}
+
+// CanAmend returns whether the last commit on the instance branch was made by claude-squad, so it can be amended
+// instead of adding a commit. Commits the branch was created from, merges and commits with any other message are
+// never amended.
+func (g *GitWorktree) CanAmend() (bool, error) {
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+	return g.canAmend()
+}
+
+// canAmend is CanAmend without taking the lock.
+func (g *GitWorktree) canAmend() (bool, error) {
+	output, err := g.runGitCommand(g.worktreePath, "log", "-1", "--format=%P%n%s", "HEAD")
+	if err != nil {
+		return false, fmt.Errorf("failed to read the last commit: %w", err)
+	}
+	parents, subject, _ := strings.Cut(strings.TrimRight(output, "\n"), "\n")
+	if !isSquadCommit(subject) || len(strings.Fields(parents)) != 1 {
+		return false, nil
+	}
+
+	// The commit must have been made on the instance branch, after it forked from its base.
+	base := g.diffBase(DiffBranch)
+	if base == "" || g.isAncestor("HEAD", base) {
+		return false, nil
+	}
+	return true, nil
+}
+
+// isSquadCommit returns whether subject is the subject of a commit made by claude-squad.
+func isSquadCommit(subject string) bool {
+	return strings.HasPrefix(subject, CommitPrefix+" ")
+}
+
+// isAncestor returns whether commit is an ancestor of, or the same as, descendant.
+func (g *GitWorktree) isAncestor(commit, descendant string) bool {
+	_, err := g.runGitCommand(g.worktreePath, "merge-base", "--is-ancestor", commit, descendant)
+	return err == nil
+}
+
+// remoteBranchSHA returns the commit of the instance branch on origin as of the last fetch, or "" if it isn't
+// there.
+func (g *GitWorktree) remoteBranchSHA() string {
+	output, err := g.runGitCommand(g.worktreePath, "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+g.branchName)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
 // CommitChanges commits changes locally without pushing to remote
 func (g *GitWorktree) CommitChanges(commitMessage string) error {
+	_, err := g.CommitIfDirty(commitMessage)
+	return err
+}
+
+// CommitIfDirty commits all the changes in the worktree locally, like CommitChanges, and returns whether there
+// were any to commit.
+func (g *GitWorktree) CommitIfDirty(commitMessage string) (bool, error) {
+	if g.inPlace {
+		return false, errInPlace("commit")
+	}
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+	return g.commitIfDirty(commitMessage)
+}
+
+// commitIfDirty is CommitIfDirty without taking the lock.
+func (g *GitWorktree) commitIfDirty(commitMessage string) (bool, error) {
 	// Check if there are any changes to commit
-	isDirty, err := g.IsDirty()
+	isDirty, err := g.isDirty()
 	if err != nil {
-		return fmt.Errorf("failed to check for changes: %w", err)
+		return false, fmt.Errorf("failed to check for changes: %w", err)
+	}
+	if !isDirty {
+		return false, nil
 	}
 
-	if isDirty {
-		// Stage all changes
-		if _, err := g.runGitCommand(g.worktreePath, "add", "."); err != nil {
-			log.ErrorLog.Print(err)
-			return fmt.Errorf("failed to stage changes: %w", err)
-		}
-
-		// Create commit (local only)
-		if _, err := g.runGitCommand(g.worktreePath, "commit", "-m", commitMessage, "--no-verify"); err != nil {
-			log.ErrorLog.Print(err)
-			return fmt.Errorf("failed to commit changes: %w", err)
-		}
+	// Stage all changes
+	if _, err := g.runGitCommand(g.worktreePath, "add", "."); err != nil {
+		log.ErrorLog.Print(err)
+		return false, fmt.Errorf("failed to stage changes: %w", err)
 	}
 
-	return nil
+	// Create commit (local only)
+	if _, err := g.runGitCommand(g.worktreePath, "commit", "-m", commitMessage, "--no-verify"); err != nil {
+		log.ErrorLog.Print(err)
+		return false, fmt.Errorf("failed to commit changes: %w", err)
+	}
+	return true, nil
 }
 
 // IsDirty checks if the worktree has uncommitted changes
 func (g *GitWorktree) IsDirty() (bool, error) {
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+	return g.isDirty()
+}
+
+// isDirty is IsDirty without taking the lock.
+func (g *GitWorktree) isDirty() (bool, error) {
 	output, err := g.runGitCommand(g.worktreePath, "status", "--porcelain")
 	if err != nil {
 		return false, fmt.Errorf("failed to check worktree status: %w", err)
@@ -112,8 +255,12 @@ func (g *GitWorktree) IsDirty() (bool, error) {
 	return len(output) > 0, nil
 }
 
-// IsBranchCheckedOut checks if the instance branch is currently checked out
+// IsBranchCheckedOut checks if the instance branch is currently checked out. An in-place instance has no branch of
+// its own, so it is never checked out.
 func (g *GitWorktree) IsBranchCheckedOut() (bool, error) {
+	if g.inPlace {
+		return false, nil
+	}
 	output, err := g.runGitCommand(g.repoPath, "branch", "--show-current")
 	if err != nil {
 		return false, fmt.Errorf("failed to get current branch: %w", err)
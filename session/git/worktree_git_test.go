@@ -0,0 +1,240 @@
+package git
+
+import (
+	"claude-squad/cmd"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSquadCommit(t *testing.T) {
+	assert.True(t, isSquadCommit("[claudesquad] update from 'task' on 01 Jan 25 10:00 UTC"))
+	assert.True(t, isSquadCommit("[claudesquad] update from 'task' on 01 Jan 25 10:00 UTC (paused)"))
+	assert.False(t, isSquadCommit("fix the tests"))
+	assert.False(t, isSquadCommit("[claudesquad]update"))
+	assert.False(t, isSquadCommit("Revert \"[claudesquad] update from 'task'\""))
+	assert.False(t, isSquadCommit(""))
+}
+
+// gitOutput runs git in dir and returns its trimmed output.
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+func TestCanAmend(t *testing.T) {
+	setupGitEnv(t)
+	newWorktree := func(t *testing.T, repo string) (*GitWorktree, string) {
+		worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), repo, "amend")
+		require.NoError(t, err)
+		require.NoError(t, worktree.Setup())
+		t.Cleanup(func() { _ = worktree.Cleanup() })
+		return worktree, worktree.GetWorktreePath()
+	}
+	commit := func(t *testing.T, dir, message string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "work.txt"), []byte(message+"\n"), 0644))
+		runGit(t, dir, "add", ".")
+		runGit(t, dir, "commit", "-q", "-m", message)
+	}
+
+	t.Run("a claude-squad commit on the branch", func(t *testing.T) {
+		worktree, path := newWorktree(t, newTestRepo(t, "main.txt"))
+		commit(t, path, CommitPrefix+" update from 'amend'")
+
+		canAmend, err := worktree.CanAmend()
+		require.NoError(t, err)
+		assert.True(t, canAmend)
+	})
+
+	t.Run("a commit by the user", func(t *testing.T) {
+		worktree, path := newWorktree(t, newTestRepo(t, "main.txt"))
+		commit(t, path, CommitPrefix+" update from 'amend'")
+		commit(t, path, "fix the tests")
+
+		canAmend, err := worktree.CanAmend()
+		require.NoError(t, err)
+		assert.False(t, canAmend)
+	})
+
+	t.Run("no commit on the branch yet", func(t *testing.T) {
+		worktree, _ := newWorktree(t, newTestRepo(t, "main.txt"))
+
+		canAmend, err := worktree.CanAmend()
+		require.NoError(t, err)
+		assert.False(t, canAmend)
+	})
+
+	t.Run("a claude-squad commit the branch was created from", func(t *testing.T) {
+		repo := newTestRepo(t, "main.txt")
+		commit(t, repo, CommitPrefix+" update from 'earlier'")
+		worktree, _ := newWorktree(t, repo)
+
+		canAmend, err := worktree.CanAmend()
+		require.NoError(t, err)
+		assert.False(t, canAmend)
+	})
+
+	t.Run("a merge", func(t *testing.T) {
+		repo := newTestRepo(t, "main.txt")
+		worktree, path := newWorktree(t, repo)
+		runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "upstream work")
+		commit(t, path, "branch work")
+		runGit(t, path, "merge", "-q", "--no-ff", "-m", CommitPrefix+" merge main", "main")
+
+		canAmend, err := worktree.CanAmend()
+		require.NoError(t, err)
+		assert.False(t, canAmend)
+	})
+}
+
+func TestAmendAndPush(t *testing.T) {
+	setupGitEnv(t)
+	origin := filepath.Join(t.TempDir(), "origin.git")
+	runGit(t, t.TempDir(), "init", "-q", "--bare", origin)
+	repo := newTestRepo(t, "main.txt")
+	runGit(t, repo, "remote", "add", "origin", origin)
+	runGit(t, repo, "push", "-q", "origin", "main")
+
+	worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), repo, "amend")
+	require.NoError(t, err)
+	require.NoError(t, worktree.Setup())
+	t.Cleanup(func() { _ = worktree.Cleanup() })
+	path := worktree.GetWorktreePath()
+	branch := worktree.GetBranchName()
+
+	require.NoError(t, os.WriteFile(filepath.Join(path, "work.txt"), []byte("first\n"), 0644))
+	runGit(t, path, "add", ".")
+	runGit(t, path, "commit", "-q", "-m", CommitPrefix+" first")
+	runGit(t, path, "push", "-q", "origin", branch)
+	pushed := gitOutput(t, path, "rev-parse", "HEAD")
+
+	require.NoError(t, os.WriteFile(filepath.Join(path, "work.txt"), []byte("second\n"), 0644))
+	runGit(t, path, "add", ".")
+	require.NoError(t, worktree.amendAndPush(CommitPrefix+" second", false))
+
+	amended := gitOutput(t, path, "rev-parse", "HEAD")
+	assert.NotEqual(t, pushed, amended)
+	assert.Equal(t, amended, gitOutput(t, origin, "rev-parse", branch), "the amended commit is force-pushed")
+	assert.Equal(t, CommitPrefix+" second", gitOutput(t, origin, "log", "-1", "--format=%s", branch))
+	assert.Equal(t, "1", gitOutput(t, origin, "rev-list", "--count", "main.."+branch), "the commit is replaced")
+
+	t.Run("refuses to overwrite work pushed from elsewhere", func(t *testing.T) {
+		other := filepath.Join(t.TempDir(), "other")
+		runGit(t, repo, "clone", "-q", "-b", branch, origin, other)
+		runGit(t, other, "commit", "-q", "--allow-empty", "-m", "pushed from elsewhere")
+		runGit(t, other, "push", "-q", "origin", branch)
+		elsewhere := gitOutput(t, other, "rev-parse", "HEAD")
+
+		require.NoError(t, os.WriteFile(filepath.Join(path, "work.txt"), []byte("third\n"), 0644))
+		runGit(t, path, "add", ".")
+		assert.ErrorContains(t, worktree.amendAndPush(CommitPrefix+" third", false), "failed to force-push")
+		assert.Equal(t, elsewhere, gitOutput(t, origin, "rev-parse", branch))
+	})
+}
+
+// fakeGH puts a gh on the PATH that is logged in and whose repo sync of a new branch fails, so pushes fall back to
+// git push.
+func fakeGH(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncase \"$*\" in\n\"repo sync --source\"*) exit 1 ;;\nesac\nexit 0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gh"), []byte(script), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestConcurrentDiffAndPush(t *testing.T) {
+	setupGitEnv(t)
+	fakeGH(t)
+	origin := filepath.Join(t.TempDir(), "origin.git")
+	runGit(t, t.TempDir(), "init", "-q", "--bare", origin)
+	repo := newTestRepo(t, "main.txt")
+	runGit(t, repo, "remote", "add", "origin", origin)
+	runGit(t, repo, "push", "-q", "origin", "main")
+
+	worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), repo, "concurrent")
+	require.NoError(t, err)
+	require.NoError(t, worktree.Setup())
+	t.Cleanup(func() { _ = worktree.Cleanup() })
+	path := worktree.GetWorktreePath()
+
+	// Diffs run the whole time the pushes do, like the metadata tick while a push is in progress. Each of them
+	// takes the index lock, which would make a push running at the same time fail.
+	const pushes = 5
+	done := make(chan struct{})
+	diffErrs := make(chan error, 1)
+	go func() {
+		defer close(diffErrs)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if stats := worktree.Diff(DiffOptions{}); stats.Error != nil {
+				diffErrs <- stats.Error
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < pushes; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(path, "work.txt"), []byte(strings.Repeat("x\n", i+1)), 0644))
+		require.NoError(t, worktree.PushChanges(CommitPrefix+" push", false, false))
+	}
+	close(done)
+	require.NoError(t, <-diffErrs)
+
+	assert.Equal(t, "5", gitOutput(t, origin, "rev-list", "--count", "main.."+worktree.GetBranchName()))
+	assert.Empty(t, gitOutput(t, path, "status", "--porcelain"))
+}
+
+func TestRefreshesDontWaitForOperations(t *testing.T) {
+	setupGitEnv(t)
+	repo := newTestRepo(t, "main.txt")
+	worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), repo, "busy")
+	require.NoError(t, err)
+	require.NoError(t, worktree.Setup())
+	t.Cleanup(func() { _ = worktree.Cleanup() })
+
+	// Like a push in progress.
+	worktree.opMu.Lock()
+	assert.ErrorIs(t, worktree.TryDiff(DiffOptions{}).Error, ErrBusy)
+	_, err = worktree.Conflicts()
+	assert.ErrorIs(t, err, ErrBusy)
+	worktree.opMu.Unlock()
+
+	assert.NoError(t, worktree.TryDiff(DiffOptions{}).Error)
+	_, err = worktree.Conflicts()
+	assert.NoError(t, err)
+}
+
+func TestPause(t *testing.T) {
+	setupGitEnv(t)
+	repo := newTestRepo(t, "main.txt")
+	worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), repo, "pause")
+	require.NoError(t, err)
+	require.NoError(t, worktree.Setup())
+	t.Cleanup(func() { _ = worktree.Cleanup() })
+	path := worktree.GetWorktreePath()
+	require.NoError(t, os.WriteFile(filepath.Join(path, "work.txt"), []byte("work\n"), 0644))
+
+	detached := false
+	err = worktree.Pause(CommitPrefix+" pause", func() error {
+		detached = true
+		// The worktree stays locked between the commit and the removal.
+		assert.ErrorIs(t, worktree.TryDiff(DiffOptions{}).Error, ErrBusy)
+		return errors.New("no tmux")
+	})
+	assert.ErrorContains(t, err, "failed to detach tmux session: no tmux")
+	assert.True(t, detached)
+	assert.NoDirExists(t, path)
+	assert.Equal(t, CommitPrefix+" pause", gitOutput(t, repo, "log", "-1", "--format=%s", worktree.GetBranchName()))
+}
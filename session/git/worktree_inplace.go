@@ -0,0 +1,69 @@
+package git
+
+import (
+	"claude-squad/cmd"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// NewInPlaceGitWorktree returns the GitWorktree of an instance that runs directly in path, a directory of a git
+// repository, instead of in a worktree of its own. No branch is created: the instance works on whatever is checked
+// out in path, and its diff is against HEAD. It returns the branch checked out in path, or "" on a detached HEAD.
+// Its git commands are run by cmdExec.
+func NewInPlaceGitWorktree(cmdExec cmd.Executor, path string, sessionName string) (*GitWorktree, string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	repoPath, err := findGitRepoRoot(cmdExec, absPath)
+	if err != nil {
+		return nil, "", err
+	}
+	g := &GitWorktree{
+		repoPath:     repoPath,
+		worktreePath: absPath,
+		sessionName:  sessionName,
+		inPlace:      true,
+		cmdExec:      cmdExec,
+	}
+	return g, g.currentBranch(), nil
+}
+
+// SetInPlace marks a worktree loaded from storage as the working directory of an in-place instance.
+func (g *GitWorktree) SetInPlace(inPlace bool) {
+	g.inPlace = inPlace
+}
+
+// IsInPlace returns true if the instance runs directly in a directory of the repository, without a worktree or
+// branch of its own.
+func (g *GitWorktree) IsInPlace() bool {
+	return g.inPlace
+}
+
+// currentBranch returns the branch checked out in the worktree, or "" on a detached HEAD.
+func (g *GitWorktree) currentBranch() string {
+	branch, err := g.runGitCommand(g.worktreePath, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(branch)
+}
+
+// setupInPlace records the commit and branch checked out in the directory of an in-place instance. Nothing is
+// created.
+func (g *GitWorktree) setupInPlace() error {
+	output, err := g.runGitCommand(g.worktreePath, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD commit hash: %w", err)
+	}
+	g.baseCommitSHA = strings.TrimSpace(output)
+	g.branchName = g.currentBranch()
+	return nil
+}
+
+// errInPlace is returned by the operations that need a branch of the instance's own, like pushing it.
+func errInPlace(action string) error {
+	return fmt.Errorf("can't %s an in-place session: it has no branch of its own, use git in its directory instead",
+		action)
+}
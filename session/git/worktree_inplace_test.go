@@ -0,0 +1,59 @@
+package git
+
+import (
+	"claude-squad/cmd"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInPlaceWorktree(t *testing.T) {
+	setupGitEnv(t)
+	repo := newTestRepo(t, "main.txt")
+	subdir := filepath.Join(repo, "pkg")
+	require.NoError(t, os.MkdirAll(subdir, 0755))
+
+	tree, branch, err := NewInPlaceGitWorktree(cmd.MakeExecutor(), subdir, "quick")
+	require.NoError(t, err)
+	assert.True(t, tree.IsInPlace())
+	assert.Equal(t, "main", branch)
+	assert.Equal(t, evalSymlinks(t, repo), tree.GetRepoPath())
+	assert.Equal(t, subdir, tree.GetWorktreePath())
+
+	// Setup creates neither a worktree nor a branch.
+	require.NoError(t, tree.Setup())
+	assert.Equal(t, gitOutput(t, repo, "rev-parse", "HEAD"), tree.GetBaseCommitSHA())
+	assert.Equal(t, "main", tree.GetBranchName())
+	assert.Equal(t, "main", gitOutput(t, repo, "branch", "--format=%(refname:short)"))
+	assert.Len(t, parseWorktreeList(gitOutput(t, repo, "worktree", "list", "--porcelain")), 1)
+
+	// The diff is against HEAD and leaves the user's index alone.
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "main.txt"), []byte("changed\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "new.txt"), []byte("new\n"), 0644))
+	stats := tree.Diff(DiffOptions{})
+	require.NoError(t, stats.Error)
+	assert.Equal(t, 1, stats.Added)
+	assert.Equal(t, 1, stats.Removed)
+	assert.Contains(t, stats.Content, "+changed")
+	assert.NotContains(t, stats.Content, "new.txt")
+	assert.Equal(t, "?? new.txt", gitOutput(t, repo, "status", "--porcelain", "--", "new.txt"))
+
+	// The branch checked out in the repository is the user's, not the instance's.
+	checkedOut, err := tree.IsBranchCheckedOut()
+	require.NoError(t, err)
+	assert.False(t, checkedOut)
+	assert.ErrorContains(t, tree.PushChanges("update", false, false), "can't push an in-place session")
+	assert.ErrorContains(t, tree.CommitChanges("update"), "can't commit an in-place session")
+	_, err = tree.Rebase()
+	assert.ErrorContains(t, err, "can't rebase an in-place session")
+	assert.Error(t, tree.Remove())
+
+	// Cleaning up leaves the directory and its changes where they are.
+	require.NoError(t, tree.Cleanup())
+	assert.DirExists(t, subdir)
+	assert.Equal(t, "changed\n", readFile(t, repo, "main.txt"))
+	assert.Equal(t, "main", gitOutput(t, repo, "branch", "--format=%(refname:short)"))
+}
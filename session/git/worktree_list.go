@@ -0,0 +1,104 @@
+package git
+
+import (
+	"claude-squad/cmd"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// WorktreeEntry is a worktree registered in a repository, as listed by git worktree list.
+type WorktreeEntry struct {
+	Path string
+	// Branch is the checked out branch without refs/heads/, or empty for a detached HEAD.
+	Branch string
+	// Prunable is true if git found the worktree's directory gone, so git worktree prune removes the entry.
+	Prunable bool
+}
+
+// runGitIn runs git in repoPath with cmdExec and returns its combined output.
+func runGitIn(cmdExec cmd.Executor, repoPath string, args ...string) (string, error) {
+	c := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+	output, err := cmdExec.CombinedOutput(c)
+	if err != nil {
+		return "", fmt.Errorf("git command failed: %s (%w)", strings.TrimSpace(string(output)), err)
+	}
+	return string(output), nil
+}
+
+// ListWorktrees returns the worktrees registered in the repository at repoPath, including the main one.
+func ListWorktrees(cmdExec cmd.Executor, repoPath string) ([]WorktreeEntry, error) {
+	output, err := runGitIn(cmdExec, repoPath, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees of %s: %w", repoPath, err)
+	}
+	return parseWorktreeList(output), nil
+}
+
+// parseWorktreeList parses the output of git worktree list --porcelain, which has a block of lines per worktree
+// separated by blank lines.
+func parseWorktreeList(output string) []WorktreeEntry {
+	var entries []WorktreeEntry
+	var current *WorktreeEntry
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			entries = append(entries, WorktreeEntry{Path: filepath.Clean(strings.TrimPrefix(line, "worktree "))})
+			current = &entries[len(entries)-1]
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "branch "):
+			current.Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		case line == "prunable" || strings.HasPrefix(line, "prunable "):
+			current.Prunable = true
+		case line == "":
+			current = nil
+		}
+	}
+	return entries
+}
+
+// PruneWorktrees removes the entries of worktrees whose directory is gone from the repository at repoPath.
+func PruneWorktrees(cmdExec cmd.Executor, repoPath string) error {
+	if _, err := runGitIn(cmdExec, repoPath, "worktree", "prune"); err != nil {
+		return fmt.Errorf("failed to prune worktrees of %s: %w", repoPath, err)
+	}
+	return nil
+}
+
+// RepairWorktree reconnects the worktree directory at worktreePath with the repository at repoPath, e.g. after
+// git lost track of it.
+func RepairWorktree(cmdExec cmd.Executor, repoPath, worktreePath string) error {
+	if _, err := runGitIn(cmdExec, repoPath, "worktree", "repair", worktreePath); err != nil {
+		return fmt.Errorf("failed to repair worktree %s: %w", worktreePath, err)
+	}
+	return nil
+}
+
+// BranchExists returns whether the repository at repoPath has a local branch with the given name. git only lists no
+// such branch if it is really missing. Any other failure, like a repository git can't read, is returned as an
+// error rather than reported as a missing branch.
+func BranchExists(cmdExec cmd.Executor, repoPath, branch string) (bool, error) {
+	ref := "refs/heads/" + branch
+	output, err := runGitIn(cmdExec, repoPath, "for-each-ref", "--format=%(refname)", ref)
+	if err != nil {
+		return false, fmt.Errorf("failed to look for branch %s in %s: %w", branch, repoPath, err)
+	}
+	// The pattern also matches the branches below it, like refs/heads/<branch>/more.
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == ref {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AddWorktree checks out the existing branch in a new worktree at worktreePath.
+func AddWorktree(cmdExec cmd.Executor, repoPath, worktreePath, branch string) error {
+	if _, err := runGitIn(cmdExec, repoPath, "worktree", "add", worktreePath, branch); err != nil {
+		return fmt.Errorf("failed to create worktree from branch %s: %w", branch, err)
+	}
+	return nil
+}
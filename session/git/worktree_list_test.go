@@ -0,0 +1,52 @@
+package git
+
+import (
+	"claude-squad/cmd"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorktreeList(t *testing.T) {
+	output := "worktree /repo\n" +
+		"HEAD 1111111111111111111111111111111111111111\n" +
+		"branch refs/heads/main\n" +
+		"\n" +
+		"worktree /worktrees/feature_1\n" +
+		"HEAD 2222222222222222222222222222222222222222\n" +
+		"branch refs/heads/me/feature\n" +
+		"prunable gitdir file points to non-existent location\n" +
+		"\n" +
+		"worktree /worktrees/detached/\n" +
+		"HEAD 3333333333333333333333333333333333333333\n" +
+		"detached\n" +
+		"\n"
+
+	assert.Equal(t, []WorktreeEntry{
+		{Path: "/repo", Branch: "main"},
+		{Path: "/worktrees/feature_1", Branch: "me/feature", Prunable: true},
+		{Path: "/worktrees/detached"},
+	}, parseWorktreeList(output))
+
+	assert.Empty(t, parseWorktreeList(""))
+}
+
+func TestBranchExists(t *testing.T) {
+	setupGitEnv(t)
+	repo := newTestRepo(t, "main.txt")
+	runGit(t, repo, "branch", "me/feature")
+	exec := cmd.MakeExecutor()
+
+	exists, err := BranchExists(exec, repo, "me/feature")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// Only a branch with the exact name counts, not the ones below it.
+	exists, err = BranchExists(exec, repo, "me")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = BranchExists(exec, t.TempDir(), "me/feature")
+	assert.Error(t, err, "a directory that isn't a repository says nothing about the branch")
+}
@@ -1,7 +1,9 @@
 package git
 
 import (
+	"claude-squad/cmd"
 	"claude-squad/log"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -14,6 +16,13 @@ import (
 
 // Setup creates a new worktree for the session
 func (g *GitWorktree) Setup() error {
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+
+	if g.inPlace {
+		return g.setupInPlace()
+	}
+
 	// Ensure worktrees directory exists early (can be done in parallel with branch check)
 	worktreesDir, err := getWorktreeDirectory()
 	if err != nil {
@@ -51,10 +60,31 @@ func (g *GitWorktree) Setup() error {
 		}
 	}
 
-	if branchExists {
-		return g.setupFromExistingBranch()
+	switch {
+	case g.remote != "":
+		err = g.setupFromRemote()
+	case branchExists:
+		err = g.setupFromExistingBranch()
+	default:
+		err = g.setupNewWorktree()
+	}
+	if err != nil {
+		return err
+	}
+
+	g.initSubmodules()
+	return nil
+}
+
+// initSubmodules checks out the submodules in the worktree if the repository uses them. A failure is only
+// logged, since the worktree is still usable without them (e.g. when a submodule's remote is unreachable).
+func (g *GitWorktree) initSubmodules() {
+	if _, err := os.Stat(filepath.Join(g.worktreePath, ".gitmodules")); err != nil {
+		return
+	}
+	if _, err := g.runGitCommand(g.worktreePath, "submodule", "update", "--init", "--recursive"); err != nil {
+		log.WarningLog.Printf("failed to initialize submodules in %s: %v", g.worktreePath, err)
 	}
-	return g.setupNewWorktree()
 }
 
 // setupFromExistingBranch creates a worktree from an existing branch
@@ -65,7 +95,7 @@ func (g *GitWorktree) setupFromExistingBranch() error {
 	_, _ = g.runGitCommand(g.repoPath, "worktree", "remove", "-f", g.worktreePath) // Ignore error if worktree doesn't exist
 
 	// Create a new worktree from the existing branch
-	if _, err := g.runGitCommand(g.repoPath, "worktree", "add", g.worktreePath, g.branchName); err != nil {
+	if _, err := g.runGitCommandWithRetry(g.repoPath, "worktree", "add", g.worktreePath, g.branchName); err != nil {
 		return fmt.Errorf("failed to create worktree from branch %s: %w", g.branchName, err)
 	}
 
@@ -105,20 +135,32 @@ func (g *GitWorktree) setupNewWorktree() error {
 	}
 	headCommit := strings.TrimSpace(string(output))
 	g.baseCommitSHA = headCommit
+	// Remember which branch the worktree came from so its diff can be compared against it. A detached HEAD
+	// has no branch, in which case the diff falls back to the base commit.
+	if branch, err := g.runGitCommand(g.repoPath, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		g.baseBranch = strings.TrimSpace(branch)
+	}
 
 	// Create a new worktree from the HEAD commit
 	// Otherwise, we'll inherit uncommitted changes from the previous worktree.
 	// This way, we can start the worktree with a clean slate.
 	// TODO: we might want to give an option to use main/master instead of the current branch.
-	if _, err := g.runGitCommand(g.repoPath, "worktree", "add", "-b", g.branchName, g.worktreePath, headCommit); err != nil {
+	if _, err := g.runGitCommandWithRetry(g.repoPath, "worktree", "add", "-b", g.branchName, g.worktreePath, headCommit); err != nil {
 		return fmt.Errorf("failed to create worktree from commit %s: %w", headCommit, err)
 	}
 
 	return nil
 }
 
-// Cleanup removes the worktree and associated branch
+// Cleanup removes the worktree and associated branch. The directory of an in-place instance is left alone.
 func (g *GitWorktree) Cleanup() error {
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+
+	if g.inPlace {
+		return nil
+	}
+
 	var errs []error
 
 	// Check if worktree path exists before attempting removal
@@ -151,7 +193,7 @@ func (g *GitWorktree) Cleanup() error {
 	}
 
 	// Prune the worktree to clean up any remaining references
-	if err := g.Prune(); err != nil {
+	if err := g.prune(); err != nil {
 		errs = append(errs, err)
 	}
 
@@ -162,8 +204,19 @@ func (g *GitWorktree) Cleanup() error {
 	return nil
 }
 
-// Remove removes the worktree but keeps the branch
+// Remove removes the worktree but keeps the branch. The directory of an in-place instance can't be removed.
 func (g *GitWorktree) Remove() error {
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+	return g.remove()
+}
+
+// remove is Remove without taking the lock.
+func (g *GitWorktree) remove() error {
+	if g.inPlace {
+		return errInPlace("remove the worktree of")
+	}
+
 	// Remove the worktree using git command
 	if _, err := g.runGitCommand(g.repoPath, "worktree", "remove", "-f", g.worktreePath); err != nil {
 		return fmt.Errorf("failed to remove worktree: %w", err)
@@ -174,14 +227,55 @@ func (g *GitWorktree) Remove() error {
 
 // Prune removes all working tree administrative files and directories
 func (g *GitWorktree) Prune() error {
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+	return g.prune()
+}
+
+// prune is Prune without taking the lock.
+func (g *GitWorktree) prune() error {
 	if _, err := g.runGitCommand(g.repoPath, "worktree", "prune"); err != nil {
 		return fmt.Errorf("failed to prune worktrees: %w", err)
 	}
 	return nil
 }
 
-// CleanupWorktrees removes all worktrees and their associated branches
-func CleanupWorktrees() error {
+// Pause commits the changes in the worktree locally if there are any, calls detach, then removes the worktree and
+// prunes, keeping the branch. The worktree stays locked for the whole sequence, so nothing else runs git in it
+// halfway through. A failing detach doesn't stop the pause; its error is returned along with any later one.
+func (g *GitWorktree) Pause(commitMessage string, detach func() error) error {
+	if g.inPlace {
+		return errInPlace("pause")
+	}
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+
+	if _, err := g.commitIfDirty(commitMessage); err != nil {
+		// Stop before removing anything so the changes aren't lost
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	var detachErr error
+	if err := detach(); err != nil {
+		detachErr = fmt.Errorf("failed to detach tmux session: %w", err)
+	}
+
+	// The worktree may already be gone, e.g. deleted by hand
+	if _, err := os.Stat(g.worktreePath); err == nil {
+		// Remove worktree but keep branch
+		if err := g.remove(); err != nil {
+			return errors.Join(detachErr, fmt.Errorf("failed to remove git worktree: %w", err))
+		}
+		// Only prune if remove was successful
+		if err := g.prune(); err != nil {
+			return errors.Join(detachErr, fmt.Errorf("failed to prune git worktrees: %w", err))
+		}
+	}
+	return detachErr
+}
+
+// CleanupWorktrees removes all worktrees and their associated branches. Git is run by cmdExec.
+func CleanupWorktrees(cmdExec cmd.Executor) error {
 	worktreesDir, err := getWorktreeDirectory()
 	if err != nil {
 		return fmt.Errorf("failed to get worktree directory: %w", err)
@@ -193,8 +287,7 @@ func CleanupWorktrees() error {
 	}
 
 	// Get a list of all branches associated with worktrees
-	cmd := exec.Command("git", "worktree", "list", "--porcelain")
-	output, err := cmd.Output()
+	output, err := cmdExec.Output(exec.Command("git", "worktree", "list", "--porcelain"))
 	if err != nil {
 		return fmt.Errorf("failed to list worktrees: %w", err)
 	}
@@ -224,8 +317,7 @@ func CleanupWorktrees() error {
 			for path, branch := range worktreeBranches {
 				if strings.Contains(path, entry.Name()) {
 					// Delete the branch
-					deleteCmd := exec.Command("git", "branch", "-D", branch)
-					if err := deleteCmd.Run(); err != nil {
+					if err := cmdExec.Run(exec.Command("git", "branch", "-D", branch)); err != nil {
 						// Log the error but continue with other worktrees
 						log.ErrorLog.Printf("failed to delete branch %s: %v", branch, err)
 					}
@@ -239,9 +331,7 @@ func CleanupWorktrees() error {
 	}
 
 	// You have to prune the cleaned up worktrees.
-	cmd = exec.Command("git", "worktree", "prune")
-	_, err = cmd.Output()
-	if err != nil {
+	if _, err := cmdExec.Output(exec.Command("git", "worktree", "prune")); err != nil {
 		return fmt.Errorf("failed to prune worktrees: %w", err)
 	}
 
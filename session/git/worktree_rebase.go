@@ -0,0 +1,165 @@
+package git
+
+import (
+	"claude-squad/log"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RebaseConflictError is returned by Rebase when the rebase stopped at conflicts. The worktree is left in the
+// middle of the rebase, so the conflicts can be resolved there.
+type RebaseConflictError struct {
+	// Onto is the branch that was rebased onto.
+	Onto string
+	// Files are the files with conflicts.
+	Files []string
+}
+
+func (e *RebaseConflictError) Error() string {
+	return fmt.Sprintf("rebasing onto %s stopped at conflicts in %s: attach to resolve them and run git rebase --continue",
+		e.Onto, strings.Join(e.Files, ", "))
+}
+
+// RebaseTarget returns the branch Rebase rebases onto, and the remote and branch to fetch first so it is up to
+// date. The remote is empty if the base branch is a local branch without an upstream, which needs no fetch. A
+// local base branch with an upstream is rebased onto the upstream, since fetching doesn't update the local branch.
+func (g *GitWorktree) RebaseTarget() (remote, branch, onto string, err error) {
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+	return g.rebaseTarget()
+}
+
+// rebaseTarget is RebaseTarget without taking the lock.
+func (g *GitWorktree) rebaseTarget() (remote, branch, onto string, err error) {
+	if g.inPlace {
+		return "", "", "", errInPlace("rebase")
+	}
+	if g.baseBranch == "" {
+		return "", "", "", fmt.Errorf("the branch %s was created from isn't known", g.branchName)
+	}
+	if _, err := g.runGitCommand(g.worktreePath, "rev-parse", "--verify", "--quiet",
+		"refs/remotes/"+g.baseBranch); err == nil {
+		remote, branch, err := ParseRemoteRef(g.baseBranch)
+		return remote, branch, g.baseBranch, err
+	}
+	upstream, err := g.runGitCommand(g.worktreePath, "rev-parse", "--abbrev-ref", "--symbolic-full-name",
+		g.baseBranch+"@{upstream}")
+	if err != nil {
+		return "", "", g.baseBranch, nil
+	}
+	onto = strings.TrimSpace(upstream)
+	remote, branch, err = ParseRemoteRef(onto)
+	return remote, branch, onto, err
+}
+
+// rebaseCommands returns the git commands that rebase the instance branch onto onto, fetching branch from remote
+// first unless remote is empty. Uncommitted changes are stashed during the rebase and applied again after it.
+func rebaseCommands(remote, branch, onto string) [][]string {
+	var commands [][]string
+	if remote != "" {
+		commands = append(commands, []string{"fetch", remote, branch})
+	}
+	return append(commands, []string{"rebase", "--autostash", onto})
+}
+
+// Rebase fetches the base branch and rebases the instance branch onto it. It returns the branch it rebased onto,
+// which becomes the base branch the diff is computed against. If the rebase stops at conflicts, it returns a
+// *RebaseConflictError and leaves the rebase in progress. Other failures abort the rebase.
+func (g *GitWorktree) Rebase() (string, error) {
+	if g.inPlace {
+		return "", errInPlace("rebase")
+	}
+	g.opMu.Lock()
+	defer g.opMu.Unlock()
+
+	remote, branch, onto, err := g.rebaseTarget()
+	if err != nil {
+		return "", err
+	}
+
+	for _, args := range rebaseCommands(remote, branch, onto) {
+		run := g.runGitCommand
+		if args[0] == "fetch" {
+			run = g.runGitCommandWithRetry
+		}
+		_, err := run(g.worktreePath, args...)
+		if err == nil {
+			continue
+		}
+		if args[0] == "fetch" {
+			// Rebase onto what was fetched before, e.g. when working offline.
+			log.WarningLog.Printf("failed to fetch %s, rebasing onto the last fetched commit: %v", onto, err)
+			continue
+		}
+		if files := g.unmergedFiles(); len(files) > 0 {
+			return "", &RebaseConflictError{Onto: onto, Files: files}
+		}
+		if _, abortErr := g.runGitCommand(g.worktreePath, "rebase", "--abort"); abortErr != nil {
+			log.WarningLog.Printf("could not abort the rebase of %s: %v", g.branchName, abortErr)
+		}
+		return "", fmt.Errorf("failed to rebase onto %s: %w", onto, err)
+	}
+
+	g.baseBranch = onto
+	return onto, nil
+}
+
+// unmergedFiles returns the files with unresolved conflicts in the worktree.
+func (g *GitWorktree) unmergedFiles() []string {
+	output, err := g.runGitCommand(g.worktreePath, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files
+}
+
+// inProgressMarkers maps the files git keeps while an operation is stopped, e.g. at conflicts, to the operation.
+var inProgressMarkers = []struct {
+	path      string
+	operation string
+}{
+	{"rebase-merge", "rebase"},
+	{"rebase-apply", "rebase"},
+	{"MERGE_HEAD", "merge"},
+	{"CHERRY_PICK_HEAD", "cherry-pick"},
+	{"REVERT_HEAD", "revert"},
+}
+
+// InProgressOperation returns the git operation that stopped in the worktree and waits to be continued or aborted,
+// like "rebase" or "merge", or "" if there is none. Files with unresolved conflicts count as a "merge". Nothing
+// should be committed automatically while one is in progress, or the conflicts would be committed.
+func (g *GitWorktree) InProgressOperation() (string, error) {
+	args := []string{"rev-parse"}
+	for _, marker := range inProgressMarkers {
+		args = append(args, "--git-path", marker.path)
+	}
+	output, err := g.runGitCommand(g.worktreePath, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the git directory: %w", err)
+	}
+	paths := strings.Split(strings.TrimSpace(output), "\n")
+	for i, marker := range inProgressMarkers {
+		if i >= len(paths) {
+			break
+		}
+		path := paths[i]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(g.worktreePath, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return marker.operation, nil
+		}
+	}
+	if len(g.unmergedFiles()) > 0 {
+		return "merge", nil
+	}
+	return "", nil
+}
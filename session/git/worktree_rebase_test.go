@@ -0,0 +1,113 @@
+package git
+
+import (
+	"claude-squad/cmd"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebaseCommands(t *testing.T) {
+	tests := []struct {
+		name   string
+		remote string
+		branch string
+		onto   string
+		want   [][]string
+	}{
+		{
+			name:   "remote base branch",
+			remote: "origin",
+			branch: "main",
+			onto:   "origin/main",
+			want:   [][]string{{"fetch", "origin", "main"}, {"rebase", "--autostash", "origin/main"}},
+		},
+		{
+			name:   "branch with slashes",
+			remote: "upstream",
+			branch: "release/1.2",
+			onto:   "upstream/release/1.2",
+			want: [][]string{
+				{"fetch", "upstream", "release/1.2"},
+				{"rebase", "--autostash", "upstream/release/1.2"},
+			},
+		},
+		{
+			name: "local base branch",
+			onto: "main",
+			want: [][]string{{"rebase", "--autostash", "main"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rebaseCommands(tt.remote, tt.branch, tt.onto))
+		})
+	}
+}
+
+func TestRebase(t *testing.T) {
+	setupGitEnv(t)
+	origin := filepath.Join(t.TempDir(), "origin.git")
+	runGit(t, t.TempDir(), "init", "-q", "--bare", origin)
+	repo := newTestRepo(t, "main.txt")
+	runGit(t, repo, "remote", "add", "origin", origin)
+	runGit(t, repo, "push", "-q", "-u", "origin", "main")
+
+	worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), repo, "rebase")
+	require.NoError(t, err)
+	require.NoError(t, worktree.Setup())
+	t.Cleanup(func() { _ = worktree.Cleanup() })
+	path := worktree.GetWorktreePath()
+
+	remote, branch, onto, err := worktree.RebaseTarget()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"origin", "main", "origin/main"}, []string{remote, branch, onto},
+		"a local base branch is rebased onto its upstream")
+
+	other := filepath.Join(t.TempDir(), "other")
+	runGit(t, repo, "clone", "-q", "-b", "main", origin, other)
+	pushToMain := func(file, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(other, file), []byte(content), 0644))
+		runGit(t, other, "add", ".")
+		runGit(t, other, "commit", "-q", "-m", "change "+file)
+		runGit(t, other, "push", "-q", "origin", "main")
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(path, "work.txt"), []byte("from the branch\n"), 0644))
+	runGit(t, path, "add", ".")
+	runGit(t, path, "commit", "-q", "-m", "work")
+	require.NoError(t, os.WriteFile(filepath.Join(path, "main.txt"), []byte("uncommitted\n"), 0644))
+
+	pushToMain("other.txt", "from main\n")
+	onto, err = worktree.Rebase()
+	require.NoError(t, err)
+	assert.Equal(t, "origin/main", onto)
+	assert.Equal(t, "origin/main", worktree.GetBaseBranch())
+	assert.FileExists(t, filepath.Join(path, "other.txt"), "the fetched commit is rebased onto")
+	assert.Equal(t, "work", gitOutput(t, path, "log", "-1", "--format=%s"))
+	content, err := os.ReadFile(filepath.Join(path, "main.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "uncommitted\n", string(content), "uncommitted changes are kept")
+	operation, err := worktree.InProgressOperation()
+	require.NoError(t, err)
+	assert.Empty(t, operation)
+
+	t.Run("stops at conflicts", func(t *testing.T) {
+		pushToMain("work.txt", "from main\n")
+		_, err := worktree.Rebase()
+
+		var conflictErr *RebaseConflictError
+		require.True(t, errors.As(err, &conflictErr), "got %v", err)
+		assert.Equal(t, []string{"work.txt"}, conflictErr.Files)
+		assert.Equal(t, "origin/main", conflictErr.Onto)
+		assert.DirExists(t, gitOutput(t, path, "rev-parse", "--path-format=absolute", "--git-path", "rebase-merge"),
+			"the rebase is left in progress")
+		operation, err := worktree.InProgressOperation()
+		require.NoError(t, err)
+		assert.Equal(t, "rebase", operation)
+	})
+}
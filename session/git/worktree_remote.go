@@ -0,0 +1,77 @@
+package git
+
+import (
+	"claude-squad/cmd"
+	"claude-squad/log"
+	"fmt"
+	"strings"
+)
+
+// ParseRemoteRef splits a remote branch like origin/feature-x into the remote and the branch name.
+func ParseRemoteRef(ref string) (remote, branch string, err error) {
+	ref = strings.TrimPrefix(strings.TrimSpace(ref), "refs/remotes/")
+	remote, branch, ok := strings.Cut(ref, "/")
+	if !ok || remote == "" || branch == "" {
+		return "", "", fmt.Errorf("invalid remote branch %q: expected <remote>/<branch>, e.g. origin/feature-x", ref)
+	}
+	return remote, branch, nil
+}
+
+// NewGitWorktreeFromRemote creates a GitWorktree that checks out the remote branch remoteRef (e.g.
+// origin/feature-x) in a local branch of the same name tracking it. The local branch must not exist yet, since
+// it is deleted with the worktree. Its git commands are run by cmdExec.
+func NewGitWorktreeFromRemote(cmdExec cmd.Executor, repoPath, sessionName, remoteRef string) (tree *GitWorktree, branchname string, err error) {
+	remote, branch, err := ParseRemoteRef(remoteRef)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tree, _, err = NewGitWorktree(cmdExec, repoPath, sessionName)
+	if err != nil {
+		return nil, "", err
+	}
+	tree.branchName = branch
+	tree.remote = remote
+
+	if _, err := tree.runGitCommand(tree.repoPath, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		return nil, "", fmt.Errorf("a local branch named %s already exists: delete or rename it to check out %s",
+			branch, remoteRef)
+	}
+	return tree, branch, nil
+}
+
+// setupFromRemote fetches the remote branch and creates the worktree on a new local branch tracking it. The
+// remote branch is the base of the diff, so only changes made in the worktree are shown.
+func (g *GitWorktree) setupFromRemote() error {
+	remoteRef := g.remote + "/" + g.branchName
+
+	_, fetchErr := g.runGitCommandWithRetry(g.repoPath, "fetch", g.remote, g.branchName)
+	if fetchErr != nil && strings.Contains(fetchErr.Error(), "couldn't find remote ref") {
+		return fmt.Errorf("remote branch %s does not exist", remoteRef)
+	}
+
+	output, err := g.runGitCommand(g.repoPath, "rev-parse", "--verify", "--quiet", "refs/remotes/"+remoteRef)
+	if err != nil {
+		if fetchErr != nil {
+			return fmt.Errorf("failed to fetch %s: %w", remoteRef, fetchErr)
+		}
+		return fmt.Errorf("remote branch %s does not exist", remoteRef)
+	}
+	if fetchErr != nil {
+		// The branch was fetched before, e.g. when working offline, so check out what we have.
+		log.WarningLog.Printf("failed to fetch %s, using the last fetched commit: %v", remoteRef, fetchErr)
+	}
+	g.baseCommitSHA = strings.TrimSpace(output)
+	g.baseBranch = remoteRef
+
+	// Clean up any existing worktree first
+	_, _ = g.runGitCommand(g.repoPath, "worktree", "remove", "-f", g.worktreePath) // Ignore error if worktree doesn't exist
+
+	if _, err := g.runGitCommandWithRetry(g.repoPath, "worktree", "add", "--track", "-b", g.branchName, g.worktreePath, remoteRef); err != nil {
+		return fmt.Errorf("failed to create worktree from %s: %w", remoteRef, err)
+	}
+
+	// The local branch now exists, so later setups (e.g. resuming) check it out like any other branch.
+	g.remote = ""
+	return nil
+}
@@ -0,0 +1,151 @@
+package git
+
+import (
+	"claude-squad/cmd"
+	"claude-squad/cmd/cmd_test"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemoteRef(t *testing.T) {
+	tests := []struct {
+		ref    string
+		remote string
+		branch string
+	}{
+		{ref: "origin/feature-x", remote: "origin", branch: "feature-x"},
+		{ref: "upstream/user/fix", remote: "upstream", branch: "user/fix"},
+		{ref: " refs/remotes/origin/main\n", remote: "origin", branch: "main"},
+	}
+	for _, tt := range tests {
+		remote, branch, err := ParseRemoteRef(tt.ref)
+		require.NoError(t, err, tt.ref)
+		assert.Equal(t, tt.remote, remote)
+		assert.Equal(t, tt.branch, branch)
+	}
+
+	for _, ref := range []string{"", "feature-x", "origin/", "/feature-x"} {
+		_, _, err := ParseRemoteRef(ref)
+		assert.ErrorContains(t, err, "invalid remote branch", ref)
+	}
+}
+
+func TestSetupFromRemoteCommands(t *testing.T) {
+	var commands []string
+	g := &GitWorktree{
+		repoPath:     "/repo",
+		worktreePath: "/worktrees/feature-x_1",
+		branchName:   "feature-x",
+		remote:       "origin",
+		cmdExec: cmd_test.MockCmdExec{
+			CombinedOutputFunc: func(c *exec.Cmd) ([]byte, error) {
+				args := strings.Join(c.Args[3:], " ")
+				commands = append(commands, args)
+				if strings.HasPrefix(args, "rev-parse") {
+					return []byte("abc123\n"), nil
+				}
+				return nil, nil
+			},
+		},
+	}
+
+	require.NoError(t, g.setupFromRemote())
+	assert.Equal(t, []string{
+		"fetch origin feature-x",
+		"rev-parse --verify --quiet refs/remotes/origin/feature-x",
+		"worktree remove -f /worktrees/feature-x_1",
+		"worktree add --track -b feature-x /worktrees/feature-x_1 origin/feature-x",
+	}, commands)
+	assert.Equal(t, "abc123", g.GetBaseCommitSHA())
+	assert.Equal(t, "origin/feature-x", g.GetBaseBranch())
+	assert.Empty(t, g.remote)
+}
+
+func TestSetupFromRemoteErrors(t *testing.T) {
+	newWorktree := func(combinedOutput func(args string) ([]byte, error)) *GitWorktree {
+		return &GitWorktree{
+			repoPath:     "/repo",
+			worktreePath: "/worktrees/feature-x_1",
+			branchName:   "feature-x",
+			remote:       "origin",
+			cmdExec: cmd_test.MockCmdExec{
+				CombinedOutputFunc: func(c *exec.Cmd) ([]byte, error) {
+					return combinedOutput(strings.Join(c.Args[3:], " "))
+				},
+			},
+		}
+	}
+
+	t.Run("missing remote branch", func(t *testing.T) {
+		g := newWorktree(func(args string) ([]byte, error) {
+			if strings.HasPrefix(args, "fetch") {
+				return []byte("fatal: couldn't find remote ref feature-x"), fmt.Errorf("exit status 128")
+			}
+			return nil, nil
+		})
+		assert.EqualError(t, g.setupFromRemote(), "remote branch origin/feature-x does not exist")
+	})
+
+	t.Run("fetch fails without a fetched copy", func(t *testing.T) {
+		g := newWorktree(func(args string) ([]byte, error) {
+			return []byte("fatal: unable to access remote"), fmt.Errorf("exit status 128")
+		})
+		assert.ErrorContains(t, g.setupFromRemote(), "failed to fetch origin/feature-x")
+	})
+
+	t.Run("fetch fails with a fetched copy", func(t *testing.T) {
+		g := newWorktree(func(args string) ([]byte, error) {
+			switch {
+			case strings.HasPrefix(args, "fetch"):
+				return []byte("fatal: unable to access remote"), fmt.Errorf("exit status 128")
+			case strings.HasPrefix(args, "rev-parse"):
+				return []byte("abc123\n"), nil
+			}
+			return nil, nil
+		})
+		assert.NoError(t, g.setupFromRemote())
+	})
+}
+
+func TestWorktreeSetupFromRemote(t *testing.T) {
+	setupGitEnv(t)
+
+	origin := newTestRepo(t, "main.txt")
+	runGit(t, origin, "checkout", "-q", "-b", "feature-x")
+	runGit(t, origin, "commit", "-q", "--allow-empty", "-m", "feature")
+	runGit(t, origin, "checkout", "-q", "main")
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	runGit(t, origin, "clone", "-q", origin, clone)
+
+	t.Run("checks out a local branch tracking the remote branch", func(t *testing.T) {
+		worktree, branch, err := NewGitWorktreeFromRemote(cmd.MakeExecutor(), clone, "review", "origin/feature-x")
+		require.NoError(t, err)
+		assert.Equal(t, "feature-x", branch)
+		require.NoError(t, worktree.Setup())
+		t.Cleanup(func() { _ = worktree.Cleanup() })
+
+		assert.FileExists(t, filepath.Join(worktree.GetWorktreePath(), "main.txt"))
+		assert.Equal(t, "origin/feature-x", worktree.GetBaseBranch())
+
+		upstream, err := exec.Command("git", "-C", worktree.GetWorktreePath(),
+			"rev-parse", "--abbrev-ref", "@{upstream}").Output()
+		require.NoError(t, err)
+		assert.Equal(t, "origin/feature-x", strings.TrimSpace(string(upstream)))
+
+		_, _, err = NewGitWorktreeFromRemote(cmd.MakeExecutor(), clone, "review-again", "origin/feature-x")
+		assert.ErrorContains(t, err, "a local branch named feature-x already exists")
+	})
+
+	t.Run("errors for a missing remote branch", func(t *testing.T) {
+		worktree, _, err := NewGitWorktreeFromRemote(cmd.MakeExecutor(), clone, "missing", "origin/missing")
+		require.NoError(t, err)
+		assert.EqualError(t, worktree.Setup(), "remote branch origin/missing does not exist")
+	})
+}
@@ -0,0 +1,191 @@
+package git
+
+import (
+	"claude-squad/cmd"
+	"claude-squad/cmd/cmd_test"
+	"claude-squad/log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain runs before all tests to set up the test environment
+func TestMain(m *testing.M) {
+	// Initialize the logger before any tests run
+	log.Initialize(false)
+	defer log.Close()
+
+	os.Exit(m.Run())
+}
+
+// setupGitEnv isolates git and the config directory from the user's environment. Local submodule remotes
+// need the file protocol, which git disallows for submodules by default.
+func setupGitEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+// newTestRepo creates a repository with one committed file.
+func newTestRepo(t *testing.T, file string) string {
+	t.Helper()
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte("content\n"), 0644))
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+// newRepoWithSubmodule creates a repository with a submodule at libs/sub.
+func newRepoWithSubmodule(t *testing.T) string {
+	t.Helper()
+	sub := newTestRepo(t, "sub.txt")
+	repo := newTestRepo(t, "main.txt")
+	runGit(t, repo, "submodule", "add", "-q", sub, "libs/sub")
+	runGit(t, repo, "commit", "-q", "-m", "add submodule")
+	return repo
+}
+
+func TestFindGitRepoRoot(t *testing.T) {
+	setupGitEnv(t)
+
+	t.Run("resolves subdirectories to the repo root", func(t *testing.T) {
+		repo := newTestRepo(t, "main.txt")
+		subdir := filepath.Join(repo, "pkg", "nested")
+		require.NoError(t, os.MkdirAll(subdir, 0755))
+
+		root, err := findGitRepoRoot(cmd.MakeExecutor(), subdir)
+		require.NoError(t, err)
+		assert.Equal(t, evalSymlinks(t, repo), root)
+	})
+
+	t.Run("resolves bare repositories to the repository directory", func(t *testing.T) {
+		repo := newTestRepo(t, "main.txt")
+		bare := filepath.Join(t.TempDir(), "bare.git")
+		runGit(t, repo, "clone", "-q", "--bare", repo, bare)
+
+		root, err := findGitRepoRoot(cmd.MakeExecutor(), bare)
+		require.NoError(t, err)
+		assert.Equal(t, evalSymlinks(t, bare), root)
+	})
+
+	t.Run("errors outside a repository", func(t *testing.T) {
+		_, err := findGitRepoRoot(cmd.MakeExecutor(), t.TempDir())
+		assert.ErrorContains(t, err, "not a git repository")
+		assert.False(t, IsGitRepo(t.TempDir()))
+	})
+
+	t.Run("runs git with the executor", func(t *testing.T) {
+		var commands []string
+		fake := cmd_test.MockCmdExec{OutputFunc: func(c *exec.Cmd) ([]byte, error) {
+			commands = append(commands, strings.Join(c.Args[3:], " "))
+			if c.Args[len(c.Args)-1] == "--is-bare-repository" {
+				return []byte("false\n"), nil
+			}
+			return []byte("/fake/repo\n"), nil
+		}}
+
+		root, err := findGitRepoRoot(fake, "/fake/repo/pkg")
+		require.NoError(t, err)
+		assert.Equal(t, "/fake/repo", root)
+		assert.Equal(t, []string{"rev-parse --is-bare-repository", "rev-parse --show-toplevel"}, commands)
+	})
+
+	t.Run("falls back to the directory outside a repository", func(t *testing.T) {
+		repo := newTestRepo(t, "main.txt")
+		subdir := filepath.Join(repo, "cmd")
+		require.NoError(t, os.MkdirAll(subdir, 0755))
+		assert.Equal(t, evalSymlinks(t, repo), RepoRootOrDir(subdir))
+
+		dir := t.TempDir()
+		assert.Equal(t, dir, RepoRootOrDir(dir))
+	})
+}
+
+func TestWorktreeSetup(t *testing.T) {
+	setupGitEnv(t)
+
+	t.Run("from a subdirectory branches from the repo root", func(t *testing.T) {
+		repo := newTestRepo(t, "main.txt")
+		subdir := filepath.Join(repo, "pkg")
+		require.NoError(t, os.MkdirAll(subdir, 0755))
+
+		worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), subdir, "from-subdir")
+		require.NoError(t, err)
+		require.NoError(t, worktree.Setup())
+		t.Cleanup(func() { _ = worktree.Cleanup() })
+
+		assert.Equal(t, evalSymlinks(t, repo), worktree.GetRepoPath())
+		assert.FileExists(t, filepath.Join(worktree.GetWorktreePath(), "main.txt"))
+		assert.Equal(t, "main", worktree.GetBaseBranch())
+	})
+
+	t.Run("from a detached HEAD has no base branch", func(t *testing.T) {
+		repo := newTestRepo(t, "main.txt")
+		runGit(t, repo, "checkout", "-q", "--detach")
+
+		worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), repo, "from-detached")
+		require.NoError(t, err)
+		require.NoError(t, worktree.Setup())
+		t.Cleanup(func() { _ = worktree.Cleanup() })
+
+		assert.Empty(t, worktree.GetBaseBranch())
+		assert.NotEmpty(t, worktree.GetBaseCommitSHA())
+	})
+
+	t.Run("initializes submodules", func(t *testing.T) {
+		repo := newRepoWithSubmodule(t)
+
+		worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), repo, "with-submodule")
+		require.NoError(t, err)
+		require.NoError(t, worktree.Setup())
+		t.Cleanup(func() { _ = worktree.Cleanup() })
+
+		assert.FileExists(t, filepath.Join(worktree.GetWorktreePath(), "libs", "sub", "sub.txt"))
+	})
+
+	t.Run("from a bare repository", func(t *testing.T) {
+		repo := newTestRepo(t, "main.txt")
+		bare := filepath.Join(t.TempDir(), "bare.git")
+		runGit(t, repo, "clone", "-q", "--bare", repo, bare)
+
+		worktree, _, err := NewGitWorktree(cmd.MakeExecutor(), bare, "from-bare")
+		require.NoError(t, err)
+		require.NoError(t, worktree.Setup())
+		t.Cleanup(func() { _ = worktree.Cleanup() })
+
+		assert.FileExists(t, filepath.Join(worktree.GetWorktreePath(), "main.txt"))
+	})
+
+	t.Run("errors outside a repository", func(t *testing.T) {
+		_, _, err := NewGitWorktree(cmd.MakeExecutor(), t.TempDir(), "no-repo")
+		assert.ErrorContains(t, err, "not a git repository")
+	})
+}
+
+// evalSymlinks resolves symlinks in path, since git reports real paths (e.g. /private/tmp on macOS).
+func evalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	require.NoError(t, err)
+	return resolved
+}
@@ -0,0 +1,108 @@
+package session
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// maxOutputHistoryLines is the number of output lines kept per instance. Older lines are dropped.
+	maxOutputHistoryLines = 10000
+	// historyAnchorLines is the number of lines at the start of a capture that must match the retained history
+	// for the capture to be merged at that position. Enough lines are needed that repeated output, like blank lines
+	// or separators, doesn't match in the wrong place.
+	historyAnchorLines = 20
+	// historyCaptureLines is the number of scrollback lines captured once the history has been seeded with the
+	// whole scrollback. Only the end of the scrollback changes between captures, so there is no need to capture
+	// all of it each time.
+	historyCaptureLines = 1000
+)
+
+// OutputHistory is a bounded, in-memory history of an instance's pane output. It keeps output that scrolled
+// past tmux's own scrollback by merging successive captures of the pane.
+type OutputHistory struct {
+	mu       sync.Mutex
+	lines    []string
+	maxLines int
+	// version changes whenever the lines change, so renderers can skip unchanged history.
+	version int
+}
+
+// NewOutputHistory returns an empty history that keeps at most maxLines lines.
+func NewOutputHistory(maxLines int) *OutputHistory {
+	return &OutputHistory{maxLines: maxLines}
+}
+
+// Merge adds a capture of the pane's scrollback and screen to the history. Lines that tmux has dropped since
+// the previous capture are kept, and the lines still in the capture replace their earlier version, since the
+// program may have redrawn them.
+func (h *OutputHistory) Merge(capture string) {
+	lines := strings.Split(strings.TrimRight(capture, "\n"), "\n")
+	// The screen below the cursor is blank.
+	for len(lines) > 0 && strings.TrimSpace(ansi.Strip(lines[len(lines)-1])) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	start := overlapStart(h.lines, lines)
+	merged := append(h.lines[:start:start], lines...)
+	if len(merged) > h.maxLines {
+		merged = merged[len(merged)-h.maxLines:]
+	}
+	if !slices.Equal(merged, h.lines) {
+		h.version++
+	}
+	h.lines = merged
+}
+
+// overlapStart returns the index in retained at which capture begins. Normally tmux has only dropped lines from
+// the start of its scrollback since retained was captured, so capture begins with lines from the end of retained.
+// If no position matches, e.g. because the scrollback was cleared, capture comes after everything retained.
+func overlapStart(retained, capture []string) int {
+	// A short capture is anchored on its first half, since the program may have redrawn the rest.
+	n := max(min(historyAnchorLines, len(capture)/2), 1)
+	matches := func(p int) bool {
+		for i := 0; i < n && p+i < len(retained); i++ {
+			if retained[p+i] != capture[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	// A capture is at least as long as the part of retained it overlaps, unless the screen got shorter.
+	earliest := max(len(retained)-len(capture), 0)
+	for p := earliest; p < len(retained); p++ {
+		if matches(p) {
+			return p
+		}
+	}
+	for p := earliest - 1; p >= 0; p-- {
+		if matches(p) {
+			return p
+		}
+	}
+	return len(retained)
+}
+
+// Lines returns a copy of the retained lines, oldest first, and the version of the history they belong to.
+func (h *OutputHistory) Lines() ([]string, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.lines...), h.version
+}
+
+// Version returns a number that changes whenever the retained lines change.
+func (h *OutputHistory) Version() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.version
+}
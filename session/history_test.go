@@ -0,0 +1,96 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// numbered returns the lines "line <from>" to "line <to>" joined as a pane capture.
+func numbered(from, to int) string {
+	var lines []string
+	for i := from; i <= to; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func historyText(h *OutputHistory) string {
+	lines, _ := h.Lines()
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestOutputHistoryMerge(t *testing.T) {
+	t.Run("blank lines below the output are dropped", func(t *testing.T) {
+		h := NewOutputHistory(100)
+		h.Merge(numbered(1, 3) + "\n\n   \n")
+		assert.Equal(t, numbered(1, 3), historyText(h))
+	})
+
+	t.Run("growing scrollback is not duplicated", func(t *testing.T) {
+		h := NewOutputHistory(100)
+		h.Merge(numbered(1, 10))
+		h.Merge(numbered(1, 15))
+		assert.Equal(t, numbered(1, 15), historyText(h))
+	})
+
+	t.Run("lines dropped by tmux are kept", func(t *testing.T) {
+		h := NewOutputHistory(100)
+		h.Merge(numbered(1, 20))
+		h.Merge(numbered(11, 30))
+		h.Merge(numbered(25, 40))
+		assert.Equal(t, numbered(1, 40), historyText(h))
+	})
+
+	t.Run("redrawn lines replace their earlier version", func(t *testing.T) {
+		h := NewOutputHistory(100)
+		h.Merge(numbered(1, 10) + "⠋ thinking\n")
+		h.Merge(numbered(1, 10) + "done\n")
+		assert.Equal(t, numbered(1, 10)+"done\n", historyText(h))
+	})
+
+	t.Run("output after the scrollback was cleared is appended", func(t *testing.T) {
+		h := NewOutputHistory(100)
+		h.Merge(numbered(1, 10))
+		h.Merge("fresh start\n")
+		assert.Equal(t, numbered(1, 10)+"fresh start\n", historyText(h))
+	})
+
+	t.Run("a capture of the recent lines is merged where it starts", func(t *testing.T) {
+		h := NewOutputHistory(5000)
+		h.Merge(numbered(1, 2000))
+		h.Merge(numbered(1001, 2010))
+		assert.Equal(t, numbered(1, 2010), historyText(h))
+	})
+
+	t.Run("repeated lines don't anchor a capture", func(t *testing.T) {
+		separator := strings.Repeat("─────\n", 10)
+		h := NewOutputHistory(100)
+		h.Merge(separator + "first\n" + separator + "second\n")
+		// tmux dropped the lines up to "first" while the program printed more.
+		h.Merge(separator + "second\n" + numbered(1, 10))
+		assert.Equal(t, separator+"first\n"+separator+"second\n"+numbered(1, 10), historyText(h))
+	})
+
+	t.Run("history is bounded", func(t *testing.T) {
+		h := NewOutputHistory(10)
+		h.Merge(numbered(1, 8))
+		h.Merge(numbered(5, 20))
+		assert.Equal(t, numbered(11, 20), historyText(h))
+	})
+
+	t.Run("version only changes with the lines", func(t *testing.T) {
+		h := NewOutputHistory(100)
+		assert.Equal(t, 0, h.Version())
+		h.Merge(numbered(1, 5))
+		first := h.Version()
+		assert.NotEqual(t, 0, first)
+
+		h.Merge(numbered(1, 5))
+		assert.Equal(t, first, h.Version())
+		h.Merge(numbered(1, 6))
+		assert.NotEqual(t, first, h.Version())
+	})
+}
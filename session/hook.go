@@ -0,0 +1,110 @@
+package session
+
+import (
+	"bytes"
+	"claude-squad/cmd"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// postCreateHookTimeout is how long the post-create hook may run before it is killed.
+var postCreateHookTimeout = 10 * time.Minute
+
+// runPostCreateHook runs the post-create hook with sh in the instance's worktree and returns its output. The
+// instance's title and branch are in CLAUDE_SQUAD_TITLE and CLAUDE_SQUAD_BRANCH. Each line of output is passed to
+// onLine as soon as it is printed, if onLine isn't nil. Nothing runs if there is no hook or the instance is
+// in-place. Cancelling ctx or running longer than postCreateHookTimeout kills the hook.
+func (i *Instance) runPostCreateHook(ctx context.Context, onLine func(line string)) (string, error) {
+	if i.postCreateHook == "" || i.gitWorktree == nil || i.inPlace {
+		return "", nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, postCreateHookTimeout)
+	defer cancel()
+
+	output := &lineWriter{onLine: onLine}
+	hook := exec.CommandContext(ctx, "sh", "-c", i.postCreateHook)
+	hook.Dir = i.gitWorktree.GetWorktreePath()
+	hook.Env = append(os.Environ(), "CLAUDE_SQUAD_TITLE="+i.Title, "CLAUDE_SQUAD_BRANCH="+i.Branch)
+	hook.Stdout = output
+	hook.Stderr = output
+	killProcessGroupOnCancel(hook)
+	// Don't wait for programs that were started in the background and keep the output open.
+	hook.WaitDelay = time.Second
+
+	cmdExec := i.cmdExec
+	if cmdExec == nil {
+		cmdExec = cmd.MakeExecutor()
+	}
+	err := cmdExec.Run(hook)
+	output.Flush()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return output.String(), fmt.Errorf("post-create hook %q timed out after %v", i.postCreateHook,
+			postCreateHookTimeout)
+	}
+	if err != nil {
+		if line := lastLine(output.String()); line != "" {
+			return output.String(), fmt.Errorf("post-create hook %q failed: %s (%w)", i.postCreateHook, line, err)
+		}
+		return output.String(), fmt.Errorf("post-create hook %q failed: %w", i.postCreateHook, err)
+	}
+	return output.String(), nil
+}
+
+// lineWriter collects the output of a command and passes each non-blank line to onLine once it is complete.
+type lineWriter struct {
+	onLine func(line string)
+
+	mu      sync.Mutex
+	output  bytes.Buffer
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.output.Write(p)
+	w.partial = append(w.partial, p...)
+	for {
+		end := bytes.IndexByte(w.partial, '\n')
+		if end < 0 {
+			break
+		}
+		w.emit(string(w.partial[:end]))
+		w.partial = w.partial[end+1:]
+	}
+	return len(p), nil
+}
+
+// Flush passes the last line to onLine if it didn't end with a newline.
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.emit(string(w.partial))
+	w.partial = nil
+}
+
+// emit passes line to onLine unless it is blank. The caller holds mu.
+func (w *lineWriter) emit(line string) {
+	if line = strings.TrimSpace(line); line != "" && w.onLine != nil {
+		w.onLine(line)
+	}
+}
+
+// String returns all the output written so far.
+func (w *lineWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.output.String()
+}
+
+// lastLine returns the last non-blank line of output, trimmed, or "" if there is none.
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
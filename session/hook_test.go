@@ -0,0 +1,133 @@
+package session
+
+import (
+	"claude-squad/cmd/cmd_test"
+	"claude-squad/session/git"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPostCreateHook(t *testing.T) {
+	newHookInstance := func(hook string, output string, err error) (*Instance, *[]*exec.Cmd) {
+		var ran []*exec.Cmd
+		instance := &Instance{
+			Title:          "hooked",
+			Branch:         "me/hooked",
+			postCreateHook: hook,
+			gitWorktree:    git.NewGitWorktreeFromStorage("/repo", "/worktree", "hooked", "me/hooked", "", "main"),
+			cmdExec: cmd_test.MockCmdExec{
+				RunFunc: func(cmd *exec.Cmd) error {
+					ran = append(ran, cmd)
+					_, _ = io.WriteString(cmd.Stdout, output)
+					return err
+				},
+			},
+		}
+		return instance, &ran
+	}
+
+	t.Run("runs the hook with sh in the worktree", func(t *testing.T) {
+		instance, ran := newHookInstance("npm install", "added 12 packages\n", nil)
+
+		output, err := instance.runPostCreateHook(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "added 12 packages\n", output)
+		require.Len(t, *ran, 1)
+		hook := (*ran)[0]
+		assert.Equal(t, []string{"sh", "-c", "npm install"}, hook.Args)
+		assert.Equal(t, "/worktree", hook.Dir)
+		assert.Contains(t, hook.Env, "CLAUDE_SQUAD_TITLE=hooked")
+		assert.Contains(t, hook.Env, "CLAUDE_SQUAD_BRANCH=me/hooked")
+	})
+
+	t.Run("passes on each line of output", func(t *testing.T) {
+		instance, _ := newHookInstance("npm install", "resolving\n\nadded 12 packages\ndone", nil)
+
+		var lines []string
+		_, err := instance.runPostCreateHook(context.Background(), func(line string) { lines = append(lines, line) })
+		require.NoError(t, err)
+		assert.Equal(t, []string{"resolving", "added 12 packages", "done"}, lines)
+	})
+
+	t.Run("reports the last line of a failure", func(t *testing.T) {
+		instance, _ := newHookInstance("npm install", "resolving\nnpm ERR! missing package.json\n\n",
+			errors.New("exit status 1"))
+
+		_, err := instance.runPostCreateHook(context.Background(), nil)
+		assert.EqualError(t, err, `post-create hook "npm install" failed: npm ERR! missing package.json (exit status 1)`)
+	})
+
+	t.Run("kills a hook that runs too long", func(t *testing.T) {
+		timeout := postCreateHookTimeout
+		postCreateHookTimeout = 50 * time.Millisecond
+		t.Cleanup(func() { postCreateHookTimeout = timeout })
+		instance := &Instance{
+			Title:          "hooked",
+			postCreateHook: "sleep 10",
+			gitWorktree:    git.NewGitWorktreeFromStorage("/repo", t.TempDir(), "hooked", "me/hooked", "", "main"),
+		}
+
+		start := time.Now()
+		_, err := instance.runPostCreateHook(context.Background(), nil)
+		assert.EqualError(t, err, `post-create hook "sleep 10" timed out after 50ms`)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("runs nothing without a hook or in place", func(t *testing.T) {
+		instance, ran := newHookInstance("", "", nil)
+		_, err := instance.runPostCreateHook(context.Background(), nil)
+		require.NoError(t, err)
+
+		inPlace, inPlaceRan := newHookInstance("npm install", "", nil)
+		inPlace.inPlace = true
+		_, err = inPlace.runPostCreateHook(context.Background(), nil)
+		require.NoError(t, err)
+
+		assert.Empty(t, *ran)
+		assert.Empty(t, *inPlaceRan)
+	})
+}
+
+func TestStartWithPostCreateHook(t *testing.T) {
+	t.Run("runs as a stage before tmux", func(t *testing.T) {
+		instance, _ := newRepoTestInstance(t)
+		instance.postCreateHook = `touch "$CLAUDE_SQUAD_TITLE.done" && echo installed`
+
+		updates := collectProgress(instance, true)
+
+		require.Len(t, updates, 7)
+		assert.Equal(t, StageRunningHook, updates[1].Stage)
+		assert.Equal(t, "[2/5]", updates[1].Counter())
+		assert.Equal(t, "Post-create hook: installed", updates[2].Message)
+		assert.Equal(t, "[2/5]", updates[2].Counter())
+		assert.Equal(t, StageStartingTmux, updates[3].Stage)
+		assert.Equal(t, StageComplete, updates[6].Stage)
+		assert.FileExists(t, filepath.Join(instance.gitWorktree.GetWorktreePath(), "cancelled.done"))
+	})
+
+	t.Run("a failure is reported and the start goes on", func(t *testing.T) {
+		instance, _ := newRepoTestInstance(t)
+		instance.postCreateHook = "echo direnv: error >&2; exit 3"
+
+		updates := collectProgress(instance, true)
+
+		var warnings []error
+		for _, p := range updates {
+			if p.Warning != nil {
+				warnings = append(warnings, p.Warning)
+			}
+		}
+		require.Len(t, warnings, 1)
+		assert.ErrorContains(t, warnings[0], "direnv: error (exit status 3)")
+		assert.Equal(t, StageComplete, updates[len(updates)-1].Stage)
+		assert.True(t, instance.Started())
+	})
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package session
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroupOnCancel runs c in a process group of its own and kills the whole group when its context is
+// done, so the programs a shell hook started don't outlive it.
+func killProcessGroupOnCancel(c *exec.Cmd) {
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	c.Cancel = func() error {
+		return syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+	}
+}
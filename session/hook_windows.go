@@ -0,0 +1,8 @@
+//go:build windows
+
+package session
+
+import "os/exec"
+
+// killProcessGroupOnCancel leaves c as it is: exec.CommandContext already kills it when its context is done.
+func killProcessGroupOnCancel(c *exec.Cmd) {}
@@ -0,0 +1,34 @@
+package session
+
+import "time"
+
+// MarkActive records that the instance's program was active at now, which restarts its idle time.
+func (i *Instance) MarkActive(now time.Time) {
+	i.lastActivity = now
+}
+
+// LastActive returns when the instance's program was last seen active, or the zero time if it hasn't been seen
+// since it was loaded, resumed or restored.
+func (i *Instance) LastActive() time.Time {
+	return i.lastActivity
+}
+
+// IdleInstances returns the instances whose program has been idle for at least timeout at now, so they can be
+// paused to save resources. Only Running and Ready instances are considered. Instances with queued prompts, in-place
+// instances, which can't be paused, and keep are never returned. keep is usually the selected instance, since it is the one the user may be attached to.
+func IdleInstances(instances []*Instance, keep *Instance, timeout time.Duration, now time.Time) []*Instance {
+	var idle []*Instance
+	for _, instance := range instances {
+		if instance == keep || !instance.Started() || instance.QueuedPrompts() > 0 || instance.InPlace() {
+			continue
+		}
+		if instance.Status != Running && instance.Status != Ready {
+			continue
+		}
+		if instance.lastActivity.IsZero() || now.Sub(instance.lastActivity) < timeout {
+			continue
+		}
+		idle = append(idle, instance)
+	}
+	return idle
+}
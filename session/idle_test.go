@@ -0,0 +1,45 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdleInstances(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	timeout := 30 * time.Minute
+
+	newInstance := func(title string, status Status) *Instance {
+		instance := &Instance{Title: title, Status: status, started: true}
+		instance.MarkActive(start)
+		return instance
+	}
+	ready := newInstance("ready", Ready)
+	running := newInstance("running", Running)
+	selected := newInstance("selected", Ready)
+	queued := newInstance("queued", Ready)
+	queued.promptQueue.Push("next")
+	paused := newInstance("paused", Paused)
+	dead := newInstance("dead", Dead)
+	unstarted := &Instance{Title: "unstarted", Status: Ready}
+	unstarted.MarkActive(start)
+	unseen := &Instance{Title: "unseen", Status: Ready, started: true}
+	inPlace := newInstance("in-place", Ready)
+	inPlace.inPlace = true
+	instances := []*Instance{ready, running, selected, queued, paused, dead, unstarted, unseen, inPlace}
+
+	// Nothing is idle before the timeout.
+	assert.Empty(t, IdleInstances(instances, selected, timeout, start.Add(timeout-time.Second)))
+
+	assert.Equal(t, []*Instance{ready, running}, IdleInstances(instances, selected, timeout, start.Add(timeout)))
+
+	// Output restarts the idle time.
+	running.MarkActive(start.Add(20 * time.Minute))
+	assert.Equal(t, []*Instance{ready}, IdleInstances(instances, selected, timeout, start.Add(45*time.Minute)))
+	assert.Equal(t, []*Instance{ready, running}, IdleInstances(instances, selected, timeout, start.Add(50*time.Minute)))
+
+	// Without a selected instance, it is paused too.
+	assert.Equal(t, []*Instance{ready, running, selected}, IdleInstances(instances, nil, timeout, start.Add(time.Hour)))
+}
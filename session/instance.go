@@ -1,15 +1,20 @@
 package session
 
 import (
+	"claude-squad/cmd"
 	"claude-squad/log"
 	"claude-squad/session/git"
 	"claude-squad/session/tmux"
+	"context"
+	"errors"
 	"path/filepath"
 
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/atotto/clipboard"
 )
@@ -27,6 +32,15 @@ const (
 	Paused
 	// Deleting is if the instance is being deleted (worktree and branch being removed).
 	Deleting
+	// Dead is if the tmux session closed or the program in it crashed: it exited with a non-zero code or was
+	// killed by a signal. The worktree and branch are still there, so the instance can be restarted.
+	Dead
+	// Archived is if the instance was put aside: its tmux session is closed, but the worktree, branch and stored
+	// record are kept so it can be restored.
+	Archived
+	// Exited is if the program exited on its own with exit code 0. Its pane is kept until the instance is
+	// restarted, archived or killed.
+	Exited
 )
 
 // InitStage represents the current stage of instance initialization
@@ -34,7 +48,9 @@ type InitStage int
 
 const (
 	StageCreatingWorktree InitStage = iota
+	StageRunningHook
 	StageStartingTmux
+	StageStartingProgram
 	StageWaitingForAgent
 	StageComplete
 	StageFailed
@@ -45,6 +61,20 @@ type InitProgress struct {
 	Stage   InitStage
 	Message string
 	Error   error
+	// Warning is a problem that didn't stop the start, like a failed post-create hook.
+	Warning error
+	// Index is the 1-based position of the stage among the Total stages of this start. A failure keeps the
+	// index of the stage that failed. Both are zero when unknown.
+	Index int
+	Total int
+}
+
+// Counter returns the position of the stage like "[2/4]", or "" if it is unknown.
+func (p InitProgress) Counter() string {
+	if p.Index <= 0 || p.Total <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("[%d/%d]", p.Index, p.Total)
 }
 
 // Instance is a running instance of claude code.
@@ -59,6 +89,9 @@ type Instance struct {
 	Status Status
 	// Program is the program to run in the instance.
 	Program string
+	// Args is the program and its arguments. When set, it is launched instead of Program so that arguments
+	// containing spaces or quotes are passed through intact. Without it, Program is run by the shell as it is.
+	Args []string
 	// Height is the height of the instance.
 	Height int
 	// Width is the width of the instance.
@@ -71,13 +104,68 @@ type Instance struct {
 	AutoYes bool
 	// Prompt is the initial prompt to pass to the instance on startup
 	Prompt string
+	// Env is set in the environment of the instance's program.
+	Env map[string]string
+	// InitPrompts are sent to the program one after another after it is first started. They are not stored.
+	InitPrompts []string
+	// Tags label the instance, e.g. with the kind of task it was created for.
+	Tags []string
+	// Note is a free-form note about what the instance is for. It has no effect on the program.
+	Note string
+	// Color is the color the instance's title is shown in, a name in ColorPalette or a hex color. Empty shows the
+	// default color.
+	Color string
+	// Icon is a character or emoji shown before the instance's title. It is empty if there is none.
+	Icon string
+	// Group is the name of the group the instance is listed under. It is empty if the instance is ungrouped.
+	Group string
+	// promptQueue holds the prompts submitted while the program was busy or couldn't take them, e.g. because it was
+	// paused. They are stored, so they are still sent after a restart.
+	promptQueue PromptQueue
+	// lastPrompt is the prompt that was last sent to the program, so it can be sent again.
+	lastPrompt string
+	// isCommand reports whether a word like "/commit" is a known slash command. Known commands are sent without their
+	// leading slash, for programs that don't use it. Nil sends every prompt as it is.
+	isCommand func(command string) bool
+	// lastActivity is when the program's output last changed. It is reset when the program is stopped, so the
+	// idle time starts over once it runs again.
+	lastActivity time.Time
+	// lastAutoCommit is when the instance's changes were last considered for an auto-commit. It is not stored.
+	lastAutoCommit time.Time
+	// unseenOutput is true if the program's output changed while the instance wasn't selected. It is not stored.
+	unseenOutput bool
+	// remoteRef is the remote branch (e.g. origin/feature-x) the worktree is checked out from when the instance is
+	// first started. Empty means a new branch from HEAD.
+	remoteRef string
 
 	// DiffStats stores the current git diff statistics
 	diffStats *git.DiffStats
+	// diffOptions control how diffStats is computed
+	diffOptions git.DiffOptions
+	// diffCache holds the diff stats last computed with each set of options, so switching back to them is instant
+	diffCache map[git.DiffOptions]*git.DiffStats
+	// conflicts are the files that conflict with the base branch as of conflictsCheckedAt.
+	conflicts []string
+	// conflictsCheckedAt is when conflicts was last computed. Zero if it never was.
+	conflictsCheckedAt time.Time
+	// outputHistory retains the pane output beyond tmux's scrollback. It is created on first use.
+	outputHistory *OutputHistory
+	// cmdExec runs the git and tmux commands of the instance. Nil runs them for real.
+	cmdExec cmd.Executor
+	// ptyFactory starts the tmux sessions of the instance. Nil starts them in a real PTY.
+	ptyFactory tmux.PtyFactory
+	// postCreateHook is run in the worktree once it's created. It is not stored.
+	postCreateHook string
+	// inPlace is true if the program runs directly in Path, without a worktree or branch of its own.
+	inPlace bool
 
 	// The below fields are initialized upon calling Start().
 
 	started bool
+	// recovered is true if the tmux session was missing when the instance was loaded from storage.
+	recovered bool
+	// exitCode is the exit code of the program once CheckExited found that it ended, or -1 if it is unknown.
+	exitCode int
 	// tmuxSession is the tmux session for the instance.
 	tmuxSession *tmux.TmuxSession
 	// gitWorktree is the git worktree for the instance.
@@ -87,16 +175,25 @@ type Instance struct {
 // ToInstanceData converts an Instance to its serializable form
 func (i *Instance) ToInstanceData() InstanceData {
 	data := InstanceData{
-		Title:     i.Title,
-		Path:      i.Path,
-		Branch:    i.Branch,
-		Status:    i.Status,
-		Height:    i.Height,
-		Width:     i.Width,
-		CreatedAt: i.CreatedAt,
-		UpdatedAt: time.Now(),
-		Program:   i.Program,
-		AutoYes:   i.AutoYes,
+		Title:         i.Title,
+		Path:          i.Path,
+		Branch:        i.Branch,
+		Status:        i.Status,
+		Height:        i.Height,
+		Width:         i.Width,
+		CreatedAt:     i.CreatedAt,
+		UpdatedAt:     time.Now(),
+		Program:       i.Program,
+		Args:          i.Args,
+		AutoYes:       i.AutoYes,
+		Env:           i.Env,
+		Tags:          i.Tags,
+		Note:          i.Note,
+		Color:         i.Color,
+		Icon:          i.Icon,
+		Group:         i.Group,
+		LastPrompt:    i.lastPrompt,
+		QueuedPrompts: slices.Clone(i.promptQueue.prompts),
 	}
 
 	// Only include worktree data if gitWorktree is initialized
@@ -107,6 +204,8 @@ func (i *Instance) ToInstanceData() InstanceData {
 			SessionName:   i.Title,
 			BranchName:    i.gitWorktree.GetBranchName(),
 			BaseCommitSHA: i.gitWorktree.GetBaseCommitSHA(),
+			BaseBranch:    i.gitWorktree.GetBaseBranch(),
+			InPlace:       i.gitWorktree.IsInPlace(),
 		}
 	}
 
@@ -125,21 +224,33 @@ func (i *Instance) ToInstanceData() InstanceData {
 // FromInstanceData creates a new Instance from serialized data
 func FromInstanceData(data InstanceData) (*Instance, error) {
 	instance := &Instance{
-		Title:     data.Title,
-		Path:      data.Path,
-		Branch:    data.Branch,
-		Status:    data.Status,
-		Height:    data.Height,
-		Width:     data.Width,
-		CreatedAt: data.CreatedAt,
-		UpdatedAt: data.UpdatedAt,
-		Program:   data.Program,
+		Title:       data.Title,
+		Path:        data.Path,
+		Branch:      data.Branch,
+		Status:      data.Status,
+		Height:      data.Height,
+		Width:       data.Width,
+		CreatedAt:   data.CreatedAt,
+		UpdatedAt:   data.UpdatedAt,
+		Program:     data.Program,
+		Args:        data.Args,
+		Env:         data.Env,
+		Tags:        data.Tags,
+		Note:        data.Note,
+		Color:       data.Color,
+		Icon:        data.Icon,
+		Group:       data.Group,
+		lastPrompt:  data.LastPrompt,
+		promptQueue: PromptQueue{prompts: data.QueuedPrompts},
+		exitCode:    -1,
+		inPlace:     data.Worktree.InPlace,
 		gitWorktree: git.NewGitWorktreeFromStorage(
 			data.Worktree.RepoPath,
 			data.Worktree.WorktreePath,
 			data.Worktree.SessionName,
 			data.Worktree.BranchName,
 			data.Worktree.BaseCommitSHA,
+			data.Worktree.BaseBranch,
 		),
 		diffStats: &git.DiffStats{
 			Added:   data.DiffStats.Added,
@@ -147,12 +258,20 @@ func FromInstanceData(data InstanceData) (*Instance, error) {
 			Content: data.DiffStats.Content,
 		},
 	}
+	instance.gitWorktree.SetInPlace(data.Worktree.InPlace)
 
-	if instance.Paused() {
+	if instance.Paused() || instance.Archived() {
 		instance.started = true
-		instance.tmuxSession = tmux.NewTmuxSession(instance.Title, instance.Program)
+		instance.tmuxSession = instance.newTmuxSession()
 	} else {
-		if err := instance.Start(false); err != nil {
+		tmuxSession := instance.newTmuxSession()
+		if !tmuxSession.DoesSessionExist() {
+			// The tmux session died while we weren't running (crash, reboot, tmux kill-server).
+			instance.tmuxSession = tmuxSession
+			if err := instance.recoverLostSession(); err != nil {
+				return nil, err
+			}
+		} else if err := instance.Start(false); err != nil {
 			return nil, err
 		}
 	}
@@ -160,16 +279,129 @@ func FromInstanceData(data InstanceData) (*Instance, error) {
 	return instance, nil
 }
 
+// recoverLostSession brings back an instance whose tmux session no longer exists. If the worktree is still on
+// disk, the program is restarted in it. Otherwise, the instance is marked as paused so it can be resumed.
+func (i *Instance) recoverLostSession() error {
+	i.recovered = true
+	i.started = true
+
+	if _, err := os.Stat(i.gitWorktree.GetWorktreePath()); err != nil {
+		log.WarningLog.Printf("worktree for instance %s is missing, marking it as paused", i.Title)
+		i.SetStatus(Paused)
+		return nil
+	}
+
+	log.WarningLog.Printf("tmux session for instance %s is missing, restarting it", i.Title)
+	if err := i.tmuxSession.Start(i.gitWorktree.GetWorktreePath()); err != nil {
+		return fmt.Errorf("failed to restart tmux session for %s: %w", i.Title, err)
+	}
+	i.SetStatus(Running)
+	return nil
+}
+
+// Recovered returns true if the instance's tmux session was missing when it was loaded from storage and had
+// to be restarted or paused.
+func (i *Instance) Recovered() bool {
+	return i.recovered
+}
+
+// command returns the shell command that launches the instance's program: Program as it is, so the shell still
+// expands variables and runs pipelines in it, unless explicit Args were given.
+func (i *Instance) command() string {
+	if len(i.Args) == 0 {
+		return i.Program
+	}
+	return JoinArgs(i.Args)
+}
+
+// executor returns the Executor that runs the git and tmux commands of the instance.
+func (i *Instance) executor() cmd.Executor {
+	if i.cmdExec == nil {
+		return cmd.MakeExecutor()
+	}
+	return i.cmdExec
+}
+
+// newTmuxSession returns a tmux session that runs the instance's program with its environment.
+func (i *Instance) newTmuxSession() *tmux.TmuxSession {
+	return i.newTmuxSessionWith(i.executor())
+}
+
+// newTmuxSessionWith returns a tmux session like newTmuxSession whose commands are run by cmdExec.
+func (i *Instance) newTmuxSessionWith(cmdExec cmd.Executor) *tmux.TmuxSession {
+	ptyFactory := i.ptyFactory
+	if ptyFactory == nil {
+		ptyFactory = tmux.MakePtyFactory()
+	}
+	t := tmux.NewTmuxSessionWithDeps(i.Title, i.command(), ptyFactory, cmdExec)
+	t.SetEnv(i.Env)
+	return t
+}
+
+// createdDetail returns the detail of the instance's created event: its branch and tags.
+func (i *Instance) createdDetail() string {
+	if len(i.Tags) == 0 {
+		return i.Branch
+	}
+	return fmt.Sprintf("%s [%s]", i.Branch, strings.Join(i.Tags, ", "))
+}
+
+// ProgramName returns the name of the program the instance runs without its arguments, like "claude".
+func (i *Instance) ProgramName() string {
+	if len(i.Args) > 0 {
+		return filepath.Base(i.Args[0])
+	}
+	args, err := SplitArgs(i.Program)
+	if err != nil {
+		args = strings.Fields(i.Program)
+	}
+	if len(args) == 0 {
+		return i.Program
+	}
+	return filepath.Base(args[0])
+}
+
+// TmuxSessionName returns the name of the instance's tmux session, or "" if it has none.
+func (i *Instance) TmuxSessionName() string {
+	if i.tmuxSession == nil {
+		return ""
+	}
+	return i.tmuxSession.Name()
+}
+
 // Options for creating a new instance
 type InstanceOptions struct {
 	// Title is the title of the instance.
 	Title string
 	// Path is the path to the workspace.
 	Path string
-	// Program is the program to run in the instance (e.g. "claude", "aider --model ollama_chat/gemma3:1b")
+	// Program is the program to run in the instance (e.g. "claude", "aider --model ollama_chat/gemma3:1b").
+	// It is run by the shell as it is, so quotes, variables and pipelines work as they do in a shell, e.g.
+	// "claude --append-system-prompt 'be brief'".
 	Program string
+	// Args is the program and its arguments. If set, it takes precedence over Program.
+	Args []string
 	// If AutoYes is true, then
 	AutoYes bool
+	// Env is set in the environment of the program.
+	Env map[string]string
+	// InitPrompts are sent to the program one after another once it has started.
+	InitPrompts []string
+	// Tags label the instance.
+	Tags []string
+	// RemoteRef is a remote branch (e.g. origin/feature-x) to check out in a local branch tracking it instead of
+	// branching from HEAD. It is fetched when the instance starts.
+	RemoteRef string
+	// Executor runs the instance's git and tmux commands, so tests can fake them. Nil uses cmd.MakeExecutor().
+	Executor cmd.Executor
+	// PtyFactory starts the instance's tmux sessions, so tests can fake them. Nil uses tmux.MakePtyFactory().
+	PtyFactory tmux.PtyFactory
+	// PostCreateHook is a shell command run in the worktree once it's created, before the program starts. It isn't
+	// run for in-place instances, which have no worktree of their own.
+	PostCreateHook string
+	// InPlace runs the program directly in Path instead of in a new worktree and branch. The instance works on
+	// whatever is checked out there, and its diff is against HEAD. It can't be paused, pushed or rebased.
+	InPlace bool
 }
 
 func NewInstance(opts InstanceOptions) (*Instance, error) {
@@ -181,19 +413,64 @@ func NewInstance(opts InstanceOptions) (*Instance, error) {
 		return nil, fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
+	if opts.RemoteRef != "" {
+		if opts.InPlace {
+			return nil, fmt.Errorf("an in-place instance can't check out a remote branch")
+		}
+		if _, _, err := git.ParseRemoteRef(opts.RemoteRef); err != nil {
+			return nil, err
+		}
+	}
+	hook := strings.TrimSpace(opts.PostCreateHook)
+	if opts.InPlace {
+		hook = ""
+	}
+
+	args := opts.Args
+	program := opts.Program
+	if len(args) > 0 && program == "" {
+		program = JoinArgs(args)
+	}
+
 	return &Instance{
-		Title:     opts.Title,
-		Status:    Ready,
-		Path:      absPath,
-		Program:   opts.Program,
-		Height:    0,
-		Width:     0,
-		CreatedAt: t,
-		UpdatedAt: t,
-		AutoYes:   false,
+		Title:       opts.Title,
+		Status:      Ready,
+		Path:        absPath,
+		Program:     program,
+		Args:        args,
+		Height:      0,
+		Width:       0,
+		CreatedAt:   t,
+		UpdatedAt:   t,
+		AutoYes:     false,
+		Env:         opts.Env,
+		InitPrompts: opts.InitPrompts,
+		Tags:        opts.Tags,
+		remoteRef:   strings.TrimSpace(opts.RemoteRef),
+		cmdExec:     opts.Executor,
+		ptyFactory:  opts.PtyFactory,
+		exitCode:    -1,
+		inPlace:     opts.InPlace,
+
+		postCreateHook: hook,
 	}, nil
 }
 
+// newGitWorktree creates the worktree of a new instance, on a new branch or the remote branch it was created from.
+// Its git commands are run by cmdExec, or for real if cmdExec is nil.
+func (i *Instance) newGitWorktree(cmdExec cmd.Executor) (*git.GitWorktree, string, error) {
+	if cmdExec == nil {
+		cmdExec = cmd.MakeExecutor()
+	}
+	if i.inPlace {
+		return git.NewInPlaceGitWorktree(cmdExec, i.Path, i.Title)
+	}
+	if i.remoteRef != "" {
+		return git.NewGitWorktreeFromRemote(cmdExec, i.Path, i.Title, i.remoteRef)
+	}
+	return git.NewGitWorktree(cmdExec, i.Path, i.Title)
+}
+
 func (i *Instance) RepoName() (string, error) {
 	if !i.started {
 		return "", fmt.Errorf("cannot get repo name for instance that has not been started")
@@ -217,12 +494,12 @@ func (i *Instance) Start(firstTimeSetup bool) error {
 		tmuxSession = i.tmuxSession
 	} else {
 		// Create new tmux session
-		tmuxSession = tmux.NewTmuxSession(i.Title, i.Program)
+		tmuxSession = i.newTmuxSession()
 	}
 	i.tmuxSession = tmuxSession
 
 	if firstTimeSetup {
-		gitWorktree, branchName, err := git.NewGitWorktree(i.Path, i.Title)
+		gitWorktree, branchName, err := i.newGitWorktree(i.cmdExec)
 		if err != nil {
 			return fmt.Errorf("failed to create git worktree: %w", err)
 		}
@@ -254,6 +531,9 @@ func (i *Instance) Start(firstTimeSetup bool) error {
 			setupErr = fmt.Errorf("failed to setup git worktree: %w", err)
 			return setupErr
 		}
+		if _, err := i.runPostCreateHook(context.Background(), nil); err != nil {
+			log.WarningLog.Print(err)
+		}
 
 		// Create new session
 		if err := i.tmuxSession.Start(i.gitWorktree.GetWorktreePath()); err != nil {
@@ -264,6 +544,7 @@ func (i *Instance) Start(firstTimeSetup bool) error {
 			setupErr = fmt.Errorf("failed to start new session: %w", err)
 			return setupErr
 		}
+		i.RecordEvent(EventCreated, i.createdDetail())
 	}
 
 	i.SetStatus(Running)
@@ -273,11 +554,28 @@ func (i *Instance) Start(firstTimeSetup bool) error {
 
 // StartWithProgress starts the instance and reports progress via the provided channel.
 // The channel is closed when initialization completes (either successfully or with an error).
-// This method should be called from a goroutine to avoid blocking.
-func (i *Instance) StartWithProgress(firstTimeSetup bool, progress chan<- InitProgress) {
+// This method should be called from a goroutine to avoid blocking. Cancelling ctx kills the git and tmux commands
+// of the start, stops it at the next stage boundary, removes the worktree and tmux session created so far and
+// reports StageFailed.
+func (i *Instance) StartWithProgress(ctx context.Context, firstTimeSetup bool, progress chan<- InitProgress) {
 	defer close(progress)
 	totalStart := time.Now()
 
+	// A first start creates the worktree, runs the post-create hook if there is one, launches tmux, starts the
+	// program and waits for the agent. A restore only reattaches to tmux, where the program is already running.
+	total := 2
+	if firstTimeSetup {
+		total = 4
+		if i.postCreateHook != "" {
+			total++
+		}
+	}
+	index := 0
+	report := func(stage InitStage, message string) {
+		index++
+		progress <- InitProgress{Stage: stage, Message: message, Index: index, Total: total}
+	}
+
 	if i.Title == "" {
 		progress <- InitProgress{Stage: StageFailed, Error: fmt.Errorf("instance title cannot be empty")}
 		return
@@ -285,31 +583,58 @@ func (i *Instance) StartWithProgress(firstTimeSetup bool, progress chan<- InitPr
 
 	i.SetStatus(Loading)
 
+	// The commands run while starting are killed when ctx is cancelled. Afterwards, they run as usual, including
+	// the ones that clean up after a failed start.
+	startExec := cmd.WithContext(ctx, i.executor())
+	defer startExec.Detach()
+
 	var tmuxSession *tmux.TmuxSession
 	if i.tmuxSession != nil {
 		tmuxSession = i.tmuxSession
 	} else {
-		tmuxSession = tmux.NewTmuxSession(i.Title, i.Program)
+		tmuxSession = i.newTmuxSessionWith(startExec)
 	}
 	i.tmuxSession = tmuxSession
 
+	// launched is set once a new tmux session exists, so a failure after that closes it again.
+	launched := false
+
 	// Helper to handle errors with cleanup
 	handleError := func(err error, cleanupWorktree bool) {
+		startExec.Detach()
+		if launched {
+			if closeErr := tmuxSession.Close(); closeErr != nil {
+				err = fmt.Errorf("%v (cleanup error: %v)", err, closeErr)
+			}
+		}
 		if cleanupWorktree && i.gitWorktree != nil {
 			if cleanupErr := i.gitWorktree.Cleanup(); cleanupErr != nil {
 				err = fmt.Errorf("%v (cleanup error: %v)", err, cleanupErr)
 			}
 		}
 		i.SetStatus(Ready) // Reset status on failure
-		progress <- InitProgress{Stage: StageFailed, Error: err}
+		progress <- InitProgress{Stage: StageFailed, Error: err, Index: index, Total: total}
+	}
+
+	// cancelled fails the start if ctx was cancelled. Only a first start created the worktree, so only it is
+	// cleaned up.
+	cancelled := func() bool {
+		if ctx.Err() == nil {
+			return false
+		}
+		handleError(fmt.Errorf("initialization cancelled: %w", ctx.Err()), firstTimeSetup)
+		return true
+	}
+	if cancelled() {
+		return
 	}
 
 	if firstTimeSetup {
 		// Stage 1: Creating git worktree
 		stageStart := time.Now()
-		progress <- InitProgress{Stage: StageCreatingWorktree, Message: "Creating git worktree..."}
+		report(StageCreatingWorktree, "Creating git worktree...")
 
-		gitWorktree, branchName, err := git.NewGitWorktree(i.Path, i.Title)
+		gitWorktree, branchName, err := i.newGitWorktree(startExec)
 		if err != nil {
 			handleError(fmt.Errorf("failed to create git worktree: %w", err), false)
 			return
@@ -324,41 +649,74 @@ func (i *Instance) StartWithProgress(firstTimeSetup bool, progress chan<- InitPr
 		if log.InfoLog != nil {
 			log.InfoLog.Printf("[instance timing] Git worktree setup: %v", time.Since(stageStart))
 		}
+		if cancelled() {
+			return
+		}
+
+		if i.postCreateHook != "" {
+			// Stage 1b: Running the post-create hook, showing each line of its output as it is printed. Its
+			// failure is reported, but the instance still starts.
+			report(StageRunningHook, "Running post-create hook...")
+			_, err := i.runPostCreateHook(ctx, func(line string) {
+				progress <- InitProgress{Stage: StageRunningHook, Message: "Post-create hook: " + line,
+					Index: index, Total: total}
+			})
+			if err != nil {
+				progress <- InitProgress{Stage: StageRunningHook, Message: "Post-create hook failed", Warning: err,
+					Index: index, Total: total}
+			}
+			if cancelled() {
+				return
+			}
+		}
 	}
 
 	// Stage 2: Starting tmux session
 	stageStart := time.Now()
-	progress <- InitProgress{Stage: StageStartingTmux, Message: "Starting tmux session..."}
-
 	if !firstTimeSetup {
+		report(StageStartingTmux, "Restoring tmux session...")
 		if err := tmuxSession.Restore(); err != nil {
 			handleError(fmt.Errorf("failed to restore existing session: %w", err), false)
 			return
 		}
 	} else {
-		if err := i.tmuxSession.Start(i.gitWorktree.GetWorktreePath()); err != nil {
+		report(StageStartingTmux, "Launching tmux session...")
+		if err := i.tmuxSession.Launch(i.gitWorktree.GetWorktreePath()); err != nil {
 			handleError(fmt.Errorf("failed to start new session: %w", err), true)
 			return
 		}
+		launched = true
+		if cancelled() {
+			return
+		}
+
+		// Stage 3: Starting the program, accepting its trust screen if it shows one
+		report(StageStartingProgram, fmt.Sprintf("Starting %s...", i.ProgramName()))
+		i.tmuxSession.AcceptTrustScreen()
 	}
 	if log.InfoLog != nil {
 		log.InfoLog.Printf("[instance timing] Tmux session start: %v", time.Since(stageStart))
 	}
 
-	// Stage 3: Waiting for agent
-	progress <- InitProgress{Stage: StageWaitingForAgent, Message: "Waiting for agent..."}
+	if cancelled() {
+		return
+	}
 
-	// The tmux Start() method already waits for the trust screen,
-	// so by this point the agent should be ready
+	// Stage 4: Waiting for agent
+	report(StageWaitingForAgent, "Waiting for agent...")
+
+	// The trust screen has been handled, so by this point the agent should be ready
 	i.started = true
 	i.SetStatus(Running)
+	if firstTimeSetup {
+		i.RecordEvent(EventCreated, i.createdDetail())
+	}
 
 	if log.InfoLog != nil {
 		log.InfoLog.Printf("[instance timing] TOTAL StartWithProgress: %v", time.Since(totalStart))
 	}
 
-	// Stage 4: Complete
-	progress <- InitProgress{Stage: StageComplete, Message: "Ready"}
+	progress <- InitProgress{Stage: StageComplete, Message: "Ready", Index: total, Total: total}
 }
 
 // Kill terminates the instance and cleans up all resources
@@ -371,8 +729,8 @@ func (i *Instance) Kill() error {
 	var errs []error
 
 	// Always try to cleanup both resources, even if one fails
-	// Clean up tmux session first since it's using the git worktree
-	if i.tmuxSession != nil {
+	// Clean up tmux session first since it's using the git worktree. Archived instances have none.
+	if i.tmuxSession != nil && i.Status != Archived {
 		if err := i.tmuxSession.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to close tmux session: %w", err))
 		}
@@ -405,7 +763,7 @@ func (i *Instance) combineErrors(errs []error) error {
 }
 
 func (i *Instance) Preview() (string, error) {
-	if !i.started || i.Status == Paused {
+	if !i.started || i.Status == Paused || i.Status == Archived {
 		return "", nil
 	}
 	content, err := i.tmuxSession.CapturePaneContent()
@@ -425,6 +783,15 @@ func (i *Instance) HasUpdated() (updated bool, hasPrompt bool) {
 	return i.tmuxSession.HasUpdated()
 }
 
+// PromptText returns the pane text around the prompt detected by the last HasUpdated call, or an empty string
+// if there is no prompt.
+func (i *Instance) PromptText() string {
+	if !i.started {
+		return ""
+	}
+	return i.tmuxSession.PromptText()
+}
+
 // TapEnter sends an enter key press to the tmux session if AutoYes is enabled.
 func (i *Instance) TapEnter() {
 	if !i.started || !i.AutoYes {
@@ -442,10 +809,28 @@ func (i *Instance) Attach() (chan struct{}, error) {
 	return i.tmuxSession.Attach()
 }
 
+// AttachArgs returns the command that attaches a terminal to the instance's tmux session, to run it outside of the
+// app.
+func (i *Instance) AttachArgs() ([]string, error) {
+	if !i.started {
+		return nil, fmt.Errorf("cannot attach instance that has not been started")
+	}
+	return i.tmuxSession.AttachArgs(), nil
+}
+
+// ObserveCommand returns the shell command that attaches to the instance's tmux session read-only, to be run from
+// another terminal.
+func (i *Instance) ObserveCommand() (string, error) {
+	if !i.started || i.Status == Paused || i.Status == Archived {
+		return "", fmt.Errorf("cannot observe instance that has not been started, is paused or is archived")
+	}
+	return JoinArgs(i.tmuxSession.ObserveArgs()), nil
+}
+
 func (i *Instance) SetPreviewSize(width, height int) error {
-	if !i.started || i.Status == Paused {
-		return fmt.Errorf("cannot set preview size for instance that has not been started or " +
-			"is paused")
+	if !i.started || i.Status == Paused || i.Status == Archived {
+		return fmt.Errorf("cannot set preview size for instance that has not been started, is paused " +
+			"or is archived")
 	}
 	return i.tmuxSession.SetDetachedSize(width, height)
 }
@@ -458,6 +843,22 @@ func (i *Instance) GetGitWorktree() (*git.GitWorktree, error) {
 	return i.gitWorktree, nil
 }
 
+// Checkpoint commits the instance's current work, including uncommitted changes, on a new branch named with the time
+// now, to bookmark its progress. The instance's own branch and worktree are left as they are. It returns the name of
+// the new branch.
+func (i *Instance) Checkpoint(now time.Time) (string, error) {
+	if !i.started || i.Paused() || i.Archived() {
+		return "", fmt.Errorf("can't checkpoint '%s' without its worktree", i.Title)
+	}
+	message := fmt.Sprintf("%s of '%s' on %s", git.CheckpointPrefix, i.Title, now.Format(time.RFC822))
+	branch, err := i.gitWorktree.Checkpoint(message, now)
+	if err != nil {
+		return "", err
+	}
+	i.RecordEvent(EventCheckpoint, branch)
+	return branch, nil
+}
+
 func (i *Instance) Started() bool {
 	return i.started
 }
@@ -476,79 +877,165 @@ func (i *Instance) Paused() bool {
 	return i.Status == Paused
 }
 
+// Archived returns true if the instance's tmux session was closed to put it aside.
+func (i *Instance) Archived() bool {
+	return i.Status == Archived
+}
+
+// BranchCheckedOut returns true if the instance's branch is checked out in its repository, e.g. after checking it
+// out with the checkout action.
+func (i *Instance) BranchCheckedOut() bool {
+	if i.gitWorktree == nil {
+		return false
+	}
+	checkedOut, err := i.gitWorktree.IsBranchCheckedOut()
+	if err != nil {
+		log.WarningLog.Printf("could not check if the branch of %s is checked out: %v", i.Title, err)
+		return false
+	}
+	return checkedOut
+}
+
 // TmuxAlive returns true if the tmux session is alive. This is a sanity check before attaching.
 func (i *Instance) TmuxAlive() bool {
 	return i.tmuxSession.DoesSessionExist()
 }
 
-// Pause stops the tmux session and removes the worktree, preserving the branch
-func (i *Instance) Pause() error {
-	if !i.started {
-		return fmt.Errorf("cannot pause instance that has not been started")
+// StatusAfterExit is the status of an instance whose program ended as exit says: Exited if it exited with code 0,
+// and Dead if it crashed.
+func StatusAfterExit(exit tmux.ExitStatus) Status {
+	if exit.Code == 0 {
+		return Exited
 	}
-	if i.Status == Paused {
-		return fmt.Errorf("instance is already paused")
-	}
-
-	var errs []error
+	return Dead
+}
 
-	// Check if there are any changes to commit
-	if dirty, err := i.gitWorktree.IsDirty(); err != nil {
-		errs = append(errs, fmt.Errorf("failed to check if worktree is dirty: %w", err))
-		log.ErrorLog.Print(err)
-	} else if dirty {
-		// Commit changes locally (without pushing to GitHub)
-		commitMsg := fmt.Sprintf("[claudesquad] update from '%s' on %s (paused)", i.Title, time.Now().Format(time.RFC822))
-		if err := i.gitWorktree.CommitChanges(commitMsg); err != nil {
-			errs = append(errs, fmt.Errorf("failed to commit changes: %w", err))
-			log.ErrorLog.Print(err)
-			// Return early if we can't commit changes to avoid corrupted state
-			return i.combineErrors(errs)
+// CheckExited marks the instance Exited or Dead if its program ended or its tmux session is gone, and reports
+// whether it did. The exit is recorded in the activity log when the status changes.
+func (i *Instance) CheckExited() bool {
+	if !i.TmuxAlive() {
+		i.exitCode = -1
+		i.SetStatus(Dead)
+		return true
+	}
+	exit, err := i.tmuxSession.ExitStatus()
+	if err != nil {
+		log.WarningLog.Printf("could not check if the program of %s exited: %v", i.Title, err)
+		return false
+	}
+	if !exit.Exited {
+		return false
+	}
+	i.exitCode = exit.Code
+	if status := StatusAfterExit(exit); i.Status != status {
+		detail := fmt.Sprintf("exit code %d", exit.Code)
+		if exit.Code < 0 {
+			detail = "killed by a signal"
 		}
+		i.RecordEvent(EventExited, detail)
+		i.SetStatus(status)
 	}
+	return true
+}
 
-	// Detach from tmux session instead of closing to preserve session output
-	if err := i.tmuxSession.DetachSafely(); err != nil {
-		errs = append(errs, fmt.Errorf("failed to detach tmux session: %w", err))
-		log.ErrorLog.Print(err)
-		// Continue with pause process even if detach fails
+// ExitCode returns the exit code of the program once it has Exited or is Dead, or -1 if it is unknown.
+func (i *Instance) ExitCode() int {
+	return i.exitCode
+}
+
+// InPlace returns true if the program runs directly in the instance's Path, without a worktree or branch of its own.
+func (i *Instance) InPlace() bool {
+	return i.inPlace
+}
+
+// Pause stops the tmux session and removes the worktree, preserving the branch, and copies the branch name to
+// the clipboard so it can be checked out.
+func (i *Instance) Pause() error {
+	if err := i.PauseQuietly(); err != nil {
+		return err
 	}
+	_ = clipboard.WriteAll(i.gitWorktree.GetBranchName())
+	return nil
+}
 
-	// Check if worktree exists before trying to remove it
-	if _, err := os.Stat(i.gitWorktree.GetWorktreePath()); err == nil {
-		// Remove worktree but keep branch
-		if err := i.gitWorktree.Remove(); err != nil {
-			errs = append(errs, fmt.Errorf("failed to remove git worktree: %w", err))
-			log.ErrorLog.Print(err)
-			return i.combineErrors(errs)
-		}
+// PauseQuietly pauses the instance like Pause without touching the clipboard, for pauses the user didn't ask for.
+func (i *Instance) PauseQuietly() error {
+	if err := i.CanPause(); err != nil {
+		return err
+	}
+	if err := i.PauseResources(); err != nil {
+		return err
+	}
+	i.MarkPaused()
+	return nil
+}
 
-		// Only prune if remove was successful
-		if err := i.gitWorktree.Prune(); err != nil {
-			errs = append(errs, fmt.Errorf("failed to prune git worktrees: %w", err))
-			log.ErrorLog.Print(err)
-			return i.combineErrors(errs)
-		}
+// CanPause returns why the instance can't be paused, or nil if it can.
+func (i *Instance) CanPause() error {
+	if !i.started {
+		return fmt.Errorf("cannot pause instance that has not been started")
+	}
+	if i.Status == Paused {
+		return fmt.Errorf("instance is already paused")
+	}
+	if i.Status == Archived {
+		return fmt.Errorf("cannot pause an archived instance, restore it first")
+	}
+	if i.inPlace {
+		return fmt.Errorf("cannot pause in-place instance %s: it has no worktree of its own, archive it instead",
+			i.Title)
 	}
+	return nil
+}
 
-	if err := i.combineErrors(errs); err != nil {
+// PauseResources does the work of PauseQuietly without changing the instance, which MarkPaused does once it
+// succeeded. It only runs git in the worktree and detaches from the tmux session, so it can run in the background
+// while the instance is shown.
+func (i *Instance) PauseResources() error {
+	// Commit changes locally (without pushing to GitHub), then detach from the tmux session instead of closing it
+	// to preserve its output, and remove the worktree
+	commitMsg := fmt.Sprintf("%s update from '%s' on %s (paused)", git.CommitPrefix, i.Title,
+		time.Now().Format(time.RFC822))
+	if err := i.gitWorktree.Pause(commitMsg, i.tmuxSession.DetachSafely); err != nil {
 		log.ErrorLog.Print(err)
 		return err
 	}
+	return nil
+}
 
+// MarkPaused marks the instance paused once PauseResources succeeded.
+func (i *Instance) MarkPaused() {
 	i.SetStatus(Paused)
-	_ = clipboard.WriteAll(i.gitWorktree.GetBranchName())
-	return nil
+	i.lastActivity = time.Time{}
+	i.RecordEvent(EventPaused, "")
 }
 
 // Resume recreates the worktree and restarts the tmux session
 func (i *Instance) Resume() error {
+	if err := i.CanResume(); err != nil {
+		return err
+	}
+	if err := i.ResumeResources(); err != nil {
+		return err
+	}
+	i.MarkResumed()
+	return nil
+}
+
+// CanResume returns why the instance can't be resumed, or nil if it can.
+func (i *Instance) CanResume() error {
 	if !i.started {
 		return fmt.Errorf("cannot resume instance that has not been started")
 	}
 	if i.Status != Paused {
 		return fmt.Errorf("can only resume paused instances")
 	}
+	return nil
+}
+
+// ResumeResources does the work of Resume without changing the instance, which MarkResumed does once it succeeded.
+// It only runs git and tmux, so it can run in the background while the instance is shown.
+func (i *Instance) ResumeResources() error {
 
 	// Check if branch is checked out
 	if checked, err := i.gitWorktree.IsBranchCheckedOut(); err != nil {
@@ -592,8 +1079,126 @@ func (i *Instance) Resume() error {
 			return fmt.Errorf("failed to start new session: %w", err)
 		}
 	}
+	return nil
+}
 
+// MarkResumed marks the instance running once ResumeResources succeeded.
+func (i *Instance) MarkResumed() {
 	i.SetStatus(Running)
+	i.RecordEvent(EventResumed, "")
+}
+
+// Restart relaunches the program of an instance whose tmux session or program has died. The existing worktree
+// and branch are reused. The instance is Loading while the program starts.
+func (i *Instance) Restart() error {
+	if !i.started {
+		return fmt.Errorf("cannot restart instance that has not been started")
+	}
+	if i.Status == Paused {
+		return fmt.Errorf("cannot restart a paused instance, resume it instead")
+	}
+	if i.Status == Archived {
+		return fmt.Errorf("cannot restart an archived instance, restore it instead")
+	}
+	if i.TmuxAlive() {
+		if exit, err := i.tmuxSession.ExitStatus(); err != nil || !exit.Exited {
+			return fmt.Errorf("instance %s is still running", i.Title)
+		}
+	}
+	if _, err := os.Stat(i.gitWorktree.GetWorktreePath()); err != nil {
+		return fmt.Errorf("cannot restart instance %s: worktree is missing: %w", i.Title, err)
+	}
+
+	i.SetStatus(Loading)
+	// Release the PTY and the pane the program exited in. Killing the session fails if tmux already removed it.
+	_ = i.tmuxSession.Close()
+	if err := i.tmuxSession.Start(i.gitWorktree.GetWorktreePath()); err != nil {
+		i.SetStatus(Dead)
+		return fmt.Errorf("failed to restart tmux session for %s: %w", i.Title, err)
+	}
+
+	i.SetStatus(Running)
+	i.RecordEvent(EventRestarted, "")
+	return nil
+}
+
+// Archive closes the instance's tmux session to free its resources. The worktree, branch and stored record are
+// kept, so Unarchive can bring the instance back.
+func (i *Instance) Archive() error {
+	if !i.started {
+		return fmt.Errorf("cannot archive instance that has not been started")
+	}
+	switch i.Status {
+	case Archived:
+		return fmt.Errorf("instance is already archived")
+	case Paused:
+		return fmt.Errorf("cannot archive a paused instance, resume it first")
+	case Loading, Deleting:
+		return fmt.Errorf("cannot archive instance %s while it is busy", i.Title)
+	}
+
+	// Killing the session fails if the program died and tmux already removed it, so only the PTY is released.
+	alive := i.TmuxAlive()
+	if err := i.tmuxSession.Close(); err != nil && alive {
+		return fmt.Errorf("failed to close tmux session of %s: %w", i.Title, err)
+	}
+
+	i.SetStatus(Archived)
+	i.lastActivity = time.Time{}
+	i.RecordEvent(EventArchived, "")
+	return nil
+}
+
+// DefaultInterruptKeys are the tmux keys Interrupt sends when none are configured.
+var DefaultInterruptKeys = []string{"C-c"}
+
+// Interrupt sends the tmux keys in keys (e.g. C-c or Escape) to the program, or DefaultInterruptKeys if keys is
+// empty, to stop what it is doing without attaching. It only works on started instances whose tmux session is
+// alive.
+func (i *Instance) Interrupt(keys []string) error {
+	if !i.started {
+		return fmt.Errorf("cannot interrupt instance that has not been started")
+	}
+	switch i.Status {
+	case Paused:
+		return fmt.Errorf("cannot interrupt paused instance %s", i.Title)
+	case Archived:
+		return fmt.Errorf("cannot interrupt archived instance %s", i.Title)
+	}
+	if !i.TmuxAlive() {
+		return fmt.Errorf("cannot interrupt instance %s: its program is not running", i.Title)
+	}
+	if len(keys) == 0 {
+		keys = DefaultInterruptKeys
+	}
+	if err := i.tmuxSession.SendKeyNames(keys...); err != nil {
+		return fmt.Errorf("failed to interrupt %s: %w", i.Title, err)
+	}
+	i.RecordEvent(EventInterrupted, strings.Join(keys, " "))
+	return nil
+}
+
+// Unarchive relaunches the program of an archived instance in its worktree. The instance is Loading while the
+// program starts and stays archived if that fails.
+func (i *Instance) Unarchive() error {
+	if !i.started {
+		return fmt.Errorf("cannot restore instance that has not been started")
+	}
+	if i.Status != Archived {
+		return fmt.Errorf("can only restore archived instances")
+	}
+	if _, err := os.Stat(i.gitWorktree.GetWorktreePath()); err != nil {
+		return fmt.Errorf("cannot restore instance %s: worktree is missing: %w", i.Title, err)
+	}
+
+	i.SetStatus(Loading)
+	if err := i.tmuxSession.Start(i.gitWorktree.GetWorktreePath()); err != nil {
+		i.SetStatus(Archived)
+		return fmt.Errorf("failed to restore tmux session for %s: %w", i.Title, err)
+	}
+
+	i.SetStatus(Running)
+	i.RecordEvent(EventRestored, "")
 	return nil
 }
 
@@ -601,6 +1206,7 @@ func (i *Instance) Resume() error {
 func (i *Instance) UpdateDiffStats() error {
 	if !i.started {
 		i.diffStats = nil
+		i.diffCache = nil
 		return nil
 	}
 
@@ -609,7 +1215,11 @@ func (i *Instance) UpdateDiffStats() error {
 		return nil
 	}
 
-	stats := i.gitWorktree.Diff()
+	// Don't wait for a push or pause running in the background: the previous stats are kept until the next update
+	stats := i.gitWorktree.TryDiff(i.diffOptions)
+	if errors.Is(stats.Error, git.ErrBusy) {
+		return nil
+	}
 	if stats.Error != nil {
 		if strings.Contains(stats.Error.Error(), "base commit SHA not set") {
 			// Worktree is not fully set up yet, not an error
@@ -620,18 +1230,87 @@ func (i *Instance) UpdateDiffStats() error {
 	}
 
 	i.diffStats = stats
+	if i.diffCache == nil {
+		i.diffCache = make(map[git.DiffOptions]*git.DiffStats)
+	}
+	i.diffCache[i.diffOptions] = stats
+	return nil
+}
+
+// ConflictCheckInterval is how often UpdateConflicts checks an instance's branch for conflicts with its base
+// branch. A check merges the branch in memory, so it isn't done on every metadata tick.
+const ConflictCheckInterval = 30 * time.Second
+
+// UpdateConflicts checks whether the instance branch conflicts with its base branch, unless it was already checked
+// within ConflictCheckInterval of now. Paused instances keep the result of their last check, and so do instances
+// busy with another git operation, which are checked again on the next update.
+func (i *Instance) UpdateConflicts(now time.Time) error {
+	if !i.started {
+		i.conflicts = nil
+		return nil
+	}
+	if i.Status == Paused || now.Sub(i.conflictsCheckedAt) < ConflictCheckInterval {
+		return nil
+	}
+	conflicts, err := i.gitWorktree.Conflicts()
+	if errors.Is(err, git.ErrBusy) {
+		return nil
+	}
+	i.conflictsCheckedAt = now
+	if err != nil {
+		return err
+	}
+	i.conflicts = conflicts
 	return nil
 }
 
+// RecheckConflicts makes the next UpdateConflicts check for conflicts however recently the last check was.
+func (i *Instance) RecheckConflicts() {
+	i.conflictsCheckedAt = time.Time{}
+}
+
+// Conflicts returns the files that conflicted with the base branch when the instance was last checked.
+func (i *Instance) Conflicts() []string {
+	return i.conflicts
+}
+
 // GetDiffStats returns the current git diff statistics
 func (i *Instance) GetDiffStats() *git.DiffStats {
 	return i.diffStats
 }
 
+// Patch returns the diff of the instance as a patch that git apply accepts. It is computed again in the mode of
+// the instance's diff options, but with git's default formatting and binary files, so that it applies whatever the
+// diff is shown with.
+func (i *Instance) Patch() (string, error) {
+	if !i.started {
+		return "", fmt.Errorf("instance not started")
+	}
+	return i.gitWorktree.Patch(i.diffOptions.Mode)
+}
+
+// DiffOptions returns the options the instance's diff is computed with.
+func (i *Instance) DiffOptions() git.DiffOptions {
+	return i.diffOptions
+}
+
+// SetDiffOptions changes how the instance's diff is computed. If a diff was already computed with opts, it is
+// used right away and true is returned. Otherwise the current diff stays until the next UpdateDiffStats.
+func (i *Instance) SetDiffOptions(opts git.DiffOptions) bool {
+	if opts == i.diffOptions {
+		return true
+	}
+	i.diffOptions = opts
+	if stats, ok := i.diffCache[opts]; ok {
+		i.diffStats = stats
+		return true
+	}
+	return false
+}
+
 // WaitForInputReady waits for the program to be ready to accept input.
-// It polls the tmux pane content and waits until it stabilizes (stops changing).
-// The function requires seeing at least one content change before checking for stability,
-// ensuring the program has actually started producing output.
+// It polls the tmux pane content and waits until it stabilizes (stops changing), see readyDetector.
+// Returns an error if it isn't ready within timeout.
 func (i *Instance) WaitForInputReady(timeout time.Duration) error {
 	if !i.started {
 		return fmt.Errorf("instance not started")
@@ -642,44 +1321,62 @@ func (i *Instance) WaitForInputReady(timeout time.Duration) error {
 
 	startTime := time.Now()
 	pollInterval := 100 * time.Millisecond
-	stableThreshold := 1 * time.Second // Content must be stable for this long
-	minContentLength := 50             // Minimum content length to consider "started"
-
-	var lastContent string
-	var lastChangeTime time.Time
-	seenContentChange := false
-	seenSubstantialContent := false
+	var detector readyDetector
 
 	for time.Since(startTime) < timeout {
 		content, err := i.tmuxSession.CapturePaneContent()
-		if err != nil {
-			time.Sleep(pollInterval)
-			continue
-		}
-
-		// Check if we have substantial content (program has started)
-		if len(content) >= minContentLength {
-			seenSubstantialContent = true
-		}
-
-		if content != lastContent {
-			if lastContent != "" {
-				seenContentChange = true
-			}
-			lastContent = content
-			lastChangeTime = time.Now()
-		} else if seenSubstantialContent && seenContentChange && time.Since(lastChangeTime) >= stableThreshold {
-			// Content has been stable for the threshold duration
-			// and we've seen the program produce output
+		if err == nil && detector.observe(content, time.Now()) {
 			return nil
 		}
-
 		time.Sleep(pollInterval)
 	}
 
-	// Timeout reached - return nil anyway to allow the prompt to be sent
-	// It's better to try than to block forever
-	return nil
+	return fmt.Errorf("program was not ready for input after %s", timeout)
+}
+
+const (
+	// readyStableThreshold is how long the pane must stay unchanged after it changed for the program to be ready.
+	readyStableThreshold = 1 * time.Second
+	// readyUnchangedThreshold is how long the pane must stay unchanged if it was never seen changing, e.g. because
+	// the program was already done starting when the pane was first captured.
+	readyUnchangedThreshold = 3 * time.Second
+	// readyMinContentLength is the minimum length of the pane content for the program to count as started.
+	readyMinContentLength = 50
+)
+
+// readyDetector decides from successive captures of a pane whether its program is ready for input: the pane shows
+// substantial content that stopped changing. A pane that changed is ready once it was stable for
+// readyStableThreshold, and one that never changed once it was stable for the longer readyUnchangedThreshold.
+type readyDetector struct {
+	lastContent     string
+	lastChange      time.Time
+	captured        bool
+	seenChange      bool
+	seenSubstantial bool
+}
+
+// observe records the pane content captured at now and returns whether the program is ready.
+func (d *readyDetector) observe(content string, now time.Time) bool {
+	if len(content) >= readyMinContentLength {
+		d.seenSubstantial = true
+	}
+	if !d.captured || content != d.lastContent {
+		if d.captured {
+			d.seenChange = true
+		}
+		d.captured = true
+		d.lastContent = content
+		d.lastChange = now
+		return false
+	}
+	if !d.seenSubstantial {
+		return false
+	}
+	stable := now.Sub(d.lastChange)
+	if d.seenChange {
+		return stable >= readyStableThreshold
+	}
+	return stable >= readyUnchangedThreshold
 }
 
 // SendPrompt sends a prompt to the tmux session
@@ -690,7 +1387,11 @@ func (i *Instance) SendPrompt(prompt string) error {
 	if i.tmuxSession == nil {
 		return fmt.Errorf("tmux session not initialized")
 	}
-	if err := i.tmuxSession.SendKeys(prompt); err != nil {
+	sent := prompt
+	if i.isCommand != nil {
+		sent = stripCommandSlash(prompt, i.isCommand)
+	}
+	if err := i.tmuxSession.SendKeys(sent); err != nil {
 		return fmt.Errorf("error sending keys to tmux session: %w", err)
 	}
 
@@ -700,12 +1401,37 @@ func (i *Instance) SendPrompt(prompt string) error {
 		return fmt.Errorf("error tapping enter: %w", err)
 	}
 
+	i.lastPrompt = prompt
+	i.RecordEvent(EventPrompt, prompt)
 	return nil
 }
 
+// SetStripCommandSlash makes the known slash commands be sent without their leading slash. isCommand reports whether
+// a word like "/commit" is a known command, so prompts like "/path/to/file" are sent as they are. Nil sends every
+// prompt as it is. The prompt is still remembered with the slash.
+func (i *Instance) SetStripCommandSlash(isCommand func(command string) bool) {
+	i.isCommand = isCommand
+}
+
+// stripCommandSlash returns prompt without the leading slash if it starts with a slash command like "/commit" that
+// isCommand knows.
+func stripCommandSlash(prompt string, isCommand func(command string) bool) string {
+	if len(prompt) < 2 || prompt[0] != '/' || unicode.IsSpace(rune(prompt[1])) || prompt[1] == '/' {
+		return prompt
+	}
+	command := prompt
+	if end := strings.IndexFunc(prompt, unicode.IsSpace); end >= 0 {
+		command = prompt[:end]
+	}
+	if !isCommand(command) {
+		return prompt
+	}
+	return prompt[1:]
+}
+
 // PreviewFullHistory captures the entire tmux pane output including full scrollback history
 func (i *Instance) PreviewFullHistory() (string, error) {
-	if !i.started || i.Status == Paused {
+	if !i.started || i.Status == Paused || i.Status == Archived {
 		return "", nil
 	}
 	content, err := i.tmuxSession.CapturePaneContentWithOptions("-", "-")
@@ -716,6 +1442,33 @@ func (i *Instance) PreviewFullHistory() (string, error) {
 	return content, nil
 }
 
+// RecordOutputHistory captures the pane with its scrollback and adds it to the instance's output history. The
+// first capture takes the whole scrollback, and later ones only its last lines.
+func (i *Instance) RecordOutputHistory() error {
+	if !i.started || i.Status == Paused || i.Status == Archived {
+		return nil
+	}
+	start := "-"
+	if i.OutputHistory().Version() > 0 {
+		start = fmt.Sprintf("-%d", historyCaptureLines)
+	}
+	content, err := i.tmuxSession.CapturePaneContentWithOptions(start, "-")
+	if err != nil {
+		return err
+	}
+	i.OutputHistory().Merge(content)
+	return nil
+}
+
+// OutputHistory returns the output of the instance's program retained so far, including output that tmux no
+// longer has in its scrollback.
+func (i *Instance) OutputHistory() *OutputHistory {
+	if i.outputHistory == nil {
+		i.outputHistory = NewOutputHistory(maxOutputHistoryLines)
+	}
+	return i.outputHistory
+}
+
 // SetTmuxSession sets the tmux session for testing purposes
 func (i *Instance) SetTmuxSession(session *tmux.TmuxSession) {
 	i.tmuxSession = session
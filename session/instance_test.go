@@ -0,0 +1,721 @@
+package session
+
+import (
+	"claude-squad/cmd/cmd_test"
+	"claude-squad/log"
+	"claude-squad/session/git"
+	"claude-squad/session/tmux"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	log.Initialize(false)
+	defer log.Close()
+	os.Exit(m.Run())
+}
+
+// filePtyFactory is a tmux.PtyFactory that hands out temporary files instead of PTYs.
+type filePtyFactory struct {
+	t    *testing.T
+	cmds []*exec.Cmd
+	// paths are the files handed out, in order.
+	paths []string
+}
+
+func (f *filePtyFactory) Start(cmd *exec.Cmd) (*os.File, error) {
+	f.cmds = append(f.cmds, cmd)
+	path := filepath.Join(f.t.TempDir(), fmt.Sprintf("pty-%d", len(f.cmds)))
+	f.paths = append(f.paths, path)
+	return os.Create(path)
+}
+
+func (f *filePtyFactory) Close() {}
+
+// newRestartTestInstance returns a started instance whose tmux session is alive as reported by alive, which is
+// passed the PTY factory to see which tmux commands were started.
+func newRestartTestInstance(t *testing.T, alive func(*filePtyFactory) bool) (*Instance, *filePtyFactory) {
+	// Keep the activity log out of the real config directory.
+	t.Setenv("HOME", t.TempDir())
+	ptyFactory := &filePtyFactory{t: t}
+	cmdExec := cmd_test.MockCmdExec{
+		RunFunc: func(cmd *exec.Cmd) error {
+			if strings.Contains(cmd.String(), "has-session") && !alive(ptyFactory) {
+				return fmt.Errorf("no such session")
+			}
+			return nil
+		},
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			return nil, nil
+		},
+	}
+	worktreePath := t.TempDir()
+	instance := &Instance{
+		Title:       "crashed",
+		Status:      Dead,
+		Program:     "my-agent",
+		started:     true,
+		tmuxSession: tmux.NewTmuxSessionWithDeps("crashed", "my-agent", ptyFactory, cmdExec),
+		gitWorktree: git.NewGitWorktreeFromStorage(t.TempDir(), worktreePath, "crashed", "user/crashed", "abc123", "main"),
+	}
+	return instance, ptyFactory
+}
+
+func TestRestart(t *testing.T) {
+	t.Run("relaunches the program in the existing worktree", func(t *testing.T) {
+		// The session comes up once tmux new-session has been run.
+		instance, ptyFactory := newRestartTestInstance(t, func(f *filePtyFactory) bool { return len(f.cmds) > 0 })
+
+		require.NoError(t, instance.Restart())
+
+		assert.Equal(t, Running, instance.Status)
+		require.NotEmpty(t, ptyFactory.cmds)
+		newSession := strings.Join(ptyFactory.cmds[0].Args, " ")
+		assert.Contains(t, newSession, "new-session")
+		assert.Contains(t, newSession, "-c "+instance.gitWorktree.GetWorktreePath())
+		assert.Equal(t, "user/crashed", instance.gitWorktree.GetBranchName())
+
+		activity, err := instance.ActivityLog()
+		require.NoError(t, err)
+		events, err := activity.Events()
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, EventRestarted, events[0].Kind)
+	})
+
+	t.Run("refuses to restart a live session", func(t *testing.T) {
+		instance, ptyFactory := newRestartTestInstance(t, func(*filePtyFactory) bool { return true })
+		instance.Status = Ready
+
+		err := instance.Restart()
+
+		assert.ErrorContains(t, err, "still running")
+		assert.Equal(t, Ready, instance.Status)
+		assert.Empty(t, ptyFactory.cmds)
+	})
+
+	t.Run("refuses to restart a paused instance", func(t *testing.T) {
+		instance, _ := newRestartTestInstance(t, func(*filePtyFactory) bool { return false })
+		instance.Status = Paused
+
+		assert.ErrorContains(t, instance.Restart(), "resume it instead")
+		assert.Equal(t, Paused, instance.Status)
+	})
+
+	t.Run("requires the worktree", func(t *testing.T) {
+		instance, ptyFactory := newRestartTestInstance(t, func(*filePtyFactory) bool { return false })
+		require.NoError(t, os.Remove(instance.gitWorktree.GetWorktreePath()))
+
+		assert.ErrorContains(t, instance.Restart(), "worktree is missing")
+		assert.Equal(t, Dead, instance.Status)
+		assert.Empty(t, ptyFactory.cmds)
+	})
+
+	t.Run("requires a started instance", func(t *testing.T) {
+		instance, err := NewInstance(InstanceOptions{Title: "new", Path: t.TempDir(), Program: "claude"})
+		require.NoError(t, err)
+
+		assert.Error(t, instance.Restart())
+	})
+}
+
+// newExitTestInstance returns a started, Running instance whose pane reports paneStatus as its
+// "#{pane_dead} #{pane_dead_status}". The session is up until it is killed, and again once new-session has been run.
+func newExitTestInstance(t *testing.T, paneStatus string) (*Instance, *filePtyFactory) {
+	killed := false
+	instance, ptyFactory := newRestartTestInstance(t, nil)
+	instance.Status = Running
+	instance.tmuxSession = tmux.NewTmuxSessionWithDeps("crashed", "my-agent", ptyFactory, cmd_test.MockCmdExec{
+		RunFunc: func(cmd *exec.Cmd) error {
+			switch {
+			case strings.Contains(cmd.String(), "kill-session"):
+				killed = true
+			case strings.Contains(cmd.String(), "has-session") && killed && len(ptyFactory.cmds) == 0:
+				return fmt.Errorf("no such session")
+			}
+			return nil
+		},
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			return []byte(paneStatus), nil
+		},
+	})
+	return instance, ptyFactory
+}
+
+func TestStatusAfterExit(t *testing.T) {
+	assert.Equal(t, Exited, StatusAfterExit(tmux.ExitStatus{Exited: true, Code: 0}))
+	assert.Equal(t, Dead, StatusAfterExit(tmux.ExitStatus{Exited: true, Code: 1}))
+	assert.Equal(t, Dead, StatusAfterExit(tmux.ExitStatus{Exited: true, Code: -1}))
+}
+
+func TestCheckExited(t *testing.T) {
+	t.Run("running program", func(t *testing.T) {
+		instance, _ := newExitTestInstance(t, "0 \n")
+		assert.False(t, instance.CheckExited())
+		assert.Equal(t, Running, instance.Status)
+	})
+
+	t.Run("clean exit", func(t *testing.T) {
+		instance, _ := newExitTestInstance(t, "1 0\n")
+		assert.True(t, instance.CheckExited())
+		assert.Equal(t, Exited, instance.Status)
+		assert.Equal(t, 0, instance.ExitCode())
+		// The exit is only recorded once.
+		assert.True(t, instance.CheckExited())
+
+		activity, err := instance.ActivityLog()
+		require.NoError(t, err)
+		events, err := activity.Events()
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, EventExited, events[0].Kind)
+		assert.Equal(t, "exit code 0", events[0].Detail)
+	})
+
+	t.Run("crash", func(t *testing.T) {
+		instance, _ := newExitTestInstance(t, "1 137\n")
+		assert.True(t, instance.CheckExited())
+		assert.Equal(t, Dead, instance.Status)
+		assert.Equal(t, 137, instance.ExitCode())
+	})
+
+	t.Run("session gone", func(t *testing.T) {
+		instance, _ := newRestartTestInstance(t, func(*filePtyFactory) bool { return false })
+		instance.Status = Ready
+		assert.True(t, instance.CheckExited())
+		assert.Equal(t, Dead, instance.Status)
+		assert.Equal(t, -1, instance.ExitCode())
+	})
+
+	t.Run("restart replaces the pane of the exited program", func(t *testing.T) {
+		instance, ptyFactory := newExitTestInstance(t, "1 0\n")
+		require.True(t, instance.CheckExited())
+
+		require.NoError(t, instance.Restart())
+		assert.Equal(t, Running, instance.Status)
+		require.NotEmpty(t, ptyFactory.cmds)
+		assert.Contains(t, strings.Join(ptyFactory.cmds[0].Args, " "), "new-session")
+	})
+}
+
+func TestArchive(t *testing.T) {
+	t.Run("closes the session and restores it later", func(t *testing.T) {
+		// The session is up until it is archived, and again once new-session has been run.
+		archived := false
+		instance, ptyFactory := newRestartTestInstance(t, func(f *filePtyFactory) bool {
+			return !archived || len(f.cmds) > 0
+		})
+		instance.Status = Ready
+
+		require.NoError(t, instance.Archive())
+		archived = true
+		assert.Equal(t, Archived, instance.Status)
+		assert.True(t, instance.Archived())
+		assert.Empty(t, ptyFactory.cmds)
+
+		assert.ErrorContains(t, instance.Archive(), "already archived")
+		assert.ErrorContains(t, instance.Restart(), "restore it instead")
+		assert.ErrorContains(t, instance.Pause(), "restore it first")
+
+		require.NoError(t, instance.Unarchive())
+		assert.Equal(t, Running, instance.Status)
+		require.NotEmpty(t, ptyFactory.cmds)
+		assert.Contains(t, strings.Join(ptyFactory.cmds[0].Args, " "), "-c "+instance.gitWorktree.GetWorktreePath())
+
+		activity, err := instance.ActivityLog()
+		require.NoError(t, err)
+		events, err := activity.Events()
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, EventArchived, events[0].Kind)
+		assert.Equal(t, EventRestored, events[1].Kind)
+	})
+
+	t.Run("archives an instance whose program died", func(t *testing.T) {
+		instance, _ := newRestartTestInstance(t, func(*filePtyFactory) bool { return false })
+
+		require.NoError(t, instance.Archive())
+		assert.Equal(t, Archived, instance.Status)
+	})
+
+	t.Run("refuses to archive a paused instance", func(t *testing.T) {
+		instance, _ := newRestartTestInstance(t, func(*filePtyFactory) bool { return false })
+		instance.Status = Paused
+
+		assert.ErrorContains(t, instance.Archive(), "resume it first")
+		assert.Equal(t, Paused, instance.Status)
+	})
+
+	t.Run("restoring requires the worktree", func(t *testing.T) {
+		instance, ptyFactory := newRestartTestInstance(t, func(*filePtyFactory) bool { return false })
+		instance.Status = Archived
+		require.NoError(t, os.Remove(instance.gitWorktree.GetWorktreePath()))
+
+		assert.ErrorContains(t, instance.Unarchive(), "worktree is missing")
+		assert.Equal(t, Archived, instance.Status)
+		assert.Empty(t, ptyFactory.cmds)
+	})
+
+	t.Run("only archived instances can be restored", func(t *testing.T) {
+		instance, _ := newRestartTestInstance(t, func(*filePtyFactory) bool { return true })
+		instance.Status = Ready
+
+		assert.ErrorContains(t, instance.Unarchive(), "only restore archived")
+	})
+
+	t.Run("archived instances are loaded without a session", func(t *testing.T) {
+		instance, _ := newRestartTestInstance(t, func(*filePtyFactory) bool { return false })
+		instance.Status = Archived
+
+		loaded, err := FromInstanceData(instance.ToInstanceData())
+		require.NoError(t, err)
+		assert.True(t, loaded.Started())
+		assert.Equal(t, Archived, loaded.Status)
+		assert.False(t, loaded.Recovered())
+	})
+}
+
+// collectProgress runs StartWithProgress and returns every progress update it sends.
+func collectProgress(instance *Instance, firstTimeSetup bool) []InitProgress {
+	ch := make(chan InitProgress, 10)
+	go instance.StartWithProgress(context.Background(), firstTimeSetup, ch)
+	var updates []InitProgress
+	for p := range ch {
+		updates = append(updates, p)
+	}
+	return updates
+}
+
+func TestInterrupt(t *testing.T) {
+	t.Run("sends the keys to a live session", func(t *testing.T) {
+		instance, _ := newRestartTestInstance(t, func(*filePtyFactory) bool { return true })
+		instance.Status = Running
+
+		require.NoError(t, instance.Interrupt(nil))
+		require.NoError(t, instance.Interrupt([]string{"Escape"}))
+
+		activity, err := instance.ActivityLog()
+		require.NoError(t, err)
+		events, err := activity.Events()
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, Event{Time: events[0].Time, Kind: EventInterrupted, Detail: "C-c"}, events[0])
+		assert.Equal(t, "Escape", events[1].Detail)
+	})
+
+	t.Run("refuses instances without a running program", func(t *testing.T) {
+		instance, _ := newRestartTestInstance(t, func(*filePtyFactory) bool { return false })
+		assert.ErrorContains(t, instance.Interrupt(nil), "not running")
+
+		instance.Status = Paused
+		assert.ErrorContains(t, instance.Interrupt(nil), "paused")
+		instance.Status = Archived
+		assert.ErrorContains(t, instance.Interrupt(nil), "archived")
+
+		assert.ErrorContains(t, (&Instance{Title: "new"}).Interrupt(nil), "not been started")
+	})
+}
+
+func TestObserveCommand(t *testing.T) {
+	instance, _ := newRestartTestInstance(t, func(*filePtyFactory) bool { return true })
+	instance.Status = Running
+
+	command, err := instance.ObserveCommand()
+	require.NoError(t, err)
+	assert.Equal(t, "tmux attach-session -t =claudesquad_crashed -r", command)
+
+	instance.Status = Paused
+	_, err = instance.ObserveCommand()
+	assert.ErrorContains(t, err, "paused")
+	_, err = (&Instance{Title: "new"}).ObserveCommand()
+	assert.ErrorContains(t, err, "not been started")
+}
+
+func TestStartWithProgress(t *testing.T) {
+	t.Run("restoring reports numbered stages", func(t *testing.T) {
+		instance, _ := newRestartTestInstance(t, func(*filePtyFactory) bool { return true })
+		instance.started = false
+
+		updates := collectProgress(instance, false)
+
+		require.Len(t, updates, 3)
+		assert.Equal(t, StageStartingTmux, updates[0].Stage)
+		assert.Equal(t, "[1/2]", updates[0].Counter())
+		assert.Equal(t, StageWaitingForAgent, updates[1].Stage)
+		assert.Equal(t, "[2/2]", updates[1].Counter())
+		assert.Equal(t, StageComplete, updates[2].Stage)
+		assert.Equal(t, Running, instance.Status)
+	})
+
+	t.Run("failure keeps the failed stage", func(t *testing.T) {
+		instance, _ := newRestartTestInstance(t, func(*filePtyFactory) bool { return true })
+		instance.started = false
+		instance.gitWorktree = nil
+		// Not a git repository, so creating the worktree fails.
+		instance.Path = t.TempDir()
+
+		updates := collectProgress(instance, true)
+
+		require.Len(t, updates, 2)
+		assert.Equal(t, StageCreatingWorktree, updates[0].Stage)
+		assert.Equal(t, "[1/4]", updates[0].Counter())
+		failed := updates[1]
+		assert.Equal(t, StageFailed, failed.Stage)
+		assert.Equal(t, "[1/4]", failed.Counter())
+		assert.ErrorContains(t, failed.Error, "failed to create git worktree")
+	})
+
+	t.Run("empty title fails before any stage", func(t *testing.T) {
+		updates := collectProgress(&Instance{}, true)
+
+		require.Len(t, updates, 1)
+		assert.Equal(t, StageFailed, updates[0].Stage)
+		assert.Empty(t, updates[0].Counter())
+	})
+}
+
+// newRepoTestInstance returns a new instance in a fresh git repository whose tmux commands are mocked. The tmux
+// session exists once new-session has been run, and every command run through tmux is recorded in ran.
+func newRepoTestInstance(t *testing.T) (instance *Instance, ran *[]string) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	repo := t.TempDir()
+	for _, args := range [][]string{{"init", "-q"}, {"commit", "-q", "--allow-empty", "-m", "initial"}} {
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	ran = &[]string{}
+	ptyFactory := &filePtyFactory{t: t}
+	cmdExec := cmd_test.MockCmdExec{
+		RunFunc: func(cmd *exec.Cmd) error {
+			*ran = append(*ran, cmd.String())
+			if strings.Contains(cmd.String(), "has-session") && len(ptyFactory.cmds) == 0 {
+				return fmt.Errorf("no such session")
+			}
+			return nil
+		},
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			return nil, nil
+		},
+	}
+	instance, err := NewInstance(InstanceOptions{Title: "cancelled", Path: repo, Program: "my-agent"})
+	require.NoError(t, err)
+	instance.tmuxSession = tmux.NewTmuxSessionWithDeps("cancelled", "my-agent", ptyFactory, cmdExec)
+	return instance, ran
+}
+
+func TestNewInstanceWithExecutor(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	repo := t.TempDir()
+	for _, args := range [][]string{{"init", "-q"}, {"commit", "-q", "--allow-empty", "-m", "initial"}} {
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	// git commands run for real so the worktree is created, and tmux commands are faked. The session exists once
+	// new-session has been started in the fake PTY.
+	var gitCmds, tmuxCmds []string
+	ptyFactory := &filePtyFactory{t: t}
+	cmdExec := cmd_test.MockCmdExec{
+		RunFunc: func(cmd *exec.Cmd) error {
+			tmuxCmds = append(tmuxCmds, strings.Join(cmd.Args, " "))
+			if strings.Contains(cmd.String(), "has-session") && len(ptyFactory.cmds) == 0 {
+				return fmt.Errorf("no such session")
+			}
+			return nil
+		},
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			if filepath.Base(cmd.Path) != "git" {
+				return nil, nil
+			}
+			gitCmds = append(gitCmds, strings.Join(cmd.Args[3:], " "))
+			return cmd.Output()
+		},
+		CombinedOutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			if filepath.Base(cmd.Path) != "git" {
+				tmuxCmds = append(tmuxCmds, strings.Join(cmd.Args, " "))
+				return nil, nil
+			}
+			gitCmds = append(gitCmds, strings.Join(cmd.Args[3:], " "))
+			return cmd.CombinedOutput()
+		},
+	}
+
+	instance, err := NewInstance(InstanceOptions{
+		Title:      "faked",
+		Path:       repo,
+		Program:    "my-agent",
+		Executor:   cmdExec,
+		PtyFactory: ptyFactory,
+	})
+	require.NoError(t, err)
+	require.NoError(t, instance.Start(true))
+	t.Cleanup(func() { _ = instance.gitWorktree.Cleanup() })
+
+	assert.Equal(t, Running, instance.Status)
+	assert.DirExists(t, instance.gitWorktree.GetWorktreePath())
+	assert.Contains(t, gitCmds, "rev-parse --show-toplevel", "the repo root is found with the executor")
+	assert.Contains(t, gitCmds, "rev-parse HEAD")
+	require.NotEmpty(t, ptyFactory.cmds)
+	assert.Contains(t, strings.Join(ptyFactory.cmds[0].Args, " "), "new-session -d -s claudesquad_faked")
+	assert.Contains(t, tmuxCmds, "tmux has-session -t=claudesquad_faked")
+}
+
+// boundaryContext is cancelled at the given stage boundary of StartWithProgress, which checks ctx.Err once before
+// it begins and then after each stage. Cancelling from the test instead would race with the stage's work.
+type boundaryContext struct {
+	context.Context
+	checks int
+}
+
+func (c *boundaryContext) Err() error {
+	c.checks--
+	if c.checks < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+// startAndCancelAfter starts instance, cancels it after the given number of stages and returns the last update.
+func startAndCancelAfter(instance *Instance, stages int) InitProgress {
+	ch := make(chan InitProgress)
+	go instance.StartWithProgress(&boundaryContext{Context: context.Background(), checks: stages}, true, ch)
+
+	var last InitProgress
+	for p := range ch {
+		last = p
+	}
+	return last
+}
+
+func TestStartWithProgressCancel(t *testing.T) {
+	t.Run("after creating the worktree", func(t *testing.T) {
+		instance, ran := newRepoTestInstance(t)
+
+		last := startAndCancelAfter(instance, 1)
+
+		assert.Equal(t, StageFailed, last.Stage)
+		assert.True(t, errors.Is(last.Error, context.Canceled))
+		assert.Equal(t, "[1/4]", last.Counter())
+		assert.False(t, instance.Started())
+		assert.NoDirExists(t, instance.gitWorktree.GetWorktreePath())
+		branches, err := exec.Command("git", "-C", instance.Path, "branch", "--list", instance.Branch).Output()
+		require.NoError(t, err)
+		assert.Empty(t, strings.TrimSpace(string(branches)))
+		for _, cmd := range *ran {
+			assert.NotContains(t, cmd, "kill-session")
+		}
+	})
+
+	t.Run("after launching tmux", func(t *testing.T) {
+		instance, ran := newRepoTestInstance(t)
+
+		last := startAndCancelAfter(instance, 2)
+
+		assert.Equal(t, StageFailed, last.Stage)
+		assert.True(t, errors.Is(last.Error, context.Canceled))
+		assert.Equal(t, "[2/4]", last.Counter())
+		assert.False(t, instance.Started())
+		assert.NoDirExists(t, instance.gitWorktree.GetWorktreePath())
+		assert.Contains(t, strings.Join(*ran, "\n"), "kill-session")
+	})
+}
+
+func TestDiffOptionsCache(t *testing.T) {
+	instance, _ := newRepoTestInstance(t)
+	writeFile := func(dir, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte(content), 0644))
+	}
+	writeFile(instance.Path, "a b\n")
+	for _, args := range [][]string{{"add", "a.txt"}, {"commit", "-q", "-m", "add a.txt"}} {
+		out, err := exec.Command("git", append([]string{"-C", instance.Path}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	updates := collectProgress(instance, true)
+	require.Equal(t, StageComplete, updates[len(updates)-1].Stage)
+	worktree := instance.gitWorktree.GetWorktreePath()
+
+	// A whitespace-only change.
+	writeFile(worktree, "a  b\n")
+	require.NoError(t, instance.UpdateDiffStats())
+	require.Equal(t, 1, instance.GetDiffStats().Added)
+
+	ignoreWhitespace := git.DiffOptions{IgnoreWhitespace: true}
+	assert.False(t, instance.SetDiffOptions(ignoreWhitespace), "nothing is cached for a new mode yet")
+	require.NoError(t, instance.UpdateDiffStats())
+	assert.True(t, instance.GetDiffStats().IsEmpty())
+
+	// Switching back uses the cached diff without running git, so a newer change only shows up on the next update.
+	writeFile(worktree, "c\n")
+	assert.True(t, instance.SetDiffOptions(git.DiffOptions{}))
+	assert.Contains(t, instance.GetDiffStats().Content, "+a  b")
+	require.NoError(t, instance.UpdateDiffStats())
+	assert.Contains(t, instance.GetDiffStats().Content, "+c")
+
+	assert.True(t, instance.SetDiffOptions(ignoreWhitespace))
+	assert.True(t, instance.GetDiffStats().IsEmpty())
+}
+
+func TestPatchIgnoresDiffOptions(t *testing.T) {
+	instance, _ := newRepoTestInstance(t)
+	gitRun := func(dir string, stdin string, args ...string) {
+		c := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		c.Stdin = strings.NewReader(stdin)
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(instance.Path, "a.txt"), []byte("a b\n"), 0644))
+	gitRun(instance.Path, "", "add", "a.txt")
+	gitRun(instance.Path, "", "commit", "-q", "-m", "add a.txt")
+	updates := collectProgress(instance, true)
+	require.Equal(t, StageComplete, updates[len(updates)-1].Stage)
+	worktree := instance.gitWorktree.GetWorktreePath()
+
+	// A whitespace-only change and a binary file.
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, "a.txt"), []byte("a  b\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, "logo.bin"), []byte{0, 1, 2, 0, 255}, 0644))
+	instance.SetDiffOptions(git.DiffOptions{IgnoreWhitespace: true, ContextLines: 1})
+	require.NoError(t, instance.UpdateDiffStats())
+	assert.NotContains(t, instance.GetDiffStats().Content, "+a  b")
+
+	patch, err := instance.Patch()
+	require.NoError(t, err)
+	assert.Contains(t, patch, "+a  b")
+	assert.Contains(t, patch, "GIT binary patch")
+	gitRun(instance.Path, patch, "apply", "--check", "-")
+}
+
+func TestBaseBranchIsSaved(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	instance, err := FromInstanceData(InstanceData{
+		Title:   "saved",
+		Program: "my-agent",
+		Status:  Paused,
+		Worktree: GitWorktreeData{
+			RepoPath:      "/repo",
+			WorktreePath:  "/worktree",
+			BranchName:    "user/saved",
+			BaseCommitSHA: "abc123",
+			BaseBranch:    "develop",
+		},
+	})
+	require.NoError(t, err)
+
+	data := instance.ToInstanceData()
+	assert.Equal(t, "develop", data.Worktree.BaseBranch)
+	assert.Equal(t, "abc123", data.Worktree.BaseCommitSHA)
+}
+
+func TestUpdateConflicts(t *testing.T) {
+	instance, _ := newRepoTestInstance(t)
+	updates := collectProgress(instance, true)
+	require.Equal(t, StageComplete, updates[len(updates)-1].Stage)
+	commit := func(dir, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte(content), 0644))
+		for _, args := range [][]string{{"add", "a.txt"}, {"commit", "-q", "-m", "change a.txt"}} {
+			out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+			require.NoError(t, err, string(out))
+		}
+	}
+
+	now := time.Now()
+	commit(instance.gitWorktree.GetWorktreePath(), "from the branch\n")
+	require.NoError(t, instance.UpdateConflicts(now))
+	assert.Empty(t, instance.Conflicts())
+
+	// The base branch now conflicts, but that only shows up once the check interval has passed.
+	commit(instance.Path, "from the base branch\n")
+	require.NoError(t, instance.UpdateConflicts(now.Add(time.Second)))
+	assert.Empty(t, instance.Conflicts())
+	require.NoError(t, instance.UpdateConflicts(now.Add(ConflictCheckInterval)))
+	assert.Equal(t, []string{"a.txt"}, instance.Conflicts())
+}
+
+func TestInPlaceInstance(t *testing.T) {
+	instance, _ := newRepoTestInstance(t)
+	instance.inPlace = true
+	repo := instance.Path
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "notes.txt"), []byte("draft\n"), 0644))
+
+	require.NoError(t, instance.Start(true))
+	assert.True(t, instance.Started())
+	assert.True(t, instance.InPlace())
+	worktree, err := instance.GetGitWorktree()
+	require.NoError(t, err)
+	assert.Equal(t, repo, worktree.GetWorktreePath())
+	worktrees, err := exec.Command("git", "-C", repo, "worktree", "list").Output()
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(worktrees), "\n"), "no worktree is created")
+
+	data := instance.ToInstanceData()
+	assert.True(t, data.Worktree.InPlace)
+	assert.Equal(t, repo, data.Worktree.WorktreePath)
+
+	assert.ErrorContains(t, instance.Pause(), "cannot pause in-place instance")
+	assert.Empty(t, Pausable([]*Instance{instance}))
+	assert.Empty(t, PausableOnQuit([]*Instance{instance}))
+
+	// Killing it closes the session but leaves the repository and its files alone.
+	require.NoError(t, instance.Kill())
+	assert.FileExists(t, filepath.Join(repo, "notes.txt"))
+
+	_, err = NewInstance(InstanceOptions{Title: "remote", Path: repo, Program: "claude", InPlace: true,
+		RemoteRef: "origin/main"})
+	assert.ErrorContains(t, err, "can't check out a remote branch")
+}
+
+func TestReadyDetector(t *testing.T) {
+	start := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	screen := strings.Repeat("Claude Code ready for your prompt ", 3)
+	// observeFor captures content every 100ms from offset to end and returns when it was first ready, or -1.
+	observeFor := func(d *readyDetector, content func(time.Duration) string, end time.Duration) time.Duration {
+		for at := time.Duration(0); at <= end; at += 100 * time.Millisecond {
+			if d.observe(content(at), start.Add(at)) {
+				return at
+			}
+		}
+		return -1
+	}
+
+	t.Run("a pane that never changes is ready once it was stable long enough", func(t *testing.T) {
+		var d readyDetector
+		ready := observeFor(&d, func(time.Duration) string { return screen }, 10*time.Second)
+		assert.Equal(t, readyUnchangedThreshold, ready)
+	})
+
+	t.Run("a pane that changed is ready sooner", func(t *testing.T) {
+		var d readyDetector
+		ready := observeFor(&d, func(at time.Duration) string {
+			if at < time.Second {
+				return "starting..."
+			}
+			return screen
+		}, 10*time.Second)
+		assert.Equal(t, time.Second+readyStableThreshold, ready)
+	})
+
+	t.Run("a pane without substantial content is never ready", func(t *testing.T) {
+		var d readyDetector
+		assert.Equal(t, time.Duration(-1), observeFor(&d, func(time.Duration) string { return "$ " }, 10*time.Second))
+	})
+}
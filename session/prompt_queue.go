@@ -0,0 +1,92 @@
+package session
+
+import "fmt"
+
+// PromptQueue holds the prompts waiting to be sent to an instance, oldest first.
+type PromptQueue struct {
+	prompts []string
+}
+
+// Push adds a prompt to the end of the queue.
+func (q *PromptQueue) Push(prompt string) {
+	q.prompts = append(q.prompts, prompt)
+}
+
+// Pop removes and returns the oldest prompt. It returns false if the queue is empty.
+func (q *PromptQueue) Pop() (string, bool) {
+	if len(q.prompts) == 0 {
+		return "", false
+	}
+	prompt := q.prompts[0]
+	q.prompts = q.prompts[1:]
+	return prompt, true
+}
+
+// Peek returns the oldest prompt without removing it. It returns false if the queue is empty.
+func (q *PromptQueue) Peek() (string, bool) {
+	if len(q.prompts) == 0 {
+		return "", false
+	}
+	return q.prompts[0], true
+}
+
+// Len returns the number of queued prompts.
+func (q *PromptQueue) Len() int {
+	return len(q.prompts)
+}
+
+// SubmitPrompt sends prompt to the program, or queues it if the program is still busy with an earlier prompt or
+// other prompts are queued, so prompts are always sent in the order they were submitted. Returns whether the prompt
+// was queued.
+func (i *Instance) SubmitPrompt(prompt string) (queued bool, err error) {
+	if i.started && (i.Status == Running || i.promptQueue.Len() > 0) {
+		i.promptQueue.Push(prompt)
+		return true, nil
+	}
+	return false, i.SendPrompt(prompt)
+}
+
+// QueuePrompt queues prompt without trying to send it, for an instance that can't take prompts right now, e.g. one
+// that is paused or still starting. It is sent once the program runs and is ready again.
+func (i *Instance) QueuePrompt(prompt string) {
+	i.promptQueue.Push(prompt)
+}
+
+// LastPrompt returns the prompt that was last sent to the program, or an empty string if none was.
+func (i *Instance) LastPrompt() string {
+	return i.lastPrompt
+}
+
+// ResendLastPrompt submits the prompt that was last sent to the program again, like SubmitPrompt. It fails if no
+// prompt has been sent yet.
+func (i *Instance) ResendLastPrompt() (queued bool, err error) {
+	if i.lastPrompt == "" {
+		return false, fmt.Errorf("no prompt has been sent to '%s' yet", i.Title)
+	}
+	return i.SubmitPrompt(i.lastPrompt)
+}
+
+// QueuedPrompts returns the number of prompts waiting to be sent.
+func (i *Instance) QueuedPrompts() int {
+	return i.promptQueue.Len()
+}
+
+// NextQueuedPrompt returns the prompt that is sent next once the program is ready, or "" if none is queued.
+func (i *Instance) NextQueuedPrompt() string {
+	prompt, _ := i.promptQueue.Peek()
+	return prompt
+}
+
+// SendQueuedPrompt sends the oldest queued prompt and marks the instance Running, since the program starts working
+// on it. It does nothing if no prompts are queued. The caller decides when the program is ready for the next prompt.
+func (i *Instance) SendQueuedPrompt() error {
+	prompt, ok := i.promptQueue.Pop()
+	if !ok {
+		return nil
+	}
+	if err := i.SendPrompt(prompt); err != nil {
+		return fmt.Errorf("failed to send queued prompt: %w", err)
+	}
+	i.SetStatus(Running)
+	return nil
+}
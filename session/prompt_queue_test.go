@@ -0,0 +1,169 @@
+package session
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptQueue(t *testing.T) {
+	var q PromptQueue
+	_, ok := q.Pop()
+	assert.False(t, ok)
+
+	q.Push("first")
+	q.Push("second")
+	q.Push("third")
+	assert.Equal(t, 3, q.Len())
+	prompt, ok := q.Peek()
+	require.True(t, ok)
+	assert.Equal(t, "first", prompt)
+	assert.Equal(t, 3, q.Len(), "peeking leaves the prompt queued")
+
+	for _, want := range []string{"first", "second", "third"} {
+		prompt, ok := q.Pop()
+		require.True(t, ok)
+		assert.Equal(t, want, prompt)
+	}
+	assert.Zero(t, q.Len())
+	_, ok = q.Pop()
+	assert.False(t, ok)
+	_, ok = q.Peek()
+	assert.False(t, ok)
+}
+
+func TestSubmitPrompt(t *testing.T) {
+	instance, ptyFactory := newRestartTestInstance(t, func(*filePtyFactory) bool { return true })
+	require.NoError(t, instance.tmuxSession.Restore())
+	sent := func() string {
+		data, err := os.ReadFile(ptyFactory.paths[0])
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	// A ready instance is sent the prompt right away.
+	instance.Status = Ready
+	queued, err := instance.SubmitPrompt("first")
+	require.NoError(t, err)
+	assert.False(t, queued)
+	assert.Equal(t, "first\r", sent())
+	assert.Empty(t, instance.NextQueuedPrompt())
+
+	// A busy instance queues prompts, and so does a ready one with prompts still queued, to keep them in order.
+	instance.Status = Running
+	queued, err = instance.SubmitPrompt("second")
+	require.NoError(t, err)
+	assert.True(t, queued)
+	instance.Status = Ready
+	queued, err = instance.SubmitPrompt("third")
+	require.NoError(t, err)
+	assert.True(t, queued)
+	assert.Equal(t, 2, instance.QueuedPrompts())
+	assert.Equal(t, "second", instance.NextQueuedPrompt())
+	assert.Equal(t, "first\r", sent())
+
+	require.NoError(t, instance.SendQueuedPrompt())
+	assert.Equal(t, "first\rsecond\r", sent())
+	assert.Equal(t, Running, instance.Status)
+	assert.Equal(t, 1, instance.QueuedPrompts())
+	assert.Equal(t, "third", instance.NextQueuedPrompt())
+
+	require.NoError(t, instance.SendQueuedPrompt())
+	assert.Equal(t, "first\rsecond\rthird\r", sent())
+	assert.Zero(t, instance.QueuedPrompts())
+	assert.Empty(t, instance.NextQueuedPrompt())
+
+	// Nothing is sent once the queue is empty.
+	require.NoError(t, instance.SendQueuedPrompt())
+	assert.Equal(t, "first\rsecond\rthird\r", sent())
+}
+
+func TestResendLastPrompt(t *testing.T) {
+	instance, ptyFactory := newRestartTestInstance(t, func(*filePtyFactory) bool { return true })
+	require.NoError(t, instance.tmuxSession.Restore())
+	sent := func() string {
+		data, err := os.ReadFile(ptyFactory.paths[0])
+		require.NoError(t, err)
+		return string(data)
+	}
+	instance.Status = Ready
+
+	// Nothing is sent before a first prompt.
+	_, err := instance.ResendLastPrompt()
+	require.ErrorContains(t, err, "no prompt has been sent")
+	assert.Empty(t, instance.LastPrompt())
+	assert.Empty(t, sent())
+
+	_, err = instance.SubmitPrompt("fix the tests")
+	require.NoError(t, err)
+	assert.Equal(t, "fix the tests", instance.LastPrompt())
+
+	queued, err := instance.ResendLastPrompt()
+	require.NoError(t, err)
+	assert.False(t, queued)
+	assert.Equal(t, "fix the tests\rfix the tests\r", sent())
+
+	// A busy instance queues the prompt like any other, and it only becomes the last prompt once sent.
+	instance.Status = Running
+	queued, err = instance.ResendLastPrompt()
+	require.NoError(t, err)
+	assert.True(t, queued)
+	assert.Equal(t, 1, instance.QueuedPrompts())
+	assert.Equal(t, "fix the tests\rfix the tests\r", sent())
+
+	// The last prompt is stored with the instance.
+	assert.Equal(t, "fix the tests", instance.ToInstanceData().LastPrompt)
+}
+
+func TestSendPromptCommandSlash(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		strip  bool
+		prompt string
+		want   string
+	}{
+		{name: "keeps the slash by default", want: "/commit the fix\r"},
+		{name: "strips the slash", strip: true, want: "commit the fix\r"},
+		{name: "keeps the slash of unknown commands", strip: true, prompt: "/path/to/file", want: "/path/to/file\r"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			instance, ptyFactory := newRestartTestInstance(t, func(*filePtyFactory) bool { return true })
+			require.NoError(t, instance.tmuxSession.Restore())
+			if tt.strip {
+				instance.SetStripCommandSlash(isCommit)
+			}
+			prompt := tt.prompt
+			if prompt == "" {
+				prompt = "/commit the fix"
+			}
+
+			require.NoError(t, instance.SendPrompt(prompt))
+
+			data, err := os.ReadFile(ptyFactory.paths[0])
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+			assert.Equal(t, prompt, instance.LastPrompt(), "the prompt is remembered as typed")
+		})
+	}
+}
+
+// isCommit knows only the "/commit" and "/review-pr" commands.
+func isCommit(command string) bool {
+	return command == "/commit" || command == "/review-pr"
+}
+
+func TestStripCommandSlash(t *testing.T) {
+	assert.Equal(t, "commit", stripCommandSlash("/commit", isCommit))
+	assert.Equal(t, "review-pr 42", stripCommandSlash("/review-pr 42", isCommit))
+	assert.Equal(t, "commit\nthe fix", stripCommandSlash("/commit\nthe fix", isCommit))
+	// Prompts that don't start with a known command are sent as they are.
+	assert.Equal(t, "/path/to/file", stripCommandSlash("/path/to/file", isCommit))
+	assert.Equal(t, "/commits", stripCommandSlash("/commits", isCommit))
+	assert.Equal(t, "fix /commit", stripCommandSlash("fix /commit", isCommit))
+	assert.Equal(t, "/", stripCommandSlash("/", isCommit))
+	assert.Equal(t, "/ not a command", stripCommandSlash("/ not a command", isCommit))
+	assert.Equal(t, "//comment", stripCommandSlash("//comment", isCommit))
+	assert.Equal(t, "", stripCommandSlash("", isCommit))
+}
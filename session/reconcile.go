@@ -0,0 +1,20 @@
+package session
+
+// FindOrphanedSessions returns the claude-squad tmux sessions in live that don't belong to any of the given
+// instances. These are usually left behind when claude-squad crashes or storage is reset.
+func FindOrphanedSessions(live []string, instances []*Instance) []string {
+	known := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		if name := instance.TmuxSessionName(); name != "" {
+			known[name] = true
+		}
+	}
+
+	var orphaned []string
+	for _, name := range live {
+		if !known[name] {
+			orphaned = append(orphaned, name)
+		}
+	}
+	return orphaned
+}
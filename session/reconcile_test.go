@@ -0,0 +1,21 @@
+package session
+
+import (
+	"claude-squad/session/tmux"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindOrphanedSessions(t *testing.T) {
+	known := &Instance{Title: "known"}
+	known.SetTmuxSession(tmux.NewTmuxSession("known", "claude"))
+	unstarted := &Instance{Title: "unstarted"}
+
+	live := []string{tmux.TmuxPrefix + "known", tmux.TmuxPrefix + "stale"}
+
+	orphaned := FindOrphanedSessions(live, []*Instance{known, unstarted})
+	assert.Equal(t, []string{tmux.TmuxPrefix + "stale"}, orphaned)
+
+	assert.Empty(t, FindOrphanedSessions(nil, []*Instance{known}))
+}
@@ -0,0 +1,198 @@
+package session
+
+import (
+	"claude-squad/cmd"
+	"claude-squad/session/git"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WorktreeProblem is what is wrong with a stored instance's worktree.
+type WorktreeProblem int
+
+const (
+	// WorktreeMissing means the worktree directory is gone but its branch still exists, so it can be checked out
+	// again.
+	WorktreeMissing WorktreeProblem = iota
+	// WorktreeBranchMissing means both the worktree directory and its branch are gone. The instance can only be
+	// removed.
+	WorktreeBranchMissing
+	// WorktreeUnregistered means the worktree directory exists but git doesn't know about it, e.g. because the
+	// repo or the worktree was moved.
+	WorktreeUnregistered
+)
+
+func (p WorktreeProblem) String() string {
+	switch p {
+	case WorktreeMissing:
+		return "worktree directory is missing"
+	case WorktreeBranchMissing:
+		return "worktree directory and branch are missing"
+	case WorktreeUnregistered:
+		return "worktree is not registered with git"
+	default:
+		return "unknown problem"
+	}
+}
+
+// WorktreeIssue is a stored instance whose worktree is broken.
+type WorktreeIssue struct {
+	Title        string
+	WorktreePath string
+	Problem      WorktreeProblem
+	// Fix describes what was done about the issue, or is empty if it was left alone.
+	Fix string
+	// Err is set if fixing the issue failed, or if it couldn't be told whether the branch is gone too.
+	Err error
+}
+
+// WorktreeReport is the result of CheckWorktrees.
+type WorktreeReport struct {
+	// Pruned are the stale worktree entries git pruned.
+	Pruned []string
+	Issues []WorktreeIssue
+}
+
+// RepairOptions selects what CheckWorktrees fixes. With neither set it only reports.
+type RepairOptions struct {
+	// Repair checks missing worktrees out again and reconnects unregistered ones.
+	Repair bool
+	// Remove deletes instances whose worktree cannot be repaired from storage.
+	Remove bool
+}
+
+// CheckWorktrees prunes stale worktree entries from the repos of the stored instances and finds instances whose
+// worktree is missing or unknown to git, fixing them as selected by opts. Paused instances are skipped since they
+// have no worktree, and so are in-place instances, which have none of their own. Running it again after a fix finds nothing, so it is safe to repeat.
+func (s *Storage) CheckWorktrees(cmdExec cmd.Executor, opts RepairOptions) (WorktreeReport, error) {
+	var report WorktreeReport
+
+	instancesData, err := s.loadInstancesData()
+	if err != nil {
+		return report, err
+	}
+
+	// Prune each repo once, then list what git still knows about.
+	registered := make(map[string]map[string]bool)
+	for _, data := range instancesData {
+		repo := data.Worktree.RepoPath
+		if repo == "" || registered[repo] != nil {
+			continue
+		}
+		entries, err := git.ListWorktrees(cmdExec, repo)
+		if err != nil {
+			return report, err
+		}
+		for _, entry := range entries {
+			if entry.Prunable {
+				report.Pruned = append(report.Pruned, entry.Path)
+			}
+		}
+		if err := git.PruneWorktrees(cmdExec, repo); err != nil {
+			return report, err
+		}
+		if entries, err = git.ListWorktrees(cmdExec, repo); err != nil {
+			return report, err
+		}
+		paths := make(map[string]bool, len(entries))
+		for _, entry := range entries {
+			paths[resolvePath(entry.Path)] = true
+		}
+		registered[repo] = paths
+	}
+
+	kept := make([]InstanceData, 0, len(instancesData))
+	for _, data := range instancesData {
+		wt := data.Worktree
+		// An in-place instance runs in a directory of the repository itself, which git doesn't list as a worktree.
+		if data.Status == Paused || wt.InPlace || wt.RepoPath == "" || wt.WorktreePath == "" {
+			kept = append(kept, data)
+			continue
+		}
+
+		issue := WorktreeIssue{Title: data.Title, WorktreePath: wt.WorktreePath}
+		if _, err := os.Stat(wt.WorktreePath); err != nil {
+			issue.Problem = WorktreeMissing
+			exists, err := git.BranchExists(cmdExec, wt.RepoPath, wt.BranchName)
+			if err != nil {
+				// Without knowing whether the branch is gone, the instance is neither checked out again nor removed.
+				issue.Err = err
+				kept = append(kept, data)
+				report.Issues = append(report.Issues, issue)
+				continue
+			}
+			if !exists {
+				issue.Problem = WorktreeBranchMissing
+			}
+		} else if !registered[wt.RepoPath][resolvePath(wt.WorktreePath)] {
+			issue.Problem = WorktreeUnregistered
+		} else {
+			kept = append(kept, data)
+			continue
+		}
+
+		removed := false
+		switch {
+		case issue.Problem == WorktreeMissing && opts.Repair:
+			if issue.Err = git.AddWorktree(cmdExec, wt.RepoPath, wt.WorktreePath, wt.BranchName); issue.Err == nil {
+				issue.Fix = "checked out branch " + wt.BranchName + " again"
+			}
+		case issue.Problem == WorktreeUnregistered && opts.Repair:
+			if issue.Err = git.RepairWorktree(cmdExec, wt.RepoPath, wt.WorktreePath); issue.Err == nil {
+				issue.Fix = "reconnected worktree"
+			}
+		case issue.Problem == WorktreeBranchMissing && opts.Remove:
+			issue.Fix = "removed instance"
+			removed = true
+		}
+		if !removed {
+			kept = append(kept, data)
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	if len(kept) != len(instancesData) {
+		jsonData, err := json.Marshal(kept)
+		if err != nil {
+			return report, fmt.Errorf("failed to marshal instances: %w", err)
+		}
+		if err := s.state.SaveInstances(jsonData); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// Unfixed returns how many issues are left after the fixes.
+func (r WorktreeReport) Unfixed() int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Fix == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Print writes the report to w, one line per pruned entry and issue.
+func (r WorktreeReport) Print(w io.Writer) {
+	if len(r.Pruned) == 0 && len(r.Issues) == 0 {
+		fmt.Fprintln(w, "All worktrees are healthy")
+		return
+	}
+	for _, path := range r.Pruned {
+		fmt.Fprintf(w, "[✓] pruned stale worktree entry %s\n", path)
+	}
+	for _, issue := range r.Issues {
+		switch {
+		case issue.Err != nil:
+			fmt.Fprintf(w, "[✗] %s: %s (%s): %v\n", issue.Title, issue.Problem, issue.WorktreePath, issue.Err)
+		case issue.Fix != "":
+			fmt.Fprintf(w, "[✓] %s: %s (%s), %s\n", issue.Title, issue.Problem, issue.WorktreePath, issue.Fix)
+		default:
+			fmt.Fprintf(w, "[!] %s: %s (%s)\n", issue.Title, issue.Problem, issue.WorktreePath)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package session
+
+import (
+	"bytes"
+	"claude-squad/cmd/cmd_test"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWorktreeGit mocks the git commands CheckWorktrees runs. The worktree list reports stale until prune has run.
+type fakeWorktreeGit struct {
+	listed   []string
+	stale    []string
+	branches map[string]bool
+	// branchErr, if set, fails looking for branches.
+	branchErr error
+	pruned    bool
+	commands  []string
+	// sessions are the live tmux sessions.
+	sessions []string
+}
+
+func (f *fakeWorktreeGit) executor() cmd_test.MockCmdExec {
+	return cmd_test.MockCmdExec{
+		OutputFunc: func(c *exec.Cmd) ([]byte, error) {
+			if c.Args[0] == "tmux" && slices.Contains(c.Args, "ls") {
+				return []byte(strings.Join(f.sessions, "\n")), nil
+			}
+			return nil, fmt.Errorf("unexpected command: %s", strings.Join(c.Args, " "))
+		},
+		CombinedOutputFunc: func(c *exec.Cmd) ([]byte, error) {
+			args := strings.Join(c.Args[3:], " ")
+			f.commands = append(f.commands, args)
+			switch {
+			case args == "worktree list --porcelain":
+				var b strings.Builder
+				for _, path := range f.listed {
+					fmt.Fprintf(&b, "worktree %s\nHEAD abc\nbranch refs/heads/x\n\n", path)
+				}
+				if !f.pruned {
+					for _, path := range f.stale {
+						fmt.Fprintf(&b, "worktree %s\nHEAD abc\nbranch refs/heads/x\nprunable gitdir file points to non-existent location\n\n", path)
+					}
+				}
+				return []byte(b.String()), nil
+			case args == "worktree prune":
+				f.pruned = true
+				return nil, nil
+			case strings.HasPrefix(args, "for-each-ref --format=%(refname) refs/heads/"):
+				if f.branchErr != nil {
+					return []byte("fatal: not a git repository"), f.branchErr
+				}
+				ref := strings.TrimPrefix(args, "for-each-ref --format=%(refname) ")
+				if f.branches[strings.TrimPrefix(ref, "refs/heads/")] {
+					return []byte(ref + "\n"), nil
+				}
+				return nil, nil
+			case strings.HasPrefix(args, "worktree add "), strings.HasPrefix(args, "worktree repair "):
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unexpected git command: %s", args)
+		},
+	}
+}
+
+func TestCheckWorktrees(t *testing.T) {
+	repo := t.TempDir()
+	healthy := t.TempDir()
+	unregistered := t.TempDir()
+	missing := filepath.Join(t.TempDir(), "missing")
+	gone := filepath.Join(t.TempDir(), "gone")
+
+	instance := func(title, path, branch string, status Status) InstanceData {
+		return InstanceData{Title: title, Status: status, Worktree: GitWorktreeData{
+			RepoPath: repo, WorktreePath: path, BranchName: branch,
+		}}
+	}
+	instances := []InstanceData{
+		instance("healthy", healthy, "healthy", Running),
+		instance("unregistered", unregistered, "unregistered", Ready),
+		instance("missing", missing, "missing", Running),
+		instance("gone", gone, "gone", Running),
+		instance("paused", filepath.Join(t.TempDir(), "paused"), "paused", Paused),
+	}
+	newGit := func() *fakeWorktreeGit {
+		return &fakeWorktreeGit{
+			listed:   []string{repo, healthy},
+			stale:    []string{missing, gone},
+			branches: map[string]bool{"missing": true},
+		}
+	}
+
+	t.Run("reports without fixing", func(t *testing.T) {
+		storage, state := newMemoryStorage(t, instances...)
+		fake := newGit()
+
+		report, err := storage.CheckWorktrees(fake.executor(), RepairOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{missing, gone}, report.Pruned)
+		assert.Equal(t, []WorktreeIssue{
+			{Title: "unregistered", WorktreePath: unregistered, Problem: WorktreeUnregistered},
+			{Title: "missing", WorktreePath: missing, Problem: WorktreeMissing},
+			{Title: "gone", WorktreePath: gone, Problem: WorktreeBranchMissing},
+		}, report.Issues)
+		assert.Equal(t, 3, report.Unfixed())
+		assert.Len(t, storedInstances(t, state), len(instances))
+		for _, c := range fake.commands {
+			assert.NotContains(t, c, "worktree add")
+			assert.NotContains(t, c, "worktree repair")
+		}
+	})
+
+	t.Run("repairs and removes", func(t *testing.T) {
+		storage, state := newMemoryStorage(t, instances...)
+		fake := newGit()
+
+		report, err := storage.CheckWorktrees(fake.executor(), RepairOptions{Repair: true, Remove: true})
+		require.NoError(t, err)
+
+		assert.Zero(t, report.Unfixed())
+		assert.Contains(t, fake.commands, "worktree repair "+unregistered)
+		assert.Contains(t, fake.commands, "worktree add "+missing+" missing")
+
+		var titles []string
+		for _, data := range storedInstances(t, state) {
+			titles = append(titles, data.Title)
+		}
+		assert.Equal(t, []string{"healthy", "unregistered", "missing", "paused"}, titles)
+
+		var out bytes.Buffer
+		report.Print(&out)
+		assert.Contains(t, out.String(), "[✓] pruned stale worktree entry "+missing)
+		assert.Contains(t, out.String(), "[✓] gone: worktree directory and branch are missing")
+	})
+
+	t.Run("keeps instances whose branch can't be looked for", func(t *testing.T) {
+		storage, state := newMemoryStorage(t, instance("gone", gone, "gone", Running))
+		fake := newGit()
+		fake.branchErr = fmt.Errorf("exit status 128")
+
+		report, err := storage.CheckWorktrees(fake.executor(), RepairOptions{Repair: true, Remove: true})
+		require.NoError(t, err)
+
+		require.Len(t, report.Issues, 1)
+		assert.Equal(t, WorktreeMissing, report.Issues[0].Problem)
+		assert.ErrorContains(t, report.Issues[0].Err, "failed to look for branch gone")
+		assert.Len(t, storedInstances(t, state), 1, "the instance isn't removed")
+		for _, c := range fake.commands {
+			assert.NotContains(t, c, "worktree add")
+		}
+	})
+
+	t.Run("skips in-place instances", func(t *testing.T) {
+		inPlace := instance("in-place", filepath.Join(repo, "sub"), "main", Running)
+		inPlace.Worktree.InPlace = true
+		storage, state := newMemoryStorage(t, inPlace)
+		fake := newGit()
+		fake.stale = nil
+
+		report, err := storage.CheckWorktrees(fake.executor(), RepairOptions{Repair: true, Remove: true})
+		require.NoError(t, err)
+		assert.Empty(t, report.Issues)
+		assert.Len(t, storedInstances(t, state), 1)
+	})
+
+	t.Run("matches worktrees through symlinks", func(t *testing.T) {
+		link := filepath.Join(t.TempDir(), "link")
+		require.NoError(t, os.Symlink(healthy, link))
+		storage, _ := newMemoryStorage(t, instance("linked", link, "linked", Running))
+		fake := newGit()
+		fake.stale = nil
+
+		report, err := storage.CheckWorktrees(fake.executor(), RepairOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, report.Issues)
+	})
+
+	t.Run("nothing to do", func(t *testing.T) {
+		storage, _ := newMemoryStorage(t, instance("healthy", healthy, "healthy", Running))
+		fake := newGit()
+		fake.stale = nil
+
+		report, err := storage.CheckWorktrees(fake.executor(), RepairOptions{Repair: true, Remove: true})
+		require.NoError(t, err)
+		assert.Empty(t, report.Issues)
+
+		var out bytes.Buffer
+		report.Print(&out)
+		assert.Equal(t, "All worktrees are healthy\n", out.String())
+	})
+}
@@ -0,0 +1,52 @@
+package session
+
+import (
+	"claude-squad/session/git"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResolveRepoPath checks that path, as entered by the user, is a directory in a git repository and returns the
+// absolute path of the repository's root, to be used as InstanceOptions.Path. A leading ~ is the home directory.
+func ResolveRepoPath(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("enter the path of a git repository")
+	}
+	expanded, err := ExpandHome(path)
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(expanded)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path of %s: %w", path, err)
+	}
+
+	info, err := os.Stat(abs)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%s does not exist", path)
+	} else if err != nil {
+		return "", fmt.Errorf("cannot use %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", path)
+	}
+	if !git.IsGitRepo(abs) {
+		return "", fmt.Errorf("%s is not a git repository: sessions need a repository to create their worktree in", path)
+	}
+	return git.RepoRootOrDir(abs), nil
+}
+
+// ExpandHome replaces a leading ~ in path with the user's home directory.
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the home directory: %w", err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
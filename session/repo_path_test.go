@@ -0,0 +1,60 @@
+package session
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRepoPath(t *testing.T) {
+	repo := t.TempDir()
+	out, err := exec.Command("git", "init", "-q", repo).CombinedOutput()
+	require.NoError(t, err, string(out))
+	// The temporary directory may be behind a symlink, which git resolves.
+	repo, err = filepath.EvalSymlinks(repo)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "sub", "dir"), 0755))
+
+	notRepo := t.TempDir()
+	file := filepath.Join(notRepo, "file.txt")
+	require.NoError(t, os.WriteFile(file, []byte("x"), 0644))
+
+	t.Run("a repository", func(t *testing.T) {
+		path, err := ResolveRepoPath(repo)
+		require.NoError(t, err)
+		assert.Equal(t, repo, path)
+	})
+
+	t.Run("a subdirectory resolves to the root", func(t *testing.T) {
+		path, err := ResolveRepoPath(" " + filepath.Join(repo, "sub", "dir") + "/ ")
+		require.NoError(t, err)
+		assert.Equal(t, repo, path)
+	})
+
+	t.Run("the home directory", func(t *testing.T) {
+		t.Setenv("HOME", filepath.Dir(repo))
+		path, err := ResolveRepoPath("~/" + filepath.Base(repo))
+		require.NoError(t, err)
+		assert.Equal(t, repo, path)
+	})
+
+	t.Run("rejected paths", func(t *testing.T) {
+		tests := []struct {
+			path string
+			err  string
+		}{
+			{path: notRepo, err: notRepo + " is not a git repository"},
+			{path: filepath.Join(notRepo, "missing"), err: filepath.Join(notRepo, "missing") + " does not exist"},
+			{path: file, err: file + " is not a directory"},
+			{path: "  ", err: "enter the path of a git repository"},
+		}
+		for _, tt := range tests {
+			_, err := ResolveRepoPath(tt.path)
+			assert.ErrorContains(t, err, tt.err)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package session
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxSearchLines is the number of most recent output lines of each instance that SearchOutput looks at, so a
+// search stays fast however long the instances have run.
+const maxSearchLines = 2000
+
+// OutputMatch is an instance whose output matched a search.
+type OutputMatch struct {
+	Instance *Instance
+	// Count is the number of matching lines.
+	Count int
+	// Line is the most recent matching line, without colors and surrounding whitespace.
+	Line string
+}
+
+// SearchOutput searches the retained output of the instances for query, ignoring case. Paused, archived and
+// instances that haven't started are skipped. It uses the output history recorded on each metadata tick, so
+// nothing is captured from tmux. The matches are in the order of instances.
+func SearchOutput(instances []*Instance, query string) []OutputMatch {
+	var matches []OutputMatch
+	for _, instance := range instances {
+		if !instance.Started() || instance.Paused() || instance.Archived() {
+			continue
+		}
+		lines, _ := instance.OutputHistory().Lines()
+		if count, line := matchLines(lines, query, maxSearchLines); count > 0 {
+			matches = append(matches, OutputMatch{Instance: instance, Count: count, Line: line})
+		}
+	}
+	return matches
+}
+
+// matchLines counts the lines among the last limit lines that contain query, ignoring case and colors, and
+// returns the last of them. A blank query matches nothing.
+func matchLines(lines []string, query string, limit int) (count int, last string) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return 0, ""
+	}
+	if len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	for _, line := range lines {
+		plain := ansi.Strip(line)
+		if strings.Contains(strings.ToLower(plain), query) {
+			count++
+			last = strings.TrimSpace(plain)
+		}
+	}
+	return count, last
+}
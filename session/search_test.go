@@ -0,0 +1,72 @@
+package session
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchLines(t *testing.T) {
+	lines := []string{
+		"Running the tests",
+		"\x1b[31mError: connection refused\x1b[0m",
+		"retrying",
+		"  ERROR: connection refused again  ",
+		"done",
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		count int
+		last  string
+	}{
+		{name: "ignores case and colors", query: "error", limit: 10, count: 2, last: "ERROR: connection refused again"},
+		{name: "phrase", query: "connection refused", limit: 10, count: 2, last: "ERROR: connection refused again"},
+		{name: "surrounding spaces in the query", query: "  tests ", limit: 10, count: 1, last: "Running the tests"},
+		{name: "no match", query: "panic", limit: 10},
+		{name: "blank query", query: "  ", limit: 10},
+		{name: "only recent lines", query: "tests", limit: 4},
+		{name: "recent lines that match", query: "error", limit: 2, count: 1, last: "ERROR: connection refused again"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, last := matchLines(lines, tt.query, tt.limit)
+			assert.Equal(t, tt.count, count)
+			assert.Equal(t, tt.last, last)
+		})
+	}
+}
+
+func TestSearchOutput(t *testing.T) {
+	newInstance := func(title string, status Status, output ...string) *Instance {
+		instance := &Instance{Title: title, Status: status, started: true}
+		for _, line := range output {
+			instance.OutputHistory().Merge(line)
+		}
+		return instance
+	}
+	first := newInstance("first", Ready, "build failed: missing import")
+	second := newInstance("second", Running, "all good")
+	third := newInstance("third", Dead, "Build failed again")
+	paused := newInstance("paused", Paused, "build failed while paused")
+	archived := newInstance("archived", Archived, "build failed before archiving")
+	unstarted := &Instance{Title: "unstarted", Status: Ready}
+	unstarted.OutputHistory().Merge("build failed before starting")
+	instances := []*Instance{first, second, third, paused, archived, unstarted}
+
+	assert.Equal(t, []OutputMatch{
+		{Instance: first, Count: 1, Line: "build failed: missing import"},
+		{Instance: third, Count: 1, Line: "Build failed again"},
+	}, SearchOutput(instances, "build failed"))
+	assert.Empty(t, SearchOutput(instances, "panic"))
+
+	// Output from before the most recent lines isn't searched.
+	long := newInstance("long", Ready, "needle")
+	for i := range maxSearchLines {
+		long.OutputHistory().Merge(fmt.Sprintf("line %d", i))
+	}
+	assert.Empty(t, SearchOutput([]*Instance{long}, "needle"))
+}
@@ -0,0 +1,55 @@
+package session
+
+// statusNames are the names of the statuses in a StatusSummary.
+var statusNames = map[Status]string{
+	Running:  "running",
+	Ready:    "ready",
+	Loading:  "loading",
+	Paused:   "paused",
+	Deleting: "deleting",
+	Dead:     "dead",
+	Archived: "archived",
+	Exited:   "exited",
+}
+
+// InstanceSummary is the status of one instance in a StatusSummary.
+type InstanceSummary struct {
+	Title   string `json:"title"`
+	Status  string `json:"status"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+}
+
+// StatusSummary is the status of all instances that external tools, like a status bar widget, can poll.
+type StatusSummary struct {
+	// Total is the number of instances.
+	Total int `json:"total"`
+	// ByStatus counts the instances by status name. Statuses without instances are left out.
+	ByStatus map[string]int `json:"by_status"`
+	// Added and Removed are the lines added and removed in the diffs of all instances.
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	// Instances are the instances in list order.
+	Instances []InstanceSummary `json:"instances"`
+}
+
+// Summarize returns the StatusSummary of instances. Diffs that haven't been computed or failed count as empty.
+func Summarize(instances []*Instance) StatusSummary {
+	summary := StatusSummary{
+		Total:     len(instances),
+		ByStatus:  make(map[string]int),
+		Instances: make([]InstanceSummary, 0, len(instances)),
+	}
+	for _, instance := range instances {
+		name := statusNames[instance.Status]
+		summary.ByStatus[name]++
+		entry := InstanceSummary{Title: instance.Title, Status: name}
+		if stats := instance.diffStats; stats != nil && stats.Error == nil {
+			entry.Added, entry.Removed = stats.Added, stats.Removed
+		}
+		summary.Added += entry.Added
+		summary.Removed += entry.Removed
+		summary.Instances = append(summary.Instances, entry)
+	}
+	return summary
+}
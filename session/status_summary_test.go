@@ -0,0 +1,53 @@
+package session
+
+import (
+	"claude-squad/session/git"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	instances := []*Instance{
+		{Title: "fix-login", Status: Running, diffStats: &git.DiffStats{Added: 12, Removed: 3}},
+		{Title: "docs", Status: Ready, diffStats: &git.DiffStats{Added: 4}},
+		{Title: "review", Status: Ready},
+		{Title: "broken", Status: Running, diffStats: &git.DiffStats{Added: 9, Error: fmt.Errorf("no base commit")}},
+		{Title: "old", Status: Paused, diffStats: &git.DiffStats{Removed: 1}},
+	}
+
+	summary := Summarize(instances)
+
+	assert.Equal(t, StatusSummary{
+		Total:    5,
+		ByStatus: map[string]int{"running": 2, "ready": 2, "paused": 1},
+		Added:    16,
+		Removed:  4,
+		Instances: []InstanceSummary{
+			{Title: "fix-login", Status: "running", Added: 12, Removed: 3},
+			{Title: "docs", Status: "ready", Added: 4},
+			{Title: "review", Status: "ready"},
+			{Title: "broken", Status: "running"},
+			{Title: "old", Status: "paused", Removed: 1},
+		},
+	}, summary)
+
+	data, err := json.Marshal(Summarize(instances[1:2]))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"total": 1,
+		"by_status": {"ready": 1},
+		"added": 4,
+		"removed": 0,
+		"instances": [{"title": "docs", "status": "ready", "added": 4, "removed": 0}]
+	}`, string(data))
+
+	t.Run("no instances", func(t *testing.T) {
+		data, err := json.Marshal(Summarize(nil))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"total": 0, "by_status": {}, "added": 0, "removed": 0, "instances": []}`, string(data))
+	})
+}
@@ -1,9 +1,17 @@
 package session
 
 import (
+	"claude-squad/cmd"
 	"claude-squad/config"
+	"claude-squad/session/git"
+	"claude-squad/session/tmux"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -19,9 +27,19 @@ type InstanceData struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	AutoYes   bool      `json:"auto_yes"`
 
-	Program   string          `json:"program"`
-	Worktree  GitWorktreeData `json:"worktree"`
-	DiffStats DiffStatsData   `json:"diff_stats"`
+	Program    string            `json:"program"`
+	Args       []string          `json:"args,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	Note       string            `json:"note,omitempty"`
+	Color      string            `json:"color,omitempty"`
+	Icon       string            `json:"icon,omitempty"`
+	Group      string            `json:"group,omitempty"`
+	LastPrompt string            `json:"last_prompt,omitempty"`
+	// QueuedPrompts are the prompts waiting to be sent, oldest first, e.g. those forwarded to a paused instance.
+	QueuedPrompts []string        `json:"queued_prompts,omitempty"`
+	Worktree      GitWorktreeData `json:"worktree"`
+	DiffStats     DiffStatsData   `json:"diff_stats"`
 }
 
 // GitWorktreeData represents the serializable data of a GitWorktree
@@ -31,6 +49,9 @@ type GitWorktreeData struct {
 	SessionName   string `json:"session_name"`
 	BranchName    string `json:"branch_name"`
 	BaseCommitSHA string `json:"base_commit_sha"`
+	BaseBranch    string `json:"base_branch,omitempty"`
+	// InPlace is true if WorktreePath is a directory of the repository the instance runs in directly.
+	InPlace bool `json:"in_place,omitempty"`
 }
 
 // DiffStatsData represents the serializable data of a DiffStats
@@ -146,3 +167,218 @@ func (s *Storage) UpdateInstance(instance *Instance) error {
 func (s *Storage) DeleteAllInstances() error {
 	return s.state.DeleteAllInstances()
 }
+
+// ConflictMode controls how ImportJSON handles an imported instance whose title already exists locally.
+type ConflictMode int
+
+const (
+	// ConflictSkip keeps the local instance and drops the imported one.
+	ConflictSkip ConflictMode = iota
+	// ConflictOverwrite replaces the local instance with the imported one. The import is refused if the local
+	// instance still has a tmux session or a worktree, which would be left behind.
+	ConflictOverwrite
+	// ConflictRename keeps both, giving the imported instance a unique title. The import is refused if the
+	// imported instance uses the branch or worktree of an instance that is kept.
+	ConflictRename
+)
+
+// ParseConflictMode converts a conflict mode name (skip, overwrite, rename) to a ConflictMode.
+func ParseConflictMode(s string) (ConflictMode, error) {
+	switch strings.ToLower(s) {
+	case "skip":
+		return ConflictSkip, nil
+	case "overwrite":
+		return ConflictOverwrite, nil
+	case "rename":
+		return ConflictRename, nil
+	default:
+		return ConflictSkip, fmt.Errorf("unknown conflict mode %q (expected skip, overwrite or rename)", s)
+	}
+}
+
+// ExportJSON writes the stored instance data to w. Only metadata is exported; worktrees stay on disk.
+func (s *Storage) ExportJSON(w io.Writer) error {
+	instancesData, err := s.loadInstancesData()
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := json.MarshalIndent(instancesData, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal instances: %w", err)
+	}
+	if _, err := w.Write(jsonData); err != nil {
+		return fmt.Errorf("failed to write instances: %w", err)
+	}
+	return nil
+}
+
+// ImportJSON reads instances written by ExportJSON from r and merges them into storage. Title collisions
+// are resolved according to onConflict. An imported instance that was running only keeps its status if its
+// worktree is checked out on its branch on this machine, as checked with the git commands run by cmdExec.
+// Otherwise it is marked as paused so it can be checked out again with resume instead of failing to load, and its
+// title is returned in needsCheckout.
+func (s *Storage) ImportJSON(cmdExec cmd.Executor, r io.Reader, onConflict ConflictMode) (needsCheckout []string,
+	err error) {
+	var imported []InstanceData
+	if err := json.NewDecoder(r).Decode(&imported); err != nil {
+		return nil, fmt.Errorf("failed to decode imported instances: %w", err)
+	}
+
+	existing, err := s.loadInstancesData()
+	if err != nil {
+		return nil, err
+	}
+	// The live tmux sessions are only listed once an instance is overwritten.
+	var liveSessions []string
+	listedSessions := false
+
+	titles := make(map[string]int, len(existing))
+	for i, data := range existing {
+		titles[data.Title] = i
+	}
+
+	for _, data := range imported {
+		if data.Title == "" {
+			return nil, fmt.Errorf("imported instance has an empty title")
+		}
+
+		checkout := false
+		if data.Status != Paused && data.Status != Archived && !worktreeCheckedOut(cmdExec, data.Worktree) {
+			data.Status = Paused
+			checkout = true
+		}
+
+		idx, exists := titles[data.Title]
+		if !exists {
+			titles[data.Title] = len(existing)
+			existing = append(existing, data)
+			if checkout {
+				needsCheckout = append(needsCheckout, data.Title)
+			}
+			continue
+		}
+
+		switch onConflict {
+		case ConflictSkip:
+			continue
+		case ConflictOverwrite:
+			if !listedSessions {
+				if liveSessions, err = tmux.ListSessions(cmdExec); err != nil {
+					return nil, err
+				}
+				listedSessions = true
+			}
+			if err := checkOverwrite(existing[idx], data, liveSessions); err != nil {
+				return nil, err
+			}
+			existing[idx] = data
+		case ConflictRename:
+			if owner, ok := worktreeOwner(data.Worktree, existing); ok {
+				return nil, fmt.Errorf("cannot import %q under a new title: it uses the branch or worktree of instance %q",
+					data.Title, owner)
+			}
+			data.Title = uniqueTitle(data.Title, titles)
+			data.Worktree.SessionName = data.Title
+			titles[data.Title] = len(existing)
+			existing = append(existing, data)
+		default:
+			return nil, fmt.Errorf("unknown conflict mode: %d", onConflict)
+		}
+		if checkout {
+			needsCheckout = append(needsCheckout, data.Title)
+		}
+	}
+
+	jsonData, err := json.Marshal(existing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal instances: %w", err)
+	}
+	if err := s.state.SaveInstances(jsonData); err != nil {
+		return nil, err
+	}
+	return needsCheckout, nil
+}
+
+// checkOverwrite returns an error if replacing the record of the local instance with the imported one would leave
+// the local instance's tmux session, one of liveSessions, or its worktree behind. The imported instance would take
+// over the tmux session, since it has the same title.
+func checkOverwrite(local, imported InstanceData, liveSessions []string) error {
+	if slices.Contains(liveSessions, tmux.SessionName(local.Title)) {
+		return fmt.Errorf("cannot overwrite instance %q: its tmux session is still running, kill it first",
+			local.Title)
+	}
+	wt := local.Worktree
+	if wt.WorktreePath == "" || wt.InPlace || resolvePath(wt.WorktreePath) == resolvePath(imported.Worktree.WorktreePath) {
+		return nil
+	}
+	if _, err := os.Stat(wt.WorktreePath); err == nil {
+		return fmt.Errorf("cannot overwrite instance %q: its worktree %s is still checked out, kill it first",
+			local.Title, wt.WorktreePath)
+	}
+	return nil
+}
+
+// worktreeCheckedOut returns whether the worktree of wt is on disk and registered in its repository with its
+// branch checked out.
+func worktreeCheckedOut(cmdExec cmd.Executor, wt GitWorktreeData) bool {
+	if wt.WorktreePath == "" {
+		return false
+	}
+	if _, err := os.Stat(wt.WorktreePath); err != nil {
+		return false
+	}
+	entries, err := git.ListWorktrees(cmdExec, wt.RepoPath)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if resolvePath(entry.Path) == resolvePath(wt.WorktreePath) {
+			return entry.Branch == wt.BranchName
+		}
+	}
+	return false
+}
+
+// resolvePath returns path with its symlinks resolved, so that paths git reports can be compared with stored ones.
+// A path that cannot be resolved is only cleaned.
+func resolvePath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// worktreeOwner returns the title of the instance in instances that uses the worktree directory of wt or its
+// branch in the same repository.
+func worktreeOwner(wt GitWorktreeData, instances []InstanceData) (string, bool) {
+	for _, data := range instances {
+		other := data.Worktree
+		if wt.WorktreePath != "" && other.WorktreePath == wt.WorktreePath {
+			return data.Title, true
+		}
+		if wt.BranchName != "" && other.BranchName == wt.BranchName && other.RepoPath == wt.RepoPath {
+			return data.Title, true
+		}
+	}
+	return "", false
+}
+
+// loadInstancesData returns the stored instance data without starting any instances.
+func (s *Storage) loadInstancesData() ([]InstanceData, error) {
+	instancesData := make([]InstanceData, 0)
+	if err := json.Unmarshal(s.state.GetInstances(), &instancesData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal instances: %w", err)
+	}
+	return instancesData, nil
+}
+
+// uniqueTitle returns title with the smallest numeric suffix that is not already taken.
+func uniqueTitle(title string, taken map[string]int) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d", title, n)
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package session
+
+import (
+	"bytes"
+	"claude-squad/session/tmux"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryState is an in-memory config.InstanceStorage used for testing.
+type memoryState struct {
+	data json.RawMessage
+}
+
+func (m *memoryState) SaveInstances(instancesJSON json.RawMessage) error {
+	m.data = instancesJSON
+	return nil
+}
+
+func (m *memoryState) GetInstances() json.RawMessage {
+	return m.data
+}
+
+func (m *memoryState) DeleteAllInstances() error {
+	m.data = json.RawMessage("[]")
+	return nil
+}
+
+func newMemoryStorage(t *testing.T, instances ...InstanceData) (*Storage, *memoryState) {
+	data, err := json.Marshal(instances)
+	require.NoError(t, err)
+	state := &memoryState{data: data}
+	storage, err := NewStorage(state)
+	require.NoError(t, err)
+	return storage, state
+}
+
+func storedInstances(t *testing.T, state *memoryState) []InstanceData {
+	var instances []InstanceData
+	require.NoError(t, json.Unmarshal(state.data, &instances))
+	return instances
+}
+
+func importReader(t *testing.T, instances ...InstanceData) *bytes.Reader {
+	data, err := json.Marshal(instances)
+	require.NoError(t, err)
+	return bytes.NewReader(data)
+}
+
+func TestImportJSON(t *testing.T) {
+	local := InstanceData{Title: "feature", Program: "claude", Status: Paused}
+	remote := InstanceData{Title: "feature", Program: "aider", Status: Running}
+	noGit := (&fakeWorktreeGit{}).executor()
+
+	t.Run("adds new instances", func(t *testing.T) {
+		storage, state := newMemoryStorage(t, local)
+
+		other := InstanceData{Title: "bugfix", Program: "claude"}
+		_, err := storage.ImportJSON(noGit, importReader(t, other), ConflictSkip)
+		require.NoError(t, err)
+
+		instances := storedInstances(t, state)
+		require.Len(t, instances, 2)
+		assert.Equal(t, "feature", instances[0].Title)
+		assert.Equal(t, "bugfix", instances[1].Title)
+	})
+
+	t.Run("skip keeps the local instance", func(t *testing.T) {
+		storage, state := newMemoryStorage(t, local)
+
+		_, err := storage.ImportJSON(noGit, importReader(t, remote), ConflictSkip)
+		require.NoError(t, err)
+
+		instances := storedInstances(t, state)
+		require.Len(t, instances, 1)
+		assert.Equal(t, "claude", instances[0].Program)
+	})
+
+	t.Run("overwrite replaces the local instance", func(t *testing.T) {
+		storage, state := newMemoryStorage(t, local)
+
+		_, err := storage.ImportJSON(noGit, importReader(t, remote), ConflictOverwrite)
+		require.NoError(t, err)
+
+		instances := storedInstances(t, state)
+		require.Len(t, instances, 1)
+		assert.Equal(t, "aider", instances[0].Program)
+	})
+
+	t.Run("overwrite refuses a local instance whose tmux session is running", func(t *testing.T) {
+		storage, state := newMemoryStorage(t, local)
+
+		live := &fakeWorktreeGit{sessions: []string{tmux.SessionName("feature")}}
+		_, err := storage.ImportJSON(live.executor(), importReader(t, remote), ConflictOverwrite)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tmux session is still running")
+		assert.Equal(t, "claude", storedInstances(t, state)[0].Program, "nothing is imported")
+	})
+
+	t.Run("overwrite refuses a local instance whose worktree is checked out", func(t *testing.T) {
+		checkedOut := local
+		checkedOut.Worktree = GitWorktreeData{RepoPath: "/repo", WorktreePath: t.TempDir(), BranchName: "user/feature"}
+		storage, state := newMemoryStorage(t, checkedOut)
+
+		_, err := storage.ImportJSON(noGit, importReader(t, remote), ConflictOverwrite)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "worktree")
+		assert.Equal(t, "claude", storedInstances(t, state)[0].Program, "nothing is imported")
+	})
+
+	t.Run("rename keeps both instances", func(t *testing.T) {
+		taken := InstanceData{Title: "feature-2", Program: "claude"}
+		storage, state := newMemoryStorage(t, local, taken)
+
+		_, err := storage.ImportJSON(noGit, importReader(t, remote), ConflictRename)
+		require.NoError(t, err)
+
+		instances := storedInstances(t, state)
+		require.Len(t, instances, 3)
+		assert.Equal(t, "feature-3", instances[2].Title)
+		assert.Equal(t, "feature-3", instances[2].Worktree.SessionName)
+		assert.Equal(t, "aider", instances[2].Program)
+	})
+
+	t.Run("rename refuses an instance sharing a branch", func(t *testing.T) {
+		branched := local
+		branched.Worktree = GitWorktreeData{RepoPath: "/repo", WorktreePath: "/worktrees/feature_1", BranchName: "user/feature"}
+		storage, state := newMemoryStorage(t, branched)
+
+		clash := remote
+		clash.Worktree = GitWorktreeData{RepoPath: "/repo", WorktreePath: "/worktrees/feature_2", BranchName: "user/feature"}
+		_, err := storage.ImportJSON(noGit, importReader(t, clash), ConflictRename)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `instance "feature"`)
+		assert.Len(t, storedInstances(t, state), 1, "nothing is imported")
+	})
+
+	t.Run("missing worktrees are marked paused", func(t *testing.T) {
+		storage, state := newMemoryStorage(t)
+
+		missing := remote
+		missing.Worktree.WorktreePath = "/nonexistent/worktree/path"
+		needsCheckout, err := storage.ImportJSON(noGit, importReader(t, missing), ConflictSkip)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"feature"}, needsCheckout)
+
+		instances := storedInstances(t, state)
+		require.Len(t, instances, 1)
+		assert.Equal(t, Paused, instances[0].Status)
+	})
+
+	t.Run("checked out worktrees keep their status", func(t *testing.T) {
+		storage, state := newMemoryStorage(t)
+
+		present := remote
+		present.Worktree = GitWorktreeData{RepoPath: t.TempDir(), WorktreePath: t.TempDir(), BranchName: "x"}
+		gitFake := &fakeWorktreeGit{listed: []string{present.Worktree.WorktreePath}}
+		_, err := storage.ImportJSON(gitFake.executor(), importReader(t, present), ConflictSkip)
+		require.NoError(t, err)
+
+		instances := storedInstances(t, state)
+		require.Len(t, instances, 1)
+		assert.Equal(t, Running, instances[0].Status)
+	})
+
+	t.Run("worktrees git doesn't know are marked paused", func(t *testing.T) {
+		storage, state := newMemoryStorage(t)
+
+		present := remote
+		present.Worktree = GitWorktreeData{RepoPath: t.TempDir(), WorktreePath: t.TempDir(), BranchName: "x"}
+		_, err := storage.ImportJSON(noGit, importReader(t, present), ConflictSkip)
+		require.NoError(t, err)
+
+		instances := storedInstances(t, state)
+		require.Len(t, instances, 1)
+		assert.Equal(t, Paused, instances[0].Status)
+	})
+
+	t.Run("worktrees on another branch are marked paused", func(t *testing.T) {
+		storage, state := newMemoryStorage(t)
+
+		present := remote
+		present.Worktree = GitWorktreeData{RepoPath: t.TempDir(), WorktreePath: t.TempDir(), BranchName: "user/feature"}
+		gitFake := &fakeWorktreeGit{listed: []string{present.Worktree.WorktreePath}}
+		_, err := storage.ImportJSON(gitFake.executor(), importReader(t, present), ConflictSkip)
+		require.NoError(t, err)
+
+		instances := storedInstances(t, state)
+		require.Len(t, instances, 1)
+		assert.Equal(t, Paused, instances[0].Status)
+	})
+
+	t.Run("rejects invalid JSON", func(t *testing.T) {
+		storage, _ := newMemoryStorage(t)
+		_, err := storage.ImportJSON(noGit, strings.NewReader("not json"), ConflictSkip)
+		assert.Error(t, err)
+	})
+}
+
+func TestExportJSONRoundTrip(t *testing.T) {
+	source, _ := newMemoryStorage(t, InstanceData{Title: "feature", Program: "claude", Status: Paused})
+
+	var buf bytes.Buffer
+	require.NoError(t, source.ExportJSON(&buf))
+
+	target, state := newMemoryStorage(t)
+	_, err := target.ImportJSON((&fakeWorktreeGit{}).executor(), &buf, ConflictSkip)
+	require.NoError(t, err)
+
+	instances := storedInstances(t, state)
+	require.Len(t, instances, 1)
+	assert.Equal(t, "feature", instances[0].Title)
+	assert.Equal(t, "claude", instances[0].Program)
+}
+
+func TestNoteRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	storage, state := newMemoryStorage(t, InstanceData{
+		Title: "feature", Program: "claude", Status: Paused, Note: "fixing the flaky login test",
+	})
+
+	instances, err := storage.LoadInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, "fixing the flaky login test", instances[0].Note)
+
+	instances[0].Note = "waiting for review"
+	require.NoError(t, storage.SaveInstances(instances))
+	assert.Equal(t, "waiting for review", storedInstances(t, state)[0].Note)
+
+	// State files written before notes existed still load.
+	state.data = []byte(`[{"title":"old","program":"claude","status":3,"worktree":{}}]`)
+	instances, err = storage.LoadInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, "old", instances[0].Title)
+	assert.Empty(t, instances[0].Note)
+	assert.Empty(t, instances[0].LastPrompt())
+}
+
+func TestLastPromptRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	storage, state := newMemoryStorage(t, InstanceData{
+		Title: "feature", Program: "claude", Status: Paused, LastPrompt: "run the linter",
+	})
+
+	instances, err := storage.LoadInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, "run the linter", instances[0].LastPrompt())
+
+	require.NoError(t, storage.SaveInstances(instances))
+	assert.Equal(t, "run the linter", storedInstances(t, state)[0].LastPrompt)
+}
+
+func TestQueuedPromptsRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	storage, state := newMemoryStorage(t, InstanceData{
+		Title: "feature", Program: "claude", Status: Paused, QueuedPrompts: []string{"run the linter"},
+	})
+
+	instances, err := storage.LoadInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, 1, instances[0].QueuedPrompts())
+	assert.Equal(t, "run the linter", instances[0].NextQueuedPrompt())
+
+	instances[0].QueuePrompt("fix what it finds")
+	require.NoError(t, storage.SaveInstances(instances))
+	assert.Equal(t, []string{"run the linter", "fix what it finds"}, storedInstances(t, state)[0].QueuedPrompts)
+}
+
+func TestColorIconRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	storage, state := newMemoryStorage(t, InstanceData{
+		Title: "feature", Program: "claude", Status: Paused, Color: "blue", Icon: "🚀",
+	})
+
+	instances, err := storage.LoadInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, "blue", instances[0].Color)
+	assert.Equal(t, "🚀", instances[0].Icon)
+
+	instances[0].Color = "#ff8800"
+	instances[0].Icon = ""
+	require.NoError(t, storage.SaveInstances(instances))
+	stored := storedInstances(t, state)[0]
+	assert.Equal(t, "#ff8800", stored.Color)
+	assert.Empty(t, stored.Icon)
+	assert.NotContains(t, string(state.data), `"icon"`, "an empty icon isn't stored")
+
+	// State files written before colors and icons existed still load, without either.
+	state.data = []byte(`[{"title":"old","program":"claude","status":3,"worktree":{}}]`)
+	instances, err = storage.LoadInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Empty(t, instances[0].Color)
+	assert.Empty(t, instances[0].Icon)
+}
+
+func TestGroupRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	storage, state := newMemoryStorage(t, InstanceData{Title: "feature", Program: "claude", Status: Paused, Group: "api"})
+
+	instances, err := storage.LoadInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, "api", instances[0].Group)
+
+	instances[0].Group = ""
+	require.NoError(t, storage.SaveInstances(instances))
+	assert.Empty(t, storedInstances(t, state)[0].Group)
+	assert.NotContains(t, string(state.data), `"group"`, "an ungrouped instance doesn't store a group")
+}
+
+func TestParseConflictMode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ConflictMode
+		wantErr  bool
+	}{
+		{input: "skip", expected: ConflictSkip},
+		{input: "overwrite", expected: ConflictOverwrite},
+		{input: "Rename", expected: ConflictRename},
+		{input: "merge", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			mode, err := ParseConflictMode(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, mode)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package session
+
+import (
+	"claude-squad/config"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// OptionsFromTemplate returns the options for a new instance in path created from tmpl. Only the title is left
+// to fill in. A template without a program runs defaultProgram, and the template's args are appended to the
+// program, quoted for the shell.
+func OptionsFromTemplate(tmpl config.Template, path, defaultProgram string) (InstanceOptions, error) {
+	program := tmpl.Program
+	if program == "" {
+		program = defaultProgram
+	}
+	if strings.TrimSpace(program) == "" {
+		return InstanceOptions{}, fmt.Errorf("template %q has no program to run", tmpl.Name)
+	}
+	// The program is run by the shell as it is, so only the template's own args are quoted.
+	if len(tmpl.Args) > 0 {
+		program += " " + JoinArgs(tmpl.Args)
+	}
+
+	return InstanceOptions{
+		Path:        path,
+		Program:     program,
+		Env:         maps.Clone(tmpl.Env),
+		InitPrompts: slices.Clone(tmpl.InitPrompts),
+		Tags:        slices.Clone(tmpl.Tags),
+	}, nil
+}
@@ -0,0 +1,79 @@
+package session
+
+import (
+	"claude-squad/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsFromTemplate(t *testing.T) {
+	t.Run("bundles the template's settings", func(t *testing.T) {
+		tmpl := config.Template{
+			Name:        "review",
+			Program:     "claude --append-system-prompt 'be brief'",
+			Args:        []string{"--model", "opus"},
+			Env:         map[string]string{"TASK": "review"},
+			InitPrompts: []string{"/review"},
+			Tags:        []string{"review", "slow"},
+		}
+
+		opts, err := OptionsFromTemplate(tmpl, "/repo", "aider")
+		require.NoError(t, err)
+
+		assert.Equal(t, InstanceOptions{
+			Path:        "/repo",
+			Program:     "claude --append-system-prompt 'be brief' --model opus",
+			Env:         map[string]string{"TASK": "review"},
+			InitPrompts: []string{"/review"},
+			Tags:        []string{"review", "slow"},
+		}, opts)
+
+		// The options don't share state with the template.
+		opts.Env["TASK"] = "changed"
+		opts.Tags[0] = "changed"
+		assert.Equal(t, "review", tmpl.Env["TASK"])
+		assert.Equal(t, "review", tmpl.Tags[0])
+	})
+
+	t.Run("defaults to the default program", func(t *testing.T) {
+		opts, err := OptionsFromTemplate(config.Template{Name: "bugfix", Args: []string{"--yes"}}, ".", "aider --model gpt")
+		require.NoError(t, err)
+
+		assert.Equal(t, "aider --model gpt --yes", opts.Program)
+		assert.Nil(t, opts.Args)
+		assert.Nil(t, opts.Env)
+		assert.Nil(t, opts.InitPrompts)
+	})
+
+	t.Run("keeps the program as the shell runs it", func(t *testing.T) {
+		opts, err := OptionsFromTemplate(config.Template{Name: "env", Program: "claude --model $MODEL",
+			Args: []string{"a b"}}, ".", "claude")
+		require.NoError(t, err)
+		assert.Equal(t, "claude --model $MODEL 'a b'", opts.Program)
+	})
+
+	t.Run("rejects templates without a program", func(t *testing.T) {
+		_, err := OptionsFromTemplate(config.Template{Name: "empty", Program: "  "}, ".", "claude")
+		assert.ErrorContains(t, err, "no program")
+	})
+
+	t.Run("instances keep the template's settings", func(t *testing.T) {
+		opts, err := OptionsFromTemplate(config.Template{Name: "review", Env: map[string]string{"A": "1"},
+			Tags: []string{"review"}}, ".", "claude")
+		require.NoError(t, err)
+		opts.Title = "task"
+
+		instance, err := NewInstance(opts)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"A": "1"}, instance.Env)
+		assert.Equal(t, []string{"review"}, instance.Tags)
+		instance.Branch = "main"
+		assert.Equal(t, "main [review]", instance.createdDetail())
+
+		data := instance.ToInstanceData()
+		assert.Equal(t, map[string]string{"A": "1"}, data.Env)
+		assert.Equal(t, []string{"review"}, data.Tags)
+	})
+}
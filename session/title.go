@@ -0,0 +1,75 @@
+package session
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxTitleWords is the number of words of a prompt used for a title derived from it.
+const maxTitleWords = 5
+
+// latinFolds spells common accented Latin letters in ASCII, so "café" becomes "cafe" rather than "caf".
+var latinFolds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a",
+	'ç': "c", 'č': "c", 'ć': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i",
+	'ł': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o",
+	'ř': "r",
+	'ś': "s", 'š': "s",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u",
+	'ý': "y", 'ÿ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+	'ß': "ss", 'æ': "ae", 'œ': "oe",
+}
+
+// TitleFromPrompt derives an instance title from the first few words of a prompt. The result is lowercase,
+// hyphenated and only contains ASCII letters, digits and hyphens, so it also makes a valid branch name. It is
+// cut at a word boundary to at most maxLen characters. Returns "" if the prompt has no usable words.
+func TitleFromPrompt(prompt string, maxLen int) string {
+	var words []string
+	for _, field := range strings.FieldsFunc(prompt, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	}) {
+		if word := slugWord(field); word != "" {
+			words = append(words, word)
+		}
+		if len(words) == maxTitleWords {
+			break
+		}
+	}
+
+	title := ""
+	for _, word := range words {
+		candidate := word
+		if title != "" {
+			candidate = title + "-" + word
+		}
+		if len(candidate) > maxLen {
+			if title == "" {
+				// A single overlong word is cut rather than dropped.
+				title = word[:maxLen]
+			}
+			break
+		}
+		title = candidate
+	}
+	return title
+}
+
+// slugWord lowercases word and keeps only ASCII letters and digits, folding accented Latin letters.
+// Apostrophes are dropped so "don't" becomes "dont".
+func slugWord(word string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(word) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteString(latinFolds[r])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTitleFromPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		prompt string
+		maxLen int
+		want   string
+	}{
+		{"first words", "Fix the flaky login test in CI please", 32, "fix-the-flaky-login-test"},
+		{"punctuation", "Refactor: the *parser*, (again)!!! -- now?", 32, "refactor-the-parser-again-now"},
+		{"apostrophes", "Don't break the user's config", 32, "dont-break-the-users-config"},
+		{"paths and symbols", "update src/ui/list.go & README.md", 32, "update-src-ui-list-go"},
+		{"accented latin letters", "Café déjà vu: naïve façade", 32, "cafe-deja-vu-naive-facade"},
+		{"german", "Größe der Straße ändern", 32, "grosse-der-strasse-andern"},
+		{"non-latin scripts are dropped", "修复 login 错误 bug", 32, "login-bug"},
+		{"emoji are dropped", "🚀 ship the release 🎉", 32, "ship-the-release"},
+		{"only unusable characters", "修复错误 🎉 !!!", 32, ""},
+		{"empty", "   \n\t", 32, ""},
+		{"multi-line", "add tests\nfor the\nslugifier", 32, "add-tests-for-the-slugifier"},
+		{"cut at a word boundary", "implement the configuration reloading feature", 20, "implement-the"},
+		{"overlong single word is cut", "supercalifragilisticexpialidocious", 10, "supercalif"},
+		{"digits kept", "Bump Go to 1.24", 32, "bump-go-to-1-24"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TitleFromPrompt(tt.prompt, tt.maxLen)
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, len(got), tt.maxLen)
+			assert.Regexp(t, `^([a-z0-9]+(-[a-z0-9]+)*)?$`, got)
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package tmux
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// controlMode makes sessions capture their pane over a tmux control mode connection instead of running
+// capture-pane for every capture.
+var controlMode bool
+
+// SetControlMode sets whether sessions keep a tmux control mode (tmux -C) connection open to capture their pane.
+// The connection also tells when the pane has output, so panes without output aren't captured at all. Sessions
+// fall back to capture-pane when the connection can't be made.
+func SetControlMode(enabled bool) {
+	controlMode = enabled
+}
+
+const (
+	// controlTimeout is how long a command on a control mode connection may take before the connection is given up.
+	controlTimeout = 2 * time.Second
+	// controlRetryInterval is how long a session waits before connecting again after a connection failed.
+	controlRetryInterval = 30 * time.Second
+)
+
+var errControlClosed = errors.New("tmux control mode connection closed")
+
+// controlResponse is the output of one command on a control mode connection.
+type controlResponse struct {
+	output string
+	err    error
+}
+
+// controlClient is a tmux control mode connection to a session. It is attached read-only and doesn't change the
+// size of the session's window.
+type controlClient struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	// mu serializes commands so that responses come back in the order commands were sent.
+	mu        sync.Mutex
+	responses chan controlResponse
+	// done is closed when the connection ends.
+	done chan struct{}
+	// output is set when the pane has output since takeOutput was last called.
+	output atomic.Bool
+}
+
+// startControlClient connects to the session target in control mode.
+func startControlClient(target string) (*controlClient, error) {
+	cmd := tmuxCommand("-C", "attach-session", "-t", target, "-f", "ignore-size,read-only")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting tmux control mode: %w", err)
+	}
+
+	c := &controlClient{
+		cmd:       cmd,
+		stdin:     stdin,
+		responses: make(chan controlResponse, 1),
+		done:      make(chan struct{}),
+	}
+	// The first capture is always made, since nothing is known about the pane yet.
+	c.output.Store(true)
+	go c.read(stdout)
+
+	// tmux answers the attach itself with an empty block before it takes commands.
+	if _, err := c.wait(); err != nil {
+		c.close()
+		return nil, fmt.Errorf("error attaching tmux control mode to %s: %w", target, err)
+	}
+	return c, nil
+}
+
+// run runs a tmux command on the connection and returns its output.
+func (c *controlClient) run(args ...string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = controlQuote(arg)
+	}
+	if _, err := io.WriteString(c.stdin, strings.Join(quoted, " ")+"\n"); err != nil {
+		c.close()
+		return "", fmt.Errorf("error writing to tmux control mode: %w", err)
+	}
+	return c.wait()
+}
+
+// wait waits for the response to the command that was sent last.
+func (c *controlClient) wait() (string, error) {
+	select {
+	case resp := <-c.responses:
+		return resp.output, resp.err
+	case <-c.done:
+		return "", errControlClosed
+	case <-time.After(controlTimeout):
+		// A late response would be taken for the next command's, so the connection can't be used anymore.
+		c.close()
+		return "", fmt.Errorf("timed out waiting for tmux control mode")
+	}
+}
+
+// takeOutput reports whether the pane had output since it was last called.
+func (c *controlClient) takeOutput() bool {
+	return c.output.Swap(false)
+}
+
+// alive reports whether the connection is still open.
+func (c *controlClient) alive() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// close ends the connection. tmux detaches the client when its stdin closes.
+func (c *controlClient) close() {
+	_ = c.stdin.Close()
+	select {
+	case <-c.done:
+	case <-time.After(controlTimeout):
+		_ = c.cmd.Process.Kill()
+		<-c.done
+	}
+}
+
+// read reads the connection's output until it ends. Lines between %begin and %end or %error are a command's
+// response. Other lines are notifications, of which only the ones about pane output are of interest.
+func (c *controlClient) read(stdout io.Reader) {
+	defer func() {
+		_ = c.cmd.Wait()
+		close(c.done)
+	}()
+
+	r := bufio.NewReader(stdout)
+	var (
+		guard string
+		block strings.Builder
+	)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		line = bytes.TrimSuffix(line, []byte("\n"))
+
+		if guard != "" {
+			// The guard is "%begin"'s time, command number and flags, which "%end" and "%error" repeat.
+			if rest, ok := bytes.CutPrefix(line, []byte("%end ")); ok && string(rest) == guard {
+				c.respond(controlResponse{output: block.String()})
+				guard = ""
+				continue
+			}
+			if rest, ok := bytes.CutPrefix(line, []byte("%error ")); ok && string(rest) == guard {
+				c.respond(controlResponse{err: fmt.Errorf("tmux: %s", strings.TrimSpace(block.String()))})
+				guard = ""
+				continue
+			}
+			block.Write(line)
+			block.WriteByte('\n')
+			continue
+		}
+
+		switch {
+		case bytes.HasPrefix(line, []byte("%begin ")):
+			guard = string(line[len("%begin "):])
+			block.Reset()
+		case bytes.HasPrefix(line, []byte("%output ")), bytes.HasPrefix(line, []byte("%layout-change ")):
+			c.output.Store(true)
+		case bytes.HasPrefix(line, []byte("%exit")):
+			return
+		}
+	}
+}
+
+// respond hands resp to the command waiting for it. A response nobody waits for anymore is dropped, so that
+// reading never blocks.
+func (c *controlClient) respond(resp controlResponse) {
+	select {
+	case c.responses <- resp:
+	default:
+	}
+}
+
+// controlQuote quotes arg for tmux's command parser.
+func controlQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
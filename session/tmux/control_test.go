@@ -0,0 +1,190 @@
+package tmux
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testServers counts the test servers, so that each test gets a socket of its own while the last one shuts down.
+var testServers atomic.Int32
+
+// useTestServer makes tmux commands run on a tmux server of the test's own, which is killed when the test ends.
+// The test is skipped if tmux isn't installed.
+func useTestServer(tb testing.TB) {
+	tb.Helper()
+	if _, err := exec.LookPath("tmux"); err != nil {
+		tb.Skip("tmux is not installed")
+	}
+	prevArgs, prevControlMode := serverArgs, controlMode
+	socket := fmt.Sprintf("claudesquad-test-%d-%d", os.Getpid(), testServers.Add(1))
+	serverArgs = []string{"-L", socket, "-f", os.DevNull}
+	tb.Cleanup(func() {
+		_ = tmuxCommand("kill-server").Run()
+		serverArgs, controlMode = prevArgs, prevControlMode
+	})
+}
+
+// startTestSession starts a detached session running program and waits until its pane shows ready.
+func startTestSession(tb testing.TB, name, program, ready string) *TmuxSession {
+	tb.Helper()
+	session := NewTmuxSession(name, program)
+	session.monitor = newStatusMonitor()
+	out, err := tmuxCommand("new-session", "-d", "-s", session.sanitizedName, "-x", "80", "-y", "24", program).
+		CombinedOutput()
+	require.NoError(tb, err, string(out))
+	tb.Cleanup(session.closeControl)
+
+	require.Eventually(tb, func() bool {
+		out, err := tmuxCommand("capture-pane", "-p", "-t", session.sanitizedName).Output()
+		return err == nil && strings.Contains(string(out), ready)
+	}, 5*time.Second, 10*time.Millisecond)
+	return session
+}
+
+func TestControlModeCapture(t *testing.T) {
+	useTestServer(t)
+	session := startTestSession(t, "capture", `printf '\033[31mred\033[0m line\n'; cat`, "red line")
+
+	controlMode = false
+	expected, err := session.CapturePaneContent()
+	require.NoError(t, err)
+	expectedHistory, err := session.CapturePaneContentWithOptions("-", "-")
+	require.NoError(t, err)
+
+	controlMode = true
+	content, err := session.CapturePaneContent()
+	require.NoError(t, err)
+	require.NotNil(t, session.control, "the capture should go over control mode")
+	require.Equal(t, expected, content)
+	require.Contains(t, content, "\033[31mred")
+
+	history, err := session.CapturePaneContentWithOptions("-", "-")
+	require.NoError(t, err)
+	require.Equal(t, expectedHistory, history)
+}
+
+func TestControlModeHasUpdated(t *testing.T) {
+	useTestServer(t)
+	controlMode = true
+	session := startTestSession(t, "updates", "cat", "")
+
+	updated, _ := session.HasUpdated()
+	require.True(t, updated, "the first check should capture the pane")
+	updated, _ = session.HasUpdated()
+	require.False(t, updated, "a pane without output should not be updated")
+
+	require.NoError(t, session.SendKeyNames("hello"))
+	require.Eventually(t, func() bool {
+		updated, _ := session.HasUpdated()
+		return updated
+	}, 5*time.Second, 10*time.Millisecond, "output should be noticed")
+	content, err := session.CapturePaneContent()
+	require.NoError(t, err)
+	require.Contains(t, content, "hello")
+}
+
+func TestControlModeQuotesSessionName(t *testing.T) {
+	useTestServer(t)
+	controlMode = true
+	session := startTestSession(t, "bob's", "printf 'quoted\n'; cat", "quoted")
+
+	content, err := session.CapturePaneContent()
+	require.NoError(t, err)
+	require.NotNil(t, session.control)
+	require.Contains(t, content, "quoted")
+}
+
+func TestControlModeFallback(t *testing.T) {
+	useTestServer(t)
+	controlMode = true
+	session := startTestSession(t, "fallback", "printf 'fallback\n'; cat", "fallback")
+
+	t.Run("reconnects after the connection closes", func(t *testing.T) {
+		_, err := session.CapturePaneContent()
+		require.NoError(t, err)
+		closed := session.control
+		closed.close()
+		require.False(t, closed.alive())
+
+		content, err := session.CapturePaneContent()
+		require.NoError(t, err)
+		require.Contains(t, content, "fallback")
+		require.NotSame(t, closed, session.control)
+	})
+
+	t.Run("uses capture-pane while it can't connect", func(t *testing.T) {
+		session.closeControl()
+		session.controlRetry = time.Now().Add(time.Hour)
+
+		content, err := session.CapturePaneContent()
+		require.NoError(t, err)
+		require.Contains(t, content, "fallback")
+		require.Nil(t, session.control)
+	})
+
+	t.Run("fails to connect to a missing session", func(t *testing.T) {
+		_, err := startControlClient(TmuxPrefix + "missing")
+		require.Error(t, err)
+	})
+}
+
+// BenchmarkHasUpdated compares checking an idle session for updates with capture-pane and with control mode.
+func BenchmarkHasUpdated(b *testing.B) {
+	useTestServer(b)
+	session := startTestSession(b, "bench", "printf 'idle\n'; cat", "idle")
+
+	for _, bench := range []struct {
+		name        string
+		controlMode bool
+	}{
+		{name: "capture-pane", controlMode: false},
+		{name: "control-mode", controlMode: true},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			controlMode = bench.controlMode
+			session.closeControl()
+			session.monitor = newStatusMonitor()
+			session.HasUpdated()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				session.HasUpdated()
+			}
+		})
+	}
+}
+
+// BenchmarkCapturePaneContent compares capturing a session with capture-pane and with control mode.
+func BenchmarkCapturePaneContent(b *testing.B) {
+	useTestServer(b)
+	session := startTestSession(b, "bench", "printf 'idle\n'; cat", "idle")
+
+	for _, bench := range []struct {
+		name        string
+		controlMode bool
+	}{
+		{name: "capture-pane", controlMode: false},
+		{name: "control-mode", controlMode: true},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			controlMode = bench.controlMode
+			session.closeControl()
+			_, err := session.CapturePaneContent()
+			require.NoError(b, err)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := session.CapturePaneContent(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -9,9 +9,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -31,6 +34,8 @@ type TmuxSession struct {
 	// The name of the tmux session and the sanitized name used for tmux commands.
 	sanitizedName string
 	program       string
+	// env holds KEY=VALUE entries exported to the program when the session is created.
+	env []string
 	// ptyFactory is used to create a PTY for the tmux session.
 	ptyFactory PtyFactory
 	// cmdExec is used to execute commands in the tmux session.
@@ -55,16 +60,127 @@ type TmuxSession struct {
 	ctx    context.Context
 	cancel func()
 	wg     *sync.WaitGroup
+
+	// With control mode, the pane is captured over control, which is connected on the first capture.
+	// controlRetry is when to try connecting again after a connection failed.
+	controlMu    sync.Mutex
+	control      *controlClient
+	controlRetry time.Time
 }
 
+// TmuxPrefix is the default prefix for the names of tmux sessions created by claude-squad.
 const TmuxPrefix = "claudesquad_"
 
+// sessionPrefix is the prefix applied to new session names and used to recognize our own sessions.
+var sessionPrefix = TmuxPrefix
+
 var whiteSpaceRegex = regexp.MustCompile(`\s+`)
 
-func toClaudeSquadTmuxName(str string) string {
+// SetSessionPrefix sets the prefix used for tmux session names. An empty prefix restores TmuxPrefix so that we
+// never mistake sessions that aren't ours for claude-squad sessions.
+func SetSessionPrefix(prefix string) {
+	prefix = sanitizeSessionName(prefix)
+	if prefix == "" {
+		prefix = TmuxPrefix
+	}
+	sessionPrefix = prefix
+}
+
+// SessionPrefix returns the prefix used for tmux session names.
+func SessionPrefix() string {
+	return sessionPrefix
+}
+
+// DefaultDetachKey is the key that detaches from an attached session unless another one is configured.
+const DefaultDetachKey = "ctrl+q"
+
+// detachKey is the configured detach key and detachByte the byte the terminal sends for it.
+var (
+	detachKey       = DefaultDetachKey
+	detachByte byte = 17
+)
+
+// SetDetachKey sets the key that detaches from an attached session and returns to claude-squad, like "ctrl+q".
+// It has to be a control key that the terminal sends as a single byte: ctrl with a letter or one of \ ] ^ _.
+// ctrl+c, ctrl+h, ctrl+i, ctrl+j and ctrl+m are refused because the program needs them. An empty key restores
+// DefaultDetachKey. On error the detach key is unchanged.
+func SetDetachKey(key string) error {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" {
+		key = DefaultDetachKey
+	}
+	char, ok := strings.CutPrefix(key, "ctrl+")
+	if !ok || len(char) != 1 {
+		return fmt.Errorf("invalid detach key %q: use ctrl with a single key, like %s", key, DefaultDetachKey)
+	}
+
+	var b byte
+	switch c := char[0]; {
+	case c == 'c' || c == 'h' || c == 'i' || c == 'j' || c == 'm':
+		return fmt.Errorf("invalid detach key %q: it is needed in the session", key)
+	case c >= 'a' && c <= 'z':
+		b = c - 'a' + 1
+	case c == '\\' || c == ']' || c == '^' || c == '_':
+		b = c - '@'
+	default:
+		return fmt.Errorf("invalid detach key %q: use ctrl with a letter or one of \\ ] ^ _", key)
+	}
+	detachKey, detachByte = key, b
+	return nil
+}
+
+// DetachKey returns the key that detaches from an attached session.
+func DetachKey() string {
+	return detachKey
+}
+
+// isolatedSocketName is the name of the tmux socket used when sessions run on their own tmux server.
+const isolatedSocketName = "claudesquad"
+
+// serverArgs select the tmux server that sessions run on. They are empty for the user's default server.
+var serverArgs []string
+
+// SetIsolatedServer makes sessions run on a tmux server of their own that doesn't load the user's tmux config, so
+// key bindings and options inside sessions are the same for everyone. Sessions on the other server are not seen,
+// so changing this makes existing instances start new sessions.
+func SetIsolatedServer(isolated bool) {
+	if isolated {
+		serverArgs = []string{"-L", isolatedSocketName, "-f", os.DevNull}
+	} else {
+		serverArgs = nil
+	}
+}
+
+// ServerCommand returns the tmux command to use to reach the server sessions run on, like "tmux -L claudesquad"
+// for the isolated server.
+func ServerCommand() string {
+	if len(serverArgs) == 0 {
+		return "tmux"
+	}
+	return "tmux -L " + isolatedSocketName
+}
+
+// tmuxCommand returns a tmux command with args that runs on the server sessions are on.
+func tmuxCommand(args ...string) *exec.Cmd {
+	return exec.Command("tmux", append(append([]string(nil), serverArgs...), args...)...)
+}
+
+// sanitizeSessionName makes str a valid tmux session name. tmux uses : and . as target separators, so
+// they can't appear in session names.
+func sanitizeSessionName(str string) string {
 	str = whiteSpaceRegex.ReplaceAllString(str, "")
 	str = strings.ReplaceAll(str, ".", "_") // tmux replaces all . with _
-	return fmt.Sprintf("%s%s", TmuxPrefix, str)
+	str = strings.ReplaceAll(str, ":", "_")
+	return str
+}
+
+func toClaudeSquadTmuxName(str string) string {
+	return fmt.Sprintf("%s%s", sessionPrefix, sanitizeSessionName(str))
+}
+
+// SessionName returns the name of the tmux session of an instance with the given title.
+func SessionName(title string) string {
+	return toClaudeSquadTmuxName(title)
 }
 
 // NewTmuxSession creates a new TmuxSession with the given name and program.
@@ -86,11 +202,55 @@ func newTmuxSession(name string, program string, ptyFactory PtyFactory, cmdExec
 	}
 }
 
+// shellQuote wraps s in single quotes so the shell takes it literally.
+func shellQuote(s string) string {
+	// A single quote can't appear inside single quotes, so close the quotes, add an escaped quote and reopen.
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// SetEnv sets variables in the environment of the program. It applies to sessions created afterwards.
+func (t *TmuxSession) SetEnv(env map[string]string) {
+	t.env = nil
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		t.env = append(t.env, name+"="+env[name])
+	}
+}
+
 // Start creates and starts a new tmux session, then attaches to it. Program is the command to run in
 // the session (ex. claude). workdir is the git worktree directory.
 func (t *TmuxSession) Start(workDir string) error {
 	totalStart := time.Now()
+	if err := t.Launch(workDir); err != nil {
+		return err
+	}
+	t.AcceptTrustScreen()
+
+	if log.InfoLog != nil {
+		log.InfoLog.Printf("[tmux timing] TOTAL tmux Start(): %v", time.Since(totalStart))
+		// Final check - is session actually alive?
+		exists := t.DoesSessionExist()
+		log.InfoLog.Printf("[tmux debug] Session alive at end of Start(): %v", exists)
+		if exists {
+			// Try to capture content to see what's in the pane
+			content, err := t.CapturePaneContent()
+			if err != nil {
+				log.InfoLog.Printf("[tmux debug] Failed to capture pane: %v", err)
+			} else {
+				// Log first 200 chars of content
+				preview := content
+				if len(preview) > 200 {
+					preview = preview[:200]
+				}
+				log.InfoLog.Printf("[tmux debug] Pane content preview: %q", preview)
+			}
+		}
+	}
+	return nil
+}
 
+// Launch creates a new tmux session running the program and attaches to it, without waiting for the program to
+// come up. workdir is the git worktree directory.
+func (t *TmuxSession) Launch(workDir string) error {
 	// Check if the session already exists
 	if t.DoesSessionExist() {
 		return fmt.Errorf("tmux session already exists: %s", t.sanitizedName)
@@ -105,13 +265,21 @@ func (t *TmuxSession) Start(workDir string) error {
 		userShell = "bash"
 	}
 	shellCmd := fmt.Sprintf("exec %s", t.program)
-	cmd := exec.Command("tmux", "new-session", "-d", "-s", t.sanitizedName, "-c", workDir, userShell, "-i", "-c", shellCmd)
+	if len(t.env) > 0 {
+		// The shell exports the variables, since new-session -e needs tmux 3.2.
+		quoted := make([]string, len(t.env))
+		for i, entry := range t.env {
+			quoted[i] = shellQuote(entry)
+		}
+		shellCmd = fmt.Sprintf("export %s; %s", strings.Join(quoted, " "), shellCmd)
+	}
+	cmd := tmuxCommand("new-session", "-d", "-s", t.sanitizedName, "-c", workDir, userShell, "-i", "-c", shellCmd)
 
 	ptmx, err := t.ptyFactory.Start(cmd)
 	if err != nil {
 		// Cleanup any partially created session if any exists.
 		if t.DoesSessionExist() {
-			cleanupCmd := exec.Command("tmux", "kill-session", "-t", t.sanitizedName)
+			cleanupCmd := tmuxCommand("kill-session", "-t", t.sanitizedName)
 			if cleanupErr := t.cmdExec.Run(cleanupCmd); cleanupErr != nil {
 				err = fmt.Errorf("%v (cleanup error: %v)", err, cleanupErr)
 			}
@@ -148,7 +316,7 @@ func (t *TmuxSession) Start(workDir string) error {
 
 	// Set history limit to enable scrollback (default is 2000, we'll use 10000 for more history)
 	stageStart = time.Now()
-	historyCmd := exec.Command("tmux", "set-option", "-t", t.sanitizedName, "history-limit", "10000")
+	historyCmd := tmuxCommand("set-option", "-t", t.sanitizedName, "history-limit", "10000")
 	if err := t.cmdExec.Run(historyCmd); err != nil {
 		if log.InfoLog != nil {
 			// Check if session still exists
@@ -158,13 +326,20 @@ func (t *TmuxSession) Start(workDir string) error {
 	}
 
 	// Enable mouse scrolling for the session
-	mouseCmd := exec.Command("tmux", "set-option", "-t", t.sanitizedName, "mouse", "on")
+	mouseCmd := tmuxCommand("set-option", "-t", t.sanitizedName, "mouse", "on")
 	if err := t.cmdExec.Run(mouseCmd); err != nil {
 		if log.InfoLog != nil {
 			exists := t.DoesSessionExist()
 			log.InfoLog.Printf("Warning: failed to enable mouse scrolling for session %s: %v (session exists: %v)", t.sanitizedName, err, exists)
 		}
 	}
+	// Keep the pane when the program exits, so that its exit code can be read and its last output stays visible
+	remainCmd := tmuxCommand("set-option", "-t", t.sanitizedName, "remain-on-exit", "on")
+	if err := t.cmdExec.Run(remainCmd); err != nil {
+		if log.InfoLog != nil {
+			log.InfoLog.Printf("Warning: failed to set remain-on-exit for session %s: %v", t.sanitizedName, err)
+		}
+	}
 	if log.InfoLog != nil {
 		log.InfoLog.Printf("[tmux timing] Set tmux options: %v", time.Since(stageStart))
 	}
@@ -180,8 +355,13 @@ func (t *TmuxSession) Start(workDir string) error {
 	if log.InfoLog != nil {
 		log.InfoLog.Printf("[tmux timing] Restore PTY: %v", time.Since(stageStart))
 	}
+	return nil
+}
 
-	stageStart = time.Now()
+// AcceptTrustScreen waits briefly for the program's trust screen and accepts it, for the programs known to show
+// one. It gives up quietly if the screen doesn't show up, since the user can still answer it from the preview.
+func (t *TmuxSession) AcceptTrustScreen() {
+	stageStart := time.Now()
 
 	// Check if program contains known agent names (handles flags like "claude --dangerously-skip-permissions")
 	isClaude := strings.Contains(t.program, ProgramClaude)
@@ -230,45 +410,32 @@ func (t *TmuxSession) Start(workDir string) error {
 			log.InfoLog.Printf("[tmux timing] Trust screen wait: %v (foundTrust=%v)", time.Since(stageStart), foundTrust)
 		}
 	}
-	if log.InfoLog != nil {
-		log.InfoLog.Printf("[tmux timing] TOTAL tmux Start(): %v", time.Since(totalStart))
-		// Final check - is session actually alive?
-		exists := t.DoesSessionExist()
-		log.InfoLog.Printf("[tmux debug] Session alive at end of Start(): %v", exists)
-		if exists {
-			// Try to capture content to see what's in the pane
-			content, err := t.CapturePaneContent()
-			if err != nil {
-				log.InfoLog.Printf("[tmux debug] Failed to capture pane: %v", err)
-			} else {
-				// Log first 200 chars of content
-				preview := content
-				if len(preview) > 200 {
-					preview = preview[:200]
-				}
-				log.InfoLog.Printf("[tmux debug] Pane content preview: %q", preview)
-			}
-		}
-	}
-	return nil
 }
 
 // Restore attaches to an existing session and restores the window size
 func (t *TmuxSession) Restore() error {
-	ptmx, err := t.ptyFactory.Start(exec.Command("tmux", "attach-session", "-t", t.sanitizedName))
+	ptmx, err := t.ptyFactory.Start(tmuxCommand("attach-session", "-t", t.sanitizedName))
 	if err != nil {
 		return fmt.Errorf("error opening PTY: %w", err)
 	}
 	t.ptmx = ptmx
 	t.monitor = newStatusMonitor()
+	t.closeControl()
 	return nil
 }
 
 type statusMonitor struct {
 	// Store hashes to save memory.
 	prevOutputHash []byte
+	// hasPrompt is whether the pane had a prompt when it was last captured.
+	hasPrompt bool
+	// promptText holds the tail of the pane when a prompt was last detected.
+	promptText string
 }
 
+// promptTextLines is the number of trailing pane lines kept as the prompt text.
+const promptTextLines = 20
+
 func newStatusMonitor() *statusMonitor {
 	return &statusMonitor{}
 }
@@ -304,9 +471,27 @@ func (t *TmuxSession) SendKeys(keys string) error {
 	return err
 }
 
+// SendKeyNames sends keys to the pane with tmux send-keys. Unlike SendKeys, the keys are tmux key names like C-c
+// or Escape, so they work without the PTY.
+func (t *TmuxSession) SendKeyNames(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	cmd := tmuxCommand(append([]string{"send-keys", "-t", t.sanitizedName}, keys...)...)
+	if output, err := t.cmdExec.CombinedOutput(cmd); err != nil {
+		return fmt.Errorf("error sending keys to %s: %v, output: %s", t.sanitizedName, err, string(output))
+	}
+	return nil
+}
+
 // HasUpdated checks if the tmux pane content has changed since the last tick. It also returns true if
 // the tmux pane has a prompt for aider or claude code.
 func (t *TmuxSession) HasUpdated() (updated bool, hasPrompt bool) {
+	// A pane that had no output since it was last captured is unchanged and doesn't need to be captured again.
+	if control := t.controlConn(); control != nil && t.monitor.prevOutputHash != nil && !control.takeOutput() {
+		return false, t.monitor.hasPrompt
+	}
+
 	content, err := t.CapturePaneContent()
 	if err != nil {
 		// Don't log errors here - they're expected during session startup/shutdown
@@ -323,6 +508,12 @@ func (t *TmuxSession) HasUpdated() (updated bool, hasPrompt bool) {
 	} else if strings.Contains(t.program, ProgramGemini) {
 		hasPrompt = strings.Contains(content, "Yes, allow once")
 	}
+	t.monitor.hasPrompt = hasPrompt
+	if hasPrompt {
+		t.monitor.promptText = lastLines(content, promptTextLines)
+	} else {
+		t.monitor.promptText = ""
+	}
 
 	if !bytes.Equal(t.monitor.hash(content), t.monitor.prevOutputHash) {
 		t.monitor.prevOutputHash = t.monitor.hash(content)
@@ -331,6 +522,21 @@ func (t *TmuxSession) HasUpdated() (updated bool, hasPrompt bool) {
 	return false, hasPrompt
 }
 
+// PromptText returns the tail of the pane from the last HasUpdated call that detected a prompt, or an empty
+// string if no prompt was detected.
+func (t *TmuxSession) PromptText() string {
+	return t.monitor.promptText
+}
+
+// lastLines returns the last n lines of s, ignoring trailing blank lines.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n "), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (t *TmuxSession) Attach() (chan struct{}, error) {
 	t.attachCh = make(chan struct{})
 
@@ -355,7 +561,7 @@ func (t *TmuxSession) Attach() (chan struct{}, error) {
 		default:
 			// If context is not done, it was likely an abnormal termination (Ctrl-D)
 			// Print warning message
-			fmt.Fprintf(os.Stderr, "\n\033[31mError: Session terminated without detaching. Use Ctrl-Q to properly detach from tmux sessions.\033[0m\n")
+			fmt.Fprintf(os.Stderr, "\n\033[31mError: Session terminated without detaching. Use %s to properly detach from tmux sessions.\033[0m\n", detachKey)
 		}
 	}()
 
@@ -392,8 +598,8 @@ func (t *TmuxSession) Attach() (chan struct{}, error) {
 				continue
 			}
 
-			// Check for Ctrl+q (ASCII 17)
-			if nr == 1 && buf[0] == 17 {
+			// Check for the detach key, ctrl+q unless configured otherwise
+			if nr == 1 && buf[0] == detachByte {
 				// Detach from the session
 				t.Detach()
 				return
@@ -410,6 +616,9 @@ func (t *TmuxSession) Attach() (chan struct{}, error) {
 
 // DetachSafely disconnects from the current tmux session without panicking
 func (t *TmuxSession) DetachSafely() error {
+	// A paused session isn't captured, so it doesn't need its control mode connection.
+	t.closeControl()
+
 	// Only detach if we're actually attached
 	if t.attachCh == nil {
 		return nil // Already detached
@@ -489,6 +698,8 @@ func (t *TmuxSession) Detach() {
 func (t *TmuxSession) Close() error {
 	var errs []error
 
+	t.closeControl()
+
 	if t.ptmx != nil {
 		if err := t.ptmx.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("error closing PTY: %w", err))
@@ -496,7 +707,7 @@ func (t *TmuxSession) Close() error {
 		t.ptmx = nil
 	}
 
-	cmd := exec.Command("tmux", "kill-session", "-t", t.sanitizedName)
+	cmd := tmuxCommand("kill-session", "-t", t.sanitizedName)
 	if err := t.cmdExec.Run(cmd); err != nil {
 		errs = append(errs, fmt.Errorf("error killing tmux session: %w", err))
 	}
@@ -531,21 +742,82 @@ func (t *TmuxSession) updateWindowSize(cols, rows int) error {
 	})
 }
 
+// Name returns the name of the tmux session.
+func (t *TmuxSession) Name() string {
+	return t.sanitizedName
+}
+
+// AttachArgs returns the command that attaches a terminal to the session, on the server the session runs on.
+func (t *TmuxSession) AttachArgs() []string {
+	args := append([]string{"tmux"}, serverArgs...)
+	return append(args, "attach-session", "-t", "="+t.sanitizedName)
+}
+
+// ObserveArgs returns the command that attaches a terminal to the session read-only, so someone else can watch it
+// without typing into it.
+func (t *TmuxSession) ObserveArgs() []string {
+	return append(t.AttachArgs(), "-r")
+}
+
 func (t *TmuxSession) DoesSessionExist() bool {
 	// Using "-t name" does a prefix match, which is wrong. `-t=` does an exact match.
-	existsCmd := exec.Command("tmux", "has-session", fmt.Sprintf("-t=%s", t.sanitizedName))
+	existsCmd := tmuxCommand("has-session", fmt.Sprintf("-t=%s", t.sanitizedName))
 	return t.cmdExec.Run(existsCmd) == nil
 }
 
+// ExitStatus is how the program in a session's pane ended.
+type ExitStatus struct {
+	// Exited is true if the program exited. Its pane stays until the session is closed.
+	Exited bool
+	// Code is the exit code of the program, or -1 if it was killed by a signal.
+	Code int
+}
+
+// ExitStatus returns whether the program in the session has exited, and with which code.
+func (t *TmuxSession) ExitStatus() (ExitStatus, error) {
+	cmd := tmuxCommand("display-message", "-p", "-t", t.sanitizedName, "#{pane_dead} #{pane_dead_status}")
+	output, err := t.cmdExec.Output(cmd)
+	if err != nil {
+		return ExitStatus{}, fmt.Errorf("failed to get exit status of %s: %w", t.sanitizedName, err)
+	}
+	return parseExitStatus(string(output))
+}
+
+// parseExitStatus parses the "#{pane_dead} #{pane_dead_status}" format of a pane. The status is empty if the pane
+// is alive or the program was killed by a signal.
+func parseExitStatus(output string) (ExitStatus, error) {
+	fields := strings.Fields(output)
+	if len(fields) == 0 || len(fields) > 2 || (fields[0] != "0" && fields[0] != "1") {
+		return ExitStatus{}, fmt.Errorf("unexpected pane status %q", strings.TrimSpace(output))
+	}
+	if fields[0] == "0" {
+		return ExitStatus{}, nil
+	}
+	if len(fields) == 1 {
+		return ExitStatus{Exited: true, Code: -1}, nil
+	}
+	code, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return ExitStatus{}, fmt.Errorf("unexpected exit code %q", fields[1])
+	}
+	return ExitStatus{Exited: true, Code: code}, nil
+}
+
 // CapturePaneContent captures the content of the tmux pane
 func (t *TmuxSession) CapturePaneContent() (string, error) {
+	if control := t.controlConn(); control != nil {
+		if output, err := control.run("capture-pane", "-p", "-e", "-J", "-t", t.sanitizedName); err == nil {
+			return output, nil
+		}
+	}
+
 	// First check if the session exists to avoid noisy errors during startup race conditions
 	if !t.DoesSessionExist() {
 		return "", fmt.Errorf("session does not exist: %s", t.sanitizedName)
 	}
 
 	// Add -e flag to preserve escape sequences (ANSI color codes)
-	cmd := exec.Command("tmux", "capture-pane", "-p", "-e", "-J", "-t", t.sanitizedName)
+	cmd := tmuxCommand("capture-pane", "-p", "-e", "-J", "-t", t.sanitizedName)
 	output, err := t.cmdExec.CombinedOutput(cmd)
 	if err != nil {
 		// Include stderr in the error message for better debugging
@@ -557,13 +829,20 @@ func (t *TmuxSession) CapturePaneContent() (string, error) {
 // CapturePaneContentWithOptions captures the pane content with additional options
 // start and end specify the starting and ending line numbers (use "-" for the start/end of history)
 func (t *TmuxSession) CapturePaneContentWithOptions(start, end string) (string, error) {
+	if control := t.controlConn(); control != nil {
+		output, err := control.run("capture-pane", "-p", "-e", "-J", "-S", start, "-E", end, "-t", t.sanitizedName)
+		if err == nil {
+			return output, nil
+		}
+	}
+
 	// First check if the session exists to avoid noisy errors during startup race conditions
 	if !t.DoesSessionExist() {
 		return "", fmt.Errorf("session does not exist: %s", t.sanitizedName)
 	}
 
 	// Add -e flag to preserve escape sequences (ANSI color codes)
-	cmd := exec.Command("tmux", "capture-pane", "-p", "-e", "-J", "-S", start, "-E", end, "-t", t.sanitizedName)
+	cmd := tmuxCommand("capture-pane", "-p", "-e", "-J", "-S", start, "-E", end, "-t", t.sanitizedName)
 	output, err := t.cmdExec.CombinedOutput(cmd)
 	if err != nil {
 		return "", fmt.Errorf("failed to capture tmux pane content with options: %v, output: %s", err, string(output))
@@ -571,31 +850,91 @@ func (t *TmuxSession) CapturePaneContentWithOptions(start, end string) (string,
 	return string(output), nil
 }
 
-// CleanupSessions kills all tmux sessions that start with "session-"
-func CleanupSessions(cmdExec cmd.Executor) error {
-	// First try to list sessions
-	cmd := exec.Command("tmux", "ls")
+// controlConn returns the session's control mode connection, connecting if there is none, or nil if control mode
+// is off or the connection can't be made. The caller falls back to capture-pane then.
+func (t *TmuxSession) controlConn() *controlClient {
+	if !controlMode {
+		return nil
+	}
+	t.controlMu.Lock()
+	defer t.controlMu.Unlock()
+
+	if t.control != nil {
+		if t.control.alive() {
+			return t.control
+		}
+		t.control = nil
+	}
+	if time.Now().Before(t.controlRetry) {
+		return nil
+	}
+	control, err := startControlClient(t.sanitizedName)
+	if err != nil {
+		t.controlRetry = time.Now().Add(controlRetryInterval)
+		if log.WarningLog != nil {
+			log.WarningLog.Printf("falling back to capture-pane for %s: %v", t.sanitizedName, err)
+		}
+		return nil
+	}
+	t.control = control
+	return control
+}
+
+// closeControl closes the session's control mode connection, if there is one.
+func (t *TmuxSession) closeControl() {
+	t.controlMu.Lock()
+	defer t.controlMu.Unlock()
+
+	if t.control != nil {
+		t.control.close()
+		t.control = nil
+	}
+	t.controlRetry = time.Time{}
+}
+
+// ListSessions returns the names of all running tmux sessions created by claude-squad.
+func ListSessions(cmdExec cmd.Executor) ([]string, error) {
+	cmd := tmuxCommand("ls", "-F", "#{session_name}")
 	output, err := cmdExec.Output(cmd)
 
 	// If there's an error and it's because no server is running, that's fine
 	// Exit code 1 typically means no sessions exist
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
-			return nil // No sessions to clean up
+			return nil, nil
 		}
-		return fmt.Errorf("failed to list tmux sessions: %v", err)
+		return nil, fmt.Errorf("failed to list tmux sessions: %v", err)
 	}
 
-	re := regexp.MustCompile(fmt.Sprintf(`%s.*:`, TmuxPrefix))
-	matches := re.FindAllString(string(output), -1)
-	for i, match := range matches {
-		matches[i] = match[:strings.Index(match, ":")]
+	var sessions []string
+	for _, line := range strings.Split(string(output), "\n") {
+		name := strings.TrimSpace(line)
+		if strings.HasPrefix(name, sessionPrefix) {
+			sessions = append(sessions, name)
+		}
+	}
+	return sessions, nil
+}
+
+// KillSession kills the tmux session with the given name.
+func KillSession(cmdExec cmd.Executor, name string) error {
+	if err := cmdExec.Run(tmuxCommand("kill-session", "-t", name)); err != nil {
+		return fmt.Errorf("failed to kill tmux session %s: %v", name, err)
+	}
+	return nil
+}
+
+// CleanupSessions kills all tmux sessions created by claude-squad.
+func CleanupSessions(cmdExec cmd.Executor) error {
+	sessions, err := ListSessions(cmdExec)
+	if err != nil {
+		return err
 	}
 
-	for _, match := range matches {
-		log.InfoLog.Printf("cleaning up session: %s", match)
-		if err := cmdExec.Run(exec.Command("tmux", "kill-session", "-t", match)); err != nil {
-			return fmt.Errorf("failed to kill tmux session %s: %v", match, err)
+	for _, name := range sessions {
+		log.InfoLog.Printf("cleaning up session: %s", name)
+		if err := KillSession(cmdExec, name); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -89,3 +89,314 @@ func TestStartTmuxSession(t *testing.T) {
 	_, err = ptyFactory.files[1].Stat()
 	require.NoError(t, err)
 }
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{version: "tmux 3.3a", expected: true},
+		{version: "tmux 2.1", expected: true},
+		{version: "tmux 2.0", expected: false},
+		{version: "tmux 1.9a", expected: false},
+		{version: "tmux next-3.4", expected: true},
+		{version: "tmux master", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			require.Equal(t, tt.expected, versionAtLeast(tt.version, MinVersion))
+		})
+	}
+}
+
+func TestListSessions(t *testing.T) {
+	cmdExec := cmd_test.MockCmdExec{
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			require.Equal(t, "tmux ls -F #{session_name}", cmd2.ToString(cmd))
+			return []byte(TmuxPrefix + "one\nwork\n" + TmuxPrefix + "two\n"), nil
+		},
+	}
+
+	sessions, err := ListSessions(cmdExec)
+	require.NoError(t, err)
+	require.Equal(t, []string{TmuxPrefix + "one", TmuxPrefix + "two"}, sessions)
+}
+
+func TestCheckVersion(t *testing.T) {
+	t.Run("returns the installed version", func(t *testing.T) {
+		cmdExec := cmd_test.MockCmdExec{
+			OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+				require.Equal(t, "tmux -V", cmd2.ToString(cmd))
+				return []byte("tmux 3.4\n"), nil
+			},
+		}
+		version, err := CheckVersion(cmdExec)
+		require.NoError(t, err)
+		require.Equal(t, "tmux 3.4", version)
+	})
+
+	t.Run("reports a missing tmux clearly", func(t *testing.T) {
+		cmdExec := cmd_test.MockCmdExec{
+			OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+				return nil, &exec.Error{Name: "tmux", Err: exec.ErrNotFound}
+			},
+		}
+		_, err := CheckVersion(cmdExec)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "tmux is not installed")
+	})
+
+	t.Run("rejects old versions", func(t *testing.T) {
+		cmdExec := cmd_test.MockCmdExec{
+			OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+				return []byte("tmux 1.8\n"), nil
+			},
+		}
+		_, err := CheckVersion(cmdExec)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "requires tmux "+MinVersion)
+	})
+}
+
+func TestInstallHint(t *testing.T) {
+	require.Contains(t, installHint("darwin"), "brew install tmux")
+	require.Contains(t, installHint("linux"), "apt install tmux")
+	require.Contains(t, installHint("windows"), "WSL")
+	require.NotEmpty(t, installHint("plan9"))
+}
+
+func TestSessionPrefix(t *testing.T) {
+	defer SetSessionPrefix(TmuxPrefix)
+
+	t.Run("sanitizes dots and colons in titles", func(t *testing.T) {
+		session := NewTmuxSession("fix: v1.2 bug", "program")
+		require.Equal(t, TmuxPrefix+"fix_v1_2bug", session.sanitizedName)
+	})
+
+	t.Run("applies a custom prefix", func(t *testing.T) {
+		SetSessionPrefix("cs-")
+		defer SetSessionPrefix(TmuxPrefix)
+
+		session := NewTmuxSession("feature", "program")
+		require.Equal(t, "cs-feature", session.sanitizedName)
+		require.Equal(t, "cs-", SessionPrefix())
+	})
+
+	t.Run("sanitizes the prefix", func(t *testing.T) {
+		SetSessionPrefix("my squad.")
+		defer SetSessionPrefix(TmuxPrefix)
+
+		require.Equal(t, "mysquad_", SessionPrefix())
+	})
+
+	t.Run("empty prefix falls back to the default", func(t *testing.T) {
+		SetSessionPrefix("")
+		require.Equal(t, TmuxPrefix, SessionPrefix())
+	})
+
+	t.Run("only lists sessions with the configured prefix", func(t *testing.T) {
+		SetSessionPrefix("cs-")
+		defer SetSessionPrefix(TmuxPrefix)
+
+		cmdExec := cmd_test.MockCmdExec{
+			OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+				return []byte("cs-one\n" + TmuxPrefix + "two\nwork\n"), nil
+			},
+		}
+		sessions, err := ListSessions(cmdExec)
+		require.NoError(t, err)
+		require.Equal(t, []string{"cs-one"}, sessions)
+	})
+}
+
+func TestLaunchSetsEnv(t *testing.T) {
+	ptyFactory := NewMockPtyFactory(t)
+	checked := false
+	cmdExec := cmd_test.MockCmdExec{
+		RunFunc: func(cmd *exec.Cmd) error {
+			if strings.Contains(cmd.String(), "has-session") && !checked {
+				checked = true
+				return fmt.Errorf("no such session")
+			}
+			return nil
+		},
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			return []byte("output"), nil
+		},
+	}
+
+	session := newTmuxSession("env-session", "claude", ptyFactory, cmdExec)
+	session.SetEnv(map[string]string{"TASK": "don't stop", "API_URL": "http://localhost"})
+	require.NoError(t, session.Launch(t.TempDir()))
+
+	args := ptyFactory.cmds[0].Args
+	require.NotContains(t, args, "-e", "new-session -e needs tmux 3.2")
+	shellCmd := args[len(args)-1]
+	require.Equal(t, `export 'API_URL=http://localhost' 'TASK=don'\''t stop'; exec claude`, shellCmd)
+
+	// The shell sees the variables as they were set.
+	out, err := exec.Command("sh", "-c", strings.Replace(shellCmd, "exec claude", `printf '%s|%s' "$API_URL" "$TASK"`, 1)).Output()
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost|don't stop", string(out))
+}
+
+func TestSetDetachKey(t *testing.T) {
+	defer SetDetachKey(DefaultDetachKey)
+
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+		b       byte
+	}{
+		{key: "", want: DefaultDetachKey, b: 17},
+		{key: "ctrl+q", want: "ctrl+q", b: 17},
+		{key: " Ctrl+A ", want: "ctrl+a", b: 1},
+		{key: "ctrl+]", want: "ctrl+]", b: 29},
+		{key: "ctrl+\\", want: "ctrl+\\", b: 28},
+		{key: "ctrl+c", wantErr: true},
+		{key: "ctrl+m", wantErr: true},
+		{key: "ctrl+1", wantErr: true},
+		{key: "alt+q", wantErr: true},
+		{key: "q", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			require.NoError(t, SetDetachKey("ctrl+x"))
+			err := SetDetachKey(tt.key)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, "ctrl+x", DetachKey(), "an invalid key leaves the detach key unchanged")
+				require.Equal(t, byte(24), detachByte)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, DetachKey())
+			require.Equal(t, tt.b, detachByte)
+		})
+	}
+}
+
+func TestIsolatedServer(t *testing.T) {
+	defer SetIsolatedServer(false)
+
+	var ran []string
+	cmdExec := cmd_test.MockCmdExec{
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			ran = append(ran, strings.Join(cmd.Args, " "))
+			return []byte(TmuxPrefix + "one\n"), nil
+		},
+	}
+
+	_, err := ListSessions(cmdExec)
+	require.NoError(t, err)
+	SetIsolatedServer(true)
+	_, err = ListSessions(cmdExec)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"tmux ls -F #{session_name}",
+		"tmux -L claudesquad -f " + os.DevNull + " ls -F #{session_name}",
+	}, ran)
+
+	session := NewTmuxSession("fix", "claude")
+	require.Equal(t, []string{"tmux", "-L", "claudesquad", "-f", os.DevNull, "attach-session", "-t", "=" + TmuxPrefix + "fix"},
+		session.AttachArgs())
+	require.Equal(t, []string{"tmux", "-L", "claudesquad", "-f", os.DevNull, "attach-session", "-t", "=" + TmuxPrefix + "fix", "-r"},
+		session.ObserveArgs())
+}
+
+func TestObserveArgs(t *testing.T) {
+	session := NewTmuxSession("pair up", "claude")
+	require.Equal(t, []string{"tmux", "attach-session", "-t", "=" + TmuxPrefix + "pairup", "-r"}, session.ObserveArgs())
+	// The name is sanitized like the session's, and the read-only command leaves the attach command alone.
+	require.Equal(t, []string{"tmux", "attach-session", "-t", "=" + TmuxPrefix + "pairup"}, session.AttachArgs())
+}
+
+func TestPromptText(t *testing.T) {
+	pane := "some earlier output\n" +
+		"Bash command\n  npm test\n" +
+		"Do you want to proceed?\n" +
+		"  2. No, and tell Claude what to do differently\n\n\n"
+	cmdExec := cmd_test.MockCmdExec{
+		RunFunc: func(cmd *exec.Cmd) error {
+			return nil
+		},
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			return []byte(pane), nil
+		},
+	}
+	session := newTmuxSession("prompt-text", "claude", NewMockPtyFactory(t), cmdExec)
+	session.monitor = newStatusMonitor()
+
+	_, hasPrompt := session.HasUpdated()
+	require.True(t, hasPrompt)
+	require.Equal(t, strings.TrimRight(pane, "\n"), session.PromptText())
+
+	pane = "plain output\n"
+	_, hasPrompt = session.HasUpdated()
+	require.False(t, hasPrompt)
+	require.Empty(t, session.PromptText())
+}
+
+func TestLastLines(t *testing.T) {
+	require.Equal(t, "c\nd", lastLines("a\nb\nc\nd\n\n", 2))
+	require.Equal(t, "a\nb", lastLines("a\nb", 5))
+}
+
+func TestSendKeyNames(t *testing.T) {
+	var ran []string
+	cmdExec := cmd_test.MockCmdExec{
+		CombinedOutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			ran = append(ran, strings.Join(cmd.Args, " "))
+			return nil, nil
+		},
+	}
+	session := newTmuxSession("interrupt-me", "claude", &MockPtyFactory{t: t}, cmdExec)
+
+	require.NoError(t, session.SendKeyNames("C-c"))
+	require.NoError(t, session.SendKeyNames("Escape", "Escape"))
+	require.NoError(t, session.SendKeyNames())
+
+	require.Equal(t, []string{
+		"tmux send-keys -t " + TmuxPrefix + "interrupt-me C-c",
+		"tmux send-keys -t " + TmuxPrefix + "interrupt-me Escape Escape",
+	}, ran)
+}
+
+func TestParseExitStatus(t *testing.T) {
+	tests := []struct {
+		output string
+		want   ExitStatus
+	}{
+		{"0 \n", ExitStatus{}},
+		{"1 0\n", ExitStatus{Exited: true, Code: 0}},
+		{"1 2\n", ExitStatus{Exited: true, Code: 2}},
+		// Killed by a signal, so there is no exit code.
+		{"1 \n", ExitStatus{Exited: true, Code: -1}},
+	}
+	for _, tt := range tests {
+		got, err := parseExitStatus(tt.output)
+		require.NoError(t, err, tt.output)
+		require.Equal(t, tt.want, got, tt.output)
+	}
+
+	for _, output := range []string{"", "yes", "1 x", "1 0 0"} {
+		_, err := parseExitStatus(output)
+		require.Error(t, err, output)
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	cmdExec := cmd_test.MockCmdExec{
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			require.Equal(t, "tmux display-message -p -t claudesquad_fix #{pane_dead} #{pane_dead_status}",
+				cmd2.ToString(cmd))
+			return []byte("1 0\n"), nil
+		},
+	}
+	status, err := newTmuxSession("fix", "claude", NewMockPtyFactory(t), cmdExec).ExitStatus()
+	require.NoError(t, err)
+	require.Equal(t, ExitStatus{Exited: true, Code: 0}, status)
+}
@@ -0,0 +1,78 @@
+package tmux
+
+import (
+	"claude-squad/cmd"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// MinVersion is the oldest tmux release claude-squad supports. The "mouse" session option, which we enable
+// for scrolling, was introduced in tmux 2.1.
+const MinVersion = "2.1"
+
+var versionRegex = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+// Version returns the tmux version string as reported by `tmux -V` (ex. "tmux 3.3a").
+func Version(cmdExec cmd.Executor) (string, error) {
+	output, err := cmdExec.Output(exec.Command("tmux", "-V"))
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("tmux is not installed or not on your PATH: %w", err)
+		}
+		return "", fmt.Errorf("failed to run tmux -V: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CheckVersion verifies that tmux is installed and at least MinVersion. It returns the installed version.
+func CheckVersion(cmdExec cmd.Executor) (string, error) {
+	version, err := Version(cmdExec)
+	if err != nil {
+		return "", err
+	}
+	if !versionAtLeast(version, MinVersion) {
+		return version, fmt.Errorf("%s is too old, claude-squad requires tmux %s or newer", version, MinVersion)
+	}
+	return version, nil
+}
+
+// versionAtLeast reports whether the major.minor version in version is at least min. Versions that can't
+// be parsed (ex. "tmux master" builds) are assumed to be recent enough.
+func versionAtLeast(version, min string) bool {
+	got := versionRegex.FindStringSubmatch(version)
+	want := versionRegex.FindStringSubmatch(min)
+	if got == nil || want == nil {
+		return true
+	}
+	gotMajor, _ := strconv.Atoi(got[1])
+	gotMinor, _ := strconv.Atoi(got[2])
+	wantMajor, _ := strconv.Atoi(want[1])
+	wantMinor, _ := strconv.Atoi(want[2])
+	if gotMajor != wantMajor {
+		return gotMajor > wantMajor
+	}
+	return gotMinor >= wantMinor
+}
+
+// InstallHint returns instructions for installing tmux on the current operating system.
+func InstallHint() string {
+	return installHint(runtime.GOOS)
+}
+
+func installHint(goos string) string {
+	switch goos {
+	case "darwin":
+		return "Install tmux with Homebrew: brew install tmux"
+	case "linux":
+		return "Install tmux with your package manager, e.g. sudo apt install tmux, sudo dnf install tmux or sudo pacman -S tmux"
+	case "windows":
+		return "tmux is not available natively on Windows. Run claude-squad inside WSL and install tmux there: sudo apt install tmux"
+	default:
+		return "Install tmux from your package manager or see https://github.com/tmux/tmux/wiki/Installing"
+	}
+}
@@ -0,0 +1,34 @@
+package session
+
+import "claude-squad/session/tmux"
+
+// TmuxSessionEntry names the tmux session of an instance, for switching to it with tmux's own key bindings.
+type TmuxSessionEntry struct {
+	Title string
+	// Name is the name of the instance's tmux session. Only instances that are running have one.
+	Name string
+	// Status is the name of the instance's status, like "running", as in a StatusSummary.
+	Status string
+	// Open is whether the tmux session exists. It doesn't for instances that haven't started yet, are paused or are
+	// archived.
+	Open bool
+}
+
+// TmuxSessions lists the tmux sessions of instances in order. An instance that hasn't started yet is listed with the
+// name its session will get.
+func TmuxSessions(instances []*Instance) []TmuxSessionEntry {
+	entries := make([]TmuxSessionEntry, 0, len(instances))
+	for _, instance := range instances {
+		name := instance.TmuxSessionName()
+		if name == "" {
+			name = tmux.SessionName(instance.Title)
+		}
+		entries = append(entries, TmuxSessionEntry{
+			Title:  instance.Title,
+			Name:   name,
+			Status: statusNames[instance.Status],
+			Open:   instance.Started() && !instance.Paused() && !instance.Archived(),
+		})
+	}
+	return entries
+}
@@ -0,0 +1,32 @@
+package session
+
+import (
+	"claude-squad/session/tmux"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTmuxSessions(t *testing.T) {
+	instances := []*Instance{
+		{Title: "fix login", Status: Running, started: true, tmuxSession: tmux.NewTmuxSession("fix login", "claude")},
+		{Title: "old", Status: Paused, started: true, tmuxSession: tmux.NewTmuxSession("old", "claude")},
+		// Not started yet, so it has no tmux session of its own.
+		{Title: "docs.v2", Status: Loading},
+	}
+
+	assert.Equal(t, []TmuxSessionEntry{
+		{Title: "fix login", Name: tmux.TmuxPrefix + "fixlogin", Status: "running", Open: true},
+		{Title: "old", Name: tmux.TmuxPrefix + "old", Status: "paused"},
+		{Title: "docs.v2", Name: tmux.TmuxPrefix + "docs_v2", Status: "loading"},
+	}, TmuxSessions(instances))
+	assert.Empty(t, TmuxSessions(nil))
+
+	t.Run("uses the configured prefix", func(t *testing.T) {
+		tmux.SetSessionPrefix("team_")
+		t.Cleanup(func() { tmux.SetSessionPrefix("") })
+
+		assert.Equal(t, []TmuxSessionEntry{{Title: "docs.v2", Name: "team_docs_v2", Status: "loading"}},
+			TmuxSessions(instances[2:]))
+	})
+}
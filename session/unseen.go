@@ -0,0 +1,24 @@
+package session
+
+// TrackUnseen updates whether the instance has output the user hasn't seen, after a check of its pane found the
+// output updated or not. Output is seen while the instance is selected. The first check after the program was
+// loaded, resumed or restored only takes in the pane, since there is nothing before it to compare with, so it must
+// be tracked before the check is recorded with MarkActive.
+func (i *Instance) TrackUnseen(updated, selected bool) {
+	switch {
+	case selected:
+		i.unseenOutput = false
+	case updated && !i.lastActivity.IsZero():
+		i.unseenOutput = true
+	}
+}
+
+// MarkSeen records that the user has seen the instance's output, because it was selected.
+func (i *Instance) MarkSeen() {
+	i.unseenOutput = false
+}
+
+// HasUnseenOutput returns true if the instance's output changed since the user last selected it.
+func (i *Instance) HasUnseenOutput() bool {
+	return i.unseenOutput
+}
@@ -0,0 +1,38 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackUnseen(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	instance := &Instance{Title: "task", Status: Running, started: true}
+
+	// The first check only takes in the pane.
+	instance.TrackUnseen(true, false)
+	assert.False(t, instance.HasUnseenOutput())
+	instance.MarkActive(now)
+
+	instance.TrackUnseen(false, false)
+	assert.False(t, instance.HasUnseenOutput(), "unchanged output is nothing new")
+
+	instance.TrackUnseen(true, false)
+	assert.True(t, instance.HasUnseenOutput())
+
+	instance.TrackUnseen(false, false)
+	assert.True(t, instance.HasUnseenOutput(), "output stays unseen until the instance is selected")
+
+	instance.TrackUnseen(false, true)
+	assert.False(t, instance.HasUnseenOutput(), "selecting the instance sees its output")
+
+	instance.TrackUnseen(true, true)
+	assert.False(t, instance.HasUnseenOutput(), "output of the selected instance is seen as it is printed")
+
+	instance.TrackUnseen(true, false)
+	assert.True(t, instance.HasUnseenOutput())
+	instance.MarkSeen()
+	assert.False(t, instance.HasUnseenOutput())
+}
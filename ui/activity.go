@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"claude-squad/session"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// activityRefreshInterval is how often the activity tab rereads the selected instance's activity log. Events are only
+// appended on lifecycle changes, so this doesn't need to keep up with the preview.
+const activityRefreshInterval = time.Second
+
+// FormatEvents renders activity events as one line each, oldest first. Multi-line details such as prompts are
+// flattened onto their line.
+func FormatEvents(events []session.Event) []string {
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		line := fmt.Sprintf("%s  %-9s %s", event.Time.Local().Format("2006-01-02 15:04:05"), event.Kind,
+			strings.Join(strings.Fields(event.Detail), " "))
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return lines
+}
+
+// ActivityPane shows the note and the activity log of the selected instance.
+type ActivityPane struct {
+	viewport viewport.Model
+	width    int
+	height   int
+
+	instance *session.Instance
+	// note is the instance's note, shown above its events.
+	note  string
+	lines []string
+	// loadedAt is when the lines were last read from the instance's activity log.
+	loadedAt time.Time
+}
+
+func NewActivityPane() *ActivityPane {
+	return &ActivityPane{
+		viewport: viewport.New(0, 0),
+	}
+}
+
+func (a *ActivityPane) SetSize(width, height int) {
+	a.width = width
+	a.height = height
+	a.viewport.Width = width
+	a.viewport.Height = height
+	a.setContent()
+}
+
+// SetInstance shows the note and activity log of instance. The log is reread at most once per
+// activityRefreshInterval unless the instance changed. instance may be nil.
+func (a *ActivityPane) SetInstance(instance *session.Instance) error {
+	changed := instance != a.instance
+	note := ""
+	if instance != nil {
+		note = instance.Note
+	}
+	if note != a.note {
+		a.note = note
+		a.setContent()
+	}
+	if !changed && time.Since(a.loadedAt) < activityRefreshInterval {
+		return nil
+	}
+	a.instance = instance
+	a.loadedAt = time.Now()
+
+	lines, err := readActivityLines(instance)
+	a.lines = lines
+	a.setContent()
+	if changed {
+		a.viewport.GotoBottom()
+	}
+	return err
+}
+
+// readActivityLines returns the formatted activity log of instance. instance may be nil.
+func readActivityLines(instance *session.Instance) ([]string, error) {
+	if instance == nil {
+		return nil, nil
+	}
+	activity, err := instance.ActivityLog()
+	if err != nil {
+		return nil, err
+	}
+	events, err := activity.Events()
+	if err != nil {
+		return nil, err
+	}
+	return FormatEvents(events), nil
+}
+
+// setContent wraps the note and lines to the pane width. The pane keeps following the newest event unless it was
+// scrolled up.
+func (a *ActivityPane) setContent() {
+	var note string
+	if a.note != "" {
+		note = lipgloss.NewStyle().Width(a.width).Render("Note: "+a.note) + "\n\n"
+	}
+	if len(a.lines) == 0 {
+		if note != "" {
+			a.viewport.SetContent(note + "No activity recorded yet")
+		} else {
+			a.viewport.SetContent(lipgloss.Place(a.width, a.height, lipgloss.Center, lipgloss.Center,
+				"No activity recorded yet"))
+		}
+		a.viewport.GotoTop()
+		return
+	}
+	following := a.viewport.AtBottom() || a.viewport.TotalLineCount() == 0
+	a.viewport.SetContent(note + lipgloss.NewStyle().Width(a.width).Render(strings.Join(a.lines, "\n")))
+	if following {
+		a.viewport.GotoBottom()
+	}
+}
+
+// ScrollUp scrolls towards older events.
+func (a *ActivityPane) ScrollUp() {
+	a.viewport.LineUp(1)
+}
+
+// ScrollDown scrolls towards newer events.
+func (a *ActivityPane) ScrollDown() {
+	a.viewport.LineDown(1)
+}
+
+func (a *ActivityPane) String() string {
+	return a.viewport.View()
+}
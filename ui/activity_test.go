@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"claude-squad/session"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatEvents(t *testing.T) {
+	at := time.Date(2026, 3, 4, 5, 6, 7, 0, time.Local)
+	lines := FormatEvents([]session.Event{
+		{Time: at, Kind: session.EventCreated, Detail: "user/feature"},
+		{Time: at, Kind: session.EventPrompt, Detail: "fix the tests\n  then push"},
+		{Time: at, Kind: session.EventPaused},
+	})
+
+	assert.Equal(t, []string{
+		"2026-03-04 05:06:07  created   user/feature",
+		"2026-03-04 05:06:07  prompt    fix the tests then push",
+		"2026-03-04 05:06:07  paused",
+	}, lines)
+}
+
+func TestTabbedWindowCycling(t *testing.T) {
+	w := NewTabbedWindow(NewPreviewPane(), NewDiffPane())
+	require.Equal(t, PreviewTab, w.ActiveTab())
+
+	t.Run("next wraps around to the first tab", func(t *testing.T) {
+		w.NextTab()
+		assert.Equal(t, DiffTab, w.ActiveTab())
+		w.NextTab()
+		assert.Equal(t, ActivityTab, w.ActiveTab())
+		w.NextTab()
+		assert.Equal(t, LogsTab, w.ActiveTab())
+		w.NextTab()
+		assert.Equal(t, PreviewTab, w.ActiveTab())
+	})
+
+	t.Run("prev wraps around to the last tab", func(t *testing.T) {
+		w.PrevTab()
+		assert.Equal(t, LogsTab, w.ActiveTab())
+		w.PrevTab()
+		assert.Equal(t, ActivityTab, w.ActiveTab())
+	})
+
+	t.Run("toggle moves to the next tab", func(t *testing.T) {
+		w.Toggle()
+		assert.Equal(t, LogsTab, w.ActiveTab())
+	})
+
+	t.Run("direct selection ignores tabs that don't exist", func(t *testing.T) {
+		assert.True(t, w.SetActiveTab(DiffTab))
+		assert.Equal(t, DiffTab, w.ActiveTab())
+		assert.False(t, w.SetActiveTab(-1))
+		assert.False(t, w.SetActiveTab(LogsTab+1))
+		assert.Equal(t, DiffTab, w.ActiveTab())
+	})
+}
+
+func TestActivityTab(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	instance := &session.Instance{Title: "activity-test"}
+	activity, err := instance.ActivityLog()
+	require.NoError(t, err)
+	require.NoError(t, activity.Append(session.EventCreated, "user/activity-test"))
+	require.NoError(t, activity.Append(session.EventPrompt, "write the docs"))
+
+	w := NewTabbedWindow(NewPreviewPane(), NewDiffPane())
+	w.SetSize(100, 20)
+
+	// The activity log is only read while the activity tab is shown.
+	require.NoError(t, w.UpdateActivity(instance))
+	assert.NotContains(t, ansi.Strip(w.String()), "write the docs")
+
+	require.True(t, w.SetActiveTab(ActivityTab))
+	require.NoError(t, w.UpdateActivity(instance))
+	out := ansi.Strip(w.String())
+	assert.Contains(t, out, "created   user/activity-test")
+	assert.Contains(t, out, "prompt    write the docs")
+
+	// The note is shown above the events.
+	instance.Note = "docs for the new API"
+	require.NoError(t, w.UpdateActivity(instance))
+	out = ansi.Strip(w.String())
+	assert.Less(t, strings.Index(out, "Note: docs for the new API"), strings.Index(out, "created"))
+
+	require.NoError(t, w.UpdateActivity(nil))
+	assert.Contains(t, ansi.Strip(w.String()), "No activity recorded yet")
+	assert.NotContains(t, ansi.Strip(w.String()), "Note:")
+}
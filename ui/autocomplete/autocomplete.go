@@ -1,11 +1,15 @@
 package autocomplete
 
+import "time"
+
 // Suggestion represents an autocomplete suggestion
 type Suggestion struct {
 	// Value is the full value to insert (e.g., "/0-fix-issue")
 	Value string
 	// Display is the text shown in the dropdown (e.g., "0-fix-issue")
 	Display string
+	// Path is the file the suggestion comes from, like ".claude/commands/0-fix-issue.md". Empty if it has none.
+	Path string
 }
 
 // Autocompleter provides autocomplete suggestions
@@ -15,3 +19,10 @@ type Autocompleter interface {
 	// Reload refreshes the available suggestions from disk
 	Reload() error
 }
+
+// UsageStats reports how often and how recently the value of a suggestion was used, so the most used suggestions
+// can be offered first.
+type UsageStats interface {
+	// Usage returns how often value was used and when it was last used. Both are zero if it wasn't used.
+	Usage(value string) (count int, lastUsed time.Time)
+}
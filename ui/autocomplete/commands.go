@@ -1,32 +1,56 @@
 package autocomplete
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 )
 
-// ClaudeCommandsAutocompleter scans .claude/commands/ for available commands
+// globalTag marks the commands that come from the global commands directory rather than the repo's.
+const globalTag = " (global)"
+
+// ClaudeCommandsAutocompleter scans .claude/commands/ in the repo and in the home directory for available commands
 type ClaudeCommandsAutocompleter struct {
 	basePath string
-	commands []Suggestion
+	// globalPath is the directory whose .claude/commands/ are offered in every repo, the home directory. Empty
+	// means only the repo's commands are offered.
+	globalPath string
+	commands   []Suggestion
+	// usage ranks the commands. Nil keeps the order they were found in.
+	usage UsageStats
 }
 
 // NewClaudeCommandsAutocompleter creates a new autocompleter that scans
-// the .claude/commands/ directory in the given base path for .md files.
+// the .claude/commands/ directory in the given base path and in the home directory for .md files.
 func NewClaudeCommandsAutocompleter(basePath string) *ClaudeCommandsAutocompleter {
+	globalPath, err := os.UserHomeDir()
+	if err != nil {
+		globalPath = ""
+	}
 	a := &ClaudeCommandsAutocompleter{
-		basePath: basePath,
-		commands: make([]Suggestion, 0),
+		basePath:   basePath,
+		globalPath: globalPath,
+		commands:   make([]Suggestion, 0),
 	}
 	_ = a.Reload() // Ignore errors, just start with empty commands
 	return a
 }
 
-// GetSuggestions returns suggestions that match the given prefix (case-insensitive).
+// SetUsage ranks the suggestions by how often and how recently they were used. Nil keeps the order they were found
+// in.
+func (a *ClaudeCommandsAutocompleter) SetUsage(usage UsageStats) {
+	a.usage = usage
+}
+
+// GetSuggestions returns suggestions that match the given prefix (case-insensitive). The most used commands come
+// first, the most recently used first among equally used ones. Unused commands follow in the order they were found
+// in, which is alphabetical: the repo's and then the global ones.
 func (a *ClaudeCommandsAutocompleter) GetSuggestions(prefix string) []Suggestion {
 	if len(prefix) == 0 {
-		return a.commands
+		return rankSuggestions(a.commands, a.usage)
 	}
 
 	lowerPrefix := strings.ToLower(prefix)
@@ -36,24 +60,78 @@ func (a *ClaudeCommandsAutocompleter) GetSuggestions(prefix string) []Suggestion
 			matches = append(matches, cmd)
 		}
 	}
-	return matches
+	return rankSuggestions(matches, a.usage)
+}
+
+// rankSuggestions returns suggestions ordered by usage, most used first and then most recently used first. Equally
+// used suggestions keep their order. suggestions itself is not changed.
+func rankSuggestions(suggestions []Suggestion, usage UsageStats) []Suggestion {
+	if usage == nil || len(suggestions) < 2 {
+		return suggestions
+	}
+	type ranked struct {
+		suggestion Suggestion
+		count      int
+		lastUsed   time.Time
+	}
+	rankedSuggestions := make([]ranked, len(suggestions))
+	for i, suggestion := range suggestions {
+		count, lastUsed := usage.Usage(suggestion.Value)
+		rankedSuggestions[i] = ranked{suggestion: suggestion, count: count, lastUsed: lastUsed}
+	}
+	slices.SortStableFunc(rankedSuggestions, func(a, b ranked) int {
+		if a.count != b.count {
+			return b.count - a.count
+		}
+		return b.lastUsed.Compare(a.lastUsed)
+	})
+
+	sorted := make([]Suggestion, len(rankedSuggestions))
+	for i, r := range rankedSuggestions {
+		sorted[i] = r.suggestion
+	}
+	return sorted
 }
 
-// Reload scans the .claude/commands/ directory and refreshes the command list.
+// Reload scans the .claude/commands/ directories and refreshes the command list. The repo's commands come first,
+// then the global ones, which are marked in their Display. A repo command hides a global command with the same name.
 func (a *ClaudeCommandsAutocompleter) Reload() error {
-	commandsDir := filepath.Join(a.basePath, ".claude", "commands")
+	commands, repoErr := scanCommands(a.basePath)
+	var globalErr error
+	if a.globalPath != "" {
+		var global []Suggestion
+		global, globalErr = scanCommands(a.globalPath)
+		repoValues := make(map[string]bool, len(commands))
+		for _, cmd := range commands {
+			repoValues[cmd.Value] = true
+		}
+		for _, cmd := range global {
+			if repoValues[cmd.Value] {
+				continue
+			}
+			cmd.Display += globalTag
+			commands = append(commands, cmd)
+		}
+	}
+	a.commands = commands
+	return errors.Join(repoErr, globalErr)
+}
+
+// scanCommands returns the commands in the .claude/commands/ directory of basePath. A missing directory has no
+// commands.
+func scanCommands(basePath string) ([]Suggestion, error) {
+	commandsDir := filepath.Join(basePath, ".claude", "commands")
 
 	entries, err := os.ReadDir(commandsDir)
 	if err != nil {
-		// If directory doesn't exist, just clear commands (not an error)
-		a.commands = make([]Suggestion, 0)
+		// If directory doesn't exist, there are no commands (not an error)
 		if os.IsNotExist(err) {
-			return nil
+			return make([]Suggestion, 0), nil
 		}
-		return err
+		return make([]Suggestion, 0), err
 	}
 
-	a.commands = make([]Suggestion, 0)
+	commands := make([]Suggestion, 0)
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -67,11 +145,12 @@ func (a *ClaudeCommandsAutocompleter) Reload() error {
 
 		// Remove .md extension to get command name
 		cmdName := strings.TrimSuffix(name, ".md")
-		a.commands = append(a.commands, Suggestion{
+		commands = append(commands, Suggestion{
 			Value:   "/" + cmdName,
 			Display: cmdName,
+			Path:    filepath.Join(commandsDir, name),
 		})
 	}
 
-	return nil
+	return commands, nil
 }
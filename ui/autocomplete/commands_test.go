@@ -4,12 +4,16 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestClaudeCommandsAutocompleter(t *testing.T) {
+	// Keep the global commands of whoever runs the tests out.
+	t.Setenv("HOME", t.TempDir())
+
 	t.Run("returns empty suggestions when directory doesn't exist", func(t *testing.T) {
 		tempDir := t.TempDir()
 
@@ -174,3 +178,154 @@ func TestClaudeCommandsAutocompleter(t *testing.T) {
 		assert.Len(t, ac.GetSuggestions(""), 2)
 	})
 }
+
+// writeCommands creates a .claude/commands/ directory in dir with an empty command file for each name.
+func writeCommands(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	commandsDir := filepath.Join(dir, ".claude", "commands")
+	require.NoError(t, os.MkdirAll(commandsDir, 0755))
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(commandsDir, name+".md"), []byte(""), 0644))
+	}
+}
+
+// commandPath returns the file of the named command written by writeCommands in dir.
+func commandPath(dir, name string) string {
+	return filepath.Join(dir, ".claude", "commands", name+".md")
+}
+
+func TestGlobalCommands(t *testing.T) {
+	t.Run("merges repo and global commands, repo first", func(t *testing.T) {
+		home := t.TempDir()
+		repo := t.TempDir()
+		t.Setenv("HOME", home)
+		writeCommands(t, home, "standup", "commit")
+		writeCommands(t, repo, "commit", "deploy")
+
+		ac := NewClaudeCommandsAutocompleter(repo)
+
+		assert.Equal(t, []Suggestion{
+			{Value: "/commit", Display: "commit", Path: commandPath(repo, "commit")},
+			{Value: "/deploy", Display: "deploy", Path: commandPath(repo, "deploy")},
+			{Value: "/standup", Display: "standup (global)", Path: commandPath(home, "standup")},
+		}, ac.GetSuggestions(""))
+		assert.Equal(t, []Suggestion{{Value: "/commit", Display: "commit", Path: commandPath(repo, "commit")}},
+			ac.GetSuggestions("/com"),
+			"the repo command hides the global one with the same name")
+	})
+
+	t.Run("only global commands", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		writeCommands(t, home, "standup")
+
+		ac := NewClaudeCommandsAutocompleter(t.TempDir())
+
+		assert.Equal(t, []Suggestion{{Value: "/standup", Display: "standup (global)", Path: commandPath(home, "standup")}},
+			ac.GetSuggestions("/st"))
+	})
+
+	t.Run("a repo in the home directory doesn't list its commands twice", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		writeCommands(t, home, "commit", "review")
+
+		ac := NewClaudeCommandsAutocompleter(home)
+
+		assert.Equal(t, []Suggestion{
+			{Value: "/commit", Display: "commit", Path: commandPath(home, "commit")},
+			{Value: "/review", Display: "review", Path: commandPath(home, "review")},
+		}, ac.GetSuggestions(""))
+	})
+
+	t.Run("Reload picks up new global commands", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		ac := NewClaudeCommandsAutocompleter(t.TempDir())
+		assert.Empty(t, ac.GetSuggestions(""))
+
+		writeCommands(t, home, "standup")
+		require.NoError(t, ac.Reload())
+		assert.Len(t, ac.GetSuggestions(""), 1)
+	})
+}
+
+// fakeUsage maps command values to their use count and the minute they were last used.
+type fakeUsage map[string][2]int
+
+func (f fakeUsage) Usage(value string) (int, time.Time) {
+	usage, ok := f[value]
+	if !ok {
+		return 0, time.Time{}
+	}
+	return usage[0], time.Date(2025, 1, 1, 0, usage[1], 0, 0, time.UTC)
+}
+
+func TestRankSuggestions(t *testing.T) {
+	suggestions := func(values ...string) []Suggestion {
+		var s []Suggestion
+		for _, v := range values {
+			s = append(s, Suggestion{Value: v, Display: v[1:]})
+		}
+		return s
+	}
+
+	tests := []struct {
+		name  string
+		usage UsageStats
+		want  []Suggestion
+	}{
+		{
+			name: "no usage keeps the order",
+			want: suggestions("/a", "/b", "/c", "/d"),
+		},
+		{
+			name:  "unused commands keep the order",
+			usage: fakeUsage{},
+			want:  suggestions("/a", "/b", "/c", "/d"),
+		},
+		{
+			name:  "most used first",
+			usage: fakeUsage{"/c": {5, 0}, "/d": {2, 0}},
+			want:  suggestions("/c", "/d", "/a", "/b"),
+		},
+		{
+			name:  "most recently used first when used as often",
+			usage: fakeUsage{"/b": {2, 1}, "/c": {2, 9}, "/d": {2, 5}},
+			want:  suggestions("/c", "/d", "/b", "/a"),
+		},
+		{
+			name:  "frequency before recency",
+			usage: fakeUsage{"/a": {1, 30}, "/d": {3, 1}},
+			want:  suggestions("/d", "/a", "/b", "/c"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := suggestions("/a", "/b", "/c", "/d")
+			assert.Equal(t, tt.want, rankSuggestions(in, tt.usage))
+			assert.Equal(t, suggestions("/a", "/b", "/c", "/d"), in, "the given suggestions are not changed")
+		})
+	}
+}
+
+func TestGetSuggestionsRanked(t *testing.T) {
+	home := t.TempDir()
+	repo := t.TempDir()
+	t.Setenv("HOME", home)
+	writeCommands(t, repo, "commit", "compact", "review")
+	writeCommands(t, home, "standup")
+
+	ac := NewClaudeCommandsAutocompleter(repo)
+	ac.SetUsage(fakeUsage{"/review": {1, 0}, "/standup": {4, 0}, "/compact": {1, 3}})
+
+	values := func(suggestions []Suggestion) []string {
+		var v []string
+		for _, s := range suggestions {
+			v = append(v, s.Value)
+		}
+		return v
+	}
+	assert.Equal(t, []string{"/standup", "/compact", "/review", "/commit"}, values(ac.GetSuggestions("")))
+	assert.Equal(t, []string{"/compact", "/commit"}, values(ac.GetSuggestions("/CO")), "the prefix still filters")
+}
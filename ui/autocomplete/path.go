@@ -0,0 +1,57 @@
+package autocomplete
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CompletePath completes the last element of a directory path like a shell does: to the only directory it is the
+// start of, followed by a slash, or to the longest start that all such directories share. Hidden directories are
+// only offered once the element starts with a dot. home replaces a leading ~ when reading the directory, but the
+// completed path keeps the ~. The value is returned unchanged if nothing completes it.
+func CompletePath(value, home string) string {
+	dir, prefix := filepath.Split(value)
+	readDir := dir
+	if home != "" && (dir == "~/" || strings.HasPrefix(dir, "~/")) {
+		readDir = filepath.Join(home, dir[1:])
+	}
+	if readDir == "" {
+		readDir = "."
+	}
+
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return value
+	}
+	var matches []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		if entry.IsDir() || isDirLink(filepath.Join(readDir, name)) {
+			matches = append(matches, name)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return value
+	case 1:
+		return dir + matches[0] + string(filepath.Separator)
+	}
+	common := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(match, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	return dir + common
+}
+
+// isDirLink returns whether path is a symlink to a directory.
+func isDirLink(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
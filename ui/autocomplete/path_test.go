@@ -0,0 +1,41 @@
+package autocomplete
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletePath(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"projects/claude-squad", "projects/claude-tools", "projects/web", ".config"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(root, "projects", "web.txt"), []byte("x"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(root, "projects", "web"), filepath.Join(root, "projects", "site")))
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "the only match", value: root + "/proj", want: root + "/projects/"},
+		{name: "shared start of several matches", value: root + "/projects/cl", want: root + "/projects/claude-"},
+		{name: "no further shared start", value: root + "/projects/claude-", want: root + "/projects/claude-"},
+		{name: "files are skipped", value: root + "/projects/we", want: root + "/projects/web/"},
+		{name: "links to directories", value: root + "/projects/si", want: root + "/projects/site/"},
+		{name: "no match", value: root + "/projects/x", want: root + "/projects/x"},
+		{name: "missing directory", value: root + "/missing/x", want: root + "/missing/x"},
+		{name: "hidden directories need a dot", value: root + "/", want: root + "/projects/"},
+		{name: "hidden directory", value: root + "/.c", want: root + "/.config/"},
+		{name: "home directory", value: "~/projects/w", want: "~/projects/web/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CompletePath(tt.value, root))
+		})
+	}
+}
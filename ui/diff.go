@@ -2,25 +2,93 @@ package ui
 
 import (
 	"claude-squad/session"
+	"claude-squad/session/git"
 	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 var (
 	AdditionStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#22c55e"))
 	DeletionStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ef4444"))
 	HunkStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#0ea5e9"))
+	FileListStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#808080"))
 )
 
+// diffNote describes the options an instance's diff is computed with, like "(vs main, ignoring whitespace)".
+// It is empty for a default diff whose base branch isn't known.
+func diffNote(instance *session.Instance) string {
+	if instance == nil {
+		return ""
+	}
+	opts := instance.DiffOptions()
+	var notes []string
+	if opts.Mode == git.DiffUncommitted {
+		notes = append(notes, "uncommitted")
+	} else if worktree, err := instance.GetGitWorktree(); err == nil && worktree.GetBaseBranch() != "" {
+		notes = append(notes, "vs "+worktree.GetBaseBranch())
+	}
+	if opts.IgnoreWhitespace {
+		notes = append(notes, "ignoring whitespace")
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(notes, ", ") + ")"
+}
+
+// conflictSummary lists the files of an instance's branch that conflict with its base branch, or returns "" if
+// there are none.
+func conflictSummary(instance *session.Instance) string {
+	conflicts := instance.Conflicts()
+	if len(conflicts) == 0 {
+		return ""
+	}
+	base := "the base branch"
+	if worktree, err := instance.GetGitWorktree(); err == nil && worktree.GetBaseBranch() != "" {
+		base = worktree.GetBaseBranch()
+	}
+	noun := "files conflict"
+	if len(conflicts) == 1 {
+		noun = "file conflicts"
+	}
+	lines := []string{DeletionStyle.Render(fmt.Sprintf("⚠ %d %s with %s", len(conflicts), noun, base))}
+	for _, path := range conflicts {
+		lines = append(lines, "  "+path)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// maxDiffLines is the number of diff lines rendered before the rest of the diff is cut off to keep
+// the UI responsive.
+const maxDiffLines = 2000
+
 type DiffPane struct {
 	viewport viewport.Model
-	diff     string
-	stats    string
-	width    int
-	height   int
+	// rawDiff is the uncolored diff that diff was rendered from.
+	rawDiff string
+	diff    string
+	stats   string
+	// conflicts lists the files that conflict with the base branch, or is empty if there are none.
+	conflicts string
+	width     int
+	height    int
+
+	// files holds the per-file stats of the current diff.
+	files []git.FileStat
+	// showFiles is true if the per-file summary is expanded.
+	showFiles bool
+	// selectedFile is the index in files of the file that was last jumped to.
+	selectedFile int
+	// xOffset is the number of columns the diff is scrolled to the right.
+	xOffset int
+	// hunks are the lines of the viewport content where hunk headers (@@ ... @@) start, in order.
+	hunks []int
+	// order is the order the files are shown in, in both the summary and the diff.
+	order git.FileOrder
 }
 
 func NewDiffPane() *DiffPane {
@@ -36,23 +104,30 @@ func (d *DiffPane) SetSize(width, height int) {
 	d.viewport.Height = height
 	// Update viewport content if diff exists
 	if d.diff != "" || d.stats != "" {
-		d.viewport.SetContent(lipgloss.JoinVertical(lipgloss.Left, d.stats, d.diff))
+		d.setContent(d.content())
 	}
 }
 
 func (d *DiffPane) SetDiff(instance *session.Instance) {
+	noChanges := "No changes"
+	if note := diffNote(instance); note != "" {
+		noChanges += " " + note
+	}
 	centeredFallbackMessage := lipgloss.Place(
 		d.width,
 		d.height,
 		lipgloss.Center,
 		lipgloss.Center,
-		"No changes",
+		noChanges,
 	)
 
+	// Only the content set by setContent has hunks to jump to.
+	d.hunks = nil
 	if instance == nil || !instance.Started() {
 		d.viewport.SetContent(centeredFallbackMessage)
 		return
 	}
+	d.conflicts = conflictSummary(instance)
 
 	stats := instance.GetDiffStats()
 	if stats == nil {
@@ -83,14 +158,216 @@ func (d *DiffPane) SetDiff(instance *session.Instance) {
 
 	if stats.IsEmpty() {
 		d.stats = ""
+		d.rawDiff = ""
 		d.diff = ""
+		d.files = nil
+		if d.conflicts != "" {
+			// Committed work can still conflict when the worktree has nothing to show, e.g. in the uncommitted mode.
+			centeredFallbackMessage = lipgloss.Place(d.width, d.height, lipgloss.Center, lipgloss.Center,
+				lipgloss.JoinVertical(lipgloss.Center, noChanges, "", d.conflicts))
+		}
 		d.viewport.SetContent(centeredFallbackMessage)
 	} else {
 		additions := AdditionStyle.Render(fmt.Sprintf("%d additions(+)", stats.Added))
 		deletions := DeletionStyle.Render(fmt.Sprintf("%d deletions(-)", stats.Removed))
 		d.stats = lipgloss.JoinHorizontal(lipgloss.Center, additions, " ", deletions)
-		d.diff = colorizeDiff(stats.Content)
-		d.viewport.SetContent(lipgloss.JoinVertical(lipgloss.Left, d.stats, d.diff))
+		if note := diffNote(instance); note != "" {
+			d.stats = lipgloss.JoinHorizontal(lipgloss.Center, d.stats, " ", FileListStyle.Render(note))
+		}
+		// Only re-render the diff when it changed, since large diffs are expensive to colorize.
+		if stats.Content != d.rawDiff {
+			d.rawDiff = stats.Content
+			d.diff = renderDiff(d.order.SortDiff(stats.Content), maxDiffLines)
+		}
+		d.files = d.order.SortFiles(stats.Files)
+		if d.selectedFile >= len(d.files) {
+			d.selectedFile = 0
+		}
+		d.setContent(d.content())
+	}
+}
+
+// SetFileOrder sets the order the files are shown in. It applies from the next SetDiff.
+func (d *DiffPane) SetFileOrder(order git.FileOrder) {
+	d.order = order
+	// Render the diff again in the new order.
+	d.rawDiff = ""
+}
+
+// setContent sets the viewport content, cutting each line to the visible columns so long lines can be
+// scrolled horizontally instead of wrapping.
+func (d *DiffPane) setContent(content string) {
+	d.hunks = hunkOffsets(content)
+	if d.width <= 0 {
+		d.viewport.SetContent(content)
+		return
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		width := ansi.StringWidth(line)
+		if width > d.xOffset+d.width {
+			// Mark lines that continue past the right edge.
+			lines[i] = ansi.Cut(line, d.xOffset, d.xOffset+d.width-1) + FileListStyle.Render("›")
+		} else {
+			lines[i] = ansi.Cut(line, d.xOffset, width)
+		}
+	}
+	d.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// SetXOffset scrolls the diff horizontally to the given column, clamped so the widest line stays in view.
+// Returns the clamped offset.
+func (d *DiffPane) SetXOffset(offset int) int {
+	maxOffset := 0
+	if d.diff != "" || d.stats != "" {
+		for _, line := range strings.Split(d.content(), "\n") {
+			maxOffset = max(maxOffset, ansi.StringWidth(line)-d.width)
+		}
+	}
+	d.xOffset = min(max(offset, 0), maxOffset)
+	if d.diff != "" || d.stats != "" {
+		d.setContent(d.content())
+	}
+	return d.xOffset
+}
+
+// content joins the aggregate stats, the conflicts, the per-file summary and the diff.
+func (d *DiffPane) content() string {
+	sections := []string{d.stats}
+	if d.conflicts != "" {
+		sections = append(sections, d.conflicts)
+	}
+	if len(d.files) > 0 {
+		sections = append(sections, d.fileSummary())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, append(sections, d.diff)...)
+}
+
+// fileSummary renders the per-file stats. When collapsed, only the number of changed files is shown.
+func (d *DiffPane) fileSummary() string {
+	if !d.showFiles {
+		return FileListStyle.Render(fmt.Sprintf("▸ %d files changed (f to expand)", len(d.files)))
+	}
+
+	lines := []string{FileListStyle.Render(fmt.Sprintf("▾ %d files changed ([/] to jump)", len(d.files)))}
+	for i, file := range d.files {
+		marker := "  "
+		if i == d.selectedFile {
+			marker = "> "
+		}
+
+		path := file.Path
+		if file.OldPath != "" {
+			path = file.OldPath + " → " + file.Path
+		}
+
+		var counts string
+		if file.Binary {
+			counts = FileListStyle.Render("binary")
+		} else {
+			counts = AdditionStyle.Render(fmt.Sprintf("+%d", file.Added)) + " " +
+				DeletionStyle.Render(fmt.Sprintf("-%d", file.Removed))
+		}
+
+		lines = append(lines, fmt.Sprintf("%s%s %s %s", marker, fileStatusLetter(file.Status), path, counts))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// fileStatusLetter returns the single letter git uses for a file status.
+func fileStatusLetter(status string) string {
+	switch status {
+	case git.FileAdded:
+		return AdditionStyle.Render("A")
+	case git.FileDeleted:
+		return DeletionStyle.Render("D")
+	case git.FileRenamed:
+		return HunkStyle.Render("R")
+	default:
+		return "M"
+	}
+}
+
+// ToggleFiles expands or collapses the per-file summary.
+func (d *DiffPane) ToggleFiles() {
+	d.showFiles = !d.showFiles
+	if d.diff != "" || d.stats != "" {
+		d.setContent(d.content())
+	}
+}
+
+// NextFile selects the next file in the summary and scrolls to its hunks.
+func (d *DiffPane) NextFile() {
+	d.jumpToFile(d.selectedFile + 1)
+}
+
+// PrevFile selects the previous file in the summary and scrolls to its hunks.
+func (d *DiffPane) PrevFile() {
+	d.jumpToFile(d.selectedFile - 1)
+}
+
+// jumpToFile selects the file at index and scrolls the viewport to the start of its diff.
+func (d *DiffPane) jumpToFile(index int) {
+	if len(d.files) == 0 {
+		return
+	}
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(d.files) {
+		index = len(d.files) - 1
+	}
+	d.selectedFile = index
+
+	content := d.content()
+	d.setContent(content)
+	if offset, ok := fileOffset(content, d.files[index].Path); ok {
+		d.viewport.SetYOffset(offset)
+	}
+}
+
+// fileOffset returns the line in content where the diff of the file at path starts.
+func fileOffset(content string, path string) (int, bool) {
+	header := " b/" + path
+	for i, line := range strings.Split(content, "\n") {
+		// JoinVertical pads lines to the same width.
+		line = strings.TrimRight(line, " ")
+		if strings.HasPrefix(line, "diff --git ") && strings.HasSuffix(line, header) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// hunkOffsets returns the lines of content where hunk headers start.
+func hunkOffsets(content string) []int {
+	var offsets []int
+	for i, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(ansi.Strip(line), "@@ ") {
+			offsets = append(offsets, i)
+		}
+	}
+	return offsets
+}
+
+// NextHunk scrolls the viewport to the first hunk header below the top line. It stays put after the last hunk.
+func (d *DiffPane) NextHunk() {
+	for _, offset := range d.hunks {
+		if offset > d.viewport.YOffset {
+			d.viewport.SetYOffset(offset)
+			return
+		}
+	}
+}
+
+// PrevHunk scrolls the viewport to the last hunk header above the top line. It stays put before the first hunk.
+func (d *DiffPane) PrevHunk() {
+	for i := len(d.hunks) - 1; i >= 0; i-- {
+		if d.hunks[i] < d.viewport.YOffset {
+			d.viewport.SetYOffset(d.hunks[i])
+			return
+		}
 	}
 }
 
@@ -108,13 +385,33 @@ func (d *DiffPane) ScrollDown() {
 	d.viewport.LineDown(1)
 }
 
+// renderDiff colorizes the diff, showing at most maxLines lines followed by a notice if the diff is longer.
+func renderDiff(diff string, maxLines int) string {
+	lines := strings.Split(diff, "\n")
+	if len(lines) <= maxLines {
+		return colorizeDiff(diff)
+	}
+
+	notice := HunkStyle.Render(fmt.Sprintf("Diff too large, showing first %d of %d lines", maxLines, len(lines)))
+	return notice + "\n" + colorizeDiff(strings.Join(lines[:maxLines], "\n"))
+}
+
+// isBinaryMarker returns true if the line is git's marker for a binary file, e.g.
+// "Binary files a/logo.png and b/logo.png differ".
+func isBinaryMarker(line string) bool {
+	return strings.HasPrefix(line, "Binary files ") && strings.HasSuffix(line, " differ")
+}
+
 func colorizeDiff(diff string) string {
 	var coloredOutput strings.Builder
 
 	lines := strings.Split(diff, "\n")
 	for _, line := range lines {
 		if len(line) > 0 {
-			if strings.HasPrefix(line, "@@") {
+			if isBinaryMarker(line) {
+				// Git doesn't show the contents of binary files, only that they changed
+				coloredOutput.WriteString(HunkStyle.Render("Binary file changed") + "\n")
+			} else if strings.HasPrefix(line, "@@") {
 				// Color hunk headers cyan
 				coloredOutput.WriteString(HunkStyle.Render(line) + "\n")
 			} else if line[0] == '+' && (len(line) == 1 || line[1] != '+') {
@@ -0,0 +1,324 @@
+package ui
+
+import (
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const twoFileDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,1 +1,2 @@
+ package main
++// added
+diff --git a/assets/logo.png b/assets/logo.png
+new file mode 100644
+index 0000000..3333333
+Binary files /dev/null and b/assets/logo.png differ`
+
+func newTestDiffPane() *DiffPane {
+	d := NewDiffPane()
+	d.SetSize(80, 5)
+	d.stats = "1 additions(+) 0 deletions(-)"
+	d.diff = colorizeDiff(twoFileDiff)
+	d.files = []git.FileStat{
+		{Path: "main.go", Added: 1, Status: git.FileModified},
+		{Path: "assets/logo.png", Binary: true, Status: git.FileAdded},
+	}
+	d.setContent(d.content())
+	return d
+}
+
+func TestDiffPaneFileSummary(t *testing.T) {
+	t.Run("collapsed by default", func(t *testing.T) {
+		d := newTestDiffPane()
+
+		summary := d.fileSummary()
+		assert.Contains(t, summary, "2 files changed")
+		assert.NotContains(t, summary, "main.go")
+	})
+
+	t.Run("expanded summary lists every file", func(t *testing.T) {
+		d := newTestDiffPane()
+		d.ToggleFiles()
+
+		summary := d.fileSummary()
+		assert.Contains(t, summary, "main.go")
+		assert.Contains(t, summary, "assets/logo.png")
+		assert.Contains(t, summary, "binary")
+	})
+
+	t.Run("renames show both paths", func(t *testing.T) {
+		d := newTestDiffPane()
+		d.files = []git.FileStat{{Path: "new.go", OldPath: "old.go", Status: git.FileRenamed}}
+		d.ToggleFiles()
+
+		assert.Contains(t, d.fileSummary(), "old.go → new.go")
+	})
+}
+
+func TestDiffPaneJumpToFile(t *testing.T) {
+	d := newTestDiffPane()
+
+	d.NextFile()
+	require.Equal(t, 1, d.selectedFile)
+	assert.True(t, strings.HasPrefix(d.viewport.View(), "diff --git a/assets/logo.png b/assets/logo.png"))
+
+	// Selecting past the last file stays on it.
+	d.NextFile()
+	assert.Equal(t, 1, d.selectedFile)
+
+	d.PrevFile()
+	require.Equal(t, 0, d.selectedFile)
+	assert.True(t, strings.HasPrefix(d.viewport.View(), "diff --git a/main.go b/main.go"))
+}
+
+func TestHunkOffsets(t *testing.T) {
+	t.Run("finds every hunk header", func(t *testing.T) {
+		diff := strings.Join([]string{
+			"diff --git a/main.go b/main.go",
+			"--- a/main.go",
+			"+++ b/main.go",
+			"@@ -1,2 +1,2 @@ package main",
+			"-old",
+			"+new",
+			"@@ -10,1 +10,2 @@ func main() {",
+			" @@ not a header",
+			"+@@ added line",
+			"diff --git a/util.go b/util.go",
+			"@@ -0,0 +1 @@",
+			"+util",
+		}, "\n")
+
+		assert.Equal(t, []int{3, 6, 10}, hunkOffsets(diff))
+		assert.Equal(t, []int{3, 6, 10}, hunkOffsets(colorizeDiff(diff)), "colors don't hide hunk headers")
+	})
+
+	t.Run("binary files and empty diffs have no hunks", func(t *testing.T) {
+		binary := strings.Join([]string{
+			"diff --git a/assets/logo.png b/assets/logo.png",
+			"new file mode 100644",
+			"Binary files /dev/null and b/assets/logo.png differ",
+		}, "\n")
+		assert.Empty(t, hunkOffsets(renderDiff(binary, maxDiffLines)))
+		assert.Empty(t, hunkOffsets(""))
+	})
+}
+
+func TestDiffPaneJumpToHunk(t *testing.T) {
+	d := newTestDiffPane()
+	var lines []string
+	for hunk := 1; hunk <= 3; hunk++ {
+		lines = append(lines, fmt.Sprintf("@@ -%d,4 +%d,4 @@", hunk*10, hunk*10))
+		for i := 0; i < 6; i++ {
+			lines = append(lines, fmt.Sprintf(" hunk %d line %d", hunk, i))
+		}
+	}
+	d.diff = colorizeDiff("diff --git a/main.go b/main.go\n" + strings.Join(lines, "\n"))
+	d.setContent(d.content())
+	require.Len(t, d.hunks, 3)
+	top := func() string { return strings.Split(ansi.Strip(d.viewport.View()), "\n")[0] }
+
+	d.NextHunk()
+	assert.Equal(t, "@@ -10,4 +10,4 @@", strings.TrimSpace(top()))
+	d.NextHunk()
+	assert.Equal(t, "@@ -20,4 +20,4 @@", strings.TrimSpace(top()))
+	d.NextHunk()
+	assert.Equal(t, "@@ -30,4 +30,4 @@", strings.TrimSpace(top()))
+
+	// Past the last hunk the view stays put.
+	d.NextHunk()
+	assert.Equal(t, "@@ -30,4 +30,4 @@", strings.TrimSpace(top()))
+
+	d.PrevHunk()
+	assert.Equal(t, "@@ -20,4 +20,4 @@", strings.TrimSpace(top()))
+	d.ScrollDown()
+	d.PrevHunk()
+	assert.Equal(t, "@@ -20,4 +20,4 @@", strings.TrimSpace(top()), "from inside a hunk, back to its header")
+	d.PrevHunk()
+	d.PrevHunk()
+	assert.Equal(t, "@@ -10,4 +10,4 @@", strings.TrimSpace(top()))
+}
+
+func TestRenderDiff(t *testing.T) {
+	t.Run("binary files show a placeholder", func(t *testing.T) {
+		rendered := renderDiff(twoFileDiff, maxDiffLines)
+
+		assert.Contains(t, rendered, "Binary file changed")
+		assert.NotContains(t, rendered, "Binary files /dev/null and b/assets/logo.png differ")
+		// The file header is kept so the file can still be jumped to.
+		assert.Contains(t, rendered, "diff --git a/assets/logo.png b/assets/logo.png")
+	})
+
+	t.Run("small diffs are rendered in full", func(t *testing.T) {
+		rendered := renderDiff(twoFileDiff, maxDiffLines)
+
+		assert.NotContains(t, rendered, "Diff too large")
+		assert.Contains(t, rendered, "+// added")
+	})
+
+	t.Run("oversized diffs are cut off with a notice", func(t *testing.T) {
+		lines := []string{"diff --git a/generated.go b/generated.go", "@@ -0,0 +1,5000 @@"}
+		for i := 0; i < 5000; i++ {
+			lines = append(lines, fmt.Sprintf("+line %d", i))
+		}
+
+		rendered := renderDiff(strings.Join(lines, "\n"), maxDiffLines)
+
+		assert.Contains(t, rendered, fmt.Sprintf("Diff too large, showing first %d of %d lines", maxDiffLines, len(lines)))
+		assert.Contains(t, rendered, fmt.Sprintf("+line %d", maxDiffLines-3))
+		assert.NotContains(t, rendered, fmt.Sprintf("+line %d", maxDiffLines-2))
+		// The notice and the rendered lines, with a trailing newline.
+		assert.Equal(t, maxDiffLines+2, len(strings.Split(rendered, "\n")))
+	})
+}
+
+func TestDiffPaneHorizontalScroll(t *testing.T) {
+	wideLine := "+" + strings.Repeat("abcdefghij", 12) + "END"
+	newWideDiffWindow := func() (*TabbedWindow, *DiffPane) {
+		d := NewDiffPane()
+		w := NewTabbedWindow(NewPreviewPane(), d)
+		w.SetSize(60, 20)
+		w.Toggle()
+		d.stats = "1 additions(+) 0 deletions(-)"
+		d.diff = colorizeDiff("@@ -0,0 +1 @@\n" + wideLine)
+		d.setContent(d.content())
+		return w, d
+	}
+
+	t.Run("wide lines are cut with an indicator", func(t *testing.T) {
+		_, d := newWideDiffWindow()
+
+		view := ansi.Strip(d.String())
+		assert.Contains(t, view, "›")
+		assert.NotContains(t, view, "END")
+	})
+
+	t.Run("scrolling right reveals the end of the line", func(t *testing.T) {
+		w, d := newWideDiffWindow()
+
+		for i := 0; i < 20; i++ {
+			w.ScrollRight()
+		}
+
+		view := ansi.Strip(d.String())
+		assert.Contains(t, view, "END")
+		assert.NotContains(t, view, "›")
+		// The offset is clamped to the widest line.
+		assert.Equal(t, ansi.StringWidth(wideLine)-d.width, w.diffXOffset)
+	})
+
+	t.Run("scrolling left stops at the first column", func(t *testing.T) {
+		w, _ := newWideDiffWindow()
+
+		w.ScrollRight()
+		w.ScrollLeft()
+		w.ScrollLeft()
+
+		assert.Equal(t, 0, w.diffXOffset)
+	})
+
+	t.Run("switching tabs resets the offset", func(t *testing.T) {
+		w, d := newWideDiffWindow()
+
+		w.ScrollRight()
+		require.NotZero(t, w.diffXOffset)
+		w.Toggle()
+
+		assert.Equal(t, 0, w.diffXOffset)
+		assert.Equal(t, 0, d.xOffset)
+	})
+
+	t.Run("only scrolls in the diff tab", func(t *testing.T) {
+		w, _ := newWideDiffWindow()
+		w.Toggle()
+
+		w.ScrollRight()
+
+		assert.Equal(t, 0, w.diffXOffset)
+	})
+}
+
+func TestTabbedWindowScrollPositions(t *testing.T) {
+	diffWithLines := func(n int) string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = fmt.Sprintf("+line %d", i)
+		}
+		return "@@ -0,0 +1 @@\n" + strings.Join(lines, "\n")
+	}
+	newScrollWindow := func() (*TabbedWindow, *DiffPane) {
+		d := NewDiffPane()
+		w := NewTabbedWindow(NewPreviewPane(), d)
+		w.SetSize(60, 20)
+		w.Toggle()
+		return w, d
+	}
+	setDiff := func(d *DiffPane, diff string) {
+		d.diff = colorizeDiff(diff)
+		d.setContent(d.content())
+	}
+	first := &session.Instance{Title: "first"}
+	second := &session.Instance{Title: "second"}
+
+	t.Run("restores the diff offset when switching back", func(t *testing.T) {
+		w, d := newScrollWindow()
+		setDiff(d, diffWithLines(100))
+		w.SetInstance(first)
+		for i := 0; i < 10; i++ {
+			w.ScrollDown()
+		}
+		require.Equal(t, 10, d.viewport.YOffset)
+
+		w.SetInstance(second)
+		assert.Equal(t, 0, d.viewport.YOffset)
+
+		w.SetInstance(first)
+		assert.Equal(t, 10, d.viewport.YOffset)
+	})
+
+	t.Run("starts from the top after the diff changed substantially", func(t *testing.T) {
+		w, d := newScrollWindow()
+		setDiff(d, diffWithLines(100))
+		w.SetInstance(first)
+		for i := 0; i < 10; i++ {
+			w.ScrollDown()
+		}
+		w.SetInstance(second)
+
+		setDiff(d, diffWithLines(30))
+		w.SetInstance(first)
+
+		assert.Equal(t, 0, d.viewport.YOffset)
+	})
+}
+
+func TestContentChanged(t *testing.T) {
+	assert.False(t, contentChanged(100, 100))
+	assert.False(t, contentChanged(100, 140))
+	assert.True(t, contentChanged(100, 151))
+	assert.True(t, contentChanged(100, 40))
+	assert.True(t, contentChanged(0, 1))
+}
+
+func TestDiffNote(t *testing.T) {
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "notes", Path: t.TempDir(), Program: "my-agent"})
+	require.NoError(t, err)
+
+	assert.Empty(t, diffNote(nil))
+	assert.Empty(t, diffNote(instance))
+
+	instance.SetDiffOptions(git.DiffOptions{Mode: git.DiffUncommitted})
+	assert.Equal(t, "(uncommitted)", diffNote(instance))
+
+	instance.SetDiffOptions(git.DiffOptions{Mode: git.DiffUncommitted, IgnoreWhitespace: true})
+	assert.Equal(t, "(uncommitted, ignoring whitespace)", diffNote(instance))
+}
@@ -1,8 +1,10 @@
 package ui
 
 import (
-	"github.com/charmbracelet/lipgloss"
 	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
 type ErrBox struct {
@@ -38,8 +40,8 @@ func (e *ErrBox) String() string {
 		err = e.err.Error()
 		lines := strings.Split(err, "\n")
 		err = strings.Join(lines, "//")
-		if len(err) > e.width-3 && e.width-3 >= 0 {
-			err = err[:e.width-3] + "..."
+		if runewidth.StringWidth(err) > e.width && e.width-3 >= 0 {
+			err = runewidth.Truncate(err, e.width, "...")
 		}
 	}
 	return lipgloss.Place(e.width, e.height, lipgloss.Center, lipgloss.Center, errStyle.Render(err))
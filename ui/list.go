@@ -5,14 +5,25 @@ import (
 	"claude-squad/session"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
 const readyIcon = "● "
 const pausedIcon = "⏸ "
+const deadIcon = "✖ "
+const archivedIcon = "▫ "
+const exitedIcon = "■ "
+
+// conflictIcon marks instances whose branch conflicts with its base branch.
+const conflictIcon = "⚠ "
+
+// unseenIcon marks instances whose output changed since they were last selected.
+const unseenIcon = "* "
 
 var readyStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.AdaptiveColor{Light: "#51bd73", Dark: "#51bd73"})
@@ -23,9 +34,21 @@ var addedLinesStyle = lipgloss.NewStyle().
 var removedLinesStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.Color("#de613e"))
 
+var unseenStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.Color("#e5c07b"))
+
+// queuedStyle highlights the number of queued prompts, so it's clear a prompt is waiting and needn't be typed again.
+var queuedStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.Color("#61afef"))
+
 var pausedStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.AdaptiveColor{Light: "#888888", Dark: "#888888"})
 
+var deadStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#de613e"))
+
 var titleStyle = lipgloss.NewStyle().
 	Padding(1, 1, 0, 1).
 	Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#dddddd"})
@@ -44,6 +67,15 @@ var selectedDescStyle = lipgloss.NewStyle().
 	Background(lipgloss.Color("#dde4f0")).
 	Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#1a1a1a"})
 
+var compactStyle = lipgloss.NewStyle().
+	Padding(0, 1).
+	Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#dddddd"})
+
+var selectedCompactStyle = lipgloss.NewStyle().
+	Padding(0, 1).
+	Background(lipgloss.Color("#dde4f0")).
+	Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#1a1a1a"})
+
 var mainTitle = lipgloss.NewStyle().
 	Background(lipgloss.Color("62")).
 	Foreground(lipgloss.Color("230"))
@@ -53,11 +85,26 @@ var autoYesStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.Color("#1a1a1a"))
 
 type List struct {
-	items         []*session.Instance
+	// items are all instances, including archived ones that are hidden.
+	items []*session.Instance
+	// selectedIdx is the index of the selected row among the shown rows.
 	selectedIdx   int
 	height, width int
 	renderer      *InstanceRenderer
 	autoyes       bool
+	// compact renders each instance on a single line instead of a title and a branch line.
+	compact bool
+	// offset is the index of the first row shown when they don't all fit in the height.
+	offset int
+	// showArchived shows archived instances. They are hidden otherwise.
+	showArchived bool
+	// sortByAttention shows the instances that need the user first instead of in the order they were added.
+	sortByAttention bool
+	// collapsed are the groups whose instances are hidden under their header.
+	collapsed map[string]bool
+	// shown are the rows in the order they were last shown, so the selection can follow the selected row when the
+	// order changes.
+	shown []listRow
 
 	// map of repo name to number of instances using it. Used to display the repo name only if there are
 	// multiple repos in play.
@@ -66,10 +113,11 @@ type List struct {
 
 func NewList(spinner *spinner.Model, autoYes bool) *List {
 	return &List{
-		items:    []*session.Instance{},
-		renderer: &InstanceRenderer{spinner: spinner},
-		repos:    make(map[string]int),
-		autoyes:  autoYes,
+		items:     []*session.Instance{},
+		renderer:  &InstanceRenderer{spinner: spinner},
+		repos:     make(map[string]int),
+		autoyes:   autoYes,
+		collapsed: make(map[string]bool),
 	}
 }
 
@@ -84,7 +132,7 @@ func (l *List) SetSize(width, height int) {
 // width and height.
 func (l *List) SetSessionPreviewSize(width, height int) (err error) {
 	for i, item := range l.items {
-		if !item.Started() || item.Paused() {
+		if !item.Started() || item.Paused() || item.Archived() {
 			continue
 		}
 
@@ -96,8 +144,100 @@ func (l *List) SetSessionPreviewSize(width, height int) (err error) {
 	return
 }
 
+// SetAutoYes sets whether the title shows that auto-yes is on.
+func (l *List) SetAutoYes(autoYes bool) {
+	l.autoyes = autoYes
+}
+
+// SetCompact switches between rendering each instance on one line and the expanded title and branch lines.
+func (l *List) SetCompact(compact bool) {
+	l.compact = compact
+}
+
+// Compact returns whether each instance is rendered on one line.
+func (l *List) Compact() bool {
+	return l.compact
+}
+
+// SetShowArchived switches between showing and hiding archived instances.
+func (l *List) SetShowArchived(show bool) {
+	l.showArchived = show
+}
+
+// ShowArchived returns whether archived instances are shown.
+func (l *List) ShowArchived() bool {
+	return l.showArchived
+}
+
+// SetSortByAttention switches between showing the instances that need the user first and showing them in the
+// order they were added.
+func (l *List) SetSortByAttention(sort bool) {
+	l.sortByAttention = sort
+}
+
+// SortByAttention returns whether the instances that need the user are shown first.
+func (l *List) SortByAttention() bool {
+	return l.sortByAttention
+}
+
+// rows returns the rows that are shown in order. Archived instances are hidden unless they are shown, and the
+// instances of collapsed groups are hidden under their group's header. The selection stays on the selected row if
+// it moved, on the header of the selected instance's group if that was collapsed, and on a shown row otherwise,
+// since instances are hidden when they are archived.
+func (l *List) rows() []listRow {
+	items := l.items
+	if !l.showArchived {
+		items = make([]*session.Instance, 0, len(l.items))
+		for _, item := range l.items {
+			if !item.Archived() {
+				items = append(items, item)
+			}
+		}
+	}
+	if l.sortByAttention {
+		items = session.SortByAttention(items)
+	}
+	rows := groupRows(items, l.collapsed)
+	if l.selectedIdx >= 0 && l.selectedIdx < len(l.shown) {
+		selected := l.shown[l.selectedIdx]
+		idx := rowIndex(rows, selected)
+		if idx == -1 && selected.instance != nil && l.collapsed[selected.instance.Group] {
+			idx = rowIndex(rows, listRow{group: selected.instance.Group})
+		}
+		if idx != -1 {
+			l.selectedIdx = idx
+		}
+	}
+	l.shown = rows
+	l.selectedIdx = max(min(l.selectedIdx, len(rows)-1), 0)
+	return rows
+}
+
+// NumInstances returns the number of instances shown, leaving out those of collapsed groups.
 func (l *List) NumInstances() int {
-	return len(l.items)
+	n := 0
+	for _, row := range l.rows() {
+		if row.instance != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Empty returns whether nothing is shown: no instances and no group headers.
+func (l *List) Empty() bool {
+	return len(l.rows()) == 0
+}
+
+// NumActiveInstances returns the number of instances that aren't archived, whether they are shown or not.
+func (l *List) NumActiveInstances() int {
+	n := 0
+	for _, item := range l.items {
+		if !item.Archived() {
+			n++
+		}
+	}
+	return n
 }
 
 // InstanceRenderer handles rendering of session.Instance objects
@@ -113,11 +253,148 @@ func (r *InstanceRenderer) setWidth(width int) {
 // ɹ and ɻ are other options.
 const branchIcon = "Ꮧ"
 
-func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, hasMultipleRepos bool) string {
+// inPlaceLabel follows the branch of instances that run directly in the repository.
+const inPlaceLabel = "[in place]"
+
+// statusGlyph returns the spinner or icon shown next to an instance's title, followed by a space.
+func (r *InstanceRenderer) statusGlyph(i *session.Instance) string {
+	// add spinner next to title if it's running, loading, or deleting
+	switch i.Status {
+	case session.Running, session.Loading, session.Deleting:
+		return fmt.Sprintf("%s ", r.spinner.View())
+	case session.Ready:
+		return readyStyle.Render(readyIcon)
+	case session.Paused:
+		return pausedStyle.Render(pausedIcon)
+	case session.Dead:
+		return deadStyle.Render(deadIcon)
+	case session.Archived:
+		return pausedStyle.Render(archivedIcon)
+	case session.Exited:
+		return pausedStyle.Render(exitedIcon)
+	}
+	return ""
+}
+
+// conflictGlyph returns the conflict icon on background if the instance conflicts with its base branch, and ""
+// otherwise.
+func conflictGlyph(i *session.Instance, background lipgloss.TerminalColor) string {
+	if len(i.Conflicts()) == 0 {
+		return ""
+	}
+	return deadStyle.Background(background).Render(conflictIcon)
+}
+
+// unseenGlyph returns the unseen icon on background if the instance's output changed since it was last selected, and
+// "" otherwise.
+func unseenGlyph(i *session.Instance, background lipgloss.TerminalColor) string {
+	if !i.HasUnseenOutput() {
+		return ""
+	}
+	return unseenStyle.Background(background).Render(unseenIcon)
+}
+
+// queueSuffix returns the number of prompts queued for an instance, shown after its title, or "" if there are none.
+func queueSuffix(i *session.Instance) string {
+	if n := i.QueuedPrompts(); n > 0 {
+		return fmt.Sprintf(" [%d queued]", n)
+	}
+	return ""
+}
+
+// iconPrefix returns the icon shown before an instance's title, followed by a space, or "" if it has none.
+func iconPrefix(i *session.Instance) string {
+	if i.Icon == "" {
+		return ""
+	}
+	return i.Icon + " "
+}
+
+// renderTitle renders the title text of an instance after its icon, in its color if it has one. The result is
+// placed inside text rendered with style, so style's colors are restored after the color ends.
+func renderTitle(i *session.Instance, text string, style lipgloss.Style) string {
+	hex := session.ColorHex(i.Color)
+	if hex == "" {
+		return iconPrefix(i) + text
+	}
+	colored := lipgloss.NewStyle().Background(style.GetBackground()).Foreground(lipgloss.Color(hex)).
+		Render(iconPrefix(i) + text)
+	return colored + restoreColors(style)
+}
+
+// renderQueued renders the queued prompts suffix returned by queueSuffix, placed inside text rendered with style.
+func renderQueued(queued string, style lipgloss.Style) string {
+	if queued == "" {
+		return ""
+	}
+	return queuedStyle.Background(style.GetBackground()).Render(queued) + restoreColors(style)
+}
+
+// restoreColors returns the sequence that sets style's colors again after text with colors of its own.
+func restoreColors(style lipgloss.Style) string {
+	// Rendering nothing produces no sequence, so the one that sets style's colors is cut from a rendered space.
+	restore, _, _ := strings.Cut(lipgloss.NewStyle().Background(style.GetBackground()).
+		Foreground(style.GetForeground()).Render(" "), " ")
+	return restore
+}
+
+// listPrefix returns the number shown before an instance's title.
+func listPrefix(idx int) string {
 	prefix := fmt.Sprintf(" %d. ", idx)
 	if idx >= 10 {
 		prefix = prefix[:len(prefix)-1]
 	}
+	return prefix
+}
+
+// RenderCompact renders an instance on a single line: its number, title, status and diff stats.
+func (r *InstanceRenderer) RenderCompact(i *session.Instance, idx int, selected bool) string {
+	prefix := listPrefix(idx)
+	style := selectedCompactStyle
+	if !selected {
+		style = compactStyle
+	}
+
+	var diff string
+	diffWidth := 0
+	if stat := i.GetDiffStats(); stat != nil && stat.Error == nil && !stat.IsEmpty() {
+		added := fmt.Sprintf("+%d", stat.Added)
+		removed := fmt.Sprintf("-%d", stat.Removed)
+		diff = lipgloss.JoinHorizontal(
+			lipgloss.Center,
+			addedLinesStyle.Background(style.GetBackground()).Render(added),
+			lipgloss.Style{}.Background(style.GetBackground()).Foreground(style.GetForeground()).Render(","),
+			removedLinesStyle.Background(style.GetBackground()).Render(removed),
+		)
+		diffWidth = len(added) + 1 + len(removed)
+	}
+
+	glyph := conflictGlyph(i, style.GetBackground()) + unseenGlyph(i, style.GetBackground()) + r.statusGlyph(i)
+	// Fill the same width as the expanded rendering. A space always separates the title from the status.
+	remainingWidth := r.width - len(prefix) - 1 - lipgloss.Width(glyph) - diffWidth
+	queued := queueSuffix(i)
+	if len(queued) > remainingWidth-1 {
+		queued = ""
+	}
+	remainingWidth -= len(queued) + runewidth.StringWidth(iconPrefix(i))
+	titleText := i.Title
+	if remainingWidth < 1 {
+		titleText = ""
+	} else if runewidth.StringWidth(titleText) > remainingWidth-1 {
+		titleText = runewidth.Truncate(titleText, remainingWidth-1, "...")
+	}
+	remainingWidth -= runewidth.StringWidth(titleText)
+
+	spaces := ""
+	if remainingWidth > 0 {
+		spaces = strings.Repeat(" ", remainingWidth)
+	}
+	return style.Render(fmt.Sprintf("%s %s%s%s", prefix, renderTitle(i, titleText, style),
+		renderQueued(queued, style), spaces) + glyph + diff)
+}
+
+func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, hasMultipleRepos bool) string {
+	prefix := listPrefix(idx)
 	titleS := selectedTitleStyle
 	descS := selectedDescStyle
 	if !selected {
@@ -125,31 +402,26 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 		descS = listDescStyle
 	}
 
-	// add spinner next to title if it's running, loading, or deleting
-	var join string
-	switch i.Status {
-	case session.Running:
-		join = fmt.Sprintf("%s ", r.spinner.View())
-	case session.Loading:
-		join = fmt.Sprintf("%s ", r.spinner.View())
-	case session.Deleting:
-		join = fmt.Sprintf("%s ", r.spinner.View())
-	case session.Ready:
-		join = readyStyle.Render(readyIcon)
-	case session.Paused:
-		join = pausedStyle.Render(pausedIcon)
-	default:
-	}
+	// The unseen icon is shown before the status, taking room from the title.
+	unseen := unseenGlyph(i, titleS.GetBackground())
+	join := unseen + r.statusGlyph(i)
 
-	// Cut the title if it's too long
+	// Cut the title if it's too long. Use display width so wide characters (CJK, emoji) don't overflow. The
+	// number of queued prompts is kept after the cut title.
 	titleText := i.Title
-	widthAvail := r.width - 3 - len(prefix) - 1
-	if widthAvail > 0 && widthAvail < len(titleText) && len(titleText) >= widthAvail-3 {
-		titleText = titleText[:widthAvail-3] + "..."
+	queued := queueSuffix(i)
+	widthAvail := r.width - 3 - len(prefix) - 1 - runewidth.StringWidth(iconPrefix(i)) - lipgloss.Width(unseen)
+	if len(queued) >= widthAvail {
+		queued = ""
+	}
+	widthAvail -= len(queued)
+	if widthAvail > 0 && runewidth.StringWidth(titleText) > widthAvail {
+		titleText = runewidth.Truncate(titleText, widthAvail, "...")
 	}
 	title := titleS.Render(lipgloss.JoinHorizontal(
 		lipgloss.Left,
-		lipgloss.Place(r.width-3, 1, lipgloss.Left, lipgloss.Center, fmt.Sprintf("%s %s", prefix, titleText)),
+		lipgloss.Place(r.width-3-lipgloss.Width(unseen), 1, lipgloss.Left, lipgloss.Center,
+			fmt.Sprintf("%s %s%s", prefix, renderTitle(i, titleText, titleS), renderQueued(queued, titleS))),
 		" ",
 		join,
 	))
@@ -176,7 +448,8 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 
 	remainingWidth := r.width
 	remainingWidth -= len(prefix)
-	remainingWidth -= len(branchIcon)
+	// The branch icon is followed by a space and a dash in the branch line.
+	remainingWidth -= runewidth.StringWidth(branchIcon) + 2
 
 	diffWidth := len(addedDiff) + len(removedDiff)
 	if diffWidth > 0 {
@@ -186,7 +459,14 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 	// Use fixed width for diff stats to avoid layout issues
 	remainingWidth -= diffWidth
 
+	conflict := conflictGlyph(i, descS.GetBackground())
+	remainingWidth -= lipgloss.Width(conflict)
+
 	branch := i.Branch
+	if i.InPlace() {
+		// In-place instances work on the branch checked out in the repository, not one of their own.
+		branch = strings.TrimSpace(branch + " " + inPlaceLabel)
+	}
 	if i.Started() && hasMultipleRepos {
 		repoName, err := i.RepoName()
 		if err != nil {
@@ -195,18 +475,24 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 			branch += fmt.Sprintf(" (%s)", repoName)
 		}
 	}
+	if len(i.Tags) > 0 {
+		branch += " [" + strings.Join(i.Tags, ", ") + "]"
+	}
+	// The note is flattened onto the branch line and cut with it.
+	if note := strings.Join(strings.Fields(i.Note), " "); note != "" {
+		branch += " · " + note
+	}
 	// Don't show branch if there's no space for it. Or show ellipsis if it's too long.
 	if remainingWidth < 0 {
 		branch = ""
-	} else if remainingWidth < len(branch) {
+	} else if remainingWidth < runewidth.StringWidth(branch) {
 		if remainingWidth < 3 {
 			branch = ""
 		} else {
-			// We know the remainingWidth is at least 4 and branch is longer than that, so this is safe.
-			branch = branch[:remainingWidth-3] + "..."
+			branch = runewidth.Truncate(branch, remainingWidth, "...")
 		}
 	}
-	remainingWidth -= len(branch)
+	remainingWidth -= runewidth.StringWidth(branch)
 
 	// Add spaces to fill the remaining width.
 	spaces := ""
@@ -214,7 +500,8 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 		spaces = strings.Repeat(" ", remainingWidth)
 	}
 
-	branchLine := fmt.Sprintf("%s %s-%s%s%s", strings.Repeat(" ", len(prefix)), branchIcon, branch, spaces, diff)
+	branchLine := fmt.Sprintf("%s %s-%s%s%s%s", strings.Repeat(" ", len(prefix)), branchIcon, branch, spaces, conflict,
+		diff)
 
 	// join title and subtitle
 	text := lipgloss.JoinVertical(
@@ -226,8 +513,113 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 	return text
 }
 
+// listHeaderHeight is the number of lines String renders above the first instance: two blank lines, the
+// title and another blank line.
+const listHeaderHeight = 4
+
+// expandedItemHeight is the number of lines of an instance rendered by Render: the title and branch lines
+// and their padding.
+const expandedItemHeight = 4
+
+// renderItem renders the shown row at index i of rows in the current mode.
+func (l *List) renderItem(rows []listRow, i int) string {
+	row := rows[i]
+	if row.instance == nil {
+		return l.renderer.RenderGroupHeader(row.group, row.size, l.collapsed[row.group], i == l.selectedIdx)
+	}
+	number := instanceIndex(rows, i) + 1
+	if l.compact {
+		return l.renderer.RenderCompact(row.instance, number, i == l.selectedIdx)
+	}
+	return l.renderer.Render(row.instance, number, i == l.selectedIdx, len(l.repos) > 1)
+}
+
+// itemGap is the number of blank lines between instances. Compact instances are not separated.
+func (l *List) itemGap() int {
+	if l.compact {
+		return 0
+	}
+	return 1
+}
+
+// gapAfter is the number of blank lines after row. A group header is not separated from its first instance.
+func (l *List) gapAfter(row listRow) int {
+	if row.instance == nil {
+		return 0
+	}
+	return l.itemGap()
+}
+
+// rowHeight is the number of lines of row, not counting the gap after it.
+func (l *List) rowHeight(row listRow) int {
+	if row.instance == nil || l.compact {
+		return 1
+	}
+	return expandedItemHeight
+}
+
+// rowsHeight is the number of lines rows take, with the gaps between them.
+func (l *List) rowsHeight(rows []listRow) int {
+	height := 0
+	for i, row := range rows {
+		height += l.rowHeight(row)
+		if i != len(rows)-1 {
+			height += l.gapAfter(row)
+		}
+	}
+	return height
+}
+
+// visibleRange returns the indices in rows of the first and one past the last shown row that are rendered. It
+// scrolls the list so the selected row is always shown.
+func (l *List) visibleRange(rows []listRow) (int, int) {
+	if l.height <= 0 {
+		return 0, len(rows)
+	}
+	room := l.height - listHeaderHeight
+	l.offset = max(min(l.offset, l.selectedIdx), 0)
+	for l.offset < l.selectedIdx && l.rowsHeight(rows[l.offset:l.selectedIdx+1]) > room {
+		l.offset++
+	}
+	// Scroll back up if there is room at the end, e.g. after instances were removed.
+	for l.offset > 0 && l.rowsHeight(rows[l.offset-1:]) <= room {
+		l.offset--
+	}
+	end := min(l.offset+1, len(rows))
+	for end < len(rows) && l.rowsHeight(rows[l.offset:end+1]) <= room {
+		end++
+	}
+	return l.offset, end
+}
+
+// InstanceAt returns the index of the instance rendered at line y of String's output. Returns false if y is
+// not on an instance, e.g. on the title, a group header or between instances.
+func (l *List) InstanceAt(y int) (int, bool) {
+	if y < listHeaderHeight || y >= l.height {
+		return 0, false
+	}
+
+	top := listHeaderHeight
+	rows := l.rows()
+	start, end := l.visibleRange(rows)
+	for i := start; i < end; i++ {
+		height := lipgloss.Height(l.renderItem(rows, i))
+		if y < top+height {
+			if rows[i].instance == nil {
+				return 0, false
+			}
+			return instanceIndex(rows, i), y >= top
+		}
+		top += height + l.gapAfter(rows[i])
+	}
+	return 0, false
+}
+
 func (l *List) String() string {
-	const titleText = " Instances "
+	titleText := " Instances "
+	if l.sortByAttention {
+		titleText = " Instances by attention "
+	}
 	const autoYesText = " auto-yes "
 
 	// Write the title.
@@ -253,32 +645,33 @@ func (l *List) String() string {
 	b.WriteString("\n")
 	b.WriteString("\n")
 
-	// Render the list.
-	for i, item := range l.items {
-		b.WriteString(l.renderer.Render(item, i+1, i == l.selectedIdx, len(l.repos) > 1))
-		if i != len(l.items)-1 {
-			b.WriteString("\n\n")
+	// Render the rows that fit.
+	rows := l.rows()
+	start, end := l.visibleRange(rows)
+	for i := start; i < end; i++ {
+		b.WriteString(l.renderItem(rows, i))
+		if i != end-1 {
+			b.WriteString(strings.Repeat("\n", 1+l.gapAfter(rows[i])))
 		}
 	}
 	return lipgloss.Place(l.width, l.height, lipgloss.Left, lipgloss.Top, b.String())
 }
 
-// Down selects the next item in the list.
+// Down selects the next row in the list.
 func (l *List) Down() {
-	if len(l.items) == 0 {
-		return
-	}
-	if l.selectedIdx < len(l.items)-1 {
+	if l.selectedIdx < len(l.rows())-1 {
 		l.selectedIdx++
 	}
 }
 
-// Kill removes the currently selected instance from the list and kills its tmux session.
+// Kill removes the currently selected instance from the list and kills its tmux session. Noop if a group header is
+// selected.
 func (l *List) Kill() {
-	if len(l.items) == 0 {
+	rows := l.rows()
+	if len(rows) == 0 || rows[l.selectedIdx].instance == nil {
 		return
 	}
-	targetInstance := l.items[l.selectedIdx]
+	targetInstance := rows[l.selectedIdx].instance
 
 	// Kill the tmux session
 	if err := targetInstance.Kill(); err != nil {
@@ -286,7 +679,7 @@ func (l *List) Kill() {
 	}
 
 	// If you delete the last one in the list, select the previous one.
-	if l.selectedIdx == len(l.items)-1 {
+	if l.selectedIdx == len(rows)-1 {
 		defer l.Up()
 	}
 
@@ -299,7 +692,8 @@ func (l *List) Kill() {
 	}
 
 	// Since there's items after this, the selectedIdx can stay the same.
-	l.items = append(l.items[:l.selectedIdx], l.items[l.selectedIdx+1:]...)
+	idx := slices.Index(l.items, targetInstance)
+	l.items = append(l.items[:idx], l.items[idx+1:]...)
 }
 
 // RemoveInstance removes a specific instance from the list (without killing it - assumes already killed).
@@ -309,14 +703,7 @@ func (l *List) RemoveInstance(instance *session.Instance) {
 	}
 
 	// Find the instance index
-	idx := -1
-	for i, item := range l.items {
-		if item == instance {
-			idx = i
-			break
-		}
-	}
-
+	idx := slices.Index(l.items, instance)
 	if idx == -1 {
 		log.ErrorLog.Printf("instance not found in list: %s", instance.Title)
 		return
@@ -330,30 +717,27 @@ func (l *List) RemoveInstance(instance *session.Instance) {
 		l.rmRepo(repoName)
 	}
 
-	// If we're removing the selected instance or one before it, adjust selection
-	if idx <= l.selectedIdx && l.selectedIdx > 0 {
+	// If we're removing the selected instance or one before it, adjust selection. Hidden instances don't move
+	// the selection.
+	shownIdx := rowIndex(l.rows(), listRow{instance: instance})
+	if shownIdx != -1 && shownIdx <= l.selectedIdx && l.selectedIdx > 0 {
 		l.selectedIdx--
 	}
 
-	// Remove from list
+	// Remove from list. The selection is kept within bounds when the list is next read.
 	l.items = append(l.items[:idx], l.items[idx+1:]...)
-
-	// Ensure selectedIdx is within bounds
-	if l.selectedIdx >= len(l.items) && len(l.items) > 0 {
-		l.selectedIdx = len(l.items) - 1
-	}
 }
 
 func (l *List) Attach() (chan struct{}, error) {
-	targetInstance := l.items[l.selectedIdx]
+	targetInstance := l.GetSelectedInstance()
+	if targetInstance == nil {
+		return nil, fmt.Errorf("no instance selected")
+	}
 	return targetInstance.Attach()
 }
 
-// Up selects the prev item in the list.
+// Up selects the prev row in the list.
 func (l *List) Up() {
-	if len(l.items) == 0 {
-		return
-	}
 	if l.selectedIdx > 0 {
 		l.selectedIdx--
 	}
@@ -394,23 +778,53 @@ func (l *List) AddInstance(instance *session.Instance) (finalize func()) {
 	}
 }
 
-// GetSelectedInstance returns the currently selected instance
+// GetSelectedInstance returns the currently selected instance, or nil if there is none or a group header is
+// selected.
 func (l *List) GetSelectedInstance() *session.Instance {
-	if len(l.items) == 0 {
+	rows := l.rows()
+	if len(rows) == 0 {
 		return nil
 	}
-	return l.items[l.selectedIdx]
+	return rows[l.selectedIdx].instance
 }
 
-// SetSelectedInstance sets the selected index. Noop if the index is out of bounds.
+// StatusGlyph returns the spinner or icon shown next to instance's title in the list, followed by a space.
+func (l *List) StatusGlyph(instance *session.Instance) string {
+	return l.renderer.statusGlyph(instance)
+}
+
+// SetSelectedInstance sets the selected index among the shown instances. Noop if the index is out of bounds.
 func (l *List) SetSelectedInstance(idx int) {
-	if idx >= len(l.items) {
-		return
+	rows := l.rows()
+	for i, row := range rows {
+		if row.instance == nil {
+			continue
+		}
+		if idx == 0 {
+			l.selectedIdx = i
+			return
+		}
+		idx--
+	}
+}
+
+// SelectInstance selects instance, expanding its group if it is collapsed. Returns false if it isn't shown.
+func (l *List) SelectInstance(instance *session.Instance) bool {
+	idx := rowIndex(l.rows(), listRow{instance: instance})
+	if idx == -1 && l.collapsed[instance.Group] {
+		delete(l.collapsed, instance.Group)
+		if idx = rowIndex(l.rows(), listRow{instance: instance}); idx == -1 {
+			l.collapsed[instance.Group] = true
+		}
+	}
+	if idx == -1 {
+		return false
 	}
 	l.selectedIdx = idx
+	return true
 }
 
-// GetInstances returns all instances in the list
+// GetInstances returns all instances in the list, including hidden archived ones
 func (l *List) GetInstances() []*session.Instance {
 	return l.items
 }
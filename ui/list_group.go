@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"claude-squad/session"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// expandedGroupIcon and collapsedGroupIcon are shown before the name of an expanded and a collapsed group.
+const (
+	expandedGroupIcon  = "▾ "
+	collapsedGroupIcon = "▸ "
+)
+
+// ungroupedHeader is the header of the instances without a group.
+const ungroupedHeader = "Ungrouped"
+
+var groupHeaderStyle = lipgloss.NewStyle().
+	Padding(0, 1).
+	Bold(true).
+	Foreground(lipgloss.AdaptiveColor{Light: "#7A7474", Dark: "#9C9494"})
+
+var selectedGroupHeaderStyle = lipgloss.NewStyle().
+	Padding(0, 1).
+	Bold(true).
+	Background(lipgloss.Color("#dde4f0")).
+	Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#1a1a1a"})
+
+// listRow is a row of the list: an instance, or the header of a group if instance is nil.
+type listRow struct {
+	instance *session.Instance
+	// group is the group of the header or instance. Ungrouped instances are in the group "".
+	group string
+	// size is the number of shown instances in the header's group, including hidden ones of a collapsed group.
+	size int
+}
+
+// groupRows returns the rows of items. If none of them has a group, the rows are just the instances. Otherwise each
+// group has a header followed by its instances, unless it is collapsed. Groups are in the order of their first
+// instance, and ungrouped instances come last.
+func groupRows(items []*session.Instance, collapsed map[string]bool) []listRow {
+	var groups []string
+	sizes := make(map[string]int)
+	for _, item := range items {
+		if item.Group != "" && sizes[item.Group] == 0 {
+			groups = append(groups, item.Group)
+		}
+		sizes[item.Group]++
+	}
+
+	rows := make([]listRow, 0, len(items)+len(groups)+1)
+	if len(groups) == 0 {
+		for _, item := range items {
+			rows = append(rows, listRow{instance: item})
+		}
+		return rows
+	}
+	if sizes[""] > 0 {
+		groups = append(groups, "")
+	}
+	for _, group := range groups {
+		rows = append(rows, listRow{group: group, size: sizes[group]})
+		if collapsed[group] {
+			continue
+		}
+		for _, item := range items {
+			if item.Group == group {
+				rows = append(rows, listRow{instance: item, group: group})
+			}
+		}
+	}
+	return rows
+}
+
+// rowIndex returns the index in rows of the row of the same instance as row, or of the same group's header if row
+// is a header. It returns -1 if there is none.
+func rowIndex(rows []listRow, row listRow) int {
+	return slices.IndexFunc(rows, func(r listRow) bool {
+		if row.instance != nil {
+			return r.instance == row.instance
+		}
+		return r.instance == nil && r.group == row.group
+	})
+}
+
+// instanceIndex returns the index of the instance at rows[i] among the instances in rows.
+func instanceIndex(rows []listRow, i int) int {
+	n := 0
+	for _, row := range rows[:i] {
+		if row.instance != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// RenderGroupHeader renders the header of a group of size instances on a single line, as wide as an instance.
+func (r *InstanceRenderer) RenderGroupHeader(group string, size int, collapsed bool, selected bool) string {
+	style := groupHeaderStyle
+	if selected {
+		style = selectedGroupHeaderStyle
+	}
+	icon := expandedGroupIcon
+	if collapsed {
+		icon = collapsedGroupIcon
+	}
+	name := group
+	if name == "" {
+		name = ungroupedHeader
+	}
+	count := fmt.Sprintf(" (%d)", size)
+
+	remainingWidth := r.width - runewidth.StringWidth(icon) - len(count)
+	if runewidth.StringWidth(name) > remainingWidth {
+		name = runewidth.Truncate(name, max(remainingWidth, 0), "...")
+	}
+	remainingWidth -= runewidth.StringWidth(name)
+	return style.Render(icon + name + count + strings.Repeat(" ", max(remainingWidth, 0)))
+}
+
+// SelectedGroup returns the group of the selected instance or group header, or "" if it is ungrouped or nothing is
+// selected.
+func (l *List) SelectedGroup() string {
+	rows := l.rows()
+	if len(rows) == 0 {
+		return ""
+	}
+	return rows[l.selectedIdx].group
+}
+
+// ToggleGroup collapses the group of the selected instance or group header, or expands it if it is collapsed. A
+// collapsed group's header stays selected. Returns false if the list isn't grouped.
+func (l *List) ToggleGroup() bool {
+	rows := l.rows()
+	if len(rows) == 0 || !slices.ContainsFunc(rows, func(r listRow) bool { return r.instance == nil }) {
+		return false
+	}
+	group := rows[l.selectedIdx].group
+	if l.collapsed[group] {
+		delete(l.collapsed, group)
+	} else {
+		l.collapsed[group] = true
+	}
+	// The header stays selected, since the selected instance is hidden when its group collapses.
+	l.shown = rows
+	l.selectedIdx = rowIndex(rows, listRow{group: group})
+	l.rows()
+	return true
+}
+
+// CollapsedGroups returns the collapsed groups that have instances, sorted. The ungrouped instances are the group "".
+func (l *List) CollapsedGroups() []string {
+	var groups []string
+	for group := range l.collapsed {
+		if slices.ContainsFunc(l.items, func(i *session.Instance) bool { return i.Group == group }) {
+			groups = append(groups, group)
+		}
+	}
+	slices.Sort(groups)
+	return groups
+}
+
+// SetCollapsedGroups collapses groups and expands all others.
+func (l *List) SetCollapsedGroups(groups []string) {
+	l.collapsed = make(map[string]bool)
+	for _, group := range groups {
+		l.collapsed[group] = true
+	}
+}
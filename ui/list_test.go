@@ -0,0 +1,566 @@
+package ui
+
+import (
+	"claude-squad/session"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/require"
+)
+
+// renderInstance renders an instance with the given title and branch in a list that is 50 columns wide.
+func renderInstance(t *testing.T, title, branch string) string {
+	t.Helper()
+
+	instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: ".", Program: "claude"})
+	require.NoError(t, err)
+	instance.Branch = branch
+
+	s := spinner.New()
+	renderer := &InstanceRenderer{spinner: &s}
+	renderer.setWidth(50)
+	return renderer.Render(instance, 1, false, false)
+}
+
+// lineWidths returns the display width of each line in s.
+func lineWidths(s string) []int {
+	var widths []int
+	for _, line := range strings.Split(s, "\n") {
+		widths = append(widths, lipgloss.Width(line))
+	}
+	return widths
+}
+
+func TestInstanceRendererWideCharacters(t *testing.T) {
+	expected := lineWidths(renderInstance(t, "plain title", "user/plain-title"))
+
+	tests := []struct {
+		name   string
+		title  string
+		branch string
+	}{
+		{name: "emoji title", title: "🚀 launch 🚀", branch: "user/launch"},
+		{name: "CJK title", title: "修复登录错误", branch: "user/fix"},
+		{name: "long emoji title is truncated", title: strings.Repeat("🔥", 40), branch: "user/fire"},
+		{name: "long CJK branch is truncated", title: "branch", branch: "user/" + strings.Repeat("分支", 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered := renderInstance(t, tt.title, tt.branch)
+			require.True(t, utf8.ValidString(rendered), "truncation must not split multi-byte characters")
+			require.Equal(t, expected, lineWidths(rendered))
+		})
+	}
+
+	t.Run("title that is long in bytes but fits in columns is not truncated", func(t *testing.T) {
+		title := strings.Repeat("修", 15)
+		require.Contains(t, renderInstance(t, title, "user/fix"), title)
+	})
+}
+
+func TestInstanceRendererNote(t *testing.T) {
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "noted", Path: ".", Program: "claude"})
+	require.NoError(t, err)
+	instance.Branch = "user/noted"
+	instance.Note = "review the\nmigration " + strings.Repeat("x", 60)
+
+	s := spinner.New()
+	renderer := &InstanceRenderer{spinner: &s}
+	renderer.setWidth(50)
+	rendered := renderer.Render(instance, 1, false, false)
+
+	require.Contains(t, ansi.Strip(rendered), "user/noted · review the migration x")
+	require.Equal(t, lineWidths(renderInstance(t, "noted", "user/noted")), lineWidths(rendered))
+}
+
+func TestInstanceRendererTags(t *testing.T) {
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "tagged", Path: ".", Program: "claude"})
+	require.NoError(t, err)
+	instance.Branch = "user/tagged"
+	instance.Tags = []string{"bug", "ui"}
+	instance.Note = "flaky"
+
+	s := spinner.New()
+	renderer := &InstanceRenderer{spinner: &s}
+	renderer.setWidth(50)
+	rendered := renderer.Render(instance, 1, false, false)
+
+	require.Contains(t, ansi.Strip(rendered), "user/tagged [bug, ui] · flaky")
+	require.Equal(t, lineWidths(renderInstance(t, "tagged", "user/tagged")), lineWidths(rendered))
+}
+
+func TestInstanceRendererColorIcon(t *testing.T) {
+	newInstance := func(title string) *session.Instance {
+		instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: ".", Program: "claude"})
+		require.NoError(t, err)
+		instance.Branch = "user/task"
+		return instance
+	}
+	s := spinner.New()
+	renderer := &InstanceRenderer{spinner: &s}
+	renderer.setWidth(50)
+	plain := newInstance("task")
+	expected := lineWidths(renderer.Render(plain, 1, true, false))
+	expectedCompact := lineWidths(renderer.RenderCompact(plain, 1, true))
+
+	for _, title := range []string{"task", strings.Repeat("long", 20)} {
+		instance := newInstance(title)
+		instance.Color = "blue"
+		instance.Icon = "🚀"
+
+		rendered := renderer.Render(instance, 1, true, false)
+		require.Contains(t, ansi.Strip(rendered), "🚀 "+title[:4])
+		require.Equal(t, expected, lineWidths(rendered))
+
+		compact := renderer.RenderCompact(instance, 1, true)
+		require.Contains(t, ansi.Strip(compact), "🚀 "+title[:4])
+		require.Equal(t, expectedCompact, lineWidths(compact))
+	}
+}
+
+func TestInstanceRendererUnseen(t *testing.T) {
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: ".", Program: "claude"})
+	require.NoError(t, err)
+	instance.Branch = "user/task"
+	s := spinner.New()
+	renderer := &InstanceRenderer{spinner: &s}
+	renderer.setWidth(50)
+	expected := lineWidths(renderer.Render(instance, 1, false, false))
+	expectedCompact := lineWidths(renderer.RenderCompact(instance, 1, false))
+	require.NotContains(t, ansi.Strip(renderer.Render(instance, 1, false, false)), unseenIcon)
+
+	instance.MarkActive(time.Now())
+	instance.TrackUnseen(true, false)
+	rendered := renderer.Render(instance, 1, false, false)
+	require.Contains(t, ansi.Strip(rendered), unseenIcon)
+	require.Equal(t, expected, lineWidths(rendered))
+	compact := renderer.RenderCompact(instance, 1, false)
+	require.Contains(t, ansi.Strip(compact), unseenIcon)
+	require.Equal(t, expectedCompact, lineWidths(compact))
+
+	instance.MarkSeen()
+	require.NotContains(t, ansi.Strip(renderer.Render(instance, 1, false, false)), unseenIcon)
+}
+
+func TestInstanceRendererQueued(t *testing.T) {
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: ".", Program: "claude"})
+	require.NoError(t, err)
+	instance.Branch = "user/task"
+	s := spinner.New()
+	renderer := &InstanceRenderer{spinner: &s}
+	renderer.setWidth(50)
+	expected := lineWidths(renderer.Render(instance, 1, false, false))
+	expectedCompact := lineWidths(renderer.RenderCompact(instance, 1, false))
+	require.NotContains(t, ansi.Strip(renderer.Render(instance, 1, false, false)), "queued")
+
+	instance.QueuePrompt("run the tests")
+	instance.QueuePrompt("fix them")
+	for _, selected := range []bool{false, true} {
+		rendered := renderer.Render(instance, 1, selected, false)
+		require.Contains(t, ansi.Strip(rendered), "task [2 queued]")
+		require.Equal(t, expected, lineWidths(rendered))
+		compact := renderer.RenderCompact(instance, 1, selected)
+		require.Contains(t, ansi.Strip(compact), "task [2 queued]")
+		require.Equal(t, expectedCompact, lineWidths(compact))
+	}
+}
+
+func TestErrBoxWideCharacters(t *testing.T) {
+	box := NewErrBox()
+	box.SetSize(20, 1)
+	box.SetError(errString(strings.Repeat("错", 30)))
+	require.Equal(t, 20, lipgloss.Width(box.String()))
+}
+
+type errString string
+
+func (e errString) Error() string { return string(e) }
+
+func TestListInstanceAt(t *testing.T) {
+	s := spinner.New()
+	list := NewList(&s, false)
+	list.SetSize(40, 40)
+	for _, title := range []string{"first", "second"} {
+		instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: ".", Program: "claude"})
+		require.NoError(t, err)
+		list.AddInstance(instance)
+	}
+
+	lines := strings.Split(list.String(), "\n")
+	found := map[string]bool{}
+	for y, line := range lines {
+		index, ok := list.InstanceAt(y)
+		if !ok {
+			continue
+		}
+		title := list.GetInstances()[index].Title
+		// Every line of an instance's row belongs to that instance, including its title line.
+		if strings.Contains(line, title) {
+			found[title] = true
+		}
+		for _, other := range list.GetInstances() {
+			if other.Title != title {
+				require.NotContains(t, line, other.Title, "line %d belongs to %s", y, title)
+			}
+		}
+	}
+	require.True(t, found["first"])
+	require.True(t, found["second"])
+
+	_, ok := list.InstanceAt(0)
+	require.False(t, ok, "the title is not an instance")
+	_, ok = list.InstanceAt(len(lines) + 10)
+	require.False(t, ok, "rows past the list are not instances")
+}
+
+func TestTabbedWindowTabAt(t *testing.T) {
+	w := NewTabbedWindow(NewPreviewPane(), NewDiffPane())
+	w.SetSize(100, 30)
+
+	tab, ok := w.TabAt(1, 3)
+	require.True(t, ok)
+	require.Equal(t, PreviewTab, tab)
+
+	tab, ok = w.TabAt(w.width*3/8, 3)
+	require.True(t, ok)
+	require.Equal(t, DiffTab, tab)
+
+	tab, ok = w.TabAt(w.width-1, 3)
+	require.True(t, ok)
+	require.Equal(t, LogsTab, tab)
+
+	_, ok = w.TabAt(1, 0)
+	require.False(t, ok, "the blank line above the tabs is not a tab")
+	_, ok = w.TabAt(1, 10)
+	require.False(t, ok, "the content is not a tab")
+}
+
+func TestTabbedWindowPinned(t *testing.T) {
+	w := NewTabbedWindow(NewPreviewPane(), NewDiffPane())
+	w.SetSize(100, 30)
+	unpinned := w.String()
+
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "watched", Path: ".", Program: "claude"})
+	require.NoError(t, err)
+	w.SetPinned(instance)
+	pinned := w.String()
+	require.Contains(t, ansi.Strip(pinned), "Pinned: watched")
+	require.Equal(t, lipgloss.Height(unpinned), lipgloss.Height(pinned), "the tabs stay where they are")
+	tab, ok := w.TabAt(1, 3)
+	require.True(t, ok)
+	require.Equal(t, PreviewTab, tab)
+
+	w.SetPinned(nil)
+	require.Equal(t, unpinned, w.String())
+}
+
+// newTestList returns a list of n instances titled task-01, task-02 and so on.
+func newTestList(t *testing.T, n, width, height int) *List {
+	t.Helper()
+	s := spinner.New()
+	list := NewList(&s, false)
+	list.SetSize(width, height)
+	for i := 1; i <= n; i++ {
+		instance, err := session.NewInstance(session.InstanceOptions{
+			Title: fmt.Sprintf("task-%02d", i), Path: ".", Program: "claude"})
+		require.NoError(t, err)
+		list.AddInstance(instance)
+	}
+	return list
+}
+
+func TestInstanceRendererCompact(t *testing.T) {
+	s := spinner.New()
+	renderer := &InstanceRenderer{spinner: &s}
+	renderer.setWidth(50)
+
+	for _, title := range []string{"short", strings.Repeat("long title ", 10), strings.Repeat("修", 30)} {
+		instance, err := session.NewInstance(session.InstanceOptions{Title: title, Path: ".", Program: "claude"})
+		require.NoError(t, err)
+		instance.Status = session.Paused
+
+		for _, selected := range []bool{false, true} {
+			compact := renderer.RenderCompact(instance, 1, selected)
+			expanded := renderer.Render(instance, 1, selected, false)
+			require.Equal(t, 1, lipgloss.Height(compact))
+			require.Equal(t, lipgloss.Width(expanded), lipgloss.Width(compact), "title %q", title)
+			require.Contains(t, compact, strings.TrimSpace(pausedIcon))
+		}
+	}
+}
+
+func TestListCompactMode(t *testing.T) {
+	// The header takes four lines, leaving room for ten compact instances or two expanded ones.
+	list := newTestList(t, 10, 40, 14)
+
+	t.Run("expanded shows the instances that fit", func(t *testing.T) {
+		out := list.String()
+		require.Contains(t, out, "task-01")
+		require.Contains(t, out, "task-02")
+		require.NotContains(t, out, "task-03")
+		require.Equal(t, 14, lipgloss.Height(out))
+	})
+
+	t.Run("expanded scrolls to the selected instance", func(t *testing.T) {
+		for i := 0; i < 9; i++ {
+			list.Down()
+		}
+		out := list.String()
+		require.NotContains(t, out, "task-08")
+		require.Contains(t, out, "task-09")
+		require.Contains(t, out, "task-10")
+
+		index, ok := list.InstanceAt(listHeaderHeight + 1)
+		require.True(t, ok)
+		require.Equal(t, 8, index)
+	})
+
+	t.Run("compact shows one line per instance", func(t *testing.T) {
+		list.SetCompact(true)
+		out := list.String()
+		require.Equal(t, 14, lipgloss.Height(out))
+		lines := strings.Split(out, "\n")
+		for i := 0; i < 10; i++ {
+			y := listHeaderHeight + i
+			require.Contains(t, lines[y], fmt.Sprintf("task-%02d", i+1))
+			index, ok := list.InstanceAt(y)
+			require.True(t, ok)
+			require.Equal(t, i, index)
+		}
+	})
+
+	t.Run("selection works the same in compact mode", func(t *testing.T) {
+		list.Up()
+		require.Equal(t, "task-09", list.GetSelectedInstance().Title)
+
+		// Only ten instances fit, so a longer list scrolls to keep the selection visible.
+		more := newTestList(t, 20, 40, 14)
+		more.SetCompact(true)
+		more.SetSelectedInstance(15)
+		out := more.String()
+		require.Contains(t, out, "task-16")
+		require.NotContains(t, out, "task-06")
+		require.Equal(t, 14, lipgloss.Height(out))
+	})
+}
+
+func TestListArchived(t *testing.T) {
+	list := newTestList(t, 4, 60, 40)
+	instances := list.GetInstances()
+	instances[1].Status = session.Archived
+	list.SetSelectedInstance(1)
+
+	t.Run("archived instances are hidden by default", func(t *testing.T) {
+		require.Equal(t, 3, list.NumInstances())
+		require.Equal(t, 3, list.NumActiveInstances())
+		require.Len(t, list.GetInstances(), 4, "hidden instances are still stored")
+		require.Equal(t, instances[2], list.GetSelectedInstance())
+
+		view := ansi.Strip(list.String())
+		require.NotContains(t, view, "task-02")
+		require.Contains(t, view, "task-03")
+
+		list.Down()
+		require.Equal(t, instances[3], list.GetSelectedInstance())
+		list.Down()
+		require.Equal(t, instances[3], list.GetSelectedInstance())
+	})
+
+	t.Run("archived instances can be shown", func(t *testing.T) {
+		list.SetShowArchived(true)
+		require.Equal(t, 4, list.NumInstances())
+		require.Equal(t, 3, list.NumActiveInstances())
+		require.Contains(t, ansi.Strip(list.String()), "task-02")
+
+		require.True(t, list.SelectInstance(instances[1]))
+		require.Equal(t, instances[1], list.GetSelectedInstance())
+		list.SetShowArchived(false)
+		require.False(t, list.SelectInstance(instances[1]))
+	})
+
+	t.Run("archiving the last shown instance selects the one before it", func(t *testing.T) {
+		list.SetSelectedInstance(2)
+		require.Equal(t, instances[3], list.GetSelectedInstance())
+		instances[3].Status = session.Archived
+		require.Equal(t, instances[2], list.GetSelectedInstance())
+	})
+}
+
+func TestListSortByAttention(t *testing.T) {
+	list := newTestList(t, 4, 60, 40)
+	instances := list.GetInstances()
+	instances[0].Status = session.Paused
+	instances[1].Status = session.Running
+	instances[2].Status = session.Ready
+	instances[3].Status = session.Running
+	list.SetSelectedInstance(1)
+	require.Equal(t, instances[1], list.GetSelectedInstance())
+
+	list.SetSortByAttention(true)
+	require.True(t, list.SortByAttention())
+	require.Equal(t, instances[1], list.GetSelectedInstance(), "the selection follows the instance")
+	require.Equal(t, []*session.Instance{instances[0], instances[1], instances[2], instances[3]}, list.GetInstances(),
+		"the created order is kept")
+	view := ansi.Strip(list.String())
+	require.Contains(t, view, "Instances by attention")
+	require.Less(t, strings.Index(view, "task-03"), strings.Index(view, "task-02"))
+	require.Less(t, strings.Index(view, "task-04"), strings.Index(view, "task-01"))
+
+	// Ready, then the running ones in created order, then paused.
+	list.SetSelectedInstance(0)
+	require.Equal(t, instances[2], list.GetSelectedInstance())
+	list.Down()
+	require.Equal(t, instances[1], list.GetSelectedInstance())
+	list.Down()
+	require.Equal(t, instances[3], list.GetSelectedInstance())
+	list.Down()
+	require.Equal(t, instances[0], list.GetSelectedInstance())
+
+	instances[0].Status = session.Ready
+	require.Equal(t, instances[0], list.GetSelectedInstance(), "the selection follows the instance when it moves")
+
+	list.SetSortByAttention(false)
+	require.Equal(t, instances[0], list.GetSelectedInstance())
+	require.NotContains(t, ansi.Strip(list.String()), "by attention")
+}
+
+func TestListGroups(t *testing.T) {
+	list := newTestList(t, 5, 60, 60)
+	instances := list.GetInstances()
+	instances[0].Group = "api"
+	instances[1].Group = "web"
+	instances[2].Group = "api"
+
+	titles := func() []string {
+		var titles []string
+		for _, row := range list.rows() {
+			if row.instance == nil {
+				titles = append(titles, "["+row.group+"]")
+			} else {
+				titles = append(titles, row.instance.Title)
+			}
+		}
+		return titles
+	}
+
+	t.Run("instances are listed under their group's header", func(t *testing.T) {
+		require.Equal(t, []string{"[api]", "task-01", "task-03", "[web]", "task-02", "[]", "task-04", "task-05"},
+			titles())
+		view := ansi.Strip(list.String())
+		require.Contains(t, view, "▾ api (2)")
+		require.Contains(t, view, "▾ Ungrouped (2)")
+		require.Less(t, strings.Index(view, "task-03"), strings.Index(view, "task-02"))
+		require.Equal(t, 5, list.NumInstances())
+	})
+
+	t.Run("navigation moves across headers and groups", func(t *testing.T) {
+		list.SetSelectedInstance(1)
+		require.Equal(t, instances[2], list.GetSelectedInstance())
+		list.Down()
+		require.Nil(t, list.GetSelectedInstance(), "the web header is selected")
+		require.Equal(t, "web", list.SelectedGroup())
+		list.Down()
+		require.Equal(t, instances[1], list.GetSelectedInstance())
+	})
+
+	t.Run("collapsing hides the group's instances and selects its header", func(t *testing.T) {
+		list.SetSelectedInstance(0)
+		require.True(t, list.ToggleGroup())
+		require.Nil(t, list.GetSelectedInstance())
+		require.Equal(t, "api", list.SelectedGroup())
+		require.Equal(t, []string{"[api]", "[web]", "task-02", "[]", "task-04", "task-05"}, titles())
+		require.Equal(t, []string{"api"}, list.CollapsedGroups())
+		require.Contains(t, ansi.Strip(list.String()), "▸ api (2)")
+		require.Equal(t, 3, list.NumInstances())
+	})
+
+	t.Run("navigation skips collapsed groups", func(t *testing.T) {
+		list.Down()
+		require.Equal(t, "web", list.SelectedGroup())
+		list.Down()
+		require.Equal(t, instances[1], list.GetSelectedInstance())
+		require.True(t, list.ToggleGroup())
+		require.Equal(t, "web", list.SelectedGroup())
+
+		list.Down()
+		require.Nil(t, list.GetSelectedInstance(), "the ungrouped header is selected")
+		list.Down()
+		require.Equal(t, instances[3], list.GetSelectedInstance())
+		list.Up()
+		list.Up()
+		list.Up()
+		require.Equal(t, "api", list.SelectedGroup())
+		list.Up()
+		require.Equal(t, "api", list.SelectedGroup(), "the first header is the top")
+
+		// Instances are numbered among the shown instances, so jumping to the first one skips the collapsed groups.
+		list.SetSelectedInstance(0)
+		require.Equal(t, instances[3], list.GetSelectedInstance())
+	})
+
+	t.Run("selecting a hidden instance expands its group", func(t *testing.T) {
+		require.True(t, list.SelectInstance(instances[1]))
+		require.Equal(t, instances[1], list.GetSelectedInstance())
+		require.Equal(t, []string{"api"}, list.CollapsedGroups())
+	})
+
+	t.Run("expanding keeps the header selected", func(t *testing.T) {
+		list.SetSelectedInstance(0)
+		list.Up()
+		list.Up()
+		require.Equal(t, "api", list.SelectedGroup())
+		require.True(t, list.ToggleGroup())
+		require.Nil(t, list.GetSelectedInstance())
+		require.Empty(t, list.CollapsedGroups())
+		list.Down()
+		require.Equal(t, instances[0], list.GetSelectedInstance())
+	})
+
+	t.Run("collapsed groups are restored", func(t *testing.T) {
+		list.SetCollapsedGroups([]string{"web", "gone"})
+		require.Equal(t, []string{"web"}, list.CollapsedGroups(), "groups without instances are dropped")
+		require.NotContains(t, titles(), "task-02")
+	})
+
+	t.Run("headers are not instances", func(t *testing.T) {
+		for y := range strings.Split(list.String(), "\n") {
+			if index, ok := list.InstanceAt(y); ok {
+				require.Less(t, index, list.NumInstances())
+			}
+		}
+		_, ok := list.InstanceAt(listHeaderHeight)
+		require.False(t, ok, "the first line is the api header")
+	})
+
+	t.Run("without groups there are no headers", func(t *testing.T) {
+		plain := newTestList(t, 2, 60, 60)
+		require.False(t, plain.ToggleGroup())
+		require.Equal(t, 2, len(plain.rows()))
+		require.NotContains(t, ansi.Strip(plain.String()), ungroupedHeader)
+	})
+}
+
+func TestRenderGroupHeader(t *testing.T) {
+	s := spinner.New()
+	renderer := &InstanceRenderer{spinner: &s}
+	renderer.setWidth(50)
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "task", Path: ".", Program: "claude"})
+	require.NoError(t, err)
+	width := lipgloss.Width(renderer.RenderCompact(instance, 1, false))
+
+	for _, group := range []string{"api", "", strings.Repeat("分组", 40)} {
+		for _, collapsed := range []bool{false, true} {
+			header := renderer.RenderGroupHeader(group, 3, collapsed, false)
+			require.Equal(t, 1, lipgloss.Height(header))
+			require.Equal(t, width, lipgloss.Width(header), "group %q", group)
+			require.Contains(t, header, "(3)")
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"claude-squad/session"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LogsPane shows the output history of the selected instance, including output that has scrolled out of tmux's
+// scrollback. It scrolls independently of the preview.
+type LogsPane struct {
+	viewport viewport.Model
+	width    int
+	height   int
+
+	instance *session.Instance
+	lines    []string
+	// version is the version of the instance's output history that lines were read from.
+	version int
+}
+
+func NewLogsPane() *LogsPane {
+	return &LogsPane{
+		viewport: viewport.New(0, 0),
+	}
+}
+
+func (l *LogsPane) SetSize(width, height int) {
+	l.width = width
+	l.height = height
+	l.viewport.Width = width
+	l.viewport.Height = height
+	l.setContent()
+}
+
+// SetInstance shows the output history of instance. instance may be nil.
+func (l *LogsPane) SetInstance(instance *session.Instance) {
+	changed := instance != l.instance
+	if !changed && (instance == nil || instance.OutputHistory().Version() == l.version) {
+		return
+	}
+	l.instance = instance
+	l.lines, l.version = nil, 0
+	if instance != nil {
+		l.lines, l.version = instance.OutputHistory().Lines()
+	}
+
+	l.setContent()
+	if changed {
+		l.viewport.GotoBottom()
+	}
+}
+
+// setContent shows the lines in the viewport. The pane keeps following the newest output unless it was
+// scrolled up.
+func (l *LogsPane) setContent() {
+	if len(l.lines) == 0 {
+		l.viewport.SetContent(lipgloss.Place(l.width, l.height, lipgloss.Center, lipgloss.Center,
+			"No output recorded yet"))
+		l.viewport.GotoTop()
+		return
+	}
+	following := l.viewport.AtBottom()
+	l.viewport.SetContent(strings.Join(l.lines, "\n"))
+	if following {
+		l.viewport.GotoBottom()
+	}
+}
+
+// ScrollUp scrolls towards older output.
+func (l *LogsPane) ScrollUp() {
+	l.viewport.LineUp(1)
+}
+
+// ScrollDown scrolls towards newer output.
+func (l *LogsPane) ScrollDown() {
+	l.viewport.LineDown(1)
+}
+
+func (l *LogsPane) String() string {
+	return l.viewport.View()
+}
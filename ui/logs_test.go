@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"claude-squad/session"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogsTab(t *testing.T) {
+	instance := &session.Instance{Title: "logs-test"}
+	var capture strings.Builder
+	for i := 1; i <= 100; i++ {
+		fmt.Fprintf(&capture, "output %d\n", i)
+	}
+	instance.OutputHistory().Merge(capture.String())
+
+	w := NewTabbedWindow(NewPreviewPane(), NewDiffPane())
+	w.SetSize(100, 20)
+
+	// The history is only shown while the logs tab is active.
+	w.UpdateLogs(instance)
+	assert.NotContains(t, ansi.Strip(w.String()), "output 100")
+
+	require.True(t, w.SetActiveTab(LogsTab))
+	w.UpdateLogs(instance)
+	assert.Contains(t, ansi.Strip(w.String()), "output 100", "starts at the newest output")
+
+	t.Run("scrolls independently and stops following the output", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			w.ScrollUp()
+		}
+		out := ansi.Strip(w.String())
+		assert.NotContains(t, out, "output 100")
+		assert.Contains(t, out, "output 50")
+
+		instance.OutputHistory().Merge(capture.String() + "output 101\n")
+		w.UpdateLogs(instance)
+		assert.NotContains(t, ansi.Strip(w.String()), "output 101")
+	})
+
+	t.Run("no instance", func(t *testing.T) {
+		w.UpdateLogs(nil)
+		assert.Contains(t, ansi.Strip(w.String()), "No output recorded yet")
+	})
+}
@@ -2,11 +2,13 @@ package ui
 
 import (
 	"claude-squad/keys"
+	"slices"
 	"strings"
 
 	"claude-squad/session"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
 var keyStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{
@@ -47,7 +49,10 @@ type Menu struct {
 	height, width int
 	state         MenuState
 	instance      *session.Instance
-	isInDiffTab   bool
+	// activeTab is the tab of the tabbed window that is shown, e.g. DiffTab.
+	activeTab int
+	// items are the options to show and their order, from the config. Nil shows the default options.
+	items []keys.KeyName
 
 	// keyDown is the key which is pressed. The default is -1.
 	keyDown keys.KeyName
@@ -57,12 +62,68 @@ var defaultMenuOptions = []keys.KeyName{keys.KeyNew, keys.KeyPrompt, keys.KeyHel
 var newInstanceMenuOptions = []keys.KeyName{keys.KeySubmitName}
 var promptMenuOptions = []keys.KeyName{keys.KeySubmitName}
 
+// extraOptions can be shown with or without a selected instance, but only if they are configured with SetItems.
+var extraOptions = []keys.KeyName{keys.KeyTemplate, keys.KeyRemote, keys.KeyShowArchived, keys.KeySort, keys.KeySearch,
+	keys.KeyNewInRepo, keys.KeyFold, keys.KeyAutoYes, keys.KeyNewInPlace, keys.KeySwitch,
+	keys.KeyTmuxSessions,
+}
+
+// instanceExtraOptions can be shown for a selected instance, but only if they are configured with SetItems.
+var instanceExtraOptions = []keys.KeyName{
+	keys.KeyPrompt, keys.KeyRefresh, keys.KeyActivity, keys.KeyCopyBranch, keys.KeyCopyPath, keys.KeyPauseAll,
+	keys.KeyResumeAll, keys.KeyCompact, keys.KeyPrevTab, keys.KeyArchive, keys.KeySendPrompt,
+	keys.KeyInterrupt, keys.KeyNote, keys.KeyResumeOpen, keys.KeyResend, keys.KeyRebase, keys.KeyColor,
+	keys.KeyGroup, keys.KeyCheckpoint, keys.KeyObserve, keys.KeyPin, keys.KeyPromptTemplate,
+	keys.KeyCopyDiff, keys.KeySaveDiff, keys.KeyForwardPrompt, keys.KeyCheckpoints,
+}
+
+// diffExtraOptions can be shown in the diff tab, but only if they are configured with SetItems.
+var diffExtraOptions = []keys.KeyName{
+	keys.KeyDiffFiles, keys.KeyNextFile, keys.KeyPrevFile, keys.KeyNextHunk, keys.KeyPrevHunk, keys.KeyDiffWhitespace,
+	keys.KeyDiffMode,
+}
+
+// menuGroup is a kind of option. Consecutive options of the same group are separated from other groups by a bar.
+type menuGroup int
+
+const (
+	groupManage menuGroup = iota
+	groupAction
+	groupDiff
+	groupTools
+	groupSystem
+	groupOther
+)
+
+func groupOf(k keys.KeyName) menuGroup {
+	switch k {
+	case keys.KeyNew, keys.KeyPrompt, keys.KeyTemplate, keys.KeyRemote, keys.KeyNewInRepo, keys.KeyNewInPlace,
+		keys.KeyKill:
+		return groupManage
+	case keys.KeyEnter, keys.KeySubmit, keys.KeyCheckout, keys.KeyResume, keys.KeyRestart, keys.KeyArchive,
+		keys.KeySendPrompt, keys.KeyInterrupt, keys.KeyResumeOpen, keys.KeyResend, keys.KeyRebase, keys.KeyCheckpoint,
+		keys.KeyPromptTemplate, keys.KeyForwardPrompt, keys.KeyCheckpoints:
+		return groupAction
+	case keys.KeyShiftUp, keys.KeyDiffFiles, keys.KeyNextFile, keys.KeyPrevFile, keys.KeyNextHunk, keys.KeyPrevHunk,
+		keys.KeyDiffWhitespace, keys.KeyDiffMode:
+		return groupDiff
+	case keys.KeyRefresh, keys.KeyActivity, keys.KeyCopyBranch, keys.KeyCopyPath, keys.KeyPauseAll, keys.KeyResumeAll,
+		keys.KeyCompact, keys.KeyShowArchived, keys.KeyNote, keys.KeySort, keys.KeySearch, keys.KeyColor, keys.KeyGroup,
+		keys.KeyFold, keys.KeyObserve, keys.KeyPin, keys.KeyCopyDiff, keys.KeySaveDiff,
+		keys.KeyAutoYes, keys.KeySwitch, keys.KeyTmuxSessions:
+		return groupTools
+	case keys.KeyTab, keys.KeyPrevTab, keys.KeyHelp, keys.KeyQuit:
+		return groupSystem
+	}
+	return groupOther
+}
+
 func NewMenu() *Menu {
 	return &Menu{
-		options:     defaultMenuOptions,
-		state:       StateEmpty,
-		isInDiffTab: false,
-		keyDown:     -1,
+		options:   defaultMenuOptions,
+		state:     StateEmpty,
+		activeTab: PreviewTab,
+		keyDown:   -1,
 	}
 }
 
@@ -74,6 +135,17 @@ func (m *Menu) ClearKeydown() {
 	m.keyDown = -1
 }
 
+// SetItems sets which options the menu shows and in what order. Options that don't apply to the current state
+// are left out. The options for naming a new instance and entering a prompt are always shown. Nil or empty items
+// restore the default options.
+func (m *Menu) SetItems(items []keys.KeyName) {
+	if len(items) == 0 {
+		items = nil
+	}
+	m.items = items
+	m.updateOptions()
+}
+
 // SetState updates the menu state and options accordingly
 func (m *Menu) SetState(state MenuState) {
 	m.state = state
@@ -94,9 +166,9 @@ func (m *Menu) SetInstance(instance *session.Instance) {
 	m.updateOptions()
 }
 
-// SetInDiffTab updates whether we're currently in the diff tab
-func (m *Menu) SetInDiffTab(inDiffTab bool) {
-	m.isInDiffTab = inDiffTab
+// SetActiveTab updates which tab of the tabbed window is shown, so the menu can offer that tab's options.
+func (m *Menu) SetActiveTab(tab int) {
+	m.activeTab = tab
 	m.updateOptions()
 }
 
@@ -115,25 +187,76 @@ func (m *Menu) updateOptions() {
 		}
 	case StateNewInstance:
 		m.options = newInstanceMenuOptions
+		return
 	case StatePrompt:
 		m.options = promptMenuOptions
+		return
+	}
+
+	if m.items != nil {
+		m.options = m.configuredOptions()
 	}
 }
 
+// configuredOptions returns the configured items that apply to the current state, in the configured order.
+func (m *Menu) configuredOptions() []keys.KeyName {
+	available := make(map[keys.KeyName]bool)
+	for _, k := range m.options {
+		available[k] = true
+	}
+	if m.state == StateEmpty || m.state == StateDefault {
+		for _, k := range extraOptions {
+			available[k] = true
+		}
+	}
+	if m.state == StateDefault && m.instance != nil {
+		for _, k := range instanceExtraOptions {
+			available[k] = true
+		}
+		if m.activeTab == DiffTab {
+			for _, k := range diffExtraOptions {
+				available[k] = true
+			}
+		}
+	}
+
+	options := make([]keys.KeyName, 0, len(m.items))
+	for _, k := range m.items {
+		if available[k] {
+			options = append(options, k)
+			// Show each option once even if it is configured twice.
+			available[k] = false
+		}
+	}
+	return options
+}
+
 func (m *Menu) addInstanceOptions() {
 	// Instance management group
 	options := []keys.KeyName{keys.KeyNew, keys.KeyKill}
 
 	// Action group
 	actionGroup := []keys.KeyName{keys.KeyEnter, keys.KeySubmit}
-	if m.instance.Status == session.Paused {
+	switch m.instance.Status {
+	case session.Paused:
 		actionGroup = append(actionGroup, keys.KeyResume)
-	} else {
+	case session.Dead, session.Exited:
+		actionGroup = append(actionGroup, keys.KeyRestart)
+	case session.Archived:
+		// An archived instance has no session to open, only its branch.
+		actionGroup = []keys.KeyName{keys.KeySubmit, keys.KeyArchive}
+	default:
 		actionGroup = append(actionGroup, keys.KeyCheckout)
 	}
+	if m.instance.InPlace() {
+		// An in-place instance has no branch of its own to push or check out.
+		actionGroup = slices.DeleteFunc(actionGroup, func(k keys.KeyName) bool {
+			return k == keys.KeySubmit || k == keys.KeyCheckout
+		})
+	}
 
-	// Navigation group (when in diff tab)
-	if m.isInDiffTab {
+	// Navigation group (when in a scrollable tab other than the preview)
+	if m.activeTab == DiffTab || m.activeTab == ActivityTab || m.activeTab == LogsTab {
 		actionGroup = append(actionGroup, keys.KeyShiftUp)
 	}
 
@@ -147,6 +270,22 @@ func (m *Menu) addInstanceOptions() {
 	m.options = options
 }
 
+// maxMenuTitleWidth is the width the selected instance's title is cut to in the menu.
+const maxMenuTitleWidth = 20
+
+// instanceLabel returns the selected instance's title after its icon, in its color, or "" if there is no selected
+// instance or it has neither a color nor an icon.
+func (m *Menu) instanceLabel() string {
+	if m.state != StateDefault || m.instance == nil {
+		return ""
+	}
+	if m.instance.Icon == "" && session.ColorHex(m.instance.Color) == "" {
+		return ""
+	}
+	title := runewidth.Truncate(m.instance.Title, maxMenuTitleWidth, "...")
+	return renderTitle(m.instance, title, descStyle)
+}
+
 // SetSize sets the width of the window. The menu will be centered horizontally within this width.
 func (m *Menu) SetSize(width, height int) {
 	m.width = width
@@ -154,19 +293,35 @@ func (m *Menu) SetSize(width, height int) {
 }
 
 func (m *Menu) String() string {
+	// Leave out options from the end until the menu fits on one line rather than letting it wrap.
+	options := m.options
+	text := m.render(options, false)
+	for len(options) > 1 && m.width > 0 && lipgloss.Width(text) > m.width {
+		options = options[:len(options)-1]
+		text = m.render(options, true)
+	}
+
+	centeredMenuText := menuStyle.Render(text)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, centeredMenuText)
+}
+
+// render renders options on one line. Truncated adds an ellipsis to show that options were left out.
+func (m *Menu) render(options []keys.KeyName, truncated bool) string {
 	var s strings.Builder
 
-	// Define group boundaries
-	groups := []struct {
-		start int
-		end   int
-	}{
-		{0, 2}, // Instance management group (n, d)
-		{2, 5}, // Action group (enter, submit, pause/resume)
-		{6, 8}, // System group (tab, help, q)
+	// The empty state highlights creating an instance, other states the actions on the selected instance.
+	highlighted := groupAction
+	if m.state == StateEmpty {
+		highlighted = groupManage
+	}
+
+	// The selected instance is named first if it has a color or icon, to tell which one the actions are for.
+	if label := m.instanceLabel(); label != "" {
+		s.WriteString(label)
+		s.WriteString(sepStyle.Render(verticalSeparator))
 	}
 
-	for i, k := range m.options {
+	for i, k := range options {
 		binding := keys.GlobalkeyBindings[k]
 
 		var (
@@ -180,17 +335,7 @@ func (m *Menu) String() string {
 			localDescStyle = localDescStyle.Underline(true)
 		}
 
-		var inActionGroup bool
-		switch m.state {
-		case StateEmpty:
-			// For empty state, the action group is the first group
-			inActionGroup = i <= 1
-		default:
-			// For other states, the action group is the second group
-			inActionGroup = i >= groups[1].start && i < groups[1].end
-		}
-
-		if inActionGroup {
+		if groupOf(k) == highlighted {
 			s.WriteString(localActionStyle.Render(binding.Help().Key))
 			s.WriteString(" ")
 			s.WriteString(localActionStyle.Render(binding.Help().Desc))
@@ -201,21 +346,18 @@ func (m *Menu) String() string {
 		}
 
 		// Add appropriate separator
-		if i != len(m.options)-1 {
-			isGroupEnd := false
-			for _, group := range groups {
-				if i == group.end-1 {
-					s.WriteString(sepStyle.Render(verticalSeparator))
-					isGroupEnd = true
-					break
-				}
-			}
-			if !isGroupEnd {
+		if i != len(options)-1 {
+			if groupOf(options[i+1]) != groupOf(k) {
+				s.WriteString(sepStyle.Render(verticalSeparator))
+			} else {
 				s.WriteString(sepStyle.Render(separator))
 			}
 		}
 	}
 
-	centeredMenuText := menuStyle.Render(s.String())
-	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, centeredMenuText)
+	if truncated {
+		s.WriteString(sepStyle.Render(separator))
+		s.WriteString(descStyle.Render("…"))
+	}
+	return s.String()
 }
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"claude-squad/keys"
+	"claude-squad/session"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMenuInstance(t *testing.T) *session.Instance {
+	t.Helper()
+	instance, err := session.NewInstance(session.InstanceOptions{Title: "test", Path: ".", Program: "claude"})
+	require.NoError(t, err)
+	instance.Status = session.Running
+	return instance
+}
+
+func TestMenuItems(t *testing.T) {
+	items, unknown := keys.ParseActionNames([]string{"quit", "files", "bogus", "kill", "new", "copy-branch"})
+	assert.Equal(t, []string{"bogus"}, unknown)
+
+	menu := NewMenu()
+	menu.SetItems(items)
+
+	t.Run("empty state only shows items that apply", func(t *testing.T) {
+		menu.SetInstance(nil)
+		assert.Equal(t, []keys.KeyName{keys.KeyQuit, keys.KeyNew}, menu.options)
+	})
+
+	t.Run("selected instance shows items in configured order", func(t *testing.T) {
+		menu.SetInstance(newTestMenuInstance(t))
+		menu.SetActiveTab(PreviewTab)
+		assert.Equal(t, []keys.KeyName{keys.KeyQuit, keys.KeyKill, keys.KeyNew, keys.KeyCopyBranch}, menu.options)
+	})
+
+	t.Run("diff items are shown in the diff tab", func(t *testing.T) {
+		menu.SetActiveTab(DiffTab)
+		assert.Equal(t, []keys.KeyName{keys.KeyQuit, keys.KeyDiffFiles, keys.KeyKill, keys.KeyNew, keys.KeyCopyBranch},
+			menu.options)
+	})
+
+	t.Run("scrolling is offered in the logs tab", func(t *testing.T) {
+		menu.SetItems([]keys.KeyName{keys.KeyShiftUp, keys.KeyDiffFiles, keys.KeyPrevTab})
+		menu.SetActiveTab(LogsTab)
+		assert.Equal(t, []keys.KeyName{keys.KeyShiftUp, keys.KeyPrevTab}, menu.options)
+		menu.SetItems(items)
+	})
+
+	t.Run("naming and prompting ignore the configured items", func(t *testing.T) {
+		menu.SetState(StateNewInstance)
+		assert.Equal(t, newInstanceMenuOptions, menu.options)
+		menu.SetState(StatePrompt)
+		assert.Equal(t, promptMenuOptions, menu.options)
+	})
+
+	t.Run("no items restores the default menu", func(t *testing.T) {
+		menu.SetState(StateDefault)
+		menu.SetActiveTab(PreviewTab)
+		menu.SetItems(nil)
+		assert.Equal(t, []keys.KeyName{keys.KeyNew, keys.KeyKill, keys.KeyEnter, keys.KeySubmit, keys.KeyCheckout,
+			keys.KeyTab, keys.KeyHelp, keys.KeyQuit}, menu.options)
+	})
+}
+
+func TestMenuString(t *testing.T) {
+	menu := NewMenu()
+	menu.SetInstance(newTestMenuInstance(t))
+
+	t.Run("groups are separated by bars", func(t *testing.T) {
+		menu.SetSize(200, 1)
+		text := strings.TrimSpace(ansi.Strip(menu.String()))
+		assert.Equal(t, "n new • D kill │ ↵/o open • p push branch • c checkout │ tab switch tab • ? help • q quit", text)
+	})
+
+	t.Run("options that don't fit are left out", func(t *testing.T) {
+		menu.SetSize(40, 1)
+		text := strings.TrimSpace(ansi.Strip(menu.String()))
+		assert.Equal(t, "n new • D kill │ ↵/o open • …", text)
+		assert.Equal(t, 1, strings.Count(menu.String(), "\n")+1)
+	})
+}
+
+func TestMenuInstanceLabel(t *testing.T) {
+	menu := NewMenu()
+	instance := newTestMenuInstance(t)
+	menu.SetInstance(instance)
+	menu.SetSize(200, 1)
+	plain := strings.TrimSpace(ansi.Strip(menu.String()))
+
+	instance.Color = "green"
+	assert.Equal(t, "test │ "+plain, strings.TrimSpace(ansi.Strip(menu.String())))
+
+	instance.Icon = "🐛"
+	instance.Title = strings.Repeat("x", 30)
+	assert.Equal(t, "🐛 "+strings.Repeat("x", maxMenuTitleWidth-3)+"... │ "+plain,
+		strings.TrimSpace(ansi.Strip(menu.String())))
+
+	instance.Icon = ""
+	instance.Color = "chartreuse"
+	assert.Equal(t, plain, strings.TrimSpace(ansi.Strip(menu.String())), "an unknown color isn't shown")
+}
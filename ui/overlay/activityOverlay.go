@@ -0,0 +1,117 @@
+package overlay
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	activityTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
+	activityHintStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#808080"))
+)
+
+// ActivityOverlay is a scrollable overlay for browsing an instance's activity log.
+type ActivityOverlay struct {
+	// Whether the overlay has been dismissed
+	Dismissed bool
+
+	title    string
+	lines    []string
+	viewport viewport.Model
+	width    int
+	// atTop starts the overlay at the first line instead of the most recent one.
+	atTop bool
+	// hint replaces the default hint about the keys of the overlay if it isn't empty.
+	hint string
+}
+
+// NewActivityOverlay creates an overlay with the given title showing lines, oldest first. It starts scrolled to
+// the most recent line.
+func NewActivityOverlay(title string, lines []string) *ActivityOverlay {
+	return &ActivityOverlay{
+		title:    title,
+		lines:    lines,
+		viewport: viewport.New(0, 0),
+	}
+}
+
+// SetSize sets the outer size of the overlay.
+func (a *ActivityOverlay) SetSize(width, height int) {
+	a.width = width
+	// Account for the border, padding, title and hint.
+	a.viewport.Width = max(width-6, 1)
+	a.viewport.Height = max(height-8, 1)
+	a.setContent()
+}
+
+// setContent wraps the lines to the viewport width so each wrapped line scrolls on its own.
+func (a *ActivityOverlay) setContent() {
+	content := "No activity recorded yet."
+	if len(a.lines) > 0 {
+		content = strings.Join(a.lines, "\n")
+	}
+	a.viewport.SetContent(lipgloss.NewStyle().Width(a.viewport.Width).Render(content))
+	if a.atTop {
+		a.viewport.GotoTop()
+	} else {
+		a.viewport.GotoBottom()
+	}
+}
+
+// StartAtTop makes the overlay start scrolled to the first line, for content that is read from the top.
+func (a *ActivityOverlay) StartAtTop() {
+	a.atTop = true
+	a.setContent()
+}
+
+// SetHint replaces the hint about the keys of the overlay, for overlays with keys of their own.
+func (a *ActivityOverlay) SetHint(hint string) {
+	a.hint = hint
+}
+
+// HandleKeyPress scrolls the overlay. Returns true if the overlay should be closed.
+func (a *ActivityOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "esc", "q":
+		a.Dismissed = true
+		return true
+	case "up", "k":
+		a.viewport.LineUp(1)
+	case "down", "j":
+		a.viewport.LineDown(1)
+	case "pgup":
+		a.viewport.ViewUp()
+	case "pgdown":
+		a.viewport.ViewDown()
+	case "home", "g":
+		a.viewport.GotoTop()
+	case "end", "G":
+		a.viewport.GotoBottom()
+	}
+	return false
+}
+
+// Render renders the activity overlay
+func (a *ActivityOverlay) Render(opts ...WhitespaceOption) string {
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2).
+		Width(a.width)
+
+	hint := "↑/↓ to scroll, esc to close"
+	if a.hint != "" {
+		hint = a.hint
+	}
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		activityTitleStyle.Render(a.title),
+		"",
+		a.viewport.View(),
+		"",
+		activityHintStyle.Render(hint),
+	)
+	return style.Render(content)
+}
@@ -0,0 +1,70 @@
+package overlay
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivityOverlay(t *testing.T) {
+	newLines := func(n int) []string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = fmt.Sprintf("event %d", i)
+		}
+		return lines
+	}
+	key := func(s string) tea.KeyMsg {
+		if s == "esc" {
+			return tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	t.Run("starts at the most recent event", func(t *testing.T) {
+		a := NewActivityOverlay("Activity", newLines(50))
+		a.SetSize(60, 20)
+
+		view := ansi.Strip(a.Render())
+		assert.Contains(t, view, "event 49")
+		assert.NotContains(t, view, "event 0\n")
+	})
+
+	t.Run("scrolls to older events", func(t *testing.T) {
+		a := NewActivityOverlay("Activity", newLines(50))
+		a.SetSize(60, 20)
+
+		assert.False(t, a.HandleKeyPress(key("g")))
+
+		view := ansi.Strip(a.Render())
+		assert.Contains(t, view, "event 0 ")
+		assert.NotContains(t, view, "event 49")
+	})
+
+	t.Run("can start at the first line", func(t *testing.T) {
+		a := NewActivityOverlay("Keys", newLines(50))
+		a.StartAtTop()
+		a.SetSize(60, 20)
+
+		view := ansi.Strip(a.Render())
+		assert.Contains(t, view, "event 0 ")
+		assert.NotContains(t, view, "event 49")
+	})
+
+	t.Run("shows a placeholder without events", func(t *testing.T) {
+		a := NewActivityOverlay("Activity", nil)
+		a.SetSize(60, 20)
+
+		assert.Contains(t, ansi.Strip(a.Render()), "No activity recorded yet.")
+	})
+
+	t.Run("closes on esc", func(t *testing.T) {
+		a := NewActivityOverlay("Activity", newLines(1))
+
+		assert.True(t, a.HandleKeyPress(key("esc")))
+		assert.True(t, a.Dismissed)
+	})
+}
@@ -2,6 +2,7 @@ package overlay
 
 import (
 	"claude-squad/ui/autocomplete"
+	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textarea"
@@ -9,6 +10,13 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// DefaultSubmitKey is the key that submits the prompt from anywhere in the overlay. Terminals
+// can't tell ctrl+enter apart from enter, so a control key is used instead.
+const DefaultSubmitKey = "ctrl+d"
+
+// editKey asks to edit the file of the highlighted suggestion.
+const editKey = "ctrl+e"
+
 // AutocompleteInputOverlay extends TextInputOverlay with tab-completion support.
 type AutocompleteInputOverlay struct {
 	textarea      textarea.Model
@@ -18,12 +26,21 @@ type AutocompleteInputOverlay struct {
 	Canceled      bool
 	OnSubmit      func()
 	width, height int
+	// submitKey submits the prompt regardless of focus. Plain enter in the textarea inserts a newline.
+	submitKey string
 
 	// Autocomplete support
 	autocompleter      autocomplete.Autocompleter
 	suggestions        []autocomplete.Suggestion
 	selectedIndex      int
 	showingSuggestions bool
+
+	// Prompt history support. historyIndex is len(history) when not browsing the history.
+	history      []string
+	historyIndex int
+
+	// editPath is the file of the suggestion the edit key was pressed on, until it is taken with TakeEditPath.
+	editPath string
 }
 
 // NewAutocompleteInputOverlay creates a new text input overlay with autocomplete support.
@@ -48,7 +65,16 @@ func NewAutocompleteInputOverlay(title string, initialValue string, ac autocompl
 		Canceled:      false,
 		autocompleter: ac,
 		suggestions:   make([]autocomplete.Suggestion, 0),
+		submitKey:     DefaultSubmitKey,
+	}
+}
+
+// SetSubmitKey sets the key that submits the prompt, e.g. "ctrl+d". An empty key keeps the default.
+func (a *AutocompleteInputOverlay) SetSubmitKey(key string) {
+	if key == "" {
+		key = DefaultSubmitKey
 	}
+	a.submitKey = key
 }
 
 func (a *AutocompleteInputOverlay) SetSize(width, height int) {
@@ -60,6 +86,23 @@ func (a *AutocompleteInputOverlay) SetSize(width, height int) {
 // HandleKeyPress processes a key press and updates the state accordingly.
 // Returns true if the overlay should be closed.
 func (a *AutocompleteInputOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	if msg.Paste {
+		a.handlePaste(msg)
+		return false
+	}
+
+	if msg.String() == a.submitKey {
+		a.submit()
+		return true
+	}
+
+	if msg.String() == editKey && a.showingSuggestions {
+		a.editPath = a.suggestions[a.selectedIndex].Path
+		// The suggestions may change once the file is edited, so they are looked up again on the next tab.
+		a.hideSuggestions()
+		return false
+	}
+
 	switch msg.Type {
 	case tea.KeyTab:
 		value := a.textarea.Value()
@@ -112,6 +155,25 @@ func (a *AutocompleteInputOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
 		a.hideSuggestions()
 		return false
 
+	case tea.KeyUp, tea.KeyDown, tea.KeyCtrlP, tea.KeyCtrlN:
+		if a.FocusIndex != 0 {
+			return false
+		}
+		// Up/down only recall history when the input is empty or still shows a recalled prompt,
+		// otherwise they move the cursor within a multi-line prompt.
+		explicit := msg.Type == tea.KeyCtrlP || msg.Type == tea.KeyCtrlN
+		if explicit || a.textarea.Value() == "" || a.browsingHistory() {
+			if msg.Type == tea.KeyUp || msg.Type == tea.KeyCtrlP {
+				a.recallHistory(-1)
+			} else {
+				a.recallHistory(1)
+			}
+			return false
+		}
+		a.textarea, _ = a.textarea.Update(msg)
+		a.hideSuggestions()
+		return false
+
 	case tea.KeyEsc:
 		if a.showingSuggestions {
 			a.hideSuggestions()
@@ -121,12 +183,12 @@ func (a *AutocompleteInputOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
 		return true
 
 	case tea.KeyEnter:
-		// Enter always submits (use Shift+Enter for newline if needed)
-		a.Submitted = true
-		if a.OnSubmit != nil {
-			a.OnSubmit()
+		if a.FocusIndex == 1 {
+			// Enter button is focused, so submit.
+			a.submit()
+			return true
 		}
-		return true
+		fallthrough // Send enter key to textarea to insert a newline
 
 	default:
 		if a.FocusIndex == 0 {
@@ -138,6 +200,72 @@ func (a *AutocompleteInputOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
 	}
 }
 
+// submit marks the form as submitted and runs the submit callback.
+func (a *AutocompleteInputOverlay) submit() {
+	a.Submitted = true
+	if a.OnSubmit != nil {
+		a.OnSubmit()
+	}
+}
+
+// TakeEditPath returns the file of the suggestion that the edit key was pressed on, or "" if it wasn't pressed
+// since the last call.
+func (a *AutocompleteInputOverlay) TakeEditPath() string {
+	path := a.editPath
+	a.editPath = ""
+	return path
+}
+
+// SetHistory sets the previously sent prompts, oldest first, that can be recalled with up/down when
+// the input is empty, or with ctrl+p/ctrl+n at any time.
+func (a *AutocompleteInputOverlay) SetHistory(history []string) {
+	a.history = history
+	a.historyIndex = len(history)
+}
+
+// browsingHistory returns true if the input still shows an unedited prompt recalled from the history.
+func (a *AutocompleteInputOverlay) browsingHistory() bool {
+	return a.historyIndex < len(a.history) && a.textarea.Value() == a.history[a.historyIndex]
+}
+
+// recallHistory moves through the history by delta (-1 is older, +1 is newer) and shows the selected
+// prompt. Moving past the newest prompt clears the input.
+func (a *AutocompleteInputOverlay) recallHistory(delta int) {
+	if len(a.history) == 0 {
+		return
+	}
+	if !a.browsingHistory() {
+		a.historyIndex = len(a.history)
+	}
+
+	index := a.historyIndex + delta
+	if index < 0 || index > len(a.history) {
+		return
+	}
+	a.historyIndex = index
+
+	if index == len(a.history) {
+		a.textarea.SetValue("")
+	} else {
+		a.textarea.SetValue(a.history[index])
+	}
+	a.textarea.CursorEnd()
+	a.hideSuggestions()
+}
+
+// handlePaste inserts bracketed-paste content into the textarea verbatim. Pasted
+// newlines and leading slashes are treated as text, so a paste never submits
+// the form or opens the suggestions dropdown.
+func (a *AutocompleteInputOverlay) handlePaste(msg tea.KeyMsg) {
+	if a.FocusIndex != 0 {
+		return
+	}
+	text := strings.ReplaceAll(string(msg.Runes), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	a.textarea.InsertString(text)
+	a.hideSuggestions()
+}
+
 // triggerAutocomplete loads suggestions based on current input
 func (a *AutocompleteInputOverlay) triggerAutocomplete() {
 	if a.autocompleter == nil {
@@ -222,6 +350,9 @@ func (a *AutocompleteInputOverlay) Render() string {
 		Background(lipgloss.Color("62")).
 		Foreground(lipgloss.Color("0"))
 
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240"))
+
 	suggestionStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("7"))
 
@@ -233,7 +364,8 @@ func (a *AutocompleteInputOverlay) Render() string {
 	a.textarea.SetWidth(a.width - 6) // Account for padding and borders
 
 	// Build the view
-	content := titleStyle.Render(a.Title) + "\n"
+	hint := hintStyle.Render(fmt.Sprintf(" (%s to submit, enter for newline)", a.submitKey))
+	content := lipgloss.JoinHorizontal(lipgloss.Top, titleStyle.Render(a.Title), hint) + "\n"
 	content += a.textarea.View() + "\n"
 
 	// Show suggestions dropdown if active
@@ -255,6 +387,9 @@ func (a *AutocompleteInputOverlay) Render() string {
 		if len(a.suggestions) > maxShow {
 			content += suggestionStyle.Render("  ...") + "\n"
 		}
+		if a.suggestions[a.selectedIndex].Path != "" {
+			content += hintStyle.Render(fmt.Sprintf("  %s to edit the command", editKey)) + "\n"
+		}
 	}
 
 	content += "\n"
@@ -0,0 +1,217 @@
+package overlay
+
+import (
+	"claude-squad/ui/autocomplete"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticAutocompleter returns the same suggestions for every prefix.
+type staticAutocompleter struct {
+	suggestions []autocomplete.Suggestion
+}
+
+func (s staticAutocompleter) GetSuggestions(string) []autocomplete.Suggestion {
+	return s.suggestions
+}
+
+func (s staticAutocompleter) Reload() error {
+	return nil
+}
+
+func newTestAutocompleteOverlay() *AutocompleteInputOverlay {
+	ac := staticAutocompleter{suggestions: []autocomplete.Suggestion{{Value: "/fix-issue", Display: "fix-issue"}}}
+	a := NewAutocompleteInputOverlay("Enter prompt", "", ac)
+	a.SetSize(80, 10)
+	return a
+}
+
+func pasteMsg(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text), Paste: true}
+}
+
+func TestAutocompleteInputOverlayPaste(t *testing.T) {
+	t.Run("multi-line paste is inserted verbatim", func(t *testing.T) {
+		a := newTestAutocompleteOverlay()
+
+		closed := a.HandleKeyPress(pasteMsg("first line\r\nsecond line\nthird line"))
+
+		assert.False(t, closed)
+		assert.False(t, a.IsSubmitted())
+		assert.Equal(t, "first line\nsecond line\nthird line", a.GetValue())
+	})
+
+	t.Run("paste with a leading slash does not show suggestions", func(t *testing.T) {
+		a := newTestAutocompleteOverlay()
+
+		a.HandleKeyPress(pasteMsg("/fix\nthe bug"))
+
+		assert.False(t, a.showingSuggestions)
+		assert.Equal(t, "/fix\nthe bug", a.GetValue())
+	})
+
+	t.Run("enter button still submits pasted content", func(t *testing.T) {
+		a := newTestAutocompleteOverlay()
+		a.HandleKeyPress(pasteMsg("line one\nline two"))
+
+		a.HandleKeyPress(tea.KeyMsg{Type: tea.KeyTab})
+		require.Equal(t, 1, a.FocusIndex)
+		closed := a.HandleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.True(t, closed)
+		assert.True(t, a.IsSubmitted())
+		assert.Equal(t, "line one\nline two", a.GetValue())
+	})
+}
+
+func TestAutocompleteInputOverlaySubmit(t *testing.T) {
+	typeText := func(a *AutocompleteInputOverlay, text string) {
+		a.HandleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	}
+
+	t.Run("enter in the textarea inserts a newline", func(t *testing.T) {
+		a := newTestAutocompleteOverlay()
+		typeText(a, "first")
+
+		closed := a.HandleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+		typeText(a, "second")
+
+		assert.False(t, closed)
+		assert.False(t, a.IsSubmitted())
+		assert.Equal(t, "first\nsecond", a.GetValue())
+	})
+
+	t.Run("submit key submits from the textarea", func(t *testing.T) {
+		a := newTestAutocompleteOverlay()
+		submitted := false
+		a.OnSubmit = func() { submitted = true }
+		typeText(a, "fix the bug")
+
+		closed := a.HandleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlD})
+
+		assert.True(t, closed)
+		assert.True(t, a.IsSubmitted())
+		assert.True(t, submitted)
+		assert.Equal(t, "fix the bug", a.GetValue())
+	})
+
+	t.Run("custom submit key", func(t *testing.T) {
+		a := newTestAutocompleteOverlay()
+		a.SetSubmitKey("ctrl+s")
+		typeText(a, "fix the bug")
+
+		assert.False(t, a.HandleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlD}))
+		assert.True(t, a.HandleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlS}))
+		assert.True(t, a.IsSubmitted())
+	})
+
+	t.Run("enter button submits", func(t *testing.T) {
+		a := newTestAutocompleteOverlay()
+		typeText(a, "fix the bug")
+
+		a.HandleKeyPress(tea.KeyMsg{Type: tea.KeyTab})
+		closed := a.HandleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.True(t, closed)
+		assert.True(t, a.IsSubmitted())
+		assert.Equal(t, "fix the bug", a.GetValue())
+	})
+
+	t.Run("title shows the submit hint", func(t *testing.T) {
+		a := newTestAutocompleteOverlay()
+		assert.Contains(t, a.Render(), "ctrl+d to submit")
+	})
+}
+
+func TestAutocompleteInputOverlayHistory(t *testing.T) {
+	newOverlayWithHistory := func() *AutocompleteInputOverlay {
+		a := newTestAutocompleteOverlay()
+		a.SetHistory([]string{"oldest", "middle", "newest"})
+		return a
+	}
+	press := func(a *AutocompleteInputOverlay, keyType tea.KeyType) {
+		a.HandleKeyPress(tea.KeyMsg{Type: keyType})
+	}
+
+	t.Run("up recalls prompts newest first", func(t *testing.T) {
+		a := newOverlayWithHistory()
+
+		press(a, tea.KeyUp)
+		assert.Equal(t, "newest", a.GetValue())
+		press(a, tea.KeyUp)
+		assert.Equal(t, "middle", a.GetValue())
+		press(a, tea.KeyUp)
+		assert.Equal(t, "oldest", a.GetValue())
+		press(a, tea.KeyUp)
+		assert.Equal(t, "oldest", a.GetValue())
+	})
+
+	t.Run("down moves back towards an empty input", func(t *testing.T) {
+		a := newOverlayWithHistory()
+
+		press(a, tea.KeyUp)
+		press(a, tea.KeyUp)
+		press(a, tea.KeyDown)
+		assert.Equal(t, "newest", a.GetValue())
+		press(a, tea.KeyDown)
+		assert.Equal(t, "", a.GetValue())
+	})
+
+	t.Run("up does not replace a typed prompt", func(t *testing.T) {
+		a := newOverlayWithHistory()
+		a.HandleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("draft")})
+
+		press(a, tea.KeyUp)
+		assert.Equal(t, "draft", a.GetValue())
+
+		press(a, tea.KeyCtrlP)
+		assert.Equal(t, "newest", a.GetValue())
+	})
+
+	t.Run("editing a recalled prompt stops browsing", func(t *testing.T) {
+		a := newOverlayWithHistory()
+
+		press(a, tea.KeyUp)
+		a.HandleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+		press(a, tea.KeyUp)
+
+		assert.Equal(t, "newest!", a.GetValue())
+	})
+
+	t.Run("history recall does not show suggestions", func(t *testing.T) {
+		a := newTestAutocompleteOverlay()
+		a.SetHistory([]string{"/fix-issue 42"})
+
+		press(a, tea.KeyUp)
+
+		assert.Equal(t, "/fix-issue 42", a.GetValue())
+		assert.False(t, a.showingSuggestions)
+	})
+}
+
+func TestAutocompleteInputOverlayEdit(t *testing.T) {
+	ac := staticAutocompleter{suggestions: []autocomplete.Suggestion{
+		{Value: "/fix-issue", Display: "fix-issue", Path: "/repo/.claude/commands/fix-issue.md"},
+		{Value: "/review", Display: "review", Path: "/repo/.claude/commands/review.md"},
+	}}
+	a := NewAutocompleteInputOverlay("Enter prompt", "/", ac)
+	a.SetSize(80, 10)
+	ctrlE := tea.KeyMsg{Type: tea.KeyCtrlE}
+
+	// Without suggestions the key does nothing.
+	assert.False(t, a.HandleKeyPress(ctrlE))
+	assert.Empty(t, a.TakeEditPath())
+
+	assert.False(t, a.HandleKeyPress(tea.KeyMsg{Type: tea.KeyTab}))
+	assert.False(t, a.HandleKeyPress(tea.KeyMsg{Type: tea.KeyTab}))
+	assert.Contains(t, a.Render(), "ctrl+e to edit the command")
+
+	assert.False(t, a.HandleKeyPress(ctrlE))
+	assert.Equal(t, "/repo/.claude/commands/review.md", a.TakeEditPath(), "the highlighted command is edited")
+	assert.Empty(t, a.TakeEditPath(), "the path is only taken once")
+	assert.False(t, a.showingSuggestions)
+	assert.Equal(t, "/review ", a.GetValue())
+}
@@ -1,6 +1,10 @@
 package overlay
 
 import (
+	"fmt"
+	"math"
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -23,6 +27,8 @@ type ConfirmationOverlay struct {
 	CancelKey string
 	// Custom styling options
 	borderColor lipgloss.Color
+	// deadline is when the overlay cancels itself. It is zero if there is no timeout.
+	deadline time.Time
 }
 
 // NewConfirmationOverlay creates a new confirmation dialog overlay with the given message
@@ -59,6 +65,34 @@ func (c *ConfirmationOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
 	}
 }
 
+// SetTimeout makes the overlay cancel itself if there is no input within timeout. A timeout of zero or less
+// disables it.
+func (c *ConfirmationOverlay) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		c.deadline = time.Time{}
+		return
+	}
+	c.deadline = time.Now().Add(timeout)
+}
+
+// Remaining returns how long until the overlay times out, and false if it has no timeout.
+func (c *ConfirmationOverlay) Remaining() (time.Duration, bool) {
+	if c.deadline.IsZero() {
+		return 0, false
+	}
+	remaining := time.Until(c.deadline)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// TimedOut returns true if the overlay has a timeout that has passed.
+func (c *ConfirmationOverlay) TimedOut() bool {
+	remaining, ok := c.Remaining()
+	return ok && remaining == 0
+}
+
 // Render renders the confirmation overlay
 func (c *ConfirmationOverlay) Render(opts ...WhitespaceOption) string {
 	style := lipgloss.NewStyle().
@@ -73,6 +107,12 @@ func (c *ConfirmationOverlay) Render(opts ...WhitespaceOption) string {
 		lipgloss.NewStyle().Bold(true).Render(c.CancelKey) + " or " +
 		lipgloss.NewStyle().Bold(true).Render("esc") + " to cancel"
 
+	if remaining, ok := c.Remaining(); ok {
+		seconds := int(math.Ceil(remaining.Seconds()))
+		content += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#808080")).
+			Render(fmt.Sprintf("Cancelling in %ds", seconds))
+	}
+
 	// Apply the border style and return
 	return style.Render(content)
 }
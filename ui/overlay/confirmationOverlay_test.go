@@ -0,0 +1,50 @@
+package overlay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmationOverlayTimeout(t *testing.T) {
+	t.Run("no timeout by default", func(t *testing.T) {
+		c := NewConfirmationOverlay("Kill session?")
+
+		_, ok := c.Remaining()
+		assert.False(t, ok)
+		assert.False(t, c.TimedOut())
+		assert.NotContains(t, ansi.Strip(c.Render()), "Cancelling")
+	})
+
+	t.Run("counts down to the deadline", func(t *testing.T) {
+		c := NewConfirmationOverlay("Kill session?")
+		c.SetTimeout(10 * time.Second)
+
+		remaining, ok := c.Remaining()
+		assert.True(t, ok)
+		assert.InDelta(t, 10*time.Second, remaining, float64(time.Second))
+		assert.False(t, c.TimedOut())
+		assert.Contains(t, ansi.Strip(c.Render()), "Cancelling in 10s")
+	})
+
+	t.Run("times out after the deadline", func(t *testing.T) {
+		c := NewConfirmationOverlay("Kill session?")
+		c.deadline = time.Now().Add(-time.Second)
+
+		remaining, _ := c.Remaining()
+		assert.Zero(t, remaining)
+		assert.True(t, c.TimedOut())
+	})
+
+	t.Run("zero disables the timeout", func(t *testing.T) {
+		c := NewConfirmationOverlay("Kill session?")
+		c.SetTimeout(time.Second)
+		c.SetTimeout(0)
+
+		assert.False(t, c.TimedOut())
+		_, ok := c.Remaining()
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,111 @@
+package overlay
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var formLabelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4")).Bold(true)
+
+// FormOverlay lets the user fill in a list of named single-line fields.
+type FormOverlay struct {
+	// Whether the form was submitted. It is false if the overlay was cancelled.
+	Submitted bool
+
+	title  string
+	names  []string
+	inputs []textinput.Model
+	// focus is the index of the focused field.
+	focus int
+	width int
+}
+
+// NewFormOverlay creates a form with the given title and an empty field for each of names, with the first one
+// focused.
+func NewFormOverlay(title string, names []string) *FormOverlay {
+	inputs := make([]textinput.Model, len(names))
+	for i := range names {
+		inputs[i] = textinput.New()
+		inputs[i].Prompt = "> "
+	}
+	f := &FormOverlay{title: title, names: names, inputs: inputs}
+	f.setFocus(0)
+	return f
+}
+
+// SetSize sets the outer size of the overlay. The form is as high as its fields need.
+func (f *FormOverlay) SetSize(width, height int) {
+	f.width = width
+	for i := range f.inputs {
+		f.inputs[i].Width = max(width-10, 1)
+	}
+}
+
+// setFocus focuses the field at index.
+func (f *FormOverlay) setFocus(index int) {
+	f.focus = index
+	for i := range f.inputs {
+		if i == index {
+			f.inputs[i].Focus()
+		} else {
+			f.inputs[i].Blur()
+		}
+	}
+}
+
+// HandleKeyPress processes a key press. Tab and the arrow keys move between the fields, enter moves to the next
+// field or submits the form on the last one, and esc cancels it. Returns true if the overlay should be closed.
+func (f *FormOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyEsc:
+		return true
+	case tea.KeyEnter:
+		if f.focus == len(f.inputs)-1 {
+			f.Submitted = true
+			return true
+		}
+		f.setFocus(f.focus + 1)
+	case tea.KeyTab, tea.KeyDown:
+		f.setFocus((f.focus + 1) % len(f.inputs))
+	case tea.KeyShiftTab, tea.KeyUp:
+		f.setFocus((f.focus + len(f.inputs) - 1) % len(f.inputs))
+	default:
+		f.inputs[f.focus], _ = f.inputs[f.focus].Update(msg)
+	}
+	return false
+}
+
+// Values returns the value of every field by name.
+func (f *FormOverlay) Values() map[string]string {
+	values := make(map[string]string, len(f.names))
+	for i, name := range f.names {
+		values[name] = f.inputs[i].Value()
+	}
+	return values
+}
+
+// Render renders the form overlay.
+func (f *FormOverlay) Render(opts ...WhitespaceOption) string {
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2).
+		Width(f.width)
+
+	fields := make([]string, len(f.inputs))
+	for i, input := range f.inputs {
+		fields[i] = formLabelStyle.Render(f.names[i]) + "\n" + input.View()
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		activityTitleStyle.Render(f.title),
+		"",
+		strings.Join(fields, "\n\n"),
+		"",
+		activityHintStyle.Render("tab to move, enter on the last field to send, esc to cancel"),
+	)
+	return style.Render(content)
+}
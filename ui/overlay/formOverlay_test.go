@@ -0,0 +1,51 @@
+package overlay
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormOverlay(t *testing.T) {
+	typeText := func(f *FormOverlay, s string) {
+		for _, r := range s {
+			assert.False(t, f.HandleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}))
+		}
+	}
+
+	t.Run("fills the fields in order and submits on the last one", func(t *testing.T) {
+		f := NewFormOverlay("fix test", []string{"file", "test"})
+		f.SetSize(60, 20)
+
+		typeText(f, "auth_test.go")
+		assert.False(t, f.HandleKeyPress(tea.KeyMsg{Type: tea.KeyEnter}), "enter moves to the next field")
+		typeText(f, "TestLogin")
+
+		rendered := ansi.Strip(f.Render())
+		assert.Contains(t, rendered, "fix test")
+		assert.Contains(t, rendered, "auth_test.go")
+
+		assert.True(t, f.HandleKeyPress(tea.KeyMsg{Type: tea.KeyEnter}))
+		assert.True(t, f.Submitted)
+		assert.Equal(t, map[string]string{"file": "auth_test.go", "test": "TestLogin"}, f.Values())
+	})
+
+	t.Run("tab wraps around the fields", func(t *testing.T) {
+		f := NewFormOverlay("fix test", []string{"file", "test"})
+		assert.False(t, f.HandleKeyPress(tea.KeyMsg{Type: tea.KeyShiftTab}))
+		typeText(f, "TestLogin")
+		assert.False(t, f.HandleKeyPress(tea.KeyMsg{Type: tea.KeyTab}))
+		typeText(f, "a.go")
+
+		assert.Equal(t, map[string]string{"file": "a.go", "test": "TestLogin"}, f.Values())
+	})
+
+	t.Run("esc cancels", func(t *testing.T) {
+		f := NewFormOverlay("fix test", []string{"file"})
+		typeText(f, "a.go")
+		assert.True(t, f.HandleKeyPress(tea.KeyMsg{Type: tea.KeyEsc}))
+		assert.False(t, f.Submitted)
+	})
+}
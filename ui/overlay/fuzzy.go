@@ -0,0 +1,77 @@
+package overlay
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Bonuses of a fuzzy match on top of one point per matched rune.
+const (
+	fuzzyWordStartBonus   = 5
+	fuzzyConsecutiveBonus = 3
+)
+
+// fuzzyScore returns how well query matches text, and false if it doesn't match at all. It matches if the runes of
+// query appear in text in the same order, ignoring case and spaces in query. Matches at the start of a word and
+// runs of consecutive matches score higher, so "fxt" prefers "fix-tests" to "flex-item-types".
+func fuzzyScore(query, text string) (int, bool) {
+	query = strings.ToLower(strings.ReplaceAll(query, " ", ""))
+	if query == "" {
+		return 0, true
+	}
+	score := 0
+	prev := ' '
+	// lastMatch is the index in text just after the previous matched rune, -1 before the first one.
+	lastMatch := -1
+	i := 0
+	for _, q := range query {
+		for {
+			if i >= len(text) {
+				return 0, false
+			}
+			r, size := utf8.DecodeRuneInString(text[i:])
+			at := i
+			i += size
+			if unicode.ToLower(r) != q {
+				prev = r
+				continue
+			}
+			score++
+			if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+				score += fuzzyWordStartBonus
+			}
+			if lastMatch != -1 && at == lastMatch {
+				score += fuzzyConsecutiveBonus
+			}
+			lastMatch = i
+			prev = r
+			break
+		}
+	}
+	return score, true
+}
+
+// FuzzyFilter returns the indexes of the candidates that query matches, best match first. Equally good matches
+// keep their order. An empty query matches every candidate.
+func FuzzyFilter(query string, candidates []string) []int {
+	type match struct {
+		index int
+		score int
+	}
+	var matches []match
+	for i, candidate := range candidates {
+		if score, ok := fuzzyScore(query, candidate); ok {
+			matches = append(matches, match{index: i, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(a, b int) bool {
+		return matches[a].score > matches[b].score
+	})
+	indexes := make([]int, len(matches))
+	for i, m := range matches {
+		indexes[i] = m.index
+	}
+	return indexes
+}
@@ -0,0 +1,46 @@
+package overlay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyFilter(t *testing.T) {
+	titles := []string{"fix-tests", "flex-item-types", "docs", "refactor-auth", "Fix login"}
+	filter := func(query string) []string {
+		var matched []string
+		for _, i := range FuzzyFilter(query, titles) {
+			matched = append(matched, titles[i])
+		}
+		return matched
+	}
+
+	assert.Equal(t, titles, filter(""), "an empty query keeps every title in order")
+	assert.Equal(t, []string{"docs"}, filter("docs"))
+	assert.Equal(t, []string{"fix-tests", "flex-item-types"}, filter("fxt"),
+		"matches at the start of words rank first")
+	assert.Equal(t, []string{"fix-tests", "Fix login"}, filter("FIX"),
+		"case is ignored, and equally good matches keep their order")
+	assert.Equal(t, []string{"flex-item-types", "fix-tests"}, filter("it"), "consecutive matches rank first")
+	assert.Equal(t, []string{"Fix login"}, filter("fix log"), "spaces in the query are ignored")
+	assert.Equal(t, []string{"refactor-auth"}, filter("ra"))
+	assert.Empty(t, filter("xyz"))
+	assert.Empty(t, filter("stsetx"), "the letters must appear in order")
+}
+
+func TestFuzzyScore(t *testing.T) {
+	score, ok := fuzzyScore("ft", "fix-tests")
+	assert.True(t, ok)
+	assert.Equal(t, 12, score, "two word starts")
+
+	score, ok = fuzzyScore("fi", "fix-tests")
+	assert.True(t, ok)
+	assert.Equal(t, 10, score, "a word start followed by a consecutive match")
+
+	_, ok = fuzzyScore("é", "cafe")
+	assert.False(t, ok)
+	score, ok = fuzzyScore("É", "café")
+	assert.True(t, ok)
+	assert.Equal(t, 1, score, "runes are compared by case-folding, not bytes")
+}
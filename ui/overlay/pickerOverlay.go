@@ -0,0 +1,129 @@
+package overlay
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var pickerSelectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
+
+// PickerOverlay lets the user choose one of a list of items.
+type PickerOverlay struct {
+	// Whether the overlay has been dismissed
+	Dismissed bool
+	// Whether an item was chosen. It is false if the overlay was cancelled.
+	Submitted bool
+
+	title    string
+	items    []string
+	selected int
+	// offset is the index of the first item shown when there are more items than fit.
+	offset int
+	width  int
+	height int
+}
+
+// NewPickerOverlay creates a picker with the given title offering items, with the first item selected.
+func NewPickerOverlay(title string, items []string) *PickerOverlay {
+	return &PickerOverlay{
+		title: title,
+		items: items,
+	}
+}
+
+// SetSize sets the outer size of the overlay.
+func (p *PickerOverlay) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+	p.scrollToSelected()
+}
+
+// visibleItems returns how many items fit. The border, padding, title and hint take 8 lines.
+func (p *PickerOverlay) visibleItems() int {
+	if p.height <= 0 {
+		return len(p.items)
+	}
+	return max(p.height-8, 1)
+}
+
+func (p *PickerOverlay) scrollToSelected() {
+	visible := p.visibleItems()
+	if p.selected < p.offset {
+		p.offset = p.selected
+	} else if p.selected >= p.offset+visible {
+		p.offset = p.selected - visible + 1
+	}
+}
+
+// HandleKeyPress moves the selection. Returns true if the overlay should be closed.
+func (p *PickerOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "esc", "q", "ctrl+c":
+		p.Dismissed = true
+		return true
+	case "enter":
+		p.Dismissed = true
+		p.Submitted = len(p.items) > 0
+		return true
+	case "up", "k", "shift+tab":
+		if p.selected > 0 {
+			p.selected--
+		}
+	case "down", "j", "tab":
+		if p.selected < len(p.items)-1 {
+			p.selected++
+		}
+	case "home", "g":
+		p.selected = 0
+	case "end", "G":
+		p.selected = max(len(p.items)-1, 0)
+	}
+	p.scrollToSelected()
+	return false
+}
+
+// Selected returns the selected item, or "" if there are no items.
+func (p *PickerOverlay) Selected() string {
+	if len(p.items) == 0 {
+		return ""
+	}
+	return p.items[p.selected]
+}
+
+// SelectedIndex returns the index of the selected item, or -1 if there are no items.
+func (p *PickerOverlay) SelectedIndex() int {
+	if len(p.items) == 0 {
+		return -1
+	}
+	return p.selected
+}
+
+// Render renders the picker overlay
+func (p *PickerOverlay) Render(opts ...WhitespaceOption) string {
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2).
+		Width(p.width)
+
+	end := min(p.offset+p.visibleItems(), len(p.items))
+	lines := make([]string, 0, end-p.offset)
+	for i := p.offset; i < end; i++ {
+		if i == p.selected {
+			lines = append(lines, pickerSelectedStyle.Render("> "+p.items[i]))
+		} else {
+			lines = append(lines, "  "+p.items[i])
+		}
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		activityTitleStyle.Render(p.title),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		activityHintStyle.Render("↑/↓ to choose, enter to select, esc to cancel"),
+	)
+	return style.Render(content)
+}
@@ -0,0 +1,67 @@
+package overlay
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickerOverlay(t *testing.T) {
+	key := func(s string) tea.KeyMsg {
+		switch s {
+		case "esc":
+			return tea.KeyMsg{Type: tea.KeyEsc}
+		case "enter":
+			return tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	t.Run("selects an item", func(t *testing.T) {
+		p := NewPickerOverlay("Templates", []string{"review", "bugfix", "docs"})
+		assert.Equal(t, "review", p.Selected())
+
+		assert.False(t, p.HandleKeyPress(key("j")))
+		assert.False(t, p.HandleKeyPress(key("j")))
+		assert.False(t, p.HandleKeyPress(key("j")))
+		assert.Equal(t, "docs", p.Selected(), "the selection stops at the last item")
+		assert.False(t, p.HandleKeyPress(key("k")))
+
+		assert.True(t, p.HandleKeyPress(key("enter")))
+		assert.True(t, p.Submitted)
+		assert.Equal(t, "bugfix", p.Selected())
+		assert.Equal(t, 1, p.SelectedIndex())
+	})
+
+	t.Run("no items", func(t *testing.T) {
+		p := NewPickerOverlay("Templates", nil)
+		assert.Equal(t, "", p.Selected())
+		assert.Equal(t, -1, p.SelectedIndex())
+		assert.True(t, p.HandleKeyPress(key("enter")))
+		assert.False(t, p.Submitted)
+	})
+
+	t.Run("can be cancelled", func(t *testing.T) {
+		p := NewPickerOverlay("Templates", []string{"review"})
+		assert.True(t, p.HandleKeyPress(key("esc")))
+		assert.True(t, p.Dismissed)
+		assert.False(t, p.Submitted)
+	})
+
+	t.Run("scrolls to the selection", func(t *testing.T) {
+		items := []string{"a", "b", "c", "d", "e", "f"}
+		p := NewPickerOverlay("Templates", items)
+		p.SetSize(30, 11)
+
+		view := ansi.Strip(p.Render())
+		assert.Contains(t, view, "> a")
+		assert.NotContains(t, view, "  d")
+
+		p.HandleKeyPress(key("G"))
+		view = ansi.Strip(p.Render())
+		assert.Contains(t, view, "> f")
+		assert.NotContains(t, view, "  a")
+	})
+}
@@ -0,0 +1,178 @@
+package overlay
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var switcherTagStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#808080"))
+
+// SwitcherItem is an item offered by a SwitcherOverlay.
+type SwitcherItem struct {
+	// Glyph is shown before the title, e.g. the status icon of an instance. It may be styled.
+	Glyph string
+	// Title is what the item is matched and shown by.
+	Title string
+	// Tags are matched after the title and shown after it.
+	Tags []string
+}
+
+// text returns what the query is matched against.
+func (i SwitcherItem) text() string {
+	return strings.Join(append([]string{i.Title}, i.Tags...), " ")
+}
+
+// SwitcherOverlay lets the user choose one of a list of items by typing part of it. The items are filtered with
+// FuzzyFilter as the user types, best match first.
+type SwitcherOverlay struct {
+	// Whether the overlay has been dismissed
+	Dismissed bool
+	// Whether an item was chosen. It is false if the overlay was cancelled.
+	Submitted bool
+
+	title string
+	items []SwitcherItem
+	// texts are the texts of items that the query is matched against.
+	texts []string
+	query string
+	// matches are the indexes of the items that match query, best match first.
+	matches  []int
+	selected int
+	// offset is the index in matches of the first item shown when there are more matches than fit.
+	offset int
+	width  int
+	height int
+}
+
+// NewSwitcherOverlay creates a switcher with the given title offering items, all of them shown until the user
+// types.
+func NewSwitcherOverlay(title string, items []SwitcherItem) *SwitcherOverlay {
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = item.text()
+	}
+	return &SwitcherOverlay{
+		title:   title,
+		items:   items,
+		texts:   texts,
+		matches: FuzzyFilter("", texts),
+	}
+}
+
+// SetSize sets the outer size of the overlay.
+func (s *SwitcherOverlay) SetSize(width, height int) {
+	s.width = width
+	s.height = height
+	s.scrollToSelected()
+}
+
+// visibleItems returns how many items fit. The border, padding, title, query and hint take 10 lines.
+func (s *SwitcherOverlay) visibleItems() int {
+	if s.height <= 0 {
+		return max(len(s.matches), 1)
+	}
+	return max(s.height-10, 1)
+}
+
+func (s *SwitcherOverlay) scrollToSelected() {
+	visible := s.visibleItems()
+	if s.selected < s.offset {
+		s.offset = s.selected
+	} else if s.selected >= s.offset+visible {
+		s.offset = s.selected - visible + 1
+	}
+}
+
+// setQuery filters the items with query and selects the best match.
+func (s *SwitcherOverlay) setQuery(query string) {
+	s.query = query
+	s.matches = FuzzyFilter(query, s.texts)
+	s.selected = 0
+	s.offset = 0
+}
+
+// HandleKeyPress edits the query or moves the selection. Returns true if the overlay should be closed.
+func (s *SwitcherOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		s.Dismissed = true
+		return true
+	case tea.KeyEnter:
+		s.Dismissed = true
+		s.Submitted = len(s.matches) > 0
+		return true
+	case tea.KeyUp, tea.KeyCtrlP, tea.KeyShiftTab:
+		if s.selected > 0 {
+			s.selected--
+		}
+	case tea.KeyDown, tea.KeyCtrlN, tea.KeyTab:
+		if s.selected < len(s.matches)-1 {
+			s.selected++
+		}
+	case tea.KeyBackspace:
+		if runes := []rune(s.query); len(runes) > 0 {
+			s.setQuery(string(runes[:len(runes)-1]))
+		}
+	case tea.KeyCtrlU:
+		s.setQuery("")
+	case tea.KeySpace:
+		s.setQuery(s.query + " ")
+	case tea.KeyRunes:
+		s.setQuery(s.query + string(msg.Runes))
+	}
+	s.scrollToSelected()
+	return false
+}
+
+// Query returns what the user typed.
+func (s *SwitcherOverlay) Query() string {
+	return s.query
+}
+
+// SelectedIndex returns the index of the selected item among all items, or -1 if no item matches.
+func (s *SwitcherOverlay) SelectedIndex() int {
+	if len(s.matches) == 0 {
+		return -1
+	}
+	return s.matches[s.selected]
+}
+
+// Render renders the switcher overlay
+func (s *SwitcherOverlay) Render(opts ...WhitespaceOption) string {
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2).
+		Width(s.width)
+
+	end := min(s.offset+s.visibleItems(), len(s.matches))
+	lines := make([]string, 0, end-s.offset)
+	for i := s.offset; i < end; i++ {
+		item := s.items[s.matches[i]]
+		tags := ""
+		if len(item.Tags) > 0 {
+			tags = " " + switcherTagStyle.Render(strings.Join(item.Tags, " "))
+		}
+		if i == s.selected {
+			lines = append(lines, pickerSelectedStyle.Render("> ")+item.Glyph+pickerSelectedStyle.Render(item.Title)+tags)
+		} else {
+			lines = append(lines, "  "+item.Glyph+item.Title+tags)
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, activityHintStyle.Render("  No matches"))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		activityTitleStyle.Render(s.title),
+		"",
+		"> "+s.query+"█",
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		activityHintStyle.Render("type to filter, ↑/↓ to choose, enter to jump, esc to cancel"),
+	)
+	return style.Render(content)
+}
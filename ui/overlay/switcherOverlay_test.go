@@ -0,0 +1,78 @@
+package overlay
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwitcherOverlay(t *testing.T) {
+	items := []SwitcherItem{
+		{Glyph: "● ", Title: "fix-tests"},
+		{Glyph: "⏸ ", Title: "docs", Tags: []string{"writing"}},
+		{Glyph: "● ", Title: "refactor-auth", Tags: []string{"backend"}},
+	}
+	typeText := func(s *SwitcherOverlay, text string) {
+		for _, r := range text {
+			assert.False(t, s.HandleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}))
+		}
+	}
+
+	t.Run("typing filters the items", func(t *testing.T) {
+		s := NewSwitcherOverlay("Jump to session", items)
+		assert.Equal(t, 0, s.SelectedIndex())
+
+		typeText(s, "rfa")
+		assert.Equal(t, "rfa", s.Query())
+		assert.Equal(t, 2, s.SelectedIndex())
+		rendered := ansi.Strip(s.Render())
+		assert.Contains(t, rendered, "> ● refactor-auth backend")
+		assert.NotContains(t, rendered, "fix-tests")
+
+		assert.True(t, s.HandleKeyPress(tea.KeyMsg{Type: tea.KeyEnter}))
+		assert.True(t, s.Submitted)
+		assert.Equal(t, 2, s.SelectedIndex())
+	})
+
+	t.Run("tags are matched", func(t *testing.T) {
+		s := NewSwitcherOverlay("Jump to session", items)
+		typeText(s, "writ")
+		assert.Equal(t, 1, s.SelectedIndex())
+	})
+
+	t.Run("keys like j and q are typed", func(t *testing.T) {
+		s := NewSwitcherOverlay("Jump to session", items)
+		typeText(s, "jq")
+		assert.False(t, s.Dismissed)
+		assert.Equal(t, -1, s.SelectedIndex())
+		assert.Contains(t, ansi.Strip(s.Render()), "No matches")
+
+		assert.True(t, s.HandleKeyPress(tea.KeyMsg{Type: tea.KeyEnter}))
+		assert.False(t, s.Submitted, "nothing can be chosen without matches")
+	})
+
+	t.Run("backspace widens the filter and arrows move the selection", func(t *testing.T) {
+		s := NewSwitcherOverlay("Jump to session", items)
+		typeText(s, "dx")
+		assert.Equal(t, -1, s.SelectedIndex())
+		s.HandleKeyPress(tea.KeyMsg{Type: tea.KeyBackspace})
+		assert.Equal(t, "d", s.Query())
+		assert.Equal(t, 1, s.SelectedIndex())
+
+		s.HandleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+		assert.Equal(t, 2, s.SelectedIndex(), "refactor-auth matches d too")
+		s.HandleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+		assert.Equal(t, 2, s.SelectedIndex(), "the selection stops at the last match")
+		s.HandleKeyPress(tea.KeyMsg{Type: tea.KeyUp})
+		assert.Equal(t, 1, s.SelectedIndex())
+	})
+
+	t.Run("esc cancels", func(t *testing.T) {
+		s := NewSwitcherOverlay("Jump to session", items)
+		assert.True(t, s.HandleKeyPress(tea.KeyMsg{Type: tea.KeyEsc}))
+		assert.True(t, s.Dismissed)
+		assert.False(t, s.Submitted)
+	})
+}
@@ -15,6 +15,8 @@ type TextInputOverlay struct {
 	Canceled      bool
 	OnSubmit      func()
 	width, height int
+	// complete completes the value when tab is pressed in the input. Nil if the input has no completion.
+	complete func(value string) string
 }
 
 // NewTextInputOverlay creates a new text input overlay with the given title and initial value.
@@ -40,6 +42,12 @@ func NewTextInputOverlay(title string, initialValue string) *TextInputOverlay {
 	}
 }
 
+// SetCompleter makes tab in the input complete the value with complete. Once complete leaves the value as it is,
+// tab moves the focus to the enter button as usual.
+func (t *TextInputOverlay) SetCompleter(complete func(value string) string) {
+	t.complete = complete
+}
+
 func (t *TextInputOverlay) SetSize(width, height int) {
 	t.textarea.SetHeight(height) // Set textarea height to 10 lines
 	t.width = width
@@ -61,6 +69,14 @@ func (t *TextInputOverlay) View() string {
 func (t *TextInputOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
 	switch msg.Type {
 	case tea.KeyTab:
+		if t.FocusIndex == 0 && t.complete != nil {
+			value := t.textarea.Value()
+			if completed := t.complete(value); completed != value {
+				t.textarea.SetValue(completed)
+				t.textarea.CursorEnd()
+				return false
+			}
+		}
 		// Toggle focus between input and enter button.
 		t.FocusIndex = (t.FocusIndex + 1) % 2
 		if t.FocusIndex == 0 {
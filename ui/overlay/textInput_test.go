@@ -0,0 +1,29 @@
+package overlay
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextInputOverlayCompleter(t *testing.T) {
+	input := NewTextInputOverlay("Repository", "~/pro")
+	input.SetCompleter(func(value string) string {
+		if value == "~/pro" {
+			return "~/projects/"
+		}
+		return value
+	})
+
+	assert.False(t, input.HandleKeyPress(tea.KeyMsg{Type: tea.KeyTab}))
+	assert.Equal(t, "~/projects/", input.GetValue())
+	assert.Equal(t, 0, input.FocusIndex, "completing keeps the focus in the input")
+
+	// Nothing more to complete, so tab moves to the enter button.
+	assert.False(t, input.HandleKeyPress(tea.KeyMsg{Type: tea.KeyTab}))
+	assert.Equal(t, 1, input.FocusIndex)
+	assert.True(t, input.HandleKeyPress(tea.KeyMsg{Type: tea.KeyEnter}))
+	assert.True(t, input.IsSubmitted())
+	assert.Equal(t, "~/projects/", input.GetValue())
+}
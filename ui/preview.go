@@ -12,6 +12,10 @@ import (
 var previewPaneStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#dddddd"})
 
+// exitedHintStyle is the style of the hint below the output of a program that exited.
+var exitedHintStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.AdaptiveColor{Light: "#FFD700", Dark: "#FFD700"})
+
 type PreviewPane struct {
 	width  int
 	height int
@@ -70,6 +74,9 @@ func (p *PreviewPane) UpdateContent(instance *session.Instance) error {
 				)),
 		))
 		return nil
+	case instance.Status == session.Archived:
+		p.setFallbackState(fmt.Sprintf("Session is archived on branch '%s'. Press 'a' to restore it.", instance.Branch))
+		return nil
 	}
 
 	var content string
@@ -96,6 +103,11 @@ func (p *PreviewPane) UpdateContent(instance *session.Instance) error {
 			return err
 		}
 
+		if instance.Status == session.Exited {
+			content = lipgloss.JoinVertical(lipgloss.Left, content,
+				exitedHintStyle.Render("The program exited. Press enter to restart, archive or kill the session."))
+		}
+
 		// Always update the preview state with content, even if empty
 		// This ensures that newly created instances will display their content immediately
 		if len(content) == 0 && !instance.Started() {
@@ -179,34 +191,46 @@ func (p *PreviewPane) String() string {
 	return rendered
 }
 
-// ScrollUp scrolls up in the viewport
-func (p *PreviewPane) ScrollUp(instance *session.Instance) error {
-	if instance == nil || instance.Status == session.Paused {
-		return nil
+// enterScrollMode captures the entire pane content including scrollback history and shows it in the
+// viewport, positioned at the bottom.
+func (p *PreviewPane) enterScrollMode(instance *session.Instance) error {
+	content, err := instance.PreviewFullHistory()
+	if err != nil {
+		return err
 	}
 
-	if !p.isScrolling {
-		// Entering scroll mode - capture entire pane content including scrollback history
-		content, err := instance.PreviewFullHistory()
-		if err != nil {
-			return err
-		}
+	// Set content in the viewport
+	footer := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#808080", Dark: "#808080"}).
+		Render("ESC to exit scroll mode")
 
-		// Set content in the viewport
-		footer := lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#808080", Dark: "#808080"}).
-			Render("ESC to exit scroll mode")
+	contentWithFooter := lipgloss.JoinVertical(lipgloss.Left, content, footer)
+	p.viewport.SetContent(contentWithFooter)
+
+	// Position the viewport at the bottom initially
+	p.viewport.GotoBottom()
 
-		contentWithFooter := lipgloss.JoinVertical(lipgloss.Left, content, footer)
-		p.viewport.SetContent(contentWithFooter)
+	p.isScrolling = true
+	return nil
+}
 
-		// Position the viewport at the bottom initially
-		p.viewport.GotoBottom()
+// exitScrollMode leaves scroll mode without refreshing the preview.
+func (p *PreviewPane) exitScrollMode() {
+	p.isScrolling = false
+	p.viewport.SetContent("")
+	p.viewport.GotoTop()
+}
 
-		p.isScrolling = true
+// ScrollUp scrolls up in the viewport
+func (p *PreviewPane) ScrollUp(instance *session.Instance) error {
+	if instance == nil || instance.Status == session.Paused {
 		return nil
 	}
 
+	if !p.isScrolling {
+		return p.enterScrollMode(instance)
+	}
+
 	// Already in scroll mode, just scroll the viewport
 	p.viewport.LineUp(1)
 	return nil
@@ -219,25 +243,7 @@ func (p *PreviewPane) ScrollDown(instance *session.Instance) error {
 	}
 
 	if !p.isScrolling {
-		// Entering scroll mode - capture entire pane content including scrollback history
-		content, err := instance.PreviewFullHistory()
-		if err != nil {
-			return err
-		}
-
-		// Set content in the viewport
-		footer := lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#808080", Dark: "#808080"}).
-			Render("ESC to exit scroll mode")
-
-		contentWithFooter := lipgloss.JoinVertical(lipgloss.Left, content, footer)
-		p.viewport.SetContent(contentWithFooter)
-
-		// Position the viewport at the bottom initially
-		p.viewport.GotoBottom()
-
-		p.isScrolling = true
-		return nil
+		return p.enterScrollMode(instance)
 	}
 
 	// Already in copy mode, just scroll the viewport
@@ -252,10 +258,7 @@ func (p *PreviewPane) ResetToNormalMode(instance *session.Instance) error {
 	}
 
 	if p.isScrolling {
-		p.isScrolling = false
-		// Reset viewport
-		p.viewport.SetContent("")
-		p.viewport.GotoTop()
+		p.exitScrollMode()
 
 		// Immediately update content instead of waiting for next UpdateContent call
 		content, err := instance.Preview()
@@ -31,6 +31,9 @@ func setupTestEnvironment(t *testing.T, cmdExec cmd_test.MockCmdExec) *testSetup
 	// Initialize logging
 	log.Initialize(false)
 
+	// Keep worktrees and activity logs out of the real config directory
+	t.Setenv("HOME", t.TempDir())
+
 	// Set up a temp working directory
 	workdir := t.TempDir()
 
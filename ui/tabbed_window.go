@@ -3,6 +3,7 @@ package ui
 import (
 	"claude-squad/log"
 	"claude-squad/session"
+	"claude-squad/session/git"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -25,14 +26,18 @@ var (
 	activeTabStyle = inactiveTabStyle.
 			Border(activeTabBorder, true).
 			AlignHorizontal(lipgloss.Center)
+	pinnedStyle = lipgloss.NewStyle().Bold(true).Foreground(highlightColor)
 	windowStyle = lipgloss.NewStyle().
 			BorderForeground(highlightColor).
 			Border(lipgloss.NormalBorder(), false, true, true, true)
 )
 
+// The tabs of the tabbed window, in the order they are shown.
 const (
 	PreviewTab int = iota
 	DiffTab
+	ActivityTab
+	LogsTab
 )
 
 type Tab struct {
@@ -51,24 +56,115 @@ type TabbedWindow struct {
 
 	preview  *PreviewPane
 	diff     *DiffPane
+	activity *ActivityPane
+	logs     *LogsPane
 	instance *session.Instance
+	// pinned is the instance the window is pinned to, or nil if it follows the selection. It is shown above the
+	// tabs.
+	pinned *session.Instance
+
+	// diffXOffset is how far the diff is scrolled horizontally. It is reset when the instance or tab changes.
+	diffXOffset int
+
+	// scrollPositions remembers where each instance's panes were scrolled to, so switching back to an
+	// instance restores them.
+	scrollPositions map[*session.Instance]scrollPosition
+}
+
+// scrollPosition is the vertical scroll state of an instance's preview and diff panes. The line counts
+// are used to detect when the content changed too much for the offsets to still make sense.
+type scrollPosition struct {
+	previewScrolling bool
+	previewYOffset   int
+	previewLines     int
+	diffYOffset      int
+	diffLines        int
+}
+
+// contentChanged returns true if the number of lines changed by more than half, in which case a saved
+// scroll offset no longer points at what the user was reading.
+func contentChanged(oldLines, newLines int) bool {
+	delta := newLines - oldLines
+	if delta < 0 {
+		delta = -delta
+	}
+	return delta > oldLines/2
 }
 
+// diffScrollStep is the number of columns the diff scrolls horizontally per key press.
+const diffScrollStep = 8
+
 func NewTabbedWindow(preview *PreviewPane, diff *DiffPane) *TabbedWindow {
 	return &TabbedWindow{
 		tabs: []string{
 			"Preview",
 			"Diff",
+			"Activity",
+			"Logs",
 		},
-		preview: preview,
-		diff:    diff,
+		preview:         preview,
+		diff:            diff,
+		activity:        NewActivityPane(),
+		logs:            NewLogsPane(),
+		scrollPositions: make(map[*session.Instance]scrollPosition),
 	}
 }
 
 func (w *TabbedWindow) SetInstance(instance *session.Instance) {
+	if w.instance != instance {
+		w.resetDiffXOffset()
+		w.restoreScrollPosition(instance)
+	}
 	w.instance = instance
 }
 
+// SetPinned sets the instance the window is pinned to, or nil if it follows the selection.
+func (w *TabbedWindow) SetPinned(instance *session.Instance) {
+	w.pinned = instance
+}
+
+// saveScrollPosition remembers the scroll position of the current instance.
+func (w *TabbedWindow) saveScrollPosition() {
+	if w.instance == nil {
+		return
+	}
+	w.scrollPositions[w.instance] = scrollPosition{
+		previewScrolling: w.preview.isScrolling,
+		previewYOffset:   w.preview.viewport.YOffset,
+		previewLines:     w.preview.viewport.TotalLineCount(),
+		diffYOffset:      w.diff.viewport.YOffset,
+		diffLines:        w.diff.viewport.TotalLineCount(),
+	}
+}
+
+// restoreScrollPosition scrolls the panes back to where they were when instance was last shown. Panes
+// without a saved position, or whose content changed substantially, start from the top of the diff and
+// the live preview.
+func (w *TabbedWindow) restoreScrollPosition(instance *session.Instance) {
+	// The preview's scroll mode holds the previous instance's history.
+	if w.preview.isScrolling {
+		w.preview.exitScrollMode()
+	}
+
+	pos, ok := w.scrollPositions[instance]
+	if !ok || contentChanged(pos.diffLines, w.diff.viewport.TotalLineCount()) {
+		w.diff.viewport.GotoTop()
+	} else {
+		w.diff.viewport.SetYOffset(pos.diffYOffset)
+	}
+
+	if !ok || !pos.previewScrolling || instance == nil || instance.Status == session.Paused {
+		return
+	}
+	if err := w.preview.enterScrollMode(instance); err != nil {
+		log.InfoLog.Printf("tabbed window failed to restore preview scroll position: %v", err)
+		return
+	}
+	if !contentChanged(pos.previewLines, w.preview.viewport.TotalLineCount()) {
+		w.preview.viewport.SetYOffset(pos.previewYOffset)
+	}
+}
+
 // AdjustPreviewWidth adjusts the width of the preview pane to be 90% of the provided width.
 func AdjustPreviewWidth(width int) int {
 	return int(float64(width) * 0.9)
@@ -93,14 +189,38 @@ func (w *TabbedWindow) SetSize(width, height int) {
 	contentWidth, contentHeight := w.contentDimensions()
 	w.preview.SetSize(contentWidth, contentHeight)
 	w.diff.SetSize(contentWidth, contentHeight)
+	w.activity.SetSize(contentWidth, contentHeight)
+	w.logs.SetSize(contentWidth, contentHeight)
 }
 
 func (w *TabbedWindow) GetPreviewSize() (width, height int) {
 	return w.preview.width, w.preview.height
 }
 
+// Toggle switches to the next tab. It is kept from when there were only the preview and diff tabs.
 func (w *TabbedWindow) Toggle() {
-	w.activeTab = (w.activeTab + 1) % len(w.tabs)
+	w.NextTab()
+}
+
+// NextTab switches to the tab to the right of the active one, wrapping around to the first tab.
+func (w *TabbedWindow) NextTab() {
+	w.SetActiveTab((w.activeTab + 1) % len(w.tabs))
+}
+
+// PrevTab switches to the tab to the left of the active one, wrapping around to the last tab.
+func (w *TabbedWindow) PrevTab() {
+	w.SetActiveTab((w.activeTab - 1 + len(w.tabs)) % len(w.tabs))
+}
+
+// SetActiveTab switches to the tab at index tab. Returns false and stays on the active tab if there is no such
+// tab.
+func (w *TabbedWindow) SetActiveTab(tab int) bool {
+	if tab < 0 || tab >= len(w.tabs) {
+		return false
+	}
+	w.activeTab = tab
+	w.resetDiffXOffset()
+	return true
 }
 
 // ToggleWithReset toggles the tab and resets preview pane to normal mode
@@ -109,7 +229,7 @@ func (w *TabbedWindow) ToggleWithReset(instance *session.Instance) error {
 	if err := w.preview.ResetToNormalMode(instance); err != nil {
 		return err
 	}
-	w.activeTab = (w.activeTab + 1) % len(w.tabs)
+	w.NextTab()
 	return nil
 }
 
@@ -128,37 +248,138 @@ func (w *TabbedWindow) UpdateDiff(instance *session.Instance) {
 	w.diff.SetDiff(instance)
 }
 
+// UpdateActivity updates the activity tab with the activity log of instance. instance may be nil.
+func (w *TabbedWindow) UpdateActivity(instance *session.Instance) error {
+	if w.activeTab != ActivityTab {
+		return nil
+	}
+	return w.activity.SetInstance(instance)
+}
+
+// UpdateLogs updates the logs tab with the output history of instance. instance may be nil.
+func (w *TabbedWindow) UpdateLogs(instance *session.Instance) {
+	if w.activeTab != LogsTab {
+		return
+	}
+	w.logs.SetInstance(instance)
+}
+
 // ResetPreviewToNormalMode resets the preview pane to normal mode
 func (w *TabbedWindow) ResetPreviewToNormalMode(instance *session.Instance) error {
+	defer w.saveScrollPosition()
 	return w.preview.ResetToNormalMode(instance)
 }
 
 // Add these new methods for handling scroll events
 func (w *TabbedWindow) ScrollUp() {
-	if w.activeTab == PreviewTab {
+	defer w.saveScrollPosition()
+	switch w.activeTab {
+	case PreviewTab:
 		err := w.preview.ScrollUp(w.instance)
 		if err != nil {
 			log.InfoLog.Printf("tabbed window failed to scroll up: %v", err)
 		}
-	} else {
+	case DiffTab:
 		w.diff.ScrollUp()
+	case ActivityTab:
+		w.activity.ScrollUp()
+	case LogsTab:
+		w.logs.ScrollUp()
 	}
 }
 
 func (w *TabbedWindow) ScrollDown() {
-	if w.activeTab == PreviewTab {
+	defer w.saveScrollPosition()
+	switch w.activeTab {
+	case PreviewTab:
 		err := w.preview.ScrollDown(w.instance)
 		if err != nil {
 			log.InfoLog.Printf("tabbed window failed to scroll down: %v", err)
 		}
-	} else {
+	case DiffTab:
 		w.diff.ScrollDown()
+	case ActivityTab:
+		w.activity.ScrollDown()
+	case LogsTab:
+		w.logs.ScrollDown()
 	}
 }
 
+// ScrollLeft scrolls the diff tab left.
+func (w *TabbedWindow) ScrollLeft() {
+	if w.activeTab == DiffTab {
+		w.diffXOffset = w.diff.SetXOffset(w.diffXOffset - diffScrollStep)
+	}
+}
+
+// ScrollRight scrolls the diff tab right.
+func (w *TabbedWindow) ScrollRight() {
+	if w.activeTab == DiffTab {
+		w.diffXOffset = w.diff.SetXOffset(w.diffXOffset + diffScrollStep)
+	}
+}
+
+// resetDiffXOffset scrolls the diff back to the first column.
+func (w *TabbedWindow) resetDiffXOffset() {
+	if w.diffXOffset != 0 {
+		w.diffXOffset = w.diff.SetXOffset(0)
+	}
+}
+
+// SetDiffFileOrder sets the order the files are shown in in the diff tab.
+func (w *TabbedWindow) SetDiffFileOrder(order git.FileOrder) {
+	w.diff.SetFileOrder(order)
+}
+
+// ToggleDiffFiles expands or collapses the per-file summary in the diff tab.
+func (w *TabbedWindow) ToggleDiffFiles() {
+	w.diff.ToggleFiles()
+}
+
+// NextDiffFile jumps to the next file in the diff tab.
+func (w *TabbedWindow) NextDiffFile() {
+	w.diff.NextFile()
+}
+
+// PrevDiffFile jumps to the previous file in the diff tab.
+func (w *TabbedWindow) PrevDiffFile() {
+	w.diff.PrevFile()
+}
+
+// NextDiffHunk jumps to the next hunk in the diff tab.
+func (w *TabbedWindow) NextDiffHunk() {
+	w.diff.NextHunk()
+}
+
+// PrevDiffHunk jumps to the previous hunk in the diff tab.
+func (w *TabbedWindow) PrevDiffHunk() {
+	w.diff.PrevHunk()
+}
+
+// TabAt returns the index of the tab whose header is rendered at (x, y) of String's output. Returns false if
+// the position is not on a tab header.
+func (w *TabbedWindow) TabAt(x, y int) (int, bool) {
+	// String renders two blank lines above the tab row.
+	const tabRowTop = 2
+	if w.width == 0 || x < 0 || x >= w.width || y < tabRowTop || y >= tabRowTop+w.tabHeight() {
+		return 0, false
+	}
+	tab := x / (w.width / len(w.tabs))
+	if tab >= len(w.tabs) {
+		// The last tab absorbs the remainder of the width.
+		tab = len(w.tabs) - 1
+	}
+	return tab, true
+}
+
+// ActiveTab returns the index of the active tab.
+func (w *TabbedWindow) ActiveTab() int {
+	return w.activeTab
+}
+
 // IsInDiffTab returns true if the diff tab is currently active
 func (w *TabbedWindow) IsInDiffTab() bool {
-	return w.activeTab == 1
+	return w.activeTab == DiffTab
 }
 
 // IsPreviewInScrollMode returns true if the preview pane is in scroll mode
@@ -206,10 +427,15 @@ func (w *TabbedWindow) String() string {
 
 	row := lipgloss.JoinHorizontal(lipgloss.Top, renderedTabs...)
 	var content string
-	if w.activeTab == 0 {
+	switch w.activeTab {
+	case PreviewTab:
 		content = w.preview.String()
-	} else {
+	case DiffTab:
 		content = w.diff.String()
+	case ActivityTab:
+		content = w.activity.String()
+	case LogsTab:
+		content = w.logs.String()
 	}
 	_, contentHeight := w.contentDimensions()
 	window := windowStyle.Render(
@@ -217,5 +443,10 @@ func (w *TabbedWindow) String() string {
 			w.width, contentHeight,
 			lipgloss.Left, lipgloss.Top, content))
 
-	return lipgloss.JoinVertical(lipgloss.Left, "\n", row, window)
+	// The tab row is kept in place with two lines above it, the first of which shows the pinned instance.
+	header := "\n"
+	if w.pinned != nil {
+		header = pinnedStyle.MaxWidth(w.width).Render("📌 Pinned: "+w.pinned.Title) + "\n"
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, header, row, window)
 }